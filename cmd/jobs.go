@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testingtask/internal/config"
+	"testingtask/internal/scheduler"
+	"testingtask/internal/service"
+	logger "testingtask/pkg"
+	"time"
+)
+
+// Имена фоновых задач в /admin/jobs и истории запусков.
+const (
+	jobExpireSubscriptions = "expire-subscriptions"
+	jobSendReminders       = "send-reminders"
+	jobPurgeJobRuns        = "purge-job-runs"
+)
+
+// newScheduler собирает задачи, включённые конфигом.
+func newScheduler(cfg *config.Config, store *storage, subs service.SubService, reminders service.ReminderService) (*scheduler.Scheduler, error) {
+	jobs := []scheduler.Job{{
+		Name:     jobPurgeJobRuns,
+		Schedule: cfg.Jobs.PurgeSchedule,
+		Run: func(ctx context.Context) error {
+			n, err := store.jobs.PurgeRuns(ctx, time.Now().Add(-cfg.Jobs.HistoryRetention))
+			if n > 0 {
+				logger.Info(ctx, "jobs: old runs purged", map[string]interface{}{
+					"purged": n,
+				})
+			}
+			return err
+		},
+	}}
+
+	// SubscriptionExpired нужен, только если события кто-то получает.
+	if cfg.RecordsEvents() {
+		jobs = append(jobs, scheduler.Job{
+			Name:     jobExpireSubscriptions,
+			Schedule: cfg.Outbox.ExpireSchedule,
+			Run: func(ctx context.Context) error {
+				_, err := subs.ExpireEnded(ctx, time.Now())
+				return err
+			},
+		})
+	}
+	if cfg.Reminders.Enabled {
+		jobs = append(jobs, scheduler.Job{
+			Name:     jobSendReminders,
+			Schedule: cfg.Reminders.Schedule,
+			Run: func(ctx context.Context) error {
+				_, err := reminders.SendDue(ctx, time.Now())
+				return err
+			},
+		})
+	}
+
+	for i := range jobs {
+		jobs[i].Timeout = cfg.Jobs.Timeout
+	}
+
+	instance, err := os.Hostname()
+	if err != nil {
+		instance = "unknown"
+	}
+	return scheduler.New(store.jobs, scheduler.Config{
+		Instance:         instance,
+		ElectionInterval: cfg.Jobs.ElectionInterval,
+	}, jobs...)
+}
+
+// startScheduler запускает планировщик. Возвращаемая функция отменяет
+// текущие запуски, дожидается их и отдаёт лидерство.
+func startScheduler(ctx context.Context, s *scheduler.Scheduler) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.Run(ctx)
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
@@ -1,22 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"testingtask/internal/config"
-	"testingtask/internal/database"
-
-	"testingtask/internal/delivery/http/middleware"
-	v1 "testingtask/internal/delivery/http/v1"
-	"testingtask/internal/repository"
-	"testingtask/internal/service"
-	"testingtask/internal/web/subscriptions"
 	logger "testingtask/pkg"
 
 	_ "testingtask/docs"
-
-	"github.com/labstack/echo/v4"
-	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
 // @title Subscription API
@@ -26,41 +21,61 @@ import (
 // @BasePath /api
 // @schemes http
 func main() {
-	e := echo.New()
-
-	e.Use(middleware.RequestLoggerMiddleware)
-
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		panic("Failed to load config: " + err.Error())
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		usage()
+		return
 	}
-
-	appEnv := cfg.AppEnv
-	logger.Init(appEnv == "development")
-
-	db, err := database.InitDB(cfg)
 	if err != nil {
-		panic("Failed to init database: " + err.Error())
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
 	}
 
-	e.GET("/ping", func(c echo.Context) error {
-		return c.String(http.StatusOK, "pong")
-	})
-
-	e.GET("/swagger/*", echoSwagger.WrapHandler)
+	if cfg.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			panic("Failed to print config: " + err.Error())
+		}
+		return
+	}
 
-	router := e.Group("/api")
+	logger.Init(cfg.Logging.Pretty || cfg.IsDevelopment())
+	if err := logger.SetLevel(cfg.Logging.Level); err != nil {
+		panic("Failed to set log level: " + err.Error())
+	}
 
-	subRepo := repository.NewSubRepository(db)
-	subService := service.NewSubService(subRepo)
-	subHandler := v1.NewSubHandler(subService)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	subStrictHandler := subscriptions.NewStrictHandler(subHandler, nil)
-	subscriptions.RegisterHandlers(router, subStrictHandler)
+	cmd, args := "serve", cfg.Args
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
 
-	port := fmt.Sprintf(":%s", cfg.PORT)
+	switch cmd {
+	case "serve":
+		err = runServer(ctx, stop, cfg)
+	case "migrate":
+		err = runMigrate(ctx, cfg, args)
+	default:
+		usage()
+		os.Exit(2)
+	}
 
-	if err := e.Start(port); err != nil {
-		e.Logger.Error("failed to start server", "error", err)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+		os.Exit(1)
 	}
 }
+
+func usage() {
+	fmt.Fprintln(os.Stdout, "Usage: server [flags] [command]")
+	fmt.Fprintln(os.Stdout)
+	fmt.Fprintln(os.Stdout, "Commands:")
+	fmt.Fprintln(os.Stdout, "  serve                 run HTTP server (default)")
+	fmt.Fprintln(os.Stdout, "  migrate up            apply all pending migrations")
+	fmt.Fprintln(os.Stdout, "  migrate down [N]      revert N migrations (default 1)")
+	fmt.Fprintln(os.Stdout, "  migrate status        show current and pending versions")
+	fmt.Fprintln(os.Stdout, "  migrate force VERSION set version and clear dirty flag")
+	fmt.Fprintln(os.Stdout)
+	config.Usage(os.Stdout)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"testingtask/internal/config"
+	"testingtask/internal/database"
+)
+
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected one of: up, down [N], status, force VERSION")
+	}
+	if database.IsMemory(cfg.Database.URL) {
+		return errors.New("in-memory storage has no migrations")
+	}
+
+	db, err := database.InitDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close(db) }()
+
+	m, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "applied %d migration(s), version %d\n", n, m.Latest())
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		n, err := m.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "reverted %d migration(s)\n", n)
+
+	case "status":
+		st, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "current: %d (dirty: %t)\nlatest:  %d\n", st.Current, st.Dirty, st.Latest)
+		for _, p := range st.Pending {
+			fmt.Fprintf(os.Stdout, "pending: %d_%s\n", p.Version, p.Name)
+		}
+
+	case "force":
+		if len(args) < 2 {
+			return errors.New("force requires VERSION")
+		}
+		v, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := m.Force(ctx, v); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "forced version %d\n", v)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testingtask/internal/config"
+	"testingtask/internal/outbox"
+	"testingtask/internal/service"
+	logger "testingtask/pkg"
+)
+
+// startOutbox запускает relay событий и, если включены webhook'и,
+// отправку доставок. Возвращаемая функция останавливает все циклы и
+// дожидается их завершения.
+func startOutbox(ctx context.Context, cfg *config.Config, store *storage) (func(), error) {
+	if !cfg.RecordsEvents() {
+		return func() {}, nil
+	}
+
+	var (
+		sinks  []outbox.Sink
+		closer io.Closer
+	)
+	switch cfg.Outbox.Sink {
+	case outbox.SinkNone:
+	case outbox.SinkWebhook:
+		sinks = append(sinks, outbox.NewWebhookSink(cfg.Outbox.WebhookURL, cfg.Outbox.WebhookTimeout))
+	case outbox.SinkFile:
+		sink, c, err := outbox.NewFileSink(cfg.Outbox.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		sinks, closer = append(sinks, sink), c
+	case outbox.SinkNATS:
+		pub, c, err := outbox.NewNATSPublisher(outbox.NATSConfig{
+			URL:       cfg.Outbox.NATSURL,
+			JetStream: cfg.Outbox.NATSJetStream,
+			Timeout:   cfg.Outbox.NATSTimeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sinks, closer = append(sinks, outbox.NewBrokerSink(pub, cfg.Outbox.NATSSubject)), c
+	default:
+		return nil, fmt.Errorf("unknown outbox sink %q", cfg.Outbox.Sink)
+	}
+	if cfg.Webhooks.Enabled {
+		sinks = append(sinks, service.NewWebhookDispatcher(store.hooks))
+	}
+
+	relay := outbox.NewRelay(store.events, outbox.NewMultiSink(sinks...), outbox.RelayConfig{
+		PollInterval: cfg.Outbox.PollInterval,
+		BatchSize:    cfg.Outbox.BatchSize,
+		RetryMin:     cfg.Outbox.RetryMin,
+		RetryMax:     cfg.Outbox.RetryMax,
+	})
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		relay.Run(ctx)
+	}()
+
+	if cfg.Webhooks.Enabled {
+		sender := service.NewWebhookSender(store.hooks, service.WebhookSenderConfig{
+			PollInterval: cfg.Webhooks.PollInterval,
+			BatchSize:    cfg.Webhooks.BatchSize,
+			Timeout:      cfg.Webhooks.Timeout,
+			MaxAttempts:  cfg.Webhooks.MaxAttempts,
+			RetryMin:     cfg.Webhooks.RetryMin,
+			RetryMax:     cfg.Webhooks.RetryMax,
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sender.Run(ctx)
+		}()
+	}
+
+	return func() {
+		cancel()
+		wg.Wait()
+		if closer != nil {
+			if err := closer.Close(); err != nil {
+				logger.Error(context.Background(), "outbox: sink close failed", err, nil)
+			}
+		}
+	}, nil
+}
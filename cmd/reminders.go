@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testingtask/internal/config"
+	"testingtask/internal/domain/reminder"
+	"testingtask/internal/notify"
+	"testingtask/internal/service"
+)
+
+// newReminderService собирает сервис напоминаний с каналами, доступными
+// по конфигу.
+func newReminderService(cfg *config.Config, store *storage) service.ReminderService {
+	rc := cfg.Reminders
+	notifiers := map[string]notify.Notifier{
+		reminder.ChannelLog:     notify.NewLogNotifier(),
+		reminder.ChannelWebhook: notify.NewWebhookNotifier(rc.WebhookTimeout),
+	}
+	if rc.SMTP.Host != "" {
+		notifiers[reminder.ChannelEmail] = notify.NewSMTPNotifier(notify.SMTPConfig{
+			Host:     rc.SMTP.Host,
+			Port:     rc.SMTP.Port,
+			Username: rc.SMTP.Username,
+			Password: rc.SMTP.Password,
+			From:     rc.SMTP.From,
+			Timeout:  rc.SMTP.Timeout,
+		})
+	}
+
+	return service.NewReminderService(store.subRepo, store.reminders, store.users, store.tx, notifiers, service.ReminderConfig{
+		DefaultDaysBefore: rc.DefaultDaysBefore,
+		Lease:             rc.Lease,
+	})
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testingtask/internal/cache"
+	"testingtask/internal/config"
+	"testingtask/internal/health"
+
+	"testingtask/internal/delivery/http/middleware"
+	v1 "testingtask/internal/delivery/http/v1"
+	"testingtask/internal/service"
+	"testingtask/internal/telemetry"
+	"testingtask/internal/web/analytics"
+	"testingtask/internal/web/budgets"
+	"testingtask/internal/web/jobs"
+	"testingtask/internal/web/reminders"
+	"testingtask/internal/web/services"
+	"testingtask/internal/web/subscriptions"
+	"testingtask/internal/web/users"
+	"testingtask/internal/web/webhooks"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	echomw "github.com/labstack/echo/v4/middleware"
+	echoSwagger "github.com/swaggo/echo-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+)
+
+func runServer(ctx context.Context, stop context.CancelFunc, cfg *config.Config) error {
+	e := echo.New()
+
+	shutdownTracing, err := telemetry.Init(ctx, cfg.Tracing.Exporter, cfg.Tracing.SampleRatio)
+	if err != nil {
+		return fmt.Errorf("init tracing: %w", err)
+	}
+
+	e.HideBanner = true
+	e.HTTPErrorHandler = middleware.HTTPErrorHandler
+
+	e.Server.ReadTimeout = cfg.Server.ReadTimeout
+	e.Server.ReadHeaderTimeout = cfg.Server.ReadHeaderTimeout
+	e.Server.WriteTimeout = cfg.Server.WriteTimeout
+	e.Server.IdleTimeout = cfg.Server.IdleTimeout
+
+	e.Use(otelecho.Middleware(telemetry.ServiceName))
+	e.Use(middleware.RequestLoggerMiddleware)
+	e.Use(echomw.BodyLimit(cfg.Server.BodyLimit))
+
+	store, err := openStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	e.GET("/ping", func(c echo.Context) error {
+		return c.String(http.StatusOK, "pong")
+	})
+
+	checker := health.NewChecker(cfg.Health.CheckTimeout, store.checks...)
+	healthHandler := v1.NewHealthHandler(checker)
+
+	e.GET("/healthz", healthHandler.Liveness)
+	e.GET("/readyz", healthHandler.Readiness)
+
+	if cfg.Features.Swagger {
+		e.GET("/swagger/*", echoSwagger.WrapHandler)
+	}
+
+	router := e.Group("/api")
+	if cfg.Auth.Enabled {
+		router.Use(middleware.APIKeyAuth(cfg.Auth.APIKeys))
+	}
+	if len(cfg.Database.Replicas.URLs) > 0 {
+		router.Use(middleware.ReadYourWrites(cfg.Database.Replicas.ReadYourWritesWindow))
+	}
+
+	// Кэш сервиса подписок и аналитики, nil — кэш выключен.
+	var lru cache.Cache
+	if cfg.Cache.Backend == cache.BackendMemory {
+		lru = cache.NewLRU(cfg.Cache.Size)
+	}
+
+	subService := service.NewSubService(store.subRepo, store.events, store.tx, cfg.Database.ReportIsolationLevel())
+	if cfg.Budgets.Enabled {
+		subService = service.NewBudgetedSubService(subService, store.subRepo, store.budgets, store.catalog, store.events, store.tx)
+		budgetHandler := v1.NewBudgetHandler(service.NewBudgetService(store.budgets, store.subRepo, store.catalog, store.users, store.tx, cfg.Database.ReportIsolationLevel()))
+		budgets.RegisterHandlers(router, budgets.NewStrictHandler(budgetHandler, nil))
+	}
+	if cfg.Cache.Backend == cache.BackendMemory {
+		cached := service.NewCachedSubService(subService, lru, service.CacheTTL{
+			Get: cfg.Cache.GetTTL,
+			Sum: cfg.Cache.SumTTL,
+		})
+		subService = cached
+		// Статистика выдаёт, какие операции вызывают, поэтому закрыта
+		// тем же ключом, что и API.
+		router.GET("/debug/cache", v1.NewCacheHandler(cached).Stats)
+	}
+	// Снаружи кэша: он видит уже канонические имена сервисов.
+	subService = service.NewCatalogSubService(subService, store.catalog)
+	subService = service.NewUserSubService(subService, store.users, store.tx)
+	userService := service.NewUserService(store.users, subService, store.tx, store.hooks, store.budgets, store.reminders)
+	users.RegisterHandlers(router, users.NewStrictHandler(v1.NewUserHandler(userService), nil))
+	catalogService := service.NewCatalogService(store.catalog, store.subRepo, subService, store.tx)
+	services.RegisterHandlers(router, services.NewStrictHandler(v1.NewCatalogHandler(catalogService), nil))
+
+	subHandler := v1.NewSubHandler(subService)
+
+	subStrictHandler := subscriptions.NewStrictHandler(subHandler, nil)
+	subscriptions.RegisterHandlers(router, subStrictHandler)
+
+	analyticsService := service.NewAnalyticsService(store.analytics, store.tx, cfg.Database.ReportIsolationLevel(), lru, cfg.Cache.AnalyticsTTL)
+	analytics.RegisterHandlers(router, analytics.NewStrictHandler(v1.NewAnalyticsHandler(analyticsService), nil))
+
+	if cfg.Webhooks.Enabled {
+		webhookHandler := v1.NewWebhookHandler(service.NewWebhookService(store.hooks, store.users))
+		webhooks.RegisterHandlers(router, webhooks.NewStrictHandler(webhookHandler, nil))
+	}
+
+	reminderService := newReminderService(cfg, store)
+	if cfg.Reminders.Enabled {
+		reminderHandler := v1.NewReminderHandler(reminderService)
+		reminders.RegisterHandlers(router, reminders.NewStrictHandler(reminderHandler, nil))
+	}
+
+	sched, err := newScheduler(cfg, store, subService, reminderService)
+	if err != nil {
+		_ = store.close()
+		return fmt.Errorf("init scheduler: %w", err)
+	}
+	jobs.RegisterHandlers(router, jobs.NewStrictHandler(v1.NewJobHandler(sched), nil))
+
+	stopOutbox, err := startOutbox(ctx, cfg, store)
+	if err != nil {
+		_ = store.close()
+		return fmt.Errorf("start outbox: %w", err)
+	}
+	stopScheduler := startScheduler(ctx, sched)
+
+	port := cfg.Server.Addr()
+
+	go func() {
+		if err := e.Start(port); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error(ctx, "failed to start server", err, nil)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info(context.Background(), "shutting down", nil)
+
+	// Сначала /readyz начинает отдавать 503, чтобы балансировщик успел
+	// убрать инстанс, затем сервер перестаёт принимать соединения и
+	// дожидается текущих запросов.
+	checker.SetShuttingDown()
+	time.Sleep(cfg.Server.ShutdownDrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		logger.Error(shutdownCtx, "server shutdown failed", err, nil)
+	}
+
+	// Планировщик дожидается текущих запусков и отдаёт лидерство другому
+	// экземпляру. Relay останавливается после сервера и задач: события
+	// последних запросов уже в outbox и уйдут при следующем запуске, если
+	// не успели сейчас.
+	stopScheduler()
+	stopOutbox()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error(shutdownCtx, "tracing shutdown failed", err, nil)
+	}
+
+	if err := store.close(); err != nil {
+		logger.Error(shutdownCtx, "database close failed", err, nil)
+	}
+
+	logger.Info(shutdownCtx, "server stopped", nil)
+	_ = logger.Sync()
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testingtask/internal/config"
+	"testingtask/internal/database"
+	"testingtask/internal/health"
+	"testingtask/internal/repository"
+	logger "testingtask/pkg"
+)
+
+// storage — выбранное по DATABASE_URL хранилище вместе с его проверками
+// готовности.
+type storage struct {
+	subRepo   repository.SubRepository
+	events    repository.OutboxRepository
+	hooks     repository.WebhookRepository
+	reminders repository.ReminderRepository
+	budgets   repository.BudgetRepository
+	analytics repository.AnalyticsRepository
+	catalog   repository.CatalogRepository
+	users     repository.UserRepository
+	jobs      repository.JobRepository
+	tx        repository.TxManager
+	checks    []health.Check
+	close     func() error
+}
+
+func openStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+	if database.IsMemory(cfg.Database.URL) {
+		logger.Warn(ctx, "using in-memory storage, data is lost on restart", nil)
+		events := repository.NewMemoryOutboxRepository()
+		if !cfg.RecordsEvents() {
+			events = repository.NewNoOutboxRepository()
+		}
+		subRepo := repository.NewMemorySubRepository()
+		return &storage{
+			subRepo:   subRepo,
+			events:    events,
+			hooks:     repository.NewMemoryWebhookRepository(),
+			reminders: repository.NewMemoryReminderRepository(),
+			budgets:   repository.NewMemoryBudgetRepository(),
+			analytics: repository.NewMemoryAnalyticsRepository(subRepo),
+			catalog:   repository.NewMemoryCatalogRepository(),
+			users:     repository.NewMemoryUserRepository(),
+			jobs:      repository.NewMemoryJobRepository(),
+			tx:        repository.NewNoTxManager(),
+			close:     func() error { return nil },
+		}, nil
+	}
+
+	db, err := database.InitDB(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("init database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get sql.DB: %w", err)
+	}
+
+	m, err := database.NewMigrator(db)
+	if err != nil {
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+
+	// Не обслуживаем запросы на схеме, которая не совпадает с кодом.
+	if err := m.Check(ctx); err != nil {
+		_ = database.Close(db)
+		return nil, fmt.Errorf("schema check: %w", err)
+	}
+
+	checks := []health.Check{
+		health.NewDBCheck(sqlDB),
+		health.NewMigrationsCheck(sqlDB, m.Latest()),
+	}
+	// У SQLite одно соединение, заполненность пула ничего не говорит.
+	if !database.IsSQLite(cfg.Database.URL) {
+		checks = append(checks, health.NewPoolCheck(sqlDB, cfg.Database.Pool.SaturationThreshold))
+	}
+
+	subRepo := repository.NewSubRepository(db)
+	tx := repository.NewTxManager(db)
+	events := repository.NewOutboxRepository(db)
+	if !cfg.RecordsEvents() {
+		events = repository.NewNoOutboxRepository()
+	}
+	closeFn := func() error { return database.Close(db) }
+
+	if cfg.Database.Repository == config.RepositoryPgx {
+		pool, err := database.InitPgxPool(ctx, cfg, repository.PreparePgxStatements)
+		if err != nil {
+			_ = database.Close(db)
+			return nil, fmt.Errorf("init pgx pool: %w", err)
+		}
+
+		// Чтение идёт через pgx, запись остаётся на GORM.
+		subRepo = repository.NewPgxSubRepository(pool, subRepo)
+		tx = repository.NewPgxTxManager(tx)
+		closeFn = func() error {
+			pool.Close()
+			return database.Close(db)
+		}
+	}
+
+	return &storage{
+		subRepo:   subRepo,
+		events:    events,
+		hooks:     repository.NewWebhookRepository(db),
+		reminders: repository.NewReminderRepository(db),
+		budgets:   repository.NewBudgetRepository(db),
+		analytics: repository.NewAnalyticsRepository(db),
+		catalog:   repository.NewCatalogRepository(db),
+		users:     repository.NewUserRepository(db),
+		jobs:      repository.NewJobRepository(db),
+		tx:        tx,
+		checks:    checks,
+		close:     closeFn,
+	}, nil
+}
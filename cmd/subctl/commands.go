@@ -0,0 +1,565 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/readpref"
+
+	"github.com/google/uuid"
+)
+
+const exportPageSize = 500
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// optString — флаг, для которого важно, был ли он указан.
+type optString struct {
+	value string
+	set   bool
+}
+
+func (o *optString) String() string { return o.value }
+
+func (o *optString) Set(v string) error {
+	o.value, o.set = v, true
+	return nil
+}
+
+func (o *optString) ptr() *string {
+	if !o.set {
+		return nil
+	}
+	return &o.value
+}
+
+// optInt — числовой флаг, для которого важно, был ли он указан: явный 0
+// отличается от отсутствия флага.
+type optInt struct {
+	value int
+	set   bool
+}
+
+func (o *optInt) String() string { return strconv.Itoa(o.value) }
+
+func (o *optInt) Set(v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", v)
+	}
+	o.value, o.set = n, true
+	return nil
+}
+
+// multiString — повторяемый флаг: -tag work -tag family.
+type multiString []string
+
+func (m *multiString) String() string { return strings.Join(*m, ",") }
+
+func (m *multiString) Set(v string) error {
+	*m = append(*m, v)
+	return nil
+}
+
+// labelFlags — флаги фильтра по тегам и metadata у list и sum.
+type labelFlags struct {
+	tags, metadata multiString
+	match          optString
+}
+
+func addLabelFlags(fs *flag.FlagSet) *labelFlags {
+	l := &labelFlags{}
+	fs.Var(&l.tags, "tag", "tag filter, repeatable")
+	fs.Var(&l.match, "tag-match", "any: at least one tag, all: every tag (default any)")
+	fs.Var(&l.metadata, "metadata", "metadata filter key or key:value, repeatable")
+	return l
+}
+
+func (l *labelFlags) filter() (domain.LabelFilter, error) {
+	return domain.NewLabelFilter(l.tags, l.match.ptr(), l.metadata)
+}
+
+// parseMeta разбирает пары key:value флага -meta в строковые значения
+// metadata.
+func parseMeta(pairs []string) (map[string]any, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	res := make(map[string]any, len(pairs))
+	for _, p := range pairs {
+		k, v, ok := strings.Cut(p, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid --meta %q, expected key:value", p)
+		}
+		res[k] = v
+	}
+	return res, nil
+}
+
+func parseID(args []string) (uuid.UUID, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return uuid.Nil, nil, errors.New("ID argument required")
+	}
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("invalid id %q", args[0])
+	}
+	return id, args[1:], nil
+}
+
+func runCreate(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("create")
+	var r record
+	var user, end optString
+	fs.StringVar(&r.ServiceName, "service", "", "service name")
+	fs.IntVar(&r.Price, "price", 0, "monthly price")
+	fs.Var(&user, "user", "user ID")
+	fs.StringVar(&r.StartDate, "start", "", "start date MM-YYYY")
+	fs.Var(&end, "end", "end date MM-YYYY")
+	var tags, meta multiString
+	fs.Var(&tags, "tag", "tag, repeatable")
+	fs.Var(&meta, "meta", "metadata key:value, repeatable")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	uid, err := uuid.Parse(user.value)
+	if err != nil {
+		return fmt.Errorf("invalid --user %q", user.value)
+	}
+	r.UserID = uid
+	r.EndDate = end.ptr()
+	r.Tags = tags
+	if r.Metadata, err = parseMeta(meta); err != nil {
+		return err
+	}
+
+	sub, err := r.toDomain()
+	if err != nil {
+		return err
+	}
+
+	id, err := a.svc.Create(ctx, sub)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(a.out, id)
+	return err
+}
+
+func runGet(ctx context.Context, a *app, args []string) error {
+	id, rest, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	fs := newFlagSet("get")
+	format := fs.String("o", formatTable, "output format: table, json, csv")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	if err := validFormat(*format); err != nil {
+		return err
+	}
+
+	sub, err := a.svc.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		enc := json.NewEncoder(a.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(toRecord(sub))
+	}
+	return printRecords(a.out, *format, []record{toRecord(sub)})
+}
+
+func runList(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("list")
+	format := fs.String("o", formatTable, "output format: table, json, csv")
+	limit := fs.Int("limit", 10, "page size")
+	offset := fs.Int("offset", 0, "page offset")
+	all := fs.Bool("all", false, "list all subscriptions")
+	labels := addLabelFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := validFormat(*format); err != nil {
+		return err
+	}
+	filter, err := labels.filter()
+	if err != nil {
+		return err
+	}
+
+	var subs []*domain.Subscription
+	if *all {
+		if subs, err = listAll(ctx, a, filter); err != nil {
+			return err
+		}
+	} else {
+		page, _, err := a.svc.List(ctx, filter, domain.NewPagingBase(*limit, *offset))
+		if err != nil {
+			return err
+		}
+		subs = page
+	}
+
+	return printRecords(a.out, *format, toRecords(subs))
+}
+
+func runSum(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("sum")
+	format := fs.String("o", formatTable, "output format: table, json, csv")
+	var user, service, start, end optString
+	fs.Var(&user, "user", "user ID")
+	fs.Var(&service, "service", "service name")
+	fs.Var(&start, "start", "start date MM-YYYY")
+	fs.Var(&end, "end", "end date MM-YYYY")
+	limit := fs.Int("limit", 10, "rows page size")
+	offset := fs.Int("offset", 0, "rows page offset")
+	labels := addLabelFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := validFormat(*format); err != nil {
+		return err
+	}
+
+	var userID *uuid.UUID
+	if user.set {
+		uid, err := uuid.Parse(user.value)
+		if err != nil {
+			return fmt.Errorf("invalid --user %q", user.value)
+		}
+		userID = &uid
+	}
+
+	filter, err := domain.NewSubscriptionFilter(userID, service.ptr(), start.ptr(), end.ptr(), *limit, *offset)
+	if err != nil {
+		return err
+	}
+	if filter.Labels, err = labels.filter(); err != nil {
+		return err
+	}
+
+	res, err := a.svc.Sum(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	return printSum(a.out, *format, res)
+}
+
+func runForecast(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("forecast")
+	format := fs.String("o", formatTable, "output format: table, json, csv")
+	months := fs.Int("months", 12, "months to forecast, starting with the current one")
+	groupBy := fs.String("group-by", "", "split into series: user or service")
+	var user, service optString
+	fs.Var(&user, "user", "user ID")
+	fs.Var(&service, "service", "service name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := validFormat(*format); err != nil {
+		return err
+	}
+
+	filter := &domain.SubscriptionFilter{ServiceName: service.ptr()}
+	if user.set {
+		uid, err := uuid.Parse(user.value)
+		if err != nil {
+			return fmt.Errorf("invalid --user %q", user.value)
+		}
+		filter.UserID = &uid
+	}
+
+	f, err := a.svc.Forecast(ctx, filter, *months, domain.ForecastGroup(*groupBy))
+	if err != nil {
+		return err
+	}
+
+	return printForecast(a.out, *format, f)
+}
+
+func runUpdate(ctx context.Context, a *app, args []string) error {
+	id, rest, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	fs := newFlagSet("update")
+	var service, user, start, end optString
+	var price optInt
+	fs.Var(&price, "price", "monthly price")
+	fs.Var(&service, "service", "service name")
+	fs.Var(&user, "user", "user ID")
+	fs.Var(&start, "start", "start date MM-YYYY")
+	fs.Var(&end, "end", "end date MM-YYYY")
+	noEnd := fs.Bool("no-end", false, "remove end date")
+	var tags, meta multiString
+	fs.Var(&tags, "tag", "tag, repeatable; replaces all tags")
+	fs.Var(&meta, "meta", "metadata key:value, repeatable; sets the key")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+
+	// Патч накладывается на актуальную версию с primary, а не на
+	// возможно отстающую реплику.
+	existing, err := a.svc.Get(readpref.WithPrimary(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	// Меняем только переданные поля.
+	r := toRecord(existing)
+	if service.set {
+		r.ServiceName = service.value
+	}
+	if price.set {
+		r.Price = price.value
+	}
+	if user.set {
+		if r.UserID, err = uuid.Parse(user.value); err != nil {
+			return fmt.Errorf("invalid --user %q", user.value)
+		}
+	}
+	if start.set {
+		r.StartDate = start.value
+	}
+	if end.set {
+		r.EndDate = end.ptr()
+	}
+	if *noEnd {
+		r.EndDate = nil
+	}
+	if len(tags) > 0 {
+		r.Tags = tags
+	}
+	pairs, err := parseMeta(meta)
+	if err != nil {
+		return err
+	}
+	if len(pairs) > 0 {
+		merged := make(map[string]any, len(r.Metadata)+len(pairs))
+		for k, v := range r.Metadata {
+			merged[k] = v
+		}
+		for k, v := range pairs {
+			merged[k] = v
+		}
+		r.Metadata = merged
+	}
+
+	sub, err := r.restore()
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.svc.Update(ctx, id, sub); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(a.out, id)
+	return err
+}
+
+func runDelete(ctx context.Context, a *app, args []string) error {
+	id, _, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	if err := a.svc.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(a.out, "deleted %s\n", id)
+	return err
+}
+
+func runDeleteUser(ctx context.Context, a *app, args []string) error {
+	userID, rest, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	fs := newFlagSet("delete-user")
+	yes := fs.Bool("yes", false, "confirm deletion of all user data")
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	if !*yes {
+		return errors.New("refusing to delete all user data without --yes")
+	}
+
+	n, err := a.users.Delete(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(a.out, "deleted user %s with %d subscription(s), webhooks, budgets and reminder preferences\n", userID, n)
+	return err
+}
+
+func runImport(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("import")
+	file := fs.String("file", "", "input file, - for stdin")
+	format := fs.String("format", "", "csv or json (default: by file extension)")
+	keepGoing := fs.Bool("continue-on-error", false, "skip invalid rows instead of stopping")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("--file is required")
+	}
+
+	f := *format
+	if f == "" {
+		f = strings.TrimPrefix(filepath.Ext(*file), ".")
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		fh, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		in = fh
+	}
+
+	recs, err := readRecords(in, f)
+	if err != nil {
+		return err
+	}
+
+	imported, failed := 0, 0
+	for i, r := range recs {
+		err := importRecord(ctx, a, r)
+		if err == nil {
+			imported++
+			continue
+		}
+
+		failed++
+		fmt.Fprintf(os.Stderr, "row %d: %v\n", i+1, err)
+		if !*keepGoing {
+			break
+		}
+	}
+
+	fmt.Fprintf(a.out, "imported %d, failed %d\n", imported, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d row(s) failed", failed)
+	}
+	return nil
+}
+
+func importRecord(ctx context.Context, a *app, r record) error {
+	sub, err := r.restore()
+	if err != nil {
+		return err
+	}
+	_, err = a.svc.Create(ctx, sub)
+	return err
+}
+
+func readRecords(in io.Reader, format string) ([]record, error) {
+	switch format {
+	case formatJSON:
+		var recs []record
+		if err := json.NewDecoder(in).Decode(&recs); err != nil {
+			return nil, fmt.Errorf("decode json: %w", err)
+		}
+		return recs, nil
+
+	case formatCSV:
+		rows, err := csv.NewReader(in).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		if len(rows) > 0 && rows[0][0] == csvHeader[0] {
+			rows = rows[1:]
+		}
+
+		recs := make([]record, 0, len(rows))
+		for i, row := range rows {
+			r, err := recordFromCSV(row)
+			if err != nil {
+				return nil, fmt.Errorf("csv row %d: %w", i+1, err)
+			}
+			recs = append(recs, r)
+		}
+		return recs, nil
+	}
+
+	return nil, fmt.Errorf("unsupported import format %q, use csv or json", format)
+}
+
+func runExport(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("export")
+	file := fs.String("file", "-", "output file, - for stdout")
+	format := fs.String("format", formatJSON, "csv or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != formatJSON && *format != formatCSV {
+		return fmt.Errorf("unsupported export format %q, use csv or json", *format)
+	}
+
+	subs, err := listAll(ctx, a, domain.LabelFilter{})
+	if err != nil {
+		return err
+	}
+
+	out := a.out
+	if *file != "-" {
+		fh, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		out = fh
+	}
+
+	if err := printRecords(out, *format, toRecords(subs)); err != nil {
+		return err
+	}
+
+	if *file != "-" {
+		fmt.Fprintf(os.Stderr, "exported %d subscription(s) to %s\n", len(subs), *file)
+	}
+	return nil
+}
+
+func listAll(ctx context.Context, a *app, labels domain.LabelFilter) ([]*domain.Subscription, error) {
+	var res []*domain.Subscription
+
+	for offset := 0; ; offset += exportPageSize {
+		page, _, err := a.svc.List(ctx, labels, domain.NewPagingBase(exportPageSize, offset))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, page...)
+		if len(page) < exportPageSize {
+			return res, nil
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository"
+	"testingtask/internal/service"
+
+	"github.com/google/uuid"
+)
+
+func newTestApp() (*app, *bytes.Buffer) {
+	var out bytes.Buffer
+	tx := repository.NewNoTxManager()
+	svc := service.NewSubService(repository.NewMemorySubRepository(), repository.NewNoOutboxRepository(), tx, sql.LevelDefault)
+	users := service.NewUserService(repository.NewMemoryUserRepository(), svc, tx, repository.NewMemoryBudgetRepository())
+	return &app{svc: svc, users: users, out: &out}, &out
+}
+
+// pastSub заводит подписку, начавшуюся до текущего месяца: через API такую
+// уже не создать, но в базе они есть.
+func pastSub(t *testing.T, a *app) *domain.Subscription {
+	t.Helper()
+	start, _ := domain.ParseSubDate("01-2020")
+	end, _ := domain.ParseSubDate("12-2020")
+	sub := domain.RestoreSubscription(uuid.New(), "Netflix", 400, uuid.New(), *start, end)
+	if err := sub.SetLabels([]string{"family"}, map[string]any{"plan": "basic"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.svc.Create(context.Background(), sub); err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+func TestUpdatePastSubscription(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestApp()
+	sub := pastSub(t, a)
+	id := sub.ID().String()
+
+	if err := runUpdate(ctx, a, []string{id, "--price", "500", "--service", "Netflix Premium"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := a.svc.Get(ctx, sub.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Price() != 500 || got.ServiceName() != "Netflix Premium" || got.StartDateStr() != "01-2020" || *got.EndDateStr() != "12-2020" {
+		t.Fatalf("after update: %+v", toRecord(got))
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		want error
+	}{
+		{"explicit zero price", []string{"--price", "0"}, domain.ErrInvalidPrice},
+		{"negative price", []string{"--price", "-1"}, domain.ErrInvalidPrice},
+		{"empty name", []string{"--service", ""}, domain.ErrEmptyServiceName},
+		{"end before start", []string{"--end", "12-2019"}, domain.ErrCompareDate},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := runUpdate(ctx, a, append([]string{id}, tc.args...)); !errors.Is(err, tc.want) {
+				t.Fatalf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, format := range []string{formatJSON, formatCSV} {
+		t.Run(format, func(t *testing.T) {
+			ctx := context.Background()
+			src, _ := newTestApp()
+			sub := pastSub(t, src)
+
+			file := filepath.Join(t.TempDir(), "subs."+format)
+			if err := runExport(ctx, src, []string{"--file", file, "--format", format}); err != nil {
+				t.Fatalf("export: %v", err)
+			}
+
+			dst, out := newTestApp()
+			if err := runImport(ctx, dst, []string{"--file", file}); err != nil {
+				t.Fatalf("import: %v (%s)", err, out)
+			}
+			got, err := dst.svc.Get(ctx, sub.ID())
+			if err != nil {
+				t.Fatalf("Get imported: %v", err)
+			}
+
+			want := toRecord(sub)
+			if format == formatCSV {
+				// В CSV нет колонок для тегов и metadata.
+				want.Tags, want.Metadata = nil, nil
+			}
+			if rec := toRecord(got); !reflect.DeepEqual(rec, want) {
+				t.Fatalf("imported %+v, want %+v", rec, want)
+			}
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	a, out := newTestApp()
+	u, err := a.users.Create(ctx, service.UserInput{DisplayName: "Bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, _ := domain.ParseSubDate("01-2020")
+	sub := domain.RestoreSubscription(uuid.New(), "Netflix", 400, u.ID(), *start, nil)
+	if _, err := a.svc.Create(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runDeleteUser(ctx, a, []string{u.ID().String()}); err == nil {
+		t.Fatal("delete-user without --yes succeeded")
+	}
+	if err := runDeleteUser(ctx, a, []string{u.ID().String(), "--yes"}); err != nil {
+		t.Fatalf("delete-user: %v", err)
+	}
+	if _, err := a.users.Get(ctx, u.ID()); !errors.Is(err, myerrors.ErrUserNotFound) {
+		t.Fatalf("user after delete-user: got %v, want ErrUserNotFound", err)
+	}
+	if _, err := a.svc.Get(ctx, sub.ID()); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("subscription after delete-user: got %v, want ErrNotFound", err)
+	}
+	if !bytes.Contains(out.Bytes(), []byte("with 1 subscription(s)")) {
+		t.Fatalf("output: %q", out.String())
+	}
+}
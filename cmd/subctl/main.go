@@ -0,0 +1,160 @@
+// subctl — административный CLI для подписок. Работает напрямую
+// через service.SubService, без HTTP и без кэша сервера: запущенный
+// сервер увидит изменения по истечении cache.get_ttl и cache.sum_ttl.
+//
+//	subctl [config flags] <command> [command flags]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"testingtask/internal/config"
+	"testingtask/internal/database"
+	"testingtask/internal/repository"
+	"testingtask/internal/service"
+	logger "testingtask/pkg"
+)
+
+type app struct {
+	svc service.SubService
+	// users удаляет пользователя со всеми его данными (delete-user).
+	users service.UserService
+	out   io.Writer
+}
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"create":      {"create --user ID --service NAME --price N --start MM-YYYY [--end MM-YYYY]", runCreate},
+	"get":         {"get ID", runGet},
+	"list":        {"list [--limit N --offset N | --all]", runList},
+	"sum":         {"sum [--user ID] [--service NAME] [--start MM-YYYY] [--end MM-YYYY] [--limit N --offset N]", runSum},
+	"forecast":    {"forecast [--months N] [--user ID] [--service NAME] [--group-by user|service]", runForecast},
+	"update":      {"update ID [--service NAME] [--price N] [--user ID] [--start MM-YYYY] [--end MM-YYYY|--no-end]", runUpdate},
+	"delete":      {"delete ID", runDelete},
+	"delete-user": {"delete-user USER_ID --yes", runDeleteUser},
+	"import":      {"import --file PATH [--format csv|json] [--continue-on-error]", runImport},
+	"export":      {"export [--file PATH] [--format csv|json]", runExport},
+}
+
+func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		usage(os.Stdout)
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+
+	if len(cfg.Args) == 0 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	name, args := cfg.Args[0], cfg.Args[1:]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	// stdout занят результатом команды, логи пишем в stderr.
+	logger.InitWithWriter(os.Stderr, cfg.Logging.Pretty)
+	if err := logger.SetLevel(cfg.Logging.Level); err != nil {
+		panic("Failed to set log level: " + err.Error())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg, cmd, args); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, cfg *config.Config, cmd command, args []string) error {
+	a := &app{out: os.Stdout}
+
+	// С memory:// данные живут только в рамках одного вызова, что
+	// полезно разве что для проверки import-файлов.
+	if database.IsMemory(cfg.Database.URL) {
+		tx := repository.NewNoTxManager()
+		a.svc = service.NewSubService(repository.NewMemorySubRepository(), repository.NewNoOutboxRepository(), tx, cfg.Database.ReportIsolationLevel())
+		a.users = service.NewUserService(repository.NewMemoryUserRepository(), a.svc, tx)
+		return cmd.run(ctx, a, args)
+	}
+
+	db, err := database.InitDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = database.Close(db) }()
+
+	m, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	if err := m.Check(ctx); err != nil {
+		return fmt.Errorf("schema check: %w", err)
+	}
+
+	// События пишутся в outbox, их отправит relay запущенного сервера.
+	events := repository.NewOutboxRepository(db)
+	if !cfg.RecordsEvents() {
+		events = repository.NewNoOutboxRepository()
+	}
+	tx := repository.NewTxManager(db)
+	subs := repository.NewSubRepository(db)
+	catalog := repository.NewCatalogRepository(db)
+	budgets := repository.NewBudgetRepository(db)
+	a.svc = service.NewSubService(subs, events, tx, cfg.Database.ReportIsolationLevel())
+	// Бюджеты проверяются так же, как в HTTP API: hard_limit отклоняет
+	// create, update и строки import, превышение пишет BudgetExceeded.
+	if cfg.Budgets.Enabled {
+		a.svc = service.NewBudgetedSubService(a.svc, subs, budgets, catalog, events, tx)
+	}
+	// Импорт и фильтры сводят названия к каталогу так же, как HTTP API.
+	a.svc = service.NewCatalogSubService(a.svc, catalog)
+	// Владельцы подписок должны быть заведены через API.
+	users := repository.NewUserRepository(db)
+	a.svc = service.NewUserSubService(a.svc, users, tx)
+	a.users = service.NewUserService(users, a.svc, tx,
+		repository.NewWebhookRepository(db), budgets, repository.NewReminderRepository(db))
+
+	return cmd.run(ctx, a, args)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: subctl [config flags] <command> [command flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", commands[name].usage)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Output: -o table|json|csv (get, list, sum).")
+	fmt.Fprintln(w)
+	config.Usage(w)
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	domain "testingtask/internal/domain/subscription"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+var csvHeader = []string{"id", "service_name", "price", "user_id", "start_date", "end_date"}
+
+// record — представление подписки для вывода, импорта и экспорта.
+// Поля и формат дат совпадают с HTTP API. Теги и metadata есть только
+// в JSON: в CSV и таблице их колонок нет.
+type record struct {
+	ID          uuid.UUID      `json:"id"`
+	ServiceName string         `json:"service_name"`
+	Price       int            `json:"price"`
+	UserID      uuid.UUID      `json:"user_id"`
+	StartDate   string         `json:"start_date"`
+	EndDate     *string        `json:"end_date,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+}
+
+func toRecord(s *domain.Subscription) record {
+	return record{
+		ID:          s.ID(),
+		ServiceName: s.ServiceName(),
+		Price:       s.Price(),
+		UserID:      s.UserID(),
+		StartDate:   s.StartDateStr(),
+		EndDate:     s.EndDateStr(),
+		Tags:        s.Tags(),
+		Metadata:    s.Metadata(),
+	}
+}
+
+func toRecords(list []*domain.Subscription) []record {
+	res := make([]record, 0, len(list))
+	for _, s := range list {
+		res = append(res, toRecord(s))
+	}
+	return res
+}
+
+func (r record) dates() (*domain.SubDate, *domain.SubDate, error) {
+	start, err := domain.ParseSubDate(r.StartDate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var end *domain.SubDate
+	if r.EndDate != nil && *r.EndDate != "" {
+		if end, err = domain.ParseSubDate(*r.EndDate); err != nil {
+			return nil, nil, err
+		}
+	}
+	return start, end, nil
+}
+
+// toDomain собирает новую подписку: даты не могут быть в прошлом.
+func (r record) toDomain() (*domain.Subscription, error) {
+	start, end, err := r.dates()
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := domain.NewSubscription(r.ID, r.ServiceName, domain.Price(r.Price), r.UserID, *start, end)
+	if err != nil {
+		return nil, err
+	}
+	if err := sub.SetLabels(r.Tags, r.Metadata); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// restore собирает подписку для update и import. Это уже существующие
+// подписки, в т.ч. из старых выгрузок, поэтому прошедший месяц начала не
+// ошибка: проверяются только название, цена и порядок дат.
+func (r record) restore() (*domain.Subscription, error) {
+	start, end, err := r.dates()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.ServiceName == "" {
+		return nil, domain.ErrEmptyServiceName
+	}
+	if err := domain.Price(r.Price).Validate(); err != nil {
+		return nil, err
+	}
+	if end != nil && end.Before(start.Time) {
+		return nil, domain.ErrCompareDate
+	}
+
+	id := r.ID
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+	sub := domain.RestoreSubscription(id, r.ServiceName, domain.Price(r.Price), r.UserID, *start, end)
+	if err := sub.SetLabels(r.Tags, r.Metadata); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (r record) csvRow() []string {
+	end := ""
+	if r.EndDate != nil {
+		end = *r.EndDate
+	}
+	return []string{r.ID.String(), r.ServiceName, strconv.Itoa(r.Price), r.UserID.String(), r.StartDate, end}
+}
+
+func recordFromCSV(row []string) (record, error) {
+	if len(row) != len(csvHeader) {
+		return record{}, fmt.Errorf("expected %d columns, got %d", len(csvHeader), len(row))
+	}
+
+	var (
+		r   record
+		err error
+	)
+
+	if row[0] != "" {
+		if r.ID, err = uuid.Parse(row[0]); err != nil {
+			return record{}, fmt.Errorf("id: %w", err)
+		}
+	}
+	r.ServiceName = row[1]
+	if r.Price, err = strconv.Atoi(row[2]); err != nil {
+		return record{}, fmt.Errorf("price: %w", err)
+	}
+	if r.UserID, err = uuid.Parse(row[3]); err != nil {
+		return record{}, fmt.Errorf("user_id: %w", err)
+	}
+	r.StartDate = row[4]
+	if row[5] != "" {
+		end := row[5]
+		r.EndDate = &end
+	}
+
+	return r, nil
+}
+
+func validFormat(f string) error {
+	switch f {
+	case formatTable, formatJSON, formatCSV:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q", f)
+}
+
+func printRecords(w io.Writer, format string, recs []record) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(recs)
+
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, r := range recs {
+			if err := cw.Write(r.csvRow()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tSERVICE\tPRICE\tUSER\tSTART\tEND")
+		for _, r := range recs {
+			end := "-"
+			if r.EndDate != nil {
+				end = *r.EndDate
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", r.ID, r.ServiceName, r.Price, r.UserID, r.StartDate, end)
+		}
+		return tw.Flush()
+	}
+}
+
+type sumOutput struct {
+	TotalSum   int      `json:"total_sum"`
+	TotalCount int      `json:"total_count"`
+	Rows       []record `json:"rows"`
+}
+
+func printSum(w io.Writer, format string, res *domain.SumResult) error {
+	out := sumOutput{
+		TotalSum:   res.TotalSum,
+		TotalCount: res.TotalCount,
+		Rows:       toRecords(res.Rows),
+	}
+
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	case formatCSV:
+		return printRecords(w, format, out.Rows)
+	default:
+		if err := printRecords(w, format, out.Rows); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w, "\nTOTAL: %d\n", out.TotalSum)
+		return err
+	}
+}
+
+type forecastPoint struct {
+	Month  string `json:"month"`
+	Amount int    `json:"amount"`
+}
+
+type forecastSeries struct {
+	Key    string          `json:"key,omitempty"`
+	Total  int             `json:"total"`
+	Points []forecastPoint `json:"points"`
+}
+
+func toForecastSeries(s domain.ForecastSeries) forecastSeries {
+	res := forecastSeries{Key: s.Key, Total: s.Total, Points: make([]forecastPoint, 0, len(s.Points))}
+	for _, p := range s.Points {
+		res.Points = append(res.Points, forecastPoint{Month: p.Month.Format("01-2006"), Amount: p.Amount})
+	}
+	return res
+}
+
+// printForecast в table и csv выводит ряды строками, месяцы — колонками,
+// последняя строка — общий ряд TOTAL.
+func printForecast(w io.Writer, format string, f *domain.Forecast) error {
+	total := toForecastSeries(f.Total)
+	series := make([]forecastSeries, 0, len(f.Series))
+	for _, s := range f.Series {
+		series = append(series, toForecastSeries(s))
+	}
+
+	if format == formatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			From    string           `json:"from"`
+			Months  int              `json:"months"`
+			GroupBy string           `json:"group_by,omitempty"`
+			Total   forecastSeries   `json:"total"`
+			Series  []forecastSeries `json:"series"`
+		}{f.From.Format("01-2006"), f.Months, string(f.Group), total, series})
+	}
+
+	header := []string{"key"}
+	for _, p := range total.Points {
+		header = append(header, p.Month)
+	}
+	header = append(header, "total")
+
+	total.Key = "TOTAL"
+	rows := make([][]string, 0, len(series)+1)
+	for _, s := range append(series, total) {
+		row := []string{s.Key}
+		for _, p := range s.Points {
+			row = append(row, strconv.Itoa(p.Amount))
+		}
+		rows = append(rows, append(row, strconv.Itoa(s.Total)))
+	}
+
+	if format == formatCSV {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -15,53 +15,127 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/subscriptions": {
+        "/admin/jobs": {
             "get": {
-                "description": "Возвращает список подписок с пагинацией",
-                "consumes": [
+                "description": "Возвращает задачи планировщика с расписанием и последним запуском. Время следующего запуска знает только лидер — экземпляр, выполняющий задачи по расписанию",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Получить список фоновых задач",
+                "responses": {
+                    "200": {
+                        "description": "Список задач",
+                        "schema": {
+                            "$ref": "#/definitions/v1.JobListDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{name}/run": {
+            "post": {
+                "description": "Запускает задачу вне расписания на экземпляре, принявшем запрос, и сразу возвращает запись о запуске. Итог появляется в истории запусков",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "jobs"
                 ],
-                "summary": "Получить список подписок",
+                "summary": "Запустить задачу",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя задачи",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Запуск начат",
+                        "schema": {
+                            "$ref": "#/definitions/v1.JobRunDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Задача не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "409": {
+                        "description": "Задача уже выполняется",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{name}/runs": {
+            "get": {
+                "description": "Возвращает запуски задачи со всех экземпляров, новые первыми",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Получить историю запусков задачи",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя задачи",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
+                        "default": 10,
                         "description": "Количество элементов",
                         "name": "limit",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     },
                     {
                         "type": "integer",
+                        "default": 0,
                         "description": "Смещение",
                         "name": "offset",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список подписок",
+                        "description": "История запусков",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/v1.SubscriptionResponseDTO"
-                            }
+                            "$ref": "#/definitions/v1.ListJobRunsResponseDTO"
                         }
                     },
                     "400": {
-                        "description": "Некорректный ID",
+                        "description": "Некорректные параметры",
                         "schema": {
                             "$ref": "#/definitions/myerrors.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Подписка не найдена",
+                        "description": "Задача не найдена",
                         "schema": {
                             "$ref": "#/definitions/myerrors.ErrorNotFound"
                         }
@@ -73,39 +147,41 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Создаёт новую подписку и возвращает её идентификатор",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/analytics/churn": {
+            "get": {
+                "description": "Для каждого сервиса и месяца диапазона считает долю пользователей, подписанных на сервис в прошлом месяце, у которых подписки на него не осталось. Закончившиеся месяцы кэшируются",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "analytics"
                 ],
-                "summary": "Создать подписку",
+                "summary": "Получить отток по сервисам",
                 "parameters": [
                     {
-                        "description": "Данные для создания подписки",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/v1.SubscriptionDTO"
-                        }
+                        "type": "string",
+                        "description": "Первый месяц (MM-YYYY), по умолчанию за 11 месяцев до to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Последний месяц (MM-YYYY), по умолчанию текущий",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Подписка успешно создана",
+                    "200": {
+                        "description": "Отток по сервисам",
                         "schema": {
-                            "$ref": "#/definitions/v1.SubscriptionID"
+                            "$ref": "#/definitions/v1.ChurnReportDTO"
                         }
                     },
                     "400": {
-                        "description": "Некорректные данные",
+                        "description": "Некорректный диапазон",
                         "schema": {
                             "$ref": "#/definitions/myerrors.ErrorResponse"
                         }
@@ -119,75 +195,103 @@ const docTemplate = `{
                 }
             }
         },
-        "/subscriptions/sum": {
+        "/analytics/mrr": {
             "get": {
-                "description": "Возвращает суммарную стоимость всех подписок пользователя",
+                "description": "Считает MRR каждого месяца диапазона и его движение к предыдущему месяцу по клиентам (user_id): new, expansion, contraction и churned. Закончившиеся месяцы кэшируются",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "analytics"
                 ],
-                "summary": "Получить сумму стоимости подписок",
+                "summary": "Получить MRR по месяцам",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "user_id",
+                        "description": "Первый месяц (MM-YYYY), по умолчанию за 11 месяцев до to",
+                        "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Service name",
-                        "name": "service_name",
+                        "description": "Последний месяц (MM-YYYY), по умолчанию текущий",
+                        "name": "to",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Start date (MM-YYYY)",
-                        "name": "start",
+                        "description": "Только подписки на этот сервис",
+                        "name": "service_name",
                         "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "MRR по месяцам",
+                        "schema": {
+                            "$ref": "#/definitions/v1.MRRReportDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный диапазон",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
                     },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets": {
+            "get": {
+                "description": "Возвращает бюджеты всех пользователей или одного с пагинацией",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Получить список бюджетов",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "End date (MM-YYYY)",
-                        "name": "end",
+                        "description": "ID пользователя",
+                        "name": "user_id",
                         "in": "query"
                     },
                     {
                         "type": "integer",
                         "default": 10,
-                        "description": "Limit subscriptions for count price",
+                        "description": "Количество элементов",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "integer",
                         "default": 0,
-                        "description": "Offset subscriptions",
+                        "description": "Смещение",
                         "name": "offset",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Список бюджетов",
                         "schema": {
-                            "$ref": "#/definitions/v1.ListSubscriptionsResponseDto"
+                            "$ref": "#/definitions/v1.ListBudgetsResponseDTO"
                         }
                     },
                     "400": {
-                        "description": "Некорректный ID",
+                        "description": "Некорректные параметры",
                         "schema": {
                             "$ref": "#/definitions/myerrors.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Подписка не найдена",
-                        "schema": {
-                            "$ref": "#/definitions/myerrors.ErrorNotFound"
-                        }
-                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
@@ -195,11 +299,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/subscriptions/{id}": {
-            "get": {
-                "description": "Возвращает подписку по её идентификатору",
+            },
+            "post": {
+                "description": "Задаёт месячный лимит трат пользователя на все подписки, на один сервис (service_name) или на категорию каталога сервисов (category) — по подпискам, связанным с сервисом этой категории. У пользователя не больше одного бюджета на каждую из этих областей. С hard_limit подписки, выводящие траты за лимит, отклоняются с 409, без него только записывается событие BudgetExceeded",
                 "consumes": [
                     "application/json"
                 ],
@@ -207,35 +309,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "budgets"
                 ],
-                "summary": "Получить подписку по ID",
+                "summary": "Создать бюджет",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Пользователь, сервис или категория и лимит",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.BudgetRequestDTO"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Подписка найдена",
+                    "201": {
+                        "description": "Бюджет создан",
                         "schema": {
-                            "$ref": "#/definitions/v1.SubscriptionResponseDTO"
+                            "$ref": "#/definitions/v1.BudgetDTO"
                         }
                     },
                     "400": {
-                        "description": "Некорректный ID",
+                        "description": "Некорректные данные или неизвестный user_id",
                         "schema": {
                             "$ref": "#/definitions/myerrors.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Подписка не найдена",
+                    "409": {
+                        "description": "Бюджет на эту область уже есть",
                         "schema": {
-                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
                         }
                     },
                     "500": {
@@ -245,56 +349,46 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Обновляет данные подписки по ID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/budgets/status": {
+            "get": {
+                "description": "Считает траты пользователя за месяц по активным подпискам (те же данные, что у /subscriptions/sum) и сравнивает их с каждым его бюджетом",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "budgets"
                 ],
-                "summary": "Обновить подписку",
+                "summary": "Получить траты пользователя по бюджетам",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "path",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "description": "Данные для обновления подписки",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/v1.SubscriptionDTO"
-                        }
+                        "type": "string",
+                        "description": "Месяц в формате MM-YYYY, по умолчанию текущий",
+                        "name": "month",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Подписка успешно обновлена",
+                        "description": "Траты и состояние бюджетов",
                         "schema": {
-                            "$ref": "#/definitions/v1.SubscriptionID"
+                            "$ref": "#/definitions/v1.BudgetReportDTO"
                         }
                     },
                     "400": {
-                        "description": "Некорректные данные",
+                        "description": "Некорректные параметры",
                         "schema": {
                             "$ref": "#/definitions/myerrors.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Подписка не найдена",
-                        "schema": {
-                            "$ref": "#/definitions/myerrors.ErrorNotFound"
-                        }
-                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
@@ -302,40 +396,36 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Удаляет подписку по её идентификатору",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/budgets/{id}": {
+            "get": {
+                "description": "Возвращает бюджет по его идентификатору",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "budgets"
                 ],
-                "summary": "Удалить подписку",
+                "summary": "Получить бюджет по ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID подписки",
+                        "description": "ID бюджета",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Подписка успешно удалена"
-                    },
-                    "400": {
-                        "description": "Некорректный ID",
+                    "200": {
+                        "description": "Бюджет найден",
                         "schema": {
-                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                            "$ref": "#/definitions/v1.BudgetDTO"
                         }
                     },
                     "404": {
-                        "description": "Подписка не найдена",
+                        "description": "Бюджет не найден",
                         "schema": {
                             "$ref": "#/definitions/myerrors.ErrorNotFound"
                         }
@@ -347,52 +437,2306 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "myerrors.ErrorInternalServerError": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "internal server error"
-                }
-            }
-        },
-        "myerrors.ErrorNotFound": {
+            },
+            "put": {
+                "description": "Меняет лимит и hard_limit бюджета. Пользователь и сервис бюджета не меняются. Уже сохранённые подписки новый лимит не затрагивает",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Обновить бюджет",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID бюджета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Лимит",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.BudgetUpdateRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Бюджет обновлён",
+                        "schema": {
+                            "$ref": "#/definitions/v1.BudgetDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Бюджет не найден",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет бюджет. Отсутствующий бюджет не ошибка",
+                "tags": [
+                    "budgets"
+                ],
+                "summary": "Удалить бюджет",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID бюджета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Бюджет удалён"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/services": {
+            "get": {
+                "description": "Возвращает сервисы каталога по имени с пагинацией",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Получить каталог сервисов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Категория",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество элементов",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список сервисов",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ListServicesResponseDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Добавляет сервис с каноническим именем и синонимами. Имя и синонимы сравниваются без учёта регистра, пробелов, знаков и с транслитерацией кириллицы и не должны совпадать с другим сервисом. Новые подписки с таким названием привязываются к сервису",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Добавить сервис в каталог",
+                "parameters": [
+                    {
+                        "description": "Сервис",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.ServiceRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Сервис добавлен",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ServiceDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Имя или синоним принадлежит другому сервису",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/resolve": {
+            "get": {
+                "description": "Возвращает сервис каталога, к которому сводится название: по имени или синониму без учёта регистра, пробелов, знаков и с транслитерацией кириллицы",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Найти сервис по названию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название сервиса",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сервис найден",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ServiceDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Название не сводится ни к одному сервису",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/services/{id}": {
+            "get": {
+                "description": "Возвращает сервис каталога по его идентификатору",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Получить сервис по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сервиса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сервис найден",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ServiceDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Сервис не найден",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет поля и синонимы сервиса. Новое имя записывается в service_name привязанных подписок",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "services"
+                ],
+                "summary": "Обновить сервис",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сервиса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Сервис",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.ServiceRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сервис обновлён",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ServiceDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Сервис не найден",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "409": {
+                        "description": "Имя или синоним принадлежит другому сервису",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет сервис из каталога. Привязанные подписки отвязываются и сохраняют service_name. Отсутствующий сервис не ошибка",
+                "tags": [
+                    "services"
+                ],
+                "summary": "Удалить сервис",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сервиса",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Сервис удалён"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions": {
+            "get": {
+                "description": "Возвращает список подписок с пагинацией. tag и metadata отбирают подписки по тегам и metadata",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Получить список подписок",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Количество элементов",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Тег, параметр повторяется",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "any — хотя бы один из тегов, all — все",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "key — ключ есть, key:value — значение ключа строка value; параметр повторяется",
+                        "name": "metadata",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список подписок",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/v1.SubscriptionResponseDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создаёт новую подписку и возвращает её идентификатор",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Создать подписку",
+                "parameters": [
+                    {
+                        "description": "Данные для создания подписки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.SubscriptionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Подписка успешно создана",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SubscriptionID"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные или неизвестный user_id",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Подписка выводит траты за бюджет с hard_limit",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/forecast": {
+            "get": {
+                "description": "Помесячно прогнозирует траты по текущим подпискам начиная с текущего месяца: подписка оплачивается по текущей цене с месяца начала до месяца окончания включительно. С group_by прогноз разбивается на ряды по пользователям или сервисам",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Прогноз трат на подписки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 12,
+                        "description": "Число месяцев прогноза, от 1 до 60",
+                        "name": "months",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service name",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "user",
+                            "service"
+                        ],
+                        "type": "string",
+                        "description": "Разбивка на ряды",
+                        "name": "group_by",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Прогноз трат",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ForecastDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/sum": {
+            "get": {
+                "description": "Возвращает суммарную стоимость всех подписок пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Получить сумму стоимости подписок",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service name",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (MM-YYYY)",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (MM-YYYY)",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit subscriptions for count price",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset subscriptions",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Тег, параметр повторяется",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "default": "any",
+                        "description": "any — хотя бы один из тегов, all — все",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "key — ключ есть, key:value — значение ключа строка value; параметр повторяется",
+                        "name": "metadata",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ListSubscriptionsResponseDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}": {
+            "get": {
+                "description": "Возвращает подписку по её идентификатору",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Получить подписку по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка найдена",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SubscriptionResponseDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновляет данные подписки по ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Обновить подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные для обновления подписки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.SubscriptionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка успешно обновлена",
+                        "schema": {
+                            "$ref": "#/definitions/v1.SubscriptionID"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные или неизвестный user_id",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "409": {
+                        "description": "Подписка выводит траты за бюджет с hard_limit",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет подписку по её идентификатору",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Удалить подписку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Подписка успешно удалена"
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/members": {
+            "get": {
+                "description": "Возвращает разделение цены подписки между участниками. У не общей подписки единственный участник — владелец с полной ценой",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Участники общей подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Разделение цены",
+                        "schema": {
+                            "$ref": "#/definitions/v1.MembersDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет участников общей подписки. Цена делится поровну (equal), по процентам (percent) или фиксированными суммами (fixed); владелец участвует всегда и платит остаток, включая остаток от округления",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Разделить подписку между участниками",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Правило и участники кроме владельца",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.MembersRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Разделение цены",
+                        "schema": {
+                            "$ref": "#/definitions/v1.MembersDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректное разделение или неизвестный участник",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "409": {
+                        "description": "Доля участника выводит траты за бюджет с hard_limit",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет всех участников кроме владельца: цену снова платит он",
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Сделать подписку не общей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Подписка больше не общая"
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "409": {
+                        "description": "Полная цена выводит траты владельца за бюджет с hard_limit",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/members/{user_id}": {
+            "delete": {
+                "description": "Исключает участника, его доля переходит владельцу (при split=equal делится между оставшимися). Если кроме владельца никого не осталось, подписка перестаёт быть общей",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Исключить участника общей подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID участника",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Разделение цены",
+                        "schema": {
+                            "$ref": "#/definitions/v1.MembersDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Владельца исключить нельзя",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка или участник не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "409": {
+                        "description": "Выросшая доля выводит траты за бюджет с hard_limit",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Возвращает пользователей по имени с пагинацией",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Получить пользователей",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество элементов",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список пользователей",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ListUsersResponseDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создаёт пользователя. Подписки и участники общих подписок могут ссылаться только на существующих пользователей. Email уникален без учёта регистра",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Создать пользователя",
+                "parameters": [
+                    {
+                        "description": "Пользователь",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.UserRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Пользователь создан",
+                        "schema": {
+                            "$ref": "#/definitions/v1.UserDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email принадлежит другому пользователю",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Возвращает пользователя по его идентификатору",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Получить пользователя по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пользователь найден",
+                        "schema": {
+                            "$ref": "#/definitions/v1.UserDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет все поля пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Обновить пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Пользователь",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.UserRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пользователь обновлён",
+                        "schema": {
+                            "$ref": "#/definitions/v1.UserDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "409": {
+                        "description": "Email принадлежит другому пользователю",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет пользователя вместе с его подписками. Его доли в чужих общих подписках возвращаются владельцам",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Удалить пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Пользователь удалён"
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "409": {
+                        "description": "Во время удаления у пользователя появилась подписка, повторите запрос",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/reminder-preferences": {
+            "get": {
+                "description": "Возвращает настройки напоминаний пользователя об окончании подписок. Если пользователь ничего не сохранял, возвращаются настройки по умолчанию",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reminders"
+                ],
+                "summary": "Получить настройки напоминаний",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Настройки напоминаний",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ReminderPreferencesDTO"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет настройки напоминаний пользователя. Канал email доступен, только если на сервере настроен SMTP",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reminders"
+                ],
+                "summary": "Сохранить настройки напоминаний",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Настройки напоминаний",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.ReminderPreferencesRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Настройки сохранены",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ReminderPreferencesDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные, канал не настроен или неизвестный пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Возвращает зарегистрированные webhook'и с пагинацией, без ключей подписи",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Получить список webhook'ов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество элементов",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список webhook'ов",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ListWebhooksResponseDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Регистрирует URL, на который будут приходить подписанные POST-запросы с событиями выбранных типов. Endpoint получает только события пользователя user_id. Адреса loopback, link-local и частных сетей отклоняются. Если secret не передан, он генерируется; secret возвращается только в этом ответе",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Зарегистрировать webhook",
+                "parameters": [
+                    {
+                        "description": "Владелец, адрес, ключ подписи и типы событий",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.WebhookRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Webhook зарегистрирован",
+                        "schema": {
+                            "$ref": "#/definitions/v1.WebhookCreatedDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Возвращает webhook без ключа подписи",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Получить webhook по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID webhook'а",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook найден",
+                        "schema": {
+                            "$ref": "#/definitions/v1.WebhookDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook не найден",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет владельца, адрес и типы событий webhook'а. Пустой или отсутствующий secret оставляет прежний ключ подписи",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Обновить webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID webhook'а",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Владелец, адрес, ключ подписи и типы событий",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.WebhookRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook обновлён",
+                        "schema": {
+                            "$ref": "#/definitions/v1.WebhookDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook не найден",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет webhook вместе с историей доставок",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Удалить webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID webhook'а",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Webhook удалён"
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Возвращает доставки событий на webhook, новые первыми, с журналом попыток каждой",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Получить историю доставок webhook'а",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID webhook'а",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Количество элементов",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "История доставок",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ListWebhookDeliveriesResponseDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook не найден",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{delivery_id}/redeliver": {
+            "post": {
+                "description": "Ставит доставку в очередь на немедленную отправку, в том числе после исчерпания попыток",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Отправить доставку повторно",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID webhook'а",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID доставки",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Доставка поставлена в очередь",
+                        "schema": {
+                            "$ref": "#/definitions/v1.WebhookDeliveryDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Доставка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorNotFound"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/myerrors.ErrorInternalServerError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "myerrors.ErrorInternalServerError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "internal server error"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "0f8fad5b-d9cb-469f-a165-70867728950e"
+                }
+            }
+        },
+        "myerrors.ErrorNotFound": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "subscription not found"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "0f8fad5b-d9cb-469f-a165-70867728950e"
+                }
+            }
+        },
+        "myerrors.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid id format"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "0f8fad5b-d9cb-469f-a165-70867728950e"
+                }
+            }
+        },
+        "v1.BudgetDTO": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "streaming"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-10-18T16:00:00Z"
+                },
+                "hard_limit": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9c4b7f2e-1d3a-4e5b-8c6d-0a1b2c3d4e5f"
+                },
+                "monthly_limit": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1500
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "v1.BudgetReportDTO": {
+            "type": "object",
+            "properties": {
+                "budgets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.BudgetStatusDTO"
+                    }
+                },
+                "month": {
+                    "type": "string",
+                    "example": "10-2026"
+                },
+                "spent": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "v1.BudgetRequestDTO": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "streaming"
+                },
+                "hard_limit": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "monthly_limit": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1500
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "v1.BudgetStatusDTO": {
+            "type": "object",
+            "properties": {
+                "budget": {
+                    "$ref": "#/definitions/v1.BudgetDTO"
+                },
+                "exceeded": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "remaining": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "spent": {
+                    "type": "integer",
+                    "example": 1200
+                }
+            }
+        },
+        "v1.BudgetUpdateRequestDTO": {
+            "type": "object",
+            "properties": {
+                "hard_limit": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "monthly_limit": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1500
+                }
+            }
+        },
+        "v1.ChurnPointDTO": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "integer",
+                    "example": 18
+                },
+                "churn_rate": {
+                    "type": "number",
+                    "example": 0.15
+                },
+                "churned": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "month": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "subscribers": {
+                    "type": "integer",
+                    "example": 20
+                }
+            }
+        },
+        "v1.ChurnReportDTO": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "services": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.ServiceChurnDTO"
+                    }
+                },
+                "to": {
+                    "type": "string",
+                    "example": "12-2025"
+                }
+            }
+        },
+        "v1.ForecastDTO": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "group_by": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "service"
+                    ]
+                },
+                "months": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.ForecastSeriesDTO"
+                    }
+                },
+                "total": {
+                    "$ref": "#/definitions/v1.ForecastSeriesDTO"
+                }
+            }
+        },
+        "v1.ForecastPointDTO": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "month": {
+                    "type": "string",
+                    "example": "07-2025"
+                }
+            }
+        },
+        "v1.ForecastSeriesDTO": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.ForecastPointDTO"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 14400
+                }
+            }
+        },
+        "v1.JobDTO": {
+            "type": "object",
+            "properties": {
+                "last_run": {
+                    "$ref": "#/definitions/v1.JobRunDTO"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "expire-subscriptions"
+                },
+                "next_run_at": {
+                    "type": "string",
+                    "example": "2026-10-18T14:00:00Z"
+                },
+                "schedule": {
+                    "type": "string",
+                    "example": "@hourly"
+                }
+            }
+        },
+        "v1.JobListDTO": {
+            "type": "object",
+            "properties": {
+                "instance": {
+                    "type": "string",
+                    "example": "subscriptions-api-7d9f8c6b5-x2k4q"
+                },
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.JobDTO"
+                    }
+                },
+                "leader": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "v1.JobRunDTO": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "database error"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "example": "2026-10-18T13:00:02Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1c9e6b7a-3f2d-4e8b-a5c1-0d9f8e7a6b5c"
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "subscriptions-api-7d9f8c6b5-x2k4q"
+                },
+                "job": {
+                    "type": "string",
+                    "example": "expire-subscriptions"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2026-10-18T13:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "succeeded",
+                        "failed"
+                    ],
+                    "example": "succeeded"
+                },
+                "trigger": {
+                    "type": "string",
+                    "enum": [
+                        "schedule",
+                        "manual"
+                    ],
+                    "example": "schedule"
+                }
+            }
+        },
+        "v1.ListBudgetsResponseDTO": {
+            "type": "object",
+            "properties": {
+                "paging": {
+                    "$ref": "#/definitions/v1.Paging"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.BudgetDTO"
+                    }
+                }
+            }
+        },
+        "v1.ListJobRunsResponseDTO": {
+            "type": "object",
+            "properties": {
+                "paging": {
+                    "$ref": "#/definitions/v1.Paging"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.JobRunDTO"
+                    }
+                }
+            }
+        },
+        "v1.ListServicesResponseDTO": {
+            "type": "object",
+            "properties": {
+                "paging": {
+                    "$ref": "#/definitions/v1.Paging"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.ServiceDTO"
+                    }
+                }
+            }
+        },
+        "v1.ListSubscriptionsResponseDto": {
+            "type": "object",
+            "properties": {
+                "paging": {
+                    "$ref": "#/definitions/v1.Paging"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.SubscriptionResponseDTO"
+                    }
+                },
+                "total_sum": {
+                    "type": "integer",
+                    "example": 15900
+                }
+            }
+        },
+        "v1.ListUsersResponseDTO": {
+            "type": "object",
+            "properties": {
+                "paging": {
+                    "$ref": "#/definitions/v1.Paging"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.UserDTO"
+                    }
+                }
+            }
+        },
+        "v1.ListWebhookDeliveriesResponseDTO": {
+            "type": "object",
+            "properties": {
+                "paging": {
+                    "$ref": "#/definitions/v1.Paging"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.WebhookDeliveryDTO"
+                    }
+                }
+            }
+        },
+        "v1.ListWebhooksResponseDTO": {
+            "type": "object",
+            "properties": {
+                "paging": {
+                    "$ref": "#/definitions/v1.Paging"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.WebhookDTO"
+                    }
+                }
+            }
+        },
+        "v1.MRRPointDTO": {
+            "type": "object",
+            "properties": {
+                "active_subscribers": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "active_subscriptions": {
+                    "type": "integer",
+                    "example": 57
+                },
+                "churned": {
+                    "type": "integer",
+                    "example": 800
+                },
+                "contraction": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "expansion": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "month": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "mrr": {
+                    "type": "integer",
+                    "example": 15400
+                },
+                "net_new": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "new": {
+                    "type": "integer",
+                    "example": 1200
+                }
+            }
+        },
+        "v1.MRRReportDTO": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "01-2025"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.MRRPointDTO"
+                    }
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "12-2025"
+                }
+            }
+        },
+        "v1.MemberDTO": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "owner": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "percent": {
+                    "type": "number",
+                    "example": 25
+                },
+                "share": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "7a1c2e3f-4b5d-4e6f-8a9b-0c1d2e3f4a5b"
+                }
+            }
+        },
+        "v1.MemberRequestDTO": {
             "type": "object",
             "properties": {
-                "error": {
+                "amount": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "percent": {
+                    "type": "number",
+                    "example": 25
+                },
+                "user_id": {
                     "type": "string",
-                    "example": "subscription not found"
+                    "example": "7a1c2e3f-4b5d-4e6f-8a9b-0c1d2e3f4a5b"
                 }
             }
         },
-        "myerrors.ErrorResponse": {
+        "v1.MembersDTO": {
             "type": "object",
             "properties": {
-                "error": {
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.MemberDTO"
+                    }
+                },
+                "owner_id": {
                     "type": "string",
-                    "example": "invalid id format"
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "split": {
+                    "type": "string",
+                    "enum": [
+                        "equal",
+                        "percent",
+                        "fixed"
+                    ],
+                    "example": "equal"
+                },
+                "subscription_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 }
             }
         },
-        "v1.ListSubscriptionsResponseDto": {
+        "v1.MembersRequestDTO": {
             "type": "object",
             "properties": {
-                "paging": {
-                    "$ref": "#/definitions/v1.Paging"
-                },
-                "rows": {
+                "members": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/v1.SubscriptionResponseDTO"
+                        "$ref": "#/definitions/v1.MemberRequestDTO"
                     }
                 },
-                "total_sum": {
-                    "type": "integer",
-                    "example": 15900
+                "split": {
+                    "type": "string",
+                    "enum": [
+                        "equal",
+                        "percent",
+                        "fixed"
+                    ],
+                    "example": "equal"
                 }
             }
         },
@@ -413,21 +2757,215 @@ const docTemplate = `{
                 }
             }
         },
+        "v1.ReminderPreferencesDTO": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "webhook",
+                        "log"
+                    ],
+                    "example": "email"
+                },
+                "days_before": {
+                    "type": "integer",
+                    "maximum": 90,
+                    "minimum": 1,
+                    "example": 3
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "webhook_url": {
+                    "type": "string",
+                    "example": "https://bot.example.com/reminders"
+                }
+            }
+        },
+        "v1.ReminderPreferencesRequestDTO": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "webhook",
+                        "log"
+                    ],
+                    "example": "email"
+                },
+                "days_before": {
+                    "type": "integer",
+                    "maximum": 90,
+                    "minimum": 1,
+                    "example": 3
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "webhook_url": {
+                    "type": "string",
+                    "example": "https://bot.example.com/reminders"
+                }
+            }
+        },
+        "v1.ServiceChurnDTO": {
+            "type": "object",
+            "properties": {
+                "churn_rate": {
+                    "type": "number",
+                    "example": 0.05
+                },
+                "churned": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.ChurnPointDTO"
+                    }
+                },
+                "service_name": {
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "subscribers": {
+                    "type": "integer",
+                    "example": 240
+                }
+            }
+        },
+        "v1.ServiceDTO": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Нетфликс",
+                        "NFLX"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "example": "streaming"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-10-19T10:00:00Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "default": "RUB",
+                    "example": "RUB"
+                },
+                "default_price": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 799
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f0b6c1e-5a2d-4c8b-9e7f-1a2b3c4d5e6f"
+                },
+                "logo_url": {
+                    "type": "string",
+                    "example": "https://example.com/netflix.svg"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Netflix"
+                }
+            }
+        },
+        "v1.ServiceRequestDTO": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Нетфликс",
+                        "NFLX"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "example": "streaming"
+                },
+                "currency": {
+                    "type": "string",
+                    "default": "RUB",
+                    "example": "RUB"
+                },
+                "default_price": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 799
+                },
+                "logo_url": {
+                    "type": "string",
+                    "example": "https://example.com/netflix.svg"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Netflix"
+                }
+            }
+        },
         "v1.SubscriptionDTO": {
             "type": "object",
             "properties": {
                 "end_date": {
                     "type": "string"
                 },
+                "metadata": {
+                    "type": "object"
+                },
                 "price": {
                     "type": "integer"
                 },
+                "service_id": {
+                    "type": "string",
+                    "example": "3f0b6c1e-5a2d-4c8b-9e7f-1a2b3c4d5e6f"
+                },
                 "service_name": {
                     "type": "string"
                 },
                 "start_date": {
                     "type": "string"
                 },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work",
+                        "tax-deductible"
+                    ]
+                },
                 "user_id": {
                     "type": "string"
                 }
@@ -453,23 +2991,262 @@ const docTemplate = `{
                     "type": "string",
                     "example": "123e4567-e89b-12d3-a456-426614174000"
                 },
+                "metadata": {
+                    "type": "object"
+                },
                 "price": {
                     "type": "integer",
                     "example": 99900
                 },
+                "service_id": {
+                    "type": "string",
+                    "example": "3f0b6c1e-5a2d-4c8b-9e7f-1a2b3c4d5e6f"
+                },
                 "service_name": {
                     "type": "string",
                     "example": "Netflix"
                 },
+                "share": {
+                    "description": "Share — доля пользователя фильтра в общей подписке, только в /subscriptions/sum.",
+                    "type": "integer",
+                    "example": 100
+                },
                 "start_date": {
                     "type": "string",
                     "example": "07-2026"
                 },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work",
+                        "tax-deductible"
+                    ]
+                },
                 "user_id": {
                     "type": "string",
                     "example": "987f6543-e21b-34d5-c678-426614174999"
                 }
             }
+        },
+        "v1.UserDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-10-19T10:00:00Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "default": "RUB",
+                    "example": "RUB"
+                },
+                "display_name": {
+                    "type": "string",
+                    "example": "Алиса"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "timezone": {
+                    "type": "string",
+                    "default": "UTC",
+                    "example": "Europe/Moscow"
+                }
+            }
+        },
+        "v1.UserRequestDTO": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "default": "RUB",
+                    "example": "RUB"
+                },
+                "display_name": {
+                    "type": "string",
+                    "example": "Алиса"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
+                "timezone": {
+                    "type": "string",
+                    "default": "UTC",
+                    "example": "Europe/Moscow"
+                }
+            }
+        },
+        "v1.WebhookAttemptDTO": {
+            "type": "object",
+            "properties": {
+                "attempted_at": {
+                    "type": "string",
+                    "example": "2026-10-18T13:00:01Z"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "error": {
+                    "type": "string",
+                    "example": "endpoint responded 503 Service Unavailable"
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 503
+                }
+            }
+        },
+        "v1.WebhookCreatedDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-10-18T13:00:00Z"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "SubscriptionCreated",
+                        "SubscriptionDeleted"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0b7c6f3e-2a1d-4c5b-9e8f-7a6b5c4d3e2f"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "whsec_5f2b8c0e4a7d9e1f3b6c8a0d2e4f6a8b9c1d3e5f7a9b0c2d"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://bot.example.com/hooks/subscriptions"
+                },
+                "user_id": {
+                    "description": "UserID нет у endpoint'ов, созданных до появления владельцев.",
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "v1.WebhookDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-10-18T13:00:00Z"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "SubscriptionCreated",
+                        "SubscriptionDeleted"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0b7c6f3e-2a1d-4c5b-9e8f-7a6b5c4d3e2f"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://bot.example.com/hooks/subscriptions"
+                },
+                "user_id": {
+                    "description": "UserID нет у endpoint'ов, созданных до появления владельцев.",
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "v1.WebhookDeliveryDTO": {
+            "type": "object",
+            "properties": {
+                "attempt_log": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.WebhookAttemptDTO"
+                    }
+                },
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-10-18T13:00:00Z"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d"
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "SubscriptionCreated"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5d3c2b1a-0f9e-4d8c-b7a6-958473625140"
+                },
+                "next_attempt_at": {
+                    "type": "string",
+                    "example": "2026-10-18T13:00:11Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "succeeded",
+                        "failed"
+                    ],
+                    "example": "pending"
+                }
+            }
+        },
+        "v1.WebhookRequestDTO": {
+            "type": "object",
+            "properties": {
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "SubscriptionCreated",
+                        "SubscriptionDeleted"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://bot.example.com/hooks/subscriptions"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,23 @@
+// Package cache — хранилища для кэширующего декоратора сервиса. Значения —
+// байты, чтобы за тем же интерфейсом можно было поставить Redis.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache — потокобезопасное хранилище значений с TTL. Ошибка бэкенда для
+// вызывающего выглядит как промах: кэш не должен ломать запрос.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	// Set сохраняет значение, ttl 0 — без срока жизни (но запись всё
+	// равно может быть вытеснена).
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+	Delete(ctx context.Context, keys ...string)
+}
+
+const (
+	BackendNone   = "none"
+	BackendMemory = "memory"
+)
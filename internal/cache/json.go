@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	logger "testingtask/pkg"
+	"time"
+)
+
+// GetJSON читает key из c и декодирует его в v. Запись, которая не
+// декодируется, удаляется и считается промахом: иначе она отдавала бы
+// ошибку до истечения TTL.
+func GetJSON(ctx context.Context, c Cache, key string, v any) bool {
+	data, ok := c.Get(ctx, key)
+	if !ok {
+		return false
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		logger.Warn(ctx, "cache: dropping corrupted entry", map[string]interface{}{
+			"key":   key,
+			"error": err.Error(),
+		})
+		c.Delete(ctx, key)
+		return false
+	}
+	return true
+}
+
+// SetJSON сохраняет v в c в JSON. Значение, которое не кодируется, не
+// сохраняется.
+func SetJSON(ctx context.Context, c Cache, key string, v any, ttl time.Duration) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		logger.Warn(ctx, "cache: entry not stored", map[string]interface{}{
+			"key":   key,
+			"error": err.Error(),
+		})
+		return
+	}
+	c.Set(ctx, key, data, ttl)
+}
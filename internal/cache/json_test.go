@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestJSONDropsCorruptedEntry(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+
+	type entry struct {
+		Price int `json:"price"`
+	}
+	SetJSON(ctx, c, "ok", entry{Price: 400}, 0)
+	var got entry
+	if !GetJSON(ctx, c, "ok", &got) || got.Price != 400 {
+		t.Fatalf("GetJSON(ok) = %+v, want price 400", got)
+	}
+
+	c.Set(ctx, "bad", []byte("{not json"), 0)
+	if GetJSON(ctx, c, "bad", &got) {
+		t.Fatal("GetJSON(bad) = hit, want miss")
+	}
+	if _, ok := c.Get(ctx, "bad"); ok {
+		t.Fatal("corrupted entry was not evicted")
+	}
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// lru — Cache в памяти процесса: не больше size записей, при переполнении
+// вытесняется давно не читанная. Просроченные записи удаляются при чтении.
+type lru struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+	now   func() time.Time
+}
+
+func NewLRU(size int) Cache {
+	return &lru{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+		now:   time.Now,
+	}
+}
+
+func (c *lru) Get(_ context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*lruEntry)
+	if !e.expires.IsZero() && !c.now().Before(e.expires) {
+		c.remove(el)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	return e.value, true
+}
+
+func (c *lru) Set(_ context.Context, key string, value []byte, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.value, e.expires = value, expires
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expires: expires})
+
+	for c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *lru) Delete(_ context.Context, keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+func (c *lru) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).key)
+}
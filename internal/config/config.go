@@ -1,51 +1,544 @@
 package config
 
 import (
+	"database/sql"
 	"errors"
-	"log"
-	"os"
-	"strconv"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"testingtask/internal/domain/reminder"
+	"testingtask/internal/scheduler"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
 )
 
 type Config struct {
-	AppEnv      string
-	DatabaseURL string
-	PORT        string
+	AppEnv string `yaml:"app_env"`
+
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Logging   LoggingConfig   `yaml:"logging"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Health    HealthConfig    `yaml:"health"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Features  FeaturesConfig  `yaml:"features"`
+	Cache     CacheConfig     `yaml:"cache"`
+	Outbox    OutboxConfig    `yaml:"outbox"`
+	Webhooks  WebhooksConfig  `yaml:"webhooks"`
+	Reminders RemindersConfig `yaml:"reminders"`
+	Budgets   BudgetsConfig   `yaml:"budgets"`
+	Jobs      JobsConfig      `yaml:"jobs"`
+
+	// PrintConfig — режим --print-config: вывести итоговый конфиг и выйти.
+	PrintConfig bool `yaml:"-"`
+	// Args — позиционные аргументы после флагов (подкоманда).
+	Args []string `yaml:"-"`
+}
+
+type ServerConfig struct {
+	Port               int           `yaml:"port"`
+	ReadTimeout        time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout  time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout       time.Duration `yaml:"write_timeout"`
+	IdleTimeout        time.Duration `yaml:"idle_timeout"`
+	BodyLimit          string        `yaml:"body_limit"`
+	ShutdownTimeout    time.Duration `yaml:"shutdown_timeout"`
+	ShutdownDrainDelay time.Duration `yaml:"shutdown_drain_delay"`
+}
+
+type DatabaseConfig struct {
+	URL string `yaml:"url"`
+	// Repository — реализация репозитория для Postgres: gorm или pgx
+	// (pgx-запросы для чтения, запись через GORM).
+	Repository string `yaml:"repository"`
+	// ReportIsolation — уровень изоляции транзакций отчётов (List, Sum,
+	// аналитика, статус бюджетов): read_committed, repeatable_read или
+	// serializable.
+	ReportIsolation string         `yaml:"report_isolation"`
+	Pool            PoolConfig     `yaml:"pool"`
+	Replicas        ReplicasConfig `yaml:"replicas"`
+}
+
+// ReplicasConfig — реплики Postgres для чтения (List, Sum, Count, Get).
+type ReplicasConfig struct {
+	URLs []string `yaml:"urls"`
+	// MaxLag — реплика с большим отставанием не получает запросов, пока
+	// не догонит primary. 0 — не проверять.
+	MaxLag time.Duration `yaml:"max_lag"`
+	// ReadYourWritesWindow — сколько после изменяющего запроса клиент
+	// читает с primary (cookie read_primary). 0 — только по заголовку.
+	ReadYourWritesWindow time.Duration `yaml:"read_your_writes_window"`
+}
+
+var isolationLevels = map[string]sql.IsolationLevel{
+	"read_committed":  sql.LevelReadCommitted,
+	"repeatable_read": sql.LevelRepeatableRead,
+	"serializable":    sql.LevelSerializable,
+}
+
+// ReportIsolationLevel возвращает ReportIsolation как sql.IsolationLevel.
+func (d DatabaseConfig) ReportIsolationLevel() sql.IsolationLevel {
+	return isolationLevels[d.ReportIsolation]
+}
+
+const (
+	RepositoryGORM = "gorm"
+	RepositoryPgx  = "pgx"
+)
+
+type PoolConfig struct {
+	MaxOpenConns        int           `yaml:"max_open_conns"`
+	MaxIdleConns        int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime     time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime     time.Duration `yaml:"conn_max_idle_time"`
+	SaturationThreshold float64       `yaml:"saturation_threshold"`
+}
+
+type LoggingConfig struct {
+	Level  string `yaml:"level"`
+	Pretty bool   `yaml:"pretty"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"check_timeout"`
+}
+
+type AuthConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	APIKeys []string `yaml:"api_keys"`
+}
+
+// CacheConfig — кэш Get и Sum в сервисе и выручки закрытых месяцев в
+// аналитике. TTL 0 отключает кэш операции. subctl пишет в базу в обход
+// кэша сервера: после его create, update, delete, delete-user и import
+// сервер отдаёт прежние Get и Sum, пока не истекут GetTTL и SumTTL.
+type CacheConfig struct {
+	Backend      string        `yaml:"backend"`
+	Size         int           `yaml:"size"`
+	GetTTL       time.Duration `yaml:"get_ttl"`
+	SumTTL       time.Duration `yaml:"sum_ttl"`
+	AnalyticsTTL time.Duration `yaml:"analytics_ttl"`
 }
 
-func LoadConfig() (*Config, error) {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
+// OutboxConfig — доставка доменных событий подписок. При Sink none
+// события не записываются.
+type OutboxConfig struct {
+	Sink           string        `yaml:"sink"`
+	WebhookURL     string        `yaml:"webhook_url"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout"`
+	FilePath       string        `yaml:"file_path"`
+	// NATSURL — nats://[user:pass@|token@]host[:4222] или tls://… для
+	// Sink nats. События уходят в subject <NATSSubject>.<тип события>.
+	NATSURL     string        `yaml:"nats_url"`
+	NATSSubject string        `yaml:"nats_subject"`
+	NATSTimeout time.Duration `yaml:"nats_timeout"`
+	// NATSJetStream — ждать подтверждения записи в поток JetStream. Без
+	// него событие, на subject которого никто не подписан, теряется.
+	NATSJetStream bool `yaml:"nats_jetstream"`
+
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+	RetryMin     time.Duration `yaml:"retry_min"`
+	RetryMax     time.Duration `yaml:"retry_max"`
+	// ExpireSchedule — cron-расписание поиска закончившихся подписок для
+	// событий SubscriptionExpired.
+	ExpireSchedule string `yaml:"expire_schedule"`
+}
+
+// WebhooksConfig — webhook'и пользователей (/webhooks). События для них
+// записывает тот же outbox, поэтому при Enabled он работает и с Sink none.
+type WebhooksConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	Timeout      time.Duration `yaml:"timeout"`
+	MaxAttempts  int           `yaml:"max_attempts"`
+	RetryMin     time.Duration `yaml:"retry_min"`
+	RetryMax     time.Duration `yaml:"retry_max"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+}
+
+// RemindersConfig — напоминания об окончании подписок. Канал log есть
+// всегда, webhook — при Enabled, email — если задан smtp.host.
+type RemindersConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Schedule — cron-расписание поиска подписок, о которых пора
+	// напомнить.
+	Schedule          string `yaml:"schedule"`
+	DefaultDaysBefore int    `yaml:"default_days_before"`
+	// Lease — сколько экземпляр владеет взятым напоминанием, прежде чем
+	// его сможет отправить другой.
+	Lease          time.Duration `yaml:"lease"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout"`
+	SMTP           SMTPConfig    `yaml:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string        `yaml:"host"`
+	Port     int           `yaml:"port"`
+	Username string        `yaml:"username"`
+	Password string        `yaml:"password"`
+	From     string        `yaml:"from"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// BudgetsConfig — бюджеты пользователей (/budgets). Событие
+// BudgetExceeded уходит через outbox, если события записываются.
+type BudgetsConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// JobsConfig — планировщик фоновых задач. Задачи по расписанию выполняет
+// один экземпляр — держатель advisory-блокировки Postgres.
+type JobsConfig struct {
+	// ElectionInterval — как часто экземпляр пытается стать лидером.
+	ElectionInterval time.Duration `yaml:"election_interval"`
+	// Timeout ограничивает один запуск задачи.
+	Timeout time.Duration `yaml:"timeout"`
+	// PurgeSchedule и HistoryRetention — когда и старше скольки удалять
+	// историю запусков.
+	PurgeSchedule    string        `yaml:"purge_schedule"`
+	HistoryRetention time.Duration `yaml:"history_retention"`
+}
+
+// RecordsEvents сообщает, нужно ли записывать события подписок в outbox.
+func (c *Config) RecordsEvents() bool {
+	return c.Outbox.Sink != "none" || c.Webhooks.Enabled
+}
+
+type FeaturesConfig struct {
+	Swagger bool `yaml:"swagger"`
+}
+
+func Default() *Config {
+	return &Config{
+		AppEnv: "development",
+		Server: ServerConfig{
+			Port:               8080,
+			ReadTimeout:        10 * time.Second,
+			ReadHeaderTimeout:  5 * time.Second,
+			WriteTimeout:       30 * time.Second,
+			IdleTimeout:        120 * time.Second,
+			BodyLimit:          "1M",
+			ShutdownTimeout:    30 * time.Second,
+			ShutdownDrainDelay: 0,
+		},
+		Database: DatabaseConfig{
+			Repository:      RepositoryGORM,
+			ReportIsolation: "repeatable_read",
+			Replicas: ReplicasConfig{
+				MaxLag:               10 * time.Second,
+				ReadYourWritesWindow: 10 * time.Second,
+			},
+			Pool: PoolConfig{
+				MaxOpenConns:        25,
+				MaxIdleConns:        10,
+				ConnMaxLifetime:     30 * time.Minute,
+				ConnMaxIdleTime:     5 * time.Minute,
+				SaturationThreshold: 0.9,
+			},
+		},
+		Logging: LoggingConfig{
+			Level: "info",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+		Health: HealthConfig{
+			CheckTimeout: 2 * time.Second,
+		},
+		Features: FeaturesConfig{
+			Swagger: true,
+		},
+		Cache: CacheConfig{
+			Backend:      "none",
+			Size:         10000,
+			GetTTL:       time.Minute,
+			SumTTL:       30 * time.Second,
+			AnalyticsTTL: 24 * time.Hour,
+		},
+		Outbox: OutboxConfig{
+			Sink:           "none",
+			WebhookTimeout: 5 * time.Second,
+			FilePath:       "outbox.jsonl",
+			NATSSubject:    "subscriptions",
+			NATSTimeout:    5 * time.Second,
+			NATSJetStream:  true,
+			PollInterval:   time.Second,
+			BatchSize:      100,
+			RetryMin:       time.Second,
+			RetryMax:       5 * time.Minute,
+			ExpireSchedule: "@hourly",
+		},
+		Webhooks: WebhooksConfig{
+			Timeout:      10 * time.Second,
+			MaxAttempts:  8,
+			RetryMin:     10 * time.Second,
+			RetryMax:     time.Hour,
+			PollInterval: time.Second,
+			BatchSize:    50,
+		},
+		Reminders: RemindersConfig{
+			Schedule:          "*/15 * * * *",
+			DefaultDaysBefore: 3,
+			Lease:             5 * time.Minute,
+			WebhookTimeout:    10 * time.Second,
+			SMTP: SMTPConfig{
+				Port:    587,
+				Timeout: 30 * time.Second,
+			},
+		},
+		Jobs: JobsConfig{
+			ElectionInterval: 10 * time.Second,
+			Timeout:          10 * time.Minute,
+			PurgeSchedule:    "@daily",
+			HistoryRetention: 30 * 24 * time.Hour,
+		},
 	}
+}
+
+func (c *Config) IsDevelopment() bool {
+	return c.AppEnv == "development"
+}
 
-	config := &Config{
-		AppEnv:      getEnv("APP_ENV", "development"),
-		DatabaseURL: getEnv("DATABASE_URL", ""),
-		// PORT:        getEnvAsInt("PORT", 8080),
-		PORT: getEnv("PORT", "8080"),
+func (s ServerConfig) Addr() string {
+	return fmt.Sprintf(":%d", s.Port)
+}
+
+var bodyLimitRe = regexp.MustCompile(`^[0-9]+[KMGTP]?$`)
+
+// namedDuration — длительность из конфига с именем параметра для ошибки.
+type namedDuration struct {
+	name  string
+	value time.Duration
+}
+
+// Validate проверяет конфиг целиком и возвращает все найденные ошибки сразу.
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	// Списки, а не map: порядок ошибок не должен меняться от запуска к
+	// запуску.
+	positive := func(fields []namedDuration) {
+		for _, f := range fields {
+			if f.value <= 0 {
+				add("%s: must be positive, got %s", f.name, f.value)
+			}
+		}
 	}
 
-	if config.DatabaseURL == "" {
-		return nil, errors.New("DATABASE_URL env variable not set")
+	switch c.AppEnv {
+	case "development", "staging", "production", "test":
+	default:
+		add("app_env: unknown environment %q", c.AppEnv)
 	}
 
-	return config, nil
-}
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		add("server.port: must be in 1..65535, got %d", c.Server.Port)
+	}
+	positive([]namedDuration{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"health.check_timeout", c.Health.CheckTimeout},
+	})
+	if c.Server.ShutdownDrainDelay < 0 {
+		add("server.shutdown_drain_delay: must not be negative")
+	}
+	if !bodyLimitRe.MatchString(c.Server.BodyLimit) {
+		add("server.body_limit: invalid size %q, expected e.g. 512K or 1M", c.Server.BodyLimit)
+	}
+
+	if c.Database.URL == "" {
+		add("database.url: required (DATABASE_URL)")
+	}
+	switch c.Database.Repository {
+	case RepositoryGORM:
+	case RepositoryPgx:
+		if strings.HasPrefix(c.Database.URL, "sqlite://") || strings.HasPrefix(c.Database.URL, "memory://") {
+			add("database.repository: pgx requires a Postgres database.url")
+		}
+	default:
+		add("database.repository: unknown %q, expected gorm or pgx", c.Database.Repository)
+	}
+	if _, ok := isolationLevels[c.Database.ReportIsolation]; !ok {
+		add("database.report_isolation: unknown %q, expected read_committed, repeatable_read or serializable", c.Database.ReportIsolation)
+	}
+	replicas := c.Database.Replicas
+	if len(replicas.URLs) > 0 && (strings.HasPrefix(c.Database.URL, "sqlite://") || strings.HasPrefix(c.Database.URL, "memory://")) {
+		add("database.replicas.urls: replicas require a Postgres database.url")
+	}
+	if len(replicas.URLs) > 0 && c.Database.Repository == RepositoryPgx {
+		// Пул pgx-репозитория читает только с primary: реплики были бы
+		// настроены, но не использовались.
+		add("database.replicas.urls: not supported with database.repository pgx, its pool reads only from the primary")
+	}
+	if replicas.MaxLag < 0 {
+		add("database.replicas.max_lag: must not be negative")
+	}
+	if replicas.ReadYourWritesWindow < 0 {
+		add("database.replicas.read_your_writes_window: must not be negative")
+	}
+	pool := c.Database.Pool
+	if pool.MaxOpenConns < 0 {
+		add("database.pool.max_open_conns: must not be negative")
+	}
+	if pool.MaxIdleConns < 0 {
+		add("database.pool.max_idle_conns: must not be negative")
+	}
+	if pool.MaxOpenConns > 0 && pool.MaxIdleConns > pool.MaxOpenConns {
+		add("database.pool.max_idle_conns: %d exceeds max_open_conns %d", pool.MaxIdleConns, pool.MaxOpenConns)
+	}
+	if pool.SaturationThreshold <= 0 || pool.SaturationThreshold > 1 {
+		add("database.pool.saturation_threshold: must be in (0, 1], got %v", pool.SaturationThreshold)
+	}
+
+	if _, err := zerolog.ParseLevel(c.Logging.Level); err != nil || c.Logging.Level == "" {
+		add("logging.level: unknown level %q", c.Logging.Level)
+	}
 
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		add("tracing.exporter: must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("tracing.sample_ratio: must be in [0, 1], got %v", c.Tracing.SampleRatio)
+	}
+
+	if c.Auth.Enabled && len(c.Auth.APIKeys) == 0 {
+		add("auth.api_keys: at least one key required when auth is enabled")
+	}
+
+	switch c.Cache.Backend {
+	case "none":
+	case "memory":
+		if c.Cache.Size <= 0 {
+			add("cache.size: must be positive, got %d", c.Cache.Size)
+		}
+	default:
+		add("cache.backend: must be none or memory, got %q", c.Cache.Backend)
 	}
-	return fallback
+	if c.Cache.GetTTL < 0 {
+		add("cache.get_ttl: must not be negative")
+	}
+	if c.Cache.SumTTL < 0 {
+		add("cache.sum_ttl: must not be negative")
+	}
+	if c.Cache.AnalyticsTTL < 0 {
+		add("cache.analytics_ttl: must not be negative")
+	}
+
+	ob := c.Outbox
+	switch ob.Sink {
+	case "none":
+	case "webhook":
+		if u, err := url.Parse(ob.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("outbox.webhook_url: required http(s) URL for the webhook sink")
+		}
+	case "file":
+		if ob.FilePath == "" {
+			add("outbox.file_path: required for the file sink")
+		}
+	case "nats":
+		if u, err := url.Parse(ob.NATSURL); err != nil || (u.Scheme != "nats" && u.Scheme != "tls") || u.Hostname() == "" {
+			add("outbox.nats_url: required nats:// or tls:// URL for the nats sink")
+		}
+		if !validSubject(ob.NATSSubject) {
+			add("outbox.nats_subject: must be dot-separated tokens without spaces or wildcards, got %q", ob.NATSSubject)
+		}
+	default:
+		add("outbox.sink: must be none, webhook, file or nats, got %q", ob.Sink)
+	}
+	positive([]namedDuration{
+		{"outbox.webhook_timeout", ob.WebhookTimeout},
+		{"outbox.nats_timeout", ob.NATSTimeout},
+		{"outbox.poll_interval", ob.PollInterval},
+		{"outbox.retry_min", ob.RetryMin},
+		{"outbox.retry_max", ob.RetryMax},
+	})
+	if ob.RetryMin > ob.RetryMax {
+		add("outbox.retry_min: %s exceeds retry_max %s", ob.RetryMin, ob.RetryMax)
+	}
+	if ob.BatchSize <= 0 {
+		add("outbox.batch_size: must be positive, got %d", ob.BatchSize)
+	}
+
+	wh := c.Webhooks
+	positive([]namedDuration{
+		{"webhooks.timeout", wh.Timeout},
+		{"webhooks.retry_min", wh.RetryMin},
+		{"webhooks.retry_max", wh.RetryMax},
+		{"webhooks.poll_interval", wh.PollInterval},
+	})
+	if wh.RetryMin > wh.RetryMax {
+		add("webhooks.retry_min: %s exceeds retry_max %s", wh.RetryMin, wh.RetryMax)
+	}
+	if wh.MaxAttempts <= 0 {
+		add("webhooks.max_attempts: must be positive, got %d", wh.MaxAttempts)
+	}
+	if wh.BatchSize <= 0 {
+		add("webhooks.batch_size: must be positive, got %d", wh.BatchSize)
+	}
+
+	rm := c.Reminders
+	positive([]namedDuration{
+		{"reminders.lease", rm.Lease},
+		{"reminders.webhook_timeout", rm.WebhookTimeout},
+		{"reminders.smtp.timeout", rm.SMTP.Timeout},
+	})
+	if rm.DefaultDaysBefore < 1 || rm.DefaultDaysBefore > reminder.MaxDaysBefore {
+		add("reminders.default_days_before: must be in 1..%d, got %d", reminder.MaxDaysBefore, rm.DefaultDaysBefore)
+	}
+	if rm.SMTP.Host != "" {
+		if rm.SMTP.Port < 1 || rm.SMTP.Port > 65535 {
+			add("reminders.smtp.port: must be in 1..65535, got %d", rm.SMTP.Port)
+		}
+		if a, err := mail.ParseAddress(rm.SMTP.From); err != nil || a.Address != rm.SMTP.From {
+			add("reminders.smtp.from: required email address when smtp.host is set")
+		}
+	}
+
+	positive([]namedDuration{
+		{"jobs.election_interval", c.Jobs.ElectionInterval},
+		{"jobs.timeout", c.Jobs.Timeout},
+		{"jobs.history_retention", c.Jobs.HistoryRetention},
+	})
+	for _, f := range []struct{ name, spec string }{
+		{"outbox.expire_schedule", ob.ExpireSchedule},
+		{"reminders.schedule", rm.Schedule},
+		{"jobs.purge_schedule", c.Jobs.PurgeSchedule},
+	} {
+		if _, err := scheduler.ParseSchedule(f.spec); err != nil {
+			add("%s: invalid cron schedule %q: %v", f.name, f.spec, err)
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
-func getEnvAsInt(key string, fallback int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err != nil {
-			return intValue
+// validSubject проверяет subject NATS: непустые токены через точку без
+// пробелов и подстановок * и >.
+func validSubject(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, token := range strings.Split(s, ".") {
+		if token == "" || strings.ContainsAny(token, " \t\r\n*>") {
+			return false
 		}
 	}
-	return fallback
+	return true
 }
@@ -0,0 +1,331 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultConfigFile = "config.yaml"
+
+// field описывает один параметр: ключ в YAML (он же имя флага),
+// переменную окружения и указатель на поле конфига.
+type field struct {
+	key    string
+	env    string
+	usage  string
+	secret bool
+	// mask — как скрывать секрет в --print-config, по умолчанию целиком.
+	mask func(string) string
+	ptr  func(c *Config) interface{}
+}
+
+var fields = []field{
+	{key: "app_env", env: "APP_ENV", usage: "environment: development, staging, production, test",
+		ptr: func(c *Config) interface{} { return &c.AppEnv }},
+
+	{key: "server.port", env: "PORT", usage: "HTTP port",
+		ptr: func(c *Config) interface{} { return &c.Server.Port }},
+	{key: "server.read_timeout", env: "HTTP_READ_TIMEOUT", usage: "HTTP read timeout",
+		ptr: func(c *Config) interface{} { return &c.Server.ReadTimeout }},
+	{key: "server.read_header_timeout", env: "HTTP_READ_HEADER_TIMEOUT", usage: "HTTP read header timeout",
+		ptr: func(c *Config) interface{} { return &c.Server.ReadHeaderTimeout }},
+	{key: "server.write_timeout", env: "HTTP_WRITE_TIMEOUT", usage: "HTTP write timeout",
+		ptr: func(c *Config) interface{} { return &c.Server.WriteTimeout }},
+	{key: "server.idle_timeout", env: "HTTP_IDLE_TIMEOUT", usage: "HTTP keep-alive idle timeout",
+		ptr: func(c *Config) interface{} { return &c.Server.IdleTimeout }},
+	{key: "server.body_limit", env: "HTTP_BODY_LIMIT", usage: "max request body size, e.g. 1M",
+		ptr: func(c *Config) interface{} { return &c.Server.BodyLimit }},
+	{key: "server.shutdown_timeout", env: "SHUTDOWN_TIMEOUT", usage: "time to drain in-flight requests",
+		ptr: func(c *Config) interface{} { return &c.Server.ShutdownTimeout }},
+	{key: "server.shutdown_drain_delay", env: "SHUTDOWN_DRAIN_DELAY", usage: "delay between failing readiness and closing the listener",
+		ptr: func(c *Config) interface{} { return &c.Server.ShutdownDrainDelay }},
+
+	{key: "database.url", env: "DATABASE_URL", usage: "database DSN: postgres://..., sqlite://path or memory://", secret: true, mask: MaskDSN,
+		ptr: func(c *Config) interface{} { return &c.Database.URL }},
+	{key: "database.repository", env: "DB_REPOSITORY", usage: "Postgres repository implementation: gorm or pgx",
+		ptr: func(c *Config) interface{} { return &c.Database.Repository }},
+	{key: "database.pool.max_open_conns", env: "DB_MAX_OPEN_CONNS", usage: "max open connections",
+		ptr: func(c *Config) interface{} { return &c.Database.Pool.MaxOpenConns }},
+	{key: "database.pool.max_idle_conns", env: "DB_MAX_IDLE_CONNS", usage: "max idle connections",
+		ptr: func(c *Config) interface{} { return &c.Database.Pool.MaxIdleConns }},
+	{key: "database.pool.conn_max_lifetime", env: "DB_CONN_MAX_LIFETIME", usage: "max connection lifetime",
+		ptr: func(c *Config) interface{} { return &c.Database.Pool.ConnMaxLifetime }},
+	{key: "database.pool.conn_max_idle_time", env: "DB_CONN_MAX_IDLE_TIME", usage: "max connection idle time",
+		ptr: func(c *Config) interface{} { return &c.Database.Pool.ConnMaxIdleTime }},
+	{key: "database.pool.saturation_threshold", env: "DB_POOL_SATURATION_THRESHOLD", usage: "share of busy connections that fails readiness",
+		ptr: func(c *Config) interface{} { return &c.Database.Pool.SaturationThreshold }},
+	{key: "database.replicas.urls", env: "DB_REPLICA_URLS", usage: "comma-separated read replica DSNs", secret: true, mask: MaskDSN,
+		ptr: func(c *Config) interface{} { return &c.Database.Replicas.URLs }},
+	{key: "database.replicas.max_lag", env: "DB_REPLICA_MAX_LAG", usage: "max replica lag before reads go to the primary, 0 disables the check",
+		ptr: func(c *Config) interface{} { return &c.Database.Replicas.MaxLag }},
+	{key: "database.replicas.read_your_writes_window", env: "DB_READ_YOUR_WRITES_WINDOW", usage: "how long a client reads from the primary after a write",
+		ptr: func(c *Config) interface{} { return &c.Database.Replicas.ReadYourWritesWindow }},
+
+	{key: "logging.level", env: "LOG_LEVEL", usage: "log level: debug, info, warn, error",
+		ptr: func(c *Config) interface{} { return &c.Logging.Level }},
+	{key: "logging.pretty", env: "LOG_PRETTY", usage: "human-readable console logs",
+		ptr: func(c *Config) interface{} { return &c.Logging.Pretty }},
+
+	{key: "tracing.exporter", env: "OTEL_TRACES_EXPORTER", usage: "traces exporter: none, stdout, otlp",
+		ptr: func(c *Config) interface{} { return &c.Tracing.Exporter }},
+	{key: "tracing.sample_ratio", env: "OTEL_TRACES_SAMPLE_RATIO", usage: "traces sample ratio 0..1",
+		ptr: func(c *Config) interface{} { return &c.Tracing.SampleRatio }},
+
+	{key: "health.check_timeout", env: "HEALTH_CHECK_TIMEOUT", usage: "timeout of a single readiness check",
+		ptr: func(c *Config) interface{} { return &c.Health.CheckTimeout }},
+
+	{key: "auth.enabled", env: "AUTH_ENABLED", usage: "require X-API-Key on /api",
+		ptr: func(c *Config) interface{} { return &c.Auth.Enabled }},
+	{key: "auth.api_keys", env: "AUTH_API_KEYS", usage: "comma-separated API keys", secret: true,
+		ptr: func(c *Config) interface{} { return &c.Auth.APIKeys }},
+
+	{key: "cache.backend", env: "CACHE_BACKEND", usage: "Get/Sum cache: none or memory",
+		ptr: func(c *Config) interface{} { return &c.Cache.Backend }},
+	{key: "cache.size", env: "CACHE_SIZE", usage: "max entries of the in-memory cache",
+		ptr: func(c *Config) interface{} { return &c.Cache.Size }},
+	{key: "cache.get_ttl", env: "CACHE_GET_TTL", usage: "TTL of cached Get, 0 disables; also bounds staleness after subctl writes",
+		ptr: func(c *Config) interface{} { return &c.Cache.GetTTL }},
+	{key: "cache.sum_ttl", env: "CACHE_SUM_TTL", usage: "TTL of cached Sum, 0 disables; also bounds staleness after subctl writes",
+		ptr: func(c *Config) interface{} { return &c.Cache.SumTTL }},
+	{key: "cache.analytics_ttl", env: "CACHE_ANALYTICS_TTL", usage: "TTL of cached revenue of closed months, 0 disables",
+		ptr: func(c *Config) interface{} { return &c.Cache.AnalyticsTTL }},
+
+	{key: "outbox.sink", env: "OUTBOX_SINK", usage: "domain events sink: none, webhook, file or nats",
+		ptr: func(c *Config) interface{} { return &c.Outbox.Sink }},
+	{key: "outbox.webhook_url", env: "OUTBOX_WEBHOOK_URL", usage: "URL that receives events as POST requests", secret: true, mask: MaskDSN,
+		ptr: func(c *Config) interface{} { return &c.Outbox.WebhookURL }},
+	{key: "outbox.webhook_timeout", env: "OUTBOX_WEBHOOK_TIMEOUT", usage: "timeout of a single webhook request",
+		ptr: func(c *Config) interface{} { return &c.Outbox.WebhookTimeout }},
+	{key: "outbox.file_path", env: "OUTBOX_FILE_PATH", usage: "JSON Lines file the file sink appends events to",
+		ptr: func(c *Config) interface{} { return &c.Outbox.FilePath }},
+	{key: "outbox.nats_url", env: "OUTBOX_NATS_URL", usage: "NATS server the nats sink publishes to (nats:// or tls://)", secret: true, mask: MaskDSN,
+		ptr: func(c *Config) interface{} { return &c.Outbox.NATSURL }},
+	{key: "outbox.nats_subject", env: "OUTBOX_NATS_SUBJECT", usage: "subject prefix: events go to <prefix>.<event type>",
+		ptr: func(c *Config) interface{} { return &c.Outbox.NATSSubject }},
+	{key: "outbox.nats_timeout", env: "OUTBOX_NATS_TIMEOUT", usage: "timeout of a single NATS publish",
+		ptr: func(c *Config) interface{} { return &c.Outbox.NATSTimeout }},
+	{key: "outbox.nats_jetstream", env: "OUTBOX_NATS_JETSTREAM", usage: "wait for the JetStream stream to acknowledge each event",
+		ptr: func(c *Config) interface{} { return &c.Outbox.NATSJetStream }},
+	{key: "outbox.poll_interval", env: "OUTBOX_POLL_INTERVAL", usage: "how often the relay looks for new events",
+		ptr: func(c *Config) interface{} { return &c.Outbox.PollInterval }},
+	{key: "outbox.batch_size", env: "OUTBOX_BATCH_SIZE", usage: "events fetched by the relay at once",
+		ptr: func(c *Config) interface{} { return &c.Outbox.BatchSize }},
+	{key: "outbox.retry_min", env: "OUTBOX_RETRY_MIN", usage: "delay before the first retry of a failed event",
+		ptr: func(c *Config) interface{} { return &c.Outbox.RetryMin }},
+	{key: "outbox.retry_max", env: "OUTBOX_RETRY_MAX", usage: "max delay between retries",
+		ptr: func(c *Config) interface{} { return &c.Outbox.RetryMax }},
+	{key: "outbox.expire_schedule", env: "OUTBOX_EXPIRE_SCHEDULE", usage: "cron schedule of checking ended subscriptions for SubscriptionExpired",
+		ptr: func(c *Config) interface{} { return &c.Outbox.ExpireSchedule }},
+
+	{key: "webhooks.enabled", env: "WEBHOOKS_ENABLED", usage: "serve /webhooks and deliver events to registered endpoints",
+		ptr: func(c *Config) interface{} { return &c.Webhooks.Enabled }},
+	{key: "webhooks.timeout", env: "WEBHOOKS_TIMEOUT", usage: "timeout of a single delivery request",
+		ptr: func(c *Config) interface{} { return &c.Webhooks.Timeout }},
+	{key: "webhooks.max_attempts", env: "WEBHOOKS_MAX_ATTEMPTS", usage: "attempts before a delivery is marked failed",
+		ptr: func(c *Config) interface{} { return &c.Webhooks.MaxAttempts }},
+	{key: "webhooks.retry_min", env: "WEBHOOKS_RETRY_MIN", usage: "delay before the first retry of a failed delivery",
+		ptr: func(c *Config) interface{} { return &c.Webhooks.RetryMin }},
+	{key: "webhooks.retry_max", env: "WEBHOOKS_RETRY_MAX", usage: "max delay between delivery retries",
+		ptr: func(c *Config) interface{} { return &c.Webhooks.RetryMax }},
+	{key: "webhooks.poll_interval", env: "WEBHOOKS_POLL_INTERVAL", usage: "how often the sender looks for due deliveries",
+		ptr: func(c *Config) interface{} { return &c.Webhooks.PollInterval }},
+	{key: "webhooks.batch_size", env: "WEBHOOKS_BATCH_SIZE", usage: "deliveries sent per batch",
+		ptr: func(c *Config) interface{} { return &c.Webhooks.BatchSize }},
+
+	{key: "reminders.enabled", env: "REMINDERS_ENABLED", usage: "send reminders about ending subscriptions and serve reminder preferences",
+		ptr: func(c *Config) interface{} { return &c.Reminders.Enabled }},
+	{key: "reminders.schedule", env: "REMINDERS_SCHEDULE", usage: "cron schedule of checking subscriptions for due reminders",
+		ptr: func(c *Config) interface{} { return &c.Reminders.Schedule }},
+	{key: "reminders.default_days_before", env: "REMINDERS_DEFAULT_DAYS_BEFORE", usage: "days before the end to remind users without saved preferences",
+		ptr: func(c *Config) interface{} { return &c.Reminders.DefaultDaysBefore }},
+	{key: "reminders.lease", env: "REMINDERS_LEASE", usage: "how long an instance owns a claimed reminder before another may send it",
+		ptr: func(c *Config) interface{} { return &c.Reminders.Lease }},
+	{key: "reminders.webhook_timeout", env: "REMINDERS_WEBHOOK_TIMEOUT", usage: "timeout of a single reminder webhook request",
+		ptr: func(c *Config) interface{} { return &c.Reminders.WebhookTimeout }},
+	{key: "reminders.smtp.host", env: "SMTP_HOST", usage: "SMTP server for email reminders, empty disables the email channel",
+		ptr: func(c *Config) interface{} { return &c.Reminders.SMTP.Host }},
+	{key: "reminders.smtp.port", env: "SMTP_PORT", usage: "SMTP port: 465 for implicit TLS, otherwise STARTTLS when offered",
+		ptr: func(c *Config) interface{} { return &c.Reminders.SMTP.Port }},
+	{key: "reminders.smtp.username", env: "SMTP_USERNAME", usage: "SMTP username, empty disables authentication",
+		ptr: func(c *Config) interface{} { return &c.Reminders.SMTP.Username }},
+	{key: "reminders.smtp.password", env: "SMTP_PASSWORD", usage: "SMTP password", secret: true,
+		ptr: func(c *Config) interface{} { return &c.Reminders.SMTP.Password }},
+	{key: "reminders.smtp.from", env: "SMTP_FROM", usage: "sender address of reminder emails",
+		ptr: func(c *Config) interface{} { return &c.Reminders.SMTP.From }},
+	{key: "reminders.smtp.timeout", env: "SMTP_TIMEOUT", usage: "timeout of sending a single email",
+		ptr: func(c *Config) interface{} { return &c.Reminders.SMTP.Timeout }},
+
+	{key: "budgets.enabled", env: "BUDGETS_ENABLED", usage: "serve /budgets and check subscriptions against user budgets",
+		ptr: func(c *Config) interface{} { return &c.Budgets.Enabled }},
+
+	{key: "jobs.election_interval", env: "JOBS_ELECTION_INTERVAL", usage: "how often an instance tries to become the scheduler leader",
+		ptr: func(c *Config) interface{} { return &c.Jobs.ElectionInterval }},
+	{key: "jobs.timeout", env: "JOBS_TIMEOUT", usage: "max duration of a single job run",
+		ptr: func(c *Config) interface{} { return &c.Jobs.Timeout }},
+	{key: "jobs.purge_schedule", env: "JOBS_PURGE_SCHEDULE", usage: "cron schedule of purging old job run history",
+		ptr: func(c *Config) interface{} { return &c.Jobs.PurgeSchedule }},
+	{key: "jobs.history_retention", env: "JOBS_HISTORY_RETENTION", usage: "how long finished job runs are kept",
+		ptr: func(c *Config) interface{} { return &c.Jobs.HistoryRetention }},
+
+	{key: "features.swagger", env: "FEATURE_SWAGGER", usage: "serve /swagger",
+		ptr: func(c *Config) interface{} { return &c.Features.Swagger }},
+}
+
+// Load собирает конфиг из трёх источников по возрастанию приоритета:
+// YAML-файл (--config, CONFIG_FILE или ./config.yaml), переменные окружения
+// (в т.ч. из .env), флаги командной строки.
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	configFile := fs.String("config", "", "path to YAML config file")
+	printConfig := fs.Bool("print-config", false, "print resulting config with secrets masked and exit")
+
+	flagValues := map[string]string{}
+	for _, f := range fields {
+		f := f
+		fs.Func(f.key, f.usage, func(v string) error {
+			flagValues[f.key] = v
+			return nil
+		})
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	cfg := Default()
+	cfg.PrintConfig = *printConfig
+	cfg.Args = fs.Args()
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if err := loadFile(cfg, path); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+
+	for _, f := range fields {
+		value, ok := os.LookupEnv(f.env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := setValue(f.ptr(cfg), value); err != nil {
+			errs = append(errs, fmt.Errorf("%s (env %s): %w", f.key, f.env, err))
+		}
+	}
+
+	for _, f := range fields {
+		value, ok := flagValues[f.key]
+		if !ok {
+			continue
+		}
+		if err := setValue(f.ptr(cfg), value); err != nil {
+			errs = append(errs, fmt.Errorf("%s (flag --%s): %w", f.key, f.key, err))
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return cfg, nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func setValue(ptr interface{}, value string) error {
+	switch p := ptr.(type) {
+	case *string:
+		*p = value
+	case *int:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*p = v
+	case *float64:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		*p = v
+	case *bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		*p = v
+	case *time.Duration:
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		*p = v
+	case *[]string:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*p = items
+	default:
+		return fmt.Errorf("unsupported type %T", ptr)
+	}
+
+	return nil
+}
+
+// Usage печатает список флагов вместе с соответствующими переменными окружения.
+func Usage(w io.Writer) {
+	fmt.Fprintln(w, "Flags (override env, which overrides the config file):")
+	fmt.Fprintln(w, "  --config string\n\tpath to YAML config file (env CONFIG_FILE)")
+	fmt.Fprintln(w, "  --print-config\n\tprint resulting config with secrets masked and exit")
+	for _, f := range fields {
+		fmt.Fprintf(w, "  --%s\n\t%s (env %s)\n", f.key, f.usage, f.env)
+	}
+}
@@ -1,20 +1,176 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
 	"testingtask/internal/config"
+	"testingtask/internal/migrator"
+	"testingtask/migrations"
 
+	"github.com/glebarez/sqlite"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
 var DB *gorm.DB
 
-func InitDB(cfg *config.Config) (*gorm.DB, error) {
-	dsn := cfg.DatabaseURL
-	DB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// InitDB открывает базу по схеме DATABASE_URL: sqlite:// — файл SQLite,
+// всё остальное передаётся драйверу Postgres.
+func InitDB(ctx context.Context, cfg *config.Config) (*gorm.DB, error) {
+	dsn := cfg.Database.URL
+
+	var dialector gorm.Dialector
+	if IsSQLite(dsn) {
+		dialector = sqlite.Open(sqliteDSN(dsn))
+	} else {
+		pgDB, err := openPostgres(dsn)
+		if err != nil {
+			return nil, err
+		}
+		dialector = postgres.New(postgres.Config{Conn: pgDB})
+	}
+
+	// Пинг ниже с ctx; автоматический пинг GORM к тому же проверял бы
+	// реплики при подключении, а недоступная реплика не должна мешать
+	// старту — чтение просто уйдёт на primary.
+	DB, err := gorm.Open(dialector, &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		return nil, fmt.Errorf("connect database: %w", err)
+	}
+
+	sqlDB, err := DB.DB()
 	if err != nil {
-		panic("failed to connect database")
+		return nil, fmt.Errorf("get sql.DB: %w", err)
+	}
+
+	configurePool(sqlDB, cfg.Database.Pool)
+
+	// SQLite допускает одного писателя, а :memory: у каждого соединения
+	// своя, поэтому держим ровно одно соединение.
+	if IsSQLite(dsn) {
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("ping database: %w", err)
+	}
+
+	// Спан на каждый SQL-запрос, дочерний к спану запроса/сервиса из ctx.
+	if err := DB.Use(tracing.NewPlugin(tracing.WithoutMetrics())); err != nil {
+		return nil, err
+	}
+
+	if err := registerSQLCommenter(DB); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Database.Replicas.URLs) > 0 {
+		rs, err := newReplicaSet(cfg)
+		if err != nil {
+			_ = sqlDB.Close()
+			return nil, err
+		}
+		if err := DB.Use(rs); err != nil {
+			_ = rs.close()
+			_ = sqlDB.Close()
+			return nil, fmt.Errorf("register replicas: %w", err)
+		}
+		rs.start(ctx)
 	}
 
 	return DB, nil
 }
+
+// openPostgres открывает пул database/sql поверх pgx в режиме
+// QueryExecModeExec: запросы не подготавливаются и не попадают в кэш
+// statements. У каждого запроса GORM свой sqlcommenter-комментарий
+// (request_id, traceparent), и в кэше по тексту SQL они бы не совпадали:
+// лишний prepare на каждый запрос и вытеснение из кэша. Exec отправляет
+// запрос с параметрами за один проход, типы параметров выводит сервер.
+// Репозиторий pgx (DB_REPOSITORY=pgx) работает через свой пул с
+// подготовленными statements и без комментариев.
+func openPostgres(dsn string) (*sql.DB, error) {
+	pgxCfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse database url %s: %w", config.MaskDSN(dsn), err)
+	}
+	pgxCfg.DefaultQueryExecMode = pgx.QueryExecModeExec
+	return stdlib.OpenDB(*pgxCfg), nil
+}
+
+func configurePool(sqlDB *sql.DB, pool config.PoolConfig) {
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+}
+
+// Close закрывает пул соединений GORM и пулы реплик.
+func Close(db *gorm.DB) error {
+	if rs, ok := db.Config.Plugins[replicasPluginName].(*replicaSet); ok {
+		_ = rs.close()
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// NewMigrator возвращает мигратор со встроенными миграциями для СУБД db.
+func NewMigrator(db *gorm.DB) (*migrator.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	if db.Dialector.Name() == "sqlite" {
+		return migrator.New(sqlDB, migrations.SQLite, migrator.SQLite)
+	}
+	return migrator.New(sqlDB, migrations.FS, migrator.Postgres)
+}
+
+// MemoryURL — значение DATABASE_URL, при котором вместо Postgres
+// используется репозиторий в памяти (тесты, локальная разработка).
+const MemoryURL = "memory://"
+
+// IsMemory сообщает, что dsn выбирает хранилище в памяти.
+func IsMemory(dsn string) bool {
+	return strings.HasPrefix(dsn, MemoryURL)
+}
+
+// SQLiteScheme — префикс DATABASE_URL для SQLite: sqlite://subs.db,
+// sqlite:///var/lib/subs.db или sqlite://:memory:.
+const SQLiteScheme = "sqlite://"
+
+// IsSQLite сообщает, что dsn выбирает SQLite.
+func IsSQLite(dsn string) bool {
+	return strings.HasPrefix(dsn, SQLiteScheme)
+}
+
+// sqliteDSN переводит sqlite://path в DSN драйвера: внешние ключи,
+// ожидание блокировки вместо SQLITE_BUSY и формат времени, который
+// понимают date() и сравнение строк.
+func sqliteDSN(dsn string) string {
+	path := strings.TrimPrefix(dsn, SQLiteScheme)
+
+	params := "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite"
+	if path != ":memory:" {
+		params += "&_pragma=journal_mode(WAL)"
+	}
+
+	if strings.Contains(path, "?") {
+		return path + "&" + params
+	}
+	return path + "?" + params
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testingtask/internal/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// InitPgxPool открывает пул pgx с настройками пула из конфига.
+// afterConnect вызывается на каждом новом соединении, например для
+// подготовки statements.
+func InitPgxPool(ctx context.Context, cfg *config.Config, afterConnect func(context.Context, *pgx.Conn) error) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.Database.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse pgx config: %w", err)
+	}
+
+	pool := cfg.Database.Pool
+	if pool.MaxOpenConns > 0 {
+		poolCfg.MaxConns = int32(pool.MaxOpenConns)
+	}
+	if pool.ConnMaxLifetime > 0 {
+		poolCfg.MaxConnLifetime = pool.ConnMaxLifetime
+	}
+	if pool.ConnMaxIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = pool.ConnMaxIdleTime
+	}
+	poolCfg.AfterConnect = afterConnect
+
+	p, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("connect pgx pool: %w", err)
+	}
+
+	if err := p.Ping(ctx); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("ping pgx pool: %w", err)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testingtask/internal/config"
+	logger "testingtask/pkg"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	replicasPluginName   = "testingtask:replicas"
+	replicaCheckInterval = 5 * time.Second
+)
+
+// replicaLagQuery возвращает отставание реплики в секундах. Если всё
+// полученное уже применено, реплика не отстаёт, даже когда на primary
+// давно не было записей и pg_last_xact_replay_timestamp() старый.
+const replicaLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+type replica struct {
+	// name — DSN со скрытым паролем, для логов.
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+	checked atomic.Bool
+}
+
+// replicaSet — плагин GORM, который подключает реплики через dbresolver
+// и следит за их отставанием. Чтение (SELECT вне транзакции) уходит на
+// случайную реплику, отстающую не больше maxLag; если таких нет — на
+// primary. Запись, транзакции и SELECT ... FOR UPDATE всегда идут на
+// primary.
+type replicaSet struct {
+	primary  gorm.ConnPool
+	replicas []*replica
+	maxLag   time.Duration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func newReplicaSet(cfg *config.Config) (*replicaSet, error) {
+	rs := &replicaSet{
+		maxLag: cfg.Database.Replicas.MaxLag,
+		stop:   make(chan struct{}),
+	}
+
+	for _, dsn := range cfg.Database.Replicas.URLs {
+		// Тот же режим без кэша statements, что и у primary.
+		db, err := openPostgres(dsn)
+		if err != nil {
+			_ = rs.closeReplicas()
+			return nil, err
+		}
+		configurePool(db, cfg.Database.Pool)
+		rs.replicas = append(rs.replicas, &replica{name: config.MaskDSN(dsn), db: db})
+	}
+
+	return rs, nil
+}
+
+func (rs *replicaSet) Name() string {
+	return replicasPluginName
+}
+
+func (rs *replicaSet) Initialize(db *gorm.DB) error {
+	rs.primary = db.ConnPool
+
+	// Primary в списке — запасной вариант чтения. Заодно пулов всегда
+	// больше одного: с единственной репликой dbresolver не вызывает
+	// Resolve и проверка отставания не работала бы.
+	dialectors := make([]gorm.Dialector, 0, len(rs.replicas)+1)
+	for _, r := range rs.replicas {
+		dialectors = append(dialectors, postgres.New(postgres.Config{Conn: r.db}))
+	}
+	dialectors = append(dialectors, postgres.New(postgres.Config{Conn: rs.primary}))
+
+	return db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   rs,
+	}))
+}
+
+// Resolve реализует dbresolver.Policy.
+func (rs *replicaSet) Resolve([]gorm.ConnPool) gorm.ConnPool {
+	healthy := make([]gorm.ConnPool, 0, len(rs.replicas))
+	for _, r := range rs.replicas {
+		if r.healthy.Load() {
+			healthy = append(healthy, r.db)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return rs.primary
+	}
+	return healthy[rand.Intn(len(healthy))]
+}
+
+// start проверяет реплики и дальше повторяет проверку раз в
+// replicaCheckInterval до close.
+func (rs *replicaSet) start(ctx context.Context) {
+	rs.checkAll(ctx)
+
+	rs.done = make(chan struct{})
+	go func() {
+		defer close(rs.done)
+
+		ticker := time.NewTicker(replicaCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-rs.stop:
+				return
+			case <-ticker.C:
+				rs.checkAll(context.Background())
+			}
+		}
+	}()
+}
+
+func (rs *replicaSet) checkAll(ctx context.Context) {
+	for _, r := range rs.replicas {
+		rs.check(ctx, r)
+	}
+}
+
+func (rs *replicaSet) check(ctx context.Context, r *replica) {
+	ctx, cancel := context.WithTimeout(ctx, replicaCheckInterval)
+	defer cancel()
+
+	var lagSeconds float64
+	err := r.db.QueryRowContext(ctx, replicaLagQuery).Scan(&lagSeconds)
+	lag := time.Duration(lagSeconds * float64(time.Second))
+
+	healthy := err == nil && (rs.maxLag == 0 || lag <= rs.maxLag)
+	// Логируем первую проверку и смену состояния, а не каждый тик.
+	first := !r.checked.Swap(true)
+	if r.healthy.Swap(healthy) == healthy && !first {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"replica": r.name,
+		"lag":     lag.String(),
+		"max_lag": rs.maxLag.String(),
+	}
+	switch {
+	case healthy:
+		logger.Info(ctx, "database: replica is in rotation", fields)
+	case err != nil:
+		logger.Error(ctx, "database: replica is unreachable, reads go elsewhere", err, fields)
+	default:
+		logger.Warn(ctx, "database: replica lags behind, reads go elsewhere", fields)
+	}
+}
+
+func (rs *replicaSet) close() error {
+	rs.stopOnce.Do(func() {
+		close(rs.stop)
+		if rs.done != nil {
+			<-rs.done
+		}
+	})
+	return rs.closeReplicas()
+}
+
+func (rs *replicaSet) closeReplicas() error {
+	var firstErr error
+	for _, r := range rs.replicas {
+		if err := r.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
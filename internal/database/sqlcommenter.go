@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"testingtask/internal/requestid"
+
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// registerSQLCommenter добавляет к каждому запросу комментарий в формате
+// sqlcommenter (/*request_id='...',traceparent='...'*/), чтобы запросы из
+// логов Postgres (log_min_duration_statement, pg_stat_activity) можно
+// было связать с HTTP-запросом и трейсом.
+func registerSQLCommenter(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("sqlcommenter:create", commentClause("INSERT")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("sqlcommenter:query", commentClause("SELECT")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("sqlcommenter:update", commentClause("UPDATE")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("sqlcommenter:delete", commentClause("DELETE")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("sqlcommenter:row", commentClause("SELECT")); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("sqlcommenter:raw", commentClause("")); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func commentClause(name string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.Statement.Context == nil {
+			return
+		}
+
+		comment := sqlComment(db.Statement.Context)
+		if comment == "" {
+			return
+		}
+
+		// Raw/Exec: SQL уже собран, клауз нет.
+		if name == "" || db.Statement.SQL.Len() > 0 {
+			sql := db.Statement.SQL.String()
+			db.Statement.SQL.Reset()
+			db.Statement.SQL.WriteString(comment)
+			db.Statement.SQL.WriteByte(' ')
+			db.Statement.SQL.WriteString(sql)
+			return
+		}
+
+		c := db.Statement.Clauses[name]
+		c.Name = name
+		c.BeforeExpression = clause.Expr{SQL: comment}
+		db.Statement.Clauses[name] = c
+	}
+}
+
+func sqlComment(ctx context.Context) string {
+	tags := map[string]string{}
+
+	if id := requestid.FromContext(ctx); id != "" {
+		tags["request_id"] = id
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		tags["traceparent"] = fmt.Sprintf("00-%s-%s-%s", sc.TraceID(), sc.SpanID(), sc.TraceFlags())
+	}
+
+	if len(tags) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s='%s'", url.QueryEscape(k), url.QueryEscape(tags[k])))
+	}
+
+	return "/*" + strings.Join(parts, ",") + "*/"
+}
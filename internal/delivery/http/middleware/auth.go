@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuth пропускает только запросы с одним из разрешённых ключей в X-API-Key.
+func APIKeyAuth(keys []string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			got := []byte(c.Request().Header.Get(APIKeyHeader))
+			if len(got) == 0 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing api key")
+			}
+
+			for _, key := range keys {
+				if subtle.ConstantTimeCompare(got, []byte(key)) == 1 {
+					return next(c)
+				}
+			}
+
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid api key")
+		}
+	}
+}
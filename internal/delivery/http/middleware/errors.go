@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"testingtask/internal/requestid"
+	"testingtask/internal/web/subscriptions"
+	logger "testingtask/pkg"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HTTPErrorHandler отдаёт ошибки echo (биндинг, 404 маршрута и т.п.)
+// в том же формате, что и хендлеры: {"error": ..., "request_id": ...}.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	code := http.StatusInternalServerError
+	msg := http.StatusText(code)
+
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		code = he.Code
+		if m, ok := he.Message.(string); ok {
+			msg = m
+		} else {
+			msg = http.StatusText(code)
+		}
+	}
+
+	ctx := c.Request().Context()
+	if code >= http.StatusInternalServerError {
+		logger.Error(ctx, "unhandled error", err, nil)
+	}
+
+	resp := subscriptions.ErrorResponse{Error: msg}
+	if id := requestid.FromContext(ctx); id != "" {
+		resp.RequestId = &id
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(code)
+	} else {
+		err = c.JSON(code, resp)
+	}
+	if err != nil {
+		logger.Error(ctx, "failed to write error response", err, nil)
+	}
+}
@@ -1,16 +1,25 @@
 package middleware
 
 import (
+	"testingtask/internal/requestid"
 	logger "testingtask/pkg"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func RequestLoggerMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		reqID := uuid.New().String()
+		reqID := c.Request().Header.Get(requestid.Header)
+		if !requestid.Valid(reqID) {
+			reqID = requestid.New()
+		}
+		c.Response().Header().Set(requestid.Header, reqID)
+
+		trace.SpanFromContext(c.Request().Context()).
+			SetAttributes(attribute.String("http.request_id", reqID))
 
 		l := logger.With(map[string]interface{}{
 			"request_id": reqID,
@@ -19,7 +28,8 @@ func RequestLoggerMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			"remote_ip":  c.RealIP(),
 		})
 
-		ctx := logger.WithContext(c.Request().Context(), l)
+		ctx := requestid.WithContext(c.Request().Context(), reqID)
+		ctx = logger.WithContext(ctx, l)
 		c.SetRequest(c.Request().WithContext(ctx))
 
 		start := time.Now()
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"testingtask/internal/readpref"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReadYourWrites отправляет чтение на primary, если в запросе есть
+// заголовок X-Read-Primary или cookie read_primary. После успешного
+// изменяющего запроса cookie выставляется на window, чтобы клиент сразу
+// видел свои изменения, пока реплики их догоняют. window 0 — cookie не
+// выставляется.
+func ReadYourWrites(window time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			if primaryRequested(req) {
+				c.SetRequest(req.WithContext(readpref.WithPrimary(req.Context())))
+			}
+
+			if window > 0 && isMutation(req.Method) {
+				res := c.Response()
+				res.Before(func() {
+					if res.Status < http.StatusBadRequest {
+						c.SetCookie(&http.Cookie{
+							Name:     readpref.Cookie,
+							Value:    "1",
+							Path:     "/",
+							MaxAge:   int(math.Ceil(window.Seconds())),
+							HttpOnly: true,
+							SameSite: http.SameSiteLaxMode,
+						})
+					}
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func primaryRequested(req *http.Request) bool {
+	if req.Header.Get(readpref.Header) != "" {
+		return true
+	}
+	_, err := req.Cookie(readpref.Cookie)
+	return err == nil
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
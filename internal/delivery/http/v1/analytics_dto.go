@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"testingtask/internal/domain/analytics"
+	domain "testingtask/internal/domain/subscription"
+	web "testingtask/internal/web/analytics"
+	"time"
+)
+
+// Типы ниже описывают ответы /analytics для swagger.
+
+type MRRPointDTO struct {
+	Month               string `json:"month" example:"07-2025"`
+	MRR                 int    `json:"mrr" example:"15400"`
+	New                 int    `json:"new" example:"1200"`
+	Expansion           int    `json:"expansion" example:"300"`
+	Contraction         int    `json:"contraction" example:"100"`
+	Churned             int    `json:"churned" example:"800"`
+	NetNew              int    `json:"net_new" example:"600"`
+	ActiveSubscribers   int    `json:"active_subscribers" example:"42"`
+	ActiveSubscriptions int    `json:"active_subscriptions" example:"57"`
+}
+
+type MRRReportDTO struct {
+	From        string        `json:"from" example:"01-2025"`
+	To          string        `json:"to" example:"12-2025"`
+	ServiceName *string       `json:"service_name,omitempty" example:"Yandex Plus"`
+	Points      []MRRPointDTO `json:"points"`
+}
+
+type ChurnPointDTO struct {
+	Month       string  `json:"month" example:"07-2025"`
+	Active      int     `json:"active" example:"18"`
+	Subscribers int     `json:"subscribers" example:"20"`
+	Churned     int     `json:"churned" example:"3"`
+	ChurnRate   float64 `json:"churn_rate" example:"0.15"`
+}
+
+type ServiceChurnDTO struct {
+	ServiceName string          `json:"service_name" example:"Yandex Plus"`
+	Subscribers int             `json:"subscribers" example:"240"`
+	Churned     int             `json:"churned" example:"12"`
+	ChurnRate   float64         `json:"churn_rate" example:"0.05"`
+	Points      []ChurnPointDTO `json:"points"`
+}
+
+type ChurnReportDTO struct {
+	From     string            `json:"from" example:"01-2025"`
+	To       string            `json:"to" example:"12-2025"`
+	Services []ServiceChurnDTO `json:"services"`
+}
+
+// AnalyticsRange разбирает from и to отчёта. По умолчанию to — текущий
+// месяц, from — за 11 месяцев до to.
+func AnalyticsRange(from, to *string) (time.Time, time.Time, error) {
+	end := analytics.StartOfMonth(time.Now())
+	if to != nil {
+		d, err := domain.ParseSubDate(*to)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = d.Time
+	}
+
+	start := end.AddDate(0, -11, 0)
+	if from != nil {
+		d, err := domain.ParseSubDate(*from)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = d.Time
+	}
+	return start, end, nil
+}
+
+func MRRToResponse(from, to time.Time, serviceName *string, points []analytics.MRRPoint) web.MRRReport {
+	res := web.MRRReport{
+		From:        from.Format("01-2006"),
+		To:          to.Format("01-2006"),
+		ServiceName: serviceName,
+		Points:      make([]web.MRRPoint, 0, len(points)),
+	}
+	for _, p := range points {
+		res.Points = append(res.Points, web.MRRPoint{
+			Month:               p.Month.Format("01-2006"),
+			Mrr:                 p.MRR,
+			New:                 p.New,
+			Expansion:           p.Expansion,
+			Contraction:         p.Contraction,
+			Churned:             p.Churned,
+			NetNew:              p.NetNew,
+			ActiveSubscribers:   p.ActiveSubscribers,
+			ActiveSubscriptions: p.ActiveSubscriptions,
+		})
+	}
+	return res
+}
+
+func ChurnToResponse(from, to time.Time, services []analytics.ServiceChurn) web.ChurnReport {
+	res := web.ChurnReport{
+		From:     from.Format("01-2006"),
+		To:       to.Format("01-2006"),
+		Services: make([]web.ServiceChurn, 0, len(services)),
+	}
+	for _, s := range services {
+		sc := web.ServiceChurn{
+			ServiceName: s.ServiceName,
+			Subscribers: s.Subscribers,
+			Churned:     s.Churned,
+			ChurnRate:   s.Rate(),
+			Points:      make([]web.ChurnPoint, 0, len(s.Points)),
+		}
+		for _, p := range s.Points {
+			sc.Points = append(sc.Points, web.ChurnPoint{
+				Month:       p.Month.Format("01-2006"),
+				Active:      p.Active,
+				Subscribers: p.Subscribers,
+				Churned:     p.Churned,
+				ChurnRate:   p.Rate(),
+			})
+		}
+		res.Services = append(res.Services, sc)
+	}
+	return res
+}
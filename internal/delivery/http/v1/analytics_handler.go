@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"context"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/service"
+	web "testingtask/internal/web/analytics"
+	logger "testingtask/pkg"
+)
+
+type AnalyticsHandler struct {
+	serv service.AnalyticsService
+}
+
+func NewAnalyticsHandler(s service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{serv: s}
+}
+
+// GetMRR Получить MRR по месяцам
+// @Summary Получить MRR по месяцам
+// @Description Считает MRR каждого месяца диапазона и его движение к предыдущему месяцу по клиентам (user_id): new, expansion, contraction и churned. Закончившиеся месяцы кэшируются
+// @Tags analytics
+// @Produce json
+// @Param from query string false "Первый месяц (MM-YYYY), по умолчанию за 11 месяцев до to"
+// @Param to query string false "Последний месяц (MM-YYYY), по умолчанию текущий"
+// @Param service_name query string false "Только подписки на этот сервис"
+// @Success 200 {object} MRRReportDTO "MRR по месяцам"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректный диапазон"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /analytics/mrr [get]
+func (h *AnalyticsHandler) GetMRR(ctx context.Context, request web.GetMRRRequestObject) (web.GetMRRResponseObject, error) {
+	logger.Info(ctx, "get mrr called", map[string]interface{}{
+		"params": request.Params,
+	})
+
+	from, to, err := AnalyticsRange(request.Params.From, request.Params.To)
+	if err != nil {
+		resp, _ := myerrors.MapError(ctx, err)
+		return web.GetMRR400JSONResponse(resp), nil
+	}
+
+	points, err := h.serv.MRR(ctx, from, to, request.Params.ServiceName)
+	if err != nil {
+		logger.Error(ctx, "error get mrr", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return web.GetMRR400JSONResponse(resp), nil
+		default:
+			return web.GetMRR500JSONResponse(resp), nil
+		}
+	}
+
+	return web.GetMRR200JSONResponse(MRRToResponse(from, to, request.Params.ServiceName, points)), nil
+}
+
+// GetChurn Получить отток по сервисам
+// @Summary Получить отток по сервисам
+// @Description Для каждого сервиса и месяца диапазона считает долю пользователей, подписанных на сервис в прошлом месяце, у которых подписки на него не осталось. Закончившиеся месяцы кэшируются
+// @Tags analytics
+// @Produce json
+// @Param from query string false "Первый месяц (MM-YYYY), по умолчанию за 11 месяцев до to"
+// @Param to query string false "Последний месяц (MM-YYYY), по умолчанию текущий"
+// @Success 200 {object} ChurnReportDTO "Отток по сервисам"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректный диапазон"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /analytics/churn [get]
+func (h *AnalyticsHandler) GetChurn(ctx context.Context, request web.GetChurnRequestObject) (web.GetChurnResponseObject, error) {
+	logger.Info(ctx, "get churn called", map[string]interface{}{
+		"params": request.Params,
+	})
+
+	from, to, err := AnalyticsRange(request.Params.From, request.Params.To)
+	if err != nil {
+		resp, _ := myerrors.MapError(ctx, err)
+		return web.GetChurn400JSONResponse(resp), nil
+	}
+
+	services, err := h.serv.Churn(ctx, from, to)
+	if err != nil {
+		logger.Error(ctx, "error get churn", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return web.GetChurn400JSONResponse(resp), nil
+		default:
+			return web.GetChurn500JSONResponse(resp), nil
+		}
+	}
+
+	return web.GetChurn200JSONResponse(ChurnToResponse(from, to, services)), nil
+}
@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"testingtask/internal/domain/budget"
+	"testingtask/internal/service"
+	"testingtask/internal/web/budgets"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы ниже описывают тела запросов и ответов /budgets для swagger.
+
+type BudgetUpdateRequestDTO struct {
+	MonthlyLimit int  `json:"monthly_limit" example:"1500" minimum:"1"`
+	HardLimit    bool `json:"hard_limit" example:"false"`
+}
+
+type BudgetRequestDTO struct {
+	UserID      uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	ServiceName *string   `json:"service_name" example:"Yandex Plus"`
+	Category    *string   `json:"category" example:"streaming"`
+	BudgetUpdateRequestDTO
+}
+
+type BudgetDTO struct {
+	ID uuid.UUID `json:"id" example:"9c4b7f2e-1d3a-4e5b-8c6d-0a1b2c3d4e5f"`
+	BudgetRequestDTO
+	CreatedAt time.Time `json:"created_at" example:"2026-10-18T16:00:00Z"`
+}
+
+type ListBudgetsResponseDTO struct {
+	Paging Paging      `json:"paging"`
+	Rows   []BudgetDTO `json:"rows"`
+}
+
+type BudgetStatusDTO struct {
+	Budget    BudgetDTO `json:"budget"`
+	Spent     int       `json:"spent" example:"1200"`
+	Remaining int       `json:"remaining" example:"300"`
+	Exceeded  bool      `json:"exceeded" example:"false"`
+}
+
+type BudgetReportDTO struct {
+	UserID  uuid.UUID         `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Month   string            `json:"month" example:"10-2026"`
+	Spent   int               `json:"spent" example:"1200"`
+	Budgets []BudgetStatusDTO `json:"budgets"`
+}
+
+func BudgetRequestToInput(req budgets.BudgetRequest) service.BudgetInput {
+	in := BudgetUpdateToInput(budgets.BudgetUpdateRequest{MonthlyLimit: req.MonthlyLimit, HardLimit: req.HardLimit})
+	in.UserID = req.UserId
+	in.ServiceName = req.ServiceName
+	in.Category = req.Category
+	return in
+}
+
+func BudgetUpdateToInput(req budgets.BudgetUpdateRequest) service.BudgetInput {
+	in := service.BudgetInput{MonthlyLimit: req.MonthlyLimit}
+	if req.HardLimit != nil {
+		in.HardLimit = *req.HardLimit
+	}
+	return in
+}
+
+func BudgetToResponse(b *budget.Budget) budgets.Budget {
+	return budgets.Budget{
+		Id:           b.ID(),
+		UserId:       b.UserID(),
+		ServiceName:  b.ServiceName(),
+		Category:     b.Category(),
+		MonthlyLimit: b.MonthlyLimit(),
+		HardLimit:    b.HardLimit(),
+		CreatedAt:    b.CreatedAt(),
+	}
+}
+
+func BudgetsToResponse(rows []*budget.Budget, paging PagingBase, total int64) budgets.ListBudgets200JSONResponse {
+	res := make([]budgets.Budget, 0, len(rows))
+	for _, b := range rows {
+		res = append(res, BudgetToResponse(b))
+	}
+	t := int(total)
+	return budgets.ListBudgets200JSONResponse{
+		Paging: budgets.Paging{Limit: &paging.Limit, Offset: &paging.Offset, Total: &t},
+		Rows:   res,
+	}
+}
+
+func BudgetReportToResponse(r *service.BudgetReport) budgets.BudgetReport {
+	res := budgets.BudgetReport{
+		UserId:  r.UserID,
+		Month:   r.Month.Format("01-2006"),
+		Spent:   r.Spent,
+		Budgets: make([]budgets.BudgetStatus, 0, len(r.Budgets)),
+	}
+	for _, st := range r.Budgets {
+		res.Budgets = append(res.Budgets, budgets.BudgetStatus{
+			Budget:    BudgetToResponse(st.Budget),
+			Spent:     st.Spent,
+			Remaining: st.Remaining(),
+			Exceeded:  st.Exceeded(),
+		})
+	}
+	return res
+}
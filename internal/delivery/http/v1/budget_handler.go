@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"context"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/service"
+	"testingtask/internal/web/budgets"
+	logger "testingtask/pkg"
+	"time"
+)
+
+type BudgetHandler struct {
+	serv service.BudgetService
+}
+
+func NewBudgetHandler(s service.BudgetService) *BudgetHandler {
+	return &BudgetHandler{serv: s}
+}
+
+// CreateBudget Создать бюджет
+// @Summary Создать бюджет
+// @Description Задаёт месячный лимит трат пользователя на все подписки, на один сервис (service_name) или на категорию каталога сервисов (category) — по подпискам, связанным с сервисом этой категории. У пользователя не больше одного бюджета на каждую из этих областей. С hard_limit подписки, выводящие траты за лимит, отклоняются с 409, без него только записывается событие BudgetExceeded
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param request body BudgetRequestDTO true "Пользователь, сервис или категория и лимит"
+// @Success 201 {object} BudgetDTO "Бюджет создан"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные или неизвестный user_id"
+// @Failure 409 {object} myerrors.ErrorResponse "Бюджет на эту область уже есть"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /budgets [post]
+func (h *BudgetHandler) CreateBudget(ctx context.Context, request budgets.CreateBudgetRequestObject) (budgets.CreateBudgetResponseObject, error) {
+	logger.Info(ctx, "create budget called", map[string]interface{}{
+		"body": request.Body,
+	})
+
+	b, err := h.serv.Create(ctx, BudgetRequestToInput(*request.Body))
+	if err != nil {
+		logger.Error(ctx, "error create budget", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return budgets.CreateBudget400JSONResponse(resp), nil
+		case 409:
+			return budgets.CreateBudget409JSONResponse(resp), nil
+		default:
+			return budgets.CreateBudget500JSONResponse(resp), nil
+		}
+	}
+
+	return budgets.CreateBudget201JSONResponse(BudgetToResponse(b)), nil
+}
+
+// ListBudgets Получить список бюджетов
+// @Summary Получить список бюджетов
+// @Description Возвращает бюджеты всех пользователей или одного с пагинацией
+// @Tags budgets
+// @Produce json
+// @Param user_id query string false "ID пользователя"
+// @Param limit query int false "Количество элементов" default(10)
+// @Param offset query int false "Смещение" default(0)
+// @Success 200 {object} ListBudgetsResponseDTO "Список бюджетов"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные параметры"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /budgets [get]
+func (h *BudgetHandler) ListBudgets(ctx context.Context, request budgets.ListBudgetsRequestObject) (budgets.ListBudgetsResponseObject, error) {
+	logger.Info(ctx, "list budgets called", map[string]interface{}{
+		"params": request.Params,
+	})
+
+	paging, ok := WebhookPaging(request.Params.Limit, request.Params.Offset)
+	if !ok {
+		resp, _ := myerrors.MapError(ctx, myerrors.ErrInvalidData)
+		return budgets.ListBudgets400JSONResponse(resp), nil
+	}
+
+	rows, total, err := h.serv.List(ctx, request.Params.UserId, NewPagingBase(paging))
+	if err != nil {
+		logger.Error(ctx, "error list budgets", err, nil)
+		resp, _ := myerrors.MapError(ctx, err)
+		return budgets.ListBudgets500JSONResponse(resp), nil
+	}
+
+	return BudgetsToResponse(rows, paging, total), nil
+}
+
+// GetBudgetStatus Получить траты пользователя по бюджетам
+// @Summary Получить траты пользователя по бюджетам
+// @Description Считает траты пользователя за месяц по активным подпискам (те же данные, что у /subscriptions/sum) и сравнивает их с каждым его бюджетом
+// @Tags budgets
+// @Produce json
+// @Param user_id query string true "ID пользователя"
+// @Param month query string false "Месяц в формате MM-YYYY, по умолчанию текущий"
+// @Success 200 {object} BudgetReportDTO "Траты и состояние бюджетов"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные параметры"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /budgets/status [get]
+func (h *BudgetHandler) GetBudgetStatus(ctx context.Context, request budgets.GetBudgetStatusRequestObject) (budgets.GetBudgetStatusResponseObject, error) {
+	logger.Info(ctx, "get budget status called", map[string]interface{}{
+		"params": request.Params,
+	})
+
+	month := time.Now()
+	if request.Params.Month != nil {
+		d, err := domain.ParseSubDate(*request.Params.Month)
+		if err != nil {
+			resp, _ := myerrors.MapError(ctx, err)
+			return budgets.GetBudgetStatus400JSONResponse(resp), nil
+		}
+		month = d.Time
+	}
+
+	report, err := h.serv.Status(ctx, request.Params.UserId, month)
+	if err != nil {
+		logger.Error(ctx, "error get budget status", err, nil)
+		resp, _ := myerrors.MapError(ctx, err)
+		return budgets.GetBudgetStatus500JSONResponse(resp), nil
+	}
+
+	return budgets.GetBudgetStatus200JSONResponse(BudgetReportToResponse(report)), nil
+}
+
+// GetBudget Получить бюджет по ID
+// @Summary Получить бюджет по ID
+// @Description Возвращает бюджет по его идентификатору
+// @Tags budgets
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Success 200 {object} BudgetDTO "Бюджет найден"
+// @Failure 404 {object} myerrors.ErrorNotFound "Бюджет не найден"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /budgets/{id} [get]
+func (h *BudgetHandler) GetBudget(ctx context.Context, request budgets.GetBudgetRequestObject) (budgets.GetBudgetResponseObject, error) {
+	logger.Info(ctx, "get budget called", map[string]interface{}{
+		"id": request.Id,
+	})
+
+	b, err := h.serv.Get(ctx, request.Id)
+	if err != nil {
+		logger.Error(ctx, "error get budget", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 404:
+			return budgets.GetBudget404JSONResponse(resp), nil
+		default:
+			return budgets.GetBudget500JSONResponse(resp), nil
+		}
+	}
+
+	return budgets.GetBudget200JSONResponse(BudgetToResponse(b)), nil
+}
+
+// UpdateBudget Обновить бюджет
+// @Summary Обновить бюджет
+// @Description Меняет лимит и hard_limit бюджета. Пользователь и сервис бюджета не меняются. Уже сохранённые подписки новый лимит не затрагивает
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Param request body BudgetUpdateRequestDTO true "Лимит"
+// @Success 200 {object} BudgetDTO "Бюджет обновлён"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные"
+// @Failure 404 {object} myerrors.ErrorNotFound "Бюджет не найден"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /budgets/{id} [put]
+func (h *BudgetHandler) UpdateBudget(ctx context.Context, request budgets.UpdateBudgetRequestObject) (budgets.UpdateBudgetResponseObject, error) {
+	logger.Info(ctx, "update budget called", map[string]interface{}{
+		"id":   request.Id,
+		"body": request.Body,
+	})
+
+	b, err := h.serv.Update(ctx, request.Id, BudgetUpdateToInput(*request.Body))
+	if err != nil {
+		logger.Error(ctx, "error update budget", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return budgets.UpdateBudget400JSONResponse(resp), nil
+		case 404:
+			return budgets.UpdateBudget404JSONResponse(resp), nil
+		default:
+			return budgets.UpdateBudget500JSONResponse(resp), nil
+		}
+	}
+
+	return budgets.UpdateBudget200JSONResponse(BudgetToResponse(b)), nil
+}
+
+// DeleteBudget Удалить бюджет
+// @Summary Удалить бюджет
+// @Description Удаляет бюджет. Отсутствующий бюджет не ошибка
+// @Tags budgets
+// @Param id path string true "ID бюджета"
+// @Success 204 "Бюджет удалён"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /budgets/{id} [delete]
+func (h *BudgetHandler) DeleteBudget(ctx context.Context, request budgets.DeleteBudgetRequestObject) (budgets.DeleteBudgetResponseObject, error) {
+	logger.Info(ctx, "delete budget called", map[string]interface{}{
+		"id": request.Id,
+	})
+
+	if err := h.serv.Delete(ctx, request.Id); err != nil {
+		logger.Error(ctx, "error delete budget", err, nil)
+		resp, _ := myerrors.MapError(ctx, err)
+		return budgets.DeleteBudget500JSONResponse(resp), nil
+	}
+
+	return budgets.DeleteBudget204Response{}, nil
+}
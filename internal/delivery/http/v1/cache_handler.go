@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"net/http"
+	"testingtask/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CacheHandler struct {
+	svc service.CachedSubService
+}
+
+func NewCacheHandler(s service.CachedSubService) *CacheHandler {
+	return &CacheHandler{svc: s}
+}
+
+// Stats — попадания и промахи кэша по операциям с момента старта.
+func (h *CacheHandler) Stats(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.svc.Stats())
+}
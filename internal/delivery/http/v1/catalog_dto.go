@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"testingtask/internal/domain/catalog"
+	"testingtask/internal/service"
+	"testingtask/internal/web/services"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы ниже описывают тела запросов и ответов /services для swagger.
+
+type ServiceRequestDTO struct {
+	Name         string   `json:"name" example:"Netflix"`
+	Aliases      []string `json:"aliases" example:"Нетфликс,NFLX"`
+	Category     *string  `json:"category" example:"streaming"`
+	DefaultPrice *int     `json:"default_price" example:"799" minimum:"1"`
+	Currency     string   `json:"currency" example:"RUB" default:"RUB"`
+	LogoURL      *string  `json:"logo_url" example:"https://example.com/netflix.svg"`
+}
+
+type ServiceDTO struct {
+	ID uuid.UUID `json:"id" example:"3f0b6c1e-5a2d-4c8b-9e7f-1a2b3c4d5e6f"`
+	ServiceRequestDTO
+	CreatedAt time.Time `json:"created_at" example:"2026-10-19T10:00:00Z"`
+}
+
+type ListServicesResponseDTO struct {
+	Paging Paging       `json:"paging"`
+	Rows   []ServiceDTO `json:"rows"`
+}
+
+func ServiceRequestToInput(req services.ServiceRequest) service.CatalogInput {
+	in := service.CatalogInput{
+		Name:         req.Name,
+		Category:     req.Category,
+		DefaultPrice: req.DefaultPrice,
+		LogoURL:      req.LogoUrl,
+	}
+	if req.Aliases != nil {
+		in.Aliases = *req.Aliases
+	}
+	if req.Currency != nil {
+		in.Currency = *req.Currency
+	}
+	return in
+}
+
+func ServiceToResponse(s *catalog.Service) services.Service {
+	aliases := s.Aliases()
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return services.Service{
+		Id:           s.ID(),
+		Name:         s.Name(),
+		Aliases:      aliases,
+		Category:     s.Category(),
+		DefaultPrice: s.DefaultPrice(),
+		Currency:     s.Currency(),
+		LogoUrl:      s.LogoURL(),
+		CreatedAt:    s.CreatedAt(),
+	}
+}
+
+func ServicesToResponse(rows []*catalog.Service, paging PagingBase, total int64) services.ListServices200JSONResponse {
+	res := make([]services.Service, 0, len(rows))
+	for _, s := range rows {
+		res = append(res, ServiceToResponse(s))
+	}
+	t := int(total)
+	return services.ListServices200JSONResponse{
+		Paging: services.Paging{Limit: &paging.Limit, Offset: &paging.Offset, Total: &t},
+		Rows:   res,
+	}
+}
@@ -0,0 +1,205 @@
+package v1
+
+import (
+	"context"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/service"
+	"testingtask/internal/web/services"
+	logger "testingtask/pkg"
+)
+
+type CatalogHandler struct {
+	serv service.CatalogService
+}
+
+func NewCatalogHandler(s service.CatalogService) *CatalogHandler {
+	return &CatalogHandler{serv: s}
+}
+
+// CreateService Добавить сервис в каталог
+// @Summary Добавить сервис в каталог
+// @Description Добавляет сервис с каноническим именем и синонимами. Имя и синонимы сравниваются без учёта регистра, пробелов, знаков и с транслитерацией кириллицы и не должны совпадать с другим сервисом. Новые подписки с таким названием привязываются к сервису
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param request body ServiceRequestDTO true "Сервис"
+// @Success 201 {object} ServiceDTO "Сервис добавлен"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные"
+// @Failure 409 {object} myerrors.ErrorResponse "Имя или синоним принадлежит другому сервису"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /services [post]
+func (h *CatalogHandler) CreateService(ctx context.Context, request services.CreateServiceRequestObject) (services.CreateServiceResponseObject, error) {
+	logger.Info(ctx, "create service called", map[string]interface{}{
+		"body": request.Body,
+	})
+
+	s, err := h.serv.Create(ctx, ServiceRequestToInput(*request.Body))
+	if err != nil {
+		logger.Error(ctx, "error create service", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return services.CreateService400JSONResponse(resp), nil
+		case 409:
+			return services.CreateService409JSONResponse(resp), nil
+		default:
+			return services.CreateService500JSONResponse(resp), nil
+		}
+	}
+
+	return services.CreateService201JSONResponse(ServiceToResponse(s)), nil
+}
+
+// ListServices Получить каталог сервисов
+// @Summary Получить каталог сервисов
+// @Description Возвращает сервисы каталога по имени с пагинацией
+// @Tags services
+// @Produce json
+// @Param category query string false "Категория"
+// @Param limit query int false "Количество элементов" default(10)
+// @Param offset query int false "Смещение" default(0)
+// @Success 200 {object} ListServicesResponseDTO "Список сервисов"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные параметры"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /services [get]
+func (h *CatalogHandler) ListServices(ctx context.Context, request services.ListServicesRequestObject) (services.ListServicesResponseObject, error) {
+	logger.Info(ctx, "list services called", map[string]interface{}{
+		"params": request.Params,
+	})
+
+	paging, ok := WebhookPaging(request.Params.Limit, request.Params.Offset)
+	if !ok {
+		resp, _ := myerrors.MapError(ctx, myerrors.ErrInvalidData)
+		return services.ListServices400JSONResponse(resp), nil
+	}
+
+	rows, total, err := h.serv.List(ctx, request.Params.Category, NewPagingBase(paging))
+	if err != nil {
+		logger.Error(ctx, "error list services", err, nil)
+		resp, _ := myerrors.MapError(ctx, err)
+		return services.ListServices500JSONResponse(resp), nil
+	}
+
+	return ServicesToResponse(rows, paging, total), nil
+}
+
+// ResolveService Найти сервис по названию
+// @Summary Найти сервис по названию
+// @Description Возвращает сервис каталога, к которому сводится название: по имени или синониму без учёта регистра, пробелов, знаков и с транслитерацией кириллицы
+// @Tags services
+// @Produce json
+// @Param name query string true "Название сервиса"
+// @Success 200 {object} ServiceDTO "Сервис найден"
+// @Failure 404 {object} myerrors.ErrorNotFound "Название не сводится ни к одному сервису"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /services/resolve [get]
+func (h *CatalogHandler) ResolveService(ctx context.Context, request services.ResolveServiceRequestObject) (services.ResolveServiceResponseObject, error) {
+	logger.Info(ctx, "resolve service called", map[string]interface{}{
+		"name": request.Params.Name,
+	})
+
+	s, err := h.serv.Resolve(ctx, request.Params.Name)
+	if err != nil {
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 404:
+			return services.ResolveService404JSONResponse(resp), nil
+		default:
+			logger.Error(ctx, "error resolve service", err, nil)
+			return services.ResolveService500JSONResponse(resp), nil
+		}
+	}
+
+	return services.ResolveService200JSONResponse(ServiceToResponse(s)), nil
+}
+
+// GetService Получить сервис по ID
+// @Summary Получить сервис по ID
+// @Description Возвращает сервис каталога по его идентификатору
+// @Tags services
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Success 200 {object} ServiceDTO "Сервис найден"
+// @Failure 404 {object} myerrors.ErrorNotFound "Сервис не найден"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /services/{id} [get]
+func (h *CatalogHandler) GetService(ctx context.Context, request services.GetServiceRequestObject) (services.GetServiceResponseObject, error) {
+	logger.Info(ctx, "get service called", map[string]interface{}{
+		"id": request.Id,
+	})
+
+	s, err := h.serv.Get(ctx, request.Id)
+	if err != nil {
+		logger.Error(ctx, "error get service", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 404:
+			return services.GetService404JSONResponse(resp), nil
+		default:
+			return services.GetService500JSONResponse(resp), nil
+		}
+	}
+
+	return services.GetService200JSONResponse(ServiceToResponse(s)), nil
+}
+
+// UpdateService Обновить сервис
+// @Summary Обновить сервис
+// @Description Заменяет поля и синонимы сервиса. Новое имя записывается в service_name привязанных подписок
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path string true "ID сервиса"
+// @Param request body ServiceRequestDTO true "Сервис"
+// @Success 200 {object} ServiceDTO "Сервис обновлён"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные"
+// @Failure 404 {object} myerrors.ErrorNotFound "Сервис не найден"
+// @Failure 409 {object} myerrors.ErrorResponse "Имя или синоним принадлежит другому сервису"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /services/{id} [put]
+func (h *CatalogHandler) UpdateService(ctx context.Context, request services.UpdateServiceRequestObject) (services.UpdateServiceResponseObject, error) {
+	logger.Info(ctx, "update service called", map[string]interface{}{
+		"id":   request.Id,
+		"body": request.Body,
+	})
+
+	s, err := h.serv.Update(ctx, request.Id, ServiceRequestToInput(*request.Body))
+	if err != nil {
+		logger.Error(ctx, "error update service", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return services.UpdateService400JSONResponse(resp), nil
+		case 404:
+			return services.UpdateService404JSONResponse(resp), nil
+		case 409:
+			return services.UpdateService409JSONResponse(resp), nil
+		default:
+			return services.UpdateService500JSONResponse(resp), nil
+		}
+	}
+
+	return services.UpdateService200JSONResponse(ServiceToResponse(s)), nil
+}
+
+// DeleteService Удалить сервис
+// @Summary Удалить сервис
+// @Description Удаляет сервис из каталога. Привязанные подписки отвязываются и сохраняют service_name. Отсутствующий сервис не ошибка
+// @Tags services
+// @Param id path string true "ID сервиса"
+// @Success 204 "Сервис удалён"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /services/{id} [delete]
+func (h *CatalogHandler) DeleteService(ctx context.Context, request services.DeleteServiceRequestObject) (services.DeleteServiceResponseObject, error) {
+	logger.Info(ctx, "delete service called", map[string]interface{}{
+		"id": request.Id,
+	})
+
+	if err := h.serv.Delete(ctx, request.Id); err != nil {
+		logger.Error(ctx, "error delete service", err, nil)
+		resp, _ := myerrors.MapError(ctx, err)
+		return services.DeleteService500JSONResponse(resp), nil
+	}
+
+	return services.DeleteService204Response{}, nil
+}
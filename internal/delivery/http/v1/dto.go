@@ -8,11 +8,14 @@ import (
 )
 
 type SubscriptionDTO struct {
-	ServiceName string    `json:"service_name"`
-	Price       int       `json:"price"`
-	UserID      uuid.UUID `json:"user_id"`
-	StartDate   string    `json:"start_date"`
-	EndDate     *string   `json:"end_date,omitempty"`
+	ServiceName string         `json:"service_name"`
+	Price       int            `json:"price"`
+	UserID      uuid.UUID      `json:"user_id"`
+	StartDate   string         `json:"start_date"`
+	EndDate     *string        `json:"end_date,omitempty"`
+	ServiceID   *uuid.UUID     `json:"service_id,omitempty" example:"3f0b6c1e-5a2d-4c8b-9e7f-1a2b3c4d5e6f"`
+	Tags        []string       `json:"tags,omitempty" example:"work,tax-deductible"`
+	Metadata    map[string]any `json:"metadata,omitempty" swaggertype:"object"`
 }
 
 func NewSubscriptionDTO(
@@ -21,6 +24,9 @@ func NewSubscriptionDTO(
 	userId uuid.UUID,
 	startDate string,
 	endDate *string,
+	serviceID *uuid.UUID,
+	tags []string,
+	metadata map[string]any,
 ) *SubscriptionDTO {
 	return &SubscriptionDTO{
 		ServiceName: serviceName,
@@ -28,19 +34,27 @@ func NewSubscriptionDTO(
 		UserID:      userId,
 		StartDate:   startDate,
 		EndDate:     endDate,
+		ServiceID:   serviceID,
+		Tags:        tags,
+		Metadata:    metadata,
 	}
 }
 
 type SubscriptionResponseDTO struct {
-	ID          uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
-	ServiceName string    `json:"service_name" example:"Netflix"`
-	Price       int       `json:"price" example:"99900"`
-	UserID      uuid.UUID `json:"user_id" example:"987f6543-e21b-34d5-c678-426614174999"`
-	StartDate   string    `json:"start_date" example:"07-2026"`
-	EndDate     *string   `json:"end_date,omitempty" example:"08-2026"`
+	ID          uuid.UUID      `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ServiceName string         `json:"service_name" example:"Netflix"`
+	Price       int            `json:"price" example:"99900"`
+	UserID      uuid.UUID      `json:"user_id" example:"987f6543-e21b-34d5-c678-426614174999"`
+	StartDate   string         `json:"start_date" example:"07-2026"`
+	EndDate     *string        `json:"end_date,omitempty" example:"08-2026"`
+	ServiceID   *uuid.UUID     `json:"service_id" example:"3f0b6c1e-5a2d-4c8b-9e7f-1a2b3c4d5e6f"`
+	Tags        []string       `json:"tags" example:"work,tax-deductible"`
+	Metadata    map[string]any `json:"metadata" swaggertype:"object"`
+	// Share — доля пользователя фильтра в общей подписке, только в /subscriptions/sum.
+	Share *int `json:"share,omitempty" example:"100"`
 }
 
-func NewSubscriptionResponseDTO(id uuid.UUID, serviceName string, price int, userId uuid.UUID, start time.Time, end *time.Time) *SubscriptionResponseDTO {
+func NewSubscriptionResponseDTO(id uuid.UUID, serviceName string, price int, userId uuid.UUID, start time.Time, end *time.Time, serviceID *uuid.UUID, tags []string, metadata map[string]any) *SubscriptionResponseDTO {
 
 	startStr := start.Format("01-2006")
 
@@ -50,14 +64,26 @@ func NewSubscriptionResponseDTO(id uuid.UUID, serviceName string, price int, use
 		endStr = &s
 	}
 
-	return &SubscriptionResponseDTO{
+	res := &SubscriptionResponseDTO{
 		ID:          id,
 		ServiceName: serviceName,
 		Price:       price,
 		UserID:      userId,
 		StartDate:   startStr,
 		EndDate:     endStr,
+		ServiceID:   serviceID,
+		Tags:        tags,
+		Metadata:    metadata,
 	}
+
+	// В ответе метки всегда есть, пустые — [] и {}.
+	if res.Tags == nil {
+		res.Tags = []string{}
+	}
+	if res.Metadata == nil {
+		res.Metadata = map[string]any{}
+	}
+	return res
 }
 
 // -------------- Filters --------------
@@ -65,12 +91,20 @@ func NewSubscriptionResponseDTO(id uuid.UUID, serviceName string, price int, use
 // Request
 
 type ListSubscriptionsRequestDTO struct {
-	UserID      *uuid.UUID `json:"user_id,omitempty"`
-	ServiceName *string    `json:"service_name,omitempty"`
-	Start       *string    `json:"start,omitempty"`
-	End         *string    `json:"end,omitempty"`
-	Limit       int        `json:"limit"`
-	Offset      int        `json:"offset"`
+	UserID      *uuid.UUID     `json:"user_id,omitempty"`
+	ServiceName *string        `json:"service_name,omitempty"`
+	Start       *string        `json:"start,omitempty"`
+	End         *string        `json:"end,omitempty"`
+	Labels      LabelFilterDTO `json:"labels"`
+	Limit       int            `json:"limit"`
+	Offset      int            `json:"offset"`
+}
+
+// LabelFilterDTO — параметры фильтра по тегам и metadata у List и Sum.
+type LabelFilterDTO struct {
+	Tags     []string `json:"tag,omitempty"`
+	TagMatch *string  `json:"tag_match,omitempty"`
+	Metadata []string `json:"metadata,omitempty"`
 }
 
 func NewListSubscriptionsRequestDTO(
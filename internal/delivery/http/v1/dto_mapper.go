@@ -19,14 +19,19 @@ func DTOToFilter(dto ListSubscriptionsRequestDTO) (*domain.SubscriptionFilter, e
 }
 
 func SubscriptionToDTO(d *domain.Subscription) *SubscriptionResponseDTO {
-	return NewSubscriptionResponseDTO(
+	res := NewSubscriptionResponseDTO(
 		d.ID(),
 		d.ServiceName(),
 		d.Price(),
 		d.UserID(),
 		d.StartDate(),
 		d.EndDate(),
+		d.ServiceID(),
+		d.Tags(),
+		d.Metadata(),
 	)
+	res.Share = d.Share()
+	return res
 }
 
 // --------------------
@@ -53,7 +58,7 @@ func DTOToDomain(uid *uuid.UUID, dto SubscriptionDTO) (*domain.Subscription, err
 		}
 	}
 
-	return domain.NewSubscription(
+	sub, err := domain.NewSubscription(
 		id,
 		dto.ServiceName,
 		domain.Price(dto.Price),
@@ -61,10 +66,18 @@ func DTOToDomain(uid *uuid.UUID, dto SubscriptionDTO) (*domain.Subscription, err
 		*start,
 		end,
 	)
+	if err != nil {
+		return nil, err
+	}
+	sub.LinkService(dto.ServiceID, "")
+	if err := sub.SetLabels(dto.Tags, dto.Metadata); err != nil {
+		return nil, err
+	}
+	return sub, nil
 }
 
 func SumDTOToDomain(dto ListSubscriptionsRequestDTO) (*domain.SubscriptionFilter, error) {
-	return domain.NewSubscriptionFilter(
+	filter, err := domain.NewSubscriptionFilter(
 		dto.UserID,
 		dto.ServiceName,
 		dto.Start,
@@ -72,6 +85,19 @@ func SumDTOToDomain(dto ListSubscriptionsRequestDTO) (*domain.SubscriptionFilter
 		dto.Limit,
 		dto.Offset,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Labels, err = LabelFilterToDomain(dto.Labels)
+	if err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func LabelFilterToDomain(dto LabelFilterDTO) (domain.LabelFilter, error) {
+	return domain.NewLabelFilter(dto.Tags, dto.TagMatch, dto.Metadata)
 }
 
 func NewPagingBase(dto PagingBase) *domain.PagingBase {
@@ -88,6 +114,10 @@ func NewRows(l []SubscriptionResponseDTO) []subscriptions.Subscription {
 			UserId:      r.UserID,
 			StartDate:   r.StartDate,
 			EndDate:     r.EndDate,
+			ServiceId:   r.ServiceID,
+			Tags:        r.Tags,
+			Metadata:    r.Metadata,
+			Share:       r.Share,
 		})
 	}
 
@@ -109,6 +139,32 @@ func ListRequestToDTO(req subscriptions.ListRequestObject) PagingBase {
 	}
 }
 
+func ListLabelsToDTO(req subscriptions.ListRequestObject) LabelFilterDTO {
+	return newLabelFilterDTO(req.Params.Tag, (*string)(req.Params.TagMatch), req.Params.Metadata)
+}
+
+func newLabelFilterDTO(tags *[]string, match *string, metadata *[]string) LabelFilterDTO {
+	return LabelFilterDTO{
+		Tags:     optSlice(tags),
+		TagMatch: match,
+		Metadata: optSlice(metadata),
+	}
+}
+
+func optSlice(s *[]string) []string {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func optMap(m *map[string]interface{}) map[string]any {
+	if m == nil {
+		return nil
+	}
+	return *m
+}
+
 func CreateRequestToDTO(req subscriptions.CreateJSONRequestBody) *SubscriptionDTO {
 	return NewSubscriptionDTO(
 		req.ServiceName,
@@ -116,6 +172,9 @@ func CreateRequestToDTO(req subscriptions.CreateJSONRequestBody) *SubscriptionDT
 		req.UserId,
 		req.StartDate,
 		req.EndDate,
+		req.ServiceId,
+		optSlice(req.Tags),
+		optMap(req.Metadata),
 	)
 }
 
@@ -126,11 +185,14 @@ func UpdateRequestToDTO(req subscriptions.UpdateJSONRequestBody) *SubscriptionDT
 		req.UserId,
 		req.StartDate,
 		req.EndDate,
+		req.ServiceId,
+		optSlice(req.Tags),
+		optMap(req.Metadata),
 	)
 }
 
 func SumRequestToDTO(req subscriptions.SumRequestObject) ListSubscriptionsRequestDTO {
-	return NewListSubscriptionsRequestDTO(
+	dto := NewListSubscriptionsRequestDTO(
 		req.Params.UserId,
 		req.Params.ServiceName,
 		req.Params.Start,
@@ -138,6 +200,8 @@ func SumRequestToDTO(req subscriptions.SumRequestObject) ListSubscriptionsReques
 		*req.Params.Limit,
 		*req.Params.Offset,
 	)
+	dto.Labels = newLabelFilterDTO(req.Params.Tag, (*string)(req.Params.TagMatch), req.Params.Metadata)
+	return dto
 }
 
 // --------------------
@@ -152,6 +216,9 @@ func DomainToDTO(d *domain.Subscription) *SubscriptionResponseDTO {
 		d.UserID(),
 		d.StartDate(),
 		d.EndDate(),
+		d.ServiceID(),
+		d.Tags(),
+		d.Metadata(),
 	)
 }
 
@@ -198,6 +265,9 @@ func GetDTOToResponse(s *SubscriptionResponseDTO) subscriptions.Get200JSONRespon
 		Price:       s.Price,
 		StartDate:   s.StartDate,
 		EndDate:     s.EndDate,
+		ServiceId:   s.ServiceID,
+		Tags:        s.Tags,
+		Metadata:    s.Metadata,
 	}
 }
 
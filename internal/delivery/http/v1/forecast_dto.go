@@ -0,0 +1,70 @@
+package v1
+
+import (
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/web/subscriptions"
+)
+
+// Типы ниже описывают ответ /subscriptions/forecast для swagger.
+
+type ForecastPointDTO struct {
+	Month  string `json:"month" example:"07-2025"`
+	Amount int    `json:"amount" example:"1200"`
+}
+
+type ForecastSeriesDTO struct {
+	Key    *string            `json:"key,omitempty" example:"Yandex Plus"`
+	Total  int                `json:"total" example:"14400"`
+	Points []ForecastPointDTO `json:"points"`
+}
+
+type ForecastDTO struct {
+	From    string              `json:"from" example:"07-2025"`
+	Months  int                 `json:"months" example:"12"`
+	GroupBy *string             `json:"group_by,omitempty" enums:"user,service"`
+	Total   ForecastSeriesDTO   `json:"total"`
+	Series  []ForecastSeriesDTO `json:"series"`
+}
+
+func ForecastRequestToFilter(p subscriptions.ForecastParams) *domain.SubscriptionFilter {
+	return &domain.SubscriptionFilter{UserID: p.UserId, ServiceName: p.ServiceName}
+}
+
+func ForecastGroupFromRequest(g *subscriptions.ForecastParamsGroupBy) domain.ForecastGroup {
+	if g == nil {
+		return domain.GroupNone
+	}
+	return domain.ForecastGroup(*g)
+}
+
+func ForecastToResponse(f *domain.Forecast) subscriptions.Forecast200JSONResponse {
+	res := subscriptions.Forecast200JSONResponse{
+		From:   f.From.Format("01-2006"),
+		Months: f.Months,
+		Total:  forecastSeriesToResponse(f.Total),
+		Series: make([]subscriptions.ForecastSeries, 0, len(f.Series)),
+	}
+	if f.Group != domain.GroupNone {
+		g := subscriptions.ForecastGroupBy(f.Group)
+		res.GroupBy = &g
+	}
+	for _, s := range f.Series {
+		res.Series = append(res.Series, forecastSeriesToResponse(s))
+	}
+	return res
+}
+
+func forecastSeriesToResponse(s domain.ForecastSeries) subscriptions.ForecastSeries {
+	res := subscriptions.ForecastSeries{
+		Total:  s.Total,
+		Points: make([]subscriptions.ForecastPoint, 0, len(s.Points)),
+	}
+	if s.Key != "" {
+		key := s.Key
+		res.Key = &key
+	}
+	for _, p := range s.Points {
+		res.Points = append(res.Points, subscriptions.ForecastPoint{Month: p.Month.Format("01-2006"), Amount: p.Amount})
+	}
+	return res
+}
@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"net/http"
+	"testingtask/internal/health"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(c *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: c}
+}
+
+// Liveness — процесс жив и отвечает на запросы.
+func (h *HealthHandler) Liveness(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.checker.Liveness())
+}
+
+// Readiness — приложение готово принимать трафик: БД доступна,
+// миграции применены, пул не исчерпан, сервер не останавливается.
+func (h *HealthHandler) Readiness(c echo.Context) error {
+	report := h.checker.Readiness(c.Request().Context())
+	if report.Status != health.StatusOK {
+		return c.JSON(http.StatusServiceUnavailable, report)
+	}
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"testingtask/internal/scheduler"
+	"testingtask/internal/web/jobs"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы ниже описывают ответы /admin/jobs для swagger.
+
+type JobRunDTO struct {
+	ID         uuid.UUID  `json:"id" example:"1c9e6b7a-3f2d-4e8b-a5c1-0d9f8e7a6b5c"`
+	Job        string     `json:"job" example:"expire-subscriptions"`
+	Trigger    string     `json:"trigger" enums:"schedule,manual" example:"schedule"`
+	Instance   string     `json:"instance" example:"subscriptions-api-7d9f8c6b5-x2k4q"`
+	Status     string     `json:"status" enums:"running,succeeded,failed" example:"succeeded"`
+	StartedAt  time.Time  `json:"started_at" example:"2026-10-18T13:00:00Z"`
+	FinishedAt *time.Time `json:"finished_at" example:"2026-10-18T13:00:02Z"`
+	Error      *string    `json:"error" example:"database error"`
+}
+
+type JobDTO struct {
+	Name      string     `json:"name" example:"expire-subscriptions"`
+	Schedule  string     `json:"schedule" example:"@hourly"`
+	NextRunAt *time.Time `json:"next_run_at" example:"2026-10-18T14:00:00Z"`
+	LastRun   *JobRunDTO `json:"last_run"`
+}
+
+type JobListDTO struct {
+	Instance string   `json:"instance" example:"subscriptions-api-7d9f8c6b5-x2k4q"`
+	Leader   bool     `json:"leader" example:"true"`
+	Jobs     []JobDTO `json:"jobs"`
+}
+
+type ListJobRunsResponseDTO struct {
+	Paging Paging      `json:"paging"`
+	Rows   []JobRunDTO `json:"rows"`
+}
+
+func JobRunToResponse(r scheduler.Run) jobs.JobRun {
+	return jobs.JobRun{
+		Id:         r.ID,
+		Job:        r.Job,
+		Trigger:    jobs.JobRunTrigger(r.Trigger),
+		Instance:   r.Instance,
+		Status:     jobs.JobRunStatus(r.Status),
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+		Error:      r.Error,
+	}
+}
+
+func JobsToResponse(infos []scheduler.JobInfo, instance string, leader bool) jobs.ListJobs200JSONResponse {
+	res := make([]jobs.Job, 0, len(infos))
+	for _, info := range infos {
+		j := jobs.Job{
+			Name:      info.Name,
+			Schedule:  info.Schedule,
+			NextRunAt: info.NextRunAt,
+		}
+		if info.LastRun != nil {
+			last := JobRunToResponse(*info.LastRun)
+			j.LastRun = &last
+		}
+		res = append(res, j)
+	}
+	return jobs.ListJobs200JSONResponse{Instance: instance, Leader: leader, Jobs: res}
+}
+
+func JobRunsToResponse(rows []scheduler.Run, paging PagingBase, total int64) jobs.ListJobRuns200JSONResponse {
+	res := make([]jobs.JobRun, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, JobRunToResponse(r))
+	}
+	t := int(total)
+	return jobs.ListJobRuns200JSONResponse{
+		Paging: jobs.Paging{Limit: &paging.Limit, Offset: &paging.Offset, Total: &t},
+		Rows:   res,
+	}
+}
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"context"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/scheduler"
+	"testingtask/internal/web/jobs"
+	logger "testingtask/pkg"
+)
+
+type JobHandler struct {
+	sched *scheduler.Scheduler
+}
+
+func NewJobHandler(s *scheduler.Scheduler) *JobHandler {
+	return &JobHandler{sched: s}
+}
+
+// ListJobs Получить список фоновых задач
+// @Summary Получить список фоновых задач
+// @Description Возвращает задачи планировщика с расписанием и последним запуском. Время следующего запуска знает только лидер — экземпляр, выполняющий задачи по расписанию
+// @Tags jobs
+// @Produce json
+// @Success 200 {object} JobListDTO "Список задач"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /admin/jobs [get]
+func (h *JobHandler) ListJobs(ctx context.Context, _ jobs.ListJobsRequestObject) (jobs.ListJobsResponseObject, error) {
+	logger.Info(ctx, "list jobs called", nil)
+
+	infos, err := h.sched.Jobs(ctx)
+	if err != nil {
+		logger.Error(ctx, "error list jobs", err, nil)
+		resp, _ := myerrors.MapError(ctx, err)
+		return jobs.ListJobs500JSONResponse(resp), nil
+	}
+
+	return JobsToResponse(infos, h.sched.Instance(), h.sched.Leader()), nil
+}
+
+// TriggerJob Запустить задачу
+// @Summary Запустить задачу
+// @Description Запускает задачу вне расписания на экземпляре, принявшем запрос, и сразу возвращает запись о запуске. Итог появляется в истории запусков
+// @Tags jobs
+// @Produce json
+// @Param name path string true "Имя задачи"
+// @Success 202 {object} JobRunDTO "Запуск начат"
+// @Failure 404 {object} myerrors.ErrorNotFound "Задача не найдена"
+// @Failure 409 {object} myerrors.ErrorResponse "Задача уже выполняется"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /admin/jobs/{name}/run [post]
+func (h *JobHandler) TriggerJob(ctx context.Context, request jobs.TriggerJobRequestObject) (jobs.TriggerJobResponseObject, error) {
+	logger.Info(ctx, "trigger job called", map[string]interface{}{
+		"name": request.Name,
+	})
+
+	run, err := h.sched.Trigger(ctx, request.Name)
+	if err != nil {
+		logger.Error(ctx, "error trigger job", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 404:
+			return jobs.TriggerJob404JSONResponse(resp), nil
+		case 409:
+			return jobs.TriggerJob409JSONResponse(resp), nil
+		default:
+			return jobs.TriggerJob500JSONResponse(resp), nil
+		}
+	}
+
+	return jobs.TriggerJob202JSONResponse(JobRunToResponse(run)), nil
+}
+
+// ListJobRuns Получить историю запусков задачи
+// @Summary Получить историю запусков задачи
+// @Description Возвращает запуски задачи со всех экземпляров, новые первыми
+// @Tags jobs
+// @Produce json
+// @Param name path string true "Имя задачи"
+// @Param limit query int false "Количество элементов" default(10)
+// @Param offset query int false "Смещение" default(0)
+// @Success 200 {object} ListJobRunsResponseDTO "История запусков"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные параметры"
+// @Failure 404 {object} myerrors.ErrorNotFound "Задача не найдена"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /admin/jobs/{name}/runs [get]
+func (h *JobHandler) ListJobRuns(ctx context.Context, request jobs.ListJobRunsRequestObject) (jobs.ListJobRunsResponseObject, error) {
+	logger.Info(ctx, "list job runs called", map[string]interface{}{
+		"name":   request.Name,
+		"params": request.Params,
+	})
+
+	paging, ok := WebhookPaging(request.Params.Limit, request.Params.Offset)
+	if !ok {
+		resp, _ := myerrors.MapError(ctx, myerrors.ErrInvalidData)
+		return jobs.ListJobRuns400JSONResponse(resp), nil
+	}
+
+	rows, total, err := h.sched.Runs(ctx, request.Name, paging.Limit, paging.Offset)
+	if err != nil {
+		logger.Error(ctx, "error list job runs", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 404:
+			return jobs.ListJobRuns404JSONResponse(resp), nil
+		default:
+			return jobs.ListJobRuns500JSONResponse(resp), nil
+		}
+	}
+
+	return JobRunsToResponse(rows, paging, total), nil
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"math"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/web/subscriptions"
+
+	"github.com/google/uuid"
+)
+
+// Типы ниже описывают тела запросов и ответов /subscriptions/{id}/members для swagger.
+
+type MemberRequestDTO struct {
+	UserID  uuid.UUID `json:"user_id" example:"7a1c2e3f-4b5d-4e6f-8a9b-0c1d2e3f4a5b"`
+	Percent *float64  `json:"percent,omitempty" example:"25"`
+	Amount  *int      `json:"amount,omitempty" example:"100"`
+}
+
+type MembersRequestDTO struct {
+	Split   string             `json:"split" enums:"equal,percent,fixed" example:"equal"`
+	Members []MemberRequestDTO `json:"members"`
+}
+
+type MemberDTO struct {
+	UserID  uuid.UUID `json:"user_id" example:"7a1c2e3f-4b5d-4e6f-8a9b-0c1d2e3f4a5b"`
+	Owner   bool      `json:"owner" example:"false"`
+	Percent *float64  `json:"percent,omitempty" example:"25"`
+	Amount  *int      `json:"amount,omitempty" example:"100"`
+	Share   int       `json:"share" example:"100"`
+}
+
+type MembersDTO struct {
+	SubscriptionID uuid.UUID   `json:"subscription_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	OwnerID        uuid.UUID   `json:"owner_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	Price          int         `json:"price" example:"400"`
+	Split          *string     `json:"split,omitempty" enums:"equal,percent,fixed" example:"equal"`
+	Members        []MemberDTO `json:"members"`
+}
+
+func MembersRequestToDomain(req subscriptions.SubscriptionMembersRequest) (domain.SplitRule, []domain.Member) {
+	members := make([]domain.Member, 0, len(req.Members))
+	for _, m := range req.Members {
+		members = append(members, domain.Member{
+			UserID:  m.UserId,
+			Percent: m.Percent,
+			Amount:  m.Amount,
+		})
+	}
+	return domain.SplitRule(req.Split), members
+}
+
+// MembersToResponse описывает разделение цены sub. Не общая подписка —
+// это один владелец, который платит всю цену. Процент или сумму владельца
+// ответ показывает явно: это остаток после остальных участников.
+func MembersToResponse(sub *domain.Subscription, split *domain.Split) subscriptions.SubscriptionMembers {
+	res := subscriptions.SubscriptionMembers{
+		SubscriptionId: sub.ID(),
+		OwnerId:        sub.UserID(),
+		Price:          sub.Price(),
+	}
+	if split == nil {
+		res.Members = []subscriptions.SubscriptionMember{{
+			UserId: sub.UserID(),
+			Owner:  true,
+			Share:  sub.Price(),
+		}}
+		return res
+	}
+
+	rule := subscriptions.SubscriptionMembersSplit(split.Rule)
+	res.Split = &rule
+	res.Members = make([]subscriptions.SubscriptionMember, 0, len(split.Members))
+	for i, m := range split.Members {
+		res.Members = append(res.Members, subscriptions.SubscriptionMember{
+			UserId:  m.UserID,
+			Owner:   i == 0,
+			Percent: m.Percent,
+			Amount:  m.Amount,
+			Share:   m.Share,
+		})
+	}
+
+	owner := &res.Members[0]
+	switch split.Rule {
+	case domain.SplitPercent:
+		rest := 100.0
+		for _, m := range split.Members[1:] {
+			rest -= *m.Percent
+		}
+		rest = math.Round(rest*100) / 100
+		owner.Percent = &rest
+	case domain.SplitFixed:
+		owner.Amount = &owner.Share
+	}
+	return res
+}
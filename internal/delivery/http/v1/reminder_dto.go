@@ -0,0 +1,46 @@
+package v1
+
+import (
+	"testingtask/internal/domain/reminder"
+	"testingtask/internal/service"
+	"testingtask/internal/web/reminders"
+
+	"github.com/google/uuid"
+)
+
+// Типы ниже описывают тела запросов и ответов /users/{user_id}/reminder-preferences
+// для swagger.
+
+type ReminderPreferencesRequestDTO struct {
+	Enabled    bool    `json:"enabled" example:"true"`
+	DaysBefore int     `json:"days_before" example:"3" minimum:"1" maximum:"90"`
+	Channel    string  `json:"channel" enums:"email,webhook,log" example:"email"`
+	Email      *string `json:"email" example:"user@example.com"`
+	WebhookURL *string `json:"webhook_url" example:"https://bot.example.com/reminders"`
+}
+
+type ReminderPreferencesDTO struct {
+	UserID uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	ReminderPreferencesRequestDTO
+}
+
+func ReminderRequestToInput(req reminders.ReminderPreferencesRequest) service.ReminderInput {
+	return service.ReminderInput{
+		Enabled:    req.Enabled,
+		DaysBefore: req.DaysBefore,
+		Channel:    string(req.Channel),
+		Email:      req.Email,
+		WebhookURL: req.WebhookUrl,
+	}
+}
+
+func PreferencesToResponse(p *reminder.Preferences) reminders.ReminderPreferences {
+	return reminders.ReminderPreferences{
+		UserId:     p.UserID(),
+		Enabled:    p.Enabled(),
+		DaysBefore: p.DaysBefore(),
+		Channel:    reminders.ReminderPreferencesChannel(p.Channel()),
+		Email:      p.Email(),
+		WebhookUrl: p.WebhookURL(),
+	}
+}
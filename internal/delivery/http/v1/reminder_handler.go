@@ -0,0 +1,74 @@
+package v1
+
+import (
+	"context"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/service"
+	"testingtask/internal/web/reminders"
+	logger "testingtask/pkg"
+)
+
+type ReminderHandler struct {
+	serv service.ReminderService
+}
+
+func NewReminderHandler(s service.ReminderService) *ReminderHandler {
+	return &ReminderHandler{serv: s}
+}
+
+// GetReminderPreferences Получить настройки напоминаний
+// @Summary Получить настройки напоминаний
+// @Description Возвращает настройки напоминаний пользователя об окончании подписок. Если пользователь ничего не сохранял, возвращаются настройки по умолчанию
+// @Tags reminders
+// @Produce json
+// @Param user_id path string true "ID пользователя"
+// @Success 200 {object} ReminderPreferencesDTO "Настройки напоминаний"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /users/{user_id}/reminder-preferences [get]
+func (h *ReminderHandler) GetReminderPreferences(ctx context.Context, request reminders.GetReminderPreferencesRequestObject) (reminders.GetReminderPreferencesResponseObject, error) {
+	logger.Info(ctx, "get reminder preferences called", map[string]interface{}{
+		"user_id": request.UserId,
+	})
+
+	p, err := h.serv.GetPreferences(ctx, request.UserId)
+	if err != nil {
+		logger.Error(ctx, "error get reminder preferences", err, nil)
+		resp, _ := myerrors.MapError(ctx, err)
+		return reminders.GetReminderPreferences500JSONResponse(resp), nil
+	}
+
+	return reminders.GetReminderPreferences200JSONResponse(PreferencesToResponse(p)), nil
+}
+
+// SaveReminderPreferences Сохранить настройки напоминаний
+// @Summary Сохранить настройки напоминаний
+// @Description Заменяет настройки напоминаний пользователя. Канал email доступен, только если на сервере настроен SMTP
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Param user_id path string true "ID пользователя"
+// @Param request body ReminderPreferencesRequestDTO true "Настройки напоминаний"
+// @Success 200 {object} ReminderPreferencesDTO "Настройки сохранены"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные, канал не настроен или неизвестный пользователь"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /users/{user_id}/reminder-preferences [put]
+func (h *ReminderHandler) SaveReminderPreferences(ctx context.Context, request reminders.SaveReminderPreferencesRequestObject) (reminders.SaveReminderPreferencesResponseObject, error) {
+	logger.Info(ctx, "save reminder preferences called", map[string]interface{}{
+		"user_id": request.UserId,
+		"channel": request.Body.Channel,
+	})
+
+	p, err := h.serv.SavePreferences(ctx, request.UserId, ReminderRequestToInput(*request.Body))
+	if err != nil {
+		logger.Error(ctx, "error save reminder preferences", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return reminders.SaveReminderPreferences400JSONResponse(resp), nil
+		default:
+			return reminders.SaveReminderPreferences500JSONResponse(resp), nil
+		}
+	}
+
+	return reminders.SaveReminderPreferences200JSONResponse(PreferencesToResponse(p)), nil
+}
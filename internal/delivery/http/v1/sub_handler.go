@@ -26,7 +26,8 @@ func NewSubHandler(s service.SubService) *SubHandler {
 // @Produce json
 // @Param request body SubscriptionDTO true "Данные для создания подписки"
 // @Success 201 {object} SubscriptionID "Подписка успешно создана"
-// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные или неизвестный user_id"
+// @Failure 409 {object} myerrors.ErrorResponse "Подписка выводит траты за бюджет с hard_limit"
 // @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
 // @Router /subscriptions [post]
 func (h *SubHandler) Create(ctx context.Context, request subscriptions.CreateRequestObject) (subscriptions.CreateResponseObject, error) {
@@ -38,15 +39,22 @@ func (h *SubHandler) Create(ctx context.Context, request subscriptions.CreateReq
 	domainObj, err := DTOToDomain(nil, *dto)
 	if err != nil {
 		logger.Error(ctx, "invalid data", err, nil)
-		resp, _ := myerrors.MapError(myerrors.ErrInvalidData)
+		resp, _ := myerrors.MapError(ctx, myerrors.ErrInvalidData)
 		return subscriptions.Create400JSONResponse(resp), nil
 	}
 
 	id, err := h.serv.Create(ctx, domainObj)
 	if err != nil {
 		logger.Error(ctx, "error create subscripton", err, nil)
-		resp, _ := myerrors.MapError(err)
-		return subscriptions.Create500JSONResponse(resp), nil
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return subscriptions.Create400JSONResponse(resp), nil
+		case 409:
+			return subscriptions.Create409JSONResponse(resp), nil
+		default:
+			return subscriptions.Create500JSONResponse(resp), nil
+		}
 	}
 
 	return CreateToResponse(id), nil
@@ -71,14 +79,14 @@ func (h *SubHandler) Get(ctx context.Context, request subscriptions.GetRequestOb
 	uid, err := uuid.Parse(request.Id)
 	if err != nil {
 		logger.Error(ctx, "invalid id format", err, nil)
-		resp, _ := myerrors.MapError(myerrors.ErrInvalidID)
+		resp, _ := myerrors.MapError(ctx, myerrors.ErrInvalidID)
 		return subscriptions.Get400JSONResponse(resp), nil
 	}
 
 	subscription, err := h.serv.Get(ctx, uid)
 	if err != nil {
 		logger.Error(ctx, "subscripton not found", err, nil)
-		resp, code := myerrors.MapError(err)
+		resp, code := myerrors.MapError(ctx, err)
 		switch code {
 		case 404:
 			return subscriptions.Get404JSONResponse(resp), nil
@@ -94,12 +102,15 @@ func (h *SubHandler) Get(ctx context.Context, request subscriptions.GetRequestOb
 
 // List Получить список подписок
 // @Summary Получить список подписок
-// @Description Возвращает список подписок с пагинацией
+// @Description Возвращает список подписок с пагинацией. tag и metadata отбирают подписки по тегам и metadata
 // @Tags subscriptions
 // @Accept json
 // @Produce json
 // @Param limit query int true "Количество элементов"
 // @Param offset query int true "Смещение"
+// @Param tag query []string false "Тег, параметр повторяется" collectionFormat(multi)
+// @Param tag_match query string false "any — хотя бы один из тегов, all — все" Enums(any, all) default(any)
+// @Param metadata query []string false "key — ключ есть, key:value — значение ключа строка value; параметр повторяется" collectionFormat(multi)
 // @Success 200 {array} SubscriptionResponseDTO "Список подписок"
 // @Failure 400 {object} myerrors.ErrorResponse "Некорректный ID"
 // @Failure 404 {object} myerrors.ErrorNotFound "Подписка не найдена"
@@ -113,10 +124,17 @@ func (h *SubHandler) List(ctx context.Context, request subscriptions.ListRequest
 
 	paging := NewPagingBase(dto)
 
-	subs, totalCount, err := h.serv.List(ctx, paging)
+	labels, err := LabelFilterToDomain(ListLabelsToDTO(request))
+	if err != nil {
+		logger.Error(ctx, "invalid data", err, nil)
+		resp, _ := myerrors.MapError(ctx, err)
+		return subscriptions.List400JSONResponse(resp), nil
+	}
+
+	subs, totalCount, err := h.serv.List(ctx, labels, paging)
 	if err != nil {
 		logger.Error(ctx, "error list", err, nil)
-		resp, code := myerrors.MapError(err)
+		resp, code := myerrors.MapError(ctx, err)
 
 		switch code {
 		case 400:
@@ -144,6 +162,9 @@ func (h *SubHandler) List(ctx context.Context, request subscriptions.ListRequest
 // @Param end query string false "End date (MM-YYYY)"
 // @Param limit query int false "Limit subscriptions for count price" default(10)
 // @Param offset query int false "Offset subscriptions" default(0)
+// @Param tag query []string false "Тег, параметр повторяется" collectionFormat(multi)
+// @Param tag_match query string false "any — хотя бы один из тегов, all — все" Enums(any, all) default(any)
+// @Param metadata query []string false "key — ключ есть, key:value — значение ключа строка value; параметр повторяется" collectionFormat(multi)
 // @Success 200 {object} ListSubscriptionsResponseDto
 // @Failure 400 {object} myerrors.ErrorResponse "Некорректный ID"
 // @Failure 404 {object} myerrors.ErrorNotFound "Подписка не найдена"
@@ -158,7 +179,7 @@ func (h *SubHandler) Sum(ctx context.Context, request subscriptions.SumRequestOb
 	filter, err := SumDTOToDomain(dto)
 	if err != nil {
 		logger.Error(ctx, "invalid data", err, nil)
-		resp, code := myerrors.MapError(err)
+		resp, code := myerrors.MapError(ctx, err)
 		switch code {
 		case 400:
 			return subscriptions.Sum400JSONResponse(resp), nil
@@ -170,7 +191,7 @@ func (h *SubHandler) Sum(ctx context.Context, request subscriptions.SumRequestOb
 	result, err := h.serv.Sum(ctx, filter)
 	if err != nil {
 		logger.Error(ctx, "error sum subscriptions", err, nil)
-		resp, code := myerrors.MapError(err)
+		resp, code := myerrors.MapError(ctx, err)
 		switch code {
 		case 400:
 			return subscriptions.Sum400JSONResponse(resp), nil
@@ -187,6 +208,43 @@ func (h *SubHandler) Sum(ctx context.Context, request subscriptions.SumRequestOb
 	return SumDTOToResponse(responseDTO, paging), nil
 }
 
+// Forecast Прогноз трат на подписки
+// @Summary Прогноз трат на подписки
+// @Description Помесячно прогнозирует траты по текущим подпискам начиная с текущего месяца: подписка оплачивается по текущей цене с месяца начала до месяца окончания включительно. С group_by прогноз разбивается на ряды по пользователям или сервисам
+// @Tags subscriptions
+// @Produce json
+// @Param months query int false "Число месяцев прогноза, от 1 до 60" default(12)
+// @Param user_id query string false "User ID"
+// @Param service_name query string false "Service name"
+// @Param group_by query string false "Разбивка на ряды" Enums(user, service)
+// @Success 200 {object} ForecastDTO "Прогноз трат"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные параметры"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /subscriptions/forecast [get]
+func (h *SubHandler) Forecast(ctx context.Context, request subscriptions.ForecastRequestObject) (subscriptions.ForecastResponseObject, error) {
+	logger.Info(ctx, "forecast subscriptions called", map[string]interface{}{
+		"params": request.Params,
+	})
+
+	months := 12
+	if request.Params.Months != nil {
+		months = *request.Params.Months
+	}
+	forecast, err := h.serv.Forecast(ctx, ForecastRequestToFilter(request.Params), months, ForecastGroupFromRequest(request.Params.GroupBy))
+	if err != nil {
+		logger.Error(ctx, "error forecast subscriptions", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return subscriptions.Forecast400JSONResponse(resp), nil
+		default:
+			return subscriptions.Forecast500JSONResponse(resp), nil
+		}
+	}
+
+	return ForecastToResponse(forecast), nil
+}
+
 // Update Обновить подписку
 // @Summary Обновить подписку
 // @Description Обновляет данные подписки по ID
@@ -196,8 +254,9 @@ func (h *SubHandler) Sum(ctx context.Context, request subscriptions.SumRequestOb
 // @Param id path string true "ID подписки"
 // @Param request body SubscriptionDTO true "Данные для обновления подписки"
 // @Success 200 {object} SubscriptionID "Подписка успешно обновлена"
-// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные или неизвестный user_id"
 // @Failure 404 {object} myerrors.ErrorNotFound "Подписка не найдена"
+// @Failure 409 {object} myerrors.ErrorResponse "Подписка выводит траты за бюджет с hard_limit"
 // @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
 // @Router /subscriptions/{id} [put]
 func (h *SubHandler) Update(ctx context.Context, request subscriptions.UpdateRequestObject) (subscriptions.UpdateResponseObject, error) {
@@ -208,7 +267,7 @@ func (h *SubHandler) Update(ctx context.Context, request subscriptions.UpdateReq
 	uid, err := uuid.Parse(request.Id.String())
 	if err != nil {
 		logger.Error(ctx, "invalid id format", err, nil)
-		resp, code := myerrors.MapError(myerrors.ErrInvalidID)
+		resp, code := myerrors.MapError(ctx, myerrors.ErrInvalidID)
 		switch code {
 		case 400:
 			return subscriptions.Update400JSONResponse(resp), nil
@@ -222,7 +281,7 @@ func (h *SubHandler) Update(ctx context.Context, request subscriptions.UpdateReq
 	subDomain, err := DTOToDomain(&uid, *dto)
 	if err != nil {
 		logger.Error(ctx, "invalid data", err, nil)
-		resp, code := myerrors.MapError(err)
+		resp, code := myerrors.MapError(ctx, err)
 		switch code {
 		case 400:
 			return subscriptions.Update400JSONResponse(resp), nil
@@ -234,12 +293,14 @@ func (h *SubHandler) Update(ctx context.Context, request subscriptions.UpdateReq
 	id, err := h.serv.Update(ctx, uid, subDomain)
 	if err != nil {
 		logger.Error(ctx, "error update subscription", err, nil)
-		resp, code := myerrors.MapError(err)
+		resp, code := myerrors.MapError(ctx, err)
 		switch code {
 		case 400:
 			return subscriptions.Update400JSONResponse(resp), nil
 		case 404:
 			return subscriptions.Update404JSONResponse(resp), nil
+		case 409:
+			return subscriptions.Update409JSONResponse(resp), nil
 		default:
 			return subscriptions.Update500JSONResponse(resp), nil
 		}
@@ -267,13 +328,13 @@ func (h *SubHandler) Delete(ctx context.Context, request subscriptions.DeleteReq
 	uid, err := uuid.Parse(request.Id.String())
 	if err != nil {
 		logger.Error(ctx, "invalid id format", err, nil)
-		resp, _ := myerrors.MapError(myerrors.ErrInvalidID)
+		resp, _ := myerrors.MapError(ctx, myerrors.ErrInvalidID)
 		return subscriptions.Delete400JSONResponse(resp), nil
 	}
 
 	if err = h.serv.Delete(ctx, uid); err != nil {
 		logger.Error(ctx, "error delete subscription", err, nil)
-		resp, code := myerrors.MapError(err)
+		resp, code := myerrors.MapError(ctx, err)
 		switch code {
 		case 404:
 			return subscriptions.Delete404JSONResponse(resp), nil
@@ -284,3 +345,145 @@ func (h *SubHandler) Delete(ctx context.Context, request subscriptions.DeleteReq
 
 	return subscriptions.Delete204Response{}, nil
 }
+
+// GetMembers Участники общей подписки
+// @Summary Участники общей подписки
+// @Description Возвращает разделение цены подписки между участниками. У не общей подписки единственный участник — владелец с полной ценой
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Success 200 {object} MembersDTO "Разделение цены"
+// @Failure 404 {object} myerrors.ErrorNotFound "Подписка не найдена"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/members [get]
+func (h *SubHandler) GetMembers(ctx context.Context, request subscriptions.GetMembersRequestObject) (subscriptions.GetMembersResponseObject, error) {
+	logger.Info(ctx, "get subscription members called", map[string]interface{}{
+		"id": request.Id,
+	})
+
+	sub, split, err := h.serv.Members(ctx, request.Id)
+	if err != nil {
+		logger.Error(ctx, "error get subscription members", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 404:
+			return subscriptions.GetMembers404JSONResponse(resp), nil
+		default:
+			return subscriptions.GetMembers500JSONResponse(resp), nil
+		}
+	}
+
+	return subscriptions.GetMembers200JSONResponse(MembersToResponse(sub, split)), nil
+}
+
+// SetMembers Разделить подписку между участниками
+// @Summary Разделить подписку между участниками
+// @Description Заменяет участников общей подписки. Цена делится поровну (equal), по процентам (percent) или фиксированными суммами (fixed); владелец участвует всегда и платит остаток, включая остаток от округления
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param request body MembersRequestDTO true "Правило и участники кроме владельца"
+// @Success 200 {object} MembersDTO "Разделение цены"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректное разделение или неизвестный участник"
+// @Failure 404 {object} myerrors.ErrorNotFound "Подписка не найдена"
+// @Failure 409 {object} myerrors.ErrorResponse "Доля участника выводит траты за бюджет с hard_limit"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/members [put]
+func (h *SubHandler) SetMembers(ctx context.Context, request subscriptions.SetMembersRequestObject) (subscriptions.SetMembersResponseObject, error) {
+	logger.Info(ctx, "set subscription members called", map[string]interface{}{
+		"id":   request.Id,
+		"body": request.Body,
+	})
+	rule, members := MembersRequestToDomain(*request.Body)
+
+	sub, split, err := h.serv.SetMembers(ctx, request.Id, rule, members)
+	if err != nil {
+		logger.Error(ctx, "error set subscription members", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return subscriptions.SetMembers400JSONResponse(resp), nil
+		case 404:
+			return subscriptions.SetMembers404JSONResponse(resp), nil
+		case 409:
+			return subscriptions.SetMembers409JSONResponse(resp), nil
+		default:
+			return subscriptions.SetMembers500JSONResponse(resp), nil
+		}
+	}
+
+	return subscriptions.SetMembers200JSONResponse(MembersToResponse(sub, split)), nil
+}
+
+// RemoveMember Исключить участника общей подписки
+// @Summary Исключить участника общей подписки
+// @Description Исключает участника, его доля переходит владельцу (при split=equal делится между оставшимися). Если кроме владельца никого не осталось, подписка перестаёт быть общей
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "ID подписки"
+// @Param user_id path string true "ID участника"
+// @Success 200 {object} MembersDTO "Разделение цены"
+// @Failure 400 {object} myerrors.ErrorResponse "Владельца исключить нельзя"
+// @Failure 404 {object} myerrors.ErrorNotFound "Подписка или участник не найдены"
+// @Failure 409 {object} myerrors.ErrorResponse "Выросшая доля выводит траты за бюджет с hard_limit"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/members/{user_id} [delete]
+func (h *SubHandler) RemoveMember(ctx context.Context, request subscriptions.RemoveMemberRequestObject) (subscriptions.RemoveMemberResponseObject, error) {
+	logger.Info(ctx, "remove subscription member called", map[string]interface{}{
+		"id":      request.Id,
+		"user_id": request.UserId,
+	})
+
+	sub, split, err := h.serv.RemoveMember(ctx, request.Id, request.UserId)
+	if err != nil {
+		logger.Error(ctx, "error remove subscription member", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return subscriptions.RemoveMember400JSONResponse(resp), nil
+		case 404:
+			return subscriptions.RemoveMember404JSONResponse(resp), nil
+		case 409:
+			return subscriptions.RemoveMember409JSONResponse(resp), nil
+		default:
+			return subscriptions.RemoveMember500JSONResponse(resp), nil
+		}
+	}
+
+	return subscriptions.RemoveMember200JSONResponse(MembersToResponse(sub, split)), nil
+}
+
+// Unshare Сделать подписку не общей
+// @Summary Сделать подписку не общей
+// @Description Удаляет всех участников кроме владельца: цену снова платит он
+// @Tags subscriptions
+// @Param id path string true "ID подписки"
+// @Success 204 "Подписка больше не общая"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные"
+// @Failure 404 {object} myerrors.ErrorNotFound "Подписка не найдена"
+// @Failure 409 {object} myerrors.ErrorResponse "Полная цена выводит траты владельца за бюджет с hard_limit"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /subscriptions/{id}/members [delete]
+func (h *SubHandler) Unshare(ctx context.Context, request subscriptions.UnshareRequestObject) (subscriptions.UnshareResponseObject, error) {
+	logger.Info(ctx, "unshare subscription called", map[string]interface{}{
+		"id": request.Id,
+	})
+
+	if err := h.serv.Unshare(ctx, request.Id); err != nil {
+		logger.Error(ctx, "error unshare subscription", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return subscriptions.Unshare400JSONResponse(resp), nil
+		case 404:
+			return subscriptions.Unshare404JSONResponse(resp), nil
+		case 409:
+			return subscriptions.Unshare409JSONResponse(resp), nil
+		default:
+			return subscriptions.Unshare500JSONResponse(resp), nil
+		}
+	}
+
+	return subscriptions.Unshare204Response{}, nil
+}
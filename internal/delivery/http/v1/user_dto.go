@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"testingtask/internal/domain/user"
+	"testingtask/internal/service"
+	"testingtask/internal/web/users"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы ниже описывают тела запросов и ответов /users для swagger.
+
+type UserRequestDTO struct {
+	DisplayName string  `json:"display_name" example:"Алиса"`
+	Email       *string `json:"email" example:"alice@example.com"`
+	Currency    string  `json:"currency" example:"RUB" default:"RUB"`
+	Timezone    string  `json:"timezone" example:"Europe/Moscow" default:"UTC"`
+}
+
+type UserDTO struct {
+	ID uuid.UUID `json:"id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	UserRequestDTO
+	CreatedAt time.Time `json:"created_at" example:"2026-10-19T10:00:00Z"`
+}
+
+type ListUsersResponseDTO struct {
+	Paging Paging    `json:"paging"`
+	Rows   []UserDTO `json:"rows"`
+}
+
+func UserRequestToInput(req users.UserRequest) service.UserInput {
+	in := service.UserInput{
+		DisplayName: req.DisplayName,
+		Email:       req.Email,
+	}
+	if req.Currency != nil {
+		in.Currency = *req.Currency
+	}
+	if req.Timezone != nil {
+		in.Timezone = *req.Timezone
+	}
+	return in
+}
+
+func UserToResponse(u *user.User) users.User {
+	return users.User{
+		Id:          u.ID(),
+		DisplayName: u.DisplayName(),
+		Email:       u.Email(),
+		Currency:    u.Currency(),
+		Timezone:    u.Timezone(),
+		CreatedAt:   u.CreatedAt(),
+	}
+}
+
+func UsersToResponse(rows []*user.User, paging PagingBase, total int64) users.ListUsers200JSONResponse {
+	res := make([]users.User, 0, len(rows))
+	for _, u := range rows {
+		res = append(res, UserToResponse(u))
+	}
+	t := int(total)
+	return users.ListUsers200JSONResponse{
+		Paging: users.Paging{Limit: &paging.Limit, Offset: &paging.Offset, Total: &t},
+		Rows:   res,
+	}
+}
@@ -0,0 +1,183 @@
+package v1
+
+import (
+	"context"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/service"
+	"testingtask/internal/web/users"
+	logger "testingtask/pkg"
+)
+
+type UserHandler struct {
+	serv service.UserService
+}
+
+func NewUserHandler(s service.UserService) *UserHandler {
+	return &UserHandler{serv: s}
+}
+
+// CreateUser Создать пользователя
+// @Summary Создать пользователя
+// @Description Создаёт пользователя. Подписки и участники общих подписок могут ссылаться только на существующих пользователей. Email уникален без учёта регистра
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body UserRequestDTO true "Пользователь"
+// @Success 201 {object} UserDTO "Пользователь создан"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные"
+// @Failure 409 {object} myerrors.ErrorResponse "Email принадлежит другому пользователю"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /users [post]
+func (h *UserHandler) CreateUser(ctx context.Context, request users.CreateUserRequestObject) (users.CreateUserResponseObject, error) {
+	logger.Info(ctx, "create user called", map[string]interface{}{
+		"display_name": request.Body.DisplayName,
+	})
+
+	u, err := h.serv.Create(ctx, UserRequestToInput(*request.Body))
+	if err != nil {
+		logger.Error(ctx, "error create user", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return users.CreateUser400JSONResponse(resp), nil
+		case 409:
+			return users.CreateUser409JSONResponse(resp), nil
+		default:
+			return users.CreateUser500JSONResponse(resp), nil
+		}
+	}
+
+	return users.CreateUser201JSONResponse(UserToResponse(u)), nil
+}
+
+// ListUsers Получить пользователей
+// @Summary Получить пользователей
+// @Description Возвращает пользователей по имени с пагинацией
+// @Tags users
+// @Produce json
+// @Param limit query int false "Количество элементов" default(10)
+// @Param offset query int false "Смещение" default(0)
+// @Success 200 {object} ListUsersResponseDTO "Список пользователей"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные параметры"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /users [get]
+func (h *UserHandler) ListUsers(ctx context.Context, request users.ListUsersRequestObject) (users.ListUsersResponseObject, error) {
+	logger.Info(ctx, "list users called", map[string]interface{}{
+		"params": request.Params,
+	})
+
+	paging, ok := WebhookPaging(request.Params.Limit, request.Params.Offset)
+	if !ok {
+		resp, _ := myerrors.MapError(ctx, myerrors.ErrInvalidData)
+		return users.ListUsers400JSONResponse(resp), nil
+	}
+
+	rows, total, err := h.serv.List(ctx, NewPagingBase(paging))
+	if err != nil {
+		logger.Error(ctx, "error list users", err, nil)
+		resp, _ := myerrors.MapError(ctx, err)
+		return users.ListUsers500JSONResponse(resp), nil
+	}
+
+	return UsersToResponse(rows, paging, total), nil
+}
+
+// GetUser Получить пользователя по ID
+// @Summary Получить пользователя по ID
+// @Description Возвращает пользователя по его идентификатору
+// @Tags users
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} UserDTO "Пользователь найден"
+// @Failure 404 {object} myerrors.ErrorNotFound "Пользователь не найден"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /users/{id} [get]
+func (h *UserHandler) GetUser(ctx context.Context, request users.GetUserRequestObject) (users.GetUserResponseObject, error) {
+	logger.Info(ctx, "get user called", map[string]interface{}{
+		"id": request.Id,
+	})
+
+	u, err := h.serv.Get(ctx, request.Id)
+	if err != nil {
+		logger.Error(ctx, "error get user", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 404:
+			return users.GetUser404JSONResponse(resp), nil
+		default:
+			return users.GetUser500JSONResponse(resp), nil
+		}
+	}
+
+	return users.GetUser200JSONResponse(UserToResponse(u)), nil
+}
+
+// UpdateUser Обновить пользователя
+// @Summary Обновить пользователя
+// @Description Заменяет все поля пользователя
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param request body UserRequestDTO true "Пользователь"
+// @Success 200 {object} UserDTO "Пользователь обновлён"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные"
+// @Failure 404 {object} myerrors.ErrorNotFound "Пользователь не найден"
+// @Failure 409 {object} myerrors.ErrorResponse "Email принадлежит другому пользователю"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /users/{id} [put]
+func (h *UserHandler) UpdateUser(ctx context.Context, request users.UpdateUserRequestObject) (users.UpdateUserResponseObject, error) {
+	logger.Info(ctx, "update user called", map[string]interface{}{
+		"id":           request.Id,
+		"display_name": request.Body.DisplayName,
+	})
+
+	u, err := h.serv.Update(ctx, request.Id, UserRequestToInput(*request.Body))
+	if err != nil {
+		logger.Error(ctx, "error update user", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return users.UpdateUser400JSONResponse(resp), nil
+		case 404:
+			return users.UpdateUser404JSONResponse(resp), nil
+		case 409:
+			return users.UpdateUser409JSONResponse(resp), nil
+		default:
+			return users.UpdateUser500JSONResponse(resp), nil
+		}
+	}
+
+	return users.UpdateUser200JSONResponse(UserToResponse(u)), nil
+}
+
+// DeleteUser Удалить пользователя
+// @Summary Удалить пользователя
+// @Description Удаляет пользователя вместе с его подписками. Его доли в чужих общих подписках возвращаются владельцам
+// @Tags users
+// @Param id path string true "ID пользователя"
+// @Success 204 "Пользователь удалён"
+// @Failure 404 {object} myerrors.ErrorNotFound "Пользователь не найден"
+// @Failure 409 {object} myerrors.ErrorResponse "Во время удаления у пользователя появилась подписка, повторите запрос"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /users/{id} [delete]
+func (h *UserHandler) DeleteUser(ctx context.Context, request users.DeleteUserRequestObject) (users.DeleteUserResponseObject, error) {
+	logger.Info(ctx, "delete user called", map[string]interface{}{
+		"id": request.Id,
+	})
+
+	if _, err := h.serv.Delete(ctx, request.Id); err != nil {
+		logger.Error(ctx, "error delete user", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 404:
+			return users.DeleteUser404JSONResponse(resp), nil
+		case 409:
+			return users.DeleteUser409JSONResponse(resp), nil
+		default:
+			return users.DeleteUser500JSONResponse(resp), nil
+		}
+	}
+
+	return users.DeleteUser204Response{}, nil
+}
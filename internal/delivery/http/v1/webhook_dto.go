@@ -0,0 +1,162 @@
+package v1
+
+import (
+	"testingtask/internal/domain/webhook"
+	"testingtask/internal/service"
+	"testingtask/internal/web/webhooks"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы ниже описывают тела запросов и ответов /webhooks для swagger.
+
+type WebhookRequestDTO struct {
+	UserID     uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	URL        string    `json:"url" example:"https://bot.example.com/hooks/subscriptions"`
+	Secret     *string   `json:"secret,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	EventTypes []string  `json:"event_types" example:"SubscriptionCreated,SubscriptionDeleted"`
+}
+
+type WebhookDTO struct {
+	ID uuid.UUID `json:"id" example:"0b7c6f3e-2a1d-4c5b-9e8f-7a6b5c4d3e2f"`
+	// UserID нет у endpoint'ов, созданных до появления владельцев.
+	UserID     *uuid.UUID `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	URL        string     `json:"url" example:"https://bot.example.com/hooks/subscriptions"`
+	EventTypes []string   `json:"event_types" example:"SubscriptionCreated,SubscriptionDeleted"`
+	CreatedAt  time.Time  `json:"created_at" example:"2026-10-18T13:00:00Z"`
+}
+
+type WebhookCreatedDTO struct {
+	WebhookDTO
+	Secret string `json:"secret" example:"whsec_5f2b8c0e4a7d9e1f3b6c8a0d2e4f6a8b9c1d3e5f7a9b0c2d"`
+}
+
+type ListWebhooksResponseDTO struct {
+	Paging Paging       `json:"paging"`
+	Rows   []WebhookDTO `json:"rows"`
+}
+
+type WebhookAttemptDTO struct {
+	AttemptedAt time.Time `json:"attempted_at" example:"2026-10-18T13:00:01Z"`
+	StatusCode  *int      `json:"status_code" example:"503"`
+	Error       *string   `json:"error" example:"endpoint responded 503 Service Unavailable"`
+	DurationMs  int       `json:"duration_ms" example:"120"`
+}
+
+type WebhookDeliveryDTO struct {
+	ID            uuid.UUID           `json:"id" example:"5d3c2b1a-0f9e-4d8c-b7a6-958473625140"`
+	EventID       uuid.UUID           `json:"event_id" example:"a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d"`
+	EventType     string              `json:"event_type" example:"SubscriptionCreated"`
+	Status        string              `json:"status" enums:"pending,succeeded,failed" example:"pending"`
+	Attempts      int                 `json:"attempts" example:"1"`
+	NextAttemptAt *time.Time          `json:"next_attempt_at" example:"2026-10-18T13:00:11Z"`
+	DeliveredAt   *time.Time          `json:"delivered_at"`
+	CreatedAt     time.Time           `json:"created_at" example:"2026-10-18T13:00:00Z"`
+	AttemptLog    []WebhookAttemptDTO `json:"attempt_log"`
+}
+
+type ListWebhookDeliveriesResponseDTO struct {
+	Paging Paging               `json:"paging"`
+	Rows   []WebhookDeliveryDTO `json:"rows"`
+}
+
+func WebhookRequestToInput(req webhooks.WebhookRequest) service.WebhookInput {
+	types := make([]string, 0, len(req.EventTypes))
+	for _, t := range req.EventTypes {
+		types = append(types, string(t))
+	}
+
+	in := service.WebhookInput{UserID: req.UserId, URL: req.Url, EventTypes: types}
+	if req.Secret != nil {
+		in.Secret = *req.Secret
+	}
+	return in
+}
+
+func EndpointToResponse(e *webhook.Endpoint) webhooks.Webhook {
+	return webhooks.Webhook{
+		Id:         e.ID(),
+		UserId:     endpointUserID(e),
+		Url:        e.URL(),
+		EventTypes: e.EventTypes(),
+		CreatedAt:  e.CreatedAt(),
+	}
+}
+
+func endpointUserID(e *webhook.Endpoint) *uuid.UUID {
+	if id := e.UserID(); id != uuid.Nil {
+		return &id
+	}
+	return nil
+}
+
+func EndpointsToResponse(rows []*webhook.Endpoint, paging PagingBase, total int64) webhooks.ListWebhooks200JSONResponse {
+	res := make([]webhooks.Webhook, 0, len(rows))
+	for _, e := range rows {
+		res = append(res, EndpointToResponse(e))
+	}
+	return webhooks.ListWebhooks200JSONResponse{
+		Paging: webhookPaging(paging, total),
+		Rows:   res,
+	}
+}
+
+func DeliveryToResponse(d webhook.Delivery) webhooks.WebhookDelivery {
+	res := webhooks.WebhookDelivery{
+		Id:          d.ID,
+		EventId:     d.EventID,
+		EventType:   d.EventType,
+		Status:      webhooks.WebhookDeliveryStatus(d.Status),
+		Attempts:    d.Attempts,
+		CreatedAt:   d.CreatedAt,
+		DeliveredAt: d.DeliveredAt,
+		AttemptLog:  make([]webhooks.WebhookAttempt, 0, len(d.Log)),
+	}
+	// Время следующей попытки есть только у ждущей доставки.
+	if d.Status == webhook.StatusPending {
+		next := d.NextAttemptAt
+		res.NextAttemptAt = &next
+	}
+	for _, a := range d.Log {
+		res.AttemptLog = append(res.AttemptLog, webhooks.WebhookAttempt{
+			AttemptedAt: a.AttemptedAt,
+			StatusCode:  a.StatusCode,
+			Error:       a.Error,
+			DurationMs:  int(a.Duration / time.Millisecond),
+		})
+	}
+	return res
+}
+
+func DeliveriesToResponse(rows []webhook.Delivery, paging PagingBase, total int64) webhooks.ListWebhookDeliveries200JSONResponse {
+	res := make([]webhooks.WebhookDelivery, 0, len(rows))
+	for _, d := range rows {
+		res = append(res, DeliveryToResponse(d))
+	}
+	return webhooks.ListWebhookDeliveries200JSONResponse{
+		Paging: webhookPaging(paging, total),
+		Rows:   res,
+	}
+}
+
+func webhookPaging(p PagingBase, total int64) webhooks.Paging {
+	t := int(total)
+	return webhooks.Paging{Limit: &p.Limit, Offset: &p.Offset, Total: &t}
+}
+
+// WebhookPaging читает необязательные limit и offset. Отрицательные
+// значения — ошибка запроса, отсутствующие берутся по умолчанию.
+func WebhookPaging(limit, offset *int) (PagingBase, bool) {
+	l, o := 0, 0
+	if limit != nil {
+		l = *limit
+	}
+	if offset != nil {
+		o = *offset
+	}
+	if l < 0 || o < 0 {
+		return PagingBase{}, false
+	}
+	return *NewBasePaging(l, o), true
+}
@@ -0,0 +1,251 @@
+package v1
+
+import (
+	"context"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/service"
+	"testingtask/internal/web/webhooks"
+	logger "testingtask/pkg"
+)
+
+type WebhookHandler struct {
+	serv service.WebhookService
+}
+
+func NewWebhookHandler(s service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{serv: s}
+}
+
+// CreateWebhook Зарегистрировать webhook
+// @Summary Зарегистрировать webhook
+// @Description Регистрирует URL, на который будут приходить подписанные POST-запросы с событиями выбранных типов. Endpoint получает только события пользователя user_id. Адреса loopback, link-local и частных сетей отклоняются. Если secret не передан, он генерируется; secret возвращается только в этом ответе
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body WebhookRequestDTO true "Владелец, адрес, ключ подписи и типы событий"
+// @Success 201 {object} WebhookCreatedDTO "Webhook зарегистрирован"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(ctx context.Context, request webhooks.CreateWebhookRequestObject) (webhooks.CreateWebhookResponseObject, error) {
+	logger.Info(ctx, "create webhook called", map[string]interface{}{
+		"user_id":     request.Body.UserId,
+		"url":         request.Body.Url,
+		"event_types": request.Body.EventTypes,
+	})
+
+	e, err := h.serv.Create(ctx, WebhookRequestToInput(*request.Body))
+	if err != nil {
+		logger.Error(ctx, "error create webhook", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return webhooks.CreateWebhook400JSONResponse(resp), nil
+		default:
+			return webhooks.CreateWebhook500JSONResponse(resp), nil
+		}
+	}
+
+	return webhooks.CreateWebhook201JSONResponse{
+		Id:         e.ID(),
+		UserId:     endpointUserID(e),
+		Url:        e.URL(),
+		EventTypes: e.EventTypes(),
+		CreatedAt:  e.CreatedAt(),
+		Secret:     e.Secret(),
+	}, nil
+}
+
+// ListWebhooks Получить список webhook'ов
+// @Summary Получить список webhook'ов
+// @Description Возвращает зарегистрированные webhook'и с пагинацией, без ключей подписи
+// @Tags webhooks
+// @Produce json
+// @Param limit query int false "Количество элементов" default(10)
+// @Param offset query int false "Смещение" default(0)
+// @Success 200 {object} ListWebhooksResponseDTO "Список webhook'ов"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные параметры"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(ctx context.Context, request webhooks.ListWebhooksRequestObject) (webhooks.ListWebhooksResponseObject, error) {
+	logger.Info(ctx, "list webhooks called", map[string]interface{}{
+		"params": request.Params,
+	})
+
+	paging, ok := WebhookPaging(request.Params.Limit, request.Params.Offset)
+	if !ok {
+		resp, _ := myerrors.MapError(ctx, myerrors.ErrInvalidData)
+		return webhooks.ListWebhooks400JSONResponse(resp), nil
+	}
+
+	rows, total, err := h.serv.List(ctx, NewPagingBase(paging))
+	if err != nil {
+		logger.Error(ctx, "error list webhooks", err, nil)
+		resp, _ := myerrors.MapError(ctx, err)
+		return webhooks.ListWebhooks500JSONResponse(resp), nil
+	}
+
+	return EndpointsToResponse(rows, paging, total), nil
+}
+
+// GetWebhook Получить webhook по ID
+// @Summary Получить webhook по ID
+// @Description Возвращает webhook без ключа подписи
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "ID webhook'а"
+// @Success 200 {object} WebhookDTO "Webhook найден"
+// @Failure 404 {object} myerrors.ErrorNotFound "Webhook не найден"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(ctx context.Context, request webhooks.GetWebhookRequestObject) (webhooks.GetWebhookResponseObject, error) {
+	logger.Info(ctx, "get webhook called", map[string]interface{}{
+		"id": request.Id,
+	})
+
+	e, err := h.serv.Get(ctx, request.Id)
+	if err != nil {
+		logger.Error(ctx, "error get webhook", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 404:
+			return webhooks.GetWebhook404JSONResponse(resp), nil
+		default:
+			return webhooks.GetWebhook500JSONResponse(resp), nil
+		}
+	}
+
+	return webhooks.GetWebhook200JSONResponse(EndpointToResponse(e)), nil
+}
+
+// UpdateWebhook Обновить webhook
+// @Summary Обновить webhook
+// @Description Заменяет владельца, адрес и типы событий webhook'а. Пустой или отсутствующий secret оставляет прежний ключ подписи
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "ID webhook'а"
+// @Param request body WebhookRequestDTO true "Владелец, адрес, ключ подписи и типы событий"
+// @Success 200 {object} WebhookDTO "Webhook обновлён"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные данные"
+// @Failure 404 {object} myerrors.ErrorNotFound "Webhook не найден"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(ctx context.Context, request webhooks.UpdateWebhookRequestObject) (webhooks.UpdateWebhookResponseObject, error) {
+	logger.Info(ctx, "update webhook called", map[string]interface{}{
+		"id":          request.Id,
+		"user_id":     request.Body.UserId,
+		"url":         request.Body.Url,
+		"event_types": request.Body.EventTypes,
+	})
+
+	e, err := h.serv.Update(ctx, request.Id, WebhookRequestToInput(*request.Body))
+	if err != nil {
+		logger.Error(ctx, "error update webhook", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 400:
+			return webhooks.UpdateWebhook400JSONResponse(resp), nil
+		case 404:
+			return webhooks.UpdateWebhook404JSONResponse(resp), nil
+		default:
+			return webhooks.UpdateWebhook500JSONResponse(resp), nil
+		}
+	}
+
+	return webhooks.UpdateWebhook200JSONResponse(EndpointToResponse(e)), nil
+}
+
+// DeleteWebhook Удалить webhook
+// @Summary Удалить webhook
+// @Description Удаляет webhook вместе с историей доставок
+// @Tags webhooks
+// @Param id path string true "ID webhook'а"
+// @Success 204 "Webhook удалён"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(ctx context.Context, request webhooks.DeleteWebhookRequestObject) (webhooks.DeleteWebhookResponseObject, error) {
+	logger.Info(ctx, "delete webhook called", map[string]interface{}{
+		"id": request.Id,
+	})
+
+	if err := h.serv.Delete(ctx, request.Id); err != nil {
+		logger.Error(ctx, "error delete webhook", err, nil)
+		resp, _ := myerrors.MapError(ctx, err)
+		return webhooks.DeleteWebhook500JSONResponse(resp), nil
+	}
+
+	return webhooks.DeleteWebhook204Response{}, nil
+}
+
+// ListWebhookDeliveries Получить историю доставок webhook'а
+// @Summary Получить историю доставок webhook'а
+// @Description Возвращает доставки событий на webhook, новые первыми, с журналом попыток каждой
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "ID webhook'а"
+// @Param limit query int false "Количество элементов" default(10)
+// @Param offset query int false "Смещение" default(0)
+// @Success 200 {object} ListWebhookDeliveriesResponseDTO "История доставок"
+// @Failure 400 {object} myerrors.ErrorResponse "Некорректные параметры"
+// @Failure 404 {object} myerrors.ErrorNotFound "Webhook не найден"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(ctx context.Context, request webhooks.ListWebhookDeliveriesRequestObject) (webhooks.ListWebhookDeliveriesResponseObject, error) {
+	logger.Info(ctx, "list webhook deliveries called", map[string]interface{}{
+		"id":     request.Id,
+		"params": request.Params,
+	})
+
+	paging, ok := WebhookPaging(request.Params.Limit, request.Params.Offset)
+	if !ok {
+		resp, _ := myerrors.MapError(ctx, myerrors.ErrInvalidData)
+		return webhooks.ListWebhookDeliveries400JSONResponse(resp), nil
+	}
+
+	rows, total, err := h.serv.Deliveries(ctx, request.Id, NewPagingBase(paging))
+	if err != nil {
+		logger.Error(ctx, "error list webhook deliveries", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 404:
+			return webhooks.ListWebhookDeliveries404JSONResponse(resp), nil
+		default:
+			return webhooks.ListWebhookDeliveries500JSONResponse(resp), nil
+		}
+	}
+
+	return DeliveriesToResponse(rows, paging, total), nil
+}
+
+// RedeliverWebhook Отправить доставку повторно
+// @Summary Отправить доставку повторно
+// @Description Ставит доставку в очередь на немедленную отправку, в том числе после исчерпания попыток
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "ID webhook'а"
+// @Param delivery_id path string true "ID доставки"
+// @Success 202 {object} WebhookDeliveryDTO "Доставка поставлена в очередь"
+// @Failure 404 {object} myerrors.ErrorNotFound "Доставка не найдена"
+// @Failure 500 {object} myerrors.ErrorInternalServerError "Внутренняя ошибка сервера"
+// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) RedeliverWebhook(ctx context.Context, request webhooks.RedeliverWebhookRequestObject) (webhooks.RedeliverWebhookResponseObject, error) {
+	logger.Info(ctx, "redeliver webhook called", map[string]interface{}{
+		"id":          request.Id,
+		"delivery_id": request.DeliveryId,
+	})
+
+	d, err := h.serv.Redeliver(ctx, request.Id, request.DeliveryId)
+	if err != nil {
+		logger.Error(ctx, "error redeliver webhook", err, nil)
+		resp, code := myerrors.MapError(ctx, err)
+		switch code {
+		case 404:
+			return webhooks.RedeliverWebhook404JSONResponse(resp), nil
+		default:
+			return webhooks.RedeliverWebhook500JSONResponse(resp), nil
+		}
+	}
+
+	return webhooks.RedeliverWebhook202JSONResponse(DeliveryToResponse(*d)), nil
+}
@@ -0,0 +1,272 @@
+// Package analytics считает метрики выручки по подпискам: MRR и его
+// движение, активных подписчиков и отток по сервисам.
+//
+// Клиент — пользователь (user_id): MRR пользователя — сумма цен его
+// подписок, оплачиваемых в месяце. Истории цен нет, поэтому подписка
+// весь срок действия приносит свою текущую цену.
+package analytics
+
+import (
+	"errors"
+	"sort"
+	domain "testingtask/internal/domain/subscription"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRange = errors.New("from must not be after to")
+	ErrRangeTooLong = errors.New("range must not exceed 120 months")
+)
+
+// MaxMonths — самый длинный диапазон отчёта.
+const MaxMonths = 120
+
+// Revenue — выручка одного пользователя по одному сервису в месяце.
+type Revenue struct {
+	UserID        uuid.UUID
+	ServiceName   string
+	MRR           int
+	Subscriptions int
+}
+
+// Month — выручка за месяц Month (первое число, UTC) по пользователям и
+// сервисам.
+type Month struct {
+	Month   time.Time
+	Revenue []Revenue
+}
+
+// StartOfMonth возвращает первое число месяца t в UTC.
+func StartOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Closed сообщает, закончился ли месяц month к моменту now.
+func Closed(month, now time.Time) bool {
+	return StartOfMonth(month).Before(StartOfMonth(now))
+}
+
+// Range возвращает месяцы с from по to включительно.
+func Range(from, to time.Time) ([]time.Time, error) {
+	from, to = StartOfMonth(from), StartOfMonth(to)
+	if from.After(to) {
+		return nil, ErrInvalidRange
+	}
+
+	n := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+	if n > MaxMonths {
+		return nil, ErrRangeTooLong
+	}
+
+	res := make([]time.Time, n)
+	for i := range res {
+		res[i] = from.AddDate(0, i, 0)
+	}
+	return res, nil
+}
+
+// Aggregate собирает выручку подписок subs по месяцам months. Результат
+// совпадает с тем, что считает SQL репозитория, и нужен хранилищу в памяти
+// и тестам.
+func Aggregate(subs []*domain.Subscription, months []time.Time) []Month {
+	type key struct {
+		user    uuid.UUID
+		service string
+	}
+
+	res := make([]Month, 0, len(months))
+	for _, m := range months {
+		cells := make(map[key]*Revenue)
+		for _, s := range subs {
+			if !s.ActiveIn(m) {
+				continue
+			}
+			k := key{s.UserID(), s.ServiceName()}
+			c := cells[k]
+			if c == nil {
+				c = &Revenue{UserID: k.user, ServiceName: k.service}
+				cells[k] = c
+			}
+			c.MRR += s.Price()
+			c.Subscriptions++
+		}
+
+		month := Month{Month: m, Revenue: make([]Revenue, 0, len(cells))}
+		for _, c := range cells {
+			month.Revenue = append(month.Revenue, *c)
+		}
+		month.Sort()
+		res = append(res, month)
+	}
+	return res
+}
+
+// Sort упорядочивает выручку по пользователю и сервису.
+func (m *Month) Sort() {
+	sort.Slice(m.Revenue, func(i, j int) bool {
+		a, b := m.Revenue[i], m.Revenue[j]
+		if a.UserID != b.UserID {
+			return a.UserID.String() < b.UserID.String()
+		}
+		return a.ServiceName < b.ServiceName
+	})
+}
+
+// Service оставляет выручку одного сервиса.
+func (m Month) Service(name string) Month {
+	res := Month{Month: m.Month}
+	for _, r := range m.Revenue {
+		if r.ServiceName == name {
+			res.Revenue = append(res.Revenue, r)
+		}
+	}
+	return res
+}
+
+// byUser возвращает MRR и число подписок каждого пользователя.
+func (m Month) byUser() (map[uuid.UUID]int, int) {
+	res := make(map[uuid.UUID]int)
+	subs := 0
+	for _, r := range m.Revenue {
+		res[r.UserID] += r.MRR
+		subs += r.Subscriptions
+	}
+	return res, subs
+}
+
+// MRRPoint — MRR месяца и его изменение к предыдущему месяцу по
+// клиентам: New — пользователи, у которых в прошлом месяце не было
+// подписок, Churned — у которых их не стало, Expansion и Contraction —
+// рост и снижение у остальных. NetNew = New + Expansion − Contraction −
+// Churned = MRR − MRR прошлого месяца.
+type MRRPoint struct {
+	Month               time.Time
+	MRR                 int
+	New                 int
+	Expansion           int
+	Contraction         int
+	Churned             int
+	NetNew              int
+	ActiveSubscribers   int
+	ActiveSubscriptions int
+}
+
+// MRR считает точки для months; prev — месяц перед первым из них.
+func MRR(prev Month, months []Month) []MRRPoint {
+	res := make([]MRRPoint, 0, len(months))
+	before, _ := prev.byUser()
+
+	for _, m := range months {
+		now, subs := m.byUser()
+		p := MRRPoint{Month: m.Month, ActiveSubscribers: len(now), ActiveSubscriptions: subs}
+
+		for user, mrr := range now {
+			p.MRR += mrr
+			old, ok := before[user]
+			switch {
+			case !ok:
+				p.New += mrr
+			case mrr > old:
+				p.Expansion += mrr - old
+			case mrr < old:
+				p.Contraction += old - mrr
+			}
+		}
+		for user, old := range before {
+			if _, ok := now[user]; !ok {
+				p.Churned += old
+			}
+		}
+		p.NetNew = p.New + p.Expansion - p.Contraction - p.Churned
+
+		res = append(res, p)
+		before = now
+	}
+	return res
+}
+
+// ChurnPoint — отток сервиса за месяц: Subscribers — пользователи с
+// подпиской на сервис в прошлом месяце, Churned — те из них, у кого её
+// в этом месяце нет, Active — пользователи с подпиской в этом месяце.
+type ChurnPoint struct {
+	Month       time.Time
+	Active      int
+	Subscribers int
+	Churned     int
+}
+
+// Rate — доля ушедших, 0 без подписчиков.
+func (p ChurnPoint) Rate() float64 {
+	return rate(p.Churned, p.Subscribers)
+}
+
+// ServiceChurn — отток сервиса по месяцам и за весь диапазон.
+type ServiceChurn struct {
+	ServiceName string
+	Subscribers int
+	Churned     int
+	Points      []ChurnPoint
+}
+
+// Rate — средний месячный отток: все ушедшие к сумме подписчиков на
+// начало каждого месяца.
+func (s ServiceChurn) Rate() float64 {
+	return rate(s.Churned, s.Subscribers)
+}
+
+func rate(churned, subscribers int) float64 {
+	if subscribers == 0 {
+		return 0
+	}
+	return float64(churned) / float64(subscribers)
+}
+
+// Churn считает отток по сервисам, у которых были подписчики в prev или
+// months. Сервисы отсортированы по имени.
+func Churn(prev Month, months []Month) []ServiceChurn {
+	users := func(m Month) map[string]map[uuid.UUID]bool {
+		res := make(map[string]map[uuid.UUID]bool)
+		for _, r := range m.Revenue {
+			if res[r.ServiceName] == nil {
+				res[r.ServiceName] = make(map[uuid.UUID]bool)
+			}
+			res[r.ServiceName][r.UserID] = true
+		}
+		return res
+	}
+
+	all := []Month{prev}
+	all = append(all, months...)
+	byMonth := make([]map[string]map[uuid.UUID]bool, len(all))
+	services := make(map[string]bool)
+	for i, m := range all {
+		byMonth[i] = users(m)
+		for name := range byMonth[i] {
+			services[name] = true
+		}
+	}
+
+	res := make([]ServiceChurn, 0, len(services))
+	for name := range services {
+		sc := ServiceChurn{ServiceName: name, Points: make([]ChurnPoint, 0, len(months))}
+		for i, m := range months {
+			before, now := byMonth[i][name], byMonth[i+1][name]
+			p := ChurnPoint{Month: m.Month, Active: len(now), Subscribers: len(before)}
+			for user := range before {
+				if !now[user] {
+					p.Churned++
+				}
+			}
+			sc.Subscribers += p.Subscribers
+			sc.Churned += p.Churned
+			sc.Points = append(sc.Points, p)
+		}
+		res = append(res, sc)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].ServiceName < res[j].ServiceName })
+	return res
+}
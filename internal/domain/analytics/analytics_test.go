@@ -0,0 +1,142 @@
+package analytics
+
+import (
+	"errors"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	userA = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	userB = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	userC = uuid.MustParse("33333333-3333-3333-3333-333333333333")
+)
+
+func month(m time.Month) time.Time {
+	return time.Date(2025, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func sub(user uuid.UUID, service string, price int, start time.Month, end time.Month) *domain.Subscription {
+	var e *domain.SubDate
+	if end != 0 {
+		e = domain.NewSubDateFromTime(month(end))
+	}
+	return domain.RestoreSubscription(uuid.New(), service, domain.Price(price), user, *domain.NewSubDateFromTime(month(start)), e)
+}
+
+// fixture — январь (prev) и февраль–апрель 2025:
+//   - A: Netflix 400 весь период, Spotify 200 с марта — expansion в марте;
+//   - B: Netflix 1000 по март — churn в апреле;
+//   - C: Spotify 300 с февраля — new в феврале, в апреле переходит на
+//     Netflix 100 — contraction.
+func fixture(t *testing.T) (Month, []Month) {
+	t.Helper()
+	subs := []*domain.Subscription{
+		sub(userA, "Netflix", 400, time.January, 0),
+		sub(userA, "Spotify", 200, time.March, 0),
+		sub(userB, "Netflix", 1000, time.January, time.March),
+		sub(userC, "Spotify", 300, time.February, time.March),
+		sub(userC, "Netflix", 100, time.April, 0),
+	}
+	months, err := Range(month(time.January), month(time.April))
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg := Aggregate(subs, months)
+	return agg[0], agg[1:]
+}
+
+func TestMRR(t *testing.T) {
+	prev, months := fixture(t)
+	got := MRR(prev, months)
+
+	want := []MRRPoint{
+		{Month: month(time.February), MRR: 1700, New: 300, NetNew: 300, ActiveSubscribers: 3, ActiveSubscriptions: 3},
+		{Month: month(time.March), MRR: 1900, Expansion: 200, NetNew: 200, ActiveSubscribers: 3, ActiveSubscriptions: 4},
+		{Month: month(time.April), MRR: 700, Contraction: 200, Churned: 1000, NetNew: -1200, ActiveSubscribers: 2, ActiveSubscriptions: 3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%d points, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("point %d:\n got %+v\nwant %+v", i, got[i], want[i])
+		}
+	}
+
+	// NetNew всегда равен изменению MRR.
+	last := 1400
+	for _, p := range got {
+		if p.NetNew != p.MRR-last {
+			t.Errorf("%s: net new %d, MRR change %d", p.Month.Format("01-2006"), p.NetNew, p.MRR-last)
+		}
+		last = p.MRR
+	}
+}
+
+func TestMRRService(t *testing.T) {
+	prev, months := fixture(t)
+	for i := range months {
+		months[i] = months[i].Service("Spotify")
+	}
+	got := MRR(prev.Service("Spotify"), months)
+
+	// В апреле у C нет Spotify: по сервису это отток, хотя клиент остался.
+	if got[0].New != 300 || got[1].New != 200 || got[2].Churned != 300 || got[2].MRR != 200 {
+		t.Fatalf("Spotify MRR: %+v", got)
+	}
+}
+
+func TestChurn(t *testing.T) {
+	prev, months := fixture(t)
+	got := Churn(prev, months)
+
+	if len(got) != 2 || got[0].ServiceName != "Netflix" || got[1].ServiceName != "Spotify" {
+		t.Fatalf("services: %+v", got)
+	}
+
+	netflix := got[0]
+	// Подписчики на начало месяца: 2, 2, 2; в апреле ушёл B.
+	if netflix.Subscribers != 6 || netflix.Churned != 1 {
+		t.Fatalf("Netflix: subscribers %d, churned %d", netflix.Subscribers, netflix.Churned)
+	}
+	april := netflix.Points[2]
+	if april.Active != 2 || april.Subscribers != 2 || april.Churned != 1 || april.Rate() != 0.5 {
+		t.Fatalf("Netflix April: %+v, rate %v", april, april.Rate())
+	}
+
+	spotify := got[1]
+	if spotify.Points[0].Subscribers != 0 || spotify.Points[0].Rate() != 0 {
+		t.Fatalf("Spotify February without subscribers: %+v", spotify.Points[0])
+	}
+	if spotify.Churned != 1 || spotify.Subscribers != 3 {
+		t.Fatalf("Spotify: subscribers %d, churned %d", spotify.Subscribers, spotify.Churned)
+	}
+	if r := spotify.Rate(); r < 0.33 || r > 0.34 {
+		t.Fatalf("Spotify rate %v, want 1/3", r)
+	}
+}
+
+func TestRange(t *testing.T) {
+	months, err := Range(time.Date(2024, time.November, 20, 0, 0, 0, 0, time.UTC), month(time.February))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(months) != 4 || !months[0].Equal(time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)) || !months[3].Equal(month(time.February)) {
+		t.Fatalf("months: %v", months)
+	}
+
+	if _, err := Range(month(time.March), month(time.February)); !errors.Is(err, ErrInvalidRange) {
+		t.Fatalf("reversed range: got %v, want ErrInvalidRange", err)
+	}
+	if _, err := Range(month(time.January), month(time.January).AddDate(0, MaxMonths, 0)); !errors.Is(err, ErrRangeTooLong) {
+		t.Fatalf("long range: got %v, want ErrRangeTooLong", err)
+	}
+
+	if !Closed(month(time.March), month(time.April).Add(time.Hour)) || Closed(month(time.April), month(time.April).Add(time.Hour)) {
+		t.Fatal("only months before the current one are closed")
+	}
+}
@@ -0,0 +1,174 @@
+package budget
+
+import (
+	"errors"
+	"testingtask/internal/domain/catalog"
+	domain "testingtask/internal/domain/subscription"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidLimit       = errors.New("monthly_limit must be positive")
+	ErrInvalidServiceName = errors.New("service_name must be 1 to 100 characters")
+	ErrInvalidCategory    = errors.New("category must be 1-50 lowercase latin letters, digits or dashes")
+	ErrScopeConflict      = errors.New("budget is either for a service_name or for a category, not both")
+	ErrBudgetExists       = errors.New("budget for this user and scope already exists")
+	ErrBudgetExceeded     = errors.New("subscription exceeds a hard budget limit")
+)
+
+// MaxServiceNameLength совпадает с длиной subscriptions.service_name.
+const MaxServiceNameLength = 100
+
+// Budget — месячный лимит трат пользователя на подписки: общий, на один
+// сервис или на категорию каталога сервисов.
+type Budget struct {
+	id          uuid.UUID
+	userID      uuid.UUID
+	serviceName *string
+	category    *string
+	limit       int
+	hardLimit   bool
+	createdAt   time.Time
+}
+
+// NewBudget создаёт бюджет. serviceName задаёт бюджет на сервис, category —
+// на категорию каталога, оба nil — бюджет на все подписки пользователя.
+// hardLimit запрещает подписки, с которыми траты выйдут за лимит, иначе
+// превышение только порождает событие.
+func NewBudget(id, userID uuid.UUID, serviceName, category *string, limit int, hardLimit bool) (*Budget, error) {
+	if limit <= 0 {
+		return nil, ErrInvalidLimit
+	}
+	if serviceName != nil && (*serviceName == "" || len(*serviceName) > MaxServiceNameLength) {
+		return nil, ErrInvalidServiceName
+	}
+	if category != nil && !catalog.ValidCategory(*category) {
+		return nil, ErrInvalidCategory
+	}
+	if serviceName != nil && category != nil {
+		return nil, ErrScopeConflict
+	}
+
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+
+	return RestoreBudget(id, userID, serviceName, category, limit, hardLimit, time.Now().UTC()), nil
+}
+
+// RestoreBudget восстанавливает бюджет из хранилища без проверок.
+func RestoreBudget(id, userID uuid.UUID, serviceName, category *string, limit int, hardLimit bool, createdAt time.Time) *Budget {
+	return &Budget{
+		id:          id,
+		userID:      userID,
+		serviceName: serviceName,
+		category:    category,
+		limit:       limit,
+		hardLimit:   hardLimit,
+		createdAt:   createdAt,
+	}
+}
+
+func (b *Budget) ID() uuid.UUID {
+	return b.id
+}
+func (b *Budget) UserID() uuid.UUID {
+	return b.userID
+}
+func (b *Budget) ServiceName() *string {
+	return b.serviceName
+}
+func (b *Budget) Category() *string {
+	return b.category
+}
+func (b *Budget) MonthlyLimit() int {
+	return b.limit
+}
+func (b *Budget) HardLimit() bool {
+	return b.hardLimit
+}
+func (b *Budget) CreatedAt() time.Time {
+	return b.createdAt
+}
+
+// SameScope сообщает, относятся ли бюджеты к одному пользователю и одной
+// области: всем подпискам, сервису или категории.
+func (b *Budget) SameScope(o *Budget) bool {
+	return b.userID == o.userID && sameName(b.serviceName, o.serviceName) && sameName(b.category, o.category)
+}
+
+func sameName(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// Categories — категории сервисов каталога по их ID. Сервисов без
+// категории в ней нет.
+type Categories map[uuid.UUID]string
+
+// Of возвращает категорию сервиса, на который ссылается sub.
+func (c Categories) Of(sub *domain.Subscription) (string, bool) {
+	if sub.ServiceID() == nil {
+		return "", false
+	}
+	category, ok := c[*sub.ServiceID()]
+	return category, ok
+}
+
+// Covers сообщает, учитывается ли подписка в этом бюджете. Общая
+// подписка с долей (Share) прочитана Sum для участника и учитывается в его
+// бюджетах. Бюджет на категорию покрывает подписки, связанные с сервисом
+// каталога этой категории (service_id); categories нужны только ему.
+func (b *Budget) Covers(sub *domain.Subscription, categories Categories) bool {
+	if sub.UserID() != b.userID && sub.Share() == nil {
+		return false
+	}
+	switch {
+	case b.serviceName != nil:
+		return *b.serviceName == sub.ServiceName()
+	case b.category != nil:
+		category, ok := categories.Of(sub)
+		return ok && category == *b.category
+	}
+	return true
+}
+
+// Spent возвращает траты по бюджету в месяце month: сумму цен (в общих
+// подписках — долей) подписок, которые он покрывает и которые
+// оплачиваются в этом месяце.
+func (b *Budget) Spent(subs []*domain.Subscription, categories Categories, month time.Time) int {
+	total := 0
+	for _, sub := range subs {
+		if b.Covers(sub, categories) && sub.ActiveIn(month) {
+			total += sub.Amount()
+		}
+	}
+	return total
+}
+
+// Month возвращает первое число месяца t в UTC — месяц, за который
+// считаются траты.
+func Month(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Status — траты по бюджету за месяц.
+type Status struct {
+	Budget *Budget
+	Month  time.Time
+	Spent  int
+}
+
+// Remaining — остаток лимита, отрицательный при превышении.
+func (s Status) Remaining() int {
+	return s.Budget.limit - s.Spent
+}
+
+func (s Status) Exceeded() bool {
+	return s.Spent > s.Budget.limit
+}
@@ -0,0 +1,192 @@
+// Package catalog — каталог сервисов: каноническое имя, синонимы,
+// категория, цена по умолчанию и логотип. Подписки ссылаются на сервис
+// каталога, а свободный текст service_name сводится к нему по ключу
+// сопоставления (Key).
+package catalog
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmptyName       = errors.New("service name is empty")
+	ErrNameTooLong     = errors.New("service name must be at most 100 characters")
+	ErrInvalidAlias    = errors.New("alias must contain letters or digits and be at most 100 characters")
+	ErrInvalidCategory = errors.New("category must be 1-50 lowercase latin letters, digits or dashes")
+	ErrInvalidPrice    = errors.New("default price must be positive")
+	ErrInvalidCurrency = errors.New("currency must be a 3-letter ISO 4217 code")
+	ErrInvalidLogoURL  = errors.New("logo url must be an absolute http(s) url of at most 500 characters")
+	// ErrAliasTaken — имя или синоним уже сводится к другому сервису.
+	ErrAliasTaken = errors.New("name or alias already belongs to another service")
+	// ErrUnknownService — подписка ссылается на отсутствующий сервис.
+	ErrUnknownService = errors.New("unknown service_id")
+)
+
+const (
+	MaxNameLength    = 100
+	MaxLogoURLLength = 500
+	DefaultCurrency  = "RUB"
+)
+
+var (
+	categoryRe = regexp.MustCompile(`^[a-z0-9-]{1,50}$`)
+	currencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// Service — сервис каталога.
+type Service struct {
+	id           uuid.UUID
+	name         string
+	aliases      []string
+	category     *string
+	defaultPrice *int
+	currency     string
+	logoURL      *string
+	createdAt    time.Time
+}
+
+// NewService проверяет поля и собирает сервис. id == uuid.Nil — новый ID,
+// пустая currency — DefaultCurrency. Синонимы, совпадающие по ключу с
+// именем или друг с другом, отбрасываются.
+func NewService(id uuid.UUID, name string, aliases []string, category *string, defaultPrice *int, currency string, logoURL *string) (*Service, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrEmptyName
+	}
+	if len([]rune(name)) > MaxNameLength {
+		return nil, ErrNameTooLong
+	}
+	if Key(name) == "" {
+		return nil, ErrInvalidAlias
+	}
+
+	seen := map[string]bool{Key(name): true}
+	clean := make([]string, 0, len(aliases))
+	for _, a := range aliases {
+		a = strings.TrimSpace(a)
+		k := Key(a)
+		if k == "" || len([]rune(a)) > MaxNameLength {
+			return nil, ErrInvalidAlias
+		}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		clean = append(clean, a)
+	}
+
+	if category != nil && !ValidCategory(*category) {
+		return nil, ErrInvalidCategory
+	}
+	if defaultPrice != nil && *defaultPrice <= 0 {
+		return nil, ErrInvalidPrice
+	}
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if !currencyRe.MatchString(currency) {
+		return nil, ErrInvalidCurrency
+	}
+	if logoURL != nil {
+		if err := validateLogoURL(*logoURL); err != nil {
+			return nil, err
+		}
+	}
+
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+
+	return &Service{
+		id:           id,
+		name:         name,
+		aliases:      clean,
+		category:     category,
+		defaultPrice: defaultPrice,
+		currency:     currency,
+		logoURL:      logoURL,
+		createdAt:    time.Now().UTC(),
+	}, nil
+}
+
+// RestoreService восстанавливает сервис из хранилища без проверок.
+func RestoreService(id uuid.UUID, name string, aliases []string, category *string, defaultPrice *int, currency string, logoURL *string, createdAt time.Time) *Service {
+	return &Service{
+		id:           id,
+		name:         name,
+		aliases:      aliases,
+		category:     category,
+		defaultPrice: defaultPrice,
+		currency:     currency,
+		logoURL:      logoURL,
+		createdAt:    createdAt,
+	}
+}
+
+// ValidCategory сообщает, подходит ли category как категория сервиса.
+func ValidCategory(category string) bool {
+	return categoryRe.MatchString(category)
+}
+
+func validateLogoURL(s string) error {
+	if len(s) > MaxLogoURLLength {
+		return ErrInvalidLogoURL
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidLogoURL
+	}
+	return nil
+}
+
+func (s *Service) ID() uuid.UUID        { return s.id }
+func (s *Service) Name() string         { return s.name }
+func (s *Service) Aliases() []string    { return s.aliases }
+func (s *Service) Category() *string    { return s.category }
+func (s *Service) DefaultPrice() *int   { return s.defaultPrice }
+func (s *Service) Currency() string     { return s.currency }
+func (s *Service) LogoURL() *string     { return s.logoURL }
+func (s *Service) CreatedAt() time.Time { return s.createdAt }
+
+// Keys возвращает ключи сопоставления имени и синонимов с исходным
+// написанием: по ним свободный текст сводится к сервису.
+func (s *Service) Keys() map[string]string {
+	res := map[string]string{Key(s.name): s.name}
+	for _, a := range s.aliases {
+		res[Key(a)] = a
+	}
+	return res
+}
+
+// translit переводит кириллицу в латиницу так, как обычно пишут названия
+// сервисов: «кс» — x, «ф» — f («Нетфликс» — netflix).
+var translit = strings.NewReplacer(
+	"кс", "x",
+	"а", "a", "б", "b", "в", "v", "г", "g", "д", "d", "е", "e", "ё", "e",
+	"ж", "zh", "з", "z", "и", "i", "й", "y", "к", "k", "л", "l", "м", "m",
+	"н", "n", "о", "o", "п", "p", "р", "r", "с", "s", "т", "t", "у", "u",
+	"ф", "f", "х", "h", "ц", "c", "ч", "ch", "ш", "sh", "щ", "sch", "ъ", "",
+	"ы", "y", "ь", "", "э", "e", "ю", "yu", "я", "ya",
+)
+
+// Key — ключ сопоставления названия: нижний регистр, кириллица в
+// латинице, без пробелов, знаков и других символов, кроме a-z и 0-9.
+// «Netflix», «netflix », «Net-flix» и «Нетфликс» дают netflix. Миграция
+// каталога считает тот же ключ на SQL.
+func Key(name string) string {
+	s := translit.Replace(strings.ToLower(name))
+	var b strings.Builder
+	for _, r := range s {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package catalog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestKey(t *testing.T) {
+	cases := map[string]string{
+		"Netflix":          "netflix",
+		" netflix ":        "netflix",
+		"Net-Flix":         "netflix",
+		"Нетфликс":         "netflix",
+		"Яндекс Плюс":      "yandexplyus",
+		"YouTube Premium!": "youtubepremium",
+		"Кинопоиск":        "kinopoisk",
+		"1Password":        "1password",
+		"Шёпот":            "shepot",
+		"---":              "",
+	}
+	for in, want := range cases {
+		if got := Key(in); got != want {
+			t.Errorf("Key(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNewService(t *testing.T) {
+	s, err := NewService(uuid.Nil, " Netflix ", []string{"Нетфликс", "netflix", " NFLX "}, ptr("streaming"), ptr(799), "", ptr("https://example.com/netflix.svg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ID() == uuid.Nil || s.Name() != "Netflix" || s.Currency() != DefaultCurrency {
+		t.Fatalf("service: id %v, name %q, currency %q", s.ID(), s.Name(), s.Currency())
+	}
+	// «Нетфликс» и «netflix» сводятся к имени и не хранятся отдельно.
+	if len(s.Aliases()) != 1 || s.Aliases()[0] != "NFLX" {
+		t.Fatalf("aliases %v, want [NFLX]", s.Aliases())
+	}
+	keys := s.Keys()
+	if len(keys) != 2 || keys["netflix"] != "Netflix" || keys["nflx"] != "NFLX" {
+		t.Fatalf("keys %v", keys)
+	}
+}
+
+func TestNewServiceInvalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		aliases  []string
+		category *string
+		price    *int
+		currency string
+		logo     *string
+		want     error
+	}{
+		{name: "  ", want: ErrEmptyName},
+		{name: "!!!", want: ErrInvalidAlias},
+		{name: "Netflix", aliases: []string{"--"}, want: ErrInvalidAlias},
+		{name: "Netflix", category: ptr("Streaming"), want: ErrInvalidCategory},
+		{name: "Netflix", price: ptr(0), want: ErrInvalidPrice},
+		{name: "Netflix", currency: "rub", want: ErrInvalidCurrency},
+		{name: "Netflix", logo: ptr("ftp://example.com/logo.png"), want: ErrInvalidLogoURL},
+		{name: "Netflix", logo: ptr("/logo.png"), want: ErrInvalidLogoURL},
+	}
+	for _, c := range cases {
+		_, err := NewService(uuid.Nil, c.name, c.aliases, c.category, c.price, c.currency, c.logo)
+		if !errors.Is(err, c.want) {
+			t.Errorf("%q: got %v, want %v", c.name, err, c.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package reminder
+
+import (
+	"errors"
+	"net/mail"
+	"net/url"
+	"testingtask/internal/netguard"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidDaysBefore = errors.New("days_before must be between 1 and 90")
+	ErrUnknownChannel    = errors.New("unknown notification channel")
+	ErrInvalidEmail      = errors.New("email channel needs a valid email address")
+	ErrInvalidWebhookURL = errors.New("webhook channel needs an absolute http or https URL")
+	ErrChannelDisabled   = errors.New("notification channel is not configured on this server")
+)
+
+// MaxDaysBefore — самое раннее напоминание, дней до окончания.
+const MaxDaysBefore = 90
+
+// Каналы доставки напоминаний.
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelLog     = "log"
+)
+
+// KindExpiry — напоминание об окончании подписки (end_date). Другие виды,
+// например продление по расчётному периоду, получат свои значения.
+const KindExpiry = "expiry"
+
+// Preferences — настройки напоминаний пользователя.
+type Preferences struct {
+	userID     uuid.UUID
+	enabled    bool
+	daysBefore int
+	channel    string
+	email      *string
+	webhookURL *string
+}
+
+func NewPreferences(userID uuid.UUID, enabled bool, daysBefore int, channel string, email, webhookURL *string) (*Preferences, error) {
+	if daysBefore < 1 || daysBefore > MaxDaysBefore {
+		return nil, ErrInvalidDaysBefore
+	}
+
+	switch channel {
+	case ChannelEmail:
+		if email == nil {
+			return nil, ErrInvalidEmail
+		}
+		if a, err := mail.ParseAddress(*email); err != nil || a.Address != *email {
+			return nil, ErrInvalidEmail
+		}
+	case ChannelWebhook:
+		if webhookURL == nil {
+			return nil, ErrInvalidWebhookURL
+		}
+		u, err := url.Parse(*webhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, ErrInvalidWebhookURL
+		}
+		// Как и у webhook-endpoint'ов: не во внутреннюю сеть сервера.
+		if err := netguard.CheckURL(u); err != nil {
+			return nil, err
+		}
+	case ChannelLog:
+	default:
+		return nil, ErrUnknownChannel
+	}
+
+	return RestorePreferences(userID, enabled, daysBefore, channel, email, webhookURL), nil
+}
+
+// RestorePreferences восстанавливает настройки из хранилища без проверок.
+func RestorePreferences(userID uuid.UUID, enabled bool, daysBefore int, channel string, email, webhookURL *string) *Preferences {
+	return &Preferences{
+		userID:     userID,
+		enabled:    enabled,
+		daysBefore: daysBefore,
+		channel:    channel,
+		email:      email,
+		webhookURL: webhookURL,
+	}
+}
+
+func (p *Preferences) UserID() uuid.UUID {
+	return p.userID
+}
+func (p *Preferences) Enabled() bool {
+	return p.enabled
+}
+func (p *Preferences) DaysBefore() int {
+	return p.daysBefore
+}
+func (p *Preferences) Channel() string {
+	return p.channel
+}
+func (p *Preferences) Email() *string {
+	return p.email
+}
+func (p *Preferences) WebhookURL() *string {
+	return p.webhookURL
+}
+
+// Recipient — адрес для канала настроек: email, URL или пусто для log.
+func (p *Preferences) Recipient() string {
+	switch {
+	case p.channel == ChannelEmail && p.email != nil:
+		return *p.email
+	case p.channel == ChannelWebhook && p.webhookURL != nil:
+		return *p.webhookURL
+	}
+	return ""
+}
+
+// EndsAt возвращает момент окончания подписки с последним месяцем
+// endMonth: доступ сохраняется до конца этого месяца.
+func EndsAt(endMonth time.Time) time.Time {
+	return time.Date(endMonth.Year(), endMonth.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Due сообщает, пора ли в now напоминать о событии в момент at за
+// daysBefore дней, и сколько полных или неполных дней осталось.
+func Due(now, at time.Time, daysBefore int) (bool, int) {
+	left := at.Sub(now)
+	if left <= 0 || left > time.Duration(daysBefore)*24*time.Hour {
+		return false, 0
+	}
+	days := int((left + 24*time.Hour - 1) / (24 * time.Hour))
+	return true, days
+}
+
+// Notification — запись журнала отправленных напоминаний. ID выводится из
+// подписки, вида и даты события, поэтому одно напоминание не уходит
+// дважды ни после перезапуска, ни с нескольких экземпляров.
+type Notification struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	SubscriptionID uuid.UUID
+	Kind           string
+	DueAt          time.Time
+	Channel        string
+}
+
+// notificationNamespace — пространство имён UUIDv5 для ID напоминаний.
+var notificationNamespace = uuid.MustParse("3e8a1f0c-6b2d-4c9e-8f7a-5d4c3b2a1e0f")
+
+func NewNotification(userID, subscriptionID uuid.UUID, kind string, dueAt time.Time, channel string) Notification {
+	key := subscriptionID.String() + "/" + kind + "/" + dueAt.UTC().Format(time.DateOnly)
+	return Notification{
+		ID:             uuid.NewSHA1(notificationNamespace, []byte(key)),
+		UserID:         userID,
+		SubscriptionID: subscriptionID,
+		Kind:           kind,
+		DueAt:          dueAt.UTC(),
+		Channel:        channel,
+	}
+}
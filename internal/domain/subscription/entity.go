@@ -23,6 +23,13 @@ type Subscription struct {
 	userId      uuid.UUID
 	startDate   SubDate
 	endDate     *SubDate
+	// serviceID — сервис каталога, nil — сервис задан только именем.
+	serviceID *uuid.UUID
+	// tags и metadata — метки пользователя, см. SetLabels.
+	tags     []string
+	metadata map[string]any
+	// share — доля пользователя фильтра Sum в общей подписке, см. Share.
+	share *int
 }
 
 type Price int
@@ -117,6 +124,27 @@ func NewSubscription(
 	}, nil
 }
 
+// RestoreSubscription восстанавливает подписку из хранилища. В отличие от
+// NewSubscription не проверяет, что даты не в прошлом: сохранённая ранее
+// подписка остаётся валидной и после того, как её месяц начала прошёл.
+func RestoreSubscription(
+	id uuid.UUID,
+	serviceName string,
+	price Price,
+	userId uuid.UUID,
+	startDate SubDate,
+	endDate *SubDate,
+) *Subscription {
+	return &Subscription{
+		id:          id,
+		serviceName: serviceName,
+		price:       price,
+		userId:      userId,
+		startDate:   startDate,
+		endDate:     endDate,
+	}
+}
+
 // ------------------- Getters ------------------
 
 func (s *Subscription) ID() uuid.UUID {
@@ -144,6 +172,20 @@ func (s *Subscription) EndDate() *time.Time {
 	endDateTime := s.endDate.Time
 	return &endDateTime
 }
+func (s *Subscription) ServiceID() *uuid.UUID {
+	return s.serviceID
+}
+
+// LinkService привязывает подписку к сервису каталога id. Непустое name
+// заменяет service_name каноническим именем сервиса; id == nil отвязывает
+// подписку, оставляя имя.
+func (s *Subscription) LinkService(id *uuid.UUID, name string) {
+	s.serviceID = id
+	if name != "" {
+		s.serviceName = name
+	}
+}
+
 func (s *Subscription) EndDateStr() *string {
 	if s.endDate == nil {
 		return nil
@@ -159,6 +201,7 @@ type SubscriptionFilter struct {
 	ServiceName *string
 	StartDate   *SubDate
 	EndDate     *SubDate
+	Labels      LabelFilter
 	Limit       int
 	Offset      int
 }
@@ -230,3 +273,12 @@ func NewPagingBase(limit, offset int) *PagingBase {
 		Offset: offset,
 	}
 }
+
+// ActiveIn сообщает, оплачивается ли подписка в месяце month (первое
+// число месяца, UTC): месяц не раньше start_date и не позже end_date.
+func (s *Subscription) ActiveIn(month time.Time) bool {
+	if s.startDate.After(month) {
+		return false
+	}
+	return s.endDate == nil || !s.endDate.Before(month)
+}
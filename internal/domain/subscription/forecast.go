@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+var (
+	ErrInvalidForecastMonths = errors.New("months must be between 1 and 60")
+	ErrInvalidForecastGroup  = errors.New("group_by must be user or service")
+)
+
+// MaxForecastMonths — самый длинный прогноз.
+const MaxForecastMonths = 60
+
+// ForecastGroup — разбивка прогноза на ряды.
+type ForecastGroup string
+
+const (
+	// GroupNone — только общий ряд.
+	GroupNone    ForecastGroup = ""
+	GroupUser    ForecastGroup = "user"
+	GroupService ForecastGroup = "service"
+)
+
+// ForecastPoint — траты за месяц Month (первое число, UTC).
+type ForecastPoint struct {
+	Month  time.Time
+	Amount int
+}
+
+// ForecastSeries — ряд одного пользователя или сервиса: Key — user_id
+// или service_name.
+type ForecastSeries struct {
+	Key    string
+	Total  int
+	Points []ForecastPoint
+}
+
+// Forecast — помесячный прогноз трат начиная с From.
+type Forecast struct {
+	From   time.Time
+	Months int
+	Group  ForecastGroup
+	Total  ForecastSeries
+	Series []ForecastSeries
+}
+
+// ChargeIn возвращает сумму, которую подписка спишет в месяце month
+// (первое число, UTC). Подписка оплачивается помесячно по текущей цене,
+// пока действует; пробных периодов, запланированных изменений цены и
+// других расчётных периодов в модели нет.
+func (s *Subscription) ChargeIn(month time.Time) int {
+	if !s.ActiveIn(month) {
+		return 0
+	}
+	return s.Amount()
+}
+
+// NewForecast считает прогноз трат подписок subs на months месяцев,
+// начиная с месяца from. Ряды group отсортированы по ключу и содержат
+// все месяцы, в том числе нулевые.
+func NewForecast(subs []*Subscription, from time.Time, months int, group ForecastGroup) (*Forecast, error) {
+	if months < 1 || months > MaxForecastMonths {
+		return nil, ErrInvalidForecastMonths
+	}
+
+	var key func(*Subscription) string
+	switch group {
+	case GroupNone:
+	case GroupUser:
+		key = func(s *Subscription) string { return s.UserID().String() }
+	case GroupService:
+		key = func(s *Subscription) string { return s.ServiceName() }
+	default:
+		return nil, ErrInvalidForecastGroup
+	}
+
+	from = from.UTC()
+	from = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	f := &Forecast{From: from, Months: months, Group: group, Total: newSeries("", from, months)}
+	byKey := make(map[string]*ForecastSeries)
+
+	for _, s := range subs {
+		var series *ForecastSeries
+		if key != nil {
+			k := key(s)
+			if series = byKey[k]; series == nil {
+				ns := newSeries(k, from, months)
+				series = &ns
+				byKey[k] = series
+			}
+		}
+
+		for i := range f.Total.Points {
+			amount := s.ChargeIn(f.Total.Points[i].Month)
+			if amount == 0 {
+				continue
+			}
+			f.Total.add(i, amount)
+			if series != nil {
+				series.add(i, amount)
+			}
+		}
+	}
+
+	for _, s := range byKey {
+		f.Series = append(f.Series, *s)
+	}
+	sort.Slice(f.Series, func(i, j int) bool { return f.Series[i].Key < f.Series[j].Key })
+
+	return f, nil
+}
+
+func newSeries(key string, from time.Time, months int) ForecastSeries {
+	s := ForecastSeries{Key: key, Points: make([]ForecastPoint, months)}
+	for i := range s.Points {
+		s.Points[i].Month = from.AddDate(0, i, 0)
+	}
+	return s
+}
+
+func (s *ForecastSeries) add(i, amount int) {
+	s.Points[i].Amount += amount
+	s.Total += amount
+}
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	forecastUserA = uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+	forecastUserB = uuid.MustParse("7a1c2e3f-4b5d-4e6f-8a9b-0c1d2e3f4a5b")
+)
+
+func month(year int, m time.Month) *SubDate {
+	return &SubDate{time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func amounts(s ForecastSeries) []int {
+	res := make([]int, len(s.Points))
+	for i, p := range s.Points {
+		res[i] = p.Amount
+	}
+	return res
+}
+
+func assertAmounts(t *testing.T, name string, s ForecastSeries, want ...int) {
+	t.Helper()
+	got := amounts(s)
+	if len(got) != len(want) {
+		t.Fatalf("%s: %d points, want %d", name, len(got), len(want))
+	}
+	total := 0
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s: got %v, want %v", name, got, want)
+		}
+		total += want[i]
+	}
+	if s.Total != total {
+		t.Fatalf("%s: total %d, want %d", name, s.Total, total)
+	}
+}
+
+func TestForecast(t *testing.T) {
+	subs := []*Subscription{
+		// Действует весь прогноз.
+		RestoreSubscription(uuid.New(), "Netflix", 400, forecastUserA, *month(2025, time.January), nil),
+		// Заканчивается в марте: март ещё оплачивается.
+		RestoreSubscription(uuid.New(), "Spotify", 200, forecastUserA, *month(2025, time.January), month(2026, time.March)),
+		// Начинается в апреле.
+		RestoreSubscription(uuid.New(), "Netflix", 1000, forecastUserB, *month(2026, time.April), nil),
+		// Закончилась до начала прогноза.
+		RestoreSubscription(uuid.New(), "Kinopoisk", 300, forecastUserB, *month(2025, time.January), month(2025, time.December)),
+	}
+	// Середина месяца в другой зоне: прогноз начинается с января по UTC.
+	from := time.Date(2026, time.January, 15, 3, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	f, err := NewForecast(subs, from, 5, GroupNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.From.Equal(month(2026, time.January).Time) || f.Months != 5 {
+		t.Fatalf("from %v, months %d", f.From, f.Months)
+	}
+	assertAmounts(t, "total", f.Total, 600, 600, 600, 1400, 1400)
+	if len(f.Series) != 0 {
+		t.Fatalf("ungrouped forecast has %d series", len(f.Series))
+	}
+	if got := f.Total.Points[4].Month; !got.Equal(month(2026, time.May).Time) {
+		t.Fatalf("last month %v, want 2026-05", got)
+	}
+
+	f, err = NewForecast(subs, from, 5, GroupService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Series) != 3 {
+		t.Fatalf("%d service series, want 3", len(f.Series))
+	}
+	// Ряд сервиса без трат в прогнозе всё равно возвращается.
+	assertAmounts(t, "Kinopoisk", f.Series[0], 0, 0, 0, 0, 0)
+	assertAmounts(t, "Netflix", f.Series[1], 400, 400, 400, 1400, 1400)
+	assertAmounts(t, "Spotify", f.Series[2], 200, 200, 200, 0, 0)
+
+	f, err = NewForecast(subs, from, 5, GroupUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Series) != 2 || f.Series[0].Key != forecastUserA.String() {
+		t.Fatalf("user series: %+v", f.Series)
+	}
+	assertAmounts(t, "user A", f.Series[0], 600, 600, 600, 400, 400)
+	assertAmounts(t, "user B", f.Series[1], 0, 0, 0, 1000, 1000)
+}
+
+func TestForecastEmpty(t *testing.T) {
+	f, err := NewForecast(nil, time.Now(), 3, GroupUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertAmounts(t, "total", f.Total, 0, 0, 0)
+	if len(f.Series) != 0 {
+		t.Fatalf("%d series without subscriptions", len(f.Series))
+	}
+}
+
+func TestForecastInvalid(t *testing.T) {
+	for _, months := range []int{0, -1, MaxForecastMonths + 1} {
+		if _, err := NewForecast(nil, time.Now(), months, GroupNone); !errors.Is(err, ErrInvalidForecastMonths) {
+			t.Errorf("months %d: got %v, want ErrInvalidForecastMonths", months, err)
+		}
+	}
+	if _, err := NewForecast(nil, time.Now(), 12, "category"); !errors.Is(err, ErrInvalidForecastGroup) {
+		t.Errorf("unknown group: got %v, want ErrInvalidForecastGroup", err)
+	}
+}
@@ -0,0 +1,237 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidTag         = errors.New("tag must be 1-50 letters, digits, '-' or '_' starting with a letter or digit")
+	ErrTooManyTags        = errors.New("too many tags")
+	ErrInvalidMetadata    = errors.New("invalid metadata")
+	ErrInvalidTagMatch    = errors.New("tag_match must be any or all")
+	ErrInvalidMetadataArg = errors.New("metadata filter must be key or key:value")
+)
+
+const (
+	MaxTags         = 20
+	MaxMetadataKeys = 50
+	// MaxMetadataSize — предел metadata в байтах JSON.
+	MaxMetadataSize = 4096
+)
+
+var (
+	tagRe         = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}_-]{0,49}$`)
+	metadataKeyRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,50}$`)
+)
+
+// NormalizeTags приводит теги к нижнему регистру без пробелов по краям,
+// убирает повторы и сортирует. Пустой список — nil.
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(tags))
+	res := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !tagRe.MatchString(t) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTag, t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			res = append(res, t)
+		}
+	}
+	if len(res) > MaxTags {
+		return nil, fmt.Errorf("%w: at most %d", ErrTooManyTags, MaxTags)
+	}
+
+	sort.Strings(res)
+	return res, nil
+}
+
+// ValidateMetadata проверяет ключи metadata и её размер. Значения —
+// любые значения JSON.
+func ValidateMetadata(m map[string]any) error {
+	if len(m) > MaxMetadataKeys {
+		return fmt.Errorf("%w: at most %d keys", ErrInvalidMetadata, MaxMetadataKeys)
+	}
+	for k := range m {
+		if !metadataKeyRe.MatchString(k) {
+			return fmt.Errorf("%w: key %q must be 1-50 latin letters, digits, '-' or '_'", ErrInvalidMetadata, k)
+		}
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+	}
+	if len(b) > MaxMetadataSize {
+		return fmt.Errorf("%w: larger than %d bytes", ErrInvalidMetadata, MaxMetadataSize)
+	}
+	return nil
+}
+
+func (s *Subscription) Tags() []string {
+	return s.tags
+}
+
+func (s *Subscription) Metadata() map[string]any {
+	return s.metadata
+}
+
+// SetLabels проверяет и задаёт теги и metadata подписки.
+func (s *Subscription) SetLabels(tags []string, metadata map[string]any) error {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if err := ValidateMetadata(metadata); err != nil {
+		return err
+	}
+	s.RestoreLabels(tags, metadata)
+	return nil
+}
+
+// RestoreLabels задаёт теги и metadata из хранилища без проверки.
+// Пустые значения хранятся как nil.
+func (s *Subscription) RestoreLabels(tags []string, metadata map[string]any) {
+	if len(tags) == 0 {
+		tags = nil
+	}
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+	s.tags, s.metadata = tags, metadata
+}
+
+// TagMatch — как теги фильтра сравниваются с тегами подписки.
+type TagMatch string
+
+const (
+	// TagMatchAny — есть хотя бы один из тегов.
+	TagMatchAny TagMatch = "any"
+	// TagMatchAll — есть все теги.
+	TagMatchAll TagMatch = "all"
+)
+
+// MetadataCond — условие на ключ metadata: ключ есть, а с Value — его
+// значение строка, равная *Value.
+type MetadataCond struct {
+	Key   string
+	Value *string
+}
+
+// LabelFilter отбирает подписки по тегам и metadata. Нулевое значение
+// ничего не отбрасывает.
+type LabelFilter struct {
+	Tags     []string
+	TagMatch TagMatch
+	Metadata []MetadataCond
+}
+
+// NewLabelFilter разбирает параметры запроса: теги, tag_match (any по
+// умолчанию) и условия metadata вида key или key:value. Условия
+// сортируются, чтобы одинаковые фильтры давали одинаковый ключ кэша.
+func NewLabelFilter(tags []string, match *string, metadata []string) (LabelFilter, error) {
+	var f LabelFilter
+
+	// Теги фильтра нормализуются как теги подписки, но их число не
+	// ограничено MaxTags.
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !tagRe.MatchString(t) {
+			return LabelFilter{}, fmt.Errorf("%w: %q", ErrInvalidTag, t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			f.Tags = append(f.Tags, t)
+		}
+	}
+	sort.Strings(f.Tags)
+
+	f.TagMatch = TagMatchAny
+	if match != nil {
+		switch TagMatch(*match) {
+		case TagMatchAny, TagMatchAll:
+			f.TagMatch = TagMatch(*match)
+		default:
+			return LabelFilter{}, ErrInvalidTagMatch
+		}
+	}
+
+	for _, arg := range metadata {
+		key, value, hasValue := strings.Cut(arg, ":")
+		if !metadataKeyRe.MatchString(key) {
+			return LabelFilter{}, fmt.Errorf("%w: %q", ErrInvalidMetadataArg, arg)
+		}
+		cond := MetadataCond{Key: key}
+		if hasValue {
+			cond.Value = &value
+		}
+		f.Metadata = append(f.Metadata, cond)
+	}
+	sort.Slice(f.Metadata, func(i, j int) bool {
+		a, b := f.Metadata[i], f.Metadata[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.Value == nil || b.Value == nil {
+			return a.Value == nil && b.Value != nil
+		}
+		return *a.Value < *b.Value
+	})
+
+	return f, nil
+}
+
+// Empty сообщает, что фильтр ничего не отбрасывает.
+func (f LabelFilter) Empty() bool {
+	return len(f.Tags) == 0 && len(f.Metadata) == 0
+}
+
+// Match сообщает, подходят ли под фильтр теги и metadata подписки.
+// Повторяет условия SQL-реализаций: значение metadata сравнивается,
+// только если это строка JSON.
+func (f LabelFilter) Match(tags []string, metadata map[string]any) bool {
+	if len(f.Tags) > 0 {
+		has := make(map[string]bool, len(tags))
+		for _, t := range tags {
+			has[t] = true
+		}
+		found := 0
+		for _, t := range f.Tags {
+			if has[t] {
+				found++
+			}
+		}
+		if found == 0 || (f.TagMatch == TagMatchAll && found < len(f.Tags)) {
+			return false
+		}
+	}
+
+	for _, c := range f.Metadata {
+		v, ok := metadata[c.Key]
+		if !ok {
+			return false
+		}
+		if c.Value != nil {
+			if s, isString := v.(string); !isString || s != *c.Value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MatchSubscription — Match по тегам и metadata подписки s.
+func (f LabelFilter) MatchSubscription(s *Subscription) bool {
+	return f.Match(s.tags, s.metadata)
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := NormalizeTags([]string{" Work", "tax-deductible", "work", "Семья"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"tax-deductible", "work", "семья"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("NormalizeTags = %v, want %v", got, want)
+	}
+
+	if got, err := NormalizeTags([]string{}); err != nil || got != nil {
+		t.Fatalf("NormalizeTags(empty) = %v, %v, want nil", got, err)
+	}
+	for _, tag := range []string{"", "-work", "two words", strings.Repeat("a", 51)} {
+		if _, err := NormalizeTags([]string{tag}); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("NormalizeTags(%q): got %v, want ErrInvalidTag", tag, err)
+		}
+	}
+
+	many := make([]string, MaxTags+1)
+	for i := range many {
+		many[i] = strings.Repeat("t", i+1)
+	}
+	if _, err := NormalizeTags(many); !errors.Is(err, ErrTooManyTags) {
+		t.Fatalf("NormalizeTags(%d tags): got %v, want ErrTooManyTags", len(many), err)
+	}
+}
+
+func TestValidateMetadata(t *testing.T) {
+	ok := map[string]any{"reimbursed_by": "company", "share": 0.5, "invoice": map[string]any{"required": true}}
+	if err := ValidateMetadata(ok); err != nil {
+		t.Fatalf("ValidateMetadata: %v", err)
+	}
+
+	bad := []map[string]any{
+		{"": "x"},
+		{"with space": "x"},
+		{"big": strings.Repeat("x", MaxMetadataSize)},
+	}
+	for _, m := range bad {
+		if err := ValidateMetadata(m); !errors.Is(err, ErrInvalidMetadata) {
+			t.Errorf("ValidateMetadata(%.40v): got %v, want ErrInvalidMetadata", m, err)
+		}
+	}
+}
+
+func TestNewLabelFilter(t *testing.T) {
+	f, err := NewLabelFilter([]string{"Work", "family", "work"}, nil, []string{"share", "reimbursed_by:company", "note:a:b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"family", "work"}; !reflect.DeepEqual(f.Tags, want) || f.TagMatch != TagMatchAny {
+		t.Fatalf("tags = %v %s, want %v any", f.Tags, f.TagMatch, want)
+	}
+	keys := make([]string, len(f.Metadata))
+	for i, c := range f.Metadata {
+		keys[i] = c.Key
+	}
+	if want := []string{"note", "reimbursed_by", "share"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("metadata keys = %v, want %v", keys, want)
+	}
+	if f.Metadata[0].Value == nil || *f.Metadata[0].Value != "a:b" || f.Metadata[2].Value != nil {
+		t.Fatalf("metadata values: %+v", f.Metadata)
+	}
+
+	bad := "some"
+	if _, err := NewLabelFilter(nil, &bad, nil); !errors.Is(err, ErrInvalidTagMatch) {
+		t.Fatalf("tag_match %q: got %v, want ErrInvalidTagMatch", bad, err)
+	}
+	if _, err := NewLabelFilter(nil, nil, []string{":company"}); !errors.Is(err, ErrInvalidMetadataArg) {
+		t.Fatalf("metadata without key: got %v, want ErrInvalidMetadataArg", err)
+	}
+	if f, err := NewLabelFilter(nil, nil, nil); err != nil || !f.Empty() {
+		t.Fatalf("empty filter = %+v, %v", f, err)
+	}
+}
+
+func TestLabelFilterMatch(t *testing.T) {
+	tags := []string{"tax-deductible", "work"}
+	metadata := map[string]any{"reimbursed_by": "company", "share": 3.0}
+
+	allMatch, anyMatch := "all", "any"
+	tests := []struct {
+		name     string
+		tags     []string
+		match    *string
+		metadata []string
+		want     bool
+	}{
+		{"empty", nil, nil, nil, true},
+		{"any hit", []string{"family", "work"}, &anyMatch, nil, true},
+		{"any miss", []string{"family"}, &anyMatch, nil, false},
+		{"all hit", []string{"work", "tax-deductible"}, &allMatch, nil, true},
+		{"all miss", []string{"work", "family"}, &allMatch, nil, false},
+		{"key", nil, nil, []string{"share"}, true},
+		{"missing key", nil, nil, []string{"invoice"}, false},
+		{"value", nil, nil, []string{"reimbursed_by:company"}, true},
+		{"other value", nil, nil, []string{"reimbursed_by:me"}, false},
+		{"non-string value", nil, nil, []string{"share:3"}, false},
+		{"tags and metadata", []string{"work"}, nil, []string{"reimbursed_by:company"}, true},
+	}
+	for _, tc := range tests {
+		f, err := NewLabelFilter(tc.tags, tc.match, tc.metadata)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := f.Match(tags, metadata); got != tc.want {
+			t.Errorf("%s: Match = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidSplitRule  = errors.New("split must be equal, percent or fixed")
+	ErrInvalidMember     = errors.New("invalid subscription member")
+	ErrTooManyMembers    = errors.New("too many subscription members")
+	ErrSplitMismatch     = errors.New("member shares must add up to the subscription price")
+	ErrSharedOwnerChange = errors.New("owner of a shared subscription cannot change, remove members first")
+)
+
+// MaxMembers — предел участников общей подписки вместе с владельцем.
+const MaxMembers = 20
+
+// SplitRule — как цена общей подписки делится между участниками.
+type SplitRule string
+
+const (
+	// SplitEqual — поровну.
+	SplitEqual SplitRule = "equal"
+	// SplitPercent — по процентам участников.
+	SplitPercent SplitRule = "percent"
+	// SplitFixed — по фиксированным суммам участников.
+	SplitFixed SplitRule = "fixed"
+)
+
+// Member — участник общей подписки. Percent задаётся для SplitPercent
+// (до сотых долей процента), Amount — для SplitFixed. Share — доля цены,
+// которую платит участник.
+type Member struct {
+	UserID  uuid.UUID
+	Percent *float64
+	Amount  *int
+	Share   int
+}
+
+// Split — разделение цены подписки. Владелец подписки всегда участник и
+// идёт первым: он платит то, что не распределено между остальными, в том
+// числе остаток от округления. Его Percent и Amount не хранятся — они
+// следуют из остальных.
+type Split struct {
+	Rule    SplitRule
+	Members []Member
+}
+
+// NewSplit проверяет разделение цены price подписки владельца owner и
+// считает доли. Владельца можно не перечислять в members; если он указан
+// с процентом или суммой, доли должны сойтись с ценой точно.
+func NewSplit(rule SplitRule, owner uuid.UUID, price int, members []Member) (*Split, error) {
+	switch rule {
+	case SplitEqual, SplitPercent, SplitFixed:
+	default:
+		return nil, ErrInvalidSplitRule
+	}
+
+	s := &Split{Rule: rule, Members: []Member{{UserID: owner}}}
+	var ownerValue *Member
+
+	seen := map[uuid.UUID]bool{}
+	for _, m := range members {
+		if m.UserID == uuid.Nil {
+			return nil, fmt.Errorf("%w: user_id is required", ErrInvalidMember)
+		}
+		if seen[m.UserID] {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrInvalidMember, m.UserID)
+		}
+		seen[m.UserID] = true
+
+		if err := checkMemberValue(rule, m, m.UserID == owner); err != nil {
+			return nil, err
+		}
+		if m.UserID == owner {
+			if m.Percent != nil || m.Amount != nil {
+				ownerValue = &m
+			}
+			continue
+		}
+		s.Members = append(s.Members, Member{UserID: m.UserID, Percent: m.Percent, Amount: m.Amount})
+	}
+
+	if len(s.Members) < 2 {
+		return nil, fmt.Errorf("%w: at least one member besides the owner", ErrInvalidMember)
+	}
+	if len(s.Members) > MaxMembers {
+		return nil, fmt.Errorf("%w: at most %d", ErrTooManyMembers, MaxMembers)
+	}
+	sort.Slice(s.Members[1:], func(i, j int) bool {
+		return s.Members[i+1].UserID.String() < s.Members[j+1].UserID.String()
+	})
+
+	if err := s.share(price); err != nil {
+		return nil, err
+	}
+
+	// Явная доля владельца должна совпасть с остатком.
+	if ownerValue != nil {
+		if (ownerValue.Percent != nil && basisPoints(*ownerValue.Percent) != s.ownerBasisPoints()) ||
+			(ownerValue.Amount != nil && *ownerValue.Amount != s.Members[0].Share) {
+			return nil, ErrSplitMismatch
+		}
+	}
+
+	return s, nil
+}
+
+// checkMemberValue проверяет, что у участника задано ровно то, что
+// требует правило. Владелец может не задавать ничего.
+func checkMemberValue(rule SplitRule, m Member, owner bool) error {
+	switch rule {
+	case SplitEqual:
+		if m.Percent != nil || m.Amount != nil {
+			return fmt.Errorf("%w: equal split takes neither percent nor amount", ErrInvalidMember)
+		}
+	case SplitPercent:
+		if m.Amount != nil || (m.Percent == nil && !owner) {
+			return fmt.Errorf("%w: percent split needs percent of every member", ErrInvalidMember)
+		}
+		if p := m.Percent; p != nil && (*p < 0 || *p > 100 || (*p == 0 && !owner) || !isCents(*p)) {
+			return fmt.Errorf("%w: percent must be in (0, 100] with at most two decimals", ErrInvalidMember)
+		}
+	case SplitFixed:
+		if m.Percent != nil || (m.Amount == nil && !owner) {
+			return fmt.Errorf("%w: fixed split needs amount of every member", ErrInvalidMember)
+		}
+		if a := m.Amount; a != nil && (*a < 0 || (*a == 0 && !owner)) {
+			return fmt.Errorf("%w: amount must be positive", ErrInvalidMember)
+		}
+	}
+	return nil
+}
+
+func isCents(p float64) bool {
+	return math.Abs(p*100-math.Round(p*100)) < 1e-6
+}
+
+// basisPoints переводит процент в сотые доли процента.
+func basisPoints(p float64) int {
+	return int(math.Round(p * 100))
+}
+
+func (s *Split) ownerBasisPoints() int {
+	bp := 10000
+	for _, m := range s.Members[1:] {
+		bp -= basisPoints(*m.Percent)
+	}
+	return bp
+}
+
+// share считает доли участников от price. Округление в пользу
+// участников: их доли округляются вниз, остаток платит владелец, а при
+// делении поровну лишние единицы достаются первым участникам.
+func (s *Split) share(price int) error {
+	others := s.Members[1:]
+	switch s.Rule {
+	case SplitEqual:
+		n := len(s.Members)
+		for i := range s.Members {
+			s.Members[i].Share = price / n
+			if i < price%n {
+				s.Members[i].Share++
+			}
+		}
+		return nil
+
+	case SplitPercent:
+		if s.ownerBasisPoints() < 0 {
+			return ErrSplitMismatch
+		}
+		for i := range others {
+			others[i].Share = price * basisPoints(*others[i].Percent) / 10000
+		}
+
+	case SplitFixed:
+		for i := range others {
+			others[i].Share = *others[i].Amount
+		}
+	}
+
+	rest := price
+	for _, m := range others {
+		rest -= m.Share
+	}
+	if rest < 0 {
+		return ErrSplitMismatch
+	}
+	s.Members[0].Share = rest
+	return nil
+}
+
+// Reprice пересчитывает доли для изменённой подписки. Владелец общей
+// подписки не меняется: его доля — остаток, и с другим владельцем
+// разделение потеряло бы смысл. Фиксированные суммы участников
+// сохраняются и должны уместиться в новую цену.
+func (s *Split) Reprice(owner uuid.UUID, price int) (*Split, error) {
+	if s.Members[0].UserID != owner {
+		return nil, ErrSharedOwnerChange
+	}
+	res := &Split{Rule: s.Rule, Members: append([]Member(nil), s.Members...)}
+	if err := res.share(price); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Without возвращает разделение без участника userID. Его доля переходит
+// к владельцу (при делении поровну — ко всем). Если кроме владельца никого
+// не осталось, возвращает nil: подписка больше не общая.
+func (s *Split) Without(userID uuid.UUID, price int) (*Split, bool) {
+	if s.Members[0].UserID == userID {
+		return s, false
+	}
+
+	res := &Split{Rule: s.Rule}
+	found := false
+	for _, m := range s.Members {
+		if m.UserID == userID {
+			found = true
+			continue
+		}
+		res.Members = append(res.Members, m)
+	}
+	if !found {
+		return s, false
+	}
+	if len(res.Members) < 2 {
+		return nil, true
+	}
+	// Доли оставшихся не растут, поэтому пересчёт не может не сойтись.
+	_ = res.share(price)
+	return res, true
+}
+
+// Owner возвращает владельца подписки.
+func (s *Split) Owner() uuid.UUID {
+	return s.Members[0].UserID
+}
+
+// ShareOf возвращает долю участника userID.
+func (s *Split) ShareOf(userID uuid.UUID) (int, bool) {
+	for _, m := range s.Members {
+		if m.UserID == userID {
+			return m.Share, true
+		}
+	}
+	return 0, false
+}
+
+// UserIDs возвращает участников, владелец первым.
+func (s *Split) UserIDs() []uuid.UUID {
+	res := make([]uuid.UUID, 0, len(s.Members))
+	for _, m := range s.Members {
+		res = append(res, m.UserID)
+	}
+	return res
+}
+
+// RestoreSplit собирает разделение из хранилища без проверки: владелец
+// owner ставится первым, правило определяется по заданным процентам или
+// суммам участников.
+func RestoreSplit(owner uuid.UUID, members []Member) *Split {
+	if len(members) == 0 {
+		return nil
+	}
+
+	s := &Split{Rule: SplitEqual}
+	for _, m := range members {
+		switch {
+		case m.Percent != nil:
+			s.Rule = SplitPercent
+		case m.Amount != nil:
+			s.Rule = SplitFixed
+		}
+		if m.UserID == owner {
+			s.Members = append([]Member{m}, s.Members...)
+		} else {
+			s.Members = append(s.Members, m)
+		}
+	}
+	sort.Slice(s.Members[1:], func(i, j int) bool {
+		return s.Members[i+1].UserID.String() < s.Members[j+1].UserID.String()
+	})
+	return s
+}
+
+// Share возвращает долю пользователя фильтра Sum в общей подписке, nil —
+// подписка не общая или прочитана без фильтра по пользователю.
+func (s *Subscription) Share() *int {
+	return s.share
+}
+
+// Amount — сколько подписка стоит пользователю фильтра: его доля в общей
+// подписке, иначе цена.
+func (s *Subscription) Amount() int {
+	if s.share != nil {
+		return *s.share
+	}
+	return s.Price()
+}
+
+// RestoreShare задаёт долю пользователя фильтра из хранилища.
+func (s *Subscription) RestoreShare(share *int) {
+	s.share = share
+}
+
+// WithShare возвращает копию подписки с долей share.
+func (s *Subscription) WithShare(share int) *Subscription {
+	c := *s
+	c.share = &share
+	return &c
+}
@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var (
+	owner = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	mate1 = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	mate2 = uuid.MustParse("33333333-3333-3333-3333-333333333333")
+)
+
+func pct(p float64) *float64 { return &p }
+
+func amt(a int) *int { return &a }
+
+func shares(s *Split) []int {
+	res := make([]int, 0, len(s.Members))
+	for _, m := range s.Members {
+		res = append(res, m.Share)
+	}
+	return res
+}
+
+func TestNewSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    SplitRule
+		price   int
+		members []Member
+		want    []int
+	}{
+		// Остаток от деления достаётся первым, владелец первый.
+		{"equal remainder", SplitEqual, 1000, []Member{{UserID: mate2}, {UserID: mate1}}, []int{334, 333, 333}},
+		{"equal with owner listed", SplitEqual, 100, []Member{{UserID: owner}, {UserID: mate1}}, []int{50, 50}},
+		// Доли участников округляются вниз, владелец платит остаток.
+		{"percent rounding", SplitPercent, 999, []Member{{UserID: mate1, Percent: pct(33.33)}, {UserID: mate2, Percent: pct(33.33)}}, []int{335, 332, 332}},
+		{"percent owner exact", SplitPercent, 400, []Member{{UserID: owner, Percent: pct(50)}, {UserID: mate1, Percent: pct(50)}}, []int{200, 200}},
+		{"fixed", SplitFixed, 1000, []Member{{UserID: mate1, Amount: amt(300)}, {UserID: mate2, Amount: amt(200)}}, []int{500, 300, 200}},
+		{"fixed owner pays nothing", SplitFixed, 500, []Member{{UserID: mate1, Amount: amt(500)}}, []int{0, 500}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewSplit(tc.rule, owner, tc.price, tc.members)
+			if err != nil {
+				t.Fatalf("NewSplit: %v", err)
+			}
+			if s.Owner() != owner {
+				t.Fatalf("owner = %s, want %s", s.Owner(), owner)
+			}
+			if got := shares(s); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("shares = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewSplitInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    SplitRule
+		members []Member
+		want    error
+	}{
+		{"unknown rule", "weighted", []Member{{UserID: mate1}}, ErrInvalidSplitRule},
+		{"owner only", SplitEqual, []Member{{UserID: owner}}, ErrInvalidMember},
+		{"no user", SplitEqual, []Member{{}}, ErrInvalidMember},
+		{"duplicate", SplitEqual, []Member{{UserID: mate1}, {UserID: mate1}}, ErrInvalidMember},
+		{"equal with percent", SplitEqual, []Member{{UserID: mate1, Percent: pct(50)}}, ErrInvalidMember},
+		{"percent missing", SplitPercent, []Member{{UserID: mate1}}, ErrInvalidMember},
+		{"percent fraction", SplitPercent, []Member{{UserID: mate1, Percent: pct(10.005)}}, ErrInvalidMember},
+		{"percent over", SplitPercent, []Member{{UserID: mate1, Percent: pct(60)}, {UserID: mate2, Percent: pct(50)}}, ErrSplitMismatch},
+		{"percent owner mismatch", SplitPercent, []Member{{UserID: owner, Percent: pct(40)}, {UserID: mate1, Percent: pct(50)}}, ErrSplitMismatch},
+		{"fixed zero", SplitFixed, []Member{{UserID: mate1, Amount: amt(0)}}, ErrInvalidMember},
+		{"fixed overflow", SplitFixed, []Member{{UserID: mate1, Amount: amt(600)}, {UserID: mate2, Amount: amt(500)}}, ErrSplitMismatch},
+		{"fixed owner mismatch", SplitFixed, []Member{{UserID: owner, Amount: amt(100)}, {UserID: mate1, Amount: amt(500)}}, ErrSplitMismatch},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewSplit(tc.rule, owner, 1000, tc.members); !errors.Is(err, tc.want) {
+				t.Fatalf("NewSplit: got %v, want %v", err, tc.want)
+			}
+		})
+	}
+
+	many := make([]Member, MaxMembers)
+	for i := range many {
+		many[i] = Member{UserID: uuid.New()}
+	}
+	if _, err := NewSplit(SplitEqual, owner, 1000, many); !errors.Is(err, ErrTooManyMembers) {
+		t.Fatalf("NewSplit(%d members): got %v, want ErrTooManyMembers", len(many)+1, err)
+	}
+}
+
+func TestSplitReprice(t *testing.T) {
+	s, err := NewSplit(SplitFixed, owner, 1000, []Member{{UserID: mate1, Amount: amt(300)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Reprice(owner, 1200)
+	if err != nil {
+		t.Fatalf("Reprice: %v", err)
+	}
+	if want := []int{900, 300}; !reflect.DeepEqual(shares(got), want) {
+		t.Fatalf("shares = %v, want %v", shares(got), want)
+	}
+	if want := []int{700, 300}; !reflect.DeepEqual(shares(s), want) {
+		t.Fatalf("Reprice changed the original: %v, want %v", shares(s), want)
+	}
+
+	if _, err := s.Reprice(owner, 200); !errors.Is(err, ErrSplitMismatch) {
+		t.Fatalf("Reprice below fixed amounts: got %v, want ErrSplitMismatch", err)
+	}
+	if _, err := s.Reprice(mate1, 1000); !errors.Is(err, ErrSharedOwnerChange) {
+		t.Fatalf("Reprice with another owner: got %v, want ErrSharedOwnerChange", err)
+	}
+}
+
+func TestSplitWithout(t *testing.T) {
+	s, err := NewSplit(SplitEqual, owner, 900, []Member{{UserID: mate1}, {UserID: mate2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := s.Without(mate1, 900)
+	if !ok {
+		t.Fatal("Without(member) = false")
+	}
+	if want := []int{450, 450}; !reflect.DeepEqual(shares(got), want) {
+		t.Fatalf("shares = %v, want %v", shares(got), want)
+	}
+	if _, ok := got.ShareOf(mate1); ok {
+		t.Fatal("removed member still has a share")
+	}
+
+	if got, ok = got.Without(mate2, 900); !ok || got != nil {
+		t.Fatalf("Without(last member) = %+v, %v, want nil, true", got, ok)
+	}
+	if _, ok := s.Without(owner, 900); ok {
+		t.Fatal("Without(owner) = true")
+	}
+	if _, ok := s.Without(uuid.New(), 900); ok {
+		t.Fatal("Without(stranger) = true")
+	}
+}
+
+func TestRestoreSplit(t *testing.T) {
+	if s := RestoreSplit(owner, nil); s != nil {
+		t.Fatalf("RestoreSplit(no rows) = %+v, want nil", s)
+	}
+
+	s := RestoreSplit(owner, []Member{
+		{UserID: mate2, Percent: pct(25), Share: 250},
+		{UserID: owner, Share: 750},
+	})
+	if s.Rule != SplitPercent || s.Owner() != owner {
+		t.Fatalf("RestoreSplit = %s owned by %s, want percent owned by %s", s.Rule, s.Owner(), owner)
+	}
+	if want := []uuid.UUID{owner, mate2}; !reflect.DeepEqual(s.UserIDs(), want) {
+		t.Fatalf("UserIDs = %v, want %v", s.UserIDs(), want)
+	}
+}
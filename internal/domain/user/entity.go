@@ -0,0 +1,123 @@
+// Package user — пользователи, которым принадлежат подписки: имя для
+// отображения, email, валюта и часовой пояс по умолчанию. user_id
+// подписок и участников общих подписок ссылается на пользователя.
+package user
+
+import (
+	"errors"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	// Часовые пояса проверяются по встроенной базе: в образе может не
+	// быть /usr/share/zoneinfo.
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmptyDisplayName   = errors.New("display name is empty")
+	ErrDisplayNameTooLong = errors.New("display name must be at most 100 characters")
+	ErrInvalidEmail       = errors.New("email must be a valid address of at most 254 characters")
+	ErrInvalidCurrency    = errors.New("currency must be a 3-letter ISO 4217 code")
+	ErrInvalidTimezone    = errors.New("timezone must be an IANA time zone name, e.g. Europe/Moscow")
+	// ErrEmailTaken — email уже у другого пользователя.
+	ErrEmailTaken = errors.New("email already belongs to another user")
+	// ErrUnknownUser — подписка или участник ссылается на отсутствующего
+	// пользователя.
+	ErrUnknownUser = errors.New("unknown user_id")
+	// ErrUserInUse — у пользователя появились подписки во время удаления.
+	ErrUserInUse = errors.New("user still has subscriptions")
+)
+
+const (
+	MaxDisplayNameLength = 100
+	MaxEmailLength       = 254
+	DefaultCurrency      = "RUB"
+	DefaultTimezone      = "UTC"
+)
+
+var currencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// User — пользователь.
+type User struct {
+	id          uuid.UUID
+	displayName string
+	email       *string
+	currency    string
+	timezone    string
+	createdAt   time.Time
+}
+
+// NewUser проверяет поля и собирает пользователя. id == uuid.Nil — новый
+// ID, пустые currency и timezone — DefaultCurrency и DefaultTimezone.
+// Email приводится к нижнему регистру: по нему пользователи уникальны.
+func NewUser(id uuid.UUID, displayName string, email *string, currency, timezone string) (*User, error) {
+	displayName = strings.TrimSpace(displayName)
+	if displayName == "" {
+		return nil, ErrEmptyDisplayName
+	}
+	if len([]rune(displayName)) > MaxDisplayNameLength {
+		return nil, ErrDisplayNameTooLong
+	}
+
+	if email != nil {
+		e := strings.ToLower(strings.TrimSpace(*email))
+		if a, err := mail.ParseAddress(e); err != nil || a.Address != e || len(e) > MaxEmailLength {
+			return nil, ErrInvalidEmail
+		}
+		email = &e
+	}
+
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if !currencyRe.MatchString(currency) {
+		return nil, ErrInvalidCurrency
+	}
+
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+	// LoadLocation принимает и "Local", и пути вида "../x": в базе
+	// храним только имена IANA.
+	if timezone == "Local" || strings.Contains(timezone, "..") {
+		return nil, ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, ErrInvalidTimezone
+	}
+
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+
+	return &User{
+		id:          id,
+		displayName: displayName,
+		email:       email,
+		currency:    currency,
+		timezone:    timezone,
+		createdAt:   time.Now().UTC(),
+	}, nil
+}
+
+// RestoreUser восстанавливает пользователя из хранилища без проверок.
+func RestoreUser(id uuid.UUID, displayName string, email *string, currency, timezone string, createdAt time.Time) *User {
+	return &User{
+		id:          id,
+		displayName: displayName,
+		email:       email,
+		currency:    currency,
+		timezone:    timezone,
+		createdAt:   createdAt,
+	}
+}
+
+func (u *User) ID() uuid.UUID        { return u.id }
+func (u *User) DisplayName() string  { return u.displayName }
+func (u *User) Email() *string       { return u.email }
+func (u *User) Currency() string     { return u.currency }
+func (u *User) Timezone() string     { return u.timezone }
+func (u *User) CreatedAt() time.Time { return u.createdAt }
@@ -0,0 +1,62 @@
+package user
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestNewUser(t *testing.T) {
+	u, err := NewUser(uuid.Nil, " Алиса ", ptr(" Alice@Example.com "), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID() == uuid.Nil || u.DisplayName() != "Алиса" {
+		t.Fatalf("user: id %v, name %q", u.ID(), u.DisplayName())
+	}
+	if u.Email() == nil || *u.Email() != "alice@example.com" {
+		t.Fatalf("email = %v, want alice@example.com", u.Email())
+	}
+	if u.Currency() != DefaultCurrency || u.Timezone() != DefaultTimezone {
+		t.Fatalf("defaults: currency %q, timezone %q", u.Currency(), u.Timezone())
+	}
+
+	u, err = NewUser(uuid.Nil, "Bob", nil, "USD", "Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Email() != nil || u.Currency() != "USD" || u.Timezone() != "Europe/Moscow" {
+		t.Fatalf("user: email %v, currency %q, timezone %q", u.Email(), u.Currency(), u.Timezone())
+	}
+}
+
+func TestNewUserInvalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		display  string
+		email    *string
+		currency string
+		timezone string
+		want     error
+	}{
+		{"empty name", "  ", nil, "", "", ErrEmptyDisplayName},
+		{"long name", strings.Repeat("я", MaxDisplayNameLength+1), nil, "", "", ErrDisplayNameTooLong},
+		{"bad email", "Bob", ptr("bob"), "", "", ErrInvalidEmail},
+		{"email with name", "Bob", ptr("Bob <bob@example.com>"), "", "", ErrInvalidEmail},
+		{"long email", "Bob", ptr(strings.Repeat("b", MaxEmailLength) + "@example.com"), "", "", ErrInvalidEmail},
+		{"lowercase currency", "Bob", nil, "usd", "", ErrInvalidCurrency},
+		{"unknown timezone", "Bob", nil, "", "Mars/Olympus", ErrInvalidTimezone},
+		{"local timezone", "Bob", nil, "", "Local", ErrInvalidTimezone},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewUser(uuid.Nil, tc.display, tc.email, tc.currency, tc.timezone); !errors.Is(err, tc.want) {
+				t.Fatalf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"testingtask/internal/netguard"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidURL       = errors.New("webhook url must be an absolute http or https URL")
+	ErrNoUser           = errors.New("webhook needs a user_id")
+	ErrSecretTooShort   = errors.New("webhook secret must be at least 16 characters")
+	ErrNoEventTypes     = errors.New("webhook needs at least one event type")
+	ErrUnknownEventType = errors.New("unknown event type")
+)
+
+// MinSecretLength — минимальная длина ключа подписи.
+const MinSecretLength = 16
+
+// Endpoint — адрес, на который отправляются события выбранных типов.
+// Endpoint получает только события своего пользователя.
+type Endpoint struct {
+	id uuid.UUID
+	// userID — владелец; uuid.Nil у endpoint'ов, созданных до появления
+	// владельцев: они не получают ничего.
+	userID     uuid.UUID
+	url        string
+	secret     string
+	eventTypes []string
+	createdAt  time.Time
+}
+
+// NewEndpoint проверяет endpoint. URL не должен вести во внутреннюю сеть
+// сервера (netguard.ErrForbiddenTarget).
+func NewEndpoint(id, userID uuid.UUID, rawURL, secret string, eventTypes []string) (*Endpoint, error) {
+	if userID == uuid.Nil {
+		return nil, ErrNoUser
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidURL
+	}
+	if err := netguard.CheckURL(u); err != nil {
+		return nil, err
+	}
+
+	if len(secret) < MinSecretLength {
+		return nil, ErrSecretTooShort
+	}
+
+	if len(eventTypes) == 0 {
+		return nil, ErrNoEventTypes
+	}
+
+	// Повторы в списке ничего не меняют, убираем их с сохранением порядка.
+	seen := make(map[string]bool, len(eventTypes))
+	types := make([]string, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+
+	return &Endpoint{
+		id:         id,
+		userID:     userID,
+		url:        rawURL,
+		secret:     secret,
+		eventTypes: types,
+		createdAt:  time.Now().UTC(),
+	}, nil
+}
+
+// RestoreEndpoint восстанавливает endpoint из хранилища без проверок.
+func RestoreEndpoint(id, userID uuid.UUID, rawURL, secret string, eventTypes []string, createdAt time.Time) *Endpoint {
+	return &Endpoint{
+		id:         id,
+		userID:     userID,
+		url:        rawURL,
+		secret:     secret,
+		eventTypes: eventTypes,
+		createdAt:  createdAt,
+	}
+}
+
+func (e *Endpoint) ID() uuid.UUID {
+	return e.id
+}
+func (e *Endpoint) UserID() uuid.UUID {
+	return e.userID
+}
+func (e *Endpoint) URL() string {
+	return e.url
+}
+func (e *Endpoint) Secret() string {
+	return e.secret
+}
+func (e *Endpoint) EventTypes() []string {
+	return append([]string(nil), e.eventTypes...)
+}
+func (e *Endpoint) CreatedAt() time.Time {
+	return e.createdAt
+}
+
+// Accepts сообщает, подписан ли endpoint на события типа eventType.
+func (e *Endpoint) Accepts(eventType string) bool {
+	for _, t := range e.eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus — состояние доставки события на endpoint.
+type DeliveryStatus string
+
+const (
+	// StatusPending — доставка ждёт первой попытки или повтора.
+	StatusPending DeliveryStatus = "pending"
+	// StatusSucceeded — endpoint ответил 2xx.
+	StatusSucceeded DeliveryStatus = "succeeded"
+	// StatusFailed — попытки исчерпаны, остаётся ручная повторная отправка.
+	StatusFailed DeliveryStatus = "failed"
+)
+
+// Delivery — отправка одного события на один endpoint.
+type Delivery struct {
+	ID            uuid.UUID
+	EndpointID    uuid.UUID
+	EventID       uuid.UUID
+	EventType     string
+	Payload       []byte
+	Status        DeliveryStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+	// Log — попытки отправки по порядку. Заполняется только при выдаче
+	// истории доставок.
+	Log []Attempt
+}
+
+// Attempt — одна попытка отправки.
+type Attempt struct {
+	AttemptedAt time.Time
+	// StatusCode — код ответа, nil если ответа не было.
+	StatusCode *int
+	Error      *string
+	Duration   time.Duration
+}
+
+// Succeeded сообщает, принял ли endpoint событие.
+func (a Attempt) Succeeded() bool {
+	return a.Error == nil
+}
+
+// Заголовки запроса с доставкой. Кроме них отправляются X-Event-ID и
+// X-Event-Type, как у outbox-синка webhook.
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+// Sign возвращает подпись тела запроса в формате заголовка
+// X-Webhook-Signature: sha256=<hex HMAC-SHA256 от "<timestamp>.<body>">.
+// Метка времени входит в подпись, чтобы перехваченный запрос нельзя было
+// повторить позже.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
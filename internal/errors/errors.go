@@ -1,21 +1,34 @@
 package myerrors
 
 import (
+	"context"
 	"errors"
+	"testingtask/internal/domain/analytics"
+	"testingtask/internal/domain/budget"
+	"testingtask/internal/domain/catalog"
+	"testingtask/internal/domain/reminder"
 	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/user"
+	"testingtask/internal/domain/webhook"
+	"testingtask/internal/netguard"
+	"testingtask/internal/requestid"
+	"testingtask/internal/scheduler"
 	"testingtask/internal/web/subscriptions"
 )
 
 type ErrorResponse struct {
-	Error string `json:"error" example:"invalid id format"`
+	Error     string `json:"error" example:"invalid id format"`
+	RequestID string `json:"request_id,omitempty" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
 }
 
 type ErrorNotFound struct {
-	Error string `json:"error" example:"subscription not found"`
+	Error     string `json:"error" example:"subscription not found"`
+	RequestID string `json:"request_id,omitempty" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
 }
 
 type ErrorInternalServerError struct {
-	Error string `json:"error" example:"internal server error"`
+	Error     string `json:"error" example:"internal server error"`
+	RequestID string `json:"request_id,omitempty" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
 }
 
 var (
@@ -24,6 +37,19 @@ var (
 	ErrInvalidData = errors.New("invalid input data")
 	ErrInternal    = errors.New("internal server error")
 
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+	ErrPreferencesNotFound = errors.New("reminder preferences not found")
+
+	ErrBudgetNotFound = errors.New("budget not found")
+
+	ErrServiceNotFound = errors.New("service not found")
+
+	ErrMemberNotFound = errors.New("subscription member not found")
+
+	ErrUserNotFound = errors.New("user not found")
+
 	ErrConflict       = errors.New("conflict")
 	ErrDatabase       = errors.New("database error")
 	ErrContextTimeout = errors.New("context timeout")
@@ -33,7 +59,17 @@ var (
 	ErrDeleteFailed   = errors.New("failed to delete subscription")
 )
 
-func MapError(err error) (subscriptions.ErrorResponse, int) {
+// MapError переводит ошибку в тело ответа и HTTP-код,
+// добавляя request_id из контекста.
+func MapError(ctx context.Context, err error) (subscriptions.ErrorResponse, int) {
+	resp, code := mapError(err)
+	if id := requestid.FromContext(ctx); id != "" {
+		resp.RequestId = &id
+	}
+	return resp, code
+}
+
+func mapError(err error) (subscriptions.ErrorResponse, int) {
 	switch {
 	case errors.Is(err, ErrInvalidID):
 		return subscriptions.ErrorResponse{Error: err.Error()}, 400
@@ -41,16 +77,75 @@ func MapError(err error) (subscriptions.ErrorResponse, int) {
 	case errors.Is(err, ErrInvalidData):
 		return subscriptions.ErrorResponse{Error: err.Error()}, 400
 
-	case errors.Is(err, ErrNotFound):
+	case errors.Is(err, ErrNotFound),
+		errors.Is(err, ErrWebhookNotFound),
+		errors.Is(err, ErrDeliveryNotFound),
+		errors.Is(err, ErrPreferencesNotFound),
+		errors.Is(err, ErrBudgetNotFound),
+		errors.Is(err, ErrServiceNotFound),
+		errors.Is(err, ErrMemberNotFound),
+		errors.Is(err, ErrUserNotFound),
+		errors.Is(err, scheduler.ErrJobNotFound):
 		return subscriptions.ErrorResponse{Error: err.Error()}, 404
 
+	case errors.Is(err, scheduler.ErrJobRunning),
+		errors.Is(err, budget.ErrBudgetExists),
+		errors.Is(err, budget.ErrBudgetExceeded),
+		errors.Is(err, catalog.ErrAliasTaken),
+		errors.Is(err, user.ErrEmailTaken),
+		errors.Is(err, user.ErrUserInUse):
+		return subscriptions.ErrorResponse{Error: err.Error()}, 409
+
 	// ДОМЕННЫЕ ОШИБКИ
 	case errors.Is(err, domain.ErrInvalidPrice),
 		errors.Is(err, domain.ErrInvalidStartDate),
 		errors.Is(err, domain.ErrInvalidEndDate),
 		errors.Is(err, domain.ErrEmptyServiceName),
 		errors.Is(err, domain.ErrCompareDate),
-		errors.Is(err, domain.ErrInvalidDate):
+		errors.Is(err, domain.ErrInvalidDate),
+		errors.Is(err, domain.ErrInvalidForecastMonths),
+		errors.Is(err, domain.ErrInvalidForecastGroup),
+		errors.Is(err, domain.ErrInvalidTag),
+		errors.Is(err, domain.ErrTooManyTags),
+		errors.Is(err, domain.ErrInvalidMetadata),
+		errors.Is(err, domain.ErrInvalidTagMatch),
+		errors.Is(err, domain.ErrInvalidMetadataArg),
+		errors.Is(err, domain.ErrInvalidSplitRule),
+		errors.Is(err, domain.ErrInvalidMember),
+		errors.Is(err, domain.ErrTooManyMembers),
+		errors.Is(err, domain.ErrSplitMismatch),
+		errors.Is(err, domain.ErrSharedOwnerChange),
+		errors.Is(err, webhook.ErrInvalidURL),
+		errors.Is(err, webhook.ErrNoUser),
+		errors.Is(err, netguard.ErrForbiddenTarget),
+		errors.Is(err, webhook.ErrSecretTooShort),
+		errors.Is(err, webhook.ErrNoEventTypes),
+		errors.Is(err, webhook.ErrUnknownEventType),
+		errors.Is(err, reminder.ErrInvalidDaysBefore),
+		errors.Is(err, reminder.ErrUnknownChannel),
+		errors.Is(err, reminder.ErrInvalidEmail),
+		errors.Is(err, reminder.ErrInvalidWebhookURL),
+		errors.Is(err, reminder.ErrChannelDisabled),
+		errors.Is(err, analytics.ErrInvalidRange),
+		errors.Is(err, analytics.ErrRangeTooLong),
+		errors.Is(err, budget.ErrInvalidLimit),
+		errors.Is(err, budget.ErrInvalidServiceName),
+		errors.Is(err, budget.ErrInvalidCategory),
+		errors.Is(err, budget.ErrScopeConflict),
+		errors.Is(err, catalog.ErrEmptyName),
+		errors.Is(err, catalog.ErrNameTooLong),
+		errors.Is(err, catalog.ErrInvalidAlias),
+		errors.Is(err, catalog.ErrInvalidCategory),
+		errors.Is(err, catalog.ErrInvalidPrice),
+		errors.Is(err, catalog.ErrInvalidCurrency),
+		errors.Is(err, catalog.ErrInvalidLogoURL),
+		errors.Is(err, catalog.ErrUnknownService),
+		errors.Is(err, user.ErrEmptyDisplayName),
+		errors.Is(err, user.ErrDisplayNameTooLong),
+		errors.Is(err, user.ErrInvalidEmail),
+		errors.Is(err, user.ErrInvalidCurrency),
+		errors.Is(err, user.ErrInvalidTimezone),
+		errors.Is(err, user.ErrUnknownUser):
 		return subscriptions.ErrorResponse{Error: err.Error()}, 400
 
 	// ОШИБКИ РЕПОЗИТОРИЯ
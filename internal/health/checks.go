@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ------------------- Database ------------------
+
+type dbCheck struct {
+	db *sql.DB
+}
+
+func NewDBCheck(db *sql.DB) Check {
+	return &dbCheck{db: db}
+}
+
+func (c *dbCheck) Name() string {
+	return "database"
+}
+
+func (c *dbCheck) Check(ctx context.Context) (map[string]interface{}, error) {
+	return nil, c.db.PingContext(ctx)
+}
+
+// ------------------- Migrations ------------------
+
+type migrationsCheck struct {
+	db       *sql.DB
+	expected uint64
+}
+
+// NewMigrationsCheck сравнивает версию в schema_migrations
+// с последней встроенной в бинарник миграцией.
+func NewMigrationsCheck(db *sql.DB, expected uint64) Check {
+	return &migrationsCheck{db: db, expected: expected}
+}
+
+func (c *migrationsCheck) Name() string {
+	return "migrations"
+}
+
+func (c *migrationsCheck) Check(ctx context.Context) (map[string]interface{}, error) {
+	var (
+		version uint64
+		dirty   bool
+	)
+
+	err := c.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{
+		"current":  version,
+		"expected": c.expected,
+		"dirty":    dirty,
+	}
+
+	switch {
+	case dirty:
+		return details, fmt.Errorf("migration %d is dirty", version)
+	case version < c.expected:
+		return details, fmt.Errorf("pending migrations: current %d, expected %d", version, c.expected)
+	}
+
+	return details, nil
+}
+
+// ------------------- Pool ------------------
+
+type poolCheck struct {
+	db        *sql.DB
+	threshold float64
+}
+
+// NewPoolCheck падает, когда занято threshold (0..1) и больше
+// соединений от MaxOpenConns. Без лимита пула проверка всегда ok.
+func NewPoolCheck(db *sql.DB, threshold float64) Check {
+	return &poolCheck{db: db, threshold: threshold}
+}
+
+func (c *poolCheck) Name() string {
+	return "db_pool"
+}
+
+func (c *poolCheck) Check(_ context.Context) (map[string]interface{}, error) {
+	stats := c.db.Stats()
+
+	details := map[string]interface{}{
+		"open":          stats.OpenConnections,
+		"in_use":        stats.InUse,
+		"idle":          stats.Idle,
+		"max_open":      stats.MaxOpenConnections,
+		"wait_count":    stats.WaitCount,
+		"wait_duration": stats.WaitDuration.String(),
+	}
+
+	if stats.MaxOpenConnections <= 0 {
+		return details, nil
+	}
+
+	saturation := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+	details["saturation"] = saturation
+
+	if saturation >= c.threshold {
+		return details, fmt.Errorf("pool saturated: %d of %d connections in use", stats.InUse, stats.MaxOpenConnections)
+	}
+
+	return details, nil
+}
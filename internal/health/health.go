@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check — одна проверка готовности. Details попадают в отчёт как есть.
+type Check interface {
+	Name() string
+	Check(ctx context.Context) (details map[string]interface{}, err error)
+}
+
+type CheckResult struct {
+	Name       string                 `json:"name"`
+	Status     string                 `json:"status"`
+	DurationMs int64                  `json:"duration_ms"`
+	Error      string                 `json:"error,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+}
+
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+type Checker struct {
+	checks       []Check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// SetShuttingDown переводит readiness в fail, чтобы балансировщик
+// перестал слать трафик до того, как сервер начнёт закрываться.
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+func (c *Checker) ShuttingDown() bool {
+	return c.shuttingDown.Load()
+}
+
+func (c *Checker) Liveness() Report {
+	return Report{Status: StatusOK}
+}
+
+func (c *Checker) Readiness(ctx context.Context) Report {
+	report := Report{
+		Status: StatusOK,
+		Checks: make([]CheckResult, 0, len(c.checks)+1),
+	}
+
+	shutdown := CheckResult{Name: "shutdown", Status: StatusOK}
+	if c.ShuttingDown() {
+		shutdown.Status = StatusFail
+		shutdown.Error = "server is shutting down"
+		report.Status = StatusFail
+	}
+	report.Checks = append(report.Checks, shutdown)
+
+	for _, check := range c.checks {
+		res := c.run(ctx, check)
+		if res.Status != StatusOK {
+			report.Status = StatusFail
+		}
+		report.Checks = append(report.Checks, res)
+	}
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check.Check(ctx)
+
+	res := CheckResult{
+		Name:       check.Name(),
+		Status:     StatusOK,
+		DurationMs: time.Since(start).Milliseconds(),
+		Details:    details,
+	}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+
+	return res
+}
@@ -0,0 +1,354 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// lockID — ключ pg_advisory_lock, чтобы миграции с нескольких реплик
+// не применялись одновременно.
+var lockID = int64(crc32.ChecksumIEEE([]byte("testingtask:schema_migrations")))
+
+var fileRe = regexp.MustCompile(`^([0-9]+)_(.+)\.(up|down)\.sql$`)
+
+var (
+	ErrDirty       = errors.New("database schema is dirty")
+	ErrNoMigration = errors.New("no such migration")
+)
+
+// Dialect определяет SQL, специфичный для СУБД: блокировку и плейсхолдеры.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status — состояние схемы относительно встроенных миграций.
+type Status struct {
+	Current uint64
+	Dirty   bool
+	Latest  uint64
+	Pending []Migration
+}
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+func New(db *sql.DB, fsys fs.FS, dialect Dialect) (*Migrator, error) {
+	if dialect != Postgres && dialect != SQLite {
+		return nil, fmt.Errorf("unsupported migration dialect %q", dialect)
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+// Load читает миграции из fsys и сортирует их по версии.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, e := range entries {
+		m := fileRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", e.Name(), err)
+		}
+
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	res := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up file", m.Version, m.Name)
+		}
+		res = append(res, *m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+
+	if len(res) == 0 {
+		return nil, errors.New("no migrations found")
+	}
+
+	return res, nil
+}
+
+func (m *Migrator) Latest() uint64 {
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Up применяет все непримененные миграции и возвращает их количество.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w at version %d, fix it and run force", ErrDirty, current)
+		}
+
+		for _, mig := range m.migrations {
+			if mig.Version <= current {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig.Up, mig.Version); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", mig.Version, mig.Name, err)
+			}
+			applied++
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down откатывает steps последних миграций.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w at version %d, fix it and run force", ErrDirty, current)
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			mig := m.migrations[i]
+			if mig.Version > current {
+				continue
+			}
+
+			var prev uint64
+			if i > 0 {
+				prev = m.migrations[i-1].Version
+			}
+
+			if err := m.apply(ctx, conn, mig.Down, prev); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", mig.Version, mig.Name, err)
+			}
+			reverted++
+			current = prev
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Force выставляет версию без выполнения SQL и снимает флаг dirty.
+func (m *Migrator) Force(ctx context.Context, v uint64) error {
+	if v != 0 && !m.known(v) {
+		return fmt.Errorf("%w: %d", ErrNoMigration, v)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback() }()
+
+		if err := m.setVersion(ctx, tx, v, false); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	current, dirty, err := version(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &Status{Current: current, Dirty: dirty, Latest: m.Latest()}
+	for _, mig := range m.migrations {
+		if mig.Version > current {
+			st.Pending = append(st.Pending, mig)
+		}
+	}
+
+	return st, nil
+}
+
+// Check возвращает ошибку, если версия схемы в БД не совпадает
+// с последней встроенной миграцией.
+func (m *Migrator) Check(ctx context.Context) error {
+	st, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case st.Dirty:
+		return fmt.Errorf("%w at version %d", ErrDirty, st.Current)
+	case st.Current < st.Latest:
+		return fmt.Errorf("schema version %d is behind %d, run `migrate up`", st.Current, st.Latest)
+	case st.Current > st.Latest:
+		return fmt.Errorf("schema version %d is newer than this binary (%d)", st.Current, st.Latest)
+	}
+
+	return nil
+}
+
+func (m *Migrator) known(v uint64) bool {
+	for _, mig := range m.migrations {
+		if mig.Version == v {
+			return true
+		}
+	}
+	return false
+}
+
+// withLock выполняет fn на выделенном соединении под блокировкой миграций.
+// В SQLite advisory-блокировок нет, запись и так сериализуется блокировкой
+// файла базы, поэтому там fn выполняется без неё.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dialect == Postgres {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer func() {
+			_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+		}()
+	}
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// Таблица совместима с golang-migrate, поэтому уже развёрнутые базы
+// продолжают работать без ручных действий.
+func ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT NOT NULL PRIMARY KEY,
+		dirty BOOLEAN NOT NULL
+	)`)
+	return err
+}
+
+func version(ctx context.Context, conn *sql.Conn) (uint64, bool, error) {
+	var (
+		v     uint64
+		dirty bool
+	)
+
+	err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&v, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+
+	return v, dirty, err
+}
+
+// apply выполняет миграцию и запись новой версии в одной транзакции:
+// при ошибке схема остаётся на предыдущей версии, а не в состоянии dirty.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, query string, newVersion uint64) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if query != "" {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	if err := m.setVersion(ctx, tx, newVersion, false); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) setVersion(ctx context.Context, tx *sql.Tx, v uint64, dirty bool) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+	if v == 0 {
+		return nil
+	}
+
+	query := "INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)"
+	if m.dialect == SQLite {
+		query = "INSERT INTO schema_migrations (version, dirty) VALUES (?, ?)"
+	}
+
+	_, err := tx.ExecContext(ctx, query, v, dirty)
+	return err
+}
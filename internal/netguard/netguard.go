@@ -0,0 +1,92 @@
+// Package netguard не пускает запросы на адреса, которые задают
+// пользователи (webhook'и, напоминания), во внутреннюю сеть сервера:
+// loopback, link-local (в т.ч. metadata облака 169.254.169.254) и частные
+// диапазоны.
+//
+// Адрес проверяется дважды: при сохранении URL (CheckURL) и при каждом
+// соединении (Control). Второе нужно потому, что имя может разрешиться в
+// публичный адрес при проверке и во внутренний при отправке (DNS
+// rebinding).
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenTarget = errors.New("target address is loopback, link-local or private")
+
+// blocked — диапазоны, которые не покрывают методы netip.Addr.
+var blocked = []netip.Prefix{
+	// «Этот» хост и сеть: 0.0.0.0/8 на Linux ведёт на localhost.
+	netip.MustParsePrefix("0.0.0.0/8"),
+	// Shared address space (CGNAT), в нём metadata некоторых облаков.
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// Allowed сообщает, можно ли отправлять запросы на ip.
+func Allowed(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, p := range blocked {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL отклоняет URL, хост которого — запрещённый IP или localhost.
+// Имена не разрешаются: адрес, в который они указывают, проверит Control.
+func CheckURL(u *url.URL) error {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, host)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !Allowed(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, ip)
+	}
+	return nil
+}
+
+// Control — net.Dialer.Control, который отказывает в соединении с
+// запрещённым адресом. Вызывается уже с разрешённым IP, поэтому проверяет
+// тот адрес, куда на самом деле уйдёт запрос.
+func Control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !Allowed(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, ip)
+	}
+	return nil
+}
+
+// NewClient возвращает HTTP-клиент с таймаутом на запрос, который
+// соединяется только с разрешёнными адресами. Прокси из окружения не
+// используется: иначе проверялся бы адрес прокси, а не получателя.
+func NewClient(timeout time.Duration) *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   Control,
+	}).DialContext
+	return &http.Client{Timeout: timeout, Transport: t}
+}
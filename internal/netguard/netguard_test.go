@@ -0,0 +1,89 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAllowed(t *testing.T) {
+	cases := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"127.1.2.3", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"100.100.100.200", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tc := range cases {
+		if got := Allowed(netip.MustParseAddr(tc.ip)); got != tc.want {
+			t.Errorf("Allowed(%s) = %v, want %v", tc.ip, got, tc.want)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	cases := []struct {
+		url  string
+		want error
+	}{
+		{"https://hooks.example.com/x", nil},
+		{"http://93.184.216.34:8080/x", nil},
+		{"http://localhost:8080/x", ErrForbiddenTarget},
+		{"http://LOCALHOST./x", ErrForbiddenTarget},
+		{"http://api.localhost/x", ErrForbiddenTarget},
+		{"http://127.0.0.1/x", ErrForbiddenTarget},
+		{"http://[::1]:9000/x", ErrForbiddenTarget},
+		{"http://169.254.169.254/latest/meta-data", ErrForbiddenTarget},
+		{"http://10.0.0.5/x", ErrForbiddenTarget},
+	}
+	for _, tc := range cases {
+		u, err := url.Parse(tc.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := CheckURL(u); !errors.Is(err, tc.want) {
+			t.Errorf("CheckURL(%s) = %v, want %v", tc.url, err, tc.want)
+		}
+	}
+}
+
+// TestClientRefusesPrivate проверяет проверку при соединении: имя,
+// прошедшее CheckURL, всё равно не приведёт во внутреннюю сеть.
+func TestClientRefusesPrivate(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient(time.Second).Do(req); !errors.Is(err, ErrForbiddenTarget) {
+		t.Fatalf("Do(%s): got %v, want ErrForbiddenTarget", srv.URL, err)
+	}
+	if called {
+		t.Fatal("request reached the loopback server")
+	}
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+	logger "testingtask/pkg"
+)
+
+// Message — одно уведомление пользователю. To — адрес в терминах
+// канала: email для SMTP, URL для webhook; log его только записывает.
+type Message struct {
+	To      string                 `json:"-"`
+	Subject string                 `json:"subject"`
+	Text    string                 `json:"text"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// Notifier доставляет уведомления по одному каналу. Ошибка означает, что
+// сообщение не доставлено и его можно отправить повторно.
+type Notifier interface {
+	Notify(ctx context.Context, m Message) error
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier пишет уведомления в лог приложения. Удобен для локальной
+// разработки: ничего не отправляет наружу.
+func NewLogNotifier() Notifier {
+	return logNotifier{}
+}
+
+func (logNotifier) Notify(ctx context.Context, m Message) error {
+	logger.Info(ctx, "notify: "+m.Subject, map[string]interface{}{
+		"to":   m.To,
+		"text": m.Text,
+		"data": m.Data,
+	})
+	return nil
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testingtask/internal/netguard"
+	"time"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var got Message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	// Тестовый сервер слушает loopback, куда боевой клиент не ходит.
+	guarded := NewWebhookNotifier(time.Second)
+	n := &webhookNotifier{client: &http.Client{Timeout: time.Second}}
+	m := Message{To: srv.URL + "/ok", Subject: "Netflix ends in 3 days", Text: "...", Data: map[string]interface{}{"days_left": 3}}
+	if err := n.Notify(context.Background(), m); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got.Subject != m.Subject || got.Data["days_left"] != float64(3) {
+		t.Fatalf("body = %+v", got)
+	}
+
+	m.To = srv.URL + "/fail"
+	if err := n.Notify(context.Background(), m); err == nil {
+		t.Fatal("Notify to a failing endpoint: want error")
+	}
+
+	m.To = srv.URL + "/ok"
+	if err := guarded.Notify(context.Background(), m); !errors.Is(err, netguard.ErrForbiddenTarget) {
+		t.Fatalf("Notify to loopback: got %v, want ErrForbiddenTarget", err)
+	}
+}
+
+func TestBuildMail(t *testing.T) {
+	m := Message{To: "user@example.com", Subject: "Подписка заканчивается", Text: "line one\nline two"}
+	mail := string(buildMail("noreply@example.com", m, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)))
+
+	for _, want := range []string{
+		"From: noreply@example.com\r\n",
+		"To: user@example.com\r\n",
+		"Subject: =?utf-8?q?",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"\r\n\r\nline one\r\nline two\r\n",
+	} {
+		if !strings.Contains(mail, want) {
+			t.Errorf("mail lacks %q:\n%s", want, mail)
+		}
+	}
+	if strings.Contains(mail, "Подписка") {
+		t.Error("subject is not encoded")
+	}
+}
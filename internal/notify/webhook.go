@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testingtask/internal/netguard"
+	"time"
+)
+
+type webhookNotifier struct {
+	client *http.Client
+}
+
+// NewWebhookNotifier отправляет уведомление POST-запросом на Message.To с
+// JSON {subject, text, data} в теле. Успех — любой ответ 2xx. Адрес задаёт
+// пользователь, поэтому соединения во внутреннюю сеть запрещены.
+func NewWebhookNotifier(timeout time.Duration) Notifier {
+	return &webhookNotifier{client: netguard.NewClient(timeout)}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, m Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.To, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
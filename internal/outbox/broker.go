@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// HeaderAggregateID — заголовок сообщения брокеру с ID агрегата. Брокеры
+// без ключа сообщения (NATS) передают его только так.
+const HeaderAggregateID = "X-Aggregate-ID"
+
+// BrokerMessage — сообщение брокеру: тема (subject NATS, topic Kafka),
+// ключ партиции, заголовки и тело.
+type BrokerMessage struct {
+	Subject string
+	Key     []byte
+	Headers map[string]string
+	Data    []byte
+}
+
+// Publisher — минимальный клиент брокера сообщений. nil — брокер принял
+// сообщение. Кроме встроенного NATS (NewNATSPublisher) ему соответствует
+// тонкая обёртка над Kafka Writer.WriteMessages: Subject — topic, Key —
+// ключ партиции, Headers — заголовки записи.
+type Publisher interface {
+	Publish(ctx context.Context, m BrokerMessage) error
+}
+
+type brokerSink struct {
+	pub    Publisher
+	prefix string
+}
+
+// NewBrokerSink публикует события в тему <prefix>.<тип события> с Message
+// в теле. Ключ сообщения — AggregateID: брокеры с партициями по ключу
+// сохраняют порядок событий одного агрегата. Заголовки — X-Event-ID,
+// X-Event-Type и X-Aggregate-ID.
+func NewBrokerSink(pub Publisher, prefix string) Sink {
+	return &brokerSink{pub: pub, prefix: prefix}
+}
+
+func (s *brokerSink) Publish(ctx context.Context, e Event) error {
+	data, err := json.Marshal(e.Message())
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	subject := e.Type
+	if s.prefix != "" {
+		subject = s.prefix + "." + e.Type
+	}
+	return s.pub.Publish(ctx, BrokerMessage{
+		Subject: subject,
+		Key:     []byte(e.AggregateID.String()),
+		Headers: map[string]string{
+			HeaderEventID:     e.ID.String(),
+			HeaderEventType:   e.Type,
+			HeaderAggregateID: e.AggregateID.String(),
+		},
+		Data: data,
+	})
+}
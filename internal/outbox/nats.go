@@ -0,0 +1,372 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HeaderNATSMsgID — заголовок, по которому JetStream отбрасывает дубли в
+// окне дедупликации потока. NATS-публикатор заполняет его из X-Event-ID.
+const HeaderNATSMsgID = "Nats-Msg-Id"
+
+const (
+	natsDefaultPort = "4222"
+	natsInboxSID    = "1"
+)
+
+// natsInfo — нужные поля INFO сервера.
+type natsInfo struct {
+	Headers     bool `json:"headers"`
+	TLSRequired bool `json:"tls_required"`
+}
+
+// natsPubAck — ответ JetStream на публикацию.
+type natsPubAck struct {
+	Stream string `json:"stream"`
+	Error  *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error"`
+}
+
+// NATSConfig — подключение NATS-публикатора.
+type NATSConfig struct {
+	// URL — nats://[user:pass@|token@]host[:4222], tls:// — то же по TLS.
+	URL string
+	// JetStream — ждать подтверждения записи в поток JetStream. Без него
+	// сообщение, на subject которого никто не подписан, теряется.
+	JetStream bool
+	Timeout   time.Duration
+}
+
+type natsPublisher struct {
+	cfg   NATSConfig
+	host  string
+	addr  string
+	tls   bool
+	user  string
+	pass  string
+	token string
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+	// inbox — subject ответов JetStream этого соединения, seq — номер
+	// последней публикации: ответ на неё приходит на <inbox>.<seq>.
+	inbox string
+	seq   uint64
+}
+
+// NewNATSPublisher публикует сообщения в NATS по протоколу клиента HPUB с
+// заголовками. С JetStream nil означает, что поток подтвердил запись
+// (повтор с тем же Nats-Msg-Id поток отбросит как дубль), иначе — что
+// сервер получил сообщение. Соединение открывается при первой публикации
+// и после ошибки переоткрывается при следующей.
+func NewNATSPublisher(cfg NATSConfig) (Publisher, io.Closer, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "nats" && u.Scheme != "tls") || u.Hostname() == "" {
+		return nil, nil, errors.New("nats url must be nats://host[:port] or tls://host[:port]")
+	}
+
+	p := &natsPublisher{cfg: cfg, host: u.Hostname(), tls: u.Scheme == "tls"}
+	port := u.Port()
+	if port == "" {
+		port = natsDefaultPort
+	}
+	p.addr = net.JoinHostPort(p.host, port)
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			p.user, p.pass = u.User.Username(), pass
+		} else {
+			p.token = u.User.Username()
+		}
+	}
+	return p, p, nil
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, m BrokerMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = p.conn.SetDeadline(deadline)
+	}
+
+	if err := p.publish(m); err != nil {
+		p.reset()
+		return err
+	}
+	return nil
+}
+
+// Close закрывает соединение, если оно открыто.
+func (p *natsPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn, p.r = nil, nil
+	return err
+}
+
+func (p *natsPublisher) reset() {
+	_ = p.conn.Close()
+	p.conn, p.r = nil, nil
+}
+
+func (p *natsPublisher) connect(ctx context.Context) error {
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("nats dial: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	p.conn, p.r = conn, bufio.NewReader(conn)
+
+	if err := p.handshake(); err != nil {
+		p.reset()
+		return err
+	}
+	return nil
+}
+
+// handshake читает INFO, при необходимости переходит на TLS, отправляет
+// CONNECT (и SUB на ответы JetStream) и дожидается PONG: так ошибки
+// авторизации видны сразу.
+func (p *natsPublisher) handshake() error {
+	line, err := p.readLine()
+	if err != nil {
+		return fmt.Errorf("nats info: %w", err)
+	}
+	payload, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		return fmt.Errorf("nats info: unexpected %q", line)
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(payload), &info); err != nil {
+		return fmt.Errorf("nats info: %w", err)
+	}
+	if !info.Headers {
+		return errors.New("nats server does not support headers")
+	}
+
+	if p.tls || info.TLSRequired {
+		conn := tls.Client(p.conn, &tls.Config{ServerName: p.host})
+		if err := conn.Handshake(); err != nil {
+			return fmt.Errorf("nats tls: %w", err)
+		}
+		p.conn, p.r = conn, bufio.NewReader(conn)
+	}
+
+	connect, _ := json.Marshal(map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"headers":  true,
+		// Публикация без получателей сразу получает ответ 503, а не
+		// ждёт таймаута.
+		"no_responders": true,
+		"protocol":      1,
+		"lang":          "go",
+		"name":          "subscriptions-api",
+		"user":          p.user,
+		"pass":          p.pass,
+		"auth_token":    p.token,
+	})
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "CONNECT %s\r\n", connect)
+	if p.cfg.JetStream {
+		p.inbox, p.seq = "_INBOX."+uuid.NewString(), 0
+		fmt.Fprintf(&buf, "SUB %s.* %s\r\n", p.inbox, natsInboxSID)
+	}
+	buf.WriteString("PING\r\n")
+	if _, err := p.conn.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("nats connect: %w", err)
+	}
+	if err := p.waitPong(); err != nil {
+		return fmt.Errorf("nats connect: %w", err)
+	}
+	return nil
+}
+
+func (p *natsPublisher) publish(m BrokerMessage) error {
+	headers := natsHeaders(m.Headers)
+
+	subject := m.Subject
+	if p.cfg.JetStream {
+		p.seq++
+		subject += fmt.Sprintf(" %s.%d", p.inbox, p.seq)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "HPUB %s %d %d\r\n", subject, len(headers), len(headers)+len(m.Data))
+	buf.Write(headers)
+	buf.Write(m.Data)
+	buf.WriteString("\r\n")
+	if !p.cfg.JetStream {
+		buf.WriteString("PING\r\n")
+	}
+
+	if _, err := p.conn.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("nats publish: %w", err)
+	}
+	if p.cfg.JetStream {
+		err := p.waitAck(fmt.Sprintf("%s.%d", p.inbox, p.seq))
+		if err != nil {
+			return fmt.Errorf("nats publish to %s: %w", m.Subject, err)
+		}
+		return nil
+	}
+	if err := p.waitPong(); err != nil {
+		return fmt.Errorf("nats publish: %w", err)
+	}
+	return nil
+}
+
+// waitAck читает ответы сервера до ответа JetStream на reply.
+func (p *natsPublisher) waitAck(reply string) error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+		if err := p.control(line); err != nil {
+			return err
+		}
+
+		op, args, _ := strings.Cut(line, " ")
+		if op != "MSG" && op != "HMSG" {
+			continue
+		}
+		// MSG <subject> <sid> [reply] <size>,
+		// HMSG <subject> <sid> [reply] <header size> <size>.
+		fields := strings.Fields(args)
+		if len(fields) < 3 {
+			return fmt.Errorf("unexpected %q", line)
+		}
+		size, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil {
+			return fmt.Errorf("unexpected %q", line)
+		}
+		body := make([]byte, size+2)
+		if _, err := io.ReadFull(p.r, body); err != nil {
+			return err
+		}
+		body = body[:size]
+
+		// Ответ на прошлую публикацию, время которой вышло.
+		if fields[0] != reply {
+			continue
+		}
+
+		var hdrSize int
+		if op == "HMSG" {
+			if hdrSize, err = strconv.Atoi(fields[len(fields)-2]); err != nil || hdrSize > size {
+				return fmt.Errorf("unexpected %q", line)
+			}
+			// Ответ только из заголовков — статус: 503 — subject не
+			// попадает ни в один поток.
+			status, _, _ := bytes.Cut(body[:hdrSize], []byte("\r\n"))
+			if hdrSize == size {
+				return fmt.Errorf("no jetstream stream acknowledged the message: %s", status)
+			}
+		}
+
+		var ack natsPubAck
+		if err := json.Unmarshal(body[hdrSize:], &ack); err != nil {
+			return fmt.Errorf("decode jetstream ack: %w", err)
+		}
+		if ack.Error != nil {
+			return fmt.Errorf("jetstream error %d: %s", ack.Error.Code, ack.Error.Description)
+		}
+		return nil
+	}
+}
+
+// natsHeaders собирает блок заголовков HPUB. Ключ сообщения в NATS не
+// передаётся: агрегат есть в X-Aggregate-ID.
+func natsHeaders(h map[string]string) []byte {
+	keys := make([]string, 0, len(h)+1)
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString("NATS/1.0\r\n")
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s: %s\r\n", k, h[k])
+	}
+	if id, ok := h[HeaderEventID]; ok {
+		fmt.Fprintf(&buf, "%s: %s\r\n", HeaderNATSMsgID, id)
+	}
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
+
+// waitPong читает ответы сервера до PONG.
+func (p *natsPublisher) waitPong() error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+		if line == "PONG" {
+			return nil
+		}
+		if err := p.control(line); err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "MSG ") || strings.HasPrefix(line, "HMSG ") {
+			return fmt.Errorf("unexpected %q", line)
+		}
+	}
+}
+
+// control обрабатывает служебные строки сервера: -ERR — отказ, PING
+// получает PONG, INFO и +OK пропускаются.
+func (p *natsPublisher) control(line string) error {
+	switch {
+	case line == "PING":
+		_, err := p.conn.Write([]byte("PONG\r\n"))
+		return err
+	case strings.HasPrefix(line, "-ERR"):
+		return fmt.Errorf("server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+	}
+	return nil
+}
+
+func (p *natsPublisher) readLine() (string, error) {
+	line, err := p.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
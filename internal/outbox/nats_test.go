@@ -0,0 +1,124 @@
+package outbox_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"testingtask/internal/outbox"
+	"testingtask/internal/repository/repotest"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// natsPub — публикация, полученная fakeNATS.
+type natsPub struct {
+	subject string
+	headers string
+	data    string
+}
+
+// fakeNATS — сервер NATS на одно соединение: отвечает на рукопожатие и на
+// каждую HPUB ответом из acks по порядку. Ответ вида "503" — статус без
+// тела, как при отсутствии потока.
+func fakeNATS(t *testing.T, acks ...string) (string, <-chan natsPub) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	pubs := make(chan natsPub, len(acks))
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		fmt.Fprint(conn, "INFO {\"headers\":true}\r\n")
+
+		var sid string
+		for len(acks) > 0 {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			switch fields[0] {
+			case "CONNECT":
+				continue
+			case "SUB":
+				sid = fields[2]
+				continue
+			case "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+				continue
+			}
+
+			// HPUB <subject> <reply> <header size> <size>
+			hdr, _ := strconv.Atoi(fields[3])
+			size, _ := strconv.Atoi(fields[4])
+			body := make([]byte, size+2)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return
+			}
+			ack := acks[0]
+			acks = acks[1:]
+			pubs <- natsPub{subject: fields[1], headers: string(body[:hdr]), data: string(body[hdr:size])}
+
+			if ack == "503" {
+				status := "NATS/1.0 503\r\n\r\n"
+				fmt.Fprintf(conn, "HMSG %s %s %d %d\r\n%s\r\n", fields[2], sid, len(status), len(status), status)
+				continue
+			}
+			fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", fields[2], sid, len(ack), ack)
+		}
+	}()
+	return "nats://" + ln.Addr().String(), pubs
+}
+
+func TestNATSSink(t *testing.T) {
+	ctx := context.Background()
+	addr, pubs := fakeNATS(t, `{"stream":"SUBS","seq":1}`, "503")
+
+	pub, closer, err := outbox.NewNATSPublisher(outbox.NATSConfig{URL: addr, JetStream: true, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closer.Close()
+	sink := outbox.NewBrokerSink(pub, "subscriptions")
+
+	e := repotest.Event(uuid.New(), "SubscriptionCreated", 0)
+	if err := sink.Publish(ctx, e); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	got := <-pubs
+	if got.subject != "subscriptions.SubscriptionCreated" {
+		t.Fatalf("subject = %q", got.subject)
+	}
+	for _, h := range []string{
+		"X-Event-ID: " + e.ID.String(),
+		"X-Event-Type: SubscriptionCreated",
+		"X-Aggregate-ID: " + e.AggregateID.String(),
+		"Nats-Msg-Id: " + e.ID.String(),
+	} {
+		if !strings.Contains(got.headers, h+"\r\n") {
+			t.Errorf("headers %q lack %q", got.headers, h)
+		}
+	}
+	if !strings.Contains(got.data, `"aggregate_id":"`+e.AggregateID.String()+`"`) {
+		t.Errorf("data = %s", got.data)
+	}
+
+	// Subject не попал ни в один поток: Relay повторит событие позже.
+	if err := sink.Publish(ctx, e); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("Publish without stream: got %v, want 503 error", err)
+	}
+}
@@ -0,0 +1,67 @@
+// Package outbox доставляет доменные события во внешние системы.
+//
+// События пишутся в таблицу outbox в той же транзакции, что и изменение
+// данных, а Relay в фоне отправляет их в Sink. Доставка «хотя бы один
+// раз»: после сбоя событие отправится повторно, поэтому получатели
+// отсеивают дубли по Event.ID.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event — доменное событие в outbox. События одного агрегата (AggregateID)
+// доставляются в порядке записи.
+type Event struct {
+	ID          uuid.UUID
+	Type        string
+	AggregateID uuid.UUID
+	Payload     json.RawMessage
+	OccurredAt  time.Time
+	// Attempts — число неудачных попыток отправки.
+	Attempts int
+}
+
+// Message — событие в том виде, в котором его получают sink'и.
+type Message struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID uuid.UUID       `json:"aggregate_id"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Data        json.RawMessage `json:"data"`
+}
+
+func (e Event) Message() Message {
+	return Message{
+		ID:          e.ID,
+		Type:        e.Type,
+		AggregateID: e.AggregateID,
+		OccurredAt:  e.OccurredAt,
+		Data:        e.Payload,
+	}
+}
+
+// Sink публикует событие во внешнюю систему. nil — событие принято;
+// при ошибке Relay повторит отправку позже.
+type Sink interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Store — хранилище outbox, из которого читает Relay.
+type Store interface {
+	// Pending возвращает до limit неотправленных событий в порядке записи.
+	// Событие не попадает в выборку, если его время повтора не наступило
+	// или раньше него в том же агрегате есть событие, ждущее повтора.
+	Pending(ctx context.Context, now time.Time, limit int) ([]Event, error)
+	MarkPublished(ctx context.Context, id uuid.UUID, at time.Time) error
+	// MarkFailed увеличивает Attempts и откладывает событие до next.
+	MarkFailed(ctx context.Context, id uuid.UUID, next time.Time, reason string) error
+	// TryLock не даёт нескольким экземплярам отправлять события
+	// одновременно и нарушать порядок. ok=false — блокировку держит другой
+	// экземпляр.
+	TryLock(ctx context.Context) (unlock func(), ok bool, err error)
+}
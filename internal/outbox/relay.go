@@ -0,0 +1,138 @@
+package outbox
+
+import (
+	"context"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// RetryMin и RetryMax — границы экспоненциальной задержки повтора.
+	RetryMin time.Duration
+	RetryMax time.Duration
+}
+
+// Relay периодически забирает неотправленные события из Store и
+// публикует их в Sink. Событие с ошибкой откладывается с растущей
+// задержкой, а следующие события того же агрегата ждут его, чтобы
+// порядок не нарушался. Попытки не ограничены: событие не теряется, пока
+// sink не примет его.
+type Relay struct {
+	store Store
+	sink  Sink
+	cfg   RelayConfig
+	now   func() time.Time
+}
+
+func NewRelay(store Store, sink Sink, cfg RelayConfig) *Relay {
+	return &Relay{store: store, sink: sink, cfg: cfg, now: time.Now}
+}
+
+// Run отправляет события до отмены ctx. Полная пачка забирается сразу
+// следующей, иначе Relay ждёт PollInterval.
+func (r *Relay) Run(ctx context.Context) {
+	logger.Info(ctx, "outbox: relay started", map[string]interface{}{
+		"poll_interval": r.cfg.PollInterval.String(),
+		"batch_size":    r.cfg.BatchSize,
+	})
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(context.Background(), "outbox: relay stopped", nil)
+			return
+		case <-timer.C:
+		}
+
+		n, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error(ctx, "outbox: relay iteration failed", err, nil)
+		}
+
+		if err == nil && n == r.cfg.BatchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(r.cfg.PollInterval)
+		}
+	}
+}
+
+// RunOnce обрабатывает одну пачку и возвращает число выбранных событий.
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	unlock, ok, err := r.store.TryLock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, nil
+	}
+	defer unlock()
+
+	events, err := r.store.Pending(ctx, r.now().UTC(), r.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// Агрегаты, у которых в этой пачке уже был сбой: их следующие
+	// события ждут повтора.
+	blocked := map[uuid.UUID]bool{}
+	for _, e := range events {
+		if blocked[e.AggregateID] {
+			continue
+		}
+
+		if err := r.sink.Publish(ctx, e); err != nil {
+			if ctx.Err() != nil {
+				return len(events), ctx.Err()
+			}
+			blocked[e.AggregateID] = true
+
+			next := r.now().UTC().Add(r.backoff(e.Attempts + 1))
+			logger.Warn(ctx, "outbox: publish failed, will retry", map[string]interface{}{
+				"event_id":     e.ID,
+				"event_type":   e.Type,
+				"aggregate_id": e.AggregateID,
+				"attempt":      e.Attempts + 1,
+				"retry_at":     next,
+				"error":        err.Error(),
+			})
+			if err := r.store.MarkFailed(ctx, e.ID, next, err.Error()); err != nil {
+				return len(events), err
+			}
+			continue
+		}
+
+		// Если отметка не сохранится, событие отправится ещё раз — это
+		// допустимо при доставке «хотя бы один раз».
+		if err := r.store.MarkPublished(ctx, e.ID, r.now().UTC()); err != nil {
+			return len(events), err
+		}
+	}
+
+	return len(events), nil
+}
+
+// backoff возвращает задержку перед попыткой attempt.
+func (r *Relay) backoff(attempt int) time.Duration {
+	return Backoff(r.cfg.RetryMin, r.cfg.RetryMax, attempt)
+}
+
+// Backoff возвращает задержку перед попыткой attempt: min, удваиваясь с
+// каждой попыткой, но не больше max.
+func Backoff(min, max time.Duration, attempt int) time.Duration {
+	d := min
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
@@ -0,0 +1,76 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testingtask/internal/outbox"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// flakySink отклоняет события из fail, остальные запоминает по порядку.
+type flakySink struct {
+	fail map[uuid.UUID]bool
+	got  []uuid.UUID
+}
+
+func (s *flakySink) Publish(_ context.Context, e outbox.Event) error {
+	if s.fail[e.ID] {
+		return errors.New("unavailable")
+	}
+	s.got = append(s.got, e.ID)
+	return nil
+}
+
+func TestRelayOrderAndRetry(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryOutboxRepository()
+
+	a, b := uuid.New(), uuid.New()
+	aCreated := repotest.Event(a, "SubscriptionCreated", 0)
+	bCreated := repotest.Event(b, "SubscriptionCreated", time.Second)
+	aUpdated := repotest.Event(a, "SubscriptionUpdated", 2*time.Second)
+	if err := store.Add(ctx, aCreated, bCreated, aUpdated); err != nil {
+		t.Fatal(err)
+	}
+
+	sink := &flakySink{fail: map[uuid.UUID]bool{aCreated.ID: true}}
+	relay := outbox.NewRelay(store, sink, outbox.RelayConfig{
+		PollInterval: time.Millisecond,
+		BatchSize:    10,
+		RetryMin:     time.Millisecond,
+		RetryMax:     time.Millisecond,
+	})
+
+	// Первое событие агрегата a не принято — второе ждёт его, b уходит.
+	if _, err := relay.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.got) != 1 || sink.got[0] != bCreated.ID {
+		t.Fatalf("first run published %v, want only %s", sink.got, bCreated.ID)
+	}
+
+	sink.fail = nil
+	time.Sleep(5 * time.Millisecond)
+	if _, err := relay.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []uuid.UUID{bCreated.ID, aCreated.ID, aUpdated.ID}
+	if len(sink.got) != len(want) {
+		t.Fatalf("published %v, want %v", sink.got, want)
+	}
+	for i := range want {
+		if sink.got[i] != want[i] {
+			t.Fatalf("published %v, want %v", sink.got, want)
+		}
+	}
+
+	if n, err := relay.RunOnce(ctx); err != nil || n != 0 {
+		t.Fatalf("third run selected %d events, %v, want 0", n, err)
+	}
+}
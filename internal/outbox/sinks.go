@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	SinkNone    = "none"
+	SinkWebhook = "webhook"
+	SinkFile    = "file"
+	SinkNATS    = "nats"
+)
+
+// Заголовки запроса WebhookSink.
+const (
+	HeaderEventID   = "X-Event-ID"
+	HeaderEventType = "X-Event-Type"
+)
+
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink отправляет каждое событие POST-запросом с Message в теле.
+// Успех — любой ответ 2xx.
+func NewWebhookSink(url string, timeout time.Duration) Sink {
+	return &webhookSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *webhookSink) Publish(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e.Message())
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, e.ID.String())
+	req.Header.Set(HeaderEventType, e.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+type fileSink struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewFileSink дописывает события в файл построчно (JSON Lines). Удобен
+// для локальной разработки и отладки.
+func NewFileSink(path string) (Sink, io.Closer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open outbox file: %w", err)
+	}
+	return &fileSink{f: f}, f, nil
+}
+
+func (s *fileSink) Publish(_ context.Context, e Event) error {
+	line, err := json.Marshal(e.Message())
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+type multiSink []Sink
+
+// NewMultiSink публикует событие во все sinks по очереди. Событие считается
+// опубликованным, только если его приняли все; при повторе его получат и
+// те, что приняли его в прошлый раз, поэтому каждый sink должен быть готов
+// к дублям.
+func NewMultiSink(sinks ...Sink) Sink {
+	if len(sinks) == 1 {
+		return sinks[0]
+	}
+	return multiSink(sinks)
+}
+
+func (m multiSink) Publish(ctx context.Context, e Event) error {
+	for _, s := range m {
+		if err := s.Publish(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
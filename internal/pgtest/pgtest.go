@@ -0,0 +1,169 @@
+// Package pgtest поднимает для тестов одноразовый Postgres.
+//
+// Источник базы выбирается так:
+//   - TEST_DATABASE_URL — готовая база (например, из docker compose);
+//   - PG_TEST_BIN — каталог с initdb и postgres: кластер создаётся во
+//     временном каталоге, запускается на свободном порту и удаляется
+//     после тестов пакета;
+//   - иначе тест пропускается.
+//
+// Пакет, использующий DSN, должен вызывать Main из TestMain, чтобы
+// запущенный сервер был остановлен.
+package pgtest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+const startTimeout = 30 * time.Second
+
+var (
+	once   sync.Once
+	srv    *server
+	dsn    string
+	srvErr error
+)
+
+type server struct {
+	dir string
+	cmd *exec.Cmd
+}
+
+// Main запускает тесты пакета и останавливает сервер, если он был поднят.
+func Main(m *testing.M) {
+	code := m.Run()
+	if srv != nil {
+		srv.stop()
+	}
+	os.Exit(code)
+}
+
+// DSN возвращает строку подключения к тестовой базе или пропускает тест.
+func DSN(t testing.TB) string {
+	t.Helper()
+
+	if url := os.Getenv("TEST_DATABASE_URL"); url != "" {
+		return url
+	}
+
+	bin := os.Getenv("PG_TEST_BIN")
+	if bin == "" {
+		t.Skip("neither TEST_DATABASE_URL nor PG_TEST_BIN is set")
+	}
+
+	once.Do(func() {
+		srv, dsn, srvErr = start(bin)
+	})
+	if srvErr != nil {
+		t.Fatalf("start postgres: %v", srvErr)
+	}
+
+	return dsn
+}
+
+func start(bin string) (*server, string, error) {
+	dir, err := os.MkdirTemp("", "pgtest-")
+	if err != nil {
+		return nil, "", err
+	}
+	data := filepath.Join(dir, "data")
+
+	initdb := exec.Command(filepath.Join(bin, "initdb"),
+		"-D", data, "-U", "postgres", "-A", "trust", "-E", "UTF8", "--no-sync")
+	if out, err := initdb.CombinedOutput(); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, "", fmt.Errorf("initdb: %w: %s", err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, "", err
+	}
+
+	logFile, err := os.Create(filepath.Join(dir, "postgres.log"))
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, "", err
+	}
+	defer logFile.Close()
+
+	// Надёжность записи тестам не нужна, выключаем всё, что её даёт.
+	cmd := exec.Command(filepath.Join(bin, "postgres"),
+		"-D", data,
+		"-p", fmt.Sprint(port),
+		"-k", dir,
+		"-c", "listen_addresses=127.0.0.1",
+		"-c", "fsync=off",
+		"-c", "synchronous_commit=off",
+		"-c", "full_page_writes=off",
+	)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, "", fmt.Errorf("start postgres: %w", err)
+	}
+
+	s := &server{dir: dir, cmd: cmd}
+	url := fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", port)
+
+	if err := waitReady(url); err != nil {
+		log, _ := os.ReadFile(logFile.Name())
+		s.stop()
+		return nil, "", fmt.Errorf("%w\n%s", err, log)
+	}
+
+	return s, url, nil
+}
+
+func waitReady(url string) error {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	deadline := time.Now().Add(startTimeout)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("postgres is not ready after %s: %w", startTimeout, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// stop делает fast shutdown (SIGINT) и удаляет каталог кластера.
+func (s *server) stop() {
+	if s.cmd.Process != nil {
+		_ = s.cmd.Process.Signal(os.Interrupt)
+		_ = s.cmd.Wait()
+	}
+	_ = os.RemoveAll(s.dir)
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,29 @@
+// Package readpref хранит в контексте запроса требование читать с primary,
+// а не с реплики ("read your writes" после изменения данных).
+package readpref
+
+import "context"
+
+const (
+	// Header с любым непустым значением отправляет чтение запроса на primary.
+	Header = "X-Read-Primary"
+	// Cookie выставляется после изменяющего запроса на время окна
+	// read-your-writes.
+	Cookie = "read_primary"
+)
+
+type ctxKey struct{}
+
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKey{}, true)
+}
+
+// Primary сообщает, что чтение должно идти с primary.
+func Primary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	v, _ := ctx.Value(ctxKey{}).(bool)
+	return v
+}
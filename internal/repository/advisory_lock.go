@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/crc32"
+	myerrors "testingtask/internal/errors"
+	logger "testingtask/pkg"
+
+	"gorm.io/gorm"
+)
+
+// advisoryLockID переводит ключ блокировки в ключ pg_advisory_lock.
+func advisoryLockID(key string) int64 {
+	return int64(crc32.ChecksumIEEE([]byte("testingtask:" + key)))
+}
+
+// advisoryLock — сессионная advisory-блокировка Postgres на отдельном
+// соединении. Блокировка живёт, пока живо соединение: если оно оборвётся,
+// Postgres снимет её сам.
+type advisoryLock struct {
+	conn *sql.Conn
+	id   int64
+	key  string
+}
+
+// tryAdvisoryLock берёт блокировку key без ожидания. ok=false — её держит
+// другая сессия.
+func tryAdvisoryLock(ctx context.Context, db *gorm.DB, key string) (*advisoryLock, bool, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	c, err := sqlDB.Conn(ctx)
+	if err != nil {
+		logger.Error(ctx, "repo: advisory lock connection failed", err, map[string]interface{}{
+			"key": key,
+		})
+		return nil, false, mapError(err, myerrors.ErrDatabase)
+	}
+
+	id := advisoryLockID(key)
+	var ok bool
+	if err := c.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", id).Scan(&ok); err != nil {
+		_ = c.Close()
+		logger.Error(ctx, "repo: advisory lock failed", err, map[string]interface{}{
+			"key": key,
+		})
+		return nil, false, mapError(err, myerrors.ErrDatabase)
+	}
+	if !ok {
+		_ = c.Close()
+		return nil, false, nil
+	}
+	return &advisoryLock{conn: c, id: id, key: key}, true, nil
+}
+
+// Held проверяет, что соединение с блокировкой живо.
+func (l *advisoryLock) Held(ctx context.Context) error {
+	if err := l.conn.PingContext(ctx); err != nil {
+		return mapError(err, myerrors.ErrDatabase)
+	}
+	return nil
+}
+
+func (l *advisoryLock) Unlock() {
+	// Если unlock не прошёл, соединение не возвращается в пул, а
+	// закрывается: Postgres снимет блокировку вместе с сессией.
+	if _, err := l.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", l.id); err != nil {
+		logger.Error(context.Background(), "repo: advisory unlock failed", err, map[string]interface{}{
+			"key": l.key,
+		})
+		_ = l.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	_ = l.conn.Close()
+}
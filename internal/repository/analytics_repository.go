@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testingtask/internal/domain/analytics"
+	myerrors "testingtask/internal/errors"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AnalyticsRepository читает выручку подписок для отчётов.
+type AnalyticsRepository interface {
+	// Revenue возвращает выручку за каждый месяц months (первые числа,
+	// UTC) по пользователям и сервисам: подписка приносит свою цену в
+	// каждом месяце с start_date по end_date включительно.
+	Revenue(ctx context.Context, months []time.Time) ([]analytics.Month, error)
+}
+
+type analyticsRepository struct {
+	DB *gorm.DB
+}
+
+func NewAnalyticsRepository(db *gorm.DB) AnalyticsRepository {
+	return &analyticsRepository{DB: db}
+}
+
+type revenueRow struct {
+	Idx           int
+	UserID        uuid.UUID
+	ServiceName   string
+	MRR           int
+	Subscriptions int
+}
+
+// Revenue считает выручку одним запросом: месяцы передаются списком
+// VALUES, который работает и в Postgres, и в SQLite, и соединяются с
+// подписками, действующими в месяце. Даты передаются строками
+// YYYY-MM-DD, чтобы приведение не зависело от часового пояса сессии.
+func (r *analyticsRepository) Revenue(ctx context.Context, months []time.Time) ([]analytics.Month, error) {
+	if len(months) == 0 {
+		return nil, nil
+	}
+
+	monthExpr, cond := "CAST(? AS DATE)", "s.start_date <= m.month AND (s.end_date IS NULL OR s.end_date >= m.month)"
+	if r.DB.Dialector.Name() == "sqlite" {
+		monthExpr, cond = "date(?)", "date(s.start_date) <= m.month AND (s.end_date IS NULL OR date(s.end_date) >= m.month)"
+	}
+
+	// Номер месяца подставляется литералом: параметр в VALUES Postgres
+	// считал бы текстом.
+	values := make([]string, len(months))
+	args := make([]interface{}, len(months))
+	for i, m := range months {
+		values[i] = fmt.Sprintf("(%d, %s)", i, monthExpr)
+		args[i] = m.Format("2006-01-02")
+	}
+
+	query := fmt.Sprintf(`WITH months (idx, month) AS (VALUES %s)
+SELECT m.idx, s.user_id, s.service_name, SUM(s.price) AS mrr, COUNT(*) AS subscriptions
+FROM months m
+JOIN subscriptions s ON %s
+GROUP BY m.idx, s.user_id, s.service_name
+ORDER BY m.idx, s.user_id, s.service_name`, strings.Join(values, ", "), cond)
+
+	var rows []revenueRow
+	if err := conn(ctx, r.DB).Raw(query, args...).Scan(&rows).Error; err != nil {
+		logger.Error(ctx, "repo: revenue query failed", err, map[string]interface{}{
+			"from":   months[0].Format("01-2006"),
+			"months": len(months),
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	res := make([]analytics.Month, len(months))
+	for i, m := range months {
+		res[i].Month = m
+	}
+	for _, row := range rows {
+		res[row.Idx].Revenue = append(res[row.Idx].Revenue, analytics.Revenue{
+			UserID:        row.UserID,
+			ServiceName:   row.ServiceName,
+			MRR:           row.MRR,
+			Subscriptions: row.Subscriptions,
+		})
+	}
+	// Порядок uuid в базе может отличаться от строкового.
+	for i := range res {
+		res[i].Sort()
+	}
+
+	return res, nil
+}
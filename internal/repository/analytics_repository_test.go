@@ -0,0 +1,34 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+	"testingtask/internal/database"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+)
+
+func TestMemoryAnalyticsRepository(t *testing.T) {
+	repotest.RunAnalyticsRepository(t, func(t *testing.T) (repository.SubRepository, repository.AnalyticsRepository) {
+		subs := repository.NewMemorySubRepository()
+		return subs, repository.NewMemoryAnalyticsRepository(subs)
+	})
+}
+
+func TestSQLiteAnalyticsRepository(t *testing.T) {
+	repotest.RunAnalyticsRepository(t, func(t *testing.T) (repository.SubRepository, repository.AnalyticsRepository) {
+		url := database.SQLiteScheme + filepath.Join(t.TempDir(), "subs.db")
+		db := openSQLite(t, url)
+		return repository.NewSubRepository(db), repository.NewAnalyticsRepository(db)
+	})
+}
+
+func TestAnalyticsRepository(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+
+	repotest.RunAnalyticsRepository(t, func(t *testing.T) (repository.SubRepository, repository.AnalyticsRepository) {
+		resetSubscriptions(t, db)
+		return repository.NewSubRepository(db), repository.NewAnalyticsRepository(db)
+	})
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testingtask/internal/domain/budget"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository/models"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BudgetRepository хранит месячные бюджеты пользователей.
+type BudgetRepository interface {
+	Create(ctx context.Context, b *budget.Budget) error
+	Get(ctx context.Context, id uuid.UUID) (*budget.Budget, error)
+	// List возвращает страницу бюджетов и их общее число. userID == nil —
+	// бюджеты всех пользователей.
+	List(ctx context.Context, userID *uuid.UUID, paging *domain.PagingBase) ([]*budget.Budget, int64, error)
+	// ForUser возвращает все бюджеты пользователя.
+	ForUser(ctx context.Context, userID uuid.UUID) ([]*budget.Budget, error)
+	// Update меняет лимит и hard_limit, пользователь и область не меняются.
+	Update(ctx context.Context, b *budget.Budget) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// DeleteByUser удаляет бюджеты пользователя и возвращает их число.
+	DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+type budgetRepository struct {
+	DB *gorm.DB
+}
+
+func NewBudgetRepository(db *gorm.DB) BudgetRepository {
+	return &budgetRepository{DB: db}
+}
+
+func budgetToModel(b *budget.Budget) *models.Budget {
+	return &models.Budget{
+		ID:           b.ID(),
+		UserID:       b.UserID(),
+		ServiceName:  b.ServiceName(),
+		Category:     b.Category(),
+		MonthlyLimit: b.MonthlyLimit(),
+		HardLimit:    b.HardLimit(),
+		CreatedAt:    b.CreatedAt().UTC(),
+	}
+}
+
+func budgetToDomain(m *models.Budget) *budget.Budget {
+	return budget.RestoreBudget(m.ID, m.UserID, m.ServiceName, m.Category, m.MonthlyLimit, m.HardLimit, m.CreatedAt)
+}
+
+func budgetsToDomain(rows []*models.Budget) []*budget.Budget {
+	res := make([]*budget.Budget, 0, len(rows))
+	for _, m := range rows {
+		res = append(res, budgetToDomain(m))
+	}
+	return res
+}
+
+func (r *budgetRepository) Create(ctx context.Context, b *budget.Budget) error {
+	if err := conn(ctx, r.DB).Create(budgetToModel(b)).Error; err != nil {
+		logger.Error(ctx, "repo: budget create failed", err, map[string]interface{}{
+			"id":      b.ID(),
+			"user_id": b.UserID(),
+		})
+		return mapError(err, myerrors.ErrDatabase)
+	}
+
+	return nil
+}
+
+func (r *budgetRepository) Get(ctx context.Context, id uuid.UUID) (*budget.Budget, error) {
+	var m models.Budget
+
+	err := conn(ctx, r.DB).First(&m, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, myerrors.ErrBudgetNotFound
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: budget get failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return budgetToDomain(&m), nil
+}
+
+func (r *budgetRepository) List(ctx context.Context, userID *uuid.UUID, paging *domain.PagingBase) ([]*budget.Budget, int64, error) {
+	var (
+		rows  []*models.Budget
+		total int64
+	)
+
+	scope := func(q *gorm.DB) *gorm.DB {
+		q = q.Model(&models.Budget{})
+		if userID != nil {
+			q = q.Where("user_id = ?", *userID)
+		}
+		return q
+	}
+
+	err := scope(conn(ctx, r.DB)).Count(&total).Error
+	if err == nil {
+		err = scope(conn(ctx, r.DB)).
+			Order("created_at, id").
+			Limit(paging.Limit).
+			Offset(paging.Offset).
+			Find(&rows).Error
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: budget list failed", err, map[string]interface{}{
+			"user_id": userID,
+			"paging":  paging,
+		})
+		return nil, 0, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return budgetsToDomain(rows), total, nil
+}
+
+func (r *budgetRepository) ForUser(ctx context.Context, userID uuid.UUID) ([]*budget.Budget, error) {
+	var rows []*models.Budget
+
+	if err := conn(ctx, r.DB).Where("user_id = ?", userID).Order("created_at, id").Find(&rows).Error; err != nil {
+		logger.Error(ctx, "repo: user budgets fetch failed", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return budgetsToDomain(rows), nil
+}
+
+func (r *budgetRepository) Update(ctx context.Context, b *budget.Budget) error {
+	res := conn(ctx, r.DB).
+		Model(&models.Budget{}).
+		Where("id = ?", b.ID()).
+		Updates(map[string]interface{}{
+			"monthly_limit": b.MonthlyLimit(),
+			"hard_limit":    b.HardLimit(),
+			"updated_at":    time.Now().UTC(),
+		})
+	if err := res.Error; err != nil {
+		logger.Error(ctx, "repo: budget update failed", err, map[string]interface{}{
+			"id": b.ID(),
+		})
+		return mapError(err, myerrors.ErrDatabase)
+	}
+	if res.RowsAffected == 0 {
+		return myerrors.ErrBudgetNotFound
+	}
+
+	return nil
+}
+
+func (r *budgetRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := conn(ctx, r.DB).Delete(&models.Budget{}, "id = ?", id).Error; err != nil {
+		logger.Error(ctx, "repo: budget delete failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return mapError(err, myerrors.ErrDatabase)
+	}
+
+	return nil
+}
+
+func (r *budgetRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	res := conn(ctx, r.DB).Delete(&models.Budget{}, "user_id = ?", userID)
+	if res.Error != nil {
+		logger.Error(ctx, "repo: budget delete by user failed", res.Error, map[string]interface{}{
+			"user_id": userID,
+		})
+		return 0, mapError(res.Error, myerrors.ErrDatabase)
+	}
+
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,35 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+	"testingtask/internal/database"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+)
+
+func TestMemoryBudgetRepository(t *testing.T) {
+	repotest.RunBudgetRepository(t, func(t *testing.T) repository.BudgetRepository {
+		return repository.NewMemoryBudgetRepository()
+	})
+}
+
+func TestSQLiteBudgetRepository(t *testing.T) {
+	repotest.RunBudgetRepository(t, func(t *testing.T) repository.BudgetRepository {
+		url := database.SQLiteScheme + filepath.Join(t.TempDir(), "subs.db")
+		return repository.NewBudgetRepository(openSQLite(t, url))
+	})
+}
+
+func TestBudgetRepository(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+
+	repotest.RunBudgetRepository(t, func(t *testing.T) repository.BudgetRepository {
+		if err := db.Exec("TRUNCATE budgets").Error; err != nil {
+			t.Fatal(err)
+		}
+		resetSubscriptions(t, db)
+		return repository.NewBudgetRepository(db)
+	})
+}
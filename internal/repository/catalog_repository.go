@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testingtask/internal/domain/catalog"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository/models"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CatalogRepository хранит каталог сервисов и ключи сопоставления их имени
+// и синонимов. Ключ уникален во всём каталоге: занятый другим сервисом
+// ключ — ErrInvalidData.
+type CatalogRepository interface {
+	Create(ctx context.Context, s *catalog.Service) error
+	Get(ctx context.Context, id uuid.UUID) (*catalog.Service, error)
+	// List возвращает страницу сервисов по имени и их общее число.
+	// category == nil — сервисы всех категорий.
+	List(ctx context.Context, category *string, paging *domain.PagingBase) ([]*catalog.Service, int64, error)
+	// Update заменяет поля и ключи сервиса, дата создания не меняется.
+	Update(ctx context.Context, s *catalog.Service) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Resolve возвращает сервис, к которому сводится название name, или
+	// ErrServiceNotFound.
+	Resolve(ctx context.Context, name string) (*catalog.Service, error)
+	// Categories возвращает категории сервисов по ID; сервисов без
+	// категории в ответе нет.
+	Categories(ctx context.Context) (map[uuid.UUID]string, error)
+}
+
+type catalogRepository struct {
+	DB *gorm.DB
+}
+
+func NewCatalogRepository(db *gorm.DB) CatalogRepository {
+	return &catalogRepository{DB: db}
+}
+
+func serviceToModel(s *catalog.Service) *models.Service {
+	return &models.Service{
+		ID:           s.ID(),
+		Name:         s.Name(),
+		Category:     s.Category(),
+		DefaultPrice: s.DefaultPrice(),
+		Currency:     s.Currency(),
+		LogoURL:      s.LogoURL(),
+		CreatedAt:    s.CreatedAt().UTC(),
+	}
+}
+
+func aliasModels(s *catalog.Service) []models.ServiceAlias {
+	keys := s.Keys()
+	res := make([]models.ServiceAlias, 0, len(keys))
+	for key, alias := range keys {
+		res = append(res, models.ServiceAlias{Key: key, ServiceID: s.ID(), Alias: alias})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	return res
+}
+
+// serviceToDomain собирает сервис; синонимы — написания ключей, кроме
+// ключа имени.
+func serviceToDomain(m *models.Service, aliases []models.ServiceAlias) *catalog.Service {
+	nameKey := catalog.Key(m.Name)
+	list := make([]string, 0, len(aliases))
+	for _, a := range aliases {
+		if a.Key != nameKey {
+			list = append(list, a.Alias)
+		}
+	}
+	sort.Strings(list)
+	return catalog.RestoreService(m.ID, m.Name, list, m.Category, m.DefaultPrice, m.Currency, m.LogoURL, m.CreatedAt)
+}
+
+// withAliases дочитывает синонимы сервисов rows.
+func (r *catalogRepository) withAliases(ctx context.Context, rows []*models.Service) ([]*catalog.Service, error) {
+	res := make([]*catalog.Service, 0, len(rows))
+	if len(rows) == 0 {
+		return res, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, m := range rows {
+		ids = append(ids, m.ID)
+	}
+
+	var aliases []models.ServiceAlias
+	if err := conn(ctx, r.DB).Where("service_id IN ?", ids).Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+	byService := make(map[uuid.UUID][]models.ServiceAlias, len(rows))
+	for _, a := range aliases {
+		byService[a.ServiceID] = append(byService[a.ServiceID], a)
+	}
+
+	for _, m := range rows {
+		res = append(res, serviceToDomain(m, byService[m.ID]))
+	}
+	return res, nil
+}
+
+func (r *catalogRepository) Create(ctx context.Context, s *catalog.Service) error {
+	err := conn(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(serviceToModel(s)).Error; err != nil {
+			return err
+		}
+		aliases := aliasModels(s)
+		return tx.Create(&aliases).Error
+	})
+	if err != nil {
+		logger.Error(ctx, "repo: service create failed", err, map[string]interface{}{
+			"id":   s.ID(),
+			"name": s.Name(),
+		})
+		return mapError(err, myerrors.ErrDatabase)
+	}
+
+	return nil
+}
+
+func (r *catalogRepository) Get(ctx context.Context, id uuid.UUID) (*catalog.Service, error) {
+	var m models.Service
+
+	err := conn(ctx, r.DB).First(&m, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, myerrors.ErrServiceNotFound
+	}
+	var res []*catalog.Service
+	if err == nil {
+		res, err = r.withAliases(ctx, []*models.Service{&m})
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: service get failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return res[0], nil
+}
+
+func (r *catalogRepository) List(ctx context.Context, category *string, paging *domain.PagingBase) ([]*catalog.Service, int64, error) {
+	var (
+		rows  []*models.Service
+		total int64
+		res   []*catalog.Service
+	)
+
+	scope := func(q *gorm.DB) *gorm.DB {
+		q = q.Model(&models.Service{})
+		if category != nil {
+			q = q.Where("category = ?", *category)
+		}
+		return q
+	}
+
+	err := scope(conn(ctx, r.DB)).Count(&total).Error
+	if err == nil {
+		err = scope(conn(ctx, r.DB)).
+			Order("name, id").
+			Limit(paging.Limit).
+			Offset(paging.Offset).
+			Find(&rows).Error
+	}
+	if err == nil {
+		res, err = r.withAliases(ctx, rows)
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: service list failed", err, map[string]interface{}{
+			"category": category,
+			"paging":   paging,
+		})
+		return nil, 0, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return res, total, nil
+}
+
+func (r *catalogRepository) Update(ctx context.Context, s *catalog.Service) error {
+	var affected int64
+
+	err := conn(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Service{}).
+			Where("id = ?", s.ID()).
+			Updates(map[string]interface{}{
+				"name":          s.Name(),
+				"category":      s.Category(),
+				"default_price": s.DefaultPrice(),
+				"currency":      s.Currency(),
+				"logo_url":      s.LogoURL(),
+				"updated_at":    time.Now().UTC(),
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		affected = res.RowsAffected
+
+		if err := tx.Delete(&models.ServiceAlias{}, "service_id = ?", s.ID()).Error; err != nil {
+			return err
+		}
+		aliases := aliasModels(s)
+		return tx.Create(&aliases).Error
+	})
+	if err != nil {
+		logger.Error(ctx, "repo: service update failed", err, map[string]interface{}{
+			"id": s.ID(),
+		})
+		return mapError(err, myerrors.ErrDatabase)
+	}
+	if affected == 0 {
+		return myerrors.ErrServiceNotFound
+	}
+
+	return nil
+}
+
+// Delete удаляет сервис с его ключами. В Postgres подписки отвязывает
+// внешний ключ (ON DELETE SET NULL), в SQLite его нет — это делает
+// вызывающий.
+func (r *catalogRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	err := conn(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.ServiceAlias{}, "service_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Service{}, "id = ?", id).Error
+	})
+	if err != nil {
+		logger.Error(ctx, "repo: service delete failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return mapError(err, myerrors.ErrDatabase)
+	}
+
+	return nil
+}
+
+func (r *catalogRepository) Resolve(ctx context.Context, name string) (*catalog.Service, error) {
+	key := catalog.Key(name)
+	if key == "" {
+		return nil, myerrors.ErrServiceNotFound
+	}
+
+	var alias models.ServiceAlias
+	err := conn(ctx, r.DB).First(&alias, "key = ?", key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, myerrors.ErrServiceNotFound
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: service resolve failed", err, map[string]interface{}{
+			"name": name,
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return r.Get(ctx, alias.ServiceID)
+}
+
+func (r *catalogRepository) Categories(ctx context.Context) (map[uuid.UUID]string, error) {
+	var rows []*models.Service
+
+	if err := conn(ctx, r.DB).Select("id", "category").Where("category IS NOT NULL").Find(&rows).Error; err != nil {
+		logger.Error(ctx, "repo: service categories fetch failed", err, nil)
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	res := make(map[uuid.UUID]string, len(rows))
+	for _, m := range rows {
+		res[m.ID] = *m.Category
+	}
+	return res, nil
+}
@@ -0,0 +1,36 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+	"testingtask/internal/database"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+)
+
+func TestMemoryCatalogRepository(t *testing.T) {
+	repotest.RunCatalogRepository(t, func(t *testing.T) (repository.SubRepository, repository.CatalogRepository) {
+		return repository.NewMemorySubRepository(), repository.NewMemoryCatalogRepository()
+	})
+}
+
+func TestSQLiteCatalogRepository(t *testing.T) {
+	repotest.RunCatalogRepository(t, func(t *testing.T) (repository.SubRepository, repository.CatalogRepository) {
+		url := database.SQLiteScheme + filepath.Join(t.TempDir(), "subs.db")
+		db := openSQLite(t, url)
+		return repository.NewSubRepository(db), repository.NewCatalogRepository(db)
+	})
+}
+
+func TestCatalogRepository(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+
+	repotest.RunCatalogRepository(t, func(t *testing.T) (repository.SubRepository, repository.CatalogRepository) {
+		if err := db.Exec("TRUNCATE subscriptions, services CASCADE").Error; err != nil {
+			t.Fatal(err)
+		}
+		resetSubscriptions(t, db)
+		return repository.NewSubRepository(db), repository.NewCatalogRepository(db)
+	})
+}
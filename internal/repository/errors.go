@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testingtask/internal/domain/catalog"
+	"testingtask/internal/domain/user"
+	myerrors "testingtask/internal/errors"
+
+	"github.com/glebarez/go-sqlite"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Коды ошибок Postgres, которые означают некорректные входные данные.
+// Для SQLite то же значение имеет любой код класса SQLITE_CONSTRAINT.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgCheckViolation      = "23514"
+	pgNotNullViolation    = "23502"
+	pgStringTooLong       = "22001"
+)
+
+// foreignKeys — внешние ключи, по нарушению которых понятно, какое поле
+// ссылается на отсутствующую запись. Нарушения остальных ключей —
+// ErrInvalidData.
+var foreignKeys = map[string]error{
+	"subscriptions_user_id_fkey":        user.ErrUnknownUser,
+	"subscription_members_user_id_fkey": user.ErrUnknownUser,
+	"subscriptions_service_id_fkey":     catalog.ErrUnknownService,
+}
+
+// mapError переводит ошибку GORM/Postgres в ошибку из myerrors.
+// fallback возвращается, если ошибку не удалось классифицировать.
+func mapError(err, fallback error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, pgx.ErrNoRows):
+		return myerrors.ErrNotFound
+	case errors.Is(err, gorm.ErrInvalidData):
+		return myerrors.ErrInvalidData
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return myerrors.ErrDatabase
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if known, ok := foreignKeys[pgErr.ConstraintName]; ok && pgErr.Code == pgForeignKeyViolation {
+			return known
+		}
+		switch pgErr.Code {
+		case pgUniqueViolation, pgForeignKeyViolation, pgCheckViolation, pgNotNullViolation, pgStringTooLong:
+			return myerrors.ErrInvalidData
+		}
+		return myerrors.ErrDatabase
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		// Расширенные коды ограничений (UNIQUE, CHECK, FOREIGN KEY,
+		// NOT NULL, PRIMARY KEY) в младшем байте содержат SQLITE_CONSTRAINT.
+		if sqliteErr.Code()&0xff == sqlite3.SQLITE_CONSTRAINT {
+			return myerrors.ErrInvalidData
+		}
+		return myerrors.ErrDatabase
+	}
+
+	return fallback
+}
+
+// isForeignKeyViolation сообщает, что err — нарушение внешнего ключа.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgForeignKeyViolation
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+	}
+	return false
+}
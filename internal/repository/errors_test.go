@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"testingtask/internal/domain/catalog"
+	"testingtask/internal/domain/user"
+	myerrors "testingtask/internal/errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestMapError(t *testing.T) {
+	fallback := errors.New("fallback")
+	pg := func(code string) error {
+		return fmt.Errorf("exec: %w", &pgconn.PgError{Code: code})
+	}
+	fk := func(constraint string) error {
+		return fmt.Errorf("exec: %w", &pgconn.PgError{Code: "23503", ConstraintName: constraint})
+	}
+
+	cases := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"not found", gorm.ErrRecordNotFound, myerrors.ErrNotFound},
+		{"gorm invalid data", gorm.ErrInvalidData, myerrors.ErrInvalidData},
+		{"unique violation", pg("23505"), myerrors.ErrInvalidData},
+		{"foreign key violation", pg("23503"), myerrors.ErrInvalidData},
+		{"unknown user", fk("subscriptions_user_id_fkey"), user.ErrUnknownUser},
+		{"unknown member", fk("subscription_members_user_id_fkey"), user.ErrUnknownUser},
+		{"unknown service", fk("subscriptions_service_id_fkey"), catalog.ErrUnknownService},
+		{"other foreign key", fk("subscription_members_subscription_id_fkey"), myerrors.ErrInvalidData},
+		{"check violation", pg("23514"), myerrors.ErrInvalidData},
+		{"not null violation", pg("23502"), myerrors.ErrInvalidData},
+		{"string too long", pg("22001"), myerrors.ErrInvalidData},
+		{"other postgres error", pg("40P01"), myerrors.ErrDatabase},
+		{"query canceled", pg("57014"), myerrors.ErrDatabase},
+		{"context canceled", fmt.Errorf("query: %w", context.Canceled), myerrors.ErrDatabase},
+		{"deadline exceeded", context.DeadlineExceeded, myerrors.ErrDatabase},
+		{"unknown", errors.New("boom"), fallback},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mapError(tc.err, fallback); !errors.Is(got, tc.want) {
+				t.Fatalf("mapError(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository/models"
+	"testingtask/internal/scheduler"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobRepository хранит историю запусков планировщика и выдаёт ему
+// блокировки.
+type JobRepository interface {
+	scheduler.Store
+}
+
+// lastRunsQuery выбирает последний запуск каждой задачи. DISTINCT ON
+// нет в SQLite, поэтому через коррелированный подзапрос по индексу
+// (job, started_at).
+const lastRunsQuery = `SELECT * FROM job_runs r
+WHERE r.started_at = (SELECT MAX(p.started_at) FROM job_runs p WHERE p.job = r.job)`
+
+type jobRepository struct {
+	DB *gorm.DB
+	// locks заменяют advisory-блокировки в SQLite: базой пользуется один
+	// процесс.
+	locks *localLocks
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{DB: db, locks: newLocalLocks()}
+}
+
+// TryLock берёт advisory-блокировку Postgres (см. tryAdvisoryLock). Если
+// её соединение оборвётся, Held вернёт ошибку.
+func (r *jobRepository) TryLock(ctx context.Context, key string) (scheduler.Lock, bool, error) {
+	if r.DB.Dialector.Name() == "sqlite" {
+		return r.locks.tryLock(key)
+	}
+
+	lock, ok, err := tryAdvisoryLock(ctx, r.DB, key)
+	if !ok {
+		return nil, ok, err
+	}
+	return lock, true, nil
+}
+
+func (r *jobRepository) StartRun(ctx context.Context, run scheduler.Run) error {
+	m := models.JobRun{
+		ID:        run.ID,
+		Job:       run.Job,
+		Trigger:   run.Trigger,
+		Instance:  run.Instance,
+		Status:    run.Status,
+		StartedAt: run.StartedAt.UTC(),
+	}
+
+	if err := conn(ctx, r.DB).Create(&m).Error; err != nil {
+		logger.Error(ctx, "repo: job run create failed", err, map[string]interface{}{
+			"job": run.Job,
+		})
+		return mapError(err, myerrors.ErrCreateFailed)
+	}
+	return nil
+}
+
+func (r *jobRepository) FinishRun(ctx context.Context, id uuid.UUID, status string, at time.Time, errText *string) error {
+	err := conn(ctx, r.DB).
+		Model(&models.JobRun{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"finished_at": at.UTC(),
+			"error":       errText,
+		}).Error
+	if err != nil {
+		logger.Error(ctx, "repo: job run finish failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return mapError(err, myerrors.ErrUpdateFailed)
+	}
+	return nil
+}
+
+func (r *jobRepository) Runs(ctx context.Context, job string, limit, offset int) ([]scheduler.Run, int64, error) {
+	var (
+		rows  []models.JobRun
+		total int64
+	)
+
+	err := conn(ctx, r.DB).Model(&models.JobRun{}).Where("job = ?", job).Count(&total).Error
+	if err == nil {
+		err = conn(ctx, r.DB).
+			Where("job = ?", job).
+			Order("started_at DESC, id").
+			Limit(limit).
+			Offset(offset).
+			Find(&rows).Error
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: job runs list failed", err, map[string]interface{}{
+			"job": job,
+		})
+		return nil, 0, mapError(err, myerrors.ErrListFailed)
+	}
+
+	res := make([]scheduler.Run, 0, len(rows))
+	for _, m := range rows {
+		res = append(res, jobRunToDomain(m))
+	}
+	return res, total, nil
+}
+
+func (r *jobRepository) LastRuns(ctx context.Context) (map[string]scheduler.Run, error) {
+	var rows []models.JobRun
+
+	if err := conn(ctx, r.DB).Raw(lastRunsQuery).Scan(&rows).Error; err != nil {
+		logger.Error(ctx, "repo: job last runs failed", err, nil)
+		return nil, mapError(err, myerrors.ErrListFailed)
+	}
+
+	res := make(map[string]scheduler.Run, len(rows))
+	for _, m := range rows {
+		res[m.Job] = jobRunToDomain(m)
+	}
+	return res, nil
+}
+
+func (r *jobRepository) PurgeRuns(ctx context.Context, before time.Time) (int64, error) {
+	res := conn(ctx, r.DB).
+		Where("started_at < ? AND status <> ?", before.UTC(), scheduler.StatusRunning).
+		Delete(&models.JobRun{})
+	if err := res.Error; err != nil {
+		logger.Error(ctx, "repo: job runs purge failed", err, nil)
+		return 0, mapError(err, myerrors.ErrDeleteFailed)
+	}
+	return res.RowsAffected, nil
+}
+
+func jobRunToDomain(m models.JobRun) scheduler.Run {
+	return scheduler.Run{
+		ID:         m.ID,
+		Job:        m.Job,
+		Trigger:    m.Trigger,
+		Instance:   m.Instance,
+		Status:     m.Status,
+		StartedAt:  m.StartedAt,
+		FinishedAt: m.FinishedAt,
+		Error:      m.Error,
+	}
+}
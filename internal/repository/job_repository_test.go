@@ -0,0 +1,34 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+	"testingtask/internal/database"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+)
+
+func TestMemoryJobRepository(t *testing.T) {
+	repotest.RunJobRepository(t, func(t *testing.T) repository.JobRepository {
+		return repository.NewMemoryJobRepository()
+	})
+}
+
+func TestSQLiteJobRepository(t *testing.T) {
+	repotest.RunJobRepository(t, func(t *testing.T) repository.JobRepository {
+		url := database.SQLiteScheme + filepath.Join(t.TempDir(), "subs.db")
+		return repository.NewJobRepository(openSQLite(t, url))
+	})
+}
+
+func TestJobRepository(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+
+	repotest.RunJobRepository(t, func(t *testing.T) repository.JobRepository {
+		if err := db.Exec("TRUNCATE job_runs").Error; err != nil {
+			t.Fatal(err)
+		}
+		return repository.NewJobRepository(db)
+	})
+}
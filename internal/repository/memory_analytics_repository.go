@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"testingtask/internal/domain/analytics"
+	domain "testingtask/internal/domain/subscription"
+	"time"
+)
+
+// memoryAnalyticsRepository считает выручку по подпискам из SubRepository
+// для DATABASE_URL=memory:// и тестов.
+type memoryAnalyticsRepository struct {
+	subs SubRepository
+}
+
+func NewMemoryAnalyticsRepository(subs SubRepository) AnalyticsRepository {
+	return &memoryAnalyticsRepository{subs: subs}
+}
+
+func (r *memoryAnalyticsRepository) Revenue(ctx context.Context, months []time.Time) ([]analytics.Month, error) {
+	if len(months) == 0 {
+		return nil, nil
+	}
+
+	res, err := r.subs.Sum(ctx, &domain.SubscriptionFilter{Limit: -1})
+	if err != nil {
+		return nil, err
+	}
+	return analytics.Aggregate(res.Rows, months), nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testingtask/internal/domain/budget"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	logger "testingtask/pkg"
+
+	"github.com/google/uuid"
+)
+
+// memoryBudgetRepository — BudgetRepository в памяти для
+// DATABASE_URL=memory:// и тестов.
+type memoryBudgetRepository struct {
+	mu      sync.Mutex
+	budgets map[uuid.UUID]*budget.Budget
+}
+
+func NewMemoryBudgetRepository() BudgetRepository {
+	return &memoryBudgetRepository{budgets: make(map[uuid.UUID]*budget.Budget)}
+}
+
+// sorted возвращает бюджеты, подходящие под match, в порядке
+// created_at, id. Вызывается под r.mu.
+func (r *memoryBudgetRepository) sorted(match func(*budget.Budget) bool) []*budget.Budget {
+	res := make([]*budget.Budget, 0, len(r.budgets))
+	for _, b := range r.budgets {
+		if match(b) {
+			res = append(res, b)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].CreatedAt().Equal(res[j].CreatedAt()) {
+			return res[i].CreatedAt().Before(res[j].CreatedAt())
+		}
+		return res[i].ID().String() < res[j].ID().String()
+	})
+	return res
+}
+
+func (r *memoryBudgetRepository) Create(ctx context.Context, b *budget.Budget) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: budget create failed", err, map[string]interface{}{
+			"id":      b.ID(),
+			"user_id": b.UserID(),
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Как PRIMARY KEY и budgets_user_scope_idx в таблице.
+	for _, o := range r.budgets {
+		if o.ID() == b.ID() || o.SameScope(b) {
+			return myerrors.ErrInvalidData
+		}
+	}
+	r.budgets[b.ID()] = b
+	return nil
+}
+
+func (r *memoryBudgetRepository) Get(ctx context.Context, id uuid.UUID) (*budget.Budget, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: budget get failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.budgets[id]
+	if !ok {
+		return nil, myerrors.ErrBudgetNotFound
+	}
+	return b, nil
+}
+
+func (r *memoryBudgetRepository) List(ctx context.Context, userID *uuid.UUID, paging *domain.PagingBase) ([]*budget.Budget, int64, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: budget list failed", err, map[string]interface{}{
+			"user_id": userID,
+			"paging":  paging,
+		})
+		return nil, 0, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := r.sorted(func(b *budget.Budget) bool { return userID == nil || b.UserID() == *userID })
+	return page(all, paging.Limit, paging.Offset), int64(len(all)), nil
+}
+
+func (r *memoryBudgetRepository) ForUser(ctx context.Context, userID uuid.UUID) ([]*budget.Budget, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: user budgets fetch failed", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.sorted(func(b *budget.Budget) bool { return b.UserID() == userID }), nil
+}
+
+func (r *memoryBudgetRepository) Update(ctx context.Context, b *budget.Budget) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: budget update failed", err, map[string]interface{}{
+			"id": b.ID(),
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.budgets[b.ID()]
+	if !ok {
+		return myerrors.ErrBudgetNotFound
+	}
+	// Пользователь, область и дата создания не меняются, как и в таблице.
+	r.budgets[b.ID()] = budget.RestoreBudget(old.ID(), old.UserID(), old.ServiceName(), old.Category(), b.MonthlyLimit(), b.HardLimit(), old.CreatedAt())
+	return nil
+}
+
+func (r *memoryBudgetRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: budget delete failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.budgets, id)
+	return nil
+}
+
+func (r *memoryBudgetRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: budget delete by user failed", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return 0, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for id, b := range r.budgets {
+		if b.UserID() == userID {
+			delete(r.budgets, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testingtask/internal/domain/catalog"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	logger "testingtask/pkg"
+
+	"github.com/google/uuid"
+)
+
+// memoryCatalogRepository — CatalogRepository в памяти для
+// DATABASE_URL=memory:// и тестов.
+type memoryCatalogRepository struct {
+	mu       sync.Mutex
+	services map[uuid.UUID]*catalog.Service
+	// keys — ключ сопоставления → ID сервиса, как service_aliases.
+	keys map[string]uuid.UUID
+}
+
+func NewMemoryCatalogRepository() CatalogRepository {
+	return &memoryCatalogRepository{
+		services: make(map[uuid.UUID]*catalog.Service),
+		keys:     make(map[string]uuid.UUID),
+	}
+}
+
+// claimKeys занимает ключи сервиса s, если ни один из них не принадлежит
+// другому сервису. Вызывается под r.mu.
+func (r *memoryCatalogRepository) claimKeys(s *catalog.Service) error {
+	keys := s.Keys()
+	for key := range keys {
+		if owner, ok := r.keys[key]; ok && owner != s.ID() {
+			return myerrors.ErrInvalidData
+		}
+	}
+	for key := range keys {
+		r.keys[key] = s.ID()
+	}
+	return nil
+}
+
+// releaseKeys освобождает ключи сервиса id. Вызывается под r.mu.
+func (r *memoryCatalogRepository) releaseKeys(id uuid.UUID) {
+	for key, owner := range r.keys {
+		if owner == id {
+			delete(r.keys, key)
+		}
+	}
+}
+
+func (r *memoryCatalogRepository) Create(ctx context.Context, s *catalog.Service) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: service create failed", err, map[string]interface{}{
+			"id":   s.ID(),
+			"name": s.Name(),
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.services[s.ID()]; ok {
+		return myerrors.ErrInvalidData
+	}
+	if err := r.claimKeys(s); err != nil {
+		return err
+	}
+	r.services[s.ID()] = s
+	return nil
+}
+
+func (r *memoryCatalogRepository) Get(ctx context.Context, id uuid.UUID) (*catalog.Service, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: service get failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.services[id]
+	if !ok {
+		return nil, myerrors.ErrServiceNotFound
+	}
+	return s, nil
+}
+
+func (r *memoryCatalogRepository) List(ctx context.Context, category *string, paging *domain.PagingBase) ([]*catalog.Service, int64, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: service list failed", err, map[string]interface{}{
+			"category": category,
+			"paging":   paging,
+		})
+		return nil, 0, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := make([]*catalog.Service, 0, len(r.services))
+	for _, s := range r.services {
+		if category == nil || (s.Category() != nil && *s.Category() == *category) {
+			all = append(all, s)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Name() != all[j].Name() {
+			return all[i].Name() < all[j].Name()
+		}
+		return all[i].ID().String() < all[j].ID().String()
+	})
+	return page(all, paging.Limit, paging.Offset), int64(len(all)), nil
+}
+
+func (r *memoryCatalogRepository) Update(ctx context.Context, s *catalog.Service) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: service update failed", err, map[string]interface{}{
+			"id": s.ID(),
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.services[s.ID()]
+	if !ok {
+		return myerrors.ErrServiceNotFound
+	}
+
+	r.releaseKeys(s.ID())
+	if err := r.claimKeys(s); err != nil {
+		// Как откат транзакции: прежние ключи остаются за сервисом.
+		_ = r.claimKeys(old)
+		return err
+	}
+	r.services[s.ID()] = catalog.RestoreService(s.ID(), s.Name(), s.Aliases(), s.Category(),
+		s.DefaultPrice(), s.Currency(), s.LogoURL(), old.CreatedAt())
+	return nil
+}
+
+func (r *memoryCatalogRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: service delete failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.releaseKeys(id)
+	delete(r.services, id)
+	return nil
+}
+
+func (r *memoryCatalogRepository) Resolve(ctx context.Context, name string) (*catalog.Service, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: service resolve failed", err, map[string]interface{}{
+			"name": name,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := r.keys[catalog.Key(name)]
+	if !ok {
+		return nil, myerrors.ErrServiceNotFound
+	}
+	return r.services[id], nil
+}
+
+func (r *memoryCatalogRepository) Categories(ctx context.Context) (map[uuid.UUID]string, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: service categories fetch failed", err, nil)
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make(map[uuid.UUID]string, len(r.services))
+	for id, s := range r.services {
+		if s.Category() != nil {
+			res[id] = *s.Category()
+		}
+	}
+	return res, nil
+}
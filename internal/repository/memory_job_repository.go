@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testingtask/internal/scheduler"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// localLocks — блокировки планировщика в пределах процесса.
+type localLocks struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+func newLocalLocks() *localLocks {
+	return &localLocks{held: make(map[string]bool)}
+}
+
+func (l *localLocks) tryLock(key string) (scheduler.Lock, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.held[key] {
+		return nil, false, nil
+	}
+	l.held[key] = true
+	return &localLock{locks: l, key: key}, true, nil
+}
+
+type localLock struct {
+	locks *localLocks
+	key   string
+}
+
+func (l *localLock) Held(context.Context) error { return nil }
+
+func (l *localLock) Unlock() {
+	l.locks.mu.Lock()
+	defer l.locks.mu.Unlock()
+	delete(l.locks.held, l.key)
+}
+
+// memoryJobRepository — JobRepository в памяти для DATABASE_URL=memory://
+// и тестов. История живёт до перезапуска.
+type memoryJobRepository struct {
+	locks *localLocks
+
+	mu   sync.Mutex
+	runs []scheduler.Run
+}
+
+func NewMemoryJobRepository() JobRepository {
+	return &memoryJobRepository{locks: newLocalLocks()}
+}
+
+func (r *memoryJobRepository) TryLock(_ context.Context, key string) (scheduler.Lock, bool, error) {
+	return r.locks.tryLock(key)
+}
+
+func (r *memoryJobRepository) StartRun(_ context.Context, run scheduler.Run) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+func (r *memoryJobRepository) FinishRun(_ context.Context, id uuid.UUID, status string, at time.Time, errText *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.runs {
+		if r.runs[i].ID == id {
+			r.runs[i].Status = status
+			r.runs[i].FinishedAt = &at
+			r.runs[i].Error = errText
+		}
+	}
+	return nil
+}
+
+func (r *memoryJobRepository) Runs(_ context.Context, job string, limit, offset int) ([]scheduler.Run, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var rows []scheduler.Run
+	for _, run := range r.runs {
+		if run.Job == job {
+			rows = append(rows, run)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].StartedAt.After(rows[j].StartedAt) })
+	return page(rows, limit, offset), int64(len(rows)), nil
+}
+
+func (r *memoryJobRepository) LastRuns(context.Context) (map[string]scheduler.Run, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make(map[string]scheduler.Run)
+	for _, run := range r.runs {
+		if last, ok := res[run.Job]; !ok || run.StartedAt.After(last.StartedAt) {
+			res[run.Job] = run
+		}
+	}
+	return res, nil
+}
+
+func (r *memoryJobRepository) PurgeRuns(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.runs[:0]
+	for _, run := range r.runs {
+		if run.StartedAt.Before(before) && run.Status != scheduler.StatusRunning {
+			continue
+		}
+		kept = append(kept, run)
+	}
+	purged := int64(len(r.runs) - len(kept))
+	r.runs = kept
+	return purged, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/outbox"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryOutboxRow struct {
+	event       outbox.Event
+	nextAttempt time.Time
+	publishedAt *time.Time
+}
+
+// memoryOutboxRepository — OutboxRepository в памяти для
+// DATABASE_URL=memory:// и тестов. Транзакций нет, событие сохраняется
+// сразу.
+type memoryOutboxRepository struct {
+	mu   sync.Mutex
+	lock sync.Mutex
+	rows []*memoryOutboxRow
+	ids  map[uuid.UUID]*memoryOutboxRow
+}
+
+func NewMemoryOutboxRepository() OutboxRepository {
+	return &memoryOutboxRepository{ids: make(map[uuid.UUID]*memoryOutboxRow)}
+}
+
+func (r *memoryOutboxRepository) Add(ctx context.Context, events ...outbox.Event) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: outbox add failed", err, map[string]interface{}{
+			"events": len(events),
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range events {
+		if _, ok := r.ids[e.ID]; ok {
+			continue
+		}
+		e.OccurredAt = e.OccurredAt.UTC()
+		row := &memoryOutboxRow{event: e, nextAttempt: e.OccurredAt}
+		r.rows = append(r.rows, row)
+		r.ids[e.ID] = row
+	}
+
+	return nil
+}
+
+func (r *memoryOutboxRepository) Pending(ctx context.Context, now time.Time, limit int) ([]outbox.Event, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: outbox pending fetch failed", err, nil)
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var res []outbox.Event
+	waiting := map[uuid.UUID]bool{}
+	for _, row := range r.rows {
+		if len(res) == limit {
+			break
+		}
+		if row.publishedAt != nil {
+			continue
+		}
+
+		agg := row.event.AggregateID
+		if row.nextAttempt.After(now) {
+			waiting[agg] = true
+			continue
+		}
+		if !waiting[agg] {
+			res = append(res, row.event)
+		}
+	}
+
+	return res, nil
+}
+
+func (r *memoryOutboxRepository) MarkPublished(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row, ok := r.ids[id]; ok {
+		at = at.UTC()
+		row.publishedAt = &at
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, next time.Time, _ string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row, ok := r.ids[id]; ok {
+		row.event.Attempts++
+		row.nextAttempt = next
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepository) TryLock(context.Context) (func(), bool, error) {
+	if !r.lock.TryLock() {
+		return nil, false, nil
+	}
+	return r.lock.Unlock, true, nil
+}
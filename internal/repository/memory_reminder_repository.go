@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testingtask/internal/domain/reminder"
+	myerrors "testingtask/internal/errors"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryNotification struct {
+	claimedUntil time.Time
+	sent         bool
+}
+
+// memoryReminderRepository — ReminderRepository в памяти для
+// DATABASE_URL=memory:// и тестов. Журнал живёт до перезапуска.
+type memoryReminderRepository struct {
+	mu    sync.Mutex
+	prefs map[uuid.UUID]*reminder.Preferences
+	log   map[uuid.UUID]*memoryNotification
+}
+
+func NewMemoryReminderRepository() ReminderRepository {
+	return &memoryReminderRepository{
+		prefs: make(map[uuid.UUID]*reminder.Preferences),
+		log:   make(map[uuid.UUID]*memoryNotification),
+	}
+}
+
+func (r *memoryReminderRepository) GetPreferences(ctx context.Context, userID uuid.UUID) (*reminder.Preferences, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: reminder preferences get failed", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.prefs[userID]
+	if !ok {
+		return nil, myerrors.ErrPreferencesNotFound
+	}
+	return p, nil
+}
+
+func (r *memoryReminderRepository) SavePreferences(ctx context.Context, p *reminder.Preferences) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: reminder preferences save failed", err, map[string]interface{}{
+			"user_id": p.UserID(),
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prefs[p.UserID()] = p
+	return nil
+}
+
+func (r *memoryReminderRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: reminder preferences delete failed", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return 0, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.prefs[userID]; !ok {
+		return 0, nil
+	}
+	delete(r.prefs, userID)
+	return 1, nil
+}
+
+func (r *memoryReminderRepository) Claim(ctx context.Context, n reminder.Notification, now time.Time, lease time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: reminder claim failed", err, map[string]interface{}{
+			"id": n.ID,
+		})
+		return false, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.log[n.ID]
+	if !ok {
+		r.log[n.ID] = &memoryNotification{claimedUntil: now.Add(lease)}
+		return true, nil
+	}
+	if row.sent || row.claimedUntil.After(now) {
+		return false, nil
+	}
+	row.claimedUntil = now.Add(lease)
+	return true, nil
+}
+
+func (r *memoryReminderRepository) MarkSent(ctx context.Context, id uuid.UUID, _ time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row, ok := r.log[id]; ok {
+		row.sent = true
+	}
+	return nil
+}
+
+func (r *memoryReminderRepository) Release(ctx context.Context, id uuid.UUID, retryAt time.Time, _ string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if row, ok := r.log[id]; ok && !row.sent {
+		row.claimedUntil = retryAt
+	}
+	return nil
+}
@@ -0,0 +1,435 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository/models"
+	logger "testingtask/pkg"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Ограничения таблицы subscriptions, которые проверяет Postgres.
+const maxServiceNameLength = 100
+
+type memoryRow struct {
+	seq   uint64
+	model models.Subscription
+}
+
+// memorySubRepository — потокобезопасная реализация SubRepository в памяти
+// для тестов и локальной разработки (DATABASE_URL=memory://). Повторяет
+// поведение Postgres-реализации: порядок выдачи, фильтры Sum, ошибки.
+type memorySubRepository struct {
+	mu   sync.RWMutex
+	seq  uint64
+	rows map[uuid.UUID]*memoryRow
+	// splits — участники общих подписок, как subscription_members.
+	splits map[uuid.UUID]*domain.Split
+}
+
+func NewMemorySubRepository() SubRepository {
+	return &memorySubRepository{
+		rows:   make(map[uuid.UUID]*memoryRow),
+		splits: make(map[uuid.UUID]*domain.Split),
+	}
+}
+
+func (s *memorySubRepository) Create(ctx context.Context, sub *domain.Subscription) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: subscription create failed", err, map[string]interface{}{
+			"data": sub,
+		})
+		return myerrors.ErrDatabase
+	}
+
+	m := models.FromDomain(sub)
+	if err := checkConstraints(m); err != nil {
+		logger.Error(ctx, "repo: subscription create failed", err, map[string]interface{}{
+			"data": sub,
+		})
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.rows[m.ID]; ok {
+		logger.Error(ctx, "repo: subscription create failed", myerrors.ErrInvalidData, map[string]interface{}{
+			"data": sub,
+		})
+		return myerrors.ErrInvalidData
+	}
+
+	now := time.Now().UTC()
+	m.CreatedAt, m.UpdatedAt = now, now
+
+	s.seq++
+	s.rows[m.ID] = &memoryRow{seq: s.seq, model: *m}
+
+	return nil
+}
+
+func (s *memorySubRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: subscription get failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	row, ok := s.rows[id]
+	if !ok {
+		logger.Error(ctx, "repo: subscription get failed", myerrors.ErrNotFound, map[string]interface{}{
+			"id": id,
+		})
+		return nil, myerrors.ErrNotFound
+	}
+
+	return models.ToDomain(&row.model)
+}
+
+// GetForUpdate равен Get: транзакций в памяти нет, каждый вызов атомарен.
+func (s *memorySubRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	return s.Get(ctx, id)
+}
+
+func (s *memorySubRepository) List(ctx context.Context, labels domain.LabelFilter, paging *domain.PagingBase) ([]*domain.Subscription, int64, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: subscription list failed", err, map[string]interface{}{
+			"labels": labels,
+			"paging": paging,
+		})
+		return nil, 0, myerrors.ErrDatabase
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows := s.sorted(func(m *models.Subscription) bool { return matchLabels(m, labels) })
+
+	subs, err := models.ToDomains(page(rows, paging.Limit, paging.Offset))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return subs, int64(len(rows)), nil
+}
+
+func (s *memorySubRepository) Sum(ctx context.Context, filter *domain.SubscriptionFilter) (*domain.SumResult, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: subscription sum failed", err, map[string]interface{}{
+			"filter": filter,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows := s.sorted(func(m *models.Subscription) bool { return matchFilter(m, s.splits[m.ID], filter) })
+
+	sum := 0
+	for _, m := range rows {
+		sum += s.amount(m.ID, m.Price, filter.UserID)
+	}
+
+	res, err := models.ToDomains(page(rows, filter.Limit, filter.Offset))
+	if err != nil {
+		return nil, myerrors.ErrDatabase
+	}
+	if filter.UserID != nil {
+		for _, sub := range res {
+			if split := s.splits[sub.ID()]; split != nil {
+				share, _ := split.ShareOf(*filter.UserID)
+				sub.RestoreShare(&share)
+			}
+		}
+	}
+
+	return domain.NewSumResult(res, sum, len(rows)), nil
+}
+
+func (s *memorySubRepository) Count(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: subscription count failed", err, nil)
+		return 0, myerrors.ErrDatabase
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.rows)), nil
+}
+
+func (s *memorySubRepository) Update(ctx context.Context, sub *domain.Subscription) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: subscription update failed", err, map[string]interface{}{
+			"data": sub,
+		})
+		return myerrors.ErrDatabase
+	}
+
+	m := models.FromDomain(sub)
+	if err := checkConstraints(m); err != nil {
+		logger.Error(ctx, "repo: subscription update failed", err, map[string]interface{}{
+			"data": sub,
+		})
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Как и UPDATE ... WHERE id = ?, отсутствующая запись не ошибка.
+	row, ok := s.rows[m.ID]
+	if !ok {
+		return nil
+	}
+
+	m.CreatedAt = row.model.CreatedAt
+	m.UpdatedAt = time.Now().UTC()
+	row.model = *m
+
+	return nil
+}
+
+func (s *memorySubRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: subscription delete failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return myerrors.ErrDatabase
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.rows, id)
+	delete(s.splits, id)
+
+	return nil
+}
+
+func (s *memorySubRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: user subscriptions delete failed", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := s.sorted(func(m *models.Subscription) bool { return m.UserID == userID })
+	for _, m := range deleted {
+		delete(s.rows, m.ID)
+		delete(s.splits, m.ID)
+	}
+
+	return models.ToDomains(deleted)
+}
+
+func (s *memorySubRepository) ListEnded(ctx context.Context, month domain.SubDate) ([]*domain.Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: ended subscriptions fetch failed", err, map[string]interface{}{
+			"month": month.Format("01-2006"),
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	next := month.AddDate(0, 1, 0)
+	return models.ToDomains(s.sorted(func(m *models.Subscription) bool {
+		return m.EndDate != nil && !m.EndDate.Before(month.Time) && m.EndDate.Before(next)
+	}))
+}
+
+func (s *memorySubRepository) ListByService(ctx context.Context, serviceID uuid.UUID) ([]*domain.Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: service subscriptions fetch failed", err, map[string]interface{}{
+			"service_id": serviceID,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return models.ToDomains(s.sorted(func(m *models.Subscription) bool {
+		return m.ServiceID != nil && *m.ServiceID == serviceID
+	}))
+}
+
+func (s *memorySubRepository) Split(ctx context.Context, id uuid.UUID) (*domain.Split, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: subscription members fetch failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.rows[id]; !ok {
+		return nil, myerrors.ErrNotFound
+	}
+	return copySplit(s.splits[id]), nil
+}
+
+func (s *memorySubRepository) SaveSplit(ctx context.Context, id uuid.UUID, split *domain.Split) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: subscription members save failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return myerrors.ErrDatabase
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if split == nil {
+		delete(s.splits, id)
+		return nil
+	}
+	// Как и внешний ключ subscription_members.
+	if _, ok := s.rows[id]; !ok {
+		return myerrors.ErrInvalidData
+	}
+	s.splits[id] = copySplit(split)
+
+	return nil
+}
+
+func (s *memorySubRepository) ListSharedWith(ctx context.Context, userID uuid.UUID) ([]*domain.Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: shared subscriptions fetch failed", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return models.ToDomains(s.sorted(func(m *models.Subscription) bool {
+		split := s.splits[m.ID]
+		if split == nil || m.UserID == userID {
+			return false
+		}
+		_, ok := split.ShareOf(userID)
+		return ok
+	}))
+}
+
+// amount — сколько подписка id стоит пользователю фильтра userID: его
+// доля в общей подписке, иначе цена.
+func (s *memorySubRepository) amount(id uuid.UUID, price int, userID *uuid.UUID) int {
+	if split := s.splits[id]; split != nil && userID != nil {
+		share, _ := split.ShareOf(*userID)
+		return share
+	}
+	return price
+}
+
+func copySplit(split *domain.Split) *domain.Split {
+	if split == nil {
+		return nil
+	}
+	return &domain.Split{Rule: split.Rule, Members: append([]domain.Member(nil), split.Members...)}
+}
+
+// sorted возвращает копии подходящих строк в порядке вставки, как их
+// отдаёт Postgres-реализация (ORDER BY created_at, id).
+func (s *memorySubRepository) sorted(match func(*models.Subscription) bool) []*models.Subscription {
+	rows := make([]*memoryRow, 0, len(s.rows))
+	for _, row := range s.rows {
+		if match(&row.model) {
+			rows = append(rows, row)
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].seq < rows[j].seq })
+
+	res := make([]*models.Subscription, 0, len(rows))
+	for _, row := range rows {
+		m := row.model
+		res = append(res, &m)
+	}
+	return res
+}
+
+// matchFilter повторяет условия WHERE из subRepository.Sum. Подписки без
+// даты окончания не попадают под фильтр по end_date (NULL <= ? ложно).
+// Общая подписка split принадлежит всем участникам.
+func matchFilter(m *models.Subscription, split *domain.Split, f *domain.SubscriptionFilter) bool {
+	if f.UserID != nil && split == nil && m.UserID != *f.UserID {
+		return false
+	}
+	if f.UserID != nil && split != nil {
+		if _, ok := split.ShareOf(*f.UserID); !ok {
+			return false
+		}
+	}
+	if f.ServiceName != nil && m.ServiceName != *f.ServiceName {
+		return false
+	}
+	if f.StartDate != nil && m.StartDate.Before(f.StartDate.Time) {
+		return false
+	}
+	if f.EndDate != nil && (m.EndDate == nil || m.EndDate.After(f.EndDate.Time)) {
+		return false
+	}
+	return matchLabels(m, f.Labels)
+}
+
+// matchLabels повторяет условия фильтра по тегам и metadata.
+func matchLabels(m *models.Subscription, f domain.LabelFilter) bool {
+	if f.Empty() {
+		return true
+	}
+	tags, metadata, err := m.Labels()
+	return err == nil && f.Match(tags, metadata)
+}
+
+// page применяет LIMIT/OFFSET с семантикой GORM: отрицательный limit
+// снимает ограничение, отрицательный offset равен нулю.
+func page[T any](rows []T, limit, offset int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(rows) {
+		return nil
+	}
+	rows = rows[offset:]
+
+	if limit >= 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// checkConstraints проверяет то же, что CHECK/VARCHAR в миграции.
+func checkConstraints(m *models.Subscription) error {
+	switch {
+	case m.Price <= 0:
+		return myerrors.ErrInvalidData
+	case utf8.RuneCountInString(m.ServiceName) > maxServiceNameLength:
+		return myerrors.ErrInvalidData
+	case m.EndDate != nil && m.EndDate.Before(m.StartDate):
+		return myerrors.ErrInvalidData
+	}
+	return nil
+}
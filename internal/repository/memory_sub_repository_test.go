@@ -0,0 +1,48 @@
+package repository_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMemorySubRepository(t *testing.T) {
+	repotest.RunSubRepository(t, func(t *testing.T) repository.SubRepository {
+		return repository.NewMemorySubRepository()
+	})
+}
+
+func TestMemorySubRepositoryConcurrent(t *testing.T) {
+	r := repository.NewMemorySubRepository()
+	ctx := context.Background()
+	user := uuid.New()
+	start := domain.NewSubDateFromTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := repotest.Sub(user, "Netflix", 100, start, nil)
+			if err := r.Create(ctx, s); err != nil {
+				t.Error(err)
+				return
+			}
+			_, _, _ = r.List(ctx, domain.LabelFilter{}, domain.NewPagingBase(10, 0))
+			_, _ = r.Get(ctx, s.ID())
+		}()
+	}
+	wg.Wait()
+
+	filter, _ := domain.NewSubscriptionFilter(&user, nil, nil, nil, 10, 0)
+	res, err := r.Sum(ctx, filter)
+	if err != nil || res.TotalSum != 5000 {
+		t.Fatalf("Sum = %+v, %v, want 5000", res, err)
+	}
+}
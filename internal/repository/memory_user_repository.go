@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/user"
+	myerrors "testingtask/internal/errors"
+	logger "testingtask/pkg"
+
+	"github.com/google/uuid"
+)
+
+// memoryUserRepository — UserRepository в памяти для DATABASE_URL=memory://
+// и тестов.
+type memoryUserRepository struct {
+	mu    sync.Mutex
+	users map[uuid.UUID]*user.User
+}
+
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: make(map[uuid.UUID]*user.User)}
+}
+
+// emailTaken сообщает, занят ли email пользователя u другим пользователем.
+// Вызывается под r.mu.
+func (r *memoryUserRepository) emailTaken(u *user.User) bool {
+	if u.Email() == nil {
+		return false
+	}
+	for id, other := range r.users {
+		if id != u.ID() && other.Email() != nil && *other.Email() == *u.Email() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, u *user.User) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: user create failed", err, map[string]interface{}{
+			"id": u.ID(),
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[u.ID()]; ok || r.emailTaken(u) {
+		return myerrors.ErrInvalidData
+	}
+	r.users[u.ID()] = u
+	return nil
+}
+
+func (r *memoryUserRepository) Get(ctx context.Context, id uuid.UUID) (*user.User, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: user get failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, myerrors.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (r *memoryUserRepository) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: user get failed", err, map[string]interface{}{
+			"email": email,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.users {
+		if u.Email() != nil && *u.Email() == email {
+			return u, nil
+		}
+	}
+	return nil, myerrors.ErrUserNotFound
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, paging *domain.PagingBase) ([]*user.User, int64, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: user list failed", err, map[string]interface{}{
+			"paging": paging,
+		})
+		return nil, 0, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := make([]*user.User, 0, len(r.users))
+	for _, u := range r.users {
+		all = append(all, u)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].DisplayName() != all[j].DisplayName() {
+			return all[i].DisplayName() < all[j].DisplayName()
+		}
+		return all[i].ID().String() < all[j].ID().String()
+	})
+	return page(all, paging.Limit, paging.Offset), int64(len(all)), nil
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, u *user.User) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: user update failed", err, map[string]interface{}{
+			"id": u.ID(),
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.users[u.ID()]
+	if !ok {
+		return myerrors.ErrUserNotFound
+	}
+	if r.emailTaken(u) {
+		return myerrors.ErrInvalidData
+	}
+	r.users[u.ID()] = user.RestoreUser(u.ID(), u.DisplayName(), u.Email(), u.Currency(), u.Timezone(), old.CreatedAt())
+	return nil
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: user delete failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return myerrors.ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+func (r *memoryUserRepository) Missing(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: user lookup failed", err, map[string]interface{}{
+			"ids": ids,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := r.users[id]; ok {
+			found = append(found, id)
+		}
+	}
+	return missingIDs(ids, found), nil
+}
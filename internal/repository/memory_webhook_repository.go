@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/webhook"
+	myerrors "testingtask/internal/errors"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryWebhookRepository — WebhookRepository в памяти для
+// DATABASE_URL=memory:// и тестов.
+type memoryWebhookRepository struct {
+	mu         sync.Mutex
+	endpoints  map[uuid.UUID]*webhook.Endpoint
+	deliveries map[uuid.UUID]*webhook.Delivery
+	// order — ID доставок в порядке добавления.
+	order []uuid.UUID
+}
+
+func NewMemoryWebhookRepository() WebhookRepository {
+	return &memoryWebhookRepository{
+		endpoints:  make(map[uuid.UUID]*webhook.Endpoint),
+		deliveries: make(map[uuid.UUID]*webhook.Delivery),
+	}
+}
+
+func (r *memoryWebhookRepository) CreateEndpoint(ctx context.Context, e *webhook.Endpoint) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: webhook create failed", err, map[string]interface{}{
+			"id": e.ID(),
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.endpoints[e.ID()]; ok {
+		return myerrors.ErrInvalidData
+	}
+	r.endpoints[e.ID()] = e
+	return nil
+}
+
+func (r *memoryWebhookRepository) GetEndpoint(ctx context.Context, id uuid.UUID) (*webhook.Endpoint, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: webhook get failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.endpoints[id]
+	if !ok {
+		return nil, myerrors.ErrWebhookNotFound
+	}
+	return e, nil
+}
+
+// sortedEndpoints возвращает endpoint'ы в порядке created_at, id.
+// Вызывается под r.mu.
+func (r *memoryWebhookRepository) sortedEndpoints() []*webhook.Endpoint {
+	res := make([]*webhook.Endpoint, 0, len(r.endpoints))
+	for _, e := range r.endpoints {
+		res = append(res, e)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].CreatedAt().Equal(res[j].CreatedAt()) {
+			return res[i].CreatedAt().Before(res[j].CreatedAt())
+		}
+		return res[i].ID().String() < res[j].ID().String()
+	})
+	return res
+}
+
+func (r *memoryWebhookRepository) ListEndpoints(ctx context.Context, paging *domain.PagingBase) ([]*webhook.Endpoint, int64, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: webhook list failed", err, map[string]interface{}{
+			"paging": paging,
+		})
+		return nil, 0, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := r.sortedEndpoints()
+	return page(all, paging.Limit, paging.Offset), int64(len(all)), nil
+}
+
+func (r *memoryWebhookRepository) UpdateEndpoint(ctx context.Context, e *webhook.Endpoint) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: webhook update failed", err, map[string]interface{}{
+			"id": e.ID(),
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.endpoints[e.ID()]
+	if !ok {
+		return myerrors.ErrWebhookNotFound
+	}
+	// Дата создания не меняется, как и в таблице.
+	r.endpoints[e.ID()] = webhook.RestoreEndpoint(e.ID(), e.UserID(), e.URL(), e.Secret(), e.EventTypes(), old.CreatedAt())
+	return nil
+}
+
+func (r *memoryWebhookRepository) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: webhook delete failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deleteEndpoints(func(e *webhook.Endpoint) bool { return e.ID() == id })
+	return nil
+}
+
+func (r *memoryWebhookRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: webhook delete by user failed", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return 0, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deleteEndpoints(func(e *webhook.Endpoint) bool { return e.UserID() == userID }), nil
+}
+
+// deleteEndpoints удаляет endpoint'ы, для которых match вернул true, вместе
+// с их доставками. Вызывается под r.mu.
+func (r *memoryWebhookRepository) deleteEndpoints(match func(e *webhook.Endpoint) bool) int64 {
+	deleted := make(map[uuid.UUID]bool)
+	for id, e := range r.endpoints {
+		if match(e) {
+			delete(r.endpoints, id)
+			deleted[id] = true
+		}
+	}
+
+	kept := r.order[:0]
+	for _, did := range r.order {
+		if deleted[r.deliveries[did].EndpointID] {
+			delete(r.deliveries, did)
+			continue
+		}
+		kept = append(kept, did)
+	}
+	r.order = kept
+	return int64(len(deleted))
+}
+
+func (r *memoryWebhookRepository) EndpointsFor(ctx context.Context, eventType string, userID uuid.UUID) ([]*webhook.Endpoint, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: webhook endpoints fetch failed", err, map[string]interface{}{
+			"event_type": eventType,
+			"user_id":    userID,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var res []*webhook.Endpoint
+	for _, e := range r.sortedEndpoints() {
+		if e.UserID() == userID && e.Accepts(eventType) {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func (r *memoryWebhookRepository) AddDeliveries(ctx context.Context, deliveries ...webhook.Delivery) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: webhook deliveries create failed", err, map[string]interface{}{
+			"deliveries": len(deliveries),
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, d := range deliveries {
+		if _, ok := r.endpoints[d.EndpointID]; !ok {
+			// Как внешний ключ в таблице.
+			return myerrors.ErrInvalidData
+		}
+	}
+	for _, d := range deliveries {
+		if _, ok := r.deliveries[d.ID]; ok {
+			continue
+		}
+		d.Log = nil
+		r.deliveries[d.ID] = &d
+		r.order = append(r.order, d.ID)
+	}
+	return nil
+}
+
+func (r *memoryWebhookRepository) ListDeliveries(ctx context.Context, endpointID uuid.UUID, paging *domain.PagingBase) ([]webhook.Delivery, int64, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: webhook deliveries list failed", err, map[string]interface{}{
+			"endpoint_id": endpointID,
+			"paging":      paging,
+		})
+		return nil, 0, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var all []webhook.Delivery
+	for _, id := range r.order {
+		if d := r.deliveries[id]; d.EndpointID == endpointID {
+			c := *d
+			c.Log = append([]webhook.Attempt(nil), d.Log...)
+			all = append(all, c)
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].CreatedAt.After(all[j].CreatedAt)
+	})
+	return page(all, paging.Limit, paging.Offset), int64(len(all)), nil
+}
+
+func (r *memoryWebhookRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]webhook.Delivery, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: webhook deliveries claim failed", err, nil)
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*webhook.Delivery
+	for _, id := range r.order {
+		d := r.deliveries[id]
+		if d.Status == webhook.StatusPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	res := make([]webhook.Delivery, 0, len(due))
+	for _, d := range due {
+		c := *d
+		c.Log = nil
+		res = append(res, c)
+		d.NextAttemptAt = now.Add(lease)
+	}
+	return res, nil
+}
+
+func (r *memoryWebhookRepository) RecordAttempt(ctx context.Context, d webhook.Delivery, a webhook.Attempt) error {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: webhook attempt record failed", err, map[string]interface{}{
+			"delivery_id": d.ID,
+		})
+		return myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.deliveries[d.ID]
+	if !ok {
+		// Endpoint удалили, пока шла попытка.
+		return nil
+	}
+	stored.Status = d.Status
+	stored.Attempts = d.Attempts
+	stored.NextAttemptAt = d.NextAttemptAt
+	stored.DeliveredAt = d.DeliveredAt
+	stored.Log = append(stored.Log, a)
+	return nil
+}
+
+func (r *memoryWebhookRepository) Redeliver(ctx context.Context, endpointID, deliveryID uuid.UUID, now time.Time) (*webhook.Delivery, error) {
+	if err := ctx.Err(); err != nil {
+		logger.Error(ctx, "repo: webhook redeliver failed", err, map[string]interface{}{
+			"delivery_id": deliveryID,
+		})
+		return nil, myerrors.ErrDatabase
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.deliveries[deliveryID]
+	if !ok || d.EndpointID != endpointID {
+		return nil, myerrors.ErrDeliveryNotFound
+	}
+	d.Status = webhook.StatusPending
+	d.NextAttemptAt = now
+	c := *d
+	c.Log = nil
+	return &c, nil
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Budget struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key"`
+	UserID uuid.UUID `gorm:"type:uuid;not null"`
+	// ServiceName и Category == nil — бюджет на все подписки пользователя.
+	ServiceName  *string   `gorm:"type:varchar(100);null"`
+	Category     *string   `gorm:"type:varchar(50);null"`
+	MonthlyLimit int       `gorm:"type:integer;not null"`
+	HardLimit    bool      `gorm:"type:boolean;not null;default:false"`
+	CreatedAt    time.Time `gorm:"type:timestamp;not null;default:now();autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"type:timestamp;not null;default:now();autoUpdateTime"`
+}
+
+func (Budget) TableName() string {
+	return "budgets"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JobRun struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key"`
+	Job        string     `gorm:"type:varchar(100);not null"`
+	Trigger    string     `gorm:"type:varchar(20);not null"`
+	Instance   string     `gorm:"type:varchar(255);not null"`
+	Status     string     `gorm:"type:varchar(20);not null"`
+	StartedAt  time.Time  `gorm:"type:timestamp;not null"`
+	FinishedAt *time.Time `gorm:"type:timestamp;null"`
+	Error      *string    `gorm:"type:text;null"`
+}
+
+func (JobRun) TableName() string {
+	return "job_runs"
+}
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"encoding/json"
 	domain "testingtask/internal/domain/subscription"
+
+	"github.com/google/uuid"
 )
 
 // --------------------
@@ -16,7 +19,49 @@ func FromDomain(d *domain.Subscription) *Subscription {
 		UserID:      d.UserID(),
 		StartDate:   d.StartDate(),
 		EndDate:     d.EndDate(),
+		ServiceID:   d.ServiceID(),
+		Tags:        tagsJSON(d.Tags()),
+		Metadata:    metadataJSON(d.Metadata()),
+	}
+}
+
+// tagsJSON и metadataJSON кодируют метки для колонок JSONB; пустые —
+// [] и {}, как DEFAULT в миграции.
+func tagsJSON(tags []string) []byte {
+	if len(tags) == 0 {
+		return []byte("[]")
+	}
+	b, _ := json.Marshal(tags)
+	return b
+}
+
+// Metadata приходит из JSON запроса или базы и проверена
+// domain.ValidateMetadata, поэтому кодируется без ошибок.
+func metadataJSON(m map[string]any) []byte {
+	if len(m) == 0 {
+		return []byte("{}")
 	}
+	b, _ := json.Marshal(m)
+	return b
+}
+
+// Labels декодирует теги и metadata строки; пустые колонки — без меток.
+func (m *Subscription) Labels() ([]string, map[string]any, error) {
+	var (
+		tags     []string
+		metadata map[string]any
+	)
+	if len(m.Tags) > 0 {
+		if err := json.Unmarshal(m.Tags, &tags); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(m.Metadata) > 0 {
+		if err := json.Unmarshal(m.Metadata, &metadata); err != nil {
+			return nil, nil, err
+		}
+	}
+	return tags, metadata, nil
 }
 
 // --------------------
@@ -29,7 +74,7 @@ func ToDomain(m *Subscription) (*domain.Subscription, error) {
 		endDate = &domain.SubDate{Time: *m.EndDate}
 	}
 
-	return domain.NewSubscription(
+	sub := domain.RestoreSubscription(
 		m.ID,
 		m.ServiceName,
 		domain.Price(m.Price),
@@ -37,6 +82,14 @@ func ToDomain(m *Subscription) (*domain.Subscription, error) {
 		domain.SubDate{Time: m.StartDate},
 		endDate,
 	)
+	sub.LinkService(m.ServiceID, "")
+
+	tags, metadata, err := m.Labels()
+	if err != nil {
+		return nil, err
+	}
+	sub.RestoreLabels(tags, metadata)
+	return sub, nil
 }
 
 func ToDomains(models []*Subscription) ([]*domain.Subscription, error) {
@@ -50,3 +103,27 @@ func ToDomains(models []*Subscription) ([]*domain.Subscription, error) {
 	}
 	return res, nil
 }
+
+// MembersFromDomain раскладывает разделение подписки id по строкам.
+func MembersFromDomain(id uuid.UUID, s *domain.Split) []SubscriptionMember {
+	res := make([]SubscriptionMember, 0, len(s.Members))
+	for i, m := range s.Members {
+		row := SubscriptionMember{SubscriptionID: id, UserID: m.UserID, Share: m.Share}
+		// Проценты и сумма владельца следуют из остальных и не хранятся.
+		if i > 0 {
+			row.Percent, row.Amount = m.Percent, m.Amount
+		}
+		res = append(res, row)
+	}
+	return res
+}
+
+// MembersToDomain собирает разделение подписки владельца owner; без строк
+// подписка не общая — nil.
+func MembersToDomain(owner uuid.UUID, rows []SubscriptionMember) *domain.Split {
+	members := make([]domain.Member, 0, len(rows))
+	for _, r := range rows {
+		members = append(members, domain.Member{UserID: r.UserID, Percent: r.Percent, Amount: r.Amount, Share: r.Share})
+	}
+	return domain.RestoreSplit(owner, members)
+}
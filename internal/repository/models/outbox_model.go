@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxEvent struct {
+	// Seq задаёт порядок доставки событий.
+	Seq           int64      `gorm:"type:bigint;primaryKey;autoIncrement"`
+	ID            uuid.UUID  `gorm:"type:uuid;not null"`
+	AggregateID   uuid.UUID  `gorm:"type:uuid;not null"`
+	EventType     string     `gorm:"type:varchar(100);not null"`
+	Payload       []byte     `gorm:"type:jsonb;not null"`
+	OccurredAt    time.Time  `gorm:"type:timestamp;not null"`
+	Attempts      int        `gorm:"type:integer;not null;default:0"`
+	NextAttemptAt time.Time  `gorm:"type:timestamp;not null"`
+	LastError     *string    `gorm:"type:text;null"`
+	PublishedAt   *time.Time `gorm:"type:timestamp;null"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
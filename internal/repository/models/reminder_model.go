@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReminderPreferences struct {
+	UserID     uuid.UUID `gorm:"type:uuid;primary_key"`
+	Enabled    bool      `gorm:"type:boolean;not null"`
+	DaysBefore int       `gorm:"type:integer;not null"`
+	Channel    string    `gorm:"type:varchar(20);not null"`
+	Email      *string   `gorm:"type:varchar(255);null"`
+	WebhookURL *string   `gorm:"column:webhook_url;type:varchar(2048);null"`
+	CreatedAt  time.Time `gorm:"type:timestamp;not null;default:now();autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"type:timestamp;not null;default:now();autoUpdateTime"`
+}
+
+func (ReminderPreferences) TableName() string {
+	return "reminder_preferences"
+}
+
+type ReminderNotification struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;not null"`
+	Kind           string    `gorm:"type:varchar(20);not null"`
+	DueAt          time.Time `gorm:"type:timestamp;not null"`
+	Channel        string    `gorm:"type:varchar(20);not null"`
+	Attempts       int       `gorm:"type:integer;not null;default:0"`
+	// ClaimedUntil — до этого момента напоминание отправляет взявший его
+	// экземпляр.
+	ClaimedUntil time.Time  `gorm:"type:timestamp;not null"`
+	SentAt       *time.Time `gorm:"type:timestamp;null"`
+	LastError    *string    `gorm:"type:text;null"`
+	CreatedAt    time.Time  `gorm:"type:timestamp;not null"`
+}
+
+func (ReminderNotification) TableName() string {
+	return "reminder_notifications"
+}
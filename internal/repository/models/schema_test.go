@@ -0,0 +1,187 @@
+package models_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testingtask/internal/migrator"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/repository/models"
+	"testingtask/migrations"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm/schema"
+)
+
+// Модели, которые должны совпадать со схемой после `migrate up`.
+var allModels = []interface{}{
+	&models.Subscription{},
+	&models.OutboxEvent{},
+	&models.WebhookEndpoint{},
+	&models.WebhookDelivery{},
+	&models.WebhookDeliveryAttempt{},
+	&models.ReminderPreferences{},
+	&models.ReminderNotification{},
+	&models.JobRun{},
+	&models.Budget{},
+	&models.Service{},
+	&models.ServiceAlias{},
+	&models.SubscriptionMember{},
+	&models.User{},
+}
+
+func TestMain(m *testing.M) {
+	pgtest.Main(m)
+}
+
+type column struct {
+	udtName  string
+	length   sql.NullInt64
+	nullable bool
+}
+
+// TestModelsMatchMigrations падает, если GORM-модели и схема,
+// полученная из встроенных миграций, разошлись (тип, длина, NULL,
+// лишние или отсутствующие колонки).
+func TestModelsMatchMigrations(t *testing.T) {
+	dsn := pgtest.DSN(t)
+	ctx := context.Background()
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	m, err := migrator.New(db, migrations.FS, migrator.Postgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	for _, model := range allModels {
+		s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(s.Table, func(t *testing.T) {
+			cols := tableColumns(t, db, s.Table)
+			if len(cols) == 0 {
+				t.Fatalf("table %s does not exist", s.Table)
+			}
+
+			seen := map[string]bool{}
+			for _, f := range s.Fields {
+				if f.DBName == "" {
+					continue
+				}
+				seen[f.DBName] = true
+
+				col, ok := cols[f.DBName]
+				if !ok {
+					t.Errorf("%s.%s: column missing in migrations", s.Table, f.DBName)
+					continue
+				}
+
+				udt, length := normalizeType(f.TagSettings["TYPE"])
+				if udt != col.udtName {
+					t.Errorf("%s.%s: model type %q, database type %q", s.Table, f.DBName, udt, col.udtName)
+				}
+				if length > 0 && (!col.length.Valid || col.length.Int64 != length) {
+					t.Errorf("%s.%s: model length %d, database length %v", s.Table, f.DBName, length, col.length.Int64)
+				}
+
+				notNull := f.NotNull || f.PrimaryKey
+				if notNull == col.nullable {
+					t.Errorf("%s.%s: model not null %t, database nullable %t", s.Table, f.DBName, notNull, col.nullable)
+				}
+			}
+
+			for name := range cols {
+				if !seen[name] {
+					t.Errorf("%s.%s: column missing in model", s.Table, name)
+				}
+			}
+		})
+	}
+}
+
+func tableColumns(t *testing.T, db *sql.DB, table string) map[string]column {
+	t.Helper()
+
+	rows, err := db.Query(`
+		SELECT column_name, udt_name, character_maximum_length, is_nullable = 'YES'
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1`, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	res := map[string]column{}
+	for rows.Next() {
+		var (
+			name string
+			c    column
+		)
+		if err := rows.Scan(&name, &c.udtName, &c.length, &c.nullable); err != nil {
+			t.Fatal(err)
+		}
+		res[name] = c
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return res
+}
+
+var typeRe = regexp.MustCompile(`^([a-z ]+?)(?:\((\d+)\))?(\[\])?$`)
+
+// normalizeType переводит тип из gorm-тега в udt_name Postgres.
+func normalizeType(tag string) (string, int64) {
+	m := typeRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(tag)))
+	if m == nil {
+		return tag, 0
+	}
+
+	aliases := map[string]string{
+		"int":                         "int4",
+		"integer":                     "int4",
+		"bigint":                      "int8",
+		"smallint":                    "int2",
+		"varchar":                     "varchar",
+		"character varying":           "varchar",
+		"char":                        "bpchar",
+		"character":                   "bpchar",
+		"boolean":                     "bool",
+		"bool":                        "bool",
+		"timestamp":                   "timestamp",
+		"timestamp without time zone": "timestamp",
+		"timestamptz":                 "timestamptz",
+		"timestamp with time zone":    "timestamptz",
+		"numeric":                     "numeric",
+	}
+
+	name := m[1]
+	if a, ok := aliases[name]; ok {
+		name = a
+	}
+	if m[3] != "" {
+		name = "_" + name
+	}
+
+	var length int64
+	if m[2] != "" && name == "varchar" {
+		length, _ = strconv.ParseInt(m[2], 10, 64)
+	}
+
+	return name, length
+}
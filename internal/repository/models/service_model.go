@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key"`
+	Name         string    `gorm:"type:varchar(100);not null"`
+	Category     *string   `gorm:"type:varchar(50);null"`
+	DefaultPrice *int      `gorm:"type:integer;null"`
+	Currency     string    `gorm:"type:char(3);not null;default:'RUB'"`
+	LogoURL      *string   `gorm:"type:varchar(500);null"`
+	CreatedAt    time.Time `gorm:"type:timestamp;not null;default:now();autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"type:timestamp;not null;default:now();autoUpdateTime"`
+}
+
+func (Service) TableName() string {
+	return "services"
+}
+
+// ServiceAlias — ключ сопоставления (catalog.Key) имени или синонима
+// сервиса и его исходное написание.
+type ServiceAlias struct {
+	Key       string    `gorm:"type:varchar(100);primary_key"`
+	ServiceID uuid.UUID `gorm:"type:uuid;not null"`
+	Alias     string    `gorm:"type:varchar(100);not null"`
+}
+
+func (ServiceAlias) TableName() string {
+	return "service_aliases"
+}
@@ -8,13 +8,31 @@ import (
 
 type Subscription struct {
 	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	ServiceName string     `gorm:"type:varchar(50);not null"`
+	ServiceName string     `gorm:"type:varchar(100);not null"`
 	UserID      uuid.UUID  `gorm:"type:uuid;not null"`
-	Price       int        `gorm:"type:int;not null"`
+	Price       int        `gorm:"type:integer;not null"`
 	StartDate   time.Time  `gorm:"type:date;not null"`
 	EndDate     *time.Time `gorm:"type:date;null"`
+	ServiceID   *uuid.UUID `gorm:"type:uuid;null;index"`
+	Tags        []byte     `gorm:"type:jsonb;not null;default:'[]'"`
+	Metadata    []byte     `gorm:"type:jsonb;not null;default:'{}'"`
+	CreatedAt   time.Time  `gorm:"type:timestamp;not null;default:now();autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"type:timestamp;not null;default:now();autoUpdateTime"`
 }
 
 func (Subscription) TableName() string {
 	return "subscriptions"
 }
+
+// SubscriptionMember — участник общей подписки, см. domain.Split.
+type SubscriptionMember struct {
+	SubscriptionID uuid.UUID `gorm:"type:uuid;primary_key"`
+	UserID         uuid.UUID `gorm:"type:uuid;primary_key"`
+	Percent        *float64  `gorm:"type:numeric;null"`
+	Amount         *int      `gorm:"type:integer;null"`
+	Share          int       `gorm:"type:integer;not null"`
+}
+
+func (SubscriptionMember) TableName() string {
+	return "subscription_members"
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type User struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key"`
+	DisplayName string    `gorm:"type:varchar(100);not null"`
+	Email       *string   `gorm:"type:varchar(254);null"`
+	Currency    string    `gorm:"type:char(3);not null;default:'RUB'"`
+	Timezone    string    `gorm:"type:varchar(64);not null;default:'UTC'"`
+	CreatedAt   time.Time `gorm:"type:timestamp;not null;default:now();autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"type:timestamp;not null;default:now();autoUpdateTime"`
+}
+
+func (User) TableName() string {
+	return "users"
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEndpoint struct {
+	ID uuid.UUID `gorm:"type:uuid;primary_key"`
+	// UserID — владелец, nil у endpoint'ов, созданных до появления
+	// владельцев.
+	UserID *uuid.UUID `gorm:"type:uuid;null;index"`
+	URL    string     `gorm:"column:url;type:varchar(2048);not null"`
+	Secret string     `gorm:"type:varchar(255);not null"`
+	// EventTypes — типы событий через запятую.
+	EventTypes string    `gorm:"type:text;not null"`
+	CreatedAt  time.Time `gorm:"type:timestamp;not null;default:now();autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"type:timestamp;not null;default:now();autoUpdateTime"`
+}
+
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+type WebhookDelivery struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key"`
+	EndpointID    uuid.UUID  `gorm:"type:uuid;not null"`
+	EventID       uuid.UUID  `gorm:"type:uuid;not null"`
+	EventType     string     `gorm:"type:varchar(100);not null"`
+	Payload       []byte     `gorm:"type:jsonb;not null"`
+	Status        string     `gorm:"type:varchar(20);not null"`
+	Attempts      int        `gorm:"type:integer;not null;default:0"`
+	NextAttemptAt time.Time  `gorm:"type:timestamp;not null"`
+	CreatedAt     time.Time  `gorm:"type:timestamp;not null"`
+	DeliveredAt   *time.Time `gorm:"type:timestamp;null"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+type WebhookDeliveryAttempt struct {
+	ID          int64     `gorm:"type:bigint;primaryKey;autoIncrement"`
+	DeliveryID  uuid.UUID `gorm:"type:uuid;not null"`
+	AttemptedAt time.Time `gorm:"type:timestamp;not null"`
+	StatusCode  *int      `gorm:"type:integer;null"`
+	Error       *string   `gorm:"type:text;null"`
+	DurationMs  int       `gorm:"type:integer;not null"`
+}
+
+func (WebhookDeliveryAttempt) TableName() string {
+	return "webhook_delivery_attempts"
+}
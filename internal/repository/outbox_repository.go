@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/outbox"
+	"testingtask/internal/readpref"
+	"testingtask/internal/repository/models"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository записывает доменные события и отдаёт их outbox.Relay.
+type OutboxRepository interface {
+	// Add сохраняет события в транзакции из ctx, если она есть. Событие
+	// с уже записанным ID пропускается.
+	Add(ctx context.Context, events ...outbox.Event) error
+	outbox.Store
+}
+
+// outboxLockKey — ключ advisory-блокировки, чтобы события отправлял один
+// экземпляр сервиса.
+const outboxLockKey = "outbox_relay"
+
+// pendingQuery выбирает события, готовые к отправке: время повтора
+// наступило и в том же агрегате нет более раннего события, ждущего
+// повтора.
+const pendingQuery = `SELECT * FROM outbox_events e
+WHERE e.published_at IS NULL AND e.next_attempt_at <= ?
+	AND NOT EXISTS (
+		SELECT 1 FROM outbox_events p
+		WHERE p.aggregate_id = e.aggregate_id AND p.published_at IS NULL
+			AND p.seq < e.seq AND p.next_attempt_at > ?
+	)
+ORDER BY e.seq
+LIMIT ?`
+
+type outboxRepository struct {
+	DB *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{DB: db}
+}
+
+func (r *outboxRepository) Add(ctx context.Context, events ...outbox.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	rows := make([]models.OutboxEvent, 0, len(events))
+	for _, e := range events {
+		rows = append(rows, models.OutboxEvent{
+			ID:            e.ID,
+			AggregateID:   e.AggregateID,
+			EventType:     e.Type,
+			Payload:       e.Payload,
+			OccurredAt:    e.OccurredAt.UTC(),
+			NextAttemptAt: e.OccurredAt.UTC(),
+		})
+	}
+
+	err := conn(ctx, r.DB).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "id"}}, DoNothing: true}).
+		Create(&rows).Error
+	if err != nil {
+		logger.Error(ctx, "repo: outbox add failed", err, map[string]interface{}{
+			"events": len(events),
+		})
+		return mapError(err, myerrors.ErrCreateFailed)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) Pending(ctx context.Context, now time.Time, limit int) ([]outbox.Event, error) {
+	var rows []models.OutboxEvent
+
+	// С реплики relay увидел бы уже отправленные события ещё раз.
+	now = now.UTC()
+	if err := conn(readpref.WithPrimary(ctx), r.DB).Raw(pendingQuery, now, now, limit).Scan(&rows).Error; err != nil {
+		logger.Error(ctx, "repo: outbox pending fetch failed", err, nil)
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	events := make([]outbox.Event, 0, len(rows))
+	for _, m := range rows {
+		events = append(events, outbox.Event{
+			ID:          m.ID,
+			Type:        m.EventType,
+			AggregateID: m.AggregateID,
+			Payload:     m.Payload,
+			OccurredAt:  m.OccurredAt,
+			Attempts:    m.Attempts,
+		})
+	}
+
+	return events, nil
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id uuid.UUID, at time.Time) error {
+	err := conn(ctx, r.DB).
+		Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"published_at": at.UTC(), "last_error": nil}).
+		Error
+	if err != nil {
+		logger.Error(ctx, "repo: outbox mark published failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return mapError(err, myerrors.ErrUpdateFailed)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, next time.Time, reason string) error {
+	err := conn(ctx, r.DB).
+		Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": next.UTC(),
+			"last_error":      reason,
+		}).
+		Error
+	if err != nil {
+		logger.Error(ctx, "repo: outbox retry schedule failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return mapError(err, myerrors.ErrUpdateFailed)
+	}
+
+	return nil
+}
+
+// TryLock берёт advisory-блокировку Postgres (см. tryAdvisoryLock) и
+// держит её до unlock. У SQLite один писатель на базу, блокировка не
+// нужна.
+func (r *outboxRepository) TryLock(ctx context.Context) (func(), bool, error) {
+	if r.DB.Dialector.Name() == "sqlite" {
+		return func() {}, true, nil
+	}
+
+	lock, ok, err := tryAdvisoryLock(ctx, r.DB, outboxLockKey)
+	if !ok {
+		return nil, ok, err
+	}
+	return lock.Unlock, true, nil
+}
+
+// noOutboxRepository — OutboxRepository при выключенной доставке
+// событий: Add ничего не сохраняет, отправлять нечего.
+type noOutboxRepository struct{}
+
+func NewNoOutboxRepository() OutboxRepository {
+	return noOutboxRepository{}
+}
+
+func (noOutboxRepository) Add(context.Context, ...outbox.Event) error { return nil }
+
+func (noOutboxRepository) Pending(context.Context, time.Time, int) ([]outbox.Event, error) {
+	return nil, nil
+}
+
+func (noOutboxRepository) MarkPublished(context.Context, uuid.UUID, time.Time) error { return nil }
+
+func (noOutboxRepository) MarkFailed(context.Context, uuid.UUID, time.Time, string) error {
+	return nil
+}
+
+func (noOutboxRepository) TryLock(context.Context) (func(), bool, error) {
+	return nil, false, nil
+}
@@ -0,0 +1,90 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testingtask/internal/database"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryOutboxRepository(t *testing.T) {
+	repotest.RunOutboxRepository(t, func(t *testing.T) repository.OutboxRepository {
+		return repository.NewMemoryOutboxRepository()
+	})
+}
+
+func TestSQLiteOutboxRepository(t *testing.T) {
+	repotest.RunOutboxRepository(t, func(t *testing.T) repository.OutboxRepository {
+		url := database.SQLiteScheme + filepath.Join(t.TempDir(), "subs.db")
+		return repository.NewOutboxRepository(openSQLite(t, url))
+	})
+}
+
+func TestOutboxRepository(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+
+	repotest.RunOutboxRepository(t, func(t *testing.T) repository.OutboxRepository {
+		if err := db.Exec("TRUNCATE outbox_events").Error; err != nil {
+			t.Fatal(err)
+		}
+		return repository.NewOutboxRepository(db)
+	})
+}
+
+// TestOutboxLockExclusive проверяет, что advisory-блокировку relay
+// получает только один экземпляр.
+func TestOutboxLockExclusive(t *testing.T) {
+	dsn := pgtest.DSN(t)
+	first := repository.NewOutboxRepository(openDB(t, dsn))
+	second := repository.NewOutboxRepository(openDB(t, dsn))
+	ctx := context.Background()
+
+	unlock, ok, err := first.TryLock(ctx)
+	if err != nil || !ok {
+		t.Fatalf("first TryLock = %t, %v", ok, err)
+	}
+
+	if _, ok, err := second.TryLock(ctx); err != nil || ok {
+		t.Fatalf("second TryLock while held = %t, %v, want false", ok, err)
+	}
+
+	unlock()
+
+	unlock, ok, err = second.TryLock(ctx)
+	if err != nil || !ok {
+		t.Fatalf("second TryLock after unlock = %t, %v", ok, err)
+	}
+	unlock()
+}
+
+// TestSQLiteOutboxRollback проверяет, что событие из откаченной
+// транзакции не попадает в outbox.
+func TestSQLiteOutboxRollback(t *testing.T) {
+	db := openSQLite(t, database.SQLiteScheme+filepath.Join(t.TempDir(), "subs.db"))
+	r := repository.NewOutboxRepository(db)
+	tx := repository.NewTxManager(db)
+	ctx := context.Background()
+
+	errBoom := errors.New("boom")
+	err := tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if err := r.Add(ctx, repotest.Event(uuid.New(), "SubscriptionCreated", 0)); err != nil {
+			return err
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Do = %v, want errBoom", err)
+	}
+
+	events, err := r.Pending(ctx, time.Now().Add(24*365*time.Hour), 10)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("Pending after rollback = %d events, %v, want 0", len(events), err)
+	}
+}
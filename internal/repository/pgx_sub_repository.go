@@ -0,0 +1,424 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository/models"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Имена подготовленных statements, см. PreparePgxStatements.
+const (
+	stmtGet        = "sub_get"
+	stmtList       = "sub_list"
+	stmtListTotals = "sub_list_totals"
+	stmtCount      = "sub_count"
+	stmtSumPage    = "sub_sum_page"
+	stmtSumTotals  = "sub_sum_totals"
+)
+
+const subColumns = `id, service_name, price, user_id, start_date, end_date, service_id, tags, metadata`
+
+// labelsWhere — условия фильтра по тегам и metadata с параметрами
+// начиная с $n: теги any (text[]), теги all (jsonb), ключи metadata
+// (text[]) и значения metadata (jsonb). Операторы ?|, @> и ?& обслуживают
+// индексы GIN.
+func labelsWhere(n int) string {
+	return fmt.Sprintf(`($%[1]d::text[] IS NULL OR tags ?| $%[1]d)
+	  AND ($%[2]d::jsonb IS NULL OR tags @> $%[2]d)
+	  AND ($%[3]d::text[] IS NULL OR metadata ?& $%[3]d)
+	  AND ($%[4]d::jsonb IS NULL OR metadata @> $%[4]d)`, n, n+1, n+2, n+3)
+}
+
+// pgxShare — доля пользователя $1 в общей подписке, NULL — подписка не
+// общая или $1 не задан.
+const pgxShare = `(SELECT sm.share FROM subscription_members sm WHERE sm.subscription_id = subscriptions.id AND sm.user_id = $1)`
+
+// Условия фильтра Sum: NULL-параметр отключает условие, поэтому один
+// подготовленный statement покрывает все комбинации фильтров. Пользователю
+// принадлежат его не общие подписки и общие, где он участник.
+var sumWhere = `
+	WHERE ($1::uuid IS NULL
+	       OR (user_id = $1 AND NOT EXISTS (SELECT 1 FROM subscription_members sm WHERE sm.subscription_id = subscriptions.id))
+	       OR EXISTS (SELECT 1 FROM subscription_members sm WHERE sm.subscription_id = subscriptions.id AND sm.user_id = $1))
+	  AND ($2::text IS NULL OR service_name = $2)
+	  AND ($3::date IS NULL OR start_date >= $3)
+	  AND ($4::date IS NULL OR end_date <= $4)
+	  AND ` + labelsWhere(5)
+
+var pgxStatements = map[string]string{
+	stmtGet: `SELECT ` + subColumns + ` FROM subscriptions WHERE id = $1`,
+
+	stmtList: `SELECT ` + subColumns + `, COUNT(*) OVER ()
+		FROM subscriptions
+		WHERE ` + labelsWhere(1) + `
+		ORDER BY created_at, id
+		LIMIT $5 OFFSET $6`,
+
+	stmtListTotals: `SELECT COUNT(*) FROM subscriptions WHERE ` + labelsWhere(1),
+
+	stmtCount: `SELECT COUNT(*) FROM subscriptions`,
+
+	// Оконные функции считаются до LIMIT, поэтому каждая строка страницы
+	// несёт количество и сумму по всем подходящим строкам.
+	stmtSumPage: `SELECT ` + subColumns + `, ` + pgxShare + `, COUNT(*) OVER (), COALESCE(SUM(COALESCE(` + pgxShare + `, price)) OVER (), 0)
+		FROM subscriptions` + sumWhere + `
+		ORDER BY created_at, id
+		LIMIT $9 OFFSET $10`,
+
+	stmtSumTotals: `SELECT COUNT(*), COALESCE(SUM(COALESCE(` + pgxShare + `, price)), 0)
+		FROM subscriptions` + sumWhere,
+}
+
+// PreparePgxStatements готовит запросы pgxSubRepository на соединении.
+// Передаётся в pgxpool как AfterConnect.
+func PreparePgxStatements(ctx context.Context, conn *pgx.Conn) error {
+	for name, sql := range pgxStatements {
+		if _, err := conn.Prepare(ctx, name, sql); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pgxSubRepository — реализация для больших таблиц: Get, List, Sum и Count
+// идут напрямую через pgx на подготовленных statements, List и Sum отдают
+// страницу и итоги за один round trip. Запись и чтение внутри транзакции
+// делегируются writes (GORM), чтобы не дублировать маппинг моделей и ошибок.
+type pgxSubRepository struct {
+	pool   *pgxpool.Pool
+	writes SubRepository
+}
+
+func NewPgxSubRepository(pool *pgxpool.Pool, writes SubRepository) SubRepository {
+	return &pgxSubRepository{pool: pool, writes: writes}
+}
+
+func (r *pgxSubRepository) Create(ctx context.Context, sub *domain.Subscription) error {
+	return r.writes.Create(ctx, sub)
+}
+
+func (r *pgxSubRepository) Update(ctx context.Context, sub *domain.Subscription) error {
+	return r.writes.Update(ctx, sub)
+}
+
+func (r *pgxSubRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.writes.Delete(ctx, id)
+}
+
+func (r *pgxSubRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Subscription, error) {
+	return r.writes.DeleteByUser(ctx, userID)
+}
+
+func (r *pgxSubRepository) ListEnded(ctx context.Context, month domain.SubDate) ([]*domain.Subscription, error) {
+	return r.writes.ListEnded(ctx, month)
+}
+
+func (r *pgxSubRepository) ListByService(ctx context.Context, serviceID uuid.UUID) ([]*domain.Subscription, error) {
+	return r.writes.ListByService(ctx, serviceID)
+}
+
+func (r *pgxSubRepository) Split(ctx context.Context, id uuid.UUID) (*domain.Split, error) {
+	return r.writes.Split(ctx, id)
+}
+
+func (r *pgxSubRepository) SaveSplit(ctx context.Context, id uuid.UUID, split *domain.Split) error {
+	return r.writes.SaveSplit(ctx, id, split)
+}
+
+func (r *pgxSubRepository) ListSharedWith(ctx context.Context, userID uuid.UUID) ([]*domain.Subscription, error) {
+	return r.writes.ListSharedWith(ctx, userID)
+}
+
+func (r *pgxSubRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	return r.writes.GetForUpdate(ctx, id)
+}
+
+func (r *pgxSubRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	if inTx(ctx) {
+		return r.writes.Get(ctx, id)
+	}
+
+	sub, err := scanSub(r.pool.QueryRow(ctx, stmtGet, id))
+	if err != nil {
+		logger.Error(ctx, "repo: subscription get failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return sub, nil
+}
+
+func (r *pgxSubRepository) List(ctx context.Context, labels domain.LabelFilter, paging *domain.PagingBase) ([]*domain.Subscription, int64, error) {
+	args, ok := labelArgs(labels)
+	if inTx(ctx) || !ok {
+		return r.writes.List(ctx, labels, paging)
+	}
+
+	var total int64
+
+	pageArgs := append(append([]interface{}{}, args...), pgLimit(paging.Limit), max(paging.Offset, 0))
+
+	subs, err := r.page(ctx,
+		stmtList, pageArgs,
+		stmtListTotals, args,
+		needTotals(paging.Limit, paging.Offset), false, &total)
+	if err != nil {
+		logger.Error(ctx, "repo: subscription list failed", err, map[string]interface{}{
+			"labels": labels,
+			"paging": paging,
+		})
+		return nil, 0, mapError(err, myerrors.ErrListFailed)
+	}
+
+	return subs, total, nil
+}
+
+func (r *pgxSubRepository) Sum(ctx context.Context, filter *domain.SubscriptionFilter) (*domain.SumResult, error) {
+	args, ok := sumArgs(filter)
+	if inTx(ctx) || !ok {
+		return r.writes.Sum(ctx, filter)
+	}
+
+	var count, sum int64
+
+	pageArgs := append(append([]interface{}{}, args...), pgLimit(filter.Limit), max(filter.Offset, 0))
+
+	rows, err := r.page(ctx,
+		stmtSumPage, pageArgs,
+		stmtSumTotals, args,
+		needTotals(filter.Limit, filter.Offset), true, &count, &sum)
+	if err != nil {
+		logger.Error(ctx, "repo: subscription sum failed", err, map[string]interface{}{
+			"filter": filter,
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return domain.NewSumResult(rows, int(sum), int(count)), nil
+}
+
+func (r *pgxSubRepository) Count(ctx context.Context) (int64, error) {
+	if inTx(ctx) {
+		return r.writes.Count(ctx)
+	}
+
+	var count int64
+	if err := r.pool.QueryRow(ctx, stmtCount).Scan(&count); err != nil {
+		logger.Error(ctx, "repo: subscription count failed", err, nil)
+		return 0, mapError(err, myerrors.ErrDatabase)
+	}
+	return count, nil
+}
+
+// inTx сообщает, открыта ли в ctx транзакция TxManager. Она живёт на
+// соединении GORM, поэтому чтение внутри неё делегируется writes, иначе
+// оно не увидело бы изменений транзакции и её снимка.
+func inTx(ctx context.Context) bool {
+	_, ok := txFromContext(ctx)
+	return ok
+}
+
+// needTotals сообщает, может ли страница оказаться пустой при непустой
+// выборке. Тогда оконным функциям не на чем считать итоги и их нужно
+// запросить отдельно.
+func needTotals(limit, offset int) bool {
+	return offset > 0 || limit == 0
+}
+
+// page выполняет запрос страницы с оконными итогами, значения которых
+// после колонок подписки пишутся в totals. Если withTotals, в тот же
+// batch добавляется запрос итогов — это по-прежнему один round trip.
+// withShare — перед итогами идёт колонка доли пользователя (pgxShare).
+func (r *pgxSubRepository) page(
+	ctx context.Context,
+	pageStmt string, pageArgs []interface{},
+	totalsStmt string, totalsArgs []interface{},
+	withTotals, withShare bool, totals ...interface{},
+) ([]*domain.Subscription, error) {
+	batch := &pgx.Batch{}
+	batch.Queue(pageStmt, pageArgs...)
+	if withTotals {
+		batch.Queue(totalsStmt, totalsArgs...)
+	}
+
+	br := r.pool.SendBatch(ctx, batch)
+	defer br.Close()
+
+	rows, err := br.Query()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res   []*domain.Subscription
+		share *int32
+	)
+	extra := totals
+	if withShare {
+		extra = append([]interface{}{&share}, totals...)
+	}
+	for rows.Next() {
+		share = nil
+		sub, err := scanSub(rows, extra...)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if share != nil {
+			n := int(*share)
+			sub.RestoreShare(&n)
+		}
+		res = append(res, sub)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if withTotals {
+		if err := br.QueryRow().Scan(totals...); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, br.Close()
+}
+
+func scanSub(row pgx.Row, extra ...interface{}) (*domain.Subscription, error) {
+	var (
+		id, userID     pgtype.UUID
+		serviceID      pgtype.UUID
+		serviceName    string
+		price          int32
+		start          time.Time
+		end            *time.Time
+		tags, metadata []byte
+	)
+
+	dest := append([]interface{}{&id, &serviceName, &price, &userID, &start, &end, &serviceID, &tags, &metadata}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	var endDate *domain.SubDate
+	if end != nil {
+		endDate = domain.NewSubDateFromTime(*end)
+	}
+
+	sub := domain.RestoreSubscription(
+		uuid.UUID(id.Bytes),
+		serviceName,
+		domain.Price(price),
+		uuid.UUID(userID.Bytes),
+		*domain.NewSubDateFromTime(start),
+		endDate,
+	)
+	if serviceID.Valid {
+		sid := uuid.UUID(serviceID.Bytes)
+		sub.LinkService(&sid, "")
+	}
+
+	m := models.Subscription{Tags: tags, Metadata: metadata}
+	labels, meta, err := m.Labels()
+	if err != nil {
+		return nil, err
+	}
+	sub.RestoreLabels(labels, meta)
+	return sub, nil
+}
+
+// sumArgs возвращает параметры sumWhere; ok == false — фильтр по
+// metadata не выражается параметрами statement (см. labelArgs).
+func sumArgs(f *domain.SubscriptionFilter) ([]interface{}, bool) {
+	args := make([]interface{}, 4, 8)
+	if f.UserID != nil {
+		args[0] = *f.UserID
+	}
+	if f.ServiceName != nil {
+		args[1] = *f.ServiceName
+	}
+	if f.StartDate != nil {
+		args[2] = f.StartDate.Time
+	}
+	if f.EndDate != nil {
+		args[3] = f.EndDate.Time
+	}
+
+	labels, ok := labelArgs(f.Labels)
+	return append(args, labels...), ok
+}
+
+// labelArgs возвращает параметры labelsWhere. Значения metadata
+// собираются в один объект для @>, поэтому два разных значения одного
+// ключа в нём не выразить — тогда ok == false и запрос выполняет writes.
+func labelArgs(f domain.LabelFilter) ([]interface{}, bool) {
+	args := make([]interface{}, 4)
+	if len(f.Tags) > 0 {
+		if f.TagMatch == domain.TagMatchAll {
+			args[1] = tagsFilterJSON(f.Tags...)
+		} else {
+			args[0] = f.Tags
+		}
+	}
+
+	var keys []string
+	values := map[string]string{}
+	for _, c := range f.Metadata {
+		if c.Value == nil {
+			keys = append(keys, c.Key)
+			continue
+		}
+		if v, ok := values[c.Key]; ok && v != *c.Value {
+			return nil, false
+		}
+		values[c.Key] = *c.Value
+	}
+	if len(keys) > 0 {
+		args[2] = keys
+	}
+	if len(values) > 0 {
+		b, _ := json.Marshal(values)
+		args[3] = string(b)
+	}
+	return args, true
+}
+
+// pgLimit повторяет семантику GORM: отрицательный limit — без ограничения
+// (LIMIT NULL).
+func pgLimit(limit int) interface{} {
+	if limit < 0 {
+		return nil
+	}
+	return limit
+}
+
+// pgxTxManager — TxManager для pgx-репозитория. Транзакции на запись
+// открываются через GORM (writes), на них работает и чтение внутри них.
+// Read-only транзакции не открываются: List и Sum pgx-репозитория читают
+// страницу и итоги одним запросом, т.е. из одного снимка, а BEGIN/COMMIT
+// добавили бы два round trip.
+type pgxTxManager struct {
+	writes TxManager
+}
+
+func NewPgxTxManager(writes TxManager) TxManager {
+	return &pgxTxManager{writes: writes}
+}
+
+func (m *pgxTxManager) Do(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+	if opts.ReadOnly {
+		return fn(ctx)
+	}
+	return m.writes.Do(ctx, opts, fn)
+}
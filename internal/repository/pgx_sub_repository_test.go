@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// openPgxPool открывает пул pgx с подготовленными statements репозитория.
+func openPgxPool(t testing.TB, dsn string) *pgxpool.Pool {
+	t.Helper()
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AfterConnect = repository.PreparePgxStatements
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+// TestPgxSubRepository прогоняет общий набор на pgx-реализации.
+func TestPgxSubRepository(t *testing.T) {
+	dsn := pgtest.DSN(t)
+	db := openDB(t, dsn)
+	pool := openPgxPool(t, dsn)
+
+	repotest.RunSubRepository(t, func(t *testing.T) repository.SubRepository {
+		resetSubscriptions(t, db)
+		return repository.NewPgxSubRepository(pool, repository.NewSubRepository(db))
+	})
+}
+
+// TestPgxTxManager проверяет, что чтение pgx-репозитория внутри
+// транзакции на запись видит её изменения.
+func TestPgxTxManager(t *testing.T) {
+	dsn := pgtest.DSN(t)
+	db := openDB(t, dsn)
+	pool := openPgxPool(t, dsn)
+
+	repotest.RunTxManager(t, func(t *testing.T) (repository.SubRepository, repository.TxManager) {
+		resetSubscriptions(t, db)
+		return repository.NewPgxSubRepository(pool, repository.NewSubRepository(db)),
+			repository.NewPgxTxManager(repository.NewTxManager(db))
+	})
+}
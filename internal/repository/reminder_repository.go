@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testingtask/internal/domain/reminder"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository/models"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReminderRepository хранит настройки напоминаний и журнал отправленных
+// напоминаний.
+type ReminderRepository interface {
+	// GetPreferences возвращает ErrPreferencesNotFound, если пользователь
+	// ничего не настраивал.
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*reminder.Preferences, error)
+	SavePreferences(ctx context.Context, p *reminder.Preferences) error
+	// DeleteByUser удаляет настройки пользователя и возвращает число
+	// удалённых строк. Журнал отправленных напоминаний остаётся.
+	DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// Claim берёт напоминание n на отправку до now+lease и возвращает
+	// true. Уже отправленное или взятое другим экземпляром напоминание не
+	// выдаётся (false).
+	Claim(ctx context.Context, n reminder.Notification, now time.Time, lease time.Duration) (bool, error)
+	MarkSent(ctx context.Context, id uuid.UUID, at time.Time) error
+	// Release возвращает неотправленное напоминание в очередь: его снова
+	// можно взять с retryAt.
+	Release(ctx context.Context, id uuid.UUID, retryAt time.Time, reason string) error
+}
+
+type reminderRepository struct {
+	DB *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) ReminderRepository {
+	return &reminderRepository{DB: db}
+}
+
+func (r *reminderRepository) GetPreferences(ctx context.Context, userID uuid.UUID) (*reminder.Preferences, error) {
+	var m models.ReminderPreferences
+
+	err := conn(ctx, r.DB).First(&m, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, myerrors.ErrPreferencesNotFound
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: reminder preferences get failed", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return reminder.RestorePreferences(m.UserID, m.Enabled, m.DaysBefore, m.Channel, m.Email, m.WebhookURL), nil
+}
+
+func (r *reminderRepository) SavePreferences(ctx context.Context, p *reminder.Preferences) error {
+	now := time.Now().UTC()
+	m := models.ReminderPreferences{
+		UserID:     p.UserID(),
+		Enabled:    p.Enabled(),
+		DaysBefore: p.DaysBefore(),
+		Channel:    p.Channel(),
+		Email:      p.Email(),
+		WebhookURL: p.WebhookURL(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	err := conn(ctx, r.DB).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "days_before", "channel", "email", "webhook_url", "updated_at"}),
+		}).
+		Create(&m).Error
+	if err != nil {
+		logger.Error(ctx, "repo: reminder preferences save failed", err, map[string]interface{}{
+			"user_id": p.UserID(),
+		})
+		return mapError(err, myerrors.ErrUpdateFailed)
+	}
+
+	return nil
+}
+
+func (r *reminderRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	res := conn(ctx, r.DB).Delete(&models.ReminderPreferences{}, "user_id = ?", userID)
+	if res.Error != nil {
+		logger.Error(ctx, "repo: reminder preferences delete failed", res.Error, map[string]interface{}{
+			"user_id": userID,
+		})
+		return 0, mapError(res.Error, myerrors.ErrDatabase)
+	}
+
+	return res.RowsAffected, nil
+}
+
+// claimQuery вставляет строку журнала или, если она есть, перехватывает
+// её, когда напоминание не отправлено и аренда истекла. RETURNING
+// возвращает строку только в этих двух случаях.
+const claimQuery = `
+INSERT INTO reminder_notifications
+    (id, user_id, subscription_id, kind, due_at, channel, attempts, claimed_until, created_at)
+VALUES (?, ?, ?, ?, ?, ?, 1, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    attempts = reminder_notifications.attempts + 1,
+    channel = excluded.channel,
+    claimed_until = excluded.claimed_until
+WHERE reminder_notifications.sent_at IS NULL
+    AND reminder_notifications.claimed_until <= ?
+RETURNING id`
+
+func (r *reminderRepository) Claim(ctx context.Context, n reminder.Notification, now time.Time, lease time.Duration) (bool, error) {
+	var ids []uuid.UUID
+	now = now.UTC()
+
+	err := conn(ctx, r.DB).
+		Raw(claimQuery, n.ID, n.UserID, n.SubscriptionID, n.Kind, n.DueAt.UTC(), n.Channel, now.Add(lease), now, now).
+		Scan(&ids).Error
+	if err != nil {
+		logger.Error(ctx, "repo: reminder claim failed", err, map[string]interface{}{
+			"id":              n.ID,
+			"subscription_id": n.SubscriptionID,
+		})
+		return false, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return len(ids) == 1, nil
+}
+
+func (r *reminderRepository) MarkSent(ctx context.Context, id uuid.UUID, at time.Time) error {
+	err := conn(ctx, r.DB).
+		Model(&models.ReminderNotification{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"sent_at":    at.UTC(),
+			"last_error": nil,
+		}).Error
+	if err != nil {
+		logger.Error(ctx, "repo: reminder mark sent failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return mapError(err, myerrors.ErrUpdateFailed)
+	}
+
+	return nil
+}
+
+func (r *reminderRepository) Release(ctx context.Context, id uuid.UUID, retryAt time.Time, reason string) error {
+	err := conn(ctx, r.DB).
+		Model(&models.ReminderNotification{}).
+		Where("id = ? AND sent_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"claimed_until": retryAt.UTC(),
+			"last_error":    reason,
+		}).Error
+	if err != nil {
+		logger.Error(ctx, "repo: reminder release failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return mapError(err, myerrors.ErrUpdateFailed)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+	"testingtask/internal/database"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+)
+
+func TestMemoryReminderRepository(t *testing.T) {
+	repotest.RunReminderRepository(t, func(t *testing.T) repository.ReminderRepository {
+		return repository.NewMemoryReminderRepository()
+	})
+}
+
+func TestSQLiteReminderRepository(t *testing.T) {
+	repotest.RunReminderRepository(t, func(t *testing.T) repository.ReminderRepository {
+		url := database.SQLiteScheme + filepath.Join(t.TempDir(), "subs.db")
+		return repository.NewReminderRepository(openSQLite(t, url))
+	})
+}
+
+func TestReminderRepository(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+
+	repotest.RunReminderRepository(t, func(t *testing.T) repository.ReminderRepository {
+		if err := db.Exec("TRUNCATE reminder_preferences, reminder_notifications").Error; err != nil {
+			t.Fatal(err)
+		}
+		resetSubscriptions(t, db)
+		return repository.NewReminderRepository(db)
+	})
+}
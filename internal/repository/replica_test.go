@@ -0,0 +1,126 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"testingtask/internal/config"
+	"testingtask/internal/database"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/readpref"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// openWithReplica открывает primary dsn с одной репликой через
+// database.InitDB, как это делает сервер.
+func openWithReplica(t *testing.T, dsn, replicaDSN string) *gorm.DB {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Database.URL = dsn
+	cfg.Database.Replicas.URLs = []string{replicaDSN}
+
+	db, err := database.InitDB(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = database.Close(db) })
+
+	return db
+}
+
+// TestReplicaRouting проверяет маршрутизацию чтения. Роль реплики играет
+// отдельная база на том же сервере с той же схемой: строка, записанная
+// только в неё, видна при обычном чтении, но не при чтении с primary
+// (readpref) и не в транзакции.
+func TestReplicaRouting(t *testing.T) {
+	dsn := pgtest.DSN(t)
+	primary := openDB(t, dsn)
+	owner := uuid.New()
+	resetSubscriptions(t, primary, owner)
+
+	const replicaName = "repotest_replica"
+	for _, q := range []string{
+		"DROP DATABASE IF EXISTS " + replicaName + " WITH (FORCE)",
+		"CREATE DATABASE " + replicaName,
+	} {
+		if err := primary.Exec(q).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Skipf("TEST_DATABASE_URL is not a URL: %v", err)
+	}
+	u.Path = "/" + replicaName
+	replicaDSN := u.String()
+
+	ctx := context.Background()
+	start := domain.NewSubDateFromTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	replica := openDB(t, replicaDSN)
+	resetSubscriptions(t, replica, owner)
+	onReplica := repotest.Sub(owner, "Netflix", 400, start, nil)
+	if err := repository.NewSubRepository(replica).Create(ctx, onReplica); err != nil {
+		t.Fatal(err)
+	}
+
+	db := openWithReplica(t, dsn, replicaDSN)
+	repo := repository.NewSubRepository(db)
+	tx := repository.NewTxManager(db)
+
+	if _, err := repo.Get(ctx, onReplica.ID()); err != nil {
+		t.Fatalf("Get: %v, want the row from the replica", err)
+	}
+	if _, err := repo.Get(readpref.WithPrimary(ctx), onReplica.ID()); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("Get with primary preference: got %v, want ErrNotFound", err)
+	}
+	err = tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		_, err := repo.Get(ctx, onReplica.ID())
+		return err
+	})
+	if !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("Get in transaction: got %v, want ErrNotFound", err)
+	}
+
+	written := repotest.Sub(owner, "Spotify", 200, start, nil)
+	if err := repo.Create(ctx, written); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Get(readpref.WithPrimary(ctx), written.ID()); err != nil {
+		t.Fatalf("Get written row from primary: %v", err)
+	}
+	if _, err := repo.Get(ctx, written.ID()); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("Get written row from replica: got %v, want ErrNotFound", err)
+	}
+}
+
+// TestReplicaUnreachable проверяет, что недоступная реплика не мешает
+// старту, а чтение уходит на primary.
+func TestReplicaUnreachable(t *testing.T) {
+	dsn := pgtest.DSN(t)
+	primary := openDB(t, dsn)
+	owner := uuid.New()
+	resetSubscriptions(t, primary, owner)
+
+	db := openWithReplica(t, dsn, "postgres://postgres@127.0.0.1:1/postgres?sslmode=disable&connect_timeout=1")
+	repo := repository.NewSubRepository(db)
+
+	ctx := context.Background()
+	sub := repotest.Sub(owner, "Netflix", 400, domain.NewSubDateFromTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)), nil)
+	if err := repo.Create(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Get(ctx, sub.ID()); err != nil {
+		t.Fatalf("Get: %v, want the row from the primary", err)
+	}
+}
@@ -0,0 +1,80 @@
+package repotest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"testingtask/internal/domain/analytics"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/repository"
+	"time"
+)
+
+// AnalyticsFactory возвращает пустое хранилище подписок и отчёты по нему
+// для одного подтеста.
+type AnalyticsFactory func(t *testing.T) (repository.SubRepository, repository.AnalyticsRepository)
+
+// RunAnalyticsRepository прогоняет сценарии AnalyticsRepository.
+func RunAnalyticsRepository(t *testing.T, newRepo AnalyticsFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, subs repository.SubRepository, r repository.AnalyticsRepository)
+	}{
+		{"Revenue", testAnalyticsRevenue},
+		{"Empty", testAnalyticsEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs, r := newRepo(t)
+			tt.fn(t, subs, r)
+		})
+	}
+}
+
+// testAnalyticsRevenue сравнивает выручку из хранилища с
+// analytics.Aggregate по тем же подпискам.
+func testAnalyticsRevenue(t *testing.T, subs repository.SubRepository, r repository.AnalyticsRepository) {
+	all := []*domain.Subscription{
+		Sub(userA, "Netflix", 400, month(2025, time.January), nil),
+		Sub(userA, "Netflix", 100, month(2025, time.March), month(2025, time.March)),
+		Sub(userA, "Spotify", 200, month(2025, time.February), month(2025, time.March)),
+		Sub(userB, "Netflix", 1000, month(2024, time.December), month(2025, time.February)),
+		Sub(userC, "Spotify", 300, month(2025, time.April), nil),
+	}
+	mustCreate(t, subs, all...)
+
+	months, err := analytics.Range(month(2025, time.January).Time, month(2025, time.April).Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.Revenue(context.Background(), months)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := analytics.Aggregate(all, months)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Revenue:\n got %+v\nwant %+v", got, want)
+	}
+
+	// Две подписки пользователя на один сервис складываются.
+	march := got[2]
+	if len(march.Revenue) != 2 || march.Revenue[0].MRR != 500 || march.Revenue[0].Subscriptions != 2 {
+		t.Fatalf("March: %+v", march.Revenue)
+	}
+}
+
+func testAnalyticsEmpty(t *testing.T, subs repository.SubRepository, r repository.AnalyticsRepository) {
+	mustCreate(t, subs, Sub(userA, "Netflix", 400, month(2025, time.June), nil))
+
+	// Месяцы не обязаны идти подряд.
+	months := []time.Time{month(2025, time.May).Time, month(2025, time.January).Time}
+	got, err := r.Revenue(context.Background(), months)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !got[0].Month.Equal(months[0]) || len(got[0].Revenue) != 0 || len(got[1].Revenue) != 0 {
+		t.Fatalf("Revenue before any subscription: %+v", got)
+	}
+}
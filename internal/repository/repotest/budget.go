@@ -0,0 +1,204 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testingtask/internal/domain/budget"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BudgetFactory возвращает пустое хранилище бюджетов для одного подтеста.
+type BudgetFactory func(t *testing.T) repository.BudgetRepository
+
+// RunBudgetRepository прогоняет сценарии BudgetRepository.
+func RunBudgetRepository(t *testing.T, newRepo BudgetFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r repository.BudgetRepository)
+	}{
+		{"CRUD", testBudgetCRUD},
+		{"UniqueScope", testBudgetUniqueScope},
+		{"ListByUser", testBudgetListByUser},
+		{"DeleteByUser", testBudgetDeleteByUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// budgetAt — бюджет с заданным временем создания, чтобы порядок выдачи не
+// зависел от точности часов.
+func budgetAt(user uuid.UUID, service *string, limit int, at time.Time) *budget.Budget {
+	return budget.RestoreBudget(uuid.New(), user, service, nil, limit, false, at)
+}
+
+func testBudgetCRUD(t *testing.T, r repository.BudgetRepository) {
+	ctx := context.Background()
+	service := "Netflix"
+	b := budgetAt(userA, &service, 1000, outboxEpoch)
+
+	if err := r.Create(ctx, b); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := r.Get(ctx, b.ID())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.UserID() != b.UserID() || got.ServiceName() == nil || *got.ServiceName() != service ||
+		got.MonthlyLimit() != 1000 || got.HardLimit() || !got.CreatedAt().Equal(outboxEpoch) {
+		t.Fatalf("Get: got %+v, want %+v", got, b)
+	}
+
+	// Update меняет только лимиты, пользователь и сервис остаются.
+	other := "Spotify"
+	upd := budget.RestoreBudget(b.ID(), userB, &other, ptr("music"), 2500, true, outboxEpoch.Add(time.Hour))
+	if err := r.Update(ctx, upd); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err = r.Get(ctx, b.ID())
+	if err != nil {
+		t.Fatalf("Get after update: %v", err)
+	}
+	if got.MonthlyLimit() != 2500 || !got.HardLimit() {
+		t.Errorf("limits after update: got %d/%t, want 2500/true", got.MonthlyLimit(), got.HardLimit())
+	}
+	if got.UserID() != b.UserID() || *got.ServiceName() != service || got.Category() != nil || !got.CreatedAt().Equal(outboxEpoch) {
+		t.Errorf("scope changed by update: got %s/%s/%v/%s", got.UserID(), *got.ServiceName(), got.Category(), got.CreatedAt())
+	}
+
+	if err := r.Delete(ctx, b.ID()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := r.Get(ctx, b.ID()); !errors.Is(err, myerrors.ErrBudgetNotFound) {
+		t.Fatalf("Get after delete: got %v, want ErrBudgetNotFound", err)
+	}
+	if err := r.Update(ctx, b); !errors.Is(err, myerrors.ErrBudgetNotFound) {
+		t.Fatalf("Update after delete: got %v, want ErrBudgetNotFound", err)
+	}
+	if err := r.Delete(ctx, b.ID()); err != nil {
+		t.Fatalf("Delete missing: %v", err)
+	}
+}
+
+func testBudgetUniqueScope(t *testing.T, r repository.BudgetRepository) {
+	ctx := context.Background()
+	user := userA
+	netflix, spotify := "Netflix", "Spotify"
+
+	video := func(limit int) *budget.Budget {
+		return budget.RestoreBudget(uuid.New(), user, nil, ptr("video"), limit, false, outboxEpoch)
+	}
+
+	for _, b := range []*budget.Budget{
+		budgetAt(user, nil, 5000, outboxEpoch),
+		budgetAt(user, &netflix, 1000, outboxEpoch),
+		budgetAt(user, &spotify, 500, outboxEpoch),
+		video(2000),
+		budgetAt(userB, nil, 5000, outboxEpoch),
+	} {
+		if err := r.Create(ctx, b); err != nil {
+			t.Fatalf("Create(%v, %v): %v", b.ServiceName(), b.Category(), err)
+		}
+	}
+
+	for _, b := range []*budget.Budget{
+		budgetAt(user, nil, 100, outboxEpoch),
+		budgetAt(user, &netflix, 100, outboxEpoch),
+		video(100),
+	} {
+		if err := r.Create(ctx, b); !errors.Is(err, myerrors.ErrInvalidData) {
+			t.Errorf("duplicate Create(%v, %v): got %v, want ErrInvalidData", b.ServiceName(), b.Category(), err)
+		}
+	}
+
+	got, err := r.ForUser(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	categories := 0
+	for _, b := range got {
+		if b.Category() != nil {
+			categories++
+			if *b.Category() != "video" || b.ServiceName() != nil {
+				t.Errorf("category budget: got %v/%v", b.ServiceName(), *b.Category())
+			}
+		}
+	}
+	if categories != 1 {
+		t.Errorf("ForUser: %d category budgets, want 1", categories)
+	}
+}
+
+func testBudgetListByUser(t *testing.T, r repository.BudgetRepository) {
+	ctx := context.Background()
+	user := userA
+	services := []string{"A", "B", "C"}
+
+	var want []uuid.UUID
+	for i := range services {
+		b := budgetAt(user, &services[i], 100, outboxEpoch.Add(time.Duration(i)*time.Minute))
+		if err := r.Create(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, b.ID())
+	}
+	if err := r.Create(ctx, budgetAt(userB, nil, 100, outboxEpoch)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, total, err := r.List(ctx, &user, domain.NewPagingBase(2, 1))
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 3 || len(got) != 2 || got[0].ID() != want[1] || got[1].ID() != want[2] {
+		t.Fatalf("List(user, 2, 1): got %d rows of %d total, want budgets B, C of 3", len(got), total)
+	}
+
+	if _, total, err = r.List(ctx, nil, domain.NewPagingBase(10, 0)); err != nil || total != 4 {
+		t.Fatalf("List(all): total %d, err %v, want 4", total, err)
+	}
+
+	all, err := r.ForUser(ctx, user)
+	if err != nil {
+		t.Fatalf("ForUser: %v", err)
+	}
+	if len(all) != 3 || all[0].ID() != want[0] {
+		t.Fatalf("ForUser: got %d budgets, want 3 starting with A", len(all))
+	}
+}
+
+func testBudgetDeleteByUser(t *testing.T, r repository.BudgetRepository) {
+	ctx := context.Background()
+	netflix := "Netflix"
+
+	for _, b := range []*budget.Budget{
+		budgetAt(userA, nil, 5000, outboxEpoch),
+		budgetAt(userA, &netflix, 1000, outboxEpoch),
+		budgetAt(userB, nil, 5000, outboxEpoch),
+	} {
+		if err := r.Create(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := r.DeleteByUser(ctx, userA)
+	if err != nil || n != 2 {
+		t.Fatalf("DeleteByUser = %d, %v, want 2", n, err)
+	}
+	if left, err := r.ForUser(ctx, userA); err != nil || len(left) != 0 {
+		t.Fatalf("ForUser after delete: %d budgets, %v", len(left), err)
+	}
+	if left, err := r.ForUser(ctx, userB); err != nil || len(left) != 1 {
+		t.Fatalf("ForUser(other user): %d budgets, %v, want 1", len(left), err)
+	}
+}
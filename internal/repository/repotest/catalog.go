@@ -0,0 +1,274 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"testingtask/internal/domain/catalog"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CatalogFactory возвращает пустые хранилища подписок и каталога для
+// одного подтеста.
+type CatalogFactory func(t *testing.T) (repository.SubRepository, repository.CatalogRepository)
+
+// RunCatalogRepository прогоняет сценарии CatalogRepository.
+func RunCatalogRepository(t *testing.T, newRepo CatalogFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, subs repository.SubRepository, r repository.CatalogRepository)
+	}{
+		{"CRUD", testCatalogCRUD},
+		{"Resolve", testCatalogResolve},
+		{"KeyTaken", testCatalogKeyTaken},
+		{"ListByCategory", testCatalogList},
+		{"LinkedSubscriptions", testCatalogLinkedSubscriptions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs, r := newRepo(t)
+			tt.fn(t, subs, r)
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func mustService(t *testing.T, r repository.CatalogRepository, name string, aliases []string, category *string) *catalog.Service {
+	t.Helper()
+	s, err := catalog.NewService(uuid.Nil, name, aliases, category, nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = catalog.RestoreService(s.ID(), s.Name(), s.Aliases(), s.Category(), s.DefaultPrice(), s.Currency(), s.LogoURL(), outboxEpoch)
+	if err := r.Create(context.Background(), s); err != nil {
+		t.Fatalf("Create(%s): %v", name, err)
+	}
+	return s
+}
+
+func testCatalogCRUD(t *testing.T, _ repository.SubRepository, r repository.CatalogRepository) {
+	ctx := context.Background()
+	s, err := catalog.NewService(uuid.Nil, "Netflix", []string{"NFLX", "Нетфликс"}, ptr("streaming"), ptr(799), "USD", ptr("https://example.com/netflix.svg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = catalog.RestoreService(s.ID(), s.Name(), s.Aliases(), s.Category(), s.DefaultPrice(), s.Currency(), s.LogoURL(), outboxEpoch)
+	if err := r.Create(ctx, s); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := r.Get(ctx, s.ID())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Name() != "Netflix" || *got.Category() != "streaming" || *got.DefaultPrice() != 799 ||
+		got.Currency() != "USD" || *got.LogoURL() != "https://example.com/netflix.svg" || !got.CreatedAt().Equal(outboxEpoch) {
+		t.Fatalf("Get: got %+v", got)
+	}
+	if !reflect.DeepEqual(got.Keys(), s.Keys()) {
+		t.Fatalf("keys: got %v, want %v", got.Keys(), s.Keys())
+	}
+
+	upd, err := catalog.NewService(s.ID(), "Netflix Premium", []string{"Netflix"}, nil, nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Update(ctx, upd); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err = r.Get(ctx, s.ID())
+	if err != nil {
+		t.Fatalf("Get after update: %v", err)
+	}
+	if got.Name() != "Netflix Premium" || got.Category() != nil || got.DefaultPrice() != nil ||
+		got.Currency() != catalog.DefaultCurrency || got.LogoURL() != nil || !got.CreatedAt().Equal(outboxEpoch) {
+		t.Fatalf("Get after update: got %+v", got)
+	}
+	// Ключи заменяются целиком: NFLX больше не сводится к сервису.
+	if !reflect.DeepEqual(got.Aliases(), []string{"Netflix"}) {
+		t.Fatalf("aliases after update: %v", got.Aliases())
+	}
+	if _, err := r.Resolve(ctx, "nflx"); !errors.Is(err, myerrors.ErrServiceNotFound) {
+		t.Fatalf("Resolve dropped alias: got %v, want ErrServiceNotFound", err)
+	}
+
+	if err := r.Delete(ctx, s.ID()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := r.Get(ctx, s.ID()); !errors.Is(err, myerrors.ErrServiceNotFound) {
+		t.Fatalf("Get after delete: got %v, want ErrServiceNotFound", err)
+	}
+	if _, err := r.Resolve(ctx, "Netflix"); !errors.Is(err, myerrors.ErrServiceNotFound) {
+		t.Fatalf("Resolve after delete: got %v, want ErrServiceNotFound", err)
+	}
+	if err := r.Update(ctx, upd); !errors.Is(err, myerrors.ErrServiceNotFound) {
+		t.Fatalf("Update after delete: got %v, want ErrServiceNotFound", err)
+	}
+	if err := r.Delete(ctx, s.ID()); err != nil {
+		t.Fatalf("Delete missing: %v", err)
+	}
+}
+
+func testCatalogResolve(t *testing.T, _ repository.SubRepository, r repository.CatalogRepository) {
+	ctx := context.Background()
+	netflix := mustService(t, r, "Netflix", []string{"NFLX"}, nil)
+	mustService(t, r, "Yandex Plus", []string{"Яндекс Плюс"}, nil)
+
+	for _, name := range []string{"Netflix", "netflix ", "Net-Flix", "Нетфликс", "nflx"} {
+		got, err := r.Resolve(ctx, name)
+		if err != nil {
+			t.Fatalf("Resolve(%q): %v", name, err)
+		}
+		if got.ID() != netflix.ID() || got.Name() != "Netflix" {
+			t.Fatalf("Resolve(%q) = %s, want Netflix", name, got.Name())
+		}
+	}
+
+	if got, err := r.Resolve(ctx, "яндекс плюс"); err != nil || got.Name() != "Yandex Plus" {
+		t.Fatalf("Resolve(яндекс плюс) = %v, %v, want Yandex Plus", got, err)
+	}
+	for _, name := range []string{"Spotify", "", "!!!"} {
+		if _, err := r.Resolve(ctx, name); !errors.Is(err, myerrors.ErrServiceNotFound) {
+			t.Errorf("Resolve(%q): got %v, want ErrServiceNotFound", name, err)
+		}
+	}
+}
+
+func testCatalogKeyTaken(t *testing.T, _ repository.SubRepository, r repository.CatalogRepository) {
+	ctx := context.Background()
+	netflix := mustService(t, r, "Netflix", []string{"NFLX"}, nil)
+	spotify := mustService(t, r, "Spotify", nil, nil)
+
+	dup, err := catalog.NewService(uuid.Nil, "Netflix HD", []string{"n-f-l-x"}, nil, nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Create(ctx, dup); !errors.Is(err, myerrors.ErrInvalidData) {
+		t.Fatalf("Create with taken alias: got %v, want ErrInvalidData", err)
+	}
+	if _, err := r.Get(ctx, dup.ID()); !errors.Is(err, myerrors.ErrServiceNotFound) {
+		t.Fatalf("rejected service stored: %v", err)
+	}
+
+	upd, err := catalog.NewService(spotify.ID(), "Spotify", []string{"Spotify Music", "netflix"}, nil, nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Update(ctx, upd); !errors.Is(err, myerrors.ErrInvalidData) {
+		t.Fatalf("Update with taken alias: got %v, want ErrInvalidData", err)
+	}
+
+	// Неудачное обновление не меняет ключей ни одного из сервисов.
+	if got, err := r.Resolve(ctx, "spotify"); err != nil || got.ID() != spotify.ID() {
+		t.Fatalf("Resolve(spotify) after failed update = %v, %v", got, err)
+	}
+	if _, err := r.Resolve(ctx, "spotify music"); !errors.Is(err, myerrors.ErrServiceNotFound) {
+		t.Fatalf("Resolve(spotify music) after failed update: got %v, want ErrServiceNotFound", err)
+	}
+	if got, err := r.Resolve(ctx, "netflix"); err != nil || got.ID() != netflix.ID() {
+		t.Fatalf("Resolve(netflix) after failed update = %v, %v", got, err)
+	}
+}
+
+func testCatalogList(t *testing.T, _ repository.SubRepository, r repository.CatalogRepository) {
+	ctx := context.Background()
+	mustService(t, r, "Spotify", nil, ptr("music"))
+	mustService(t, r, "Netflix", []string{"NFLX"}, ptr("streaming"))
+	mustService(t, r, "Apple Music", nil, ptr("music"))
+	mustService(t, r, "Kinopoisk", nil, ptr("streaming"))
+	mustService(t, r, "iCloud", nil, nil)
+
+	names := func(list []*catalog.Service) []string {
+		res := make([]string, 0, len(list))
+		for _, s := range list {
+			res = append(res, s.Name())
+		}
+		return res
+	}
+
+	got, total, err := r.List(ctx, nil, domain.NewPagingBase(2, 1))
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if want := []string{"Kinopoisk", "Netflix"}; total != 5 || !reflect.DeepEqual(names(got), want) {
+		t.Fatalf("List(2, 1) = %v of %d, want %v of 5", names(got), total, want)
+	}
+	if len(got[1].Aliases()) != 1 || got[1].Aliases()[0] != "NFLX" {
+		t.Fatalf("List aliases: %v", got[1].Aliases())
+	}
+
+	got, total, err = r.List(ctx, ptr("music"), domain.NewPagingBase(10, 0))
+	if err != nil {
+		t.Fatalf("List(music): %v", err)
+	}
+	if want := []string{"Apple Music", "Spotify"}; total != 2 || !reflect.DeepEqual(names(got), want) {
+		t.Fatalf("List(music) = %v of %d, want %v", names(got), total, want)
+	}
+
+	if got, total, err = r.List(ctx, ptr("cloud"), domain.NewPagingBase(10, 0)); err != nil || total != 0 || len(got) != 0 {
+		t.Fatalf("List(cloud) = %d of %d, %v, want empty", len(got), total, err)
+	}
+
+	all, _, err := r.List(ctx, nil, domain.NewPagingBase(10, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[uuid.UUID]string{}
+	for _, s := range all {
+		if s.Category() != nil {
+			want[s.ID()] = *s.Category()
+		}
+	}
+	if got, err := r.Categories(ctx); err != nil || !reflect.DeepEqual(got, want) || len(got) != 4 {
+		t.Fatalf("Categories = %v, %v, want %v", got, err, want)
+	}
+}
+
+func testCatalogLinkedSubscriptions(t *testing.T, subs repository.SubRepository, r repository.CatalogRepository) {
+	ctx := context.Background()
+	netflix := mustService(t, r, "Netflix", nil, nil)
+	spotify := mustService(t, r, "Spotify", nil, nil)
+	id := netflix.ID()
+
+	linked := Sub(userA, "Netflix", 400, month(2025, time.January), nil)
+	linked.LinkService(&id, "")
+	other := Sub(userB, "Spotify", 200, month(2025, time.January), nil)
+	sid := spotify.ID()
+	other.LinkService(&sid, "")
+	free := Sub(userB, "Netflix", 400, month(2025, time.January), nil)
+	mustCreate(t, subs, linked, other, free)
+
+	got, err := subs.Get(ctx, linked.ID())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	assertEqualSub(t, got, linked)
+
+	list, err := subs.ListByService(ctx, netflix.ID())
+	if err != nil {
+		t.Fatalf("ListByService: %v", err)
+	}
+	if want := []uuid.UUID{linked.ID()}; !sameIDs(ids(list), want) {
+		t.Fatalf("ListByService = %v, want %v", ids(list), want)
+	}
+
+	// Update пишет и отвязку.
+	got.LinkService(nil, "")
+	if err := subs.Update(ctx, got); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if list, err = subs.ListByService(ctx, netflix.ID()); err != nil || len(list) != 0 {
+		t.Fatalf("ListByService after unlink = %d rows, %v, want 0", len(list), err)
+	}
+	if got, err = subs.Get(ctx, linked.ID()); err != nil || got.ServiceID() != nil {
+		t.Fatalf("Get after unlink: service id %v, %v", got.ServiceID(), err)
+	}
+}
@@ -0,0 +1,171 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"testingtask/internal/repository"
+	"testingtask/internal/scheduler"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobFactory возвращает пустое хранилище истории запусков для одного
+// подтеста.
+type JobFactory func(t *testing.T) repository.JobRepository
+
+// RunJobRepository прогоняет сценарии JobRepository.
+func RunJobRepository(t *testing.T, newRepo JobFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r repository.JobRepository)
+	}{
+		{"Runs", testJobRuns},
+		{"LastRuns", testJobLastRuns},
+		{"PurgeRuns", testJobPurgeRuns},
+		{"TryLock", testJobTryLock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// startRun записывает запуск job, начатый через offset после outboxEpoch.
+func startRun(t *testing.T, r repository.JobRepository, job string, offset time.Duration) scheduler.Run {
+	t.Helper()
+
+	run := scheduler.Run{
+		ID:        uuid.New(),
+		Job:       job,
+		Trigger:   scheduler.TriggerSchedule,
+		Instance:  "replica-1",
+		Status:    scheduler.StatusRunning,
+		StartedAt: outboxEpoch.Add(offset),
+	}
+	if err := r.StartRun(context.Background(), run); err != nil {
+		t.Fatalf("StartRun: %v", err)
+	}
+	return run
+}
+
+func finishRun(t *testing.T, r repository.JobRepository, run scheduler.Run, status string, errText *string) {
+	t.Helper()
+
+	if err := r.FinishRun(context.Background(), run.ID, status, run.StartedAt.Add(time.Second), errText); err != nil {
+		t.Fatalf("FinishRun: %v", err)
+	}
+}
+
+func testJobRuns(t *testing.T, r repository.JobRepository) {
+	ctx := context.Background()
+
+	first := startRun(t, r, "expire", 0)
+	second := startRun(t, r, "expire", time.Hour)
+	third := startRun(t, r, "expire", 2*time.Hour)
+	startRun(t, r, "purge", 0)
+
+	reason := "database is down"
+	finishRun(t, r, first, scheduler.StatusSucceeded, nil)
+	finishRun(t, r, second, scheduler.StatusFailed, &reason)
+
+	runs, total, err := r.Runs(ctx, "expire", 2, 0)
+	if err != nil {
+		t.Fatalf("Runs: %v", err)
+	}
+	if total != 3 || len(runs) != 2 {
+		t.Fatalf("Runs = %d rows of %d, want 2 of 3", len(runs), total)
+	}
+	if runs[0].ID != third.ID || runs[1].ID != second.ID {
+		t.Fatalf("Runs order = %s, %s, want newest first", runs[0].ID, runs[1].ID)
+	}
+	if runs[0].Status != scheduler.StatusRunning || runs[0].FinishedAt != nil {
+		t.Fatalf("running run = %+v", runs[0])
+	}
+	got := runs[1]
+	if got.Status != scheduler.StatusFailed || got.Error == nil || *got.Error != reason ||
+		got.FinishedAt == nil || !got.FinishedAt.Equal(second.StartedAt.Add(time.Second)) ||
+		got.Trigger != scheduler.TriggerSchedule || got.Instance != "replica-1" {
+		t.Fatalf("failed run = %+v", got)
+	}
+
+	runs, _, err = r.Runs(ctx, "expire", 10, 2)
+	if err != nil || len(runs) != 1 || runs[0].ID != first.ID {
+		t.Fatalf("Runs(offset 2) = %v, %v, want the first run", runs, err)
+	}
+}
+
+func testJobLastRuns(t *testing.T, r repository.JobRepository) {
+	startRun(t, r, "expire", 0)
+	latest := startRun(t, r, "expire", time.Hour)
+	purge := startRun(t, r, "purge", 0)
+
+	last, err := r.LastRuns(context.Background())
+	if err != nil {
+		t.Fatalf("LastRuns: %v", err)
+	}
+	if len(last) != 2 || last["expire"].ID != latest.ID || last["purge"].ID != purge.ID {
+		t.Fatalf("LastRuns = %+v", last)
+	}
+	if !last["expire"].StartedAt.Equal(latest.StartedAt) {
+		t.Fatalf("LastRuns started_at = %s, want %s", last["expire"].StartedAt, latest.StartedAt)
+	}
+}
+
+func testJobPurgeRuns(t *testing.T, r repository.JobRepository) {
+	ctx := context.Background()
+
+	old := startRun(t, r, "expire", 0)
+	finishRun(t, r, old, scheduler.StatusSucceeded, nil)
+	// Запуск, который так и не завершился, остаётся в истории.
+	stuck := startRun(t, r, "expire", time.Minute)
+	recent := startRun(t, r, "expire", 48*time.Hour)
+	finishRun(t, r, recent, scheduler.StatusSucceeded, nil)
+
+	n, err := r.PurgeRuns(ctx, outboxEpoch.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeRuns: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("PurgeRuns removed %d, want 1", n)
+	}
+
+	runs, _, err := r.Runs(ctx, "expire", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != recent.ID || runs[1].ID != stuck.ID {
+		t.Fatalf("Runs after purge = %+v", runs)
+	}
+}
+
+func testJobTryLock(t *testing.T, r repository.JobRepository) {
+	ctx := context.Background()
+
+	lock, ok, err := r.TryLock(ctx, "scheduler:leader")
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %t, %v, want lock", ok, err)
+	}
+	if err := lock.Held(ctx); err != nil {
+		t.Fatalf("Held: %v", err)
+	}
+
+	if _, ok, err := r.TryLock(ctx, "scheduler:leader"); err != nil || ok {
+		t.Fatalf("second TryLock = %t, %v, want busy", ok, err)
+	}
+
+	other, ok, err := r.TryLock(ctx, "scheduler:job:expire")
+	if err != nil || !ok {
+		t.Fatalf("TryLock(other key) = %t, %v, want lock", ok, err)
+	}
+	other.Unlock()
+
+	lock.Unlock()
+	lock, ok, err = r.TryLock(ctx, "scheduler:leader")
+	if err != nil || !ok {
+		t.Fatalf("TryLock after unlock = %t, %v, want lock", ok, err)
+	}
+	lock.Unlock()
+}
@@ -0,0 +1,158 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// labeled — подписка с метками; метки проходят проверку SetLabels.
+func labeled(t *testing.T, user uuid.UUID, service string, price int, tags []string, metadata map[string]any) *domain.Subscription {
+	t.Helper()
+	s := Sub(user, service, price, month(2025, time.January), nil)
+	if err := s.SetLabels(tags, metadata); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func labelFilter(t *testing.T, tags []string, match string, metadata ...string) domain.LabelFilter {
+	t.Helper()
+	f, err := domain.NewLabelFilter(tags, &match, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func testLabels(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	s := labeled(t, userA, "Netflix", 400, []string{"Work", "tax-deductible", "work"}, map[string]any{
+		"reimbursed_by": "company",
+		"share":         0.5,
+		"invoice":       map[string]any{"required": true},
+	})
+	plain := Sub(userA, "Spotify", 200, month(2025, time.January), nil)
+	mustCreate(t, r, s, plain)
+
+	got, err := r.Get(ctx, s.ID())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	assertEqualSub(t, got, s)
+	if want := []string{"tax-deductible", "work"}; len(got.Tags()) != 2 || got.Tags()[0] != want[0] || got.Tags()[1] != want[1] {
+		t.Fatalf("tags: got %v, want %v", got.Tags(), want)
+	}
+
+	if got, err = r.Get(ctx, plain.ID()); err != nil {
+		t.Fatalf("Get plain: %v", err)
+	}
+	if got.Tags() != nil || got.Metadata() != nil {
+		t.Fatalf("plain labels: got %v %v, want none", got.Tags(), got.Metadata())
+	}
+
+	// Update заменяет метки целиком.
+	if err := s.SetLabels([]string{"family"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Update(ctx, s); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got, err = r.Get(ctx, s.ID()); err != nil {
+		t.Fatalf("Get after update: %v", err)
+	}
+	assertEqualSub(t, got, s)
+}
+
+func testListByLabels(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	work := labeled(t, userA, "Notion", 800, []string{"work"}, map[string]any{"reimbursed_by": "company"})
+	both := labeled(t, userA, "Zoom", 1200, []string{"work", "tax-deductible"}, map[string]any{"reimbursed_by": "company", "invoice": "INV-1"})
+	family := labeled(t, userB, "Netflix", 400, []string{"family"}, map[string]any{"reimbursed_by": "me", "share": 3})
+	plain := Sub(userB, "Spotify", 200, month(2025, time.January), nil)
+	mustCreate(t, r, work, both, family, plain)
+
+	tests := []struct {
+		name   string
+		filter domain.LabelFilter
+		want   []uuid.UUID
+	}{
+		{"none", domain.LabelFilter{}, []uuid.UUID{work.ID(), both.ID(), family.ID(), plain.ID()}},
+		{"any", labelFilter(t, []string{"tax-deductible", "family"}, "any"), []uuid.UUID{both.ID(), family.ID()}},
+		{"all", labelFilter(t, []string{"work", "tax-deductible"}, "all"), []uuid.UUID{both.ID()}},
+		{"all missing", labelFilter(t, []string{"work", "family"}, "all"), nil},
+		{"unknown tag", labelFilter(t, []string{"car"}, "any"), nil},
+		{"key", labelFilter(t, nil, "any", "invoice"), []uuid.UUID{both.ID()}},
+		{"key value", labelFilter(t, nil, "any", "reimbursed_by:company"), []uuid.UUID{work.ID(), both.ID()}},
+		// Значение сравнивается только со строкой: число 3 не равно "3".
+		{"non-string value", labelFilter(t, nil, "any", "share:3"), nil},
+		{"conflicting values", labelFilter(t, nil, "any", "reimbursed_by:company", "reimbursed_by:me"), nil},
+		{"tags and metadata", labelFilter(t, []string{"work"}, "any", "reimbursed_by:company", "invoice"), []uuid.UUID{both.ID()}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, total, err := r.List(ctx, tc.filter, domain.NewPagingBase(10, 0))
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if !sameIDs(ids(got), tc.want) || total != int64(len(tc.want)) {
+				t.Fatalf("List = %v of %d, want %v", ids(got), total, tc.want)
+			}
+		})
+	}
+
+	// Итог считается по всем подходящим строкам, а не по странице.
+	var paged []uuid.UUID
+	for offset := 0; offset < 2; offset++ {
+		page, total, err := r.List(ctx, labelFilter(t, []string{"work"}, "any"), domain.NewPagingBase(1, offset))
+		if err != nil {
+			t.Fatalf("List page: %v", err)
+		}
+		if len(page) != 1 || total != 2 {
+			t.Fatalf("List(1, %d) = %v of %d, want 1 row of 2", offset, ids(page), total)
+		}
+		paged = append(paged, page[0].ID())
+	}
+	if want := []uuid.UUID{work.ID(), both.ID()}; !sameIDs(paged, want) {
+		t.Fatalf("List pages = %v, want %v", paged, want)
+	}
+}
+
+func testSumByLabels(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	mustCreate(t, r,
+		labeled(t, userA, "Notion", 800, []string{"work"}, map[string]any{"reimbursed_by": "company"}),
+		labeled(t, userA, "Zoom", 1200, []string{"work", "tax-deductible"}, nil),
+		labeled(t, userA, "Netflix", 400, []string{"family"}, map[string]any{"reimbursed_by": "me"}),
+		labeled(t, userB, "Slack", 500, []string{"work"}, map[string]any{"reimbursed_by": "company"}),
+	)
+
+	tests := []struct {
+		name   string
+		labels domain.LabelFilter
+		sum    int
+		count  int
+	}{
+		{"work", labelFilter(t, []string{"work"}, "any"), 2000, 2},
+		{"personal", labelFilter(t, []string{"family", "tax-deductible"}, "any"), 1600, 2},
+		{"company", labelFilter(t, nil, "any", "reimbursed_by:company"), 800, 1},
+		{"company work", labelFilter(t, []string{"work", "tax-deductible"}, "all", "reimbursed_by:company"), 0, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := r.Sum(ctx, &domain.SubscriptionFilter{UserID: &userA, Labels: tc.labels, Limit: 10})
+			if err != nil {
+				t.Fatalf("Sum: %v", err)
+			}
+			if res.TotalSum != tc.sum || res.TotalCount != tc.count || len(res.Rows) != tc.count {
+				t.Fatalf("Sum = %d of %d (%d rows), want %d of %d", res.TotalSum, res.TotalCount, len(res.Rows), tc.sum, tc.count)
+			}
+		})
+	}
+}
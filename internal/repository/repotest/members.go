@@ -0,0 +1,185 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newSplit(t *testing.T, rule domain.SplitRule, sub *domain.Subscription, members ...domain.Member) *domain.Split {
+	t.Helper()
+	s, err := domain.NewSplit(rule, sub.UserID(), sub.Price(), members)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func percent(p float64) *float64 { return &p }
+
+func intPtr(v int) *int { return &v }
+
+func assertShares(t *testing.T, got *domain.Split, want map[uuid.UUID]int) {
+	t.Helper()
+	if got == nil || len(got.Members) != len(want) {
+		t.Fatalf("split = %+v, want shares %v", got, want)
+	}
+	for id, share := range want {
+		if s, ok := got.ShareOf(id); !ok || s != share {
+			t.Fatalf("share of %s = %d (%v), want %d", id, s, ok, share)
+		}
+	}
+}
+
+func testSplit(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	family := Sub(userA, "Spotify", 1000, month(2025, time.January), nil)
+	plain := Sub(userA, "Netflix", 400, month(2025, time.January), nil)
+	mustCreate(t, r, family, plain)
+
+	if got, err := r.Split(ctx, plain.ID()); err != nil || got != nil {
+		t.Fatalf("Split(plain) = %+v, %v, want nil", got, err)
+	}
+	if _, err := r.Split(ctx, uuid.New()); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("Split(missing) error = %v, want ErrNotFound", err)
+	}
+
+	split := newSplit(t, domain.SplitPercent, family,
+		domain.Member{UserID: userB, Percent: percent(33.33)},
+		domain.Member{UserID: userC, Percent: percent(25)})
+	if err := r.SaveSplit(ctx, family.ID(), split); err != nil {
+		t.Fatalf("SaveSplit: %v", err)
+	}
+
+	got, err := r.Split(ctx, family.ID())
+	if err != nil {
+		t.Fatalf("Split: %v", err)
+	}
+	if got.Rule != domain.SplitPercent || got.Owner() != userA {
+		t.Fatalf("Split = %s owned by %s, want percent owned by %s", got.Rule, got.Owner(), userA)
+	}
+	assertShares(t, got, map[uuid.UUID]int{userA: 417, userB: 333, userC: 250})
+	if p := got.Members[1].Percent; p == nil || *p != 33.33 {
+		t.Fatalf("percent of %s = %v, want 33.33", got.Members[1].UserID, p)
+	}
+
+	// SaveSplit заменяет участников целиком.
+	split = newSplit(t, domain.SplitFixed, family, domain.Member{UserID: userC, Amount: intPtr(300)})
+	if err := r.SaveSplit(ctx, family.ID(), split); err != nil {
+		t.Fatalf("SaveSplit fixed: %v", err)
+	}
+	if got, err = r.Split(ctx, family.ID()); err != nil {
+		t.Fatalf("Split fixed: %v", err)
+	}
+	if got.Rule != domain.SplitFixed {
+		t.Fatalf("rule = %s, want fixed", got.Rule)
+	}
+	assertShares(t, got, map[uuid.UUID]int{userA: 700, userC: 300})
+
+	if err := r.SaveSplit(ctx, family.ID(), nil); err != nil {
+		t.Fatalf("SaveSplit(nil): %v", err)
+	}
+	if got, err = r.Split(ctx, family.ID()); err != nil || got != nil {
+		t.Fatalf("Split after unshare = %+v, %v, want nil", got, err)
+	}
+}
+
+func testSumByShare(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	family := Sub(userA, "Spotify", 1000, month(2025, time.January), nil)
+	own := Sub(userA, "Netflix", 400, month(2025, time.January), nil)
+	other := Sub(userB, "Slack", 500, month(2025, time.January), nil)
+	mustCreate(t, r, family, own, other)
+	if err := r.SaveSplit(ctx, family.ID(), newSplit(t, domain.SplitEqual, family,
+		domain.Member{UserID: userB}, domain.Member{UserID: userC})); err != nil {
+		t.Fatalf("SaveSplit: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		user  uuid.UUID
+		sum   int
+		share map[uuid.UUID]int
+	}{
+		// Владелец платит остаток от деления: 1000 = 334 + 333 + 333.
+		{"owner", userA, 334 + 400, map[uuid.UUID]int{family.ID(): 334}},
+		{"member", userB, 333 + 500, map[uuid.UUID]int{family.ID(): 333}},
+		{"member only", userC, 333, map[uuid.UUID]int{family.ID(): 333}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := r.Sum(ctx, &domain.SubscriptionFilter{UserID: &tc.user, Limit: 10})
+			if err != nil {
+				t.Fatalf("Sum: %v", err)
+			}
+			if res.TotalSum != tc.sum {
+				t.Fatalf("Sum = %d, want %d", res.TotalSum, tc.sum)
+			}
+			for _, row := range res.Rows {
+				want, shared := tc.share[row.ID()]
+				got := row.Share()
+				if shared != (got != nil) || (shared && *got != want) {
+					t.Fatalf("share of %s = %v, want %d (shared %v)", row.ServiceName(), got, want, shared)
+				}
+			}
+		})
+	}
+
+	// Без пользователя в фильтре общая подписка считается по полной цене.
+	res, err := r.Sum(ctx, &domain.SubscriptionFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("Sum: %v", err)
+	}
+	if res.TotalSum != 1900 || res.TotalCount != 3 {
+		t.Fatalf("Sum = %d of %d, want 1900 of 3", res.TotalSum, res.TotalCount)
+	}
+}
+
+func testListSharedWith(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	family := Sub(userA, "Spotify", 1000, month(2025, time.January), nil)
+	mustCreate(t, r, family, Sub(userB, "Netflix", 400, month(2025, time.January), nil))
+	if err := r.SaveSplit(ctx, family.ID(), newSplit(t, domain.SplitEqual, family, domain.Member{UserID: userB})); err != nil {
+		t.Fatalf("SaveSplit: %v", err)
+	}
+
+	got, err := r.ListSharedWith(ctx, userB)
+	if err != nil {
+		t.Fatalf("ListSharedWith: %v", err)
+	}
+	if want := []uuid.UUID{family.ID()}; !sameIDs(ids(got), want) {
+		t.Fatalf("ListSharedWith(member) = %v, want %v", ids(got), want)
+	}
+
+	// Свои подписки владельца в список не входят.
+	if got, err = r.ListSharedWith(ctx, userA); err != nil || len(got) != 0 {
+		t.Fatalf("ListSharedWith(owner) = %v, %v, want none", ids(got), err)
+	}
+}
+
+func testDeleteShared(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	family := Sub(userA, "Spotify", 1000, month(2025, time.January), nil)
+	mustCreate(t, r, family)
+	if err := r.SaveSplit(ctx, family.ID(), newSplit(t, domain.SplitEqual, family, domain.Member{UserID: userB})); err != nil {
+		t.Fatalf("SaveSplit: %v", err)
+	}
+
+	if err := r.Delete(ctx, family.ID()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, err := r.ListSharedWith(ctx, userB); err != nil || len(got) != 0 {
+		t.Fatalf("ListSharedWith after Delete = %v, %v, want none", ids(got), err)
+	}
+	res, err := r.Sum(ctx, &domain.SubscriptionFilter{UserID: &userB, Limit: 10})
+	if err != nil || res.TotalSum != 0 {
+		t.Fatalf("Sum after Delete = %+v, %v, want 0", res, err)
+	}
+}
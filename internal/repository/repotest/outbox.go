@@ -0,0 +1,169 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"testingtask/internal/outbox"
+	"testingtask/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxFactory возвращает пустой outbox для одного подтеста.
+type OutboxFactory func(t *testing.T) repository.OutboxRepository
+
+// RunOutboxRepository прогоняет сценарии OutboxRepository.
+func RunOutboxRepository(t *testing.T, newRepo OutboxFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r repository.OutboxRepository)
+	}{
+		{"PendingOrder", testOutboxPendingOrder},
+		{"AddDuplicate", testOutboxAddDuplicate},
+		{"MarkPublished", testOutboxMarkPublished},
+		{"RetryBlocksAggregate", testOutboxRetryBlocksAggregate},
+		{"Lock", testOutboxLock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+var outboxEpoch = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// Event собирает событие агрегата, случившееся через offset после
+// outboxEpoch.
+func Event(aggregate uuid.UUID, typ string, offset time.Duration) outbox.Event {
+	payload, _ := json.Marshal(map[string]string{"id": aggregate.String()})
+	return outbox.Event{
+		ID:          uuid.New(),
+		Type:        typ,
+		AggregateID: aggregate,
+		Payload:     payload,
+		OccurredAt:  outboxEpoch.Add(offset),
+	}
+}
+
+func mustAdd(t *testing.T, r repository.OutboxRepository, events ...outbox.Event) {
+	t.Helper()
+	if err := r.Add(context.Background(), events...); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+}
+
+func pendingIDs(t *testing.T, r repository.OutboxRepository, now time.Time) []uuid.UUID {
+	t.Helper()
+
+	events, err := r.Pending(context.Background(), now, 100)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+
+	res := make([]uuid.UUID, 0, len(events))
+	for _, e := range events {
+		res = append(res, e.ID)
+	}
+	return res
+}
+
+func assertOrder(t *testing.T, got []uuid.UUID, want ...outbox.Event) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("Pending returned %d events, want %d", len(got), len(want))
+	}
+	for i, e := range want {
+		if got[i] != e.ID {
+			t.Fatalf("Pending[%d] = %s, want %s (%s)", i, got[i], e.ID, e.Type)
+		}
+	}
+}
+
+func testOutboxPendingOrder(t *testing.T, r repository.OutboxRepository) {
+	a, b := uuid.New(), uuid.New()
+	created := Event(a, "SubscriptionCreated", 0)
+	other := Event(b, "SubscriptionCreated", time.Second)
+	updated := Event(a, "SubscriptionUpdated", 2*time.Second)
+	mustAdd(t, r, created, other)
+	mustAdd(t, r, updated)
+
+	assertOrder(t, pendingIDs(t, r, outboxEpoch.Add(time.Minute)), created, other, updated)
+
+	events, err := r.Pending(context.Background(), outboxEpoch.Add(time.Minute), 1)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Pending(limit 1) = %d events, %v", len(events), err)
+	}
+	got := events[0]
+	if got.Type != created.Type || got.AggregateID != a || !got.OccurredAt.Equal(created.OccurredAt) ||
+		string(got.Payload) != string(created.Payload) || got.Attempts != 0 {
+		t.Fatalf("Pending returned %+v, want %+v", got, created)
+	}
+}
+
+func testOutboxAddDuplicate(t *testing.T, r repository.OutboxRepository) {
+	e := Event(uuid.New(), "SubscriptionExpired", 0)
+	mustAdd(t, r, e)
+	mustAdd(t, r, e)
+
+	assertOrder(t, pendingIDs(t, r, outboxEpoch.Add(time.Minute)), e)
+}
+
+func testOutboxMarkPublished(t *testing.T, r repository.OutboxRepository) {
+	ctx := context.Background()
+	first := Event(uuid.New(), "SubscriptionCreated", 0)
+	second := Event(uuid.New(), "SubscriptionCreated", time.Second)
+	mustAdd(t, r, first, second)
+
+	if err := r.MarkPublished(ctx, first.ID, outboxEpoch.Add(time.Minute)); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+
+	assertOrder(t, pendingIDs(t, r, outboxEpoch.Add(time.Minute)), second)
+}
+
+func testOutboxRetryBlocksAggregate(t *testing.T, r repository.OutboxRepository) {
+	ctx := context.Background()
+	a, b := uuid.New(), uuid.New()
+	failed := Event(a, "SubscriptionCreated", 0)
+	next := Event(a, "SubscriptionUpdated", time.Second)
+	other := Event(b, "SubscriptionCreated", 2*time.Second)
+	mustAdd(t, r, failed, next, other)
+
+	now := outboxEpoch.Add(time.Minute)
+	if err := r.MarkFailed(ctx, failed.ID, now.Add(time.Minute), "connection refused"); err != nil {
+		t.Fatalf("MarkFailed: %v", err)
+	}
+
+	// Пока первое событие ждёт повтора, второе событие агрегата тоже ждёт.
+	assertOrder(t, pendingIDs(t, r, now), other)
+
+	later := now.Add(2 * time.Minute)
+	events, err := r.Pending(ctx, later, 100)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(events) != 3 || events[0].ID != failed.ID || events[0].Attempts != 1 {
+		t.Fatalf("Pending after retry time = %+v, want failed event first with 1 attempt", events)
+	}
+}
+
+func testOutboxLock(t *testing.T, r repository.OutboxRepository) {
+	ctx := context.Background()
+
+	unlock, ok, err := r.TryLock(ctx)
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %t, %v, want lock", ok, err)
+	}
+	unlock()
+
+	unlock, ok, err = r.TryLock(ctx)
+	if err != nil || !ok {
+		t.Fatalf("TryLock after unlock = %t, %v, want lock", ok, err)
+	}
+	unlock()
+}
@@ -0,0 +1,156 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testingtask/internal/domain/reminder"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReminderFactory возвращает пустое хранилище напоминаний для одного
+// подтеста.
+type ReminderFactory func(t *testing.T) repository.ReminderRepository
+
+// RunReminderRepository прогоняет сценарии ReminderRepository.
+func RunReminderRepository(t *testing.T, newRepo ReminderFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r repository.ReminderRepository)
+	}{
+		{"Preferences", testReminderPreferences},
+		{"DeleteByUser", testReminderDeleteByUser},
+		{"ClaimOnce", testReminderClaimOnce},
+		{"Release", testReminderRelease},
+		{"LeaseExpiry", testReminderLeaseExpiry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func notification() reminder.Notification {
+	return reminder.NewNotification(uuid.New(), uuid.New(), reminder.KindExpiry, outboxEpoch.AddDate(0, 1, 0), reminder.ChannelLog)
+}
+
+func mustClaim(t *testing.T, r repository.ReminderRepository, n reminder.Notification, now time.Time, want bool) {
+	t.Helper()
+
+	got, err := r.Claim(context.Background(), n, now, time.Minute)
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if got != want {
+		t.Fatalf("Claim at %s = %t, want %t", now.Format(time.TimeOnly), got, want)
+	}
+}
+
+func testReminderPreferences(t *testing.T, r repository.ReminderRepository) {
+	ctx := context.Background()
+	user := userA
+
+	if _, err := r.GetPreferences(ctx, user); !errors.Is(err, myerrors.ErrPreferencesNotFound) {
+		t.Fatalf("GetPreferences(missing): got %v, want ErrPreferencesNotFound", err)
+	}
+
+	email := "user@example.com"
+	p, err := reminder.NewPreferences(user, true, 7, reminder.ChannelEmail, &email, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SavePreferences(ctx, p); err != nil {
+		t.Fatalf("SavePreferences: %v", err)
+	}
+
+	hook := "https://example.com/remind"
+	p, err = reminder.NewPreferences(user, false, 3, reminder.ChannelWebhook, nil, &hook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SavePreferences(ctx, p); err != nil {
+		t.Fatalf("SavePreferences(update): %v", err)
+	}
+
+	got, err := r.GetPreferences(ctx, user)
+	if err != nil {
+		t.Fatalf("GetPreferences: %v", err)
+	}
+	if got.Enabled() || got.DaysBefore() != 3 || got.Channel() != reminder.ChannelWebhook ||
+		got.Email() != nil || got.WebhookURL() == nil || *got.WebhookURL() != hook {
+		t.Fatalf("GetPreferences = %+v, want the updated preferences", got)
+	}
+}
+
+func testReminderDeleteByUser(t *testing.T, r repository.ReminderRepository) {
+	ctx := context.Background()
+	for _, user := range []uuid.UUID{userA, userB} {
+		p, err := reminder.NewPreferences(user, true, 7, reminder.ChannelLog, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.SavePreferences(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := r.DeleteByUser(ctx, userA); err != nil || n != 1 {
+		t.Fatalf("DeleteByUser = %d, %v, want 1", n, err)
+	}
+	if _, err := r.GetPreferences(ctx, userA); !errors.Is(err, myerrors.ErrPreferencesNotFound) {
+		t.Fatalf("GetPreferences after delete: got %v, want ErrPreferencesNotFound", err)
+	}
+	if _, err := r.GetPreferences(ctx, userB); err != nil {
+		t.Fatalf("GetPreferences(other user): %v", err)
+	}
+	if n, err := r.DeleteByUser(ctx, userA); err != nil || n != 0 {
+		t.Fatalf("DeleteByUser(again) = %d, %v, want 0", n, err)
+	}
+}
+
+func testReminderClaimOnce(t *testing.T, r repository.ReminderRepository) {
+	n := notification()
+	now := outboxEpoch
+
+	mustClaim(t, r, n, now, true)
+	// Второй экземпляр или повторная проверка не берёт взятое.
+	mustClaim(t, r, n, now.Add(time.Second), false)
+
+	if err := r.MarkSent(context.Background(), n.ID, now.Add(time.Second)); err != nil {
+		t.Fatalf("MarkSent: %v", err)
+	}
+	// Отправленное не выдаётся и после окончания аренды.
+	mustClaim(t, r, n, now.Add(time.Hour), false)
+
+	mustClaim(t, r, notification(), now, true)
+}
+
+func testReminderRelease(t *testing.T, r repository.ReminderRepository) {
+	n := notification()
+	now := outboxEpoch
+
+	mustClaim(t, r, n, now, true)
+	if err := r.Release(context.Background(), n.ID, now.Add(10*time.Second), "smtp: connection refused"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	mustClaim(t, r, n, now.Add(5*time.Second), false)
+	mustClaim(t, r, n, now.Add(10*time.Second), true)
+}
+
+func testReminderLeaseExpiry(t *testing.T, r repository.ReminderRepository) {
+	n := notification()
+	now := outboxEpoch
+
+	// Экземпляр взял напоминание и упал, не отправив: после аренды его
+	// берёт другой.
+	mustClaim(t, r, n, now, true)
+	mustClaim(t, r, n, now.Add(30*time.Second), false)
+	mustClaim(t, r, n, now.Add(time.Minute), true)
+}
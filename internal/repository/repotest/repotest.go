@@ -0,0 +1,576 @@
+// Package repotest содержит общий набор тестов поведения SubRepository.
+// Каждая реализация (Postgres, память и т.д.) прогоняет его у себя, чтобы
+// их семантика не расходилась.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Factory возвращает пустой репозиторий для одного подтеста.
+type Factory func(t *testing.T) repository.SubRepository
+
+// RunSubRepository прогоняет все сценарии против реализации из newRepo.
+func RunSubRepository(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r repository.SubRepository)
+	}{
+		{"CreateGet", testCreateGet},
+		{"GetNotFound", testGetNotFound},
+		{"GetForUpdate", testGetForUpdate},
+		{"CreateDuplicate", testCreateDuplicate},
+		{"CreateInvalid", testCreateInvalid},
+		{"ListPaging", testListPaging},
+		{"ListPagingEdges", testListPagingEdges},
+		{"Count", testCount},
+		{"SumFilters", testSumFilters},
+		{"SumPaging", testSumPaging},
+		{"Update", testUpdate},
+		{"UpdateMissing", testUpdateMissing},
+		{"Delete", testDelete},
+		{"DeleteByUser", testDeleteByUser},
+		{"ListEnded", testListEnded},
+		{"Labels", testLabels},
+		{"ListByLabels", testListByLabels},
+		{"SumByLabels", testSumByLabels},
+		{"Split", testSplit},
+		{"SumByShare", testSumByShare},
+		{"ListSharedWith", testListSharedWith},
+		{"DeleteShared", testDeleteShared},
+		{"CanceledContext", testCanceledContext},
+		{"ExpiredDeadline", testExpiredDeadline},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+var (
+	userA = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	userB = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	userC = uuid.MustParse("33333333-3333-3333-3333-333333333333")
+)
+
+// Users — пользователи, которым сценарии создают подписки. Хранилище с
+// внешним ключом на users должно завести их до прогона.
+var Users = []uuid.UUID{userA, userB, userC}
+
+func month(year int, m time.Month) *domain.SubDate {
+	return domain.NewSubDateFromTime(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// Sub собирает подписку без доменной валидации дат, чтобы в тестах можно
+// было использовать прошедшие месяцы.
+func Sub(user uuid.UUID, service string, price int, start, end *domain.SubDate) *domain.Subscription {
+	return domain.RestoreSubscription(uuid.New(), service, domain.Price(price), user, *start, end)
+}
+
+func mustCreate(t *testing.T, r repository.SubRepository, subs ...*domain.Subscription) {
+	t.Helper()
+	for _, s := range subs {
+		if err := r.Create(context.Background(), s); err != nil {
+			t.Fatalf("Create(%s): %v", s.ServiceName(), err)
+		}
+	}
+}
+
+func ids(subs []*domain.Subscription) []uuid.UUID {
+	res := make([]uuid.UUID, 0, len(subs))
+	for _, s := range subs {
+		res = append(res, s.ID())
+	}
+	return res
+}
+
+func sameIDs(got, want []uuid.UUID) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := make(map[uuid.UUID]int, len(want))
+	for _, id := range want {
+		seen[id]++
+	}
+	for _, id := range got {
+		if seen[id] == 0 {
+			return false
+		}
+		seen[id]--
+	}
+	return true
+}
+
+func assertEqualSub(t *testing.T, got, want *domain.Subscription) {
+	t.Helper()
+	if got.ID() != want.ID() ||
+		got.ServiceName() != want.ServiceName() ||
+		got.Price() != want.Price() ||
+		got.UserID() != want.UserID() ||
+		!got.StartDate().Equal(want.StartDate()) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	ge, we := got.EndDate(), want.EndDate()
+	if (ge == nil) != (we == nil) || (ge != nil && !ge.Equal(*we)) {
+		t.Fatalf("end date: got %v, want %v", ge, we)
+	}
+
+	gs, ws := got.ServiceID(), want.ServiceID()
+	if (gs == nil) != (ws == nil) || (gs != nil && *gs != *ws) {
+		t.Fatalf("service id: got %v, want %v", gs, ws)
+	}
+
+	if !reflect.DeepEqual(got.Tags(), want.Tags()) || !reflect.DeepEqual(got.Metadata(), want.Metadata()) {
+		t.Fatalf("labels: got %v %v, want %v %v", got.Tags(), got.Metadata(), want.Tags(), want.Metadata())
+	}
+}
+
+func testCreateGet(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	withEnd := Sub(userA, "Netflix", 400, month(2025, 1), month(2025, 6))
+	noEnd := Sub(userA, "Spotify", 200, month(2024, 3), nil)
+	mustCreate(t, r, withEnd, noEnd)
+
+	for _, want := range []*domain.Subscription{withEnd, noEnd} {
+		got, err := r.Get(ctx, want.ID())
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		assertEqualSub(t, got, want)
+	}
+}
+
+func testGetNotFound(t *testing.T, r repository.SubRepository) {
+	_, err := r.Get(context.Background(), uuid.New())
+	if !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("got %v, want ErrNotFound", err)
+	}
+}
+
+func testGetForUpdate(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	want := Sub(userA, "Netflix", 400, month(2025, 1), nil)
+	mustCreate(t, r, want)
+
+	got, err := r.GetForUpdate(ctx, want.ID())
+	if err != nil {
+		t.Fatalf("GetForUpdate: %v", err)
+	}
+	assertEqualSub(t, got, want)
+
+	if _, err := r.GetForUpdate(ctx, uuid.New()); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("GetForUpdate(missing): got %v, want ErrNotFound", err)
+	}
+}
+
+func testCreateDuplicate(t *testing.T, r repository.SubRepository) {
+	s := Sub(userA, "Netflix", 400, month(2025, 1), nil)
+	mustCreate(t, r, s)
+
+	err := r.Create(context.Background(), s)
+	if !errors.Is(err, myerrors.ErrInvalidData) {
+		t.Fatalf("got %v, want ErrInvalidData", err)
+	}
+}
+
+func testCreateInvalid(t *testing.T, r repository.SubRepository) {
+	long := make([]byte, 101)
+	for i := range long {
+		long[i] = 'a'
+	}
+
+	cases := map[string]*domain.Subscription{
+		"zero price":       Sub(userA, "Netflix", 0, month(2025, 1), nil),
+		"end before start": Sub(userA, "Netflix", 100, month(2025, 6), month(2025, 1)),
+		"long name":        Sub(userA, string(long), 100, month(2025, 1), nil),
+	}
+
+	for name, s := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := r.Create(context.Background(), s)
+			if !errors.Is(err, myerrors.ErrInvalidData) {
+				t.Fatalf("got %v, want ErrInvalidData", err)
+			}
+		})
+	}
+
+	if n, _ := r.Count(context.Background()); n != 0 {
+		t.Fatalf("invalid rows were stored: count %d", n)
+	}
+}
+
+func testListPaging(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+
+	var created []*domain.Subscription
+	for i := 1; i <= 5; i++ {
+		s := Sub(userA, "Service", i*100, month(2025, time.Month(i)), nil)
+		mustCreate(t, r, s)
+		created = append(created, s)
+	}
+
+	all, total, err := r.List(ctx, domain.LabelFilter{}, domain.NewPagingBase(100, 0))
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 5 {
+		t.Fatalf("List total = %d, want 5", total)
+	}
+	if !sameIDs(ids(all), ids(created)) {
+		t.Fatalf("List returned %v, want %v", ids(all), ids(created))
+	}
+
+	// Страницы должны идти в стабильном порядке без пропусков и повторов.
+	var paged []uuid.UUID
+	for offset := 0; offset < 6; offset += 2 {
+		p, total, err := r.List(ctx, domain.LabelFilter{}, domain.NewPagingBase(2, offset))
+		if err != nil {
+			t.Fatalf("List offset %d: %v", offset, err)
+		}
+		if total != 5 {
+			t.Fatalf("List offset %d total = %d, want 5", offset, total)
+		}
+		paged = append(paged, ids(p)...)
+	}
+	if len(paged) != len(all) {
+		t.Fatalf("paged %d rows, want %d", len(paged), len(all))
+	}
+	for i := range paged {
+		if paged[i] != all[i].ID() {
+			t.Fatalf("page order differs at %d: %v vs %v", i, paged, ids(all))
+		}
+	}
+
+	// Общее число известно и для страницы за концом списка.
+	beyond, total, err := r.List(ctx, domain.LabelFilter{}, domain.NewPagingBase(10, 10))
+	if err != nil {
+		t.Fatalf("List beyond end: %v", err)
+	}
+	if len(beyond) != 0 || total != 5 {
+		t.Fatalf("List beyond end returned %d rows, total %d", len(beyond), total)
+	}
+
+	zero, total, err := r.List(ctx, domain.LabelFilter{}, domain.NewPagingBase(0, 0))
+	if err != nil {
+		t.Fatalf("List limit 0: %v", err)
+	}
+	if total != 5 {
+		t.Fatalf("List limit 0 total = %d, want 5", total)
+	}
+	if len(zero) != 0 {
+		t.Fatalf("List limit 0 returned %d rows", len(zero))
+	}
+}
+
+func testListPagingEdges(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		mustCreate(t, r, Sub(userA, "Service", 100, month(2025, time.Month(i)), nil))
+	}
+
+	cases := []struct {
+		name          string
+		limit, offset int
+		want          int
+	}{
+		{"last partial page", 2, 4, 1},
+		{"offset equals count", 2, 5, 0},
+		{"limit above count", 100, 0, 5},
+		{"negative offset", 2, -1, 2},
+		{"negative limit", -1, 1, 4},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, _, err := r.List(ctx, domain.LabelFilter{}, domain.NewPagingBase(tc.limit, tc.offset))
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(rows) != tc.want {
+				t.Fatalf("List(%d, %d) returned %d rows, want %d", tc.limit, tc.offset, len(rows), tc.want)
+			}
+		})
+	}
+}
+
+func testCount(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+
+	n, err := r.Count(ctx)
+	if err != nil || n != 0 {
+		t.Fatalf("empty Count = %d, %v", n, err)
+	}
+
+	mustCreate(t, r,
+		Sub(userA, "Netflix", 100, month(2025, 1), nil),
+		Sub(userB, "Netflix", 100, month(2025, 1), nil),
+	)
+
+	n, err = r.Count(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("Count = %d, %v, want 2", n, err)
+	}
+}
+
+func testSumFilters(t *testing.T, r repository.SubRepository) {
+	netflixA := Sub(userA, "Netflix", 400, month(2025, 1), month(2025, 6))
+	spotifyA := Sub(userA, "Spotify", 200, month(2025, 3), nil)
+	netflixB := Sub(userB, "Netflix", 500, month(2024, 11), month(2025, 2))
+	yandexB := Sub(userB, "Yandex", 300, month(2025, 7), month(2025, 12))
+	mustCreate(t, r, netflixA, spotifyA, netflixB, yandexB)
+
+	str := func(s string) *string { return &s }
+	ua, ub, none := userA, userB, uuid.New()
+
+	cases := []struct {
+		name       string
+		user       *uuid.UUID
+		service    *string
+		start, end *string
+		want       []*domain.Subscription
+	}{
+		{"no filters", nil, nil, nil, nil, []*domain.Subscription{netflixA, spotifyA, netflixB, yandexB}},
+		{"user", &ua, nil, nil, nil, []*domain.Subscription{netflixA, spotifyA}},
+		{"service", nil, str("Netflix"), nil, nil, []*domain.Subscription{netflixA, netflixB}},
+		{"user and service", &ub, str("Netflix"), nil, nil, []*domain.Subscription{netflixB}},
+		{"start inclusive", nil, nil, str("03-2025"), nil, []*domain.Subscription{spotifyA, yandexB}},
+		{"end inclusive, open-ended excluded", nil, nil, nil, str("06-2025"), []*domain.Subscription{netflixA, netflixB}},
+		{"start and end", nil, nil, str("01-2025"), str("12-2025"), []*domain.Subscription{netflixA, yandexB}},
+		{"all filters", &ua, str("Netflix"), str("01-2025"), str("06-2025"), []*domain.Subscription{netflixA}},
+		{"service and start", nil, str("Netflix"), str("01-2025"), nil, []*domain.Subscription{netflixA}},
+		{"user and end", &ub, nil, nil, str("02-2025"), []*domain.Subscription{netflixB}},
+		{"single month", nil, nil, str("07-2025"), str("07-2025"), nil},
+		{"service is case sensitive", nil, str("netflix"), nil, nil, nil},
+		{"user without subscriptions", &none, nil, nil, nil, nil},
+		{"unknown service", nil, str("Missing"), nil, nil, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := domain.NewSubscriptionFilter(tc.user, tc.service, tc.start, tc.end, 100, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := r.Sum(context.Background(), filter)
+			if err != nil {
+				t.Fatalf("Sum: %v", err)
+			}
+
+			wantSum := 0
+			for _, s := range tc.want {
+				wantSum += s.Price()
+			}
+			if res.TotalSum != wantSum {
+				t.Errorf("sum = %d, want %d", res.TotalSum, wantSum)
+			}
+			if res.TotalCount != len(tc.want) {
+				t.Errorf("count = %d, want %d", res.TotalCount, len(tc.want))
+			}
+			if !sameIDs(ids(res.Rows), ids(tc.want)) {
+				t.Errorf("rows = %v, want %v", ids(res.Rows), ids(tc.want))
+			}
+		})
+	}
+}
+
+func testSumPaging(t *testing.T, r repository.SubRepository) {
+	for i := 1; i <= 5; i++ {
+		mustCreate(t, r, Sub(userA, "Netflix", 100, month(2025, time.Month(i)), nil))
+	}
+
+	// Сумма и количество считаются по всем подходящим строкам,
+	// пагинация влияет только на rows.
+	cases := []struct {
+		limit, offset int
+		wantRows      int
+	}{
+		{2, 0, 2},
+		{2, 4, 1},
+		{2, 10, 0},
+		{0, 0, 0},
+	}
+
+	for _, tc := range cases {
+		filter, err := domain.NewSubscriptionFilter(&userA, nil, nil, nil, tc.limit, tc.offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := r.Sum(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("Sum(%d, %d): %v", tc.limit, tc.offset, err)
+		}
+		if res.TotalSum != 500 || res.TotalCount != 5 {
+			t.Errorf("Sum(%d, %d) = sum %d, count %d, want 500, 5", tc.limit, tc.offset, res.TotalSum, res.TotalCount)
+		}
+		if len(res.Rows) != tc.wantRows {
+			t.Errorf("Sum(%d, %d) rows = %d, want %d", tc.limit, tc.offset, len(res.Rows), tc.wantRows)
+		}
+	}
+}
+
+func testUpdate(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	s := Sub(userA, "Netflix", 400, month(2025, 1), month(2025, 6))
+	mustCreate(t, r, s)
+
+	upd := domain.RestoreSubscription(s.ID(), "Netflix Premium", 900, userB, *month(2025, 2), nil)
+	if err := r.Update(ctx, upd); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	got, err := r.Get(ctx, s.ID())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	assertEqualSub(t, got, upd)
+
+	invalid := domain.RestoreSubscription(s.ID(), "Netflix", 0, userA, *month(2025, 1), nil)
+	if err := r.Update(ctx, invalid); !errors.Is(err, myerrors.ErrInvalidData) {
+		t.Fatalf("invalid Update: got %v, want ErrInvalidData", err)
+	}
+}
+
+func testUpdateMissing(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	s := Sub(userA, "Netflix", 400, month(2025, 1), nil)
+
+	if err := r.Update(ctx, s); err != nil {
+		t.Fatalf("Update of missing row: %v", err)
+	}
+	if _, err := r.Get(ctx, s.ID()); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("Update created a row: %v", err)
+	}
+}
+
+func testDelete(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	s := Sub(userA, "Netflix", 400, month(2025, 1), nil)
+	keep := Sub(userA, "Spotify", 200, month(2025, 1), nil)
+	mustCreate(t, r, s, keep)
+
+	if err := r.Delete(ctx, s.ID()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := r.Get(ctx, s.ID()); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("Get after Delete: %v", err)
+	}
+	if _, err := r.Get(ctx, keep.ID()); err != nil {
+		t.Fatalf("Delete removed another row: %v", err)
+	}
+
+	if err := r.Delete(ctx, uuid.New()); err != nil {
+		t.Fatalf("Delete of missing row: %v", err)
+	}
+}
+
+func testDeleteByUser(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	netflix := Sub(userA, "Netflix", 400, month(2025, 1), nil)
+	spotify := Sub(userA, "Spotify", 200, month(2025, 1), nil)
+	mustCreate(t, r, netflix, spotify, Sub(userB, "Netflix", 400, month(2025, 1), nil))
+
+	deleted, err := r.DeleteByUser(ctx, userA)
+	if err != nil {
+		t.Fatalf("DeleteByUser: %v", err)
+	}
+	if want := []uuid.UUID{netflix.ID(), spotify.ID()}; !sameIDs(ids(deleted), want) {
+		t.Fatalf("DeleteByUser returned %v, want %v", ids(deleted), want)
+	}
+
+	if count, _ := r.Count(ctx); count != 1 {
+		t.Fatalf("Count after DeleteByUser = %d, want 1", count)
+	}
+
+	deleted, err = r.DeleteByUser(ctx, userA)
+	if err != nil || len(deleted) != 0 {
+		t.Fatalf("second DeleteByUser = %d rows, %v, want 0", len(deleted), err)
+	}
+}
+
+func testListEnded(t *testing.T, r repository.SubRepository) {
+	ctx := context.Background()
+	endedMarch := Sub(userA, "Netflix", 400, month(2025, 1), month(2025, 3))
+	endedMarchToo := Sub(userB, "Spotify", 200, month(2025, 3), month(2025, 3))
+	mustCreate(t, r,
+		endedMarch,
+		Sub(userA, "Spotify", 200, month(2025, 1), month(2025, 2)),
+		Sub(userA, "Yandex", 300, month(2025, 1), month(2025, 4)),
+		Sub(userB, "Netflix", 400, month(2025, 1), nil),
+		endedMarchToo,
+	)
+
+	ended, err := r.ListEnded(ctx, *month(2025, 3))
+	if err != nil {
+		t.Fatalf("ListEnded: %v", err)
+	}
+	if want := []uuid.UUID{endedMarch.ID(), endedMarchToo.ID()}; !sameIDs(ids(ended), want) {
+		t.Fatalf("ListEnded returned %v, want %v", ids(ended), want)
+	}
+
+	ended, err = r.ListEnded(ctx, *month(2025, 5))
+	if err != nil || len(ended) != 0 {
+		t.Fatalf("ListEnded(05-2025) = %d rows, %v, want 0", len(ended), err)
+	}
+}
+
+func testCanceledContext(t *testing.T, r repository.SubRepository) {
+	s := Sub(userA, "Netflix", 400, month(2025, 1), nil)
+	mustCreate(t, r, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	filter, _ := domain.NewSubscriptionFilter(nil, nil, nil, nil, 10, 0)
+
+	checks := map[string]error{
+		"Create": r.Create(ctx, Sub(userA, "Netflix", 400, month(2025, 1), nil)),
+		"Update": r.Update(ctx, s),
+		"Delete": r.Delete(ctx, s.ID()),
+	}
+	_, checks["Get"] = r.Get(ctx, s.ID())
+	_, _, checks["List"] = r.List(ctx, domain.LabelFilter{}, domain.NewPagingBase(10, 0))
+	_, checks["Sum"] = r.Sum(ctx, filter)
+	_, checks["Count"] = r.Count(ctx)
+	_, checks["DeleteByUser"] = r.DeleteByUser(ctx, userA)
+	_, checks["ListEnded"] = r.ListEnded(ctx, *month(2025, 1))
+	_, checks["ListByService"] = r.ListByService(ctx, uuid.New())
+
+	for name, err := range checks {
+		if !errors.Is(err, myerrors.ErrDatabase) {
+			t.Errorf("%s: got %v, want ErrDatabase", name, err)
+		}
+	}
+
+	if _, err := r.Get(context.Background(), s.ID()); err != nil {
+		t.Fatalf("row changed by canceled calls: %v", err)
+	}
+}
+
+func testExpiredDeadline(t *testing.T, r repository.SubRepository) {
+	s := Sub(userA, "Netflix", 400, month(2025, 1), nil)
+	mustCreate(t, r, s)
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	if _, err := r.Get(ctx, s.ID()); !errors.Is(err, myerrors.ErrDatabase) {
+		t.Errorf("Get: got %v, want ErrDatabase", err)
+	}
+	if err := r.Create(ctx, Sub(userA, "Netflix", 400, month(2025, 1), nil)); !errors.Is(err, myerrors.ErrDatabase) {
+		t.Errorf("Create: got %v, want ErrDatabase", err)
+	}
+}
@@ -0,0 +1,169 @@
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository"
+)
+
+// TxFactory возвращает пустой репозиторий и TxManager над той же базой.
+type TxFactory func(t *testing.T) (repository.SubRepository, repository.TxManager)
+
+// RunTxManager прогоняет сценарии транзакций для реализаций, которые их
+// поддерживают.
+func RunTxManager(t *testing.T, newRepo TxFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r repository.SubRepository, tx repository.TxManager)
+	}{
+		{"Commit", testTxCommit},
+		{"Rollback", testTxRollback},
+		{"RollbackOnPanic", testTxRollbackOnPanic},
+		{"Nested", testTxNested},
+		{"UpdateRollback", testTxUpdateRollback},
+		{"ReadOnlyReport", testTxReadOnlyReport},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, tx := newRepo(t)
+			tt.fn(t, r, tx)
+		})
+	}
+}
+
+var errRollback = errors.New("rollback")
+
+func testTxCommit(t *testing.T, r repository.SubRepository, tx repository.TxManager) {
+	s := Sub(userA, "Netflix", 400, month(2025, 1), nil)
+
+	err := tx.Do(context.Background(), repository.TxOptions{}, func(ctx context.Context) error {
+		if err := r.Create(ctx, s); err != nil {
+			return err
+		}
+		// Внутри транзакции видны её собственные изменения.
+		_, err := r.GetForUpdate(ctx, s.ID())
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	if _, err := r.Get(context.Background(), s.ID()); err != nil {
+		t.Fatalf("Get after commit: %v", err)
+	}
+}
+
+func testTxRollback(t *testing.T, r repository.SubRepository, tx repository.TxManager) {
+	s := Sub(userA, "Netflix", 400, month(2025, 1), nil)
+
+	err := tx.Do(context.Background(), repository.TxOptions{}, func(ctx context.Context) error {
+		if err := r.Create(ctx, s); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Do: got %v, want the error returned by fn", err)
+	}
+
+	if _, err := r.Get(context.Background(), s.ID()); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("Get after rollback: got %v, want ErrNotFound", err)
+	}
+}
+
+func testTxRollbackOnPanic(t *testing.T, r repository.SubRepository, tx repository.TxManager) {
+	s := Sub(userA, "Netflix", 400, month(2025, 1), nil)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic was not propagated")
+			}
+		}()
+		_ = tx.Do(context.Background(), repository.TxOptions{}, func(ctx context.Context) error {
+			if err := r.Create(ctx, s); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	}()
+
+	if _, err := r.Get(context.Background(), s.ID()); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("Get after panic: got %v, want ErrNotFound", err)
+	}
+}
+
+// testTxNested проверяет, что вложенный Do присоединяется к внешней
+// транзакции и откатывается вместе с ней.
+func testTxNested(t *testing.T, r repository.SubRepository, tx repository.TxManager) {
+	s := Sub(userA, "Netflix", 400, month(2025, 1), nil)
+
+	err := tx.Do(context.Background(), repository.TxOptions{}, func(ctx context.Context) error {
+		err := tx.Do(ctx, repository.TxOptions{Isolation: sql.LevelSerializable}, func(ctx context.Context) error {
+			return r.Create(ctx, s)
+		})
+		if err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Do: got %v, want the error returned by fn", err)
+	}
+
+	if _, err := r.Get(context.Background(), s.ID()); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("Get after rollback: got %v, want ErrNotFound", err)
+	}
+}
+
+func testTxUpdateRollback(t *testing.T, r repository.SubRepository, tx repository.TxManager) {
+	s := Sub(userA, "Netflix", 400, month(2025, 1), nil)
+	mustCreate(t, r, s)
+
+	err := tx.Do(context.Background(), repository.TxOptions{}, func(ctx context.Context) error {
+		if _, err := r.GetForUpdate(ctx, s.ID()); err != nil {
+			return err
+		}
+		changed := domain.RestoreSubscription(s.ID(), "Netflix", 999, userA, *month(2025, 1), nil)
+		if err := r.Update(ctx, changed); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Do: got %v, want the error returned by fn", err)
+	}
+
+	got, err := r.Get(context.Background(), s.ID())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	assertEqualSub(t, got, s)
+}
+
+func testTxReadOnlyReport(t *testing.T, r repository.SubRepository, tx repository.TxManager) {
+	mustCreate(t, r,
+		Sub(userA, "Netflix", 400, month(2025, 1), nil),
+		Sub(userB, "Spotify", 200, month(2025, 2), nil),
+	)
+
+	opts := repository.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := tx.Do(context.Background(), opts, func(ctx context.Context) error {
+		_, total, err := r.List(ctx, domain.LabelFilter{}, domain.NewPagingBase(10, 0))
+		if err != nil {
+			return err
+		}
+		if total != 2 {
+			t.Errorf("List total: got %d, want 2", total)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/user"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// UserFactory возвращает пустое хранилище пользователей для одного подтеста.
+type UserFactory func(t *testing.T) repository.UserRepository
+
+// RunUserRepository прогоняет сценарии UserRepository.
+func RunUserRepository(t *testing.T, newRepo UserFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r repository.UserRepository)
+	}{
+		{"CRUD", testUserCRUD},
+		{"UniqueEmail", testUserUniqueEmail},
+		{"List", testUserList},
+		{"Missing", testUserMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func mustUser(t *testing.T, r repository.UserRepository, name string, email *string) *user.User {
+	t.Helper()
+	u, err := user.NewUser(uuid.Nil, name, email, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u = user.RestoreUser(u.ID(), u.DisplayName(), u.Email(), u.Currency(), u.Timezone(), outboxEpoch)
+	if err := r.Create(context.Background(), u); err != nil {
+		t.Fatalf("Create(%s): %v", name, err)
+	}
+	return u
+}
+
+func testUserCRUD(t *testing.T, r repository.UserRepository) {
+	ctx := context.Background()
+	u := mustUser(t, r, "Алиса", ptr("alice@example.com"))
+
+	got, err := r.Get(ctx, u.ID())
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.DisplayName() != "Алиса" || got.Email() == nil || *got.Email() != "alice@example.com" ||
+		got.Currency() != user.DefaultCurrency || got.Timezone() != user.DefaultTimezone || !got.CreatedAt().Equal(outboxEpoch) {
+		t.Fatalf("Get: got %+v, want %+v", got, u)
+	}
+
+	upd, err := user.NewUser(u.ID(), "Алиса К.", nil, "USD", "Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Update(ctx, upd); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err = r.Get(ctx, u.ID())
+	if err != nil {
+		t.Fatalf("Get after update: %v", err)
+	}
+	if got.DisplayName() != "Алиса К." || got.Email() != nil || got.Currency() != "USD" ||
+		got.Timezone() != "Europe/Moscow" || !got.CreatedAt().Equal(outboxEpoch) {
+		t.Fatalf("Get after update: got %+v", got)
+	}
+
+	if err := r.Delete(ctx, u.ID()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := r.Get(ctx, u.ID()); !errors.Is(err, myerrors.ErrUserNotFound) {
+		t.Fatalf("Get after delete: got %v, want ErrUserNotFound", err)
+	}
+	if err := r.Update(ctx, upd); !errors.Is(err, myerrors.ErrUserNotFound) {
+		t.Fatalf("Update(missing): got %v, want ErrUserNotFound", err)
+	}
+	if err := r.Delete(ctx, u.ID()); !errors.Is(err, myerrors.ErrUserNotFound) {
+		t.Fatalf("Delete(missing): got %v, want ErrUserNotFound", err)
+	}
+}
+
+func testUserUniqueEmail(t *testing.T, r repository.UserRepository) {
+	ctx := context.Background()
+	alice := mustUser(t, r, "Алиса", ptr("alice@example.com"))
+	bob := mustUser(t, r, "Боб", nil)
+	// Пользователей без email может быть сколько угодно.
+	mustUser(t, r, "Ева", nil)
+
+	got, err := r.GetByEmail(ctx, "alice@example.com")
+	if err != nil || got.ID() != alice.ID() {
+		t.Fatalf("GetByEmail = %+v, %v, want %s", got, err, alice.ID())
+	}
+	if _, err := r.GetByEmail(ctx, "bob@example.com"); !errors.Is(err, myerrors.ErrUserNotFound) {
+		t.Fatalf("GetByEmail(missing): got %v, want ErrUserNotFound", err)
+	}
+
+	dup, err := user.NewUser(uuid.Nil, "Алиса 2", ptr("alice@example.com"), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Create(ctx, dup); !errors.Is(err, myerrors.ErrInvalidData) {
+		t.Fatalf("Create(taken email): got %v, want ErrInvalidData", err)
+	}
+
+	upd, err := user.NewUser(bob.ID(), "Боб", ptr("alice@example.com"), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Update(ctx, upd); !errors.Is(err, myerrors.ErrInvalidData) {
+		t.Fatalf("Update(taken email): got %v, want ErrInvalidData", err)
+	}
+}
+
+func testUserList(t *testing.T, r repository.UserRepository) {
+	ctx := context.Background()
+	for _, name := range []string{"Виктор", "Алиса", "Боб"} {
+		mustUser(t, r, name, nil)
+	}
+
+	got, total, err := r.List(ctx, &domain.PagingBase{Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 3 || len(got) != 2 || got[0].DisplayName() != "Боб" || got[1].DisplayName() != "Виктор" {
+		t.Fatalf("List: total %d, got %d users", total, len(got))
+	}
+}
+
+func testUserMissing(t *testing.T, r repository.UserRepository) {
+	ctx := context.Background()
+	u := mustUser(t, r, "Алиса", nil)
+	stranger := uuid.New()
+
+	got, err := r.Missing(ctx, []uuid.UUID{u.ID(), stranger, stranger})
+	if err != nil {
+		t.Fatalf("Missing: %v", err)
+	}
+	if want := []uuid.UUID{stranger}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Missing = %v, want %v", got, want)
+	}
+	if got, err := r.Missing(ctx, nil); err != nil || len(got) != 0 {
+		t.Fatalf("Missing(nil) = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,304 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/webhook"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookFactory возвращает пустое хранилище webhook'ов для одного подтеста.
+type WebhookFactory func(t *testing.T) repository.WebhookRepository
+
+// RunWebhookRepository прогоняет сценарии WebhookRepository.
+func RunWebhookRepository(t *testing.T, newRepo WebhookFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r repository.WebhookRepository)
+	}{
+		{"EndpointCRUD", testWebhookEndpointCRUD},
+		{"EndpointsFor", testWebhookEndpointsFor},
+		{"DeliveryDuplicate", testWebhookDeliveryDuplicate},
+		{"ClaimAndRecord", testWebhookClaimAndRecord},
+		{"Redeliver", testWebhookRedeliver},
+		{"DeleteCascade", testWebhookDeleteCascade},
+		{"DeleteByUser", testWebhookDeleteByUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+const testSecret = "0123456789abcdef"
+
+// mustEndpoint заводит endpoint пользователя owner, одного из Users.
+func mustEndpoint(t *testing.T, r repository.WebhookRepository, owner uuid.UUID, types ...string) *webhook.Endpoint {
+	t.Helper()
+
+	e, err := webhook.NewEndpoint(uuid.New(), owner, "https://example.com/hook", testSecret, types)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.CreateEndpoint(context.Background(), e); err != nil {
+		t.Fatalf("CreateEndpoint: %v", err)
+	}
+	return e
+}
+
+// Delivery собирает доставку события на endpoint, созданную через
+// offset после outboxEpoch.
+func Delivery(endpoint uuid.UUID, offset time.Duration) webhook.Delivery {
+	at := outboxEpoch.Add(offset)
+	return webhook.Delivery{
+		ID:            uuid.New(),
+		EndpointID:    endpoint,
+		EventID:       uuid.New(),
+		EventType:     "SubscriptionCreated",
+		Payload:       []byte(`{"id":"1"}`),
+		Status:        webhook.StatusPending,
+		NextAttemptAt: at,
+		CreatedAt:     at,
+	}
+}
+
+func mustDeliveries(t *testing.T, r repository.WebhookRepository, endpoint uuid.UUID) []webhook.Delivery {
+	t.Helper()
+
+	res, _, err := r.ListDeliveries(context.Background(), endpoint, domain.NewPagingBase(100, 0))
+	if err != nil {
+		t.Fatalf("ListDeliveries: %v", err)
+	}
+	return res
+}
+
+func testWebhookEndpointCRUD(t *testing.T, r repository.WebhookRepository) {
+	ctx := context.Background()
+	first := mustEndpoint(t, r, userA, "SubscriptionCreated")
+	second := mustEndpoint(t, r, userA, "SubscriptionDeleted")
+
+	got, err := r.GetEndpoint(ctx, first.ID())
+	if err != nil {
+		t.Fatalf("GetEndpoint: %v", err)
+	}
+	if got.URL() != first.URL() || got.Secret() != testSecret || !got.Accepts("SubscriptionCreated") {
+		t.Fatalf("GetEndpoint = %+v, want %+v", got, first)
+	}
+
+	rows, total, err := r.ListEndpoints(ctx, domain.NewPagingBase(1, 1))
+	if err != nil || total != 2 || len(rows) != 1 {
+		t.Fatalf("ListEndpoints = %d rows of %d, %v, want 1 of 2", len(rows), total, err)
+	}
+
+	updated := webhook.RestoreEndpoint(second.ID(), userB, "http://example.org/other", "fedcba9876543210", []string{"SubscriptionUpdated"}, second.CreatedAt())
+	if err := r.UpdateEndpoint(ctx, updated); err != nil {
+		t.Fatalf("UpdateEndpoint: %v", err)
+	}
+	got, err = r.GetEndpoint(ctx, second.ID())
+	if err != nil || got.URL() != "http://example.org/other" || got.UserID() != userB ||
+		!got.Accepts("SubscriptionUpdated") || got.Accepts("SubscriptionDeleted") {
+		t.Fatalf("GetEndpoint after update = %+v, %v", got, err)
+	}
+
+	missing := webhook.RestoreEndpoint(uuid.New(), userA, "http://example.org", testSecret, []string{"SubscriptionCreated"}, time.Now())
+	if err := r.UpdateEndpoint(ctx, missing); !errors.Is(err, myerrors.ErrWebhookNotFound) {
+		t.Fatalf("UpdateEndpoint(missing): got %v, want ErrWebhookNotFound", err)
+	}
+
+	if err := r.DeleteEndpoint(ctx, first.ID()); err != nil {
+		t.Fatalf("DeleteEndpoint: %v", err)
+	}
+	if _, err := r.GetEndpoint(ctx, first.ID()); !errors.Is(err, myerrors.ErrWebhookNotFound) {
+		t.Fatalf("GetEndpoint after delete: got %v, want ErrWebhookNotFound", err)
+	}
+}
+
+func testWebhookEndpointsFor(t *testing.T, r repository.WebhookRepository) {
+	created := mustEndpoint(t, r, userA, "SubscriptionCreated", "SubscriptionDeleted")
+	mustEndpoint(t, r, userA, "SubscriptionUpdated")
+	deleted := mustEndpoint(t, r, userA, "SubscriptionDeleted")
+	// Endpoint другого пользователя не получает чужие события.
+	mustEndpoint(t, r, userB, "SubscriptionDeleted")
+
+	got, err := r.EndpointsFor(context.Background(), "SubscriptionDeleted", userA)
+	if err != nil {
+		t.Fatalf("EndpointsFor: %v", err)
+	}
+	ids := map[uuid.UUID]bool{}
+	for _, e := range got {
+		ids[e.ID()] = true
+	}
+	if len(got) != 2 || !ids[created.ID()] || !ids[deleted.ID()] {
+		t.Fatalf("EndpointsFor = %d endpoints, want %s and %s", len(got), created.ID(), deleted.ID())
+	}
+}
+
+func testWebhookDeliveryDuplicate(t *testing.T, r repository.WebhookRepository) {
+	ctx := context.Background()
+	e := mustEndpoint(t, r, userA, "SubscriptionCreated")
+	d := Delivery(e.ID(), 0)
+
+	for i := 0; i < 2; i++ {
+		if err := r.AddDeliveries(ctx, d); err != nil {
+			t.Fatalf("AddDeliveries: %v", err)
+		}
+	}
+	if got := mustDeliveries(t, r, e.ID()); len(got) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(got))
+	}
+
+	if err := r.AddDeliveries(ctx, Delivery(uuid.New(), 0)); !errors.Is(err, myerrors.ErrInvalidData) {
+		t.Fatalf("AddDeliveries(unknown endpoint): got %v, want ErrInvalidData", err)
+	}
+}
+
+func testWebhookClaimAndRecord(t *testing.T, r repository.WebhookRepository) {
+	ctx := context.Background()
+	e := mustEndpoint(t, r, userA, "SubscriptionCreated")
+	older := Delivery(e.ID(), 0)
+	newer := Delivery(e.ID(), time.Second)
+	future := Delivery(e.ID(), time.Hour)
+	if err := r.AddDeliveries(ctx, newer, older, future); err != nil {
+		t.Fatalf("AddDeliveries: %v", err)
+	}
+
+	now := outboxEpoch.Add(time.Minute)
+	claimed, err := r.ClaimDue(ctx, now, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("ClaimDue: %v", err)
+	}
+	if len(claimed) != 2 || claimed[0].ID != older.ID || claimed[1].ID != newer.ID {
+		t.Fatalf("ClaimDue = %+v, want older then newer", claimed)
+	}
+	if string(claimed[0].Payload) != string(older.Payload) || claimed[0].EndpointID != e.ID() {
+		t.Fatalf("claimed delivery = %+v, want %+v", claimed[0], older)
+	}
+
+	// Взятые доставки до конца аренды не выдаются повторно.
+	if again, err := r.ClaimDue(ctx, now, time.Minute, 10); err != nil || len(again) != 0 {
+		t.Fatalf("ClaimDue during lease = %d deliveries, %v, want 0", len(again), err)
+	}
+
+	code := 503
+	msg := "unexpected status 503"
+	failed := claimed[0]
+	failed.Attempts = 1
+	failed.NextAttemptAt = now.Add(10 * time.Minute)
+	if err := r.RecordAttempt(ctx, failed, webhook.Attempt{AttemptedAt: now, StatusCode: &code, Error: &msg, Duration: 120 * time.Millisecond}); err != nil {
+		t.Fatalf("RecordAttempt(failed): %v", err)
+	}
+
+	ok := 200
+	delivered := claimed[1]
+	delivered.Attempts = 1
+	delivered.Status = webhook.StatusSucceeded
+	delivered.DeliveredAt = &now
+	if err := r.RecordAttempt(ctx, delivered, webhook.Attempt{AttemptedAt: now, StatusCode: &ok, Duration: 80 * time.Millisecond}); err != nil {
+		t.Fatalf("RecordAttempt(succeeded): %v", err)
+	}
+
+	// Новые первыми: future, newer, older.
+	got := mustDeliveries(t, r, e.ID())
+	if len(got) != 3 || got[0].ID != future.ID || got[1].ID != newer.ID || got[2].ID != older.ID {
+		t.Fatalf("ListDeliveries order = %+v", got)
+	}
+	if d := got[1]; d.Status != webhook.StatusSucceeded || d.DeliveredAt == nil || len(d.Log) != 1 || *d.Log[0].StatusCode != 200 {
+		t.Fatalf("succeeded delivery = %+v", d)
+	}
+	if d := got[2]; d.Status != webhook.StatusPending || d.Attempts != 1 || len(d.Log) != 1 ||
+		*d.Log[0].Error != msg || d.Log[0].Duration != 120*time.Millisecond {
+		t.Fatalf("failed delivery = %+v", d)
+	}
+
+	retry, err := r.ClaimDue(ctx, now.Add(10*time.Minute), time.Minute, 10)
+	if err != nil || len(retry) != 1 || retry[0].ID != older.ID || retry[0].Attempts != 1 {
+		t.Fatalf("ClaimDue after backoff = %+v, %v, want the failed delivery", retry, err)
+	}
+}
+
+func testWebhookRedeliver(t *testing.T, r repository.WebhookRepository) {
+	ctx := context.Background()
+	e := mustEndpoint(t, r, userA, "SubscriptionCreated")
+	other := mustEndpoint(t, r, userA, "SubscriptionCreated")
+	d := Delivery(e.ID(), 0)
+	if err := r.AddDeliveries(ctx, d); err != nil {
+		t.Fatalf("AddDeliveries: %v", err)
+	}
+
+	now := outboxEpoch.Add(time.Hour)
+	d.Status = webhook.StatusFailed
+	d.Attempts = 5
+	if err := r.RecordAttempt(ctx, d, webhook.Attempt{AttemptedAt: now}); err != nil {
+		t.Fatalf("RecordAttempt: %v", err)
+	}
+	if claimed, err := r.ClaimDue(ctx, now, time.Minute, 10); err != nil || len(claimed) != 0 {
+		t.Fatalf("ClaimDue(failed) = %d deliveries, %v, want 0", len(claimed), err)
+	}
+
+	if _, err := r.Redeliver(ctx, other.ID(), d.ID, now); !errors.Is(err, myerrors.ErrDeliveryNotFound) {
+		t.Fatalf("Redeliver(other endpoint): got %v, want ErrDeliveryNotFound", err)
+	}
+
+	got, err := r.Redeliver(ctx, e.ID(), d.ID, now)
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if got.Status != webhook.StatusPending || got.Attempts != 5 || !got.NextAttemptAt.Equal(now) {
+		t.Fatalf("Redeliver = %+v", got)
+	}
+
+	claimed, err := r.ClaimDue(ctx, now, time.Minute, 10)
+	if err != nil || len(claimed) != 1 || claimed[0].ID != d.ID {
+		t.Fatalf("ClaimDue after redeliver = %+v, %v", claimed, err)
+	}
+}
+
+func testWebhookDeleteCascade(t *testing.T, r repository.WebhookRepository) {
+	ctx := context.Background()
+	e := mustEndpoint(t, r, userA, "SubscriptionCreated")
+	kept := mustEndpoint(t, r, userA, "SubscriptionCreated")
+	if err := r.AddDeliveries(ctx, Delivery(e.ID(), 0), Delivery(kept.ID(), 0)); err != nil {
+		t.Fatalf("AddDeliveries: %v", err)
+	}
+
+	if err := r.DeleteEndpoint(ctx, e.ID()); err != nil {
+		t.Fatalf("DeleteEndpoint: %v", err)
+	}
+
+	claimed, err := r.ClaimDue(ctx, outboxEpoch.Add(time.Minute), time.Minute, 10)
+	if err != nil || len(claimed) != 1 || claimed[0].EndpointID != kept.ID() {
+		t.Fatalf("ClaimDue after delete = %+v, %v, want only the kept endpoint", claimed, err)
+	}
+}
+
+func testWebhookDeleteByUser(t *testing.T, r repository.WebhookRepository) {
+	ctx := context.Background()
+	first := mustEndpoint(t, r, userA, "SubscriptionCreated")
+	second := mustEndpoint(t, r, userA, "SubscriptionDeleted")
+	kept := mustEndpoint(t, r, userB, "SubscriptionCreated")
+	if err := r.AddDeliveries(ctx, Delivery(first.ID(), 0), Delivery(kept.ID(), 0)); err != nil {
+		t.Fatalf("AddDeliveries: %v", err)
+	}
+
+	n, err := r.DeleteByUser(ctx, userA)
+	if err != nil || n != 2 {
+		t.Fatalf("DeleteByUser = %d, %v, want 2", n, err)
+	}
+	for _, id := range []uuid.UUID{first.ID(), second.ID()} {
+		if _, err := r.GetEndpoint(ctx, id); !errors.Is(err, myerrors.ErrWebhookNotFound) {
+			t.Fatalf("GetEndpoint after DeleteByUser: got %v, want ErrWebhookNotFound", err)
+		}
+	}
+	claimed, err := r.ClaimDue(ctx, outboxEpoch.Add(time.Minute), time.Minute, 10)
+	if err != nil || len(claimed) != 1 || claimed[0].EndpointID != kept.ID() {
+		t.Fatalf("ClaimDue after DeleteByUser = %+v, %v, want only the other user's endpoint", claimed, err)
+	}
+}
@@ -0,0 +1,58 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"testingtask/internal/config"
+	"testingtask/internal/database"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+
+	"gorm.io/gorm"
+)
+
+func openSQLite(t *testing.T, url string) *gorm.DB {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Database.URL = url
+
+	db, err := database.InitDB(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = database.Close(db) })
+
+	m, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	return db
+}
+
+// TestSQLiteSubRepository прогоняет общий набор на SQLite. Каждый подтест
+// получает свой файл базы, чтобы проверять и WAL, и миграции с нуля.
+func TestSQLiteSubRepository(t *testing.T) {
+	repotest.RunSubRepository(t, func(t *testing.T) repository.SubRepository {
+		url := database.SQLiteScheme + filepath.Join(t.TempDir(), "subs.db")
+		return repository.NewSubRepository(openSQLite(t, url))
+	})
+}
+
+func TestSQLiteSubRepositoryInMemory(t *testing.T) {
+	repotest.RunSubRepository(t, func(t *testing.T) repository.SubRepository {
+		return repository.NewSubRepository(openSQLite(t, database.SQLiteScheme+":memory:"))
+	})
+}
+
+func TestSQLiteTxManager(t *testing.T) {
+	repotest.RunTxManager(t, func(t *testing.T) (repository.SubRepository, repository.TxManager) {
+		db := openSQLite(t, database.SQLiteScheme+filepath.Join(t.TempDir(), "subs.db"))
+		return repository.NewSubRepository(db), repository.NewTxManager(db)
+	})
+}
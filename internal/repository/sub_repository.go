@@ -2,26 +2,48 @@ package repository
 
 import (
 	"context"
-	"database/sql"
-	"errors"
+	"encoding/json"
 	domain "testingtask/internal/domain/subscription"
 	myerrors "testingtask/internal/errors"
 	"testingtask/internal/repository/models"
 	logger "testingtask/pkg"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SubRepository interface {
 	Create(ctx context.Context, sub *domain.Subscription) error
 	Get(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
-	List(ctx context.Context, paging *domain.PagingBase) ([]*domain.Subscription, error)
-	Sum(ctx context.Context, filter *domain.SubscriptionFilter) ([]*domain.Subscription, int, error)
+	// GetForUpdate читает подписку и блокирует строку до конца транзакции
+	// из ctx (SELECT ... FOR UPDATE). Вне транзакции работает как Get.
+	GetForUpdate(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
+	// List возвращает страницу и общее число подписок, подходящих под
+	// фильтр по тегам и metadata.
+	List(ctx context.Context, labels domain.LabelFilter, paging *domain.PagingBase) ([]*domain.Subscription, int64, error)
+	// Sum возвращает страницу подходящих под фильтр подписок, их сумму
+	// и количество (без учёта пагинации). С UserID в выборку входят и
+	// общие подписки, где пользователь участник: в сумму идёт его доля,
+	// она же — Share строки.
+	Sum(ctx context.Context, filter *domain.SubscriptionFilter) (*domain.SumResult, error)
 	Count(ctx context.Context) (int64, error)
 	Update(ctx context.Context, sub *domain.Subscription) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// DeleteByUser удаляет подписки пользователя и возвращает удалённые.
+	DeleteByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Subscription, error)
+	// ListEnded возвращает подписки, последний месяц которых — month.
+	ListEnded(ctx context.Context, month domain.SubDate) ([]*domain.Subscription, error)
+	// ListByService возвращает подписки, привязанные к сервису каталога.
+	ListByService(ctx context.Context, serviceID uuid.UUID) ([]*domain.Subscription, error)
+	// Split возвращает разделение цены подписки id, nil — подписка не
+	// общая.
+	Split(ctx context.Context, id uuid.UUID) (*domain.Split, error)
+	// SaveSplit заменяет участников подписки id; nil делает её не общей.
+	SaveSplit(ctx context.Context, id uuid.UUID, split *domain.Split) error
+	// ListSharedWith возвращает общие подписки, в которых пользователь
+	// участник, но не владелец.
+	ListSharedWith(ctx context.Context, userID uuid.UUID) ([]*domain.Subscription, error)
 }
 
 type subRepository struct {
@@ -35,30 +57,12 @@ func NewSubRepository(db *gorm.DB) SubRepository {
 func (s *subRepository) Create(ctx context.Context, sub *domain.Subscription) error {
 	m := models.FromDomain(sub)
 
-	err := s.DB.WithContext(ctx).Create(&m).Error
+	err := conn(ctx, s.DB).Create(&m).Error
 	if err != nil {
 		logger.Error(ctx, "repo: subscription create failed", err, map[string]interface{}{
 			"data": sub,
 		})
-		if errors.Is(err, gorm.ErrInvalidData) {
-			return myerrors.ErrInvalidData
-		}
-
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			switch pgErr.Code {
-			case "23505":
-				return myerrors.ErrInvalidData
-			case "23503":
-				return myerrors.ErrInvalidData
-			}
-		}
-
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-			return myerrors.ErrDatabase
-		}
-
-		return myerrors.ErrCreateFailed
+		return mapError(err, myerrors.ErrCreateFailed)
 	}
 
 	return nil
@@ -67,121 +71,97 @@ func (s *subRepository) Create(ctx context.Context, sub *domain.Subscription) er
 func (s *subRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
 	var m models.Subscription
 
-	err := s.DB.WithContext(ctx).First(&m, "id = ?", id).Error
+	err := conn(ctx, s.DB).First(&m, "id = ?", id).Error
 	if err != nil {
 		logger.Error(ctx, "repo: subscription get failed", err, map[string]interface{}{
 			"id": id,
 		})
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, myerrors.ErrNotFound
-		}
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
 
-		if errors.Is(err, gorm.ErrInvalidData) {
-			return nil, myerrors.ErrInvalidData
-		}
+	return models.ToDomain(&m)
+}
 
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			return nil, myerrors.ErrDatabase
-		}
+func (s *subRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	var m models.Subscription
 
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-			return nil, myerrors.ErrDatabase
-		}
+	q := conn(ctx, s.DB)
+	// В SQLite нет FOR UPDATE: запись и так блокирует всю базу.
+	if s.DB.Dialector.Name() != "sqlite" {
+		q = q.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
 
-		return nil, myerrors.ErrDatabase
+	err := q.First(&m, "id = ?", id).Error
+	if err != nil {
+		logger.Error(ctx, "repo: subscription get for update failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
 	}
 
 	return models.ToDomain(&m)
 }
 
-func (s *subRepository) List(ctx context.Context, paging *domain.PagingBase) ([]*domain.Subscription, error) {
-	var m []*models.Subscription
+func (s *subRepository) List(ctx context.Context, labels domain.LabelFilter, paging *domain.PagingBase) ([]*domain.Subscription, int64, error) {
+	var (
+		m     []*models.Subscription
+		total int64
+	)
+
+	scope := func() *gorm.DB {
+		return s.withLabels(conn(ctx, s.DB).Model(&models.Subscription{}), labels)
+	}
 
-	err := s.DB.WithContext(ctx).
+	err := scope().
+		Order("created_at, id").
 		Limit(paging.Limit).
 		Offset(paging.Offset).
 		Find(&m).Error
-
+	if err == nil {
+		err = scope().Count(&total).Error
+	}
 	if err != nil {
 		logger.Error(ctx, "repo: subscription list failed", err, map[string]interface{}{
+			"labels": labels,
 			"paging": paging,
 		})
-		if errors.Is(err, gorm.ErrInvalidData) {
-			return nil, myerrors.ErrInvalidData
-		}
-
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			return nil, myerrors.ErrDatabase
-		}
-
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-			return nil, myerrors.ErrDatabase
-		}
+		return nil, 0, mapError(err, myerrors.ErrListFailed)
+	}
 
-		return nil, myerrors.ErrListFailed
+	subs, err := models.ToDomains(m)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return models.ToDomains(m)
+	return subs, total, nil
 }
 
-func (r *subRepository) Sum(ctx context.Context, filter *domain.SubscriptionFilter) ([]*domain.Subscription, int, error) {
-	var totalSum sql.NullInt64
-
-	sumQuery := r.DB.WithContext(ctx).Model(&models.Subscription{})
+func (r *subRepository) Sum(ctx context.Context, filter *domain.SubscriptionFilter) (*domain.SumResult, error) {
+	var agg struct {
+		TotalSum   int64
+		TotalCount int64
+	}
 
+	sumQuery := r.filtered(ctx, filter)
 	if filter.UserID != nil {
-		sumQuery = sumQuery.Where("user_id = ?", *filter.UserID)
-	}
-	if filter.ServiceName != nil {
-		sumQuery = sumQuery.Where("service_name = ?", *filter.ServiceName)
-	}
-	if filter.StartDate != nil {
-		sumQuery = sumQuery.Where("start_date >= ?", filter.StartDate.Time)
-	}
-	if filter.EndDate != nil {
-		sumQuery = sumQuery.Where("end_date <= ?", filter.EndDate.Time)
+		sumQuery = sumQuery.Select("COALESCE(SUM(COALESCE("+memberShare+", price)), 0) AS total_sum, COUNT(*) AS total_count", *filter.UserID)
+	} else {
+		sumQuery = sumQuery.Select("COALESCE(SUM(price), 0) AS total_sum, COUNT(*) AS total_count")
 	}
 
-	if err := sumQuery.Select("SUM(price)").Scan(&totalSum).Error; err != nil {
+	if err := sumQuery.Scan(&agg).Error; err != nil {
 		logger.Error(ctx, "repo: subscription sum failed", err, map[string]interface{}{
 			"filter": filter,
 		})
-		if errors.Is(err, gorm.ErrInvalidData) {
-			return nil, 0, myerrors.ErrInvalidData
-		}
-		var pgErr *pgconn.PgError
-
-		if errors.As(err, &pgErr) {
-			return nil, 0, myerrors.ErrDatabase
-		}
-
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-			return nil, 0, myerrors.ErrDatabase
-		}
-
-		return nil, 0, myerrors.ErrDatabase
+		return nil, mapError(err, myerrors.ErrDatabase)
 	}
 
 	var m []*models.Subscription
 
-	rowsQuery := r.DB.WithContext(ctx).Model(&models.Subscription{})
-
-	if filter.UserID != nil {
-		rowsQuery = rowsQuery.Where("user_id = ?", *filter.UserID)
-	}
-	if filter.ServiceName != nil {
-		rowsQuery = rowsQuery.Where("service_name = ?", *filter.ServiceName)
-	}
-	if filter.StartDate != nil {
-		rowsQuery = rowsQuery.Where("start_date >= ?", filter.StartDate.Time)
-	}
-	if filter.EndDate != nil {
-		rowsQuery = rowsQuery.Where("end_date <= ?", filter.EndDate.Time)
-	}
+	rowsQuery := r.filtered(ctx, filter)
 
 	if err := rowsQuery.
+		Order("created_at, id").
 		Limit(filter.Limit).
 		Offset(filter.Offset).
 		Find(&m).Error; err != nil {
@@ -190,48 +170,163 @@ func (r *subRepository) Sum(ctx context.Context, filter *domain.SubscriptionFilt
 			"filter": filter,
 		})
 
-		if errors.Is(err, gorm.ErrInvalidData) {
-			return nil, 0, myerrors.ErrInvalidData
-		}
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
 
-		var pgErr *pgconn.PgError
+	rows, err := models.ToDomains(m)
+	if err != nil {
+		logger.Error(ctx, "subscription convert failed", err, map[string]interface{}{
+			"models": m,
+		})
+		return nil, myerrors.ErrDatabase
+	}
 
-		if errors.As(err, &pgErr) {
-			return nil, 0, myerrors.ErrDatabase
+	if filter.UserID != nil {
+		if err := r.restoreShares(ctx, *filter.UserID, rows); err != nil {
+			logger.Error(ctx, "repo: subscription shares fetch failed", err, map[string]interface{}{
+				"filter": filter,
+			})
+			return nil, mapError(err, myerrors.ErrDatabase)
 		}
+	}
 
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-			return nil, 0, myerrors.ErrDatabase
-		}
+	return domain.NewSumResult(rows, int(agg.TotalSum), int(agg.TotalCount)), nil
+}
 
-		return nil, 0, myerrors.ErrDatabase
+// memberOf — подписки пользователя: свои не общие и общие, где он
+// участник, в том числе как владелец.
+const memberOf = `((user_id = ? AND NOT EXISTS (SELECT 1 FROM subscription_members sm WHERE sm.subscription_id = subscriptions.id))
+	OR EXISTS (SELECT 1 FROM subscription_members sm WHERE sm.subscription_id = subscriptions.id AND sm.user_id = ?))`
+
+// memberShare — доля пользователя в подписке, NULL — подписка не общая.
+const memberShare = `(SELECT sm.share FROM subscription_members sm WHERE sm.subscription_id = subscriptions.id AND sm.user_id = ?)`
+
+// restoreShares задаёт строкам страницы долю пользователя userID в общих
+// подписках.
+func (r *subRepository) restoreShares(ctx context.Context, userID uuid.UUID, rows []*domain.Subscription) error {
+	if len(rows) == 0 {
+		return nil
 	}
 
-	rows, err := models.ToDomains(m)
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, sub := range rows {
+		ids = append(ids, sub.ID())
+	}
+
+	var members []models.SubscriptionMember
+	err := conn(ctx, r.DB).
+		Where("user_id = ? AND subscription_id IN ?", userID, ids).
+		Find(&members).Error
 	if err != nil {
-		logger.Error(ctx, "subscription convert failed", err, map[string]interface{}{
-			"models": m,
-		})
-		return nil, 0, myerrors.ErrDatabase
+		return err
 	}
 
-	sum := 0
-	if totalSum.Valid {
-		sum = int(totalSum.Int64)
+	shares := make(map[uuid.UUID]int, len(members))
+	for _, m := range members {
+		shares[m.SubscriptionID] = m.Share
 	}
+	for _, sub := range rows {
+		if share, ok := shares[sub.ID()]; ok {
+			sub.RestoreShare(&share)
+		}
+	}
+	return nil
+}
+
+// filtered строит запрос с условиями фильтра Sum. В SQLite даты хранятся
+// текстом, поэтому сравниваются через date(), а не как строки.
+func (r *subRepository) filtered(ctx context.Context, filter *domain.SubscriptionFilter) *gorm.DB {
+	q := conn(ctx, r.DB).Model(&models.Subscription{})
 
-	return rows, sum, nil
+	startCond, endCond := "start_date >= ?", "end_date <= ?"
+	if r.DB.Dialector.Name() == "sqlite" {
+		startCond, endCond = "date(start_date) >= date(?)", "date(end_date) <= date(?)"
+	}
+
+	if filter.UserID != nil {
+		q = q.Where(memberOf, *filter.UserID, *filter.UserID)
+	}
+	if filter.ServiceName != nil {
+		q = q.Where("service_name = ?", *filter.ServiceName)
+	}
+	if filter.StartDate != nil {
+		q = q.Where(startCond, filter.StartDate.Time)
+	}
+	if filter.EndDate != nil {
+		q = q.Where(endCond, filter.EndDate.Time)
+	}
+
+	return r.withLabels(q, filter.Labels)
+}
+
+// jsonbHasKey — оператор ? Postgres. GORM принимает ? в тексте условия
+// за параметр, поэтому оператор подставляется выражением-параметром.
+var jsonbHasKey = clause.Expr{SQL: "?"}
+
+// withLabels добавляет к q условия фильтра по тегам и metadata. В Postgres
+// это @>, ? и OR из @>, которые обслуживают индексы GIN; в SQLite JSON
+// разбирается через json_each и json_extract.
+func (r *subRepository) withLabels(q *gorm.DB, f domain.LabelFilter) *gorm.DB {
+	sqlite := r.DB.Dialector.Name() == "sqlite"
+
+	if len(f.Tags) > 0 {
+		switch {
+		case sqlite && f.TagMatch == domain.TagMatchAll:
+			q = q.Where("(SELECT COUNT(DISTINCT value) FROM json_each(subscriptions.tags) WHERE value IN ?) = ?", f.Tags, len(f.Tags))
+		case sqlite:
+			q = q.Where("EXISTS (SELECT 1 FROM json_each(subscriptions.tags) WHERE value IN ?)", f.Tags)
+		case f.TagMatch == domain.TagMatchAll:
+			q = q.Where("tags @> ?::jsonb", tagsFilterJSON(f.Tags...))
+		default:
+			anyTag := r.DB.Where("tags @> ?::jsonb", tagsFilterJSON(f.Tags[0]))
+			for _, t := range f.Tags[1:] {
+				anyTag = anyTag.Or("tags @> ?::jsonb", tagsFilterJSON(t))
+			}
+			q = q.Where(anyTag)
+		}
+	}
+
+	for _, c := range f.Metadata {
+		switch {
+		case sqlite && c.Value == nil:
+			q = q.Where("json_type(metadata, ?) IS NOT NULL", jsonPath(c.Key))
+		case sqlite:
+			q = q.Where("json_type(metadata, ?) = 'text' AND json_extract(metadata, ?) = ?", jsonPath(c.Key), jsonPath(c.Key), *c.Value)
+		case c.Value == nil:
+			q = q.Where("metadata ? ?", jsonbHasKey, c.Key)
+		default:
+			q = q.Where("metadata @> ?::jsonb", metadataFilterJSON(c))
+		}
+	}
+
+	return q
+}
+
+func tagsFilterJSON(tags ...string) string {
+	b, _ := json.Marshal(tags)
+	return string(b)
+}
+
+func metadataFilterJSON(c domain.MetadataCond) string {
+	b, _ := json.Marshal(map[string]string{c.Key: *c.Value})
+	return string(b)
+}
+
+// jsonPath — путь SQLite к ключу верхнего уровня. Ключи metadata состоят
+// из латиницы, цифр, - и _, экранирование не нужно.
+func jsonPath(key string) string {
+	return `$."` + key + `"`
 }
 
 func (s *subRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
 
-	query := s.DB.WithContext(ctx).Model(&models.Subscription{})
+	query := conn(ctx, s.DB).Model(&models.Subscription{})
 
 	err := query.Count(&count).Error
 	if err != nil {
 		logger.Error(ctx, "repo: subscription count failed", err, nil)
-		return 0, myerrors.ErrDatabase
+		return 0, mapError(err, myerrors.ErrDatabase)
 	}
 
 	return count, nil
@@ -240,10 +335,11 @@ func (s *subRepository) Count(ctx context.Context) (int64, error) {
 func (s *subRepository) Update(ctx context.Context, sub *domain.Subscription) error {
 	m := models.FromDomain(sub)
 
-	err := s.DB.WithContext(ctx).
+	err := conn(ctx, s.DB).
 		Model(&models.Subscription{}).
 		Where("id = ?", sub.ID()).
 		Select("*").
+		Omit("created_at").
 		Updates(m).
 		Error
 
@@ -253,59 +349,140 @@ func (s *subRepository) Update(ctx context.Context, sub *domain.Subscription) er
 			"data": sub,
 		})
 
-		if errors.Is(err, gorm.ErrInvalidData) {
-			return myerrors.ErrInvalidData
-		}
+		return mapError(err, myerrors.ErrUpdateFailed)
+	}
 
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			switch pgErr.Code {
-			case "23505":
-				return myerrors.ErrInvalidData
-			case "23503":
-				return myerrors.ErrInvalidData
-			default:
-				return myerrors.ErrDatabase
-			}
-		}
+	return nil
+}
 
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-			return myerrors.ErrDatabase
-		}
+func (s *subRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	err := conn(ctx, s.DB).Delete(&models.Subscription{}, id).Error
+	if err != nil {
+
+		logger.Error(ctx, "repo: subscription delete failed", err, map[string]interface{}{
+			"id": id,
+		})
 
-		return myerrors.ErrUpdateFailed
+		return mapError(err, myerrors.ErrDeleteFailed)
 	}
 
 	return nil
 }
 
-func (s *subRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	err := s.DB.WithContext(ctx).Delete(&models.Subscription{}, id).Error
+func (s *subRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Subscription, error) {
+	var m []*models.Subscription
+
+	err := conn(ctx, s.DB).
+		Clauses(clause.Returning{}).
+		Where("user_id = ?", userID).
+		Delete(&m).Error
 	if err != nil {
 
-		logger.Error(ctx, "repo: subscription delete failed", err, map[string]interface{}{
+		logger.Error(ctx, "repo: user subscriptions delete failed", err, map[string]interface{}{
+			"user_id": userID,
+		})
+
+		return nil, mapError(err, myerrors.ErrDeleteFailed)
+	}
+
+	return models.ToDomains(m)
+}
+
+func (s *subRepository) ListEnded(ctx context.Context, month domain.SubDate) ([]*domain.Subscription, error) {
+	var m []*models.Subscription
+
+	cond := "end_date >= ? AND end_date < ?"
+	if s.DB.Dialector.Name() == "sqlite" {
+		cond = "date(end_date) >= date(?) AND date(end_date) < date(?)"
+	}
+
+	err := conn(ctx, s.DB).
+		Where(cond, month.Time, month.AddDate(0, 1, 0)).
+		Order("created_at, id").
+		Find(&m).Error
+	if err != nil {
+		logger.Error(ctx, "repo: ended subscriptions fetch failed", err, map[string]interface{}{
+			"month": month.Format("01-2006"),
+		})
+		return nil, mapError(err, myerrors.ErrListFailed)
+	}
+
+	return models.ToDomains(m)
+}
+
+func (s *subRepository) ListByService(ctx context.Context, serviceID uuid.UUID) ([]*domain.Subscription, error) {
+	var m []*models.Subscription
+
+	err := conn(ctx, s.DB).
+		Where("service_id = ?", serviceID).
+		Order("created_at, id").
+		Find(&m).Error
+	if err != nil {
+		logger.Error(ctx, "repo: service subscriptions fetch failed", err, map[string]interface{}{
+			"service_id": serviceID,
+		})
+		return nil, mapError(err, myerrors.ErrListFailed)
+	}
+
+	return models.ToDomains(m)
+}
+
+func (s *subRepository) Split(ctx context.Context, id uuid.UUID) (*domain.Split, error) {
+	var owner struct {
+		UserID uuid.UUID
+	}
+	var rows []models.SubscriptionMember
+
+	q := conn(ctx, s.DB)
+	err := q.Model(&models.Subscription{}).Select("user_id").Where("id = ?", id).Take(&owner).Error
+	if err == nil {
+		err = q.Where("subscription_id = ?", id).Find(&rows).Error
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: subscription members fetch failed", err, map[string]interface{}{
 			"id": id,
 		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
 
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return myerrors.ErrNotFound
-		}
+	return models.MembersToDomain(owner.UserID, rows), nil
+}
 
-		if errors.Is(err, gorm.ErrInvalidData) {
-			return myerrors.ErrInvalidData
+func (s *subRepository) SaveSplit(ctx context.Context, id uuid.UUID, split *domain.Split) error {
+	err := conn(ctx, s.DB).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.SubscriptionMember{}, "subscription_id = ?", id).Error; err != nil {
+			return err
 		}
-
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			return myerrors.ErrDatabase
+		if split == nil {
+			return nil
 		}
+		rows := models.MembersFromDomain(id, split)
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		logger.Error(ctx, "repo: subscription members save failed", err, map[string]interface{}{
+			"id":    id,
+			"split": split,
+		})
+		return mapError(err, myerrors.ErrUpdateFailed)
+	}
 
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-			return myerrors.ErrDatabase
-		}
+	return nil
+}
+
+func (s *subRepository) ListSharedWith(ctx context.Context, userID uuid.UUID) ([]*domain.Subscription, error) {
+	var m []*models.Subscription
 
-		return myerrors.ErrDeleteFailed
+	err := conn(ctx, s.DB).
+		Where("user_id <> ? AND id IN (SELECT subscription_id FROM subscription_members WHERE user_id = ?)", userID, userID).
+		Order("created_at, id").
+		Find(&m).Error
+	if err != nil {
+		logger.Error(ctx, "repo: shared subscriptions fetch failed", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return nil, mapError(err, myerrors.ErrListFailed)
 	}
 
-	return nil
+	return models.ToDomains(m)
 }
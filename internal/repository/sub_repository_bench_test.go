@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/repository"
+)
+
+// benchRows — размер таблицы, на котором сравниваются реализации.
+const benchRows = 10000
+
+// benchRepos заполняет subscriptions и возвращает GORM- и pgx-реализации
+// поверх одной базы.
+func benchRepos(b *testing.B) map[string]repository.SubRepository {
+	b.Helper()
+
+	dsn := pgtest.DSN(b)
+	db := openDB(b, dsn)
+
+	for _, q := range []string{
+		"TRUNCATE subscriptions CASCADE",
+		`INSERT INTO users (id, display_name)
+		SELECT ('00000000-0000-0000-0000-' || lpad(i::text, 12, '0'))::uuid, 'user ' || i
+		FROM generate_series(0, 99) AS i
+		ON CONFLICT DO NOTHING`,
+		`INSERT INTO subscriptions (id, service_name, price, user_id, start_date, end_date)
+		SELECT gen_random_uuid(),
+		       'service-' || (i % 20),
+		       100 + i % 900,
+		       ('00000000-0000-0000-0000-' || lpad((i % 100)::text, 12, '0'))::uuid,
+		       DATE '2024-01-01' + (i % 365),
+		       CASE WHEN i % 3 = 0 THEN NULL ELSE DATE '2025-01-01' + (i % 365) END
+		FROM generate_series(1, ` + strconv.Itoa(benchRows) + `) AS i`,
+		"ANALYZE subscriptions",
+	} {
+		if err := db.Exec(q).Error; err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	gormRepo := repository.NewSubRepository(db)
+	return map[string]repository.SubRepository{
+		"gorm": gormRepo,
+		"pgx":  repository.NewPgxSubRepository(openPgxPool(b, dsn), gormRepo),
+	}
+}
+
+func BenchmarkList(b *testing.B) {
+	repos := benchRepos(b)
+	paging := &domain.PagingBase{Limit: 50, Offset: benchRows / 2}
+
+	for _, name := range []string{"gorm", "pgx"} {
+		repo := repos[name]
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			for i := 0; i < b.N; i++ {
+				if _, total, err := repo.List(ctx, domain.LabelFilter{}, paging); err != nil || total != benchRows {
+					b.Fatalf("List: total %d, err %v", total, err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSum(b *testing.B) {
+	repos := benchRepos(b)
+	service := "service-7"
+	filter := &domain.SubscriptionFilter{
+		ServiceName: &service,
+		Limit:       50,
+	}
+
+	for _, name := range []string{"gorm", "pgx"} {
+		repo := repos[name]
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.Sum(ctx, filter); err != nil {
+					b.Fatalf("Sum: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/user"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/migrator"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+	"testingtask/migrations"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	pgtest.Main(m)
+}
+
+// openDB подключается к тестовой базе и применяет миграции.
+func openDB(t testing.TB, dsn string) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	m, err := migrator.New(sqlDB, migrations.FS, migrator.Postgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	return db
+}
+
+// resetSubscriptions очищает подписки и заводит пользователей, на которых
+// ссылаются сценарии repotest, и extra: subscriptions.user_id — внешний
+// ключ на users.
+func resetSubscriptions(t testing.TB, db *gorm.DB, extra ...uuid.UUID) {
+	t.Helper()
+
+	if err := db.Exec("TRUNCATE subscriptions CASCADE").Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range append(append([]uuid.UUID{}, repotest.Users...), extra...) {
+		err := db.Exec("INSERT INTO users (id, display_name) VALUES (?, ?) ON CONFLICT DO NOTHING",
+			id, "user "+id.String()[:8]).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestSubRepository прогоняет общий набор на Postgres. Таблица
+// subscriptions очищается перед каждым подтестом.
+func TestSubRepository(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+
+	repotest.RunSubRepository(t, func(t *testing.T) repository.SubRepository {
+		resetSubscriptions(t, db)
+		return repository.NewSubRepository(db)
+	})
+}
+
+// TestSubRepositoryForeignKey проверяет, что подписка и участник общей
+// подписки с несуществующим user_id отдаются как user.ErrUnknownUser по
+// внешним ключам на users, а пользователя с подписками нельзя удалить.
+func TestSubRepositoryForeignKey(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+	resetSubscriptions(t, db)
+
+	ctx := context.Background()
+	r := repository.NewSubRepository(db)
+	start := domain.NewSubDateFromTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	owner := repotest.Users[0]
+
+	err := r.Create(ctx, repotest.Sub(uuid.New(), "Netflix", 100, start, nil))
+	if !errors.Is(err, user.ErrUnknownUser) {
+		t.Fatalf("Create: got %v, want ErrUnknownUser", err)
+	}
+
+	s := repotest.Sub(owner, "Spotify", 900, start, nil)
+	if err := r.Create(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	split, err := domain.NewSplit(domain.SplitEqual, owner, s.Price(), []domain.Member{{UserID: uuid.New()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SaveSplit(ctx, s.ID(), split); !errors.Is(err, user.ErrUnknownUser) {
+		t.Fatalf("SaveSplit: got %v, want ErrUnknownUser", err)
+	}
+
+	if err := repository.NewUserRepository(db).Delete(ctx, owner); !errors.Is(err, user.ErrUserInUse) {
+		t.Fatalf("Delete user with subscriptions: got %v, want ErrUserInUse", err)
+	}
+}
+
+// TestSubRepositoryQueryTimeout проверяет, что истёкший во время запроса
+// контекст отдаётся как ErrDatabase, а не как ошибка конкретной операции.
+func TestSubRepositoryQueryTimeout(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+
+	// Блокируем таблицу в отдельной транзакции, чтобы запрос репозитория
+	// гарантированно ждал до истечения таймаута.
+	tx := db.Begin()
+	if err := tx.Exec("LOCK TABLE subscriptions IN ACCESS EXCLUSIVE MODE").Error; err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err := repository.NewSubRepository(db).Count(ctx)
+	if !errors.Is(err, myerrors.ErrDatabase) {
+		t.Fatalf("got %v, want ErrDatabase", err)
+	}
+}
+
+func TestTxManager(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+
+	repotest.RunTxManager(t, func(t *testing.T) (repository.SubRepository, repository.TxManager) {
+		resetSubscriptions(t, db)
+		return repository.NewSubRepository(db), repository.NewTxManager(db)
+	})
+}
+
+// TestTxManagerRepeatableRead проверяет, что отчёт в REPEATABLE READ видит
+// один снимок: строка, вставленная другой транзакцией между двумя
+// чтениями, в итоги не попадает.
+func TestTxManagerRepeatableRead(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+	user := uuid.New()
+	resetSubscriptions(t, db, user)
+
+	repo := repository.NewSubRepository(db)
+	tx := repository.NewTxManager(db)
+	start := domain.NewSubDateFromTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if err := repo.Create(context.Background(), repotest.Sub(user, "Netflix", 400, start, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := repository.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := tx.Do(context.Background(), opts, func(ctx context.Context) error {
+		first, err := repo.Sum(ctx, &domain.SubscriptionFilter{UserID: &user, Limit: 10})
+		if err != nil {
+			return err
+		}
+
+		// Вне транзакции: ctx без неё.
+		if err := repo.Create(context.Background(), repotest.Sub(user, "Spotify", 200, start, nil)); err != nil {
+			return err
+		}
+
+		second, err := repo.Sum(ctx, &domain.SubscriptionFilter{UserID: &user, Limit: 10})
+		if err != nil {
+			return err
+		}
+		if second.TotalSum != first.TotalSum || second.TotalCount != first.TotalCount {
+			t.Errorf("snapshot changed: %d/%d, then %d/%d",
+				first.TotalSum, first.TotalCount, second.TotalSum, second.TotalCount)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/readpref"
+	logger "testingtask/pkg"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// TxOptions — параметры транзакции. Нулевое значение — уровень изоляции
+// базы по умолчанию (READ COMMITTED в Postgres), чтение и запись.
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+}
+
+// TxManager выполняет несколько вызовов репозиториев в одной транзакции.
+// Транзакция передаётся через ctx: методы репозиториев, вызванные с ctx
+// из fn, работают в ней.
+type TxManager interface {
+	// Do открывает транзакцию и вызывает fn. Ошибка или паника в fn
+	// откатывают транзакцию, иначе она фиксируется. Если в ctx уже есть
+	// транзакция, fn выполняется в ней, а opts игнорируются.
+	Do(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+// txFromContext возвращает транзакцию, открытую TxManager.Do.
+func txFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok
+}
+
+// conn возвращает транзакцию из ctx, если она есть, иначе db. Чтение
+// вне транзакции может уйти на реплику, если запрос не требует primary
+// (readpref.Primary).
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	if readpref.Primary(ctx) {
+		return db.WithContext(ctx).Clauses(dbresolver.Write)
+	}
+	return db.WithContext(ctx)
+}
+
+type gormTxManager struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) TxManager {
+	return &gormTxManager{db: db}
+}
+
+func (m *gormTxManager) Do(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+	if _, ok := txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	// Ошибка fn возвращается как есть, ошибки BEGIN/COMMIT переводятся
+	// в myerrors. SQLite уровни изоляции не поддерживает (его транзакции
+	// всегда сериализуемы), драйвер их игнорирует.
+	var fnErr error
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		fnErr = fn(context.WithValue(ctx, txKey{}, tx))
+		return fnErr
+	}, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
+
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: transaction failed", err, map[string]interface{}{
+			"isolation": opts.Isolation.String(),
+			"read_only": opts.ReadOnly,
+		})
+		return mapError(err, myerrors.ErrDatabase)
+	}
+
+	return nil
+}
+
+// noTxManager — TxManager для хранилища в памяти, где транзакций нет:
+// fn выполняется как есть.
+type noTxManager struct{}
+
+func NewNoTxManager() TxManager {
+	return noTxManager{}
+}
+
+func (noTxManager) Do(ctx context.Context, _ TxOptions, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
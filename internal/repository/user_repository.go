@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/user"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository/models"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserRepository хранит пользователей. email уникален: занятый другим
+// пользователем — ErrInvalidData.
+type UserRepository interface {
+	Create(ctx context.Context, u *user.User) error
+	// Get возвращает пользователя или ErrUserNotFound.
+	Get(ctx context.Context, id uuid.UUID) (*user.User, error)
+	// GetByEmail возвращает пользователя с email или ErrUserNotFound.
+	GetByEmail(ctx context.Context, email string) (*user.User, error)
+	// List возвращает страницу пользователей по имени и их общее число.
+	List(ctx context.Context, paging *domain.PagingBase) ([]*user.User, int64, error)
+	// Update заменяет поля пользователя, дата создания не меняется.
+	Update(ctx context.Context, u *user.User) error
+	// Delete удаляет пользователя. Пользователь, на которого ещё ссылаются
+	// подписки, в Postgres не удаляется (ErrUserInUse); в SQLite и памяти
+	// внешнего ключа нет, подписки удаляет вызывающий.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Missing возвращает ID из ids, для которых нет пользователя.
+	Missing(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error)
+}
+
+type userRepository struct {
+	DB *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{DB: db}
+}
+
+func userToModel(u *user.User) *models.User {
+	return &models.User{
+		ID:          u.ID(),
+		DisplayName: u.DisplayName(),
+		Email:       u.Email(),
+		Currency:    u.Currency(),
+		Timezone:    u.Timezone(),
+		CreatedAt:   u.CreatedAt().UTC(),
+	}
+}
+
+func userToDomain(m *models.User) *user.User {
+	return user.RestoreUser(m.ID, m.DisplayName, m.Email, m.Currency, m.Timezone, m.CreatedAt)
+}
+
+func (r *userRepository) Create(ctx context.Context, u *user.User) error {
+	if err := conn(ctx, r.DB).Create(userToModel(u)).Error; err != nil {
+		logger.Error(ctx, "repo: user create failed", err, map[string]interface{}{
+			"id": u.ID(),
+		})
+		return mapError(err, myerrors.ErrDatabase)
+	}
+
+	return nil
+}
+
+func (r *userRepository) Get(ctx context.Context, id uuid.UUID) (*user.User, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	return r.first(ctx, "email = ?", email)
+}
+
+func (r *userRepository) first(ctx context.Context, query string, arg interface{}) (*user.User, error) {
+	var m models.User
+
+	err := conn(ctx, r.DB).First(&m, query, arg).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, myerrors.ErrUserNotFound
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: user get failed", err, map[string]interface{}{
+			"query": query,
+			"arg":   arg,
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return userToDomain(&m), nil
+}
+
+func (r *userRepository) List(ctx context.Context, paging *domain.PagingBase) ([]*user.User, int64, error) {
+	var (
+		rows  []*models.User
+		total int64
+	)
+
+	err := conn(ctx, r.DB).Model(&models.User{}).Count(&total).Error
+	if err == nil {
+		err = conn(ctx, r.DB).
+			Order("display_name, id").
+			Limit(paging.Limit).
+			Offset(paging.Offset).
+			Find(&rows).Error
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: user list failed", err, map[string]interface{}{
+			"paging": paging,
+		})
+		return nil, 0, mapError(err, myerrors.ErrDatabase)
+	}
+
+	res := make([]*user.User, 0, len(rows))
+	for _, m := range rows {
+		res = append(res, userToDomain(m))
+	}
+	return res, total, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, u *user.User) error {
+	res := conn(ctx, r.DB).Model(&models.User{}).
+		Where("id = ?", u.ID()).
+		Updates(map[string]interface{}{
+			"display_name": u.DisplayName(),
+			"email":        u.Email(),
+			"currency":     u.Currency(),
+			"timezone":     u.Timezone(),
+			"updated_at":   time.Now().UTC(),
+		})
+	if res.Error != nil {
+		logger.Error(ctx, "repo: user update failed", res.Error, map[string]interface{}{
+			"id": u.ID(),
+		})
+		return mapError(res.Error, myerrors.ErrDatabase)
+	}
+	if res.RowsAffected == 0 {
+		return myerrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res := conn(ctx, r.DB).Delete(&models.User{}, "id = ?", id)
+	if res.Error != nil {
+		logger.Error(ctx, "repo: user delete failed", res.Error, map[string]interface{}{
+			"id": id,
+		})
+		if isForeignKeyViolation(res.Error) {
+			return user.ErrUserInUse
+		}
+		return mapError(res.Error, myerrors.ErrDatabase)
+	}
+	if res.RowsAffected == 0 {
+		return myerrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) Missing(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var found []uuid.UUID
+	err := conn(ctx, r.DB).Model(&models.User{}).Where("id IN ?", ids).Pluck("id", &found).Error
+	if err != nil {
+		logger.Error(ctx, "repo: user lookup failed", err, map[string]interface{}{
+			"ids": ids,
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return missingIDs(ids, found), nil
+}
+
+// missingIDs возвращает ID из ids, которых нет в found, без повторов.
+func missingIDs(ids, found []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range found {
+		seen[id] = true
+	}
+	var res []uuid.UUID
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			res = append(res, id)
+		}
+	}
+	return res
+}
@@ -0,0 +1,34 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+	"testingtask/internal/database"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+	repotest.RunUserRepository(t, func(t *testing.T) repository.UserRepository {
+		return repository.NewMemoryUserRepository()
+	})
+}
+
+func TestSQLiteUserRepository(t *testing.T) {
+	repotest.RunUserRepository(t, func(t *testing.T) repository.UserRepository {
+		url := database.SQLiteScheme + filepath.Join(t.TempDir(), "subs.db")
+		return repository.NewUserRepository(openSQLite(t, url))
+	})
+}
+
+func TestUserRepository(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+
+	repotest.RunUserRepository(t, func(t *testing.T) repository.UserRepository {
+		if err := db.Exec("TRUNCATE users CASCADE").Error; err != nil {
+			t.Fatal(err)
+		}
+		return repository.NewUserRepository(db)
+	})
+}
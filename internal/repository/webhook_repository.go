@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/webhook"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository/models"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepository хранит webhook-endpoint'ы пользователей и их доставки.
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, e *webhook.Endpoint) error
+	GetEndpoint(ctx context.Context, id uuid.UUID) (*webhook.Endpoint, error)
+	// ListEndpoints возвращает страницу endpoint'ов и их общее число.
+	ListEndpoints(ctx context.Context, paging *domain.PagingBase) ([]*webhook.Endpoint, int64, error)
+	UpdateEndpoint(ctx context.Context, e *webhook.Endpoint) error
+	// DeleteEndpoint удаляет endpoint вместе с его доставками.
+	DeleteEndpoint(ctx context.Context, id uuid.UUID) error
+	// EndpointsFor возвращает endpoint'ы пользователя userID, подписанные
+	// на eventType.
+	EndpointsFor(ctx context.Context, eventType string, userID uuid.UUID) ([]*webhook.Endpoint, error)
+	// DeleteByUser удаляет endpoint'ы пользователя вместе с доставками и
+	// возвращает их число.
+	DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// AddDeliveries сохраняет доставки. Доставка с уже записанным ID
+	// пропускается.
+	AddDeliveries(ctx context.Context, deliveries ...webhook.Delivery) error
+	// ListDeliveries возвращает страницу доставок endpoint'а, новые
+	// первыми, вместе с журналом попыток.
+	ListDeliveries(ctx context.Context, endpointID uuid.UUID, paging *domain.PagingBase) ([]webhook.Delivery, int64, error)
+	// ClaimDue выбирает до limit доставок, время попытки которых
+	// наступило, и переносит их попытку на now+lease: другой экземпляр их
+	// не возьмёт, а если этот упадёт, доставка вернётся в очередь сама.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]webhook.Delivery, error)
+	// RecordAttempt сохраняет попытку и новое состояние доставки d.
+	RecordAttempt(ctx context.Context, d webhook.Delivery, a webhook.Attempt) error
+	// Redeliver ставит доставку endpoint'а в очередь на now.
+	Redeliver(ctx context.Context, endpointID, deliveryID uuid.UUID, now time.Time) (*webhook.Delivery, error)
+}
+
+type webhookRepository struct {
+	DB *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{DB: db}
+}
+
+func endpointToModel(e *webhook.Endpoint) *models.WebhookEndpoint {
+	var userID *uuid.UUID
+	if id := e.UserID(); id != uuid.Nil {
+		userID = &id
+	}
+	return &models.WebhookEndpoint{
+		ID:         e.ID(),
+		UserID:     userID,
+		URL:        e.URL(),
+		Secret:     e.Secret(),
+		EventTypes: strings.Join(e.EventTypes(), ","),
+		CreatedAt:  e.CreatedAt().UTC(),
+	}
+}
+
+func endpointToDomain(m *models.WebhookEndpoint) *webhook.Endpoint {
+	var userID uuid.UUID
+	if m.UserID != nil {
+		userID = *m.UserID
+	}
+	return webhook.RestoreEndpoint(m.ID, userID, m.URL, m.Secret, strings.Split(m.EventTypes, ","), m.CreatedAt)
+}
+
+func deliveryToModel(d webhook.Delivery) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:            d.ID,
+		EndpointID:    d.EndpointID,
+		EventID:       d.EventID,
+		EventType:     d.EventType,
+		Payload:       d.Payload,
+		Status:        string(d.Status),
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt.UTC(),
+		CreatedAt:     d.CreatedAt.UTC(),
+		DeliveredAt:   utcPtr(d.DeliveredAt),
+	}
+}
+
+func deliveryToDomain(m models.WebhookDelivery) webhook.Delivery {
+	return webhook.Delivery{
+		ID:            m.ID,
+		EndpointID:    m.EndpointID,
+		EventID:       m.EventID,
+		EventType:     m.EventType,
+		Payload:       m.Payload,
+		Status:        webhook.DeliveryStatus(m.Status),
+		Attempts:      m.Attempts,
+		NextAttemptAt: m.NextAttemptAt,
+		CreatedAt:     m.CreatedAt,
+		DeliveredAt:   m.DeliveredAt,
+	}
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+func (r *webhookRepository) CreateEndpoint(ctx context.Context, e *webhook.Endpoint) error {
+	m := endpointToModel(e)
+
+	if err := conn(ctx, r.DB).Create(m).Error; err != nil {
+		logger.Error(ctx, "repo: webhook create failed", err, map[string]interface{}{
+			"id": e.ID(),
+		})
+		return mapError(err, myerrors.ErrCreateFailed)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) GetEndpoint(ctx context.Context, id uuid.UUID) (*webhook.Endpoint, error) {
+	var m models.WebhookEndpoint
+
+	err := conn(ctx, r.DB).First(&m, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, myerrors.ErrWebhookNotFound
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: webhook get failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	return endpointToDomain(&m), nil
+}
+
+func (r *webhookRepository) ListEndpoints(ctx context.Context, paging *domain.PagingBase) ([]*webhook.Endpoint, int64, error) {
+	var (
+		rows  []*models.WebhookEndpoint
+		total int64
+	)
+
+	err := conn(ctx, r.DB).Model(&models.WebhookEndpoint{}).Count(&total).Error
+	if err == nil {
+		err = conn(ctx, r.DB).
+			Order("created_at, id").
+			Limit(paging.Limit).
+			Offset(paging.Offset).
+			Find(&rows).Error
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: webhook list failed", err, map[string]interface{}{
+			"paging": paging,
+		})
+		return nil, 0, mapError(err, myerrors.ErrListFailed)
+	}
+
+	res := make([]*webhook.Endpoint, 0, len(rows))
+	for _, m := range rows {
+		res = append(res, endpointToDomain(m))
+	}
+	return res, total, nil
+}
+
+func (r *webhookRepository) UpdateEndpoint(ctx context.Context, e *webhook.Endpoint) error {
+	m := endpointToModel(e)
+
+	res := conn(ctx, r.DB).
+		Model(&models.WebhookEndpoint{}).
+		Where("id = ?", e.ID()).
+		Updates(map[string]interface{}{
+			"user_id":     m.UserID,
+			"url":         m.URL,
+			"secret":      m.Secret,
+			"event_types": m.EventTypes,
+			"updated_at":  time.Now().UTC(),
+		})
+	if err := res.Error; err != nil {
+		logger.Error(ctx, "repo: webhook update failed", err, map[string]interface{}{
+			"id": e.ID(),
+		})
+		return mapError(err, myerrors.ErrUpdateFailed)
+	}
+	if res.RowsAffected == 0 {
+		return myerrors.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	// Доставки и попытки удаляет ON DELETE CASCADE.
+	if err := conn(ctx, r.DB).Delete(&models.WebhookEndpoint{}, "id = ?", id).Error; err != nil {
+		logger.Error(ctx, "repo: webhook delete failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return mapError(err, myerrors.ErrDeleteFailed)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	res := conn(ctx, r.DB).Delete(&models.WebhookEndpoint{}, "user_id = ?", userID)
+	if res.Error != nil {
+		logger.Error(ctx, "repo: webhook delete by user failed", res.Error, map[string]interface{}{
+			"user_id": userID,
+		})
+		return 0, mapError(res.Error, myerrors.ErrDeleteFailed)
+	}
+
+	return res.RowsAffected, nil
+}
+
+func (r *webhookRepository) EndpointsFor(ctx context.Context, eventType string, userID uuid.UUID) ([]*webhook.Endpoint, error) {
+	var rows []*models.WebhookEndpoint
+
+	// Endpoint'ов у пользователя немного, фильтр по типу проще сделать в
+	// Go, чем разбирать список в SQL двух диалектов.
+	err := conn(ctx, r.DB).Where("user_id = ?", userID).Order("created_at, id").Find(&rows).Error
+	if err != nil {
+		logger.Error(ctx, "repo: webhook endpoints fetch failed", err, map[string]interface{}{
+			"event_type": eventType,
+			"user_id":    userID,
+		})
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	var res []*webhook.Endpoint
+	for _, m := range rows {
+		if e := endpointToDomain(m); e.Accepts(eventType) {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func (r *webhookRepository) AddDeliveries(ctx context.Context, deliveries ...webhook.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	rows := make([]models.WebhookDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		rows = append(rows, deliveryToModel(d))
+	}
+
+	err := conn(ctx, r.DB).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "id"}}, DoNothing: true}).
+		Create(&rows).Error
+	if err != nil {
+		logger.Error(ctx, "repo: webhook deliveries create failed", err, map[string]interface{}{
+			"deliveries": len(deliveries),
+		})
+		return mapError(err, myerrors.ErrCreateFailed)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, endpointID uuid.UUID, paging *domain.PagingBase) ([]webhook.Delivery, int64, error) {
+	var (
+		rows     []models.WebhookDelivery
+		attempts []models.WebhookDeliveryAttempt
+		total    int64
+	)
+
+	q := conn(ctx, r.DB)
+	err := q.Model(&models.WebhookDelivery{}).Where("endpoint_id = ?", endpointID).Count(&total).Error
+	if err == nil {
+		err = q.Where("endpoint_id = ?", endpointID).
+			Order("created_at DESC, id").
+			Limit(paging.Limit).
+			Offset(paging.Offset).
+			Find(&rows).Error
+	}
+	if err == nil && len(rows) > 0 {
+		ids := make([]uuid.UUID, 0, len(rows))
+		for _, m := range rows {
+			ids = append(ids, m.ID)
+		}
+		err = q.Where("delivery_id IN ?", ids).Order("id").Find(&attempts).Error
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: webhook deliveries list failed", err, map[string]interface{}{
+			"endpoint_id": endpointID,
+			"paging":      paging,
+		})
+		return nil, 0, mapError(err, myerrors.ErrListFailed)
+	}
+
+	logs := make(map[uuid.UUID][]webhook.Attempt, len(rows))
+	for _, a := range attempts {
+		logs[a.DeliveryID] = append(logs[a.DeliveryID], webhook.Attempt{
+			AttemptedAt: a.AttemptedAt,
+			StatusCode:  a.StatusCode,
+			Error:       a.Error,
+			Duration:    time.Duration(a.DurationMs) * time.Millisecond,
+		})
+	}
+
+	res := make([]webhook.Delivery, 0, len(rows))
+	for _, m := range rows {
+		d := deliveryToDomain(m)
+		d.Log = logs[m.ID]
+		res = append(res, d)
+	}
+	return res, total, nil
+}
+
+func (r *webhookRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]webhook.Delivery, error) {
+	var rows []models.WebhookDelivery
+	now = now.UTC()
+
+	err := conn(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		q := tx.Where("status = ? AND next_attempt_at <= ?", string(webhook.StatusPending), now).
+			Order("next_attempt_at, id").
+			Limit(limit)
+		// В SQLite нет FOR UPDATE, но и писатель у базы один.
+		if r.DB.Dialector.Name() != "sqlite" {
+			q = q.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+		if err := q.Find(&rows).Error; err != nil || len(rows) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, 0, len(rows))
+		for _, m := range rows {
+			ids = append(ids, m.ID)
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		logger.Error(ctx, "repo: webhook deliveries claim failed", err, nil)
+		return nil, mapError(err, myerrors.ErrDatabase)
+	}
+
+	res := make([]webhook.Delivery, 0, len(rows))
+	for _, m := range rows {
+		res = append(res, deliveryToDomain(m))
+	}
+	return res, nil
+}
+
+func (r *webhookRepository) RecordAttempt(ctx context.Context, d webhook.Delivery, a webhook.Attempt) error {
+	attempt := models.WebhookDeliveryAttempt{
+		DeliveryID:  d.ID,
+		AttemptedAt: a.AttemptedAt.UTC(),
+		StatusCode:  a.StatusCode,
+		Error:       a.Error,
+		DurationMs:  int(a.Duration / time.Millisecond),
+	}
+
+	err := conn(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id = ?", d.ID).
+			Updates(map[string]interface{}{
+				"status":          string(d.Status),
+				"attempts":        d.Attempts,
+				"next_attempt_at": d.NextAttemptAt.UTC(),
+				"delivered_at":    utcPtr(d.DeliveredAt),
+			}).Error
+	})
+	if err != nil {
+		logger.Error(ctx, "repo: webhook attempt record failed", err, map[string]interface{}{
+			"delivery_id": d.ID,
+		})
+		return mapError(err, myerrors.ErrUpdateFailed)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) Redeliver(ctx context.Context, endpointID, deliveryID uuid.UUID, now time.Time) (*webhook.Delivery, error) {
+	var m models.WebhookDelivery
+
+	err := conn(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND endpoint_id = ?", deliveryID, endpointID).
+			Updates(map[string]interface{}{
+				"status":          string(webhook.StatusPending),
+				"next_attempt_at": now.UTC(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return myerrors.ErrDeliveryNotFound
+		}
+		return tx.First(&m, "id = ?", deliveryID).Error
+	})
+	if errors.Is(err, myerrors.ErrDeliveryNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		logger.Error(ctx, "repo: webhook redeliver failed", err, map[string]interface{}{
+			"delivery_id": deliveryID,
+		})
+		return nil, mapError(err, myerrors.ErrUpdateFailed)
+	}
+
+	d := deliveryToDomain(m)
+	return &d, nil
+}
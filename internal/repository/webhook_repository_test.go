@@ -0,0 +1,36 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+	"testingtask/internal/database"
+	"testingtask/internal/pgtest"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+)
+
+func TestMemoryWebhookRepository(t *testing.T) {
+	repotest.RunWebhookRepository(t, func(t *testing.T) repository.WebhookRepository {
+		return repository.NewMemoryWebhookRepository()
+	})
+}
+
+func TestSQLiteWebhookRepository(t *testing.T) {
+	repotest.RunWebhookRepository(t, func(t *testing.T) repository.WebhookRepository {
+		url := database.SQLiteScheme + filepath.Join(t.TempDir(), "subs.db")
+		return repository.NewWebhookRepository(openSQLite(t, url))
+	})
+}
+
+func TestWebhookRepository(t *testing.T) {
+	db := openDB(t, pgtest.DSN(t))
+
+	repotest.RunWebhookRepository(t, func(t *testing.T) repository.WebhookRepository {
+		if err := db.Exec("TRUNCATE webhook_endpoints CASCADE").Error; err != nil {
+			t.Fatal(err)
+		}
+		// webhook_endpoints.user_id — внешний ключ на users.
+		resetSubscriptions(t, db)
+		return repository.NewWebhookRepository(db)
+	})
+}
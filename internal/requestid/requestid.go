@@ -0,0 +1,56 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	Header    = echo.HeaderXRequestID
+	MaxLength = 128
+)
+
+type ctxKey struct{}
+
+// New генерирует идентификатор для запросов без валидного X-Request-ID.
+func New() string {
+	return uuid.New().String()
+}
+
+// Valid проверяет длину и набор символов входящего X-Request-ID.
+// Допускаются только [A-Za-z0-9._:-], чтобы значение было безопасно
+// класть в заголовки, логи и SQL-комментарии.
+func Valid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z',
+			c >= 'A' && c <= 'Z',
+			c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
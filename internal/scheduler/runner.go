@@ -0,0 +1,358 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+// Ключи блокировок в Store.
+const (
+	leaderKey = "scheduler:leader"
+	jobKey    = "scheduler:job:"
+)
+
+type Config struct {
+	// Instance — имя экземпляра в истории запусков, обычно hostname.
+	Instance string
+	// ElectionInterval — как часто экземпляр пытается стать лидером, а
+	// лидер проверяет, что блокировка всё ещё за ним.
+	ElectionInterval time.Duration
+}
+
+type entry struct {
+	job      Job
+	schedule cron.Schedule
+	next     time.Time
+}
+
+// JobInfo — задача и её расписание для списка задач.
+type JobInfo struct {
+	Name     string
+	Schedule string
+	// NextRunAt известно только лидеру.
+	NextRunAt *time.Time
+	LastRun   *Run
+}
+
+// Scheduler выполняет задачи по расписанию, пока экземпляр — лидер, и
+// запускает их вручную на любом экземпляре.
+type Scheduler struct {
+	store Store
+	cfg   Config
+	jobs  []*entry
+	now   func() time.Time
+
+	// runCtx живёт до остановки Run: запуски не должны обрываться
+	// вместе с HTTP-запросом, который их начал.
+	runCtx context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	lease Lock
+}
+
+// New проверяет расписания и имена задач.
+func New(store Store, cfg Config, jobs ...Job) (*Scheduler, error) {
+	s := &Scheduler{store: store, cfg: cfg, now: time.Now}
+	seen := make(map[string]bool, len(jobs))
+	for _, j := range jobs {
+		if seen[j.Name] {
+			return nil, fmt.Errorf("job %q registered twice", j.Name)
+		}
+		seen[j.Name] = true
+
+		sched, err := ParseSchedule(j.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("job %q: invalid schedule %q: %w", j.Name, j.Schedule, err)
+		}
+		s.jobs = append(s.jobs, &entry{job: j, schedule: sched})
+	}
+	s.runCtx, s.cancel = context.WithCancel(context.Background())
+	return s, nil
+}
+
+// Run выполняет задачи по расписанию до отмены ctx, затем отменяет
+// текущие запуски, дожидается их и отдаёт лидерство.
+func (s *Scheduler) Run(ctx context.Context) {
+	logger.Info(ctx, "scheduler: started", map[string]interface{}{
+		"instance": s.cfg.Instance,
+		"jobs":     len(s.jobs),
+	})
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.stop()
+			logger.Info(context.Background(), "scheduler: stopped", nil)
+			return
+		case <-timer.C:
+		}
+
+		s.tick(ctx)
+		timer.Reset(s.sleep())
+	}
+}
+
+func (s *Scheduler) stop() {
+	s.cancel()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lease != nil {
+		s.lease.Unlock()
+		s.lease = nil
+	}
+}
+
+// Leader сообщает, выполняет ли этот экземпляр задачи по расписанию.
+func (s *Scheduler) Leader() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lease != nil
+}
+
+// Instance возвращает имя экземпляра из Config.
+func (s *Scheduler) Instance() string {
+	return s.cfg.Instance
+}
+
+func (s *Scheduler) tick(ctx context.Context) {
+	now := s.now().UTC()
+
+	if !s.Leader() {
+		if !s.elect(ctx, now) {
+			return
+		}
+	} else if err := s.lease.Held(ctx); err != nil {
+		logger.Error(ctx, "scheduler: leadership lost", err, nil)
+		s.mu.Lock()
+		s.lease.Unlock()
+		s.lease = nil
+		s.mu.Unlock()
+		return
+	}
+
+	s.mu.Lock()
+	var due []*entry
+	for _, e := range s.jobs {
+		if !e.next.After(now) {
+			e.next = e.schedule.Next(now)
+			due = append(due, e)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, e := range due {
+		if _, err := s.start(ctx, e, TriggerSchedule); err != nil {
+			logger.Warn(ctx, "scheduler: scheduled run skipped", map[string]interface{}{
+				"job":   e.job.Name,
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// elect пытается взять лидерство. Новый лидер продолжает расписание от
+// последнего запуска каждой задачи: пропущенный при смене лидера запуск
+// выполняется сразу, а уже выполненный прежним лидером не повторяется.
+func (s *Scheduler) elect(ctx context.Context, now time.Time) bool {
+	lock, ok, err := s.store.TryLock(ctx, leaderKey)
+	if err != nil {
+		logger.Error(ctx, "scheduler: leader election failed", err, nil)
+		return false
+	}
+	if !ok {
+		return false
+	}
+
+	last, err := s.store.LastRuns(ctx)
+	if err != nil {
+		logger.Error(ctx, "scheduler: last runs not loaded", err, nil)
+		lock.Unlock()
+		return false
+	}
+	s.mu.Lock()
+	for _, e := range s.jobs {
+		from := now
+		if r, ok := last[e.job.Name]; ok {
+			from = r.StartedAt
+		}
+		e.next = e.schedule.Next(from)
+	}
+	s.lease = lock
+	s.mu.Unlock()
+
+	logger.Info(ctx, "scheduler: became leader", map[string]interface{}{
+		"instance": s.cfg.Instance,
+	})
+	return true
+}
+
+// sleep возвращает время до ближайшего запуска, но не больше
+// ElectionInterval.
+func (s *Scheduler) sleep() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := s.cfg.ElectionInterval
+	if s.lease == nil {
+		return d
+	}
+
+	now := s.now()
+	for _, e := range s.jobs {
+		if until := e.next.Sub(now); until < d {
+			d = until
+		}
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+// Trigger запускает задачу name вне расписания на этом экземпляре и
+// возвращает запись о запуске, не дожидаясь его окончания.
+func (s *Scheduler) Trigger(ctx context.Context, name string) (Run, error) {
+	for _, e := range s.jobs {
+		if e.job.Name == name {
+			return s.start(ctx, e, TriggerManual)
+		}
+	}
+	return Run{}, ErrJobNotFound
+}
+
+// start берёт блокировку задачи, записывает запуск и выполняет задачу в
+// отдельной горутине.
+func (s *Scheduler) start(ctx context.Context, e *entry, trigger string) (Run, error) {
+	lock, ok, err := s.store.TryLock(ctx, jobKey+e.job.Name)
+	if err != nil {
+		return Run{}, err
+	}
+	if !ok {
+		return Run{}, ErrJobRunning
+	}
+
+	r := Run{
+		ID:        uuid.New(),
+		Job:       e.job.Name,
+		Trigger:   trigger,
+		Instance:  s.cfg.Instance,
+		Status:    StatusRunning,
+		StartedAt: s.now().UTC(),
+	}
+	if err := s.store.StartRun(ctx, r); err != nil {
+		lock.Unlock()
+		return Run{}, err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer lock.Unlock()
+		s.execute(e.job, r)
+	}()
+	return r, nil
+}
+
+func (s *Scheduler) execute(job Job, r Run) {
+	ctx := s.runCtx
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	logger.Info(ctx, "scheduler: job started", map[string]interface{}{
+		"job":     job.Name,
+		"run_id":  r.ID,
+		"trigger": r.Trigger,
+	})
+
+	err := runJob(ctx, job)
+	finished := s.now().UTC()
+
+	status, errText := StatusSucceeded, (*string)(nil)
+	if err != nil {
+		status = StatusFailed
+		text := err.Error()
+		errText = &text
+		logger.Error(ctx, "scheduler: job failed", err, map[string]interface{}{
+			"job":         job.Name,
+			"run_id":      r.ID,
+			"duration_ms": finished.Sub(r.StartedAt).Milliseconds(),
+		})
+	} else {
+		logger.Info(ctx, "scheduler: job finished", map[string]interface{}{
+			"job":         job.Name,
+			"run_id":      r.ID,
+			"duration_ms": finished.Sub(r.StartedAt).Milliseconds(),
+		})
+	}
+
+	// Итог записывается и при остановке сервера, когда runCtx уже отменён.
+	if err := s.store.FinishRun(context.WithoutCancel(ctx), r.ID, status, finished, errText); err != nil {
+		logger.Error(ctx, "scheduler: run result not saved", err, map[string]interface{}{
+			"job":    job.Name,
+			"run_id": r.ID,
+		})
+	}
+}
+
+// runJob превращает панику задачи в ошибку запуска.
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// Jobs возвращает задачи, отсортированные по имени, с последним запуском.
+func (s *Scheduler) Jobs(ctx context.Context) ([]JobInfo, error) {
+	last, err := s.store.LastRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := make([]JobInfo, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		info := JobInfo{Name: e.job.Name, Schedule: e.job.Schedule}
+		if s.lease != nil {
+			next := e.next
+			info.NextRunAt = &next
+		}
+		if r, ok := last[e.job.Name]; ok {
+			info.LastRun = &r
+		}
+		res = append(res, info)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// Runs возвращает историю запусков задачи name, новые первыми.
+func (s *Scheduler) Runs(ctx context.Context, name string, limit, offset int) ([]Run, int64, error) {
+	for _, e := range s.jobs {
+		if e.job.Name == name {
+			return s.store.Runs(ctx, name, limit, offset)
+		}
+	}
+	return nil, 0, ErrJobNotFound
+}
@@ -0,0 +1,150 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testingtask/internal/repository"
+	"testingtask/internal/scheduler"
+	"time"
+)
+
+func newScheduler(t *testing.T, store scheduler.Store, instance string, jobs ...scheduler.Job) *scheduler.Scheduler {
+	t.Helper()
+	s, err := scheduler.New(store, scheduler.Config{Instance: instance, ElectionInterval: 20 * time.Millisecond}, jobs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// start запускает s.Run и возвращает функцию остановки.
+func start(s *scheduler.Scheduler) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func lastRun(t *testing.T, store scheduler.Store, job string) scheduler.Run {
+	t.Helper()
+	runs, _, err := store.Runs(context.Background(), job, 1, 0)
+	if err != nil || len(runs) == 0 {
+		t.Fatalf("Runs(%s) = %v, %v", job, runs, err)
+	}
+	return runs[0]
+}
+
+func TestNewRejectsInvalidJobs(t *testing.T) {
+	store := repository.NewMemoryJobRepository()
+	noop := func(context.Context) error { return nil }
+
+	if _, err := scheduler.New(store, scheduler.Config{}, scheduler.Job{Name: "a", Schedule: "every minute", Run: noop}); err == nil {
+		t.Error("New with an invalid schedule: want error")
+	}
+	if _, err := scheduler.New(store, scheduler.Config{},
+		scheduler.Job{Name: "a", Schedule: "@hourly", Run: noop},
+		scheduler.Job{Name: "a", Schedule: "*/5 * * * *", Run: noop},
+	); err == nil {
+		t.Error("New with duplicate names: want error")
+	}
+}
+
+func TestOnlyLeaderRunsScheduledJobs(t *testing.T) {
+	store := repository.NewMemoryJobRepository()
+	var runs atomic.Int32
+	job := scheduler.Job{Name: "tick", Schedule: "@every 1s", Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}}
+
+	first := newScheduler(t, store, "replica-1", job)
+	stopFirst := start(first)
+	waitFor(t, "replica-1 to become leader", first.Leader)
+
+	second := newScheduler(t, store, "replica-2", job)
+	stopSecond := start(second)
+	defer stopSecond()
+
+	waitFor(t, "the scheduled run", func() bool { return runs.Load() == 1 })
+	time.Sleep(300 * time.Millisecond)
+	if second.Leader() || runs.Load() != 1 {
+		t.Fatalf("runs = %d, second leader = %t, want one run by the leader", runs.Load(), second.Leader())
+	}
+	if r := lastRun(t, store, "tick"); r.Instance != "replica-1" || r.Trigger != scheduler.TriggerSchedule {
+		t.Fatalf("run = %+v, want a scheduled run on replica-1", r)
+	}
+
+	// Остановленный лидер отдаёт лидерство второму экземпляру.
+	stopFirst()
+	waitFor(t, "replica-2 to become leader", second.Leader)
+}
+
+func TestTrigger(t *testing.T) {
+	store := repository.NewMemoryJobRepository()
+	release := make(chan struct{})
+	s := newScheduler(t, store, "replica-1",
+		scheduler.Job{Name: "slow", Schedule: "@daily", Run: func(ctx context.Context) error {
+			<-release
+			return nil
+		}},
+		scheduler.Job{Name: "broken", Schedule: "@daily", Run: func(context.Context) error {
+			panic("nil map")
+		}},
+	)
+	defer start(s)()
+	ctx := context.Background()
+
+	run, err := s.Trigger(ctx, "slow")
+	if err != nil {
+		t.Fatalf("Trigger: %v", err)
+	}
+	if run.Status != scheduler.StatusRunning || run.Trigger != scheduler.TriggerManual {
+		t.Fatalf("Trigger = %+v", run)
+	}
+	if _, err := s.Trigger(ctx, "slow"); !errors.Is(err, scheduler.ErrJobRunning) {
+		t.Fatalf("Trigger while running: got %v, want ErrJobRunning", err)
+	}
+	if _, err := s.Trigger(ctx, "missing"); !errors.Is(err, scheduler.ErrJobNotFound) {
+		t.Fatalf("Trigger(missing): got %v, want ErrJobNotFound", err)
+	}
+
+	close(release)
+	waitFor(t, "slow to finish", func() bool { return lastRun(t, store, "slow").Status == scheduler.StatusSucceeded })
+
+	if _, err := s.Trigger(ctx, "broken"); err != nil {
+		t.Fatalf("Trigger(broken): %v", err)
+	}
+	waitFor(t, "broken to fail", func() bool { return lastRun(t, store, "broken").Status == scheduler.StatusFailed })
+	if r := lastRun(t, store, "broken"); r.Error == nil || *r.Error != "job panicked: nil map" {
+		t.Fatalf("broken run error = %v", r.Error)
+	}
+
+	jobs, err := s.Jobs(ctx)
+	if err != nil {
+		t.Fatalf("Jobs: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].Name != "broken" || jobs[1].LastRun == nil || jobs[1].LastRun.ID != run.ID {
+		t.Fatalf("Jobs = %+v", jobs)
+	}
+}
@@ -0,0 +1,86 @@
+// Package scheduler запускает фоновые задачи по cron-расписанию.
+//
+// Задачи по расписанию выполняет только лидер — экземпляр, который держит
+// advisory-блокировку Postgres. Каждый запуск, плановый или ручной,
+// дополнительно берёт блокировку задачи, поэтому одна задача не выполняется
+// одновременно на двух экземплярах. Запуски записываются в историю.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is already running")
+)
+
+// Причины запуска.
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+// Статусы запуска.
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Job — периодическая задача.
+type Job struct {
+	Name string
+	// Schedule — cron-выражение из пяти полей (минуты, часы, день месяца,
+	// месяц, день недели) в UTC или @hourly, @daily, @every 15m.
+	Schedule string
+	// Timeout ограничивает один запуск, 0 — без ограничения.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Run — запись истории запусков задачи.
+type Run struct {
+	ID      uuid.UUID
+	Job     string
+	Trigger string
+	// Instance — экземпляр сервиса, выполнявший запуск.
+	Instance   string
+	Status     string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	Error      *string
+}
+
+// Lock — взятая advisory-блокировка.
+type Lock interface {
+	// Held возвращает ошибку, если блокировка могла быть потеряна,
+	// например, вместе с соединением с базой.
+	Held(ctx context.Context) error
+	Unlock()
+}
+
+// Store хранит историю запусков и выдаёт блокировки.
+type Store interface {
+	// TryLock берёт блокировку key без ожидания. ok=false — её держит
+	// другой экземпляр или запуск.
+	TryLock(ctx context.Context, key string) (lock Lock, ok bool, err error)
+	StartRun(ctx context.Context, r Run) error
+	FinishRun(ctx context.Context, id uuid.UUID, status string, at time.Time, errText *string) error
+	// Runs возвращает запуски задачи, новые первыми, и их общее число.
+	Runs(ctx context.Context, job string, limit, offset int) ([]Run, int64, error)
+	// LastRuns возвращает последний запуск каждой задачи.
+	LastRuns(ctx context.Context) (map[string]Run, error)
+	// PurgeRuns удаляет завершённые запуски, начатые раньше before.
+	PurgeRuns(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ParseSchedule разбирает расписание задачи, см. Job.Schedule.
+func ParseSchedule(spec string) (cron.Schedule, error) {
+	return cron.ParseStandard(spec)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testingtask/internal/cache"
+	"testingtask/internal/domain/analytics"
+	"testingtask/internal/repository"
+	logger "testingtask/pkg"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type AnalyticsService interface {
+	// MRR считает MRR и его движение за каждый месяц с from по to.
+	// serviceName ограничивает расчёт одним сервисом.
+	MRR(ctx context.Context, from, to time.Time, serviceName *string) ([]analytics.MRRPoint, error)
+	// Churn считает отток по сервисам за каждый месяц с from по to.
+	Churn(ctx context.Context, from, to time.Time) ([]analytics.ServiceChurn, error)
+}
+
+// analyticsService кэширует выручку закончившихся месяцев: новые подписки
+// в прошлом не создаются, поэтому она меняется только при удалении или
+// переносе старых подписок и такие изменения видны по истечении ttl.
+// Текущий и будущие месяцы всегда читаются из базы.
+type analyticsService struct {
+	repo     repository.AnalyticsRepository
+	tx       repository.TxManager
+	reportTx repository.TxOptions
+	// cache — nil, если кэш выключен.
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+func NewAnalyticsService(r repository.AnalyticsRepository, tx repository.TxManager, reportIsolation sql.IsolationLevel, c cache.Cache, ttl time.Duration) AnalyticsService {
+	if ttl == 0 {
+		c = nil
+	}
+	return &analyticsService{
+		repo:     r,
+		tx:       tx,
+		reportTx: repository.TxOptions{Isolation: reportIsolation, ReadOnly: true},
+		cache:    c,
+		ttl:      ttl,
+	}
+}
+
+func (s *analyticsService) MRR(ctx context.Context, from, to time.Time, serviceName *string) ([]analytics.MRRPoint, error) {
+	ctx, span := startSpan(ctx, "AnalyticsService.MRR",
+		attribute.String("analytics.from", from.Format("01-2006")),
+		attribute.String("analytics.to", to.Format("01-2006")),
+	)
+	defer span.End()
+
+	prev, months, err := s.revenue(ctx, from, to)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	if serviceName != nil {
+		prev = prev.Service(*serviceName)
+		for i := range months {
+			months[i] = months[i].Service(*serviceName)
+		}
+	}
+	return analytics.MRR(prev, months), nil
+}
+
+func (s *analyticsService) Churn(ctx context.Context, from, to time.Time) ([]analytics.ServiceChurn, error) {
+	ctx, span := startSpan(ctx, "AnalyticsService.Churn",
+		attribute.String("analytics.from", from.Format("01-2006")),
+		attribute.String("analytics.to", to.Format("01-2006")),
+	)
+	defer span.End()
+
+	prev, months, err := s.revenue(ctx, from, to)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return analytics.Churn(prev, months), nil
+}
+
+// revenue возвращает выручку месяца перед from и каждого месяца с from по
+// to. Закончившиеся месяцы берутся из кэша, остальные читаются одним
+// запросом в read-only транзакции.
+func (s *analyticsService) revenue(ctx context.Context, from, to time.Time) (analytics.Month, []analytics.Month, error) {
+	months, err := analytics.Range(from, to)
+	if err != nil {
+		return analytics.Month{}, nil, err
+	}
+	months = append([]time.Time{months[0].AddDate(0, -1, 0)}, months...)
+
+	now := time.Now()
+	res := make([]analytics.Month, len(months))
+	var missing []time.Time
+	var missingIdx []int
+	for i, m := range months {
+		if s.cache != nil && analytics.Closed(m, now) && cache.GetJSON(ctx, s.cache, revenueKey(m), &res[i]) {
+			continue
+		}
+		missing = append(missing, m)
+		missingIdx = append(missingIdx, i)
+	}
+
+	if len(missing) > 0 {
+		var loaded []analytics.Month
+		err := s.tx.Do(ctx, s.reportTx, func(ctx context.Context) error {
+			var err error
+			loaded, err = s.repo.Revenue(ctx, missing)
+			return err
+		})
+		if err != nil {
+			logger.Error(ctx, "service: revenue failed", err, map[string]interface{}{
+				"from": from.Format("01-2006"),
+				"to":   to.Format("01-2006"),
+			})
+			return analytics.Month{}, nil, err
+		}
+
+		for j, m := range loaded {
+			res[missingIdx[j]] = m
+			if s.cache != nil && analytics.Closed(m.Month, now) {
+				cache.SetJSON(ctx, s.cache, revenueKey(m.Month), m, s.ttl)
+			}
+		}
+	}
+
+	logger.Debug(ctx, "service: revenue loaded", map[string]interface{}{
+		"months": len(months),
+		"cached": len(months) - len(missing),
+	})
+	return res[0], res[1:], nil
+}
+
+func revenueKey(month time.Time) string {
+	return "analytics:revenue:" + month.Format("2006-01")
+}
@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testingtask/internal/cache"
+	"testingtask/internal/domain/analytics"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/repository"
+	"testingtask/internal/service"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// countingAnalytics считает запрошенные у хранилища месяцы.
+type countingAnalytics struct {
+	repository.AnalyticsRepository
+	months int
+}
+
+func (r *countingAnalytics) Revenue(ctx context.Context, months []time.Time) ([]analytics.Month, error) {
+	r.months += len(months)
+	return r.AnalyticsRepository.Revenue(ctx, months)
+}
+
+func TestAnalyticsMRR(t *testing.T) {
+	ctx := context.Background()
+	subs := repository.NewMemorySubRepository()
+	repo := &countingAnalytics{AnalyticsRepository: repository.NewMemoryAnalyticsRepository(subs)}
+	svc := service.NewAnalyticsService(repo, repository.NewNoTxManager(), sql.LevelDefault, cache.NewLRU(100), time.Hour)
+
+	netflix := domain.RestoreSubscription(uuid.New(), "Netflix", 400, userA, *start, nil)
+	spotify := domain.RestoreSubscription(uuid.New(), "Spotify", 200, userB, *monthOf(2025, time.March), nil)
+	for _, s := range []*domain.Subscription{netflix, spotify} {
+		if err := subs.Create(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	points, err := svc.MRR(ctx, monthOf(2025, time.February).Time, monthOf(2025, time.April).Time, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 3 || points[0].MRR != 400 || points[1].MRR != 600 || points[1].New != 200 {
+		t.Fatalf("MRR: %+v", points)
+	}
+	// Запрошены все месяцы и январь как предыдущий.
+	if repo.months != 4 {
+		t.Fatalf("first call read %d months, want 4", repo.months)
+	}
+
+	netflixOnly := "Netflix"
+	points, err = svc.MRR(ctx, monthOf(2025, time.February).Time, monthOf(2025, time.April).Time, &netflixOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if points[1].MRR != 400 || points[1].New != 0 {
+		t.Fatalf("Netflix MRR: %+v", points)
+	}
+
+	// Закончившиеся месяцы берутся из кэша: удаление старой подписки в
+	// них не видно до истечения TTL, а в текущем месяце видно сразу.
+	if err := subs.Delete(ctx, spotify.ID()); err != nil {
+		t.Fatal(err)
+	}
+	points, err = svc.MRR(ctx, monthOf(2025, time.March).Time, monthOf(2025, time.April).Time, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.months != 4 || points[0].MRR != 600 {
+		t.Fatalf("cached March: MRR %d, %d months read", points[0].MRR, repo.months)
+	}
+
+	now := time.Now()
+	points, err = svc.MRR(ctx, now, now, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.months != 6 || points[0].MRR != 400 {
+		t.Fatalf("current month: MRR %d, %d months read", points[0].MRR, repo.months)
+	}
+}
+
+func TestAnalyticsChurn(t *testing.T) {
+	ctx := context.Background()
+	subs := repository.NewMemorySubRepository()
+	svc := service.NewAnalyticsService(repository.NewMemoryAnalyticsRepository(subs), repository.NewNoTxManager(), sql.LevelDefault, nil, 0)
+
+	for _, s := range []*domain.Subscription{
+		domain.RestoreSubscription(uuid.New(), "Netflix", 400, userA, *start, monthOf(2025, time.February)),
+		domain.RestoreSubscription(uuid.New(), "Netflix", 400, userB, *start, nil),
+	} {
+		if err := subs.Create(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	churn, err := svc.Churn(ctx, monthOf(2025, time.February).Time, monthOf(2025, time.March).Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(churn) != 1 || churn[0].Churned != 1 || churn[0].Subscribers != 4 || churn[0].Points[1].Rate() != 0.5 {
+		t.Fatalf("churn: %+v", churn)
+	}
+
+	if _, err := svc.Churn(ctx, monthOf(2025, time.March).Time, monthOf(2025, time.February).Time); !errors.Is(err, analytics.ErrInvalidRange) {
+		t.Fatalf("reversed range: got %v, want ErrInvalidRange", err)
+	}
+}
@@ -0,0 +1,522 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testingtask/internal/domain/budget"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/outbox"
+	"testingtask/internal/repository"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// BudgetInput — бюджет из запроса. UserID, ServiceName и Category
+// задаются только при создании.
+type BudgetInput struct {
+	UserID       uuid.UUID
+	ServiceName  *string
+	Category     *string
+	MonthlyLimit int
+	HardLimit    bool
+}
+
+// BudgetReport — траты пользователя за месяц: по всем подпискам и по
+// каждому его бюджету.
+type BudgetReport struct {
+	UserID  uuid.UUID
+	Month   time.Time
+	Spent   int
+	Budgets []budget.Status
+}
+
+type BudgetService interface {
+	Create(ctx context.Context, in BudgetInput) (*budget.Budget, error)
+	Get(ctx context.Context, id uuid.UUID) (*budget.Budget, error)
+	List(ctx context.Context, userID *uuid.UUID, paging *domain.PagingBase) ([]*budget.Budget, int64, error)
+	Update(ctx context.Context, id uuid.UUID, in BudgetInput) (*budget.Budget, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Status считает траты пользователя в месяце month по его активным
+	// подпискам.
+	Status(ctx context.Context, userID uuid.UUID, month time.Time) (*BudgetReport, error)
+}
+
+type budgetService struct {
+	repo    repository.BudgetRepository
+	subs    repository.SubRepository
+	catalog repository.CatalogRepository
+	users   repository.UserRepository
+	tx      repository.TxManager
+	// reportTx — параметры транзакции Status: бюджеты, подписки и
+	// категории должны читаться из одного снимка.
+	reportTx repository.TxOptions
+}
+
+func NewBudgetService(r repository.BudgetRepository, subs repository.SubRepository, catalog repository.CatalogRepository, users repository.UserRepository, tx repository.TxManager, reportIsolation sql.IsolationLevel) BudgetService {
+	return &budgetService{
+		repo:     r,
+		subs:     subs,
+		catalog:  catalog,
+		users:    users,
+		tx:       tx,
+		reportTx: repository.TxOptions{Isolation: reportIsolation, ReadOnly: true},
+	}
+}
+
+// userSubscriptions возвращает все подписки пользователя из тех же данных,
+// что и Sum.
+func userSubscriptions(ctx context.Context, subs repository.SubRepository, userID uuid.UUID) ([]*domain.Subscription, error) {
+	res, err := subs.Sum(ctx, &domain.SubscriptionFilter{UserID: &userID, Limit: -1})
+	if err != nil {
+		return nil, err
+	}
+	return res.Rows, nil
+}
+
+// budgetCategories возвращает категории сервисов каталога, если среди
+// budgets есть бюджет на категорию, иначе nil: остальным бюджетам они не
+// нужны.
+func budgetCategories(ctx context.Context, catalog repository.CatalogRepository, budgets []*budget.Budget) (budget.Categories, error) {
+	for _, b := range budgets {
+		if b.Category() != nil {
+			return catalog.Categories(ctx)
+		}
+	}
+	return nil, nil
+}
+
+func (s *budgetService) Create(ctx context.Context, in BudgetInput) (*budget.Budget, error) {
+	ctx, span := startSpan(ctx, "BudgetService.Create", attribute.String("user.id", in.UserID.String()))
+	defer span.End()
+
+	b, err := budget.NewBudget(uuid.Nil, in.UserID, in.ServiceName, in.Category, in.MonthlyLimit, in.HardLimit)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	// Уникальный индекс тоже не даст создать второй бюджет, но его ошибка
+	// неотличима от прочих нарушений ограничений.
+	err = s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if err := checkUsers(ctx, s.users, in.UserID); err != nil {
+			return err
+		}
+		existing, err := s.repo.ForUser(ctx, in.UserID)
+		if err != nil {
+			return err
+		}
+		for _, o := range existing {
+			if o.SameScope(b) {
+				return budget.ErrBudgetExists
+			}
+		}
+		return s.repo.Create(ctx, b)
+	})
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: budget created", map[string]interface{}{
+		"id":            b.ID(),
+		"user_id":       b.UserID(),
+		"service_name":  b.ServiceName(),
+		"category":      b.Category(),
+		"monthly_limit": b.MonthlyLimit(),
+		"hard_limit":    b.HardLimit(),
+	})
+	return b, nil
+}
+
+func (s *budgetService) Get(ctx context.Context, id uuid.UUID) (*budget.Budget, error) {
+	ctx, span := startSpan(ctx, "BudgetService.Get", attribute.String("budget.id", id.String()))
+	defer span.End()
+
+	b, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return b, nil
+}
+
+func (s *budgetService) List(ctx context.Context, userID *uuid.UUID, paging *domain.PagingBase) ([]*budget.Budget, int64, error) {
+	ctx, span := startSpan(ctx, "BudgetService.List")
+	defer span.End()
+
+	res, total, err := s.repo.List(ctx, userID, paging)
+	if err != nil {
+		return nil, 0, spanError(span, err)
+	}
+	return res, total, nil
+}
+
+func (s *budgetService) Update(ctx context.Context, id uuid.UUID, in BudgetInput) (*budget.Budget, error) {
+	ctx, span := startSpan(ctx, "BudgetService.Update", attribute.String("budget.id", id.String()))
+	defer span.End()
+
+	old, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	b, err := budget.NewBudget(id, old.UserID(), old.ServiceName(), old.Category(), in.MonthlyLimit, in.HardLimit)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	if err := s.repo.Update(ctx, b); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: budget updated", map[string]interface{}{
+		"id":            id,
+		"monthly_limit": b.MonthlyLimit(),
+		"hard_limit":    b.HardLimit(),
+	})
+	return budget.RestoreBudget(id, old.UserID(), old.ServiceName(), old.Category(), b.MonthlyLimit(), b.HardLimit(), old.CreatedAt()), nil
+}
+
+func (s *budgetService) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := startSpan(ctx, "BudgetService.Delete", attribute.String("budget.id", id.String()))
+	defer span.End()
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: budget deleted", map[string]interface{}{
+		"id": id,
+	})
+	return nil
+}
+
+func (s *budgetService) Status(ctx context.Context, userID uuid.UUID, month time.Time) (*BudgetReport, error) {
+	ctx, span := startSpan(ctx, "BudgetService.Status", attribute.String("user.id", userID.String()))
+	defer span.End()
+
+	month = budget.Month(month)
+	report := &BudgetReport{UserID: userID, Month: month}
+
+	err := s.tx.Do(ctx, s.reportTx, func(ctx context.Context) error {
+		budgets, err := s.repo.ForUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		subs, err := userSubscriptions(ctx, s.subs, userID)
+		if err != nil {
+			return err
+		}
+		categories, err := budgetCategories(ctx, s.catalog, budgets)
+		if err != nil {
+			return err
+		}
+
+		for _, sub := range subs {
+			if sub.ActiveIn(month) {
+				report.Spent += sub.Amount()
+			}
+		}
+		for _, b := range budgets {
+			report.Budgets = append(report.Budgets, budget.Status{Budget: b, Month: month, Spent: b.Spent(subs, categories, month)})
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(ctx, "service: budget status failed", err, map[string]interface{}{
+			"user_id": userID,
+			"month":   month.Format("01-2006"),
+		})
+		return nil, spanError(span, err)
+	}
+
+	return report, nil
+}
+
+// budgetedSubService проверяет создание и изменение подписок по бюджетам
+// пользователя. Если с новой подпиской траты выходят за лимит бюджета,
+// записывается событие BudgetExceeded, а бюджет с hard_limit запрещает
+// изменение ошибкой budget.ErrBudgetExceeded.
+type budgetedSubService struct {
+	SubService
+	subs    repository.SubRepository
+	budgets repository.BudgetRepository
+	catalog repository.CatalogRepository
+	events  repository.OutboxRepository
+	tx      repository.TxManager
+}
+
+// NewBudgetedSubService оборачивает next. Проверка, изменение и событие
+// выполняются в одной транзакции. next должен получать подписки уже
+// связанными с каталогом (service_id): по нему считаются бюджеты на
+// категорию.
+func NewBudgetedSubService(next SubService, subs repository.SubRepository, budgets repository.BudgetRepository, catalog repository.CatalogRepository, events repository.OutboxRepository, tx repository.TxManager) SubService {
+	return &budgetedSubService{SubService: next, subs: subs, budgets: budgets, catalog: catalog, events: events, tx: tx}
+}
+
+func (s *budgetedSubService) Create(ctx context.Context, sub *domain.Subscription) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if err := s.check(ctx, sub); err != nil {
+			return err
+		}
+		var err error
+		id, err = s.SubService.Create(ctx, sub)
+		return err
+	})
+	return id, err
+}
+
+func (s *budgetedSubService) Update(ctx context.Context, id uuid.UUID, sub *domain.Subscription) (uuid.UUID, error) {
+	var res uuid.UUID
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		// Строка блокируется до конца транзакции. Отсутствующую подписку и
+		// несовпадение ID обработает next.
+		if _, err := s.subs.GetForUpdate(ctx, id); err == nil && sub.ID() == id {
+			if err := s.check(ctx, sub); err != nil {
+				return err
+			}
+		}
+		var err error
+		res, err = s.SubService.Update(ctx, id, sub)
+		return err
+	})
+	return res, err
+}
+
+// SetMembers, RemoveMember и Unshare меняют доли участников: проверяются
+// бюджеты каждого, чья доля меняется. Ошибки разделения и отсутствующую
+// подписку обработает next.
+func (s *budgetedSubService) SetMembers(ctx context.Context, id uuid.UUID, rule domain.SplitRule, members []domain.Member) (*domain.Subscription, *domain.Split, error) {
+	var (
+		sub   *domain.Subscription
+		split *domain.Split
+	)
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if cur, err := s.subs.GetForUpdate(ctx, id); err == nil {
+			if next, err := domain.NewSplit(rule, cur.UserID(), cur.Price(), members); err == nil {
+				if err := s.checkSplit(ctx, cur, next); err != nil {
+					return err
+				}
+			}
+		}
+		var err error
+		sub, split, err = s.SubService.SetMembers(ctx, id, rule, members)
+		return err
+	})
+	return sub, split, err
+}
+
+func (s *budgetedSubService) RemoveMember(ctx context.Context, id, userID uuid.UUID) (*domain.Subscription, *domain.Split, error) {
+	var (
+		sub   *domain.Subscription
+		split *domain.Split
+	)
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if cur, err := s.subs.GetForUpdate(ctx, id); err == nil {
+			old, err := s.subs.Split(ctx, id)
+			if err != nil {
+				return err
+			}
+			if old != nil {
+				if next, ok := old.Without(userID, cur.Price()); ok {
+					if err := s.checkSplit(ctx, cur, next); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		var err error
+		sub, split, err = s.SubService.RemoveMember(ctx, id, userID)
+		return err
+	})
+	return sub, split, err
+}
+
+func (s *budgetedSubService) Unshare(ctx context.Context, id uuid.UUID) error {
+	return s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if cur, err := s.subs.GetForUpdate(ctx, id); err == nil {
+			if err := s.checkSplit(ctx, cur, nil); err != nil {
+				return err
+			}
+		}
+		return s.SubService.Unshare(ctx, id)
+	})
+}
+
+// check сравнивает траты до и после сохранения sub в первом месяце, когда
+// она оплачивается, но не раньше текущего.
+func (s *budgetedSubService) check(ctx context.Context, sub *domain.Subscription) error {
+	month := chargeMonth(sub)
+	if !sub.ActiveIn(month) {
+		return nil
+	}
+
+	budgets, err := s.budgets.ForUser(ctx, sub.UserID())
+	if err != nil || len(budgets) == 0 {
+		return err
+	}
+
+	before, err := userSubscriptions(ctx, s.subs, sub.UserID())
+	if err != nil {
+		return err
+	}
+
+	shared := false
+	for _, o := range before {
+		if o.ID() == sub.ID() {
+			shared = o.Share() != nil
+		}
+	}
+
+	// Владелец общей подписки тратит свою долю от новой цены. Если
+	// разделение с ней не сходится, изменение отклоняется той же ошибкой,
+	// что вернул бы next: без долей бюджет проверить нельзя.
+	if shared {
+		split, err := s.subs.Split(ctx, sub.ID())
+		if err != nil {
+			return err
+		}
+		if split != nil {
+			if split, err = split.Reprice(sub.UserID(), sub.Price()); err != nil {
+				return err
+			}
+			share, _ := split.ShareOf(sub.UserID())
+			sub = sub.WithShare(share)
+		}
+	}
+
+	return s.compare(ctx, budgets, before, replaceSub(before, sub.ID(), sub), sub, month)
+}
+
+// checkSplit проверяет бюджеты владельца и участников sub — прежних и
+// новых — при замене разделения на split (nil — подписка больше не
+// общая): доли растут, когда участник уходит или меняется правило.
+func (s *budgetedSubService) checkSplit(ctx context.Context, sub *domain.Subscription, split *domain.Split) error {
+	month := chargeMonth(sub)
+	if !sub.ActiveIn(month) {
+		return nil
+	}
+
+	old, err := s.subs.Split(ctx, sub.ID())
+	if err != nil {
+		return err
+	}
+	users := []uuid.UUID{sub.UserID()}
+	seen := map[uuid.UUID]bool{sub.UserID(): true}
+	for _, sp := range []*domain.Split{old, split} {
+		if sp == nil {
+			continue
+		}
+		for _, id := range sp.UserIDs() {
+			if !seen[id] {
+				seen[id] = true
+				users = append(users, id)
+			}
+		}
+	}
+
+	for _, userID := range users {
+		budgets, err := s.budgets.ForUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if len(budgets) == 0 {
+			continue
+		}
+		before, err := userSubscriptions(ctx, s.subs, userID)
+		if err != nil {
+			return err
+		}
+
+		// Исключённый участник больше не платит за sub, владелец не общей
+		// подписки платит полную цену.
+		var paid *domain.Subscription
+		if split == nil {
+			if userID == sub.UserID() {
+				paid = sub
+			}
+		} else if share, ok := split.ShareOf(userID); ok {
+			paid = sub.WithShare(share)
+		}
+		if paid == nil {
+			continue
+		}
+		if err := s.compare(ctx, budgets, before, replaceSub(before, sub.ID(), paid), paid, month); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compare сравнивает траты по budgets до и после изменения sub в месяце
+// month. hard_limit запрещает любое увеличение трат сверх лимита, а
+// событие пишется только при переходе через лимит: о бюджете, превышенном
+// раньше, повторно не сообщается.
+func (s *budgetedSubService) compare(ctx context.Context, budgets []*budget.Budget, before, after []*domain.Subscription, sub *domain.Subscription, month time.Time) error {
+	categories, err := budgetCategories(ctx, s.catalog, budgets)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var events []outbox.Event
+	for _, b := range budgets {
+		if !b.Covers(sub, categories) {
+			continue
+		}
+		spent := b.Spent(before, categories, month)
+		st := budget.Status{Budget: b, Month: month, Spent: b.Spent(after, categories, month)}
+		if !st.Exceeded() || st.Spent <= spent {
+			continue
+		}
+
+		if b.HardLimit() {
+			logger.Warn(ctx, "service: subscription rejected by budget", map[string]interface{}{
+				"budget_id":       b.ID(),
+				"user_id":         b.UserID(),
+				"subscription_id": sub.ID(),
+				"monthly_limit":   b.MonthlyLimit(),
+				"spent":           st.Spent,
+			})
+			return fmt.Errorf("%w: limit %d, projected spend %d in %s",
+				budget.ErrBudgetExceeded, b.MonthlyLimit(), st.Spent, month.Format("01-2006"))
+		}
+
+		if spent > b.MonthlyLimit() {
+			continue
+		}
+		logger.Warn(ctx, "service: budget exceeded", map[string]interface{}{
+			"budget_id":       b.ID(),
+			"user_id":         b.UserID(),
+			"subscription_id": sub.ID(),
+			"monthly_limit":   b.MonthlyLimit(),
+			"spent":           st.Spent,
+		})
+		events = append(events, newBudgetEvent(st, sub, now))
+	}
+
+	return s.events.Add(ctx, events...)
+}
+
+// chargeMonth — первый месяц, когда sub оплачивается, но не раньше
+// текущего.
+func chargeMonth(sub *domain.Subscription) time.Time {
+	month := budget.Month(time.Now())
+	if start := budget.Month(sub.StartDate()); start.After(month) {
+		month = start
+	}
+	return month
+}
+
+// replaceSub возвращает subs, где подписка id заменена на sub (или
+// добавлена).
+func replaceSub(subs []*domain.Subscription, id uuid.UUID, sub *domain.Subscription) []*domain.Subscription {
+	res := make([]*domain.Subscription, 0, len(subs)+1)
+	for _, o := range subs {
+		if o.ID() != id {
+			res = append(res, o)
+		}
+	}
+	return append(res, sub)
+}
@@ -0,0 +1,203 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"testingtask/internal/domain/budget"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/user"
+	"testingtask/internal/service"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBudgetStatus(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withBudgets)
+	netflix := "Netflix"
+
+	mustCreate(t, f.subs, userA, netflix, 400)
+	mustCreate(t, f.subs, userA, "Spotify", 200)
+	mustCreate(t, f.subs, userB, netflix, 1000)
+	ended := domain.RestoreSubscription(uuid.New(), "Kinopoisk", 300, userA, *start, monthOf(2025, time.March))
+	if _, err := f.subs.Create(ctx, ended); err != nil {
+		t.Fatal(err)
+	}
+
+	total := f.budget(t, userA, nil, 500, false)
+	perService := f.budget(t, userA, &netflix, 450, false)
+
+	report, err := f.budgets.Status(ctx, userA, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Spent != 600 || len(report.Budgets) != 2 {
+		t.Fatalf("current month: spent %d with %d budgets, want 600 with 2", report.Spent, len(report.Budgets))
+	}
+	for _, st := range report.Budgets {
+		switch st.Budget.ID() {
+		case total.ID():
+			if st.Spent != 600 || st.Remaining() != -100 || !st.Exceeded() {
+				t.Errorf("total budget: spent %d, remaining %d, exceeded %t", st.Spent, st.Remaining(), st.Exceeded())
+			}
+		case perService.ID():
+			if st.Spent != 400 || st.Remaining() != 50 || st.Exceeded() {
+				t.Errorf("Netflix budget: spent %d, remaining %d, exceeded %t", st.Spent, st.Remaining(), st.Exceeded())
+			}
+		}
+	}
+
+	// В марте 2025 ещё оплачивалась закончившаяся подписка.
+	report, err = f.budgets.Status(ctx, userA, monthOf(2025, time.March).Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Spent != 900 {
+		t.Fatalf("March 2025: spent %d, want 900", report.Spent)
+	}
+}
+
+func TestBudgetUniqueScope(t *testing.T) {
+	f := newHarness(t, withBudgets)
+	netflix := "Netflix"
+
+	f.budget(t, userA, nil, 500, false)
+	f.budget(t, userA, &netflix, 100, false)
+	f.budget(t, userB, nil, 500, false)
+
+	_, err := f.budgets.Create(context.Background(), service.BudgetInput{UserID: userA, MonthlyLimit: 700})
+	if !errors.Is(err, budget.ErrBudgetExists) {
+		t.Fatalf("second total budget: got %v, want ErrBudgetExists", err)
+	}
+
+	_, err = f.budgets.Create(context.Background(), service.BudgetInput{UserID: userA, MonthlyLimit: 0})
+	if !errors.Is(err, budget.ErrInvalidLimit) {
+		t.Fatalf("zero limit: got %v, want ErrInvalidLimit", err)
+	}
+
+	_, err = f.budgets.Create(context.Background(), service.BudgetInput{UserID: uuid.New(), MonthlyLimit: 500})
+	if !errors.Is(err, user.ErrUnknownUser) {
+		t.Fatalf("unknown user: got %v, want ErrUnknownUser", err)
+	}
+}
+
+func TestBudgetExceededEvent(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withBudgets)
+	b := f.budget(t, userA, nil, 500, false)
+
+	mustCreate(t, f.subs, userA, "Netflix", 400)
+	mustCreate(t, f.subs, userB, "Netflix", 400)
+	assertTypes(t, pending(t, f.events), service.EventSubscriptionCreated, service.EventSubscriptionCreated)
+
+	// Подписка, которая выводит траты за лимит, сохраняется и порождает
+	// событие.
+	spotify := mustCreate(t, f.subs, userA, "Spotify", 200)
+	got := pending(t, f.events)
+	assertTypes(t, got, service.EventSubscriptionCreated, service.EventSubscriptionCreated,
+		service.EventBudgetExceeded, service.EventSubscriptionCreated)
+
+	var data service.BudgetExceededEventData
+	if err := json.Unmarshal(got[2].Payload, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.BudgetID != b.ID() || data.Spent != 600 || data.MonthlyLimit != 500 || data.SubscriptionID != spotify.ID() {
+		t.Fatalf("event data: %+v", data)
+	}
+
+	// Бюджет уже превышен: о нём не сообщается повторно.
+	upd := domain.RestoreSubscription(spotify.ID(), "Spotify", 300, userA, *start, nil)
+	if _, err := f.subs.Update(ctx, spotify.ID(), upd); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(pending(t, f.events)); n != 5 {
+		t.Fatalf("after update of exceeded budget: %d events, want 5", n)
+	}
+}
+
+func TestBudgetHardLimit(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withBudgets)
+	netflix := "Netflix"
+	f.budget(t, userA, &netflix, 500, true)
+
+	sub := mustCreate(t, f.subs, userA, netflix, 300)
+
+	// Другие сервисы бюджет на Netflix не ограничивает.
+	mustCreate(t, f.subs, userA, "Spotify", 1000)
+
+	extra := domain.RestoreSubscription(uuid.New(), netflix, 300, userA, *start, nil)
+	if _, err := f.subs.Create(ctx, extra); !errors.Is(err, budget.ErrBudgetExceeded) {
+		t.Fatalf("Create over hard limit: got %v, want ErrBudgetExceeded", err)
+	}
+	if _, err := f.subs.Get(ctx, extra.ID()); err == nil {
+		t.Fatal("subscription over hard limit was saved")
+	}
+
+	upd := domain.RestoreSubscription(sub.ID(), netflix, 600, userA, *start, nil)
+	if _, err := f.subs.Update(ctx, sub.ID(), upd); !errors.Is(err, budget.ErrBudgetExceeded) {
+		t.Fatalf("Update over hard limit: got %v, want ErrBudgetExceeded", err)
+	}
+
+	// Подешевление проходит.
+	upd = domain.RestoreSubscription(sub.ID(), netflix, 200, userA, *start, nil)
+	if _, err := f.subs.Update(ctx, sub.ID(), upd); err != nil {
+		t.Fatalf("Update under hard limit: %v", err)
+	}
+}
+
+func TestBudgetCategory(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withBudgets, withCatalog)
+	streaming, music, netflix, video := "streaming", "music", "Netflix", "Video"
+	for name, category := range map[string]*string{"Netflix": &streaming, "Kinopoisk": &streaming, "Spotify": &music} {
+		if _, err := f.catalog.Create(ctx, service.CatalogInput{Name: name, Category: category}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := f.budgets.Create(ctx, service.BudgetInput{UserID: userA, Category: &streaming, MonthlyLimit: 1000, HardLimit: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.budget(t, userA, nil, 5000, false)
+
+	// Бюджет на категорию покрывает подписки, связанные с её сервисами, —
+	// под любым написанием имени.
+	mustCreate(t, f.subs, userA, "netflix", 400)
+	mustCreate(t, f.subs, userA, "Kinopoisk", 500)
+	mustCreate(t, f.subs, userA, "Spotify", 800)
+	mustCreate(t, f.subs, userA, "Okko", 300)
+
+	extra := domain.RestoreSubscription(uuid.New(), "Netflix", 200, userA, *start, nil)
+	if _, err := f.subs.Create(ctx, extra); !errors.Is(err, budget.ErrBudgetExceeded) {
+		t.Fatalf("Create over category hard limit: got %v, want ErrBudgetExceeded", err)
+	}
+
+	report, err := f.budgets.Status(ctx, userA, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range report.Budgets {
+		if st.Budget.ID() == b.ID() && st.Spent != 900 {
+			t.Errorf("streaming budget: spent %d, want 900", st.Spent)
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		in   service.BudgetInput
+		want error
+	}{
+		{"duplicate", service.BudgetInput{UserID: userA, Category: &streaming, MonthlyLimit: 100}, budget.ErrBudgetExists},
+		{"service and category", service.BudgetInput{UserID: userA, ServiceName: &netflix, Category: &music, MonthlyLimit: 100}, budget.ErrScopeConflict},
+		{"invalid category", service.BudgetInput{UserID: userA, Category: &video, MonthlyLimit: 100}, budget.ErrInvalidCategory},
+	} {
+		if _, err := f.budgets.Create(ctx, tt.in); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
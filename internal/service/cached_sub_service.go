@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testingtask/internal/cache"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/readpref"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CacheTTL — время жизни записей по операциям, 0 отключает кэш операции.
+type CacheTTL struct {
+	Get time.Duration
+	Sum time.Duration
+}
+
+type OpStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+type CacheStats struct {
+	Get OpStats `json:"get"`
+	Sum OpStats `json:"sum"`
+}
+
+// CachedSubService — SubService с кэшем Get и Sum и счётчиками попаданий.
+type CachedSubService interface {
+	SubService
+	Stats() CacheStats
+}
+
+type opCounters struct {
+	hits, misses atomic.Int64
+}
+
+func (c *opCounters) stats() OpStats {
+	return OpStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+// cachedSubService кэширует Get по ID и Sum по нормализованному фильтру.
+//
+// Инвалидация построена на поколениях: ключ записи включает токен
+// поколения её области (пользователь, сервис или вся таблица), а запись
+// меняет токены затронутых областей — старые записи становятся
+// недостижимыми и вытесняются по TTL или LRU. Токены хранятся в том же
+// Cache, поэтому схема работает и с общим Redis. Вытесненный токен
+// создаётся заново со случайным значением, так что старые записи не
+// оживают.
+type cachedSubService struct {
+	next  SubService
+	cache cache.Cache
+	ttl   CacheTTL
+
+	get, sum opCounters
+}
+
+func NewCachedSubService(next SubService, c cache.Cache, ttl CacheTTL) CachedSubService {
+	return &cachedSubService{next: next, cache: c, ttl: ttl}
+}
+
+// Области инвалидации. genBulk меняется при массовом удалении, когда
+// затронутые подписки и сервисы неизвестны.
+const (
+	genAll  = "gen:all"
+	genBulk = "gen:bulk"
+)
+
+func genUser(id uuid.UUID) string             { return "gen:user:" + id.String() }
+func genService(name string) string           { return "gen:service:" + name }
+func getKey(id uuid.UUID, bulk string) string { return "sub:" + id.String() + ":" + bulk }
+
+func (s *cachedSubService) Stats() CacheStats {
+	return CacheStats{Get: s.get.stats(), Sum: s.sum.stats()}
+}
+
+func (s *cachedSubService) Get(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	// Чтение с primary нужно сразу после записи — кэш его не заменяет.
+	if s.ttl.Get == 0 || readpref.Primary(ctx) {
+		return s.next.Get(ctx, id)
+	}
+
+	key := getKey(id, s.generation(ctx, genBulk))
+
+	var cached cachedSub
+	if cache.GetJSON(ctx, s.cache, key, &cached) {
+		s.get.hits.Add(1)
+		return cached.toDomain(), nil
+	}
+	s.get.misses.Add(1)
+
+	sub, err := s.next.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.SetJSON(ctx, s.cache, key, fromDomainSub(sub), s.ttl.Get)
+	return sub, nil
+}
+
+func (s *cachedSubService) Sum(ctx context.Context, filters *domain.SubscriptionFilter) (*domain.SumResult, error) {
+	if s.ttl.Sum == 0 || readpref.Primary(ctx) {
+		return s.next.Sum(ctx, filters)
+	}
+
+	key := s.sumKey(ctx, filters)
+
+	var cached cachedSum
+	if cache.GetJSON(ctx, s.cache, key, &cached) {
+		s.sum.hits.Add(1)
+		return cached.toDomain(), nil
+	}
+	s.sum.misses.Add(1)
+
+	res, err := s.next.Sum(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.SetJSON(ctx, s.cache, key, fromDomainSum(res), s.ttl.Sum)
+	return res, nil
+}
+
+func (s *cachedSubService) List(ctx context.Context, labels domain.LabelFilter, paging *domain.PagingBase) ([]*domain.Subscription, int64, error) {
+	return s.next.List(ctx, labels, paging)
+}
+
+func (s *cachedSubService) Forecast(ctx context.Context, filters *domain.SubscriptionFilter, months int, group domain.ForecastGroup) (*domain.Forecast, error) {
+	return s.next.Forecast(ctx, filters, months, group)
+}
+
+func (s *cachedSubService) Create(ctx context.Context, sub *domain.Subscription) (uuid.UUID, error) {
+	id, err := s.next.Create(ctx, sub)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	s.invalidate(ctx, sub)
+	return id, nil
+}
+
+func (s *cachedSubService) Update(ctx context.Context, id uuid.UUID, sub *domain.Subscription) (uuid.UUID, error) {
+	// Старые пользователь и сервис тоже теряют актуальность их сумм, а
+	// участники общей подписки — долей.
+	old, split, _ := s.next.Members(readpref.WithPrimary(ctx), id)
+
+	res, err := s.next.Update(ctx, id, sub)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	s.invalidate(ctx, sub, old)
+	s.invalidateMembers(ctx, split)
+	return res, nil
+}
+
+func (s *cachedSubService) Delete(ctx context.Context, id uuid.UUID) error {
+	old, split, _ := s.next.Members(readpref.WithPrimary(ctx), id)
+
+	if err := s.next.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if old != nil {
+		s.invalidate(ctx, old)
+		s.invalidateMembers(ctx, split)
+	}
+	return nil
+}
+
+func (s *cachedSubService) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	// Общие подписки, где пользователь участник, меняют доли остальных.
+	splits := s.userSplits(readpref.WithPrimary(ctx), userID)
+
+	n, err := s.next.DeleteByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if n > 0 || len(splits) > 0 {
+		s.bump(ctx, genUser(userID), genAll, genBulk)
+		s.invalidateMembers(ctx, splits...)
+	}
+	return n, nil
+}
+
+// Members не кэшируется: участников читают при их изменении.
+func (s *cachedSubService) Members(ctx context.Context, id uuid.UUID) (*domain.Subscription, *domain.Split, error) {
+	return s.next.Members(ctx, id)
+}
+
+func (s *cachedSubService) SetMembers(ctx context.Context, id uuid.UUID, rule domain.SplitRule, members []domain.Member) (*domain.Subscription, *domain.Split, error) {
+	_, old, _ := s.next.Members(readpref.WithPrimary(ctx), id)
+
+	sub, split, err := s.next.SetMembers(ctx, id, rule, members)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.invalidateMembers(ctx, old, split)
+	return sub, split, nil
+}
+
+func (s *cachedSubService) RemoveMember(ctx context.Context, id, userID uuid.UUID) (*domain.Subscription, *domain.Split, error) {
+	_, old, _ := s.next.Members(readpref.WithPrimary(ctx), id)
+
+	sub, split, err := s.next.RemoveMember(ctx, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.invalidateMembers(ctx, old, split)
+	return sub, split, nil
+}
+
+func (s *cachedSubService) Unshare(ctx context.Context, id uuid.UUID) error {
+	_, old, _ := s.next.Members(readpref.WithPrimary(ctx), id)
+
+	if err := s.next.Unshare(ctx, id); err != nil {
+		return err
+	}
+
+	s.invalidateMembers(ctx, old)
+	return nil
+}
+
+// userSplits возвращает разделения общих подписок, где userID участник.
+func (s *cachedSubService) userSplits(ctx context.Context, userID uuid.UUID) []*domain.Split {
+	res, err := s.next.Sum(ctx, &domain.SubscriptionFilter{UserID: &userID, Limit: -1})
+	if err != nil {
+		return nil
+	}
+
+	var splits []*domain.Split
+	for _, sub := range res.Rows {
+		if sub.Share() == nil {
+			continue
+		}
+		if _, split, err := s.next.Members(ctx, sub.ID()); err == nil && split != nil {
+			splits = append(splits, split)
+		}
+	}
+	return splits
+}
+
+// invalidateMembers сбрасывает суммы участников разделений: их доли
+// зависят от цены и состава участников. Суммы без фильтра по
+// пользователю от долей не зависят.
+func (s *cachedSubService) invalidateMembers(ctx context.Context, splits ...*domain.Split) {
+	var keys []string
+	for _, split := range splits {
+		if split == nil {
+			continue
+		}
+		for _, id := range split.UserIDs() {
+			keys = append(keys, genUser(id))
+		}
+	}
+	s.bump(ctx, keys...)
+}
+
+// ExpireEnded только записывает события, данные подписок не меняются.
+func (s *cachedSubService) ExpireEnded(ctx context.Context, now time.Time) (int, error) {
+	return s.next.ExpireEnded(ctx, now)
+}
+
+// invalidate сбрасывает записи, на которые влияют подписки subs: Get по
+// их ID и Sum с фильтрами по их пользователям, сервисам или без фильтра.
+func (s *cachedSubService) invalidate(ctx context.Context, subs ...*domain.Subscription) {
+	keys := []string{genAll}
+	bulk := s.generation(ctx, genBulk)
+
+	for _, sub := range subs {
+		if sub == nil {
+			continue
+		}
+		keys = append(keys, genUser(sub.UserID()), genService(sub.ServiceName()))
+		s.cache.Delete(ctx, getKey(sub.ID(), bulk))
+	}
+
+	s.bump(ctx, keys...)
+}
+
+func (s *cachedSubService) bump(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		s.cache.Set(ctx, key, []byte(uuid.NewString()), 0)
+	}
+}
+
+// generation возвращает текущий токен поколения, создавая его при
+// отсутствии.
+func (s *cachedSubService) generation(ctx context.Context, key string) string {
+	if tok, ok := s.cache.Get(ctx, key); ok {
+		return string(tok)
+	}
+
+	tok := uuid.NewString()
+	s.cache.Set(ctx, key, []byte(tok), 0)
+	return tok
+}
+
+// sumKey строит ключ из нормализованного фильтра и поколения самой узкой
+// области, которую меняет любая подходящая под фильтр запись: пользователя,
+// иначе сервиса (плюс массовых удалений — их сервисы неизвестны), иначе
+// всей таблицы.
+func (s *cachedSubService) sumKey(ctx context.Context, f *domain.SubscriptionFilter) string {
+	var gen string
+	switch {
+	case f.UserID != nil:
+		gen = s.generation(ctx, genUser(*f.UserID))
+	case f.ServiceName != nil:
+		gen = s.generation(ctx, genService(*f.ServiceName)) + "/" + s.generation(ctx, genBulk)
+	default:
+		gen = s.generation(ctx, genAll)
+	}
+
+	limit, offset := f.Limit, f.Offset
+	if limit < 0 {
+		limit = -1
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return fmt.Sprintf("sum:%s:user=%s:service=%s:start=%s:end=%s:labels=%s:limit=%d:offset=%d",
+		gen, optUUID(f.UserID), optString(f.ServiceName),
+		optMonth(f.StartDate), optMonth(f.EndDate), optLabels(f.Labels), limit, offset)
+}
+
+// optLabels кодирует фильтр по меткам; NewLabelFilter сортирует условия,
+// поэтому одинаковые фильтры дают один ключ.
+func optLabels(f domain.LabelFilter) string {
+	if f.Empty() {
+		return "-"
+	}
+	b, _ := json.Marshal(f)
+	return string(b)
+}
+
+func optUUID(id *uuid.UUID) string {
+	if id == nil {
+		return "-"
+	}
+	return id.String()
+}
+
+func optString(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return strconv.Quote(*s)
+}
+
+func optMonth(d *domain.SubDate) string {
+	if d == nil {
+		return "-"
+	}
+	return d.Format("2006-01")
+}
+
+// cachedSub — сериализуемая копия подписки: у доменной сущности поля
+// закрыты.
+type cachedSub struct {
+	ID          uuid.UUID      `json:"id"`
+	ServiceName string         `json:"service_name"`
+	Price       int            `json:"price"`
+	UserID      uuid.UUID      `json:"user_id"`
+	StartDate   time.Time      `json:"start_date"`
+	EndDate     *time.Time     `json:"end_date,omitempty"`
+	ServiceID   *uuid.UUID     `json:"service_id,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	Share       *int           `json:"share,omitempty"`
+}
+
+func fromDomainSub(sub *domain.Subscription) cachedSub {
+	return cachedSub{
+		ID:          sub.ID(),
+		ServiceName: sub.ServiceName(),
+		Price:       sub.Price(),
+		UserID:      sub.UserID(),
+		StartDate:   sub.StartDate(),
+		EndDate:     sub.EndDate(),
+		ServiceID:   sub.ServiceID(),
+		Tags:        sub.Tags(),
+		Metadata:    sub.Metadata(),
+		Share:       sub.Share(),
+	}
+}
+
+func (c cachedSub) toDomain() *domain.Subscription {
+	var end *domain.SubDate
+	if c.EndDate != nil {
+		end = domain.NewSubDateFromTime(*c.EndDate)
+	}
+	sub := domain.RestoreSubscription(c.ID, c.ServiceName, domain.Price(c.Price), c.UserID,
+		*domain.NewSubDateFromTime(c.StartDate), end)
+	sub.LinkService(c.ServiceID, "")
+	sub.RestoreLabels(c.Tags, c.Metadata)
+	sub.RestoreShare(c.Share)
+	return sub
+}
+
+type cachedSum struct {
+	Rows       []cachedSub `json:"rows"`
+	TotalSum   int         `json:"total_sum"`
+	TotalCount int         `json:"total_count"`
+}
+
+func fromDomainSum(res *domain.SumResult) cachedSum {
+	rows := make([]cachedSub, 0, len(res.Rows))
+	for _, sub := range res.Rows {
+		rows = append(rows, fromDomainSub(sub))
+	}
+	return cachedSum{Rows: rows, TotalSum: res.TotalSum, TotalCount: res.TotalCount}
+}
+
+func (c cachedSum) toDomain() *domain.SumResult {
+	rows := make([]*domain.Subscription, 0, len(c.Rows))
+	for _, sub := range c.Rows {
+		rows = append(rows, sub.toDomain())
+	}
+	return domain.NewSumResult(rows, c.TotalSum, c.TotalCount)
+}
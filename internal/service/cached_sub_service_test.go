@@ -0,0 +1,157 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"testingtask/internal/cache"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/repository"
+	"testingtask/internal/service"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	userA = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	userB = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	start = domain.NewSubDateFromTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+)
+
+func newCached(t *testing.T, size int) service.CachedSubService {
+	t.Helper()
+	svc := service.NewSubService(repository.NewMemorySubRepository(), repository.NewNoOutboxRepository(), repository.NewNoTxManager(), sql.LevelDefault)
+	return service.NewCachedSubService(svc, cache.NewLRU(size), service.CacheTTL{Get: time.Minute, Sum: time.Minute})
+}
+
+func mustCreate(t *testing.T, svc service.SubService, user uuid.UUID, name string, price int) *domain.Subscription {
+	t.Helper()
+	sub := domain.RestoreSubscription(uuid.New(), name, domain.Price(price), user, *start, nil)
+	if _, err := svc.Create(context.Background(), sub); err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+func mustSum(t *testing.T, svc service.SubService, f domain.SubscriptionFilter) int {
+	t.Helper()
+	f.Limit = 10
+	res, err := svc.Sum(context.Background(), &f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.TotalSum
+}
+
+func TestCachedGet(t *testing.T) {
+	ctx := context.Background()
+	svc := newCached(t, 100)
+	sub := mustCreate(t, svc, userA, "Netflix", 400)
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Get(ctx, sub.ID()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := svc.Stats().Get; got.Hits != 1 || got.Misses != 1 {
+		t.Fatalf("stats: %+v, want 1 hit and 1 miss", got)
+	}
+
+	upd := domain.RestoreSubscription(sub.ID(), "Netflix", 500, userA, *start, nil)
+	if _, err := svc.Update(ctx, sub.ID(), upd); err != nil {
+		t.Fatal(err)
+	}
+	got, err := svc.Get(ctx, sub.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Price() != 500 {
+		t.Fatalf("price after update: got %d, want 500", got.Price())
+	}
+
+	if err := svc.Delete(ctx, sub.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Get(ctx, sub.ID()); err == nil {
+		t.Fatal("Get after delete returned the cached subscription")
+	}
+}
+
+func TestCachedSumInvalidation(t *testing.T) {
+	ctx := context.Background()
+	svc := newCached(t, 100)
+	netflix, spotify := "Netflix", "Spotify"
+
+	mustCreate(t, svc, userA, netflix, 400)
+	mustCreate(t, svc, userB, spotify, 200)
+
+	filters := map[string]domain.SubscriptionFilter{
+		"all":     {},
+		"user A":  {UserID: &userA},
+		"user B":  {UserID: &userB},
+		"netflix": {ServiceName: &netflix},
+		"spotify": {ServiceName: &spotify},
+	}
+	sums := func() map[string]int {
+		res := make(map[string]int, len(filters))
+		for name, f := range filters {
+			res[name] = mustSum(t, svc, f)
+		}
+		return res
+	}
+	check := func(step string, want map[string]int) {
+		t.Helper()
+		for name, got := range sums() {
+			if got != want[name] {
+				t.Errorf("%s: sum %q = %d, want %d", step, name, got, want[name])
+			}
+		}
+	}
+
+	check("initial", map[string]int{"all": 600, "user A": 400, "user B": 200, "netflix": 400, "spotify": 200})
+	hits := svc.Stats().Sum.Hits
+	sums()
+	if got := svc.Stats().Sum.Hits - hits; got != int64(len(filters)) {
+		t.Fatalf("repeated Sum: %d hits, want %d", got, len(filters))
+	}
+
+	// Запись пользователя A не трогает суммы пользователя B.
+	hits = svc.Stats().Sum.Hits
+	mustCreate(t, svc, userA, netflix, 100)
+	mustSum(t, svc, filters["user B"])
+	if svc.Stats().Sum.Hits != hits+1 {
+		t.Error("Sum for an unrelated user was invalidated")
+	}
+	check("create", map[string]int{"all": 700, "user A": 500, "user B": 200, "netflix": 500, "spotify": 200})
+
+	if _, err := svc.DeleteByUser(ctx, userB); err != nil {
+		t.Fatal(err)
+	}
+	check("delete by user", map[string]int{"all": 500, "user A": 500, "user B": 0, "netflix": 500, "spotify": 0})
+}
+
+// TestCachedSumEviction проверяет, что вытесненный из LRU токен поколения
+// не возвращает к жизни устаревшие суммы.
+func TestCachedSumEviction(t *testing.T) {
+	ctx := context.Background()
+	svc := newCached(t, 4)
+
+	sub := mustCreate(t, svc, userA, "Netflix", 400)
+	mustSum(t, svc, domain.SubscriptionFilter{UserID: &userA})
+
+	upd := domain.RestoreSubscription(sub.ID(), "Netflix", 900, userA, *start, nil)
+	if _, err := svc.Update(ctx, sub.ID(), upd); err != nil {
+		t.Fatal(err)
+	}
+	// Вытесняем всё, включая токены поколений.
+	for i := 0; i < 4; i++ {
+		if _, err := svc.Get(ctx, mustCreate(t, svc, userB, "Spotify", 100).ID()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := mustSum(t, svc, domain.SubscriptionFilter{UserID: &userA}); got != 900 {
+		t.Fatalf("sum after eviction: got %d, want 900", got)
+	}
+}
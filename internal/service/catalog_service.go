@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testingtask/internal/domain/catalog"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository"
+	logger "testingtask/pkg"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CatalogInput — сервис каталога из запроса.
+type CatalogInput struct {
+	Name         string
+	Aliases      []string
+	Category     *string
+	DefaultPrice *int
+	Currency     string
+	LogoURL      *string
+}
+
+type CatalogService interface {
+	Create(ctx context.Context, in CatalogInput) (*catalog.Service, error)
+	Get(ctx context.Context, id uuid.UUID) (*catalog.Service, error)
+	List(ctx context.Context, category *string, paging *domain.PagingBase) ([]*catalog.Service, int64, error)
+	// Update заменяет поля и синонимы сервиса. Новое имя переносится в
+	// service_name привязанных подписок.
+	Update(ctx context.Context, id uuid.UUID, in CatalogInput) (*catalog.Service, error)
+	// Delete удаляет сервис и отвязывает его подписки, их service_name
+	// остаётся прежним.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Resolve возвращает сервис, к которому сводится название name.
+	Resolve(ctx context.Context, name string) (*catalog.Service, error)
+}
+
+type catalogService struct {
+	repo    repository.CatalogRepository
+	subRepo repository.SubRepository
+	// subs меняет привязанные подписки: через него проходят события,
+	// бюджеты и инвалидация кэша.
+	subs SubService
+	tx   repository.TxManager
+}
+
+func NewCatalogService(r repository.CatalogRepository, subRepo repository.SubRepository, subs SubService, tx repository.TxManager) CatalogService {
+	return &catalogService{repo: r, subRepo: subRepo, subs: subs, tx: tx}
+}
+
+// checkKeys проверяет, что имя и синонимы s не сводятся к другому сервису.
+// Уникальный ключ в таблице тоже это запрещает, но его ошибка неотличима
+// от прочих нарушений ограничений.
+func (s *catalogService) checkKeys(ctx context.Context, svc *catalog.Service) error {
+	for key, alias := range svc.Keys() {
+		other, err := s.repo.Resolve(ctx, key)
+		if errors.Is(err, myerrors.ErrServiceNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if other.ID() != svc.ID() {
+			return fmt.Errorf("%w: %q matches %s", catalog.ErrAliasTaken, alias, other.Name())
+		}
+	}
+	return nil
+}
+
+func (s *catalogService) Create(ctx context.Context, in CatalogInput) (*catalog.Service, error) {
+	ctx, span := startSpan(ctx, "CatalogService.Create", attribute.String("service.name", in.Name))
+	defer span.End()
+
+	svc, err := catalog.NewService(uuid.Nil, in.Name, in.Aliases, in.Category, in.DefaultPrice, in.Currency, in.LogoURL)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	err = s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if err := s.checkKeys(ctx, svc); err != nil {
+			return err
+		}
+		return s.repo.Create(ctx, svc)
+	})
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: catalog service created", map[string]interface{}{
+		"id":       svc.ID(),
+		"name":     svc.Name(),
+		"aliases":  svc.Aliases(),
+		"category": svc.Category(),
+	})
+	return svc, nil
+}
+
+func (s *catalogService) Get(ctx context.Context, id uuid.UUID) (*catalog.Service, error) {
+	ctx, span := startSpan(ctx, "CatalogService.Get", attribute.String("service.id", id.String()))
+	defer span.End()
+
+	svc, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return svc, nil
+}
+
+func (s *catalogService) List(ctx context.Context, category *string, paging *domain.PagingBase) ([]*catalog.Service, int64, error) {
+	ctx, span := startSpan(ctx, "CatalogService.List")
+	defer span.End()
+
+	res, total, err := s.repo.List(ctx, category, paging)
+	if err != nil {
+		return nil, 0, spanError(span, err)
+	}
+	return res, total, nil
+}
+
+func (s *catalogService) Update(ctx context.Context, id uuid.UUID, in CatalogInput) (*catalog.Service, error) {
+	ctx, span := startSpan(ctx, "CatalogService.Update", attribute.String("service.id", id.String()))
+	defer span.End()
+
+	svc, err := catalog.NewService(id, in.Name, in.Aliases, in.Category, in.DefaultPrice, in.Currency, in.LogoURL)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	renamed := 0
+	err = s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		old, err := s.repo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		svc = catalog.RestoreService(id, svc.Name(), svc.Aliases(), svc.Category(), svc.DefaultPrice(), svc.Currency(), svc.LogoURL(), old.CreatedAt())
+
+		if err := s.checkKeys(ctx, svc); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, svc); err != nil {
+			return err
+		}
+		if old.Name() == svc.Name() {
+			return nil
+		}
+
+		linked, err := s.subRepo.ListByService(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, sub := range linked {
+			sub.LinkService(&id, svc.Name())
+			if _, err := s.subs.Update(ctx, sub.ID(), sub); err != nil {
+				return err
+			}
+		}
+		renamed = len(linked)
+		return nil
+	})
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: catalog service updated", map[string]interface{}{
+		"id":                    id,
+		"name":                  svc.Name(),
+		"aliases":               svc.Aliases(),
+		"renamed_subscriptions": renamed,
+	})
+	return svc, nil
+}
+
+func (s *catalogService) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := startSpan(ctx, "CatalogService.Delete", attribute.String("service.id", id.String()))
+	defer span.End()
+
+	unlinked := 0
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		linked, err := s.subRepo.ListByService(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Сервис удаляется первым, иначе подписка снова свелась бы к нему
+		// по имени.
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		for _, sub := range linked {
+			sub.LinkService(nil, "")
+			if _, err := s.subs.Update(ctx, sub.ID(), sub); err != nil {
+				return err
+			}
+		}
+		unlinked = len(linked)
+		return nil
+	})
+	if err != nil {
+		return spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: catalog service deleted", map[string]interface{}{
+		"id":                     id,
+		"unlinked_subscriptions": unlinked,
+	})
+	return nil
+}
+
+func (s *catalogService) Resolve(ctx context.Context, name string) (*catalog.Service, error) {
+	ctx, span := startSpan(ctx, "CatalogService.Resolve", attribute.String("service.name", name))
+	defer span.End()
+
+	svc, err := s.repo.Resolve(ctx, name)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return svc, nil
+}
+
+// catalogSubService сводит service_name подписок и фильтров к каталогу:
+// подписка, название которой совпадает по ключу с именем или синонимом
+// сервиса, привязывается к нему и получает его каноническое имя, а Sum и
+// Forecast по любому написанию считают подписки сервиса. Названия вне
+// каталога остаются как есть.
+type catalogSubService struct {
+	SubService
+	catalog repository.CatalogRepository
+}
+
+// NewCatalogSubService оборачивает next. Должен стоять снаружи кэша:
+// кэш инвалидирует суммы по каноническому имени.
+func NewCatalogSubService(next SubService, c repository.CatalogRepository) SubService {
+	return &catalogSubService{SubService: next, catalog: c}
+}
+
+// link привязывает sub к сервису: явному service_id, который должен быть в
+// каталоге, иначе найденному по названию.
+func (s *catalogSubService) link(ctx context.Context, sub *domain.Subscription) error {
+	if id := sub.ServiceID(); id != nil {
+		svc, err := s.catalog.Get(ctx, *id)
+		if errors.Is(err, myerrors.ErrServiceNotFound) {
+			return catalog.ErrUnknownService
+		}
+		if err != nil {
+			return err
+		}
+		sub.LinkService(id, svc.Name())
+		return nil
+	}
+
+	svc, err := s.catalog.Resolve(ctx, sub.ServiceName())
+	if errors.Is(err, myerrors.ErrServiceNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	id := svc.ID()
+	sub.LinkService(&id, svc.Name())
+	return nil
+}
+
+// canonical возвращает фильтр с каноническим именем сервиса вместо
+// написания из запроса. Фильтр вызывающего не меняется.
+func (s *catalogSubService) canonical(ctx context.Context, filters *domain.SubscriptionFilter) (*domain.SubscriptionFilter, error) {
+	if filters.ServiceName == nil {
+		return filters, nil
+	}
+
+	svc, err := s.catalog.Resolve(ctx, *filters.ServiceName)
+	if errors.Is(err, myerrors.ErrServiceNotFound) {
+		return filters, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := *filters
+	name := svc.Name()
+	f.ServiceName = &name
+	return &f, nil
+}
+
+func (s *catalogSubService) Create(ctx context.Context, sub *domain.Subscription) (uuid.UUID, error) {
+	if err := s.link(ctx, sub); err != nil {
+		return uuid.Nil, err
+	}
+	return s.SubService.Create(ctx, sub)
+}
+
+func (s *catalogSubService) Update(ctx context.Context, id uuid.UUID, sub *domain.Subscription) (uuid.UUID, error) {
+	if err := s.link(ctx, sub); err != nil {
+		return uuid.Nil, err
+	}
+	return s.SubService.Update(ctx, id, sub)
+}
+
+func (s *catalogSubService) Sum(ctx context.Context, filters *domain.SubscriptionFilter) (*domain.SumResult, error) {
+	f, err := s.canonical(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	return s.SubService.Sum(ctx, f)
+}
+
+func (s *catalogSubService) Forecast(ctx context.Context, filters *domain.SubscriptionFilter, months int, group domain.ForecastGroup) (*domain.Forecast, error) {
+	f, err := s.canonical(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	return s.SubService.Forecast(ctx, f, months, group)
+}
@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testingtask/internal/domain/catalog"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/service"
+
+	"github.com/google/uuid"
+)
+
+func TestCatalogLinksOnCreate(t *testing.T) {
+	f := newHarness(t, withCatalog)
+	netflix := f.service(t, "Netflix", "NFLX")
+
+	linked := f.get(t, mustCreate(t, f.subs, userA, "нетфликс ", 400).ID())
+	if linked.ServiceID() == nil || *linked.ServiceID() != netflix.ID() || linked.ServiceName() != "Netflix" {
+		t.Fatalf("linked: service %v %q, want %s Netflix", linked.ServiceID(), linked.ServiceName(), netflix.ID())
+	}
+
+	free := f.get(t, mustCreate(t, f.subs, userA, "Spotify", 200).ID())
+	if free.ServiceID() != nil || free.ServiceName() != "Spotify" {
+		t.Fatalf("free: service %v %q, want unlinked Spotify", free.ServiceID(), free.ServiceName())
+	}
+}
+
+func TestCatalogExplicitServiceID(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withCatalog)
+	netflix := f.service(t, "Netflix")
+	id := netflix.ID()
+
+	// Явный service_id важнее названия.
+	sub := domain.RestoreSubscription(uuid.New(), "Кинопоиск", 400, userA, *start, nil)
+	sub.LinkService(&id, "")
+	if _, err := f.subs.Create(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.get(t, sub.ID()); got.ServiceName() != "Netflix" {
+		t.Fatalf("service name %q, want Netflix", got.ServiceName())
+	}
+
+	unknown := uuid.New()
+	sub = domain.RestoreSubscription(uuid.New(), "Netflix", 400, userA, *start, nil)
+	sub.LinkService(&unknown, "")
+	if _, err := f.subs.Create(ctx, sub); !errors.Is(err, catalog.ErrUnknownService) {
+		t.Fatalf("unknown service_id: got %v, want ErrUnknownService", err)
+	}
+}
+
+func TestCatalogSumByAlias(t *testing.T) {
+	f := newHarness(t, withCatalog)
+	f.service(t, "Netflix", "NFLX", "Нетфликс")
+
+	mustCreate(t, f.subs, userA, "netflix", 400)
+	mustCreate(t, f.subs, userA, "NFLX", 300)
+	mustCreate(t, f.subs, userA, "Spotify", 200)
+
+	for _, name := range []string{"Netflix", "Нетфликс", "n-f-l-x"} {
+		name := name
+		if got := mustSum(t, f.subs, domain.SubscriptionFilter{UserID: &userA, ServiceName: &name}); got != 700 {
+			t.Errorf("Sum(%q) = %d, want 700", name, got)
+		}
+	}
+	spotify := "spotify"
+	if got := mustSum(t, f.subs, domain.SubscriptionFilter{UserID: &userA, ServiceName: &spotify}); got != 0 {
+		t.Errorf("Sum(spotify) = %d, want 0: names outside the catalog match as is", got)
+	}
+}
+
+func TestCatalogRenameCascades(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withCatalog)
+	netflix := f.service(t, "Netflix")
+	sub := mustCreate(t, f.subs, userA, "netflix", 400)
+
+	upd, err := f.catalog.Update(ctx, netflix.ID(), service.CatalogInput{Name: "Netflix Premium", Aliases: []string{"Netflix"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !upd.CreatedAt().Equal(netflix.CreatedAt()) {
+		t.Fatalf("created at changed: %v, want %v", upd.CreatedAt(), netflix.CreatedAt())
+	}
+	if got := f.get(t, sub.ID()); got.ServiceName() != "Netflix Premium" || *got.ServiceID() != netflix.ID() {
+		t.Fatalf("after rename: service %v %q, want Netflix Premium", got.ServiceID(), got.ServiceName())
+	}
+
+	if _, err := f.catalog.Update(ctx, uuid.New(), service.CatalogInput{Name: "Spotify"}); err == nil {
+		t.Fatal("update of a missing service succeeded")
+	}
+}
+
+func TestCatalogDeleteUnlinks(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withCatalog)
+	netflix := f.service(t, "Netflix", "NFLX")
+	sub := mustCreate(t, f.subs, userA, "nflx", 400)
+
+	if err := f.catalog.Delete(ctx, netflix.ID()); err != nil {
+		t.Fatal(err)
+	}
+	got := f.get(t, sub.ID())
+	if got.ServiceID() != nil || got.ServiceName() != "Netflix" {
+		t.Fatalf("after delete: service %v %q, want unlinked Netflix", got.ServiceID(), got.ServiceName())
+	}
+	if list, err := f.subRepo.ListByService(ctx, netflix.ID()); err != nil || len(list) != 0 {
+		t.Fatalf("ListByService after delete = %d rows, %v", len(list), err)
+	}
+}
+
+func TestCatalogAliasTaken(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withCatalog)
+	f.service(t, "Netflix", "NFLX")
+	spotify := f.service(t, "Spotify")
+
+	if _, err := f.catalog.Create(ctx, service.CatalogInput{Name: "Netflix HD", Aliases: []string{"nflx"}}); !errors.Is(err, catalog.ErrAliasTaken) {
+		t.Fatalf("Create: got %v, want ErrAliasTaken", err)
+	}
+	if _, err := f.catalog.Update(ctx, spotify.ID(), service.CatalogInput{Name: "Spotify", Aliases: []string{"Net Flix"}}); !errors.Is(err, catalog.ErrAliasTaken) {
+		t.Fatalf("Update: got %v, want ErrAliasTaken", err)
+	}
+	// Свои ключи не конфликтуют сами с собой.
+	if _, err := f.catalog.Update(ctx, spotify.ID(), service.CatalogInput{Name: "spotify", Aliases: []string{"Spotify Music"}}); err != nil {
+		t.Fatalf("Update own keys: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"testingtask/internal/domain/budget"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/outbox"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы доменных событий подписки.
+const (
+	EventSubscriptionCreated = "SubscriptionCreated"
+	EventSubscriptionUpdated = "SubscriptionUpdated"
+	EventSubscriptionDeleted = "SubscriptionDeleted"
+	EventSubscriptionExpired = "SubscriptionExpired"
+	// EventBudgetExceeded — создание или изменение подписки вывело траты
+	// пользователя за лимит бюджета.
+	EventBudgetExceeded = "BudgetExceeded"
+)
+
+// expiredEventNamespace — пространство имён UUIDv5 для ID событий
+// SubscriptionExpired: повторная проверка того же месяца даёт тот же ID,
+// и outbox не записывает событие дважды.
+var expiredEventNamespace = uuid.MustParse("6f1c2a4e-8d3b-4f5a-9c7e-2b1d0e3f4a5b")
+
+// SubscriptionEventData — данные события: состояние подписки после
+// изменения (для SubscriptionDeleted — перед удалением). Даты в формате
+// MM-YYYY, как в API.
+type SubscriptionEventData struct {
+	ID          uuid.UUID      `json:"id"`
+	ServiceName string         `json:"service_name"`
+	Price       int            `json:"price"`
+	UserID      uuid.UUID      `json:"user_id"`
+	StartDate   string         `json:"start_date"`
+	EndDate     *string        `json:"end_date,omitempty"`
+	ServiceID   *uuid.UUID     `json:"service_id,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	// Members есть только в SubscriptionUpdated после изменения
+	// участников: кто сколько платит, владелец первым. У подписки, которая
+	// перестала быть общей, единственный участник — владелец.
+	Members []MemberEventData `json:"members,omitempty"`
+}
+
+// MemberEventData — участник подписки и его доля цены.
+type MemberEventData struct {
+	UserID uuid.UUID `json:"user_id"`
+	Share  int       `json:"share"`
+}
+
+func newSubEvent(typ string, sub *domain.Subscription, at time.Time) outbox.Event {
+	return newSubEventData(typ, sub, subEventData(sub), at)
+}
+
+// newMembersEvent — SubscriptionUpdated после изменения участников sub,
+// split nil — подписка больше не общая.
+func newMembersEvent(sub *domain.Subscription, split *domain.Split, at time.Time) outbox.Event {
+	data := subEventData(sub)
+	if split == nil {
+		data.Members = []MemberEventData{{UserID: sub.UserID(), Share: sub.Price()}}
+	} else {
+		for _, m := range split.Members {
+			data.Members = append(data.Members, MemberEventData{UserID: m.UserID, Share: m.Share})
+		}
+	}
+	return newSubEventData(EventSubscriptionUpdated, sub, data, at)
+}
+
+func subEventData(sub *domain.Subscription) SubscriptionEventData {
+	data := SubscriptionEventData{
+		ID:          sub.ID(),
+		ServiceName: sub.ServiceName(),
+		Price:       sub.Price(),
+		UserID:      sub.UserID(),
+		StartDate:   sub.StartDate().Format("01-2006"),
+		ServiceID:   sub.ServiceID(),
+		Tags:        sub.Tags(),
+		Metadata:    sub.Metadata(),
+	}
+	if end := sub.EndDate(); end != nil {
+		s := end.Format("01-2006")
+		data.EndDate = &s
+	}
+	return data
+}
+
+func newSubEventData(typ string, sub *domain.Subscription, data SubscriptionEventData, at time.Time) outbox.Event {
+	// Поля — строки и числа, Marshal не может завершиться ошибкой.
+	payload, _ := json.Marshal(data)
+
+	id := uuid.New()
+	if typ == EventSubscriptionExpired {
+		id = uuid.NewSHA1(expiredEventNamespace, []byte(sub.ID().String()+"/"+*data.EndDate))
+	}
+
+	return outbox.Event{
+		ID:          id,
+		Type:        typ,
+		AggregateID: sub.ID(),
+		Payload:     payload,
+		OccurredAt:  at.UTC(),
+	}
+}
+
+// BudgetExceededEventData — данные события BudgetExceeded: бюджет, месяц
+// (MM-YYYY) и траты в нём с учётом подписки, которая вывела их за лимит.
+type BudgetExceededEventData struct {
+	BudgetID       uuid.UUID `json:"budget_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	ServiceName    *string   `json:"service_name,omitempty"`
+	Category       *string   `json:"category,omitempty"`
+	Month          string    `json:"month"`
+	MonthlyLimit   int       `json:"monthly_limit"`
+	Spent          int       `json:"spent"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+}
+
+func newBudgetEvent(st budget.Status, sub *domain.Subscription, at time.Time) outbox.Event {
+	b := st.Budget
+	payload, _ := json.Marshal(BudgetExceededEventData{
+		BudgetID:       b.ID(),
+		UserID:         b.UserID(),
+		ServiceName:    b.ServiceName(),
+		Category:       b.Category(),
+		Month:          st.Month.Format("01-2006"),
+		MonthlyLimit:   b.MonthlyLimit(),
+		Spent:          st.Spent,
+		SubscriptionID: sub.ID(),
+	})
+
+	return outbox.Event{
+		ID:          uuid.New(),
+		Type:        EventBudgetExceeded,
+		AggregateID: b.ID(),
+		Payload:     payload,
+		OccurredAt:  at.UTC(),
+	}
+}
+
+// eventUserID возвращает пользователя, которому принадлежит событие:
+// данные всех событий содержат user_id верхнего уровня (для подписки —
+// владелец).
+func eventUserID(e outbox.Event) (uuid.UUID, error) {
+	var data struct {
+		UserID uuid.UUID `json:"user_id"`
+	}
+	if err := json.Unmarshal(e.Payload, &data); err != nil {
+		return uuid.Nil, fmt.Errorf("decode event user: %w", err)
+	}
+	return data.UserID, nil
+}
@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testingtask/internal/config"
+	"testingtask/internal/database"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/outbox"
+	"testingtask/internal/repository"
+	"testingtask/internal/service"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newWithOutbox(t *testing.T) (service.SubService, repository.OutboxRepository) {
+	t.Helper()
+	events := repository.NewMemoryOutboxRepository()
+	return service.NewSubService(repository.NewMemorySubRepository(), events, repository.NewNoTxManager(), sql.LevelDefault), events
+}
+
+func pending(t *testing.T, events outbox.Store) []outbox.Event {
+	t.Helper()
+	res, err := events.Pending(context.Background(), time.Now().Add(time.Hour), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func assertTypes(t *testing.T, events []outbox.Event, want ...string) {
+	t.Helper()
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %v", len(events), want)
+	}
+	for i, e := range events {
+		if e.Type != want[i] {
+			t.Fatalf("event %d: got %s, want %s", i, e.Type, want[i])
+		}
+	}
+}
+
+func TestSubServiceEvents(t *testing.T) {
+	ctx := context.Background()
+	svc, events := newWithOutbox(t)
+
+	sub := mustCreate(t, svc, userA, "Netflix", 400)
+	other := mustCreate(t, svc, userB, "Spotify", 200)
+
+	updated := domain.RestoreSubscription(sub.ID(), "Netflix", 500, userA, *start, nil)
+	if _, err := svc.Update(ctx, sub.ID(), updated); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Delete(ctx, sub.ID()); err != nil {
+		t.Fatal(err)
+	}
+	// Повторное удаление не ошибка, но события не даёт.
+	if err := svc.Delete(ctx, sub.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.DeleteByUser(ctx, userB); err != nil {
+		t.Fatal(err)
+	}
+
+	got := pending(t, events)
+	assertTypes(t, got,
+		service.EventSubscriptionCreated,
+		service.EventSubscriptionCreated,
+		service.EventSubscriptionUpdated,
+		service.EventSubscriptionDeleted,
+		service.EventSubscriptionDeleted,
+	)
+
+	var data service.SubscriptionEventData
+	if err := json.Unmarshal(got[2].Payload, &data); err != nil {
+		t.Fatal(err)
+	}
+	if got[2].AggregateID != sub.ID() || data.ID != sub.ID() || data.Price != 500 || data.StartDate != "01-2025" {
+		t.Fatalf("update event: aggregate %s, data %+v", got[2].AggregateID, data)
+	}
+	if got[4].AggregateID != other.ID() {
+		t.Fatalf("DeleteByUser event aggregate = %s, want %s", got[4].AggregateID, other.ID())
+	}
+}
+
+func TestExpireEnded(t *testing.T) {
+	ctx := context.Background()
+	svc, events := newWithOutbox(t)
+
+	september := domain.NewSubDateFromTime(time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC))
+	october := domain.NewSubDateFromTime(time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+	ended := domain.RestoreSubscription(uuid.New(), "Netflix", 400, userA, *start, september)
+	for _, sub := range []*domain.Subscription{
+		ended,
+		domain.RestoreSubscription(uuid.New(), "Spotify", 200, userA, *start, october),
+		domain.RestoreSubscription(uuid.New(), "Yandex", 300, userA, *start, nil),
+	} {
+		if _, err := svc.Create(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Date(2025, 10, 15, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		n, err := svc.ExpireEnded(ctx, now.Add(time.Duration(i)*time.Hour))
+		if err != nil || n != 1 {
+			t.Fatalf("ExpireEnded = %d, %v, want 1", n, err)
+		}
+	}
+
+	var expired []outbox.Event
+	for _, e := range pending(t, events) {
+		if e.Type == service.EventSubscriptionExpired {
+			expired = append(expired, e)
+		}
+	}
+	if len(expired) != 1 || expired[0].AggregateID != ended.ID() {
+		t.Fatalf("expired events = %+v, want one for %s", expired, ended.ID())
+	}
+}
+
+// TestSQLiteEventsRollback проверяет, что подписка и событие
+// сохраняются вместе: при ошибке записи изменение откатывается.
+func TestSQLiteEventsRollback(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := config.Default()
+	cfg.Database.URL = database.SQLiteScheme + filepath.Join(t.TempDir(), "subs.db")
+	db, err := database.InitDB(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = database.Close(db) })
+
+	m, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := repository.NewSubRepository(db)
+	events := repository.NewOutboxRepository(db)
+	svc := service.NewSubService(repo, events, repository.NewTxManager(db), sql.LevelDefault)
+
+	sub := mustCreate(t, svc, userA, "Netflix", 400)
+	if got := pending(t, events); len(got) != 1 {
+		t.Fatalf("after Create: %d events, want 1", len(got))
+	}
+
+	// Без таблицы outbox запись события падает, и удаление откатывается.
+	if err := db.Exec("DROP TABLE outbox_events").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Delete(ctx, sub.ID()); err == nil {
+		t.Fatal("Delete succeeded without outbox table")
+	}
+	if _, err := repo.Get(ctx, sub.ID()); errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatal("subscription deleted although its event was not recorded")
+	}
+}
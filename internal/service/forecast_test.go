@@ -0,0 +1,51 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSubServiceForecast(t *testing.T) {
+	ctx := context.Background()
+	svc := newCached(t, 16)
+
+	// Подписок больше, чем лимит Sum по умолчанию: прогноз учитывает все.
+	for i := 0; i < 12; i++ {
+		mustCreate(t, svc, userA, "Netflix", 100)
+	}
+	mustCreate(t, svc, userA, "Spotify", 50)
+	mustCreate(t, svc, userB, "Netflix", 1000)
+
+	// Подписка заканчивается в следующем месяце.
+	now := time.Now().UTC()
+	end := domain.NewSubDateFromTime(time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC))
+	if _, err := svc.Create(ctx, domain.RestoreSubscription(uuid.New(), "Kinopoisk", 300, userA, *start, end)); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := svc.Forecast(ctx, &domain.SubscriptionFilter{UserID: &userA}, 3, domain.GroupService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Months != 3 || !f.From.Equal(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("forecast from %v for %d months", f.From, f.Months)
+	}
+	want := []int{1550, 1550, 1250}
+	for i, p := range f.Total.Points {
+		if p.Amount != want[i] {
+			t.Fatalf("month %d: %d, want %d", i, p.Amount, want[i])
+		}
+	}
+	if len(f.Series) != 3 || f.Series[1].Key != "Netflix" || f.Series[1].Total != 3600 {
+		t.Fatalf("service series: %+v", f.Series)
+	}
+
+	if _, err := svc.Forecast(ctx, &domain.SubscriptionFilter{}, 0, domain.GroupNone); !errors.Is(err, domain.ErrInvalidForecastMonths) {
+		t.Fatalf("zero months: got %v, want ErrInvalidForecastMonths", err)
+	}
+}
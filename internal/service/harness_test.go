@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"testingtask/internal/domain/budget"
+	"testingtask/internal/domain/catalog"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/user"
+	"testingtask/internal/repository"
+	"testingtask/internal/service"
+
+	"github.com/google/uuid"
+)
+
+// harness — хранилища в памяти и сервисы над ними. subs собран из
+// NewSubService и декораторов, переданных newHarness, остальные сервисы
+// работают поверх этого subs, как в cmd/serve.go.
+type harness struct {
+	subRepo      repository.SubRepository
+	userRepo     repository.UserRepository
+	budgetRepo   repository.BudgetRepository
+	reminderRepo repository.ReminderRepository
+	hookRepo     repository.WebhookRepository
+	catalogRepo  repository.CatalogRepository
+	events       repository.OutboxRepository
+	tx           repository.TxManager
+
+	subs    service.SubService
+	users   service.UserService
+	budgets service.BudgetService
+	catalog service.CatalogService
+}
+
+// decorator оборачивает SubService хранилищами h.
+type decorator func(h *harness, next service.SubService) service.SubService
+
+func withBudgets(h *harness, next service.SubService) service.SubService {
+	return service.NewBudgetedSubService(next, h.subRepo, h.budgetRepo, h.catalogRepo, h.events, h.tx)
+}
+
+func withCatalog(h *harness, next service.SubService) service.SubService {
+	return service.NewCatalogSubService(next, h.catalogRepo)
+}
+
+func withUsers(h *harness, next service.SubService) service.SubService {
+	return service.NewUserSubService(next, h.userRepo, h.tx)
+}
+
+// newHarness применяет decorators по порядку: первый оборачивает
+// NewSubService. userA, userB и userC заведены заранее.
+func newHarness(t *testing.T, decorators ...decorator) *harness {
+	t.Helper()
+	h := &harness{
+		subRepo:      repository.NewMemorySubRepository(),
+		userRepo:     repository.NewMemoryUserRepository(),
+		budgetRepo:   repository.NewMemoryBudgetRepository(),
+		reminderRepo: repository.NewMemoryReminderRepository(),
+		hookRepo:     repository.NewMemoryWebhookRepository(),
+		catalogRepo:  repository.NewMemoryCatalogRepository(),
+		events:       repository.NewMemoryOutboxRepository(),
+		tx:           repository.NewNoTxManager(),
+	}
+	addUsers(t, h.userRepo, userA, userB, userC)
+
+	h.subs = service.NewSubService(h.subRepo, h.events, h.tx, sql.LevelDefault)
+	for _, d := range decorators {
+		h.subs = d(h, h.subs)
+	}
+	h.users = service.NewUserService(h.userRepo, h.subs, h.tx, h.hookRepo, h.budgetRepo, h.reminderRepo)
+	h.budgets = service.NewBudgetService(h.budgetRepo, h.subRepo, h.catalogRepo, h.userRepo, h.tx, sql.LevelDefault)
+	h.catalog = service.NewCatalogService(h.catalogRepo, h.subRepo, h.subs, h.tx)
+	return h
+}
+
+// addUsers заводит пользователей с заданными ID.
+func addUsers(t *testing.T, users repository.UserRepository, ids ...uuid.UUID) {
+	t.Helper()
+	for _, id := range ids {
+		u, err := user.NewUser(id, "user "+id.String()[:8], nil, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := users.Create(context.Background(), u); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// user заводит пользователя через UserService.
+func (h *harness) user(t *testing.T, name string, email *string) uuid.UUID {
+	t.Helper()
+	u, err := h.users.Create(context.Background(), service.UserInput{DisplayName: name, Email: email})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.ID()
+}
+
+func (h *harness) budget(t *testing.T, user uuid.UUID, serviceName *string, limit int, hard bool) *budget.Budget {
+	t.Helper()
+	b, err := h.budgets.Create(context.Background(), service.BudgetInput{
+		UserID:       user,
+		ServiceName:  serviceName,
+		MonthlyLimit: limit,
+		HardLimit:    hard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// service заводит сервис каталога.
+func (h *harness) service(t *testing.T, name string, aliases ...string) *catalog.Service {
+	t.Helper()
+	s, err := h.catalog.Create(context.Background(), service.CatalogInput{Name: name, Aliases: aliases})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// get читает подписку из хранилища в обход декораторов.
+func (h *harness) get(t *testing.T, id uuid.UUID) *domain.Subscription {
+	t.Helper()
+	sub, err := h.subRepo.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
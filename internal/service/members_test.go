@@ -0,0 +1,207 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"testingtask/internal/domain/budget"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/service"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var userC = uuid.MustParse("33333333-3333-3333-3333-333333333333")
+
+func TestSubServiceMembers(t *testing.T) {
+	ctx := context.Background()
+	svc := newCached(t, 100)
+	family := mustCreate(t, svc, userA, "Spotify", 900)
+
+	sums := func(step string, a, b, c int) {
+		t.Helper()
+		for user, want := range map[uuid.UUID]int{userA: a, userB: b, userC: c} {
+			if got := mustSum(t, svc, domain.SubscriptionFilter{UserID: &user}); got != want {
+				t.Errorf("%s: sum of %s = %d, want %d", step, user, got, want)
+			}
+		}
+	}
+	// Суммы попадают в кэш до изменения участников.
+	sums("initial", 900, 0, 0)
+
+	_, split, err := svc.SetMembers(ctx, family.ID(), domain.SplitEqual, []domain.Member{{UserID: userB}, {UserID: userC}})
+	if err != nil {
+		t.Fatalf("SetMembers: %v", err)
+	}
+	if want := []uuid.UUID{userA, userB, userC}; !reflect.DeepEqual(split.UserIDs(), want) {
+		t.Fatalf("members = %v, want %v", split.UserIDs(), want)
+	}
+	sums("shared", 300, 300, 300)
+
+	// Изменение цены пересчитывает доли, смена владельца запрещена.
+	upd := domain.RestoreSubscription(family.ID(), "Spotify", 1200, userA, *start, nil)
+	if _, err := svc.Update(ctx, family.ID(), upd); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	sums("repriced", 400, 400, 400)
+
+	upd = domain.RestoreSubscription(family.ID(), "Spotify", 1200, userB, *start, nil)
+	if _, err := svc.Update(ctx, family.ID(), upd); !errors.Is(err, domain.ErrSharedOwnerChange) {
+		t.Fatalf("Update owner: got %v, want ErrSharedOwnerChange", err)
+	}
+
+	if _, _, err := svc.RemoveMember(ctx, family.ID(), userA); !errors.Is(err, domain.ErrInvalidMember) {
+		t.Fatalf("RemoveMember(owner): got %v, want ErrInvalidMember", err)
+	}
+	if _, _, err := svc.RemoveMember(ctx, family.ID(), uuid.New()); !errors.Is(err, myerrors.ErrMemberNotFound) {
+		t.Fatalf("RemoveMember(stranger): got %v, want ErrMemberNotFound", err)
+	}
+
+	// Удаление участника-пользователя отдаёт его долю остальным.
+	if _, err := svc.DeleteByUser(ctx, userC); err != nil {
+		t.Fatalf("DeleteByUser: %v", err)
+	}
+	if _, split, err = svc.Members(ctx, family.ID()); err != nil {
+		t.Fatalf("Members: %v", err)
+	}
+	if want := []uuid.UUID{userA, userB}; !reflect.DeepEqual(split.UserIDs(), want) {
+		t.Fatalf("members after DeleteByUser = %v, want %v", split.UserIDs(), want)
+	}
+	sums("member deleted", 600, 600, 0)
+
+	if _, split, err = svc.RemoveMember(ctx, family.ID(), userB); err != nil || split != nil {
+		t.Fatalf("RemoveMember(last) = %+v, %v, want not shared", split, err)
+	}
+	sums("last member removed", 1200, 0, 0)
+
+	if err := svc.Unshare(ctx, uuid.New()); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("Unshare(missing): got %v, want ErrNotFound", err)
+	}
+}
+
+func TestBudgetSharedSubscription(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withBudgets)
+	family := mustCreate(t, f.subs, userA, "Spotify", 900)
+	if _, _, err := f.subs.SetMembers(ctx, family.ID(), domain.SplitEqual, []domain.Member{{UserID: userB}, {UserID: userC}}); err != nil {
+		t.Fatal(err)
+	}
+	f.budget(t, userA, nil, 500, true)
+	f.budget(t, userB, nil, 250, false)
+
+	for user, want := range map[uuid.UUID]int{userA: 300, userB: 300} {
+		report, err := f.budgets.Status(ctx, user, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Spent != want || len(report.Budgets) != 1 {
+			t.Fatalf("status of %s: spent %d, want %d", user, report.Spent, want)
+		}
+	}
+
+	// Владельцу бюджет считает его долю от новой цены.
+	upd := domain.RestoreSubscription(family.ID(), "Spotify", 1200, userA, *start, nil)
+	if _, err := f.subs.Update(ctx, family.ID(), upd); err != nil {
+		t.Fatalf("Update within share: %v", err)
+	}
+	upd = domain.RestoreSubscription(family.ID(), "Spotify", 1800, userA, *start, nil)
+	if _, err := f.subs.Update(ctx, family.ID(), upd); !errors.Is(err, budget.ErrBudgetExceeded) {
+		t.Fatalf("Update over owner's hard limit: got %v, want ErrBudgetExceeded", err)
+	}
+
+	// Фиксированная доля участника не умещается в новую цену: без долей
+	// бюджет не проверить, и изменение отклоняется.
+	fixed := mustCreate(t, f.subs, userB, "Okko", 400)
+	amount := 300
+	if _, _, err := f.subs.SetMembers(ctx, fixed.ID(), domain.SplitFixed, []domain.Member{{UserID: userC, Amount: &amount}}); err != nil {
+		t.Fatal(err)
+	}
+	upd = domain.RestoreSubscription(fixed.ID(), "Okko", 200, userB, *start, nil)
+	if _, err := f.subs.Update(ctx, fixed.ID(), upd); !errors.Is(err, domain.ErrSplitMismatch) {
+		t.Fatalf("Update below fixed shares: got %v, want ErrSplitMismatch", err)
+	}
+}
+
+func TestBudgetMemberChanges(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withBudgets)
+	family := mustCreate(t, f.subs, userA, "Spotify", 900)
+	f.budget(t, userA, nil, 800, true)
+	b := f.budget(t, userB, nil, 200, false)
+
+	// Доля нового участника выводит его траты за лимит.
+	if _, _, err := f.subs.SetMembers(ctx, family.ID(), domain.SplitEqual, []domain.Member{{UserID: userB}}); err != nil {
+		t.Fatalf("SetMembers: %v", err)
+	}
+	got := pending(t, f.events)
+	assertTypes(t, got, service.EventSubscriptionCreated, service.EventBudgetExceeded, service.EventSubscriptionUpdated)
+
+	var exceeded service.BudgetExceededEventData
+	if err := json.Unmarshal(got[1].Payload, &exceeded); err != nil {
+		t.Fatal(err)
+	}
+	if exceeded.BudgetID != b.ID() || exceeded.UserID != userB || exceeded.Spent != 450 {
+		t.Fatalf("BudgetExceeded data: %+v", exceeded)
+	}
+	var updated service.SubscriptionEventData
+	if err := json.Unmarshal(got[2].Payload, &updated); err != nil {
+		t.Fatal(err)
+	}
+	want := []service.MemberEventData{{UserID: userA, Share: 450}, {UserID: userB, Share: 450}}
+	if !reflect.DeepEqual(updated.Members, want) {
+		t.Fatalf("SubscriptionUpdated members = %+v, want %+v", updated.Members, want)
+	}
+
+	// Доля участника перешла бы владельцу и вывела его за hard_limit.
+	if _, _, err := f.subs.RemoveMember(ctx, family.ID(), userB); !errors.Is(err, budget.ErrBudgetExceeded) {
+		t.Fatalf("RemoveMember over owner's hard limit: got %v, want ErrBudgetExceeded", err)
+	}
+	if err := f.subs.Unshare(ctx, family.ID()); !errors.Is(err, budget.ErrBudgetExceeded) {
+		t.Fatalf("Unshare over owner's hard limit: got %v, want ErrBudgetExceeded", err)
+	}
+	if _, split, err := f.subs.Members(ctx, family.ID()); err != nil || len(split.Members) != 2 {
+		t.Fatalf("Members after rejected changes = %+v, %v, want 2 members", split, err)
+	}
+
+	// Доли уменьшаются: проверка пропускает, о превышенном бюджете B
+	// повторно не сообщается.
+	if _, _, err := f.subs.SetMembers(ctx, family.ID(), domain.SplitEqual, []domain.Member{{UserID: userB}, {UserID: userC}}); err != nil {
+		t.Fatalf("SetMembers with smaller shares: %v", err)
+	}
+	assertTypes(t, pending(t, f.events), service.EventSubscriptionCreated, service.EventBudgetExceeded,
+		service.EventSubscriptionUpdated, service.EventSubscriptionUpdated)
+}
+
+func TestDeleteByUserMembersEvent(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t)
+	family := mustCreate(t, f.subs, userA, "Spotify", 900)
+	if _, _, err := f.subs.SetMembers(ctx, family.ID(), domain.SplitEqual, []domain.Member{{UserID: userB}, {UserID: userC}}); err != nil {
+		t.Fatal(err)
+	}
+	own := mustCreate(t, f.subs, userC, "Netflix", 500)
+
+	// Владелец узнаёт о новом разделении, как при RemoveMember.
+	if _, err := f.subs.DeleteByUser(ctx, userC); err != nil {
+		t.Fatalf("DeleteByUser: %v", err)
+	}
+	got := pending(t, f.events)
+	assertTypes(t, got, service.EventSubscriptionCreated, service.EventSubscriptionUpdated, service.EventSubscriptionCreated,
+		service.EventSubscriptionUpdated, service.EventSubscriptionDeleted)
+
+	var updated service.SubscriptionEventData
+	if err := json.Unmarshal(got[3].Payload, &updated); err != nil {
+		t.Fatal(err)
+	}
+	want := []service.MemberEventData{{UserID: userA, Share: 450}, {UserID: userB, Share: 450}}
+	if updated.ID != family.ID() || updated.UserID != userA || !reflect.DeepEqual(updated.Members, want) {
+		t.Fatalf("SubscriptionUpdated = %+v, want members %+v", updated, want)
+	}
+	if got[4].AggregateID != own.ID() {
+		t.Fatalf("SubscriptionDeleted aggregate = %s, want %s", got[4].AggregateID, own.ID())
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testingtask/internal/domain/reminder"
+	domain "testingtask/internal/domain/subscription"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/notify"
+	"testingtask/internal/readpref"
+	"testingtask/internal/repository"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ReminderInput — настройки напоминаний из запроса.
+type ReminderInput struct {
+	Enabled    bool
+	DaysBefore int
+	Channel    string
+	Email      *string
+	WebhookURL *string
+}
+
+type ReminderConfig struct {
+	// DefaultDaysBefore — за сколько дней напоминать пользователю без
+	// сохранённых настроек. Таким пользователям напоминания пишутся в лог.
+	DefaultDaysBefore int
+	// Lease — сколько экземпляр владеет взятым напоминанием. Если он упал
+	// до отправки, после Lease напоминание отправит другой.
+	Lease time.Duration
+}
+
+type ReminderService interface {
+	// GetPreferences возвращает сохранённые настройки пользователя или
+	// настройки по умолчанию.
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*reminder.Preferences, error)
+	SavePreferences(ctx context.Context, userID uuid.UUID, in ReminderInput) (*reminder.Preferences, error)
+	// SendDue отправляет напоминания, срок которых наступил в now, и
+	// возвращает число отправленных.
+	SendDue(ctx context.Context, now time.Time) (int, error)
+}
+
+type reminderService struct {
+	subs      repository.SubRepository
+	repo      repository.ReminderRepository
+	users     repository.UserRepository
+	tx        repository.TxManager
+	notifiers map[string]notify.Notifier
+	cfg       ReminderConfig
+}
+
+// NewReminderService принимает notifiers по каналам (reminder.ChannelEmail
+// и т. д.). Канал без notifier'а нельзя выбрать в настройках.
+func NewReminderService(subs repository.SubRepository, r repository.ReminderRepository, users repository.UserRepository, tx repository.TxManager, notifiers map[string]notify.Notifier, cfg ReminderConfig) ReminderService {
+	return &reminderService{subs: subs, repo: r, users: users, tx: tx, notifiers: notifiers, cfg: cfg}
+}
+
+func (s *reminderService) defaults(userID uuid.UUID) *reminder.Preferences {
+	return reminder.RestorePreferences(userID, true, s.cfg.DefaultDaysBefore, reminder.ChannelLog, nil, nil)
+}
+
+func (s *reminderService) GetPreferences(ctx context.Context, userID uuid.UUID) (*reminder.Preferences, error) {
+	ctx, span := startSpan(ctx, "ReminderService.GetPreferences")
+	defer span.End()
+
+	p, err := s.repo.GetPreferences(ctx, userID)
+	if errors.Is(err, myerrors.ErrPreferencesNotFound) {
+		return s.defaults(userID), nil
+	}
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return p, nil
+}
+
+func (s *reminderService) SavePreferences(ctx context.Context, userID uuid.UUID, in ReminderInput) (*reminder.Preferences, error) {
+	ctx, span := startSpan(ctx, "ReminderService.SavePreferences")
+	defer span.End()
+
+	p, err := reminder.NewPreferences(userID, in.Enabled, in.DaysBefore, in.Channel, in.Email, in.WebhookURL)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	if s.notifiers[p.Channel()] == nil {
+		return nil, spanError(span, reminder.ErrChannelDisabled)
+	}
+
+	// Проверка пользователя и запись — в одной транзакции, чтобы он не
+	// удалился между ними.
+	err = s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if err := checkUsers(ctx, s.users, userID); err != nil {
+			return err
+		}
+		return s.repo.SavePreferences(ctx, p)
+	})
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return p, nil
+}
+
+func (s *reminderService) SendDue(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := startSpan(ctx, "ReminderService.SendDue")
+	defer span.End()
+
+	now = now.UTC()
+	// Напоминание может прийти не раньше чем за MaxDaysBefore дней, так
+	// что дальше этого горизонта подписки не интересны.
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	horizon := now.AddDate(0, 0, reminder.MaxDaysBefore)
+	prefs := make(map[uuid.UUID]*reminder.Preferences)
+
+	sent := 0
+	for month := first; !month.After(horizon); month = month.AddDate(0, 1, 0) {
+		subs, err := s.subs.ListEnded(readpref.WithPrimary(ctx), *domain.NewSubDateFromTime(month))
+		if err != nil {
+			logger.Error(ctx, "service: list ending subscriptions failed", err, map[string]interface{}{
+				"month": month.Format("01-2006"),
+			})
+			return sent, spanError(span, err)
+		}
+
+		for _, sub := range subs {
+			p, ok := prefs[sub.UserID()]
+			if !ok {
+				if p, err = s.GetPreferences(ctx, sub.UserID()); err != nil {
+					return sent, spanError(span, err)
+				}
+				prefs[sub.UserID()] = p
+			}
+
+			ok, err := s.remind(ctx, sub, p, now)
+			if err != nil {
+				return sent, spanError(span, err)
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+
+	span.SetAttributes(attribute.Int("reminders.sent", sent))
+	if sent > 0 {
+		logger.Info(ctx, "service: reminders sent", map[string]interface{}{
+			"sent": sent,
+		})
+	}
+	return sent, nil
+}
+
+// remind отправляет напоминание об окончании sub, если оно нужно и не
+// отправлено ранее. Ошибка канала не прерывает обход: напоминание
+// возвращается в очередь до следующего запуска.
+func (s *reminderService) remind(ctx context.Context, sub *domain.Subscription, p *reminder.Preferences, now time.Time) (bool, error) {
+	end := sub.EndDate()
+	if !p.Enabled() || end == nil {
+		return false, nil
+	}
+	endsAt := reminder.EndsAt(*end)
+	due, daysLeft := reminder.Due(now, endsAt, p.DaysBefore())
+	if !due {
+		return false, nil
+	}
+
+	notifier := s.notifiers[p.Channel()]
+	if notifier == nil {
+		// Канал выключили в конфиге после сохранения настроек.
+		logger.Warn(ctx, "service: reminder channel is not configured", map[string]interface{}{
+			"user_id": p.UserID(),
+			"channel": p.Channel(),
+		})
+		return false, nil
+	}
+
+	n := reminder.NewNotification(sub.UserID(), sub.ID(), reminder.KindExpiry, endsAt, p.Channel())
+	claimed, err := s.repo.Claim(ctx, n, now, s.cfg.Lease)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	if err := notifier.Notify(ctx, expiryMessage(sub, p, endsAt, daysLeft)); err != nil {
+		logger.Error(ctx, "service: reminder not delivered", err, map[string]interface{}{
+			"id":              n.ID,
+			"subscription_id": sub.ID(),
+			"channel":         p.Channel(),
+		})
+		return false, s.repo.Release(ctx, n.ID, now, err.Error())
+	}
+
+	return true, s.repo.MarkSent(ctx, n.ID, time.Now())
+}
+
+func expiryMessage(sub *domain.Subscription, p *reminder.Preferences, endsAt time.Time, daysLeft int) notify.Message {
+	endDate := ""
+	if s := sub.EndDateStr(); s != nil {
+		endDate = *s
+	}
+
+	return notify.Message{
+		To:      p.Recipient(),
+		Subject: fmt.Sprintf("Your %s subscription ends in %d day(s)", sub.ServiceName(), daysLeft),
+		Text: fmt.Sprintf("Your %s subscription (price %d per month) ends on %s. Renew it before then to keep access.",
+			sub.ServiceName(), sub.Price(), endsAt.Format(time.DateOnly)),
+		Data: map[string]interface{}{
+			"kind":            reminder.KindExpiry,
+			"user_id":         sub.UserID(),
+			"subscription_id": sub.ID(),
+			"service_name":    sub.ServiceName(),
+			"price":           sub.Price(),
+			"end_date":        endDate,
+			"ends_at":         endsAt,
+			"days_left":       daysLeft,
+		},
+	}
+}
@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"testingtask/internal/domain/reminder"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/user"
+	"testingtask/internal/notify"
+	"testingtask/internal/repository"
+	"testingtask/internal/repository/repotest"
+	"testingtask/internal/service"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeNotifier запоминает сообщения и, пока fail, возвращает ошибку.
+type fakeNotifier struct {
+	mu   sync.Mutex
+	fail bool
+	got  []notify.Message
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, m notify.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.fail {
+		return errors.New("channel is down")
+	}
+	n.got = append(n.got, m)
+	return nil
+}
+
+func monthOf(year int, m time.Month) *domain.SubDate {
+	return domain.NewSubDateFromTime(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC))
+}
+
+type reminderFixture struct {
+	subs      repository.SubRepository
+	reminders repository.ReminderRepository
+	users     repository.UserRepository
+	log       *fakeNotifier
+	hook      *fakeNotifier
+}
+
+func newReminderFixture() *reminderFixture {
+	return &reminderFixture{
+		subs:      repository.NewMemorySubRepository(),
+		reminders: repository.NewMemoryReminderRepository(),
+		users:     repository.NewMemoryUserRepository(),
+		log:       &fakeNotifier{},
+		hook:      &fakeNotifier{},
+	}
+}
+
+// service возвращает новый экземпляр сервиса над общими хранилищами —
+// как второй процесс или реплика.
+func (f *reminderFixture) service() service.ReminderService {
+	return service.NewReminderService(f.subs, f.reminders, f.users, repository.NewNoTxManager(), map[string]notify.Notifier{
+		reminder.ChannelLog:     f.log,
+		reminder.ChannelWebhook: f.hook,
+	}, service.ReminderConfig{DefaultDaysBefore: 3, Lease: time.Minute})
+}
+
+func (f *reminderFixture) create(t *testing.T, subs ...*domain.Subscription) {
+	t.Helper()
+	for _, s := range subs {
+		if err := f.subs.Create(context.Background(), s); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func sendDue(t *testing.T, s service.ReminderService, now time.Time, want int) {
+	t.Helper()
+	got, err := s.SendDue(context.Background(), now)
+	if err != nil {
+		t.Fatalf("SendDue: %v", err)
+	}
+	if got != want {
+		t.Fatalf("SendDue at %s sent %d, want %d", now.Format(time.DateTime), got, want)
+	}
+}
+
+func TestReminderSendDue(t *testing.T) {
+	ctx := context.Background()
+	f := newReminderFixture()
+	svc := f.service()
+
+	// До 1 ноября остаётся 2,5 дня.
+	now := time.Date(2026, 10, 29, 12, 0, 0, 0, time.UTC)
+
+	byDefault, disabled, byHook := uuid.New(), uuid.New(), uuid.New()
+	addUsers(t, f.users, byDefault, disabled, byHook)
+	endsOctober := repotest.Sub(byDefault, "Netflix", 400, monthOf(2026, 1), monthOf(2026, 10))
+	f.create(t,
+		endsOctober,
+		// Конец через 32,5 дня — позже DefaultDaysBefore.
+		repotest.Sub(byDefault, "Spotify", 200, monthOf(2026, 1), monthOf(2026, 11)),
+		repotest.Sub(byDefault, "Yandex Plus", 300, monthOf(2026, 1), nil),
+		repotest.Sub(disabled, "Netflix", 400, monthOf(2026, 1), monthOf(2026, 10)),
+		repotest.Sub(byHook, "Kinopoisk", 500, monthOf(2026, 1), monthOf(2026, 10)),
+	)
+
+	if _, err := svc.SavePreferences(ctx, disabled, service.ReminderInput{Enabled: false, DaysBefore: 3, Channel: reminder.ChannelLog}); err != nil {
+		t.Fatal(err)
+	}
+	hookURL := "https://example.com/remind"
+	if _, err := svc.SavePreferences(ctx, byHook, service.ReminderInput{Enabled: true, DaysBefore: 7, Channel: reminder.ChannelWebhook, WebhookURL: &hookURL}); err != nil {
+		t.Fatal(err)
+	}
+
+	sendDue(t, svc, now, 2)
+
+	if len(f.log.got) != 1 || f.log.got[0].Data["subscription_id"] != endsOctober.ID() || f.log.got[0].Data["days_left"] != 3 {
+		t.Fatalf("log notifier got %+v, want one reminder for %s", f.log.got, endsOctober.ID())
+	}
+	if len(f.hook.got) != 1 || f.hook.got[0].To != hookURL {
+		t.Fatalf("webhook notifier got %+v, want one reminder to %s", f.hook.got, hookURL)
+	}
+
+	// Ни повторный запуск, ни другой экземпляр не отправляют то же самое.
+	sendDue(t, svc, now.Add(time.Hour), 0)
+	sendDue(t, f.service(), now.Add(2*time.Hour), 0)
+}
+
+func TestReminderSendDueRetriesFailed(t *testing.T) {
+	f := newReminderFixture()
+	svc := f.service()
+	now := time.Date(2026, 10, 30, 9, 0, 0, 0, time.UTC)
+
+	f.create(t, repotest.Sub(uuid.New(), "Netflix", 400, monthOf(2026, 1), monthOf(2026, 10)))
+
+	f.log.fail = true
+	sendDue(t, svc, now, 0)
+
+	f.log.fail = false
+	sendDue(t, svc, now.Add(15*time.Minute), 1)
+	sendDue(t, svc, now.Add(30*time.Minute), 0)
+
+	if len(f.log.got) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(f.log.got))
+	}
+}
+
+func TestReminderPreferences(t *testing.T) {
+	ctx := context.Background()
+	f := newReminderFixture()
+	svc := f.service()
+	userID := uuid.New()
+
+	p, err := svc.GetPreferences(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetPreferences: %v", err)
+	}
+	if !p.Enabled() || p.DaysBefore() != 3 || p.Channel() != reminder.ChannelLog {
+		t.Fatalf("defaults = %+v", p)
+	}
+
+	email := "user@example.com"
+	_, err = svc.SavePreferences(ctx, userID, service.ReminderInput{Enabled: true, DaysBefore: 3, Channel: reminder.ChannelEmail, Email: &email})
+	if !errors.Is(err, reminder.ErrChannelDisabled) {
+		t.Fatalf("SavePreferences(email without SMTP): got %v, want ErrChannelDisabled", err)
+	}
+
+	_, err = svc.SavePreferences(ctx, userID, service.ReminderInput{Enabled: true, DaysBefore: 0, Channel: reminder.ChannelLog})
+	if !errors.Is(err, reminder.ErrInvalidDaysBefore) {
+		t.Fatalf("SavePreferences(days_before 0): got %v, want ErrInvalidDaysBefore", err)
+	}
+
+	in := service.ReminderInput{Enabled: true, DaysBefore: 5, Channel: reminder.ChannelLog}
+	if _, err := svc.SavePreferences(ctx, userID, in); !errors.Is(err, user.ErrUnknownUser) {
+		t.Fatalf("SavePreferences(unknown user): got %v, want ErrUnknownUser", err)
+	}
+	addUsers(t, f.users, userID)
+	if _, err := svc.SavePreferences(ctx, userID, in); err != nil {
+		t.Fatalf("SavePreferences: %v", err)
+	}
+}
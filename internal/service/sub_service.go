@@ -2,33 +2,77 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	domain "testingtask/internal/domain/subscription"
 	myerrors "testingtask/internal/errors"
+	"testingtask/internal/outbox"
+	"testingtask/internal/readpref"
 	"testingtask/internal/repository"
 	logger "testingtask/pkg"
+	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type SubService interface {
 	Create(ctx context.Context, sub *domain.Subscription) (uuid.UUID, error)
 	Get(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
-	List(ctx context.Context, paging *domain.PagingBase) ([]*domain.Subscription, int64, error)
+	// List возвращает страницу подписок, подходящих под фильтр по тегам и
+	// metadata, и их общее число.
+	List(ctx context.Context, labels domain.LabelFilter, paging *domain.PagingBase) ([]*domain.Subscription, int64, error)
 	Sum(ctx context.Context, filters *domain.SubscriptionFilter) (*domain.SumResult, error)
 	Update(ctx context.Context, id uuid.UUID, sub *domain.Subscription) (uuid.UUID, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	// ExpireEnded записывает SubscriptionExpired для подписок, закончившихся
+	// в месяце перед now, и возвращает их число. Повторный вызов в том же
+	// месяце событий не дублирует.
+	ExpireEnded(ctx context.Context, now time.Time) (int, error)
+	// Forecast прогнозирует траты подписок, подходящих под UserID и
+	// ServiceName фильтра, на months месяцев начиная с текущего.
+	Forecast(ctx context.Context, filters *domain.SubscriptionFilter, months int, group domain.ForecastGroup) (*domain.Forecast, error)
+	// Members возвращает подписку и разделение её цены, nil — подписка не
+	// общая.
+	Members(ctx context.Context, id uuid.UUID) (*domain.Subscription, *domain.Split, error)
+	// SetMembers делит цену подписки между участниками по правилу rule,
+	// заменяя прежних. Владелец участвует всегда, см. domain.NewSplit.
+	SetMembers(ctx context.Context, id uuid.UUID, rule domain.SplitRule, members []domain.Member) (*domain.Subscription, *domain.Split, error)
+	// RemoveMember исключает участника, его доля переходит владельцу.
+	// Без других участников подписка перестаёт быть общей.
+	RemoveMember(ctx context.Context, id, userID uuid.UUID) (*domain.Subscription, *domain.Split, error)
+	// Unshare делает подписку не общей: цену снова платит владелец.
+	Unshare(ctx context.Context, id uuid.UUID) error
 }
 
 type subService struct {
 	repo repository.SubRepository
+	// events — outbox: события пишутся в транзакции изменения.
+	events repository.OutboxRepository
+	tx     repository.TxManager
+	// reportTx — параметры транзакций List и Sum: страница и итоги должны
+	// читаться из одного снимка.
+	reportTx repository.TxOptions
 }
 
-func NewSubService(r repository.SubRepository) SubService {
-	return &subService{repo: r}
+func NewSubService(r repository.SubRepository, events repository.OutboxRepository, tx repository.TxManager, reportIsolation sql.IsolationLevel) SubService {
+	return &subService{
+		repo:     r,
+		events:   events,
+		tx:       tx,
+		reportTx: repository.TxOptions{Isolation: reportIsolation, ReadOnly: true},
+	}
 }
 
 func (s *subService) Create(ctx context.Context, sub *domain.Subscription) (uuid.UUID, error) {
+	ctx, span := startSpan(ctx, "SubService.Create",
+		attribute.String("subscription.id", sub.ID().String()),
+		attribute.String("user.id", sub.UserID().String()),
+	)
+	defer span.End()
+
 	logger.Info(ctx, "service: creating subscription", map[string]interface{}{
 		"user_id":      sub.UserID(),
 		"service_name": sub.ServiceName(),
@@ -36,9 +80,15 @@ func (s *subService) Create(ctx context.Context, sub *domain.Subscription) (uuid
 		"start_date":   sub.StartDate(),
 		"end_date":     sub.EndDate(),
 	})
-	if err := s.repo.Create(ctx, sub); err != nil {
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, sub); err != nil {
+			return err
+		}
+		return s.events.Add(ctx, newSubEvent(EventSubscriptionCreated, sub, time.Now()))
+	})
+	if err != nil {
 		logger.Error(ctx, "service: create failed", err, map[string]interface{}{"user_id": sub.UserID()})
-		return uuid.Nil, err
+		return uuid.Nil, spanError(span, err)
 	}
 
 	logger.Info(ctx, "service: subscription created", map[string]interface{}{
@@ -49,6 +99,9 @@ func (s *subService) Create(ctx context.Context, sub *domain.Subscription) (uuid
 }
 
 func (s *subService) Get(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
+	ctx, span := startSpan(ctx, "SubService.Get", attribute.String("subscription.id", id.String()))
+	defer span.End()
+
 	logger.Debug(ctx, "service: getting subscription by ID", map[string]interface{}{
 		"id": id,
 	})
@@ -56,96 +109,165 @@ func (s *subService) Get(ctx context.Context, id uuid.UUID) (*domain.Subscriptio
 	sub, err := s.repo.Get(ctx, id)
 	if err != nil {
 		logger.Error(ctx, "service: get failed", err, map[string]interface{}{"id": id})
-		return nil, err
+		return nil, spanError(span, err)
 	}
 
 	return sub, nil
 }
 
-func (s *subService) List(ctx context.Context, paging *domain.PagingBase) ([]*domain.Subscription, int64, error) {
+func (s *subService) List(ctx context.Context, labels domain.LabelFilter, paging *domain.PagingBase) ([]*domain.Subscription, int64, error) {
+	ctx, span := startSpan(ctx, "SubService.List",
+		attribute.Int("paging.limit", paging.Limit),
+		attribute.Int("paging.offset", paging.Offset),
+	)
+	defer span.End()
+
 	logger.Debug(ctx, "service: getting list subscirptions", map[string]interface{}{
+		"labels": labels,
 		"paging": paging,
 	})
-	subs, err := s.repo.List(ctx, paging)
-
+	var (
+		subs       []*domain.Subscription
+		totalCount int64
+	)
+	err := s.tx.Do(ctx, s.reportTx, func(ctx context.Context) error {
+		var err error
+		subs, totalCount, err = s.repo.List(ctx, labels, paging)
+		return err
+	})
 	if err != nil {
 		logger.Error(ctx, "service: list failed", err, map[string]interface{}{
 			"limit":  paging.Limit,
 			"offset": paging.Offset,
 		})
-		return nil, 0, err
-	}
-
-	totalCount, err := s.repo.Count(ctx)
-	if err != nil {
-		logger.Error(ctx, "service: total count failed", err, nil)
-		return nil, 0, err
+		return nil, 0, spanError(span, err)
 	}
 
 	return subs, totalCount, nil
 }
 
 func (s *subService) Sum(ctx context.Context, filters *domain.SubscriptionFilter) (*domain.SumResult, error) {
+	ctx, span := startSpan(ctx, "SubService.Sum")
+	defer span.End()
+
 	logger.Debug(ctx, "service: getting sum subscriptions prices", map[string]interface{}{
 		"filters": filters,
 	})
 
-	rows, totalSum, err := s.repo.Sum(ctx, filters)
+	var res *domain.SumResult
+	err := s.tx.Do(ctx, s.reportTx, func(ctx context.Context) error {
+		var err error
+		res, err = s.repo.Sum(ctx, filters)
+		return err
+	})
 	if err != nil {
 		logger.Error(ctx, "service: sum failed", err, map[string]interface{}{
 			"user_id":      filters.UserID,
 			"service_name": filters.ServiceName,
 		})
-		return nil, err
+		return nil, spanError(span, err)
 	}
 
-	totalCount, err := s.repo.Count(ctx)
+	return res, nil
+}
+
+func (s *subService) Forecast(ctx context.Context, filters *domain.SubscriptionFilter, months int, group domain.ForecastGroup) (*domain.Forecast, error) {
+	ctx, span := startSpan(ctx, "SubService.Forecast", attribute.Int("forecast.months", months))
+	defer span.End()
+
+	logger.Debug(ctx, "service: forecasting subscriptions spend", map[string]interface{}{
+		"filters":  filters,
+		"months":   months,
+		"group_by": group,
+	})
+
+	// Период прогноза задаётся months, а не датами фильтра: в расчёт идут
+	// все подписки пользователя или сервиса.
+	f := &domain.SubscriptionFilter{UserID: filters.UserID, ServiceName: filters.ServiceName, Limit: -1}
+
+	var res *domain.SumResult
+	err := s.tx.Do(ctx, s.reportTx, func(ctx context.Context) error {
+		var err error
+		res, err = s.repo.Sum(ctx, f)
+		return err
+	})
 	if err != nil {
-		logger.Error(ctx, "service: total count failed", err, nil)
-		return nil, err
+		logger.Error(ctx, "service: forecast failed", err, map[string]interface{}{
+			"user_id":      filters.UserID,
+			"service_name": filters.ServiceName,
+		})
+		return nil, spanError(span, err)
 	}
 
-	return &domain.SumResult{
-		Rows:       rows,
-		TotalSum:   totalSum,
-		TotalCount: int(totalCount),
-	}, nil
+	forecast, err := domain.NewForecast(res.Rows, time.Now(), months, group)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return forecast, nil
 }
 
 func (s *subService) Update(ctx context.Context, id uuid.UUID, sub *domain.Subscription) (uuid.UUID, error) {
+	ctx, span := startSpan(ctx, "SubService.Update", attribute.String("subscription.id", id.String()))
+	defer span.End()
+
 	logger.Debug(ctx, "service: updating subscription", map[string]interface{}{
 		"id":   id,
 		"data": sub,
 	})
 
-	existingSub, err := s.repo.Get(ctx, id)
-	if err != nil {
-		if errors.Is(err, myerrors.ErrNotFound) {
-			logger.Warn(ctx, "service: subscription not found for update", map[string]interface{}{
+	// Проверка и запись в одной транзакции: строка заблокирована до
+	// коммита, конкурентный запрос не изменит и не удалит её между ними.
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		existingSub, err := s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, myerrors.ErrNotFound) {
+				logger.Warn(ctx, "service: subscription not found for update", map[string]interface{}{
+					"id": id,
+				})
+				return myerrors.ErrNotFound
+			}
+			logger.Error(ctx, "service: failed to check subscription existence", err, map[string]interface{}{
 				"id": id,
 			})
-			return uuid.Nil, myerrors.ErrNotFound
+
+			return err
 		}
-		logger.Error(ctx, "service: failed to check subscription existence", err, map[string]interface{}{
-			"id": id,
-		})
 
-		return uuid.Nil, err
-	}
+		if existingSub.ID() != sub.ID() {
+			logger.Warn(ctx, "service: id mismatch", map[string]interface{}{
+				"path_id": id,
+				"body_id": sub.ID(),
+			})
+			return myerrors.ErrInvalidData
+		}
 
-	if existingSub.ID() != sub.ID() {
-		logger.Warn(ctx, "service: id mismatch", map[string]interface{}{
-			"path_id": id,
-			"body_id": sub.ID(),
-		})
-		return uuid.Nil, myerrors.ErrInvalidData
-	}
+		// Доли общей подписки пересчитываются от новой цены.
+		split, err := s.repo.Split(ctx, id)
+		if err != nil {
+			return err
+		}
+		if split != nil {
+			if split, err = split.Reprice(sub.UserID(), sub.Price()); err != nil {
+				return err
+			}
+		}
 
-	if err := s.repo.Update(ctx, sub); err != nil {
-		logger.Error(ctx, "service: update failed", err, map[string]interface{}{
-			"id": id,
-		})
-		return uuid.Nil, err
+		if err := s.repo.Update(ctx, sub); err != nil {
+			logger.Error(ctx, "service: update failed", err, map[string]interface{}{
+				"id": id,
+			})
+			return err
+		}
+		if split != nil {
+			if err := s.repo.SaveSplit(ctx, id, split); err != nil {
+				return err
+			}
+		}
+
+		return s.events.Add(ctx, newSubEvent(EventSubscriptionUpdated, sub, time.Now()))
+	})
+	if err != nil {
+		return uuid.Nil, spanError(span, err)
 	}
 
 	logger.Info(ctx, "service: subscription updated", map[string]interface{}{
@@ -156,15 +278,34 @@ func (s *subService) Update(ctx context.Context, id uuid.UUID, sub *domain.Subsc
 }
 
 func (s *subService) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := startSpan(ctx, "SubService.Delete", attribute.String("subscription.id", id.String()))
+	defer span.End()
+
 	logger.Info(ctx, "service: deleting subscription", map[string]interface{}{
 		"id": id,
 	})
 
-	if err := s.repo.Delete(ctx, id); err != nil {
+	// Подписка читается перед удалением ради данных события. Отсутствующая
+	// подписка, как и раньше, не ошибка, но и события нет.
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		sub, err := s.repo.GetForUpdate(ctx, id)
+		if errors.Is(err, myerrors.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.events.Add(ctx, newSubEvent(EventSubscriptionDeleted, sub, time.Now()))
+	})
+	if err != nil {
 		logger.Error(ctx, "service: delete failed", err, map[string]interface{}{
 			"id": id,
 		})
-		return err
+		return spanError(span, err)
 	}
 
 	logger.Info(ctx, "service: subscription deleted", map[string]interface{}{
@@ -173,3 +314,246 @@ func (s *subService) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// DeleteByUser удаляет все подписки пользователя (удаление данных по запросу).
+func (s *subService) DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	ctx, span := startSpan(ctx, "SubService.DeleteByUser", attribute.String("user.id", userID.String()))
+	defer span.End()
+
+	logger.Info(ctx, "service: deleting user subscriptions", map[string]interface{}{
+		"user_id": userID,
+	})
+
+	var n int64
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		// Из чужих общих подписок пользователь выходит, его доли переходят
+		// владельцам: о новом разделении сообщается, как при RemoveMember.
+		shared, err := s.repo.ListSharedWith(ctx, userID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		events := make([]outbox.Event, 0, len(shared))
+		for _, sub := range shared {
+			split, err := s.removeMember(ctx, sub, userID)
+			if err != nil {
+				return err
+			}
+			events = append(events, newMembersEvent(sub, split, now))
+		}
+
+		deleted, err := s.repo.DeleteByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		n = int64(len(deleted))
+
+		for _, sub := range deleted {
+			events = append(events, newSubEvent(EventSubscriptionDeleted, sub, now))
+		}
+		return s.events.Add(ctx, events...)
+	})
+	if err != nil {
+		logger.Error(ctx, "service: delete user subscriptions failed", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return 0, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: user subscriptions deleted", map[string]interface{}{
+		"user_id": userID,
+		"deleted": n,
+	})
+
+	return n, nil
+}
+
+// ExpireEnded отмечает истечение подписок, последний оплаченный месяц
+// которых закончился: в октябре — подписок с end_date в сентябре.
+func (s *subService) ExpireEnded(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := startSpan(ctx, "SubService.ExpireEnded")
+	defer span.End()
+
+	now = now.UTC()
+	month := domain.NewSubDateFromTime(time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC))
+
+	ended, err := s.repo.ListEnded(readpref.WithPrimary(ctx), *month)
+	if err != nil {
+		logger.Error(ctx, "service: list ended subscriptions failed", err, map[string]interface{}{
+			"month": month.Format("01-2006"),
+		})
+		return 0, spanError(span, err)
+	}
+
+	events := make([]outbox.Event, 0, len(ended))
+	for _, sub := range ended {
+		events = append(events, newSubEvent(EventSubscriptionExpired, sub, now))
+	}
+	if err := s.events.Add(ctx, events...); err != nil {
+		logger.Error(ctx, "service: expired events not recorded", err, map[string]interface{}{
+			"month": month.Format("01-2006"),
+		})
+		return 0, spanError(span, err)
+	}
+
+	if len(ended) > 0 {
+		logger.Info(ctx, "service: subscriptions expired", map[string]interface{}{
+			"month":   month.Format("01-2006"),
+			"expired": len(ended),
+		})
+	}
+
+	return len(ended), nil
+}
+
+func (s *subService) Members(ctx context.Context, id uuid.UUID) (*domain.Subscription, *domain.Split, error) {
+	ctx, span := startSpan(ctx, "SubService.Members", attribute.String("subscription.id", id.String()))
+	defer span.End()
+
+	var (
+		sub   *domain.Subscription
+		split *domain.Split
+	)
+	err := s.tx.Do(ctx, s.reportTx, func(ctx context.Context) error {
+		var err error
+		if sub, err = s.repo.Get(ctx, id); err != nil {
+			return err
+		}
+		split, err = s.repo.Split(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, nil, spanError(span, err)
+	}
+
+	return sub, split, nil
+}
+
+func (s *subService) SetMembers(ctx context.Context, id uuid.UUID, rule domain.SplitRule, members []domain.Member) (*domain.Subscription, *domain.Split, error) {
+	ctx, span := startSpan(ctx, "SubService.SetMembers",
+		attribute.String("subscription.id", id.String()),
+		attribute.String("split.rule", string(rule)))
+	defer span.End()
+
+	var (
+		sub   *domain.Subscription
+		split *domain.Split
+	)
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		if sub, err = s.repo.GetForUpdate(ctx, id); err != nil {
+			return err
+		}
+		if split, err = domain.NewSplit(rule, sub.UserID(), sub.Price(), members); err != nil {
+			return err
+		}
+		if err := s.repo.SaveSplit(ctx, id, split); err != nil {
+			return err
+		}
+		return s.events.Add(ctx, newMembersEvent(sub, split, time.Now()))
+	})
+	if err != nil {
+		logger.Error(ctx, "service: set members failed", err, map[string]interface{}{
+			"id":   id,
+			"rule": rule,
+		})
+		return nil, nil, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: subscription members set", map[string]interface{}{
+		"id":      id,
+		"rule":    split.Rule,
+		"members": split.UserIDs(),
+	})
+	return sub, split, nil
+}
+
+func (s *subService) RemoveMember(ctx context.Context, id, userID uuid.UUID) (*domain.Subscription, *domain.Split, error) {
+	ctx, span := startSpan(ctx, "SubService.RemoveMember",
+		attribute.String("subscription.id", id.String()),
+		attribute.String("user.id", userID.String()))
+	defer span.End()
+
+	var (
+		sub   *domain.Subscription
+		split *domain.Split
+	)
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		var err error
+		if sub, err = s.repo.GetForUpdate(ctx, id); err != nil {
+			return err
+		}
+		if userID == sub.UserID() {
+			return fmt.Errorf("%w: the owner cannot be removed", domain.ErrInvalidMember)
+		}
+		if split, err = s.removeMember(ctx, sub, userID); err != nil {
+			return err
+		}
+		return s.events.Add(ctx, newMembersEvent(sub, split, time.Now()))
+	})
+	if err != nil {
+		logger.Error(ctx, "service: remove member failed", err, map[string]interface{}{
+			"id":      id,
+			"user_id": userID,
+		})
+		return nil, nil, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: subscription member removed", map[string]interface{}{
+		"id":      id,
+		"user_id": userID,
+	})
+	return sub, split, nil
+}
+
+// removeMember исключает userID из участников sub и возвращает новое
+// разделение (nil — подписка больше не общая).
+func (s *subService) removeMember(ctx context.Context, sub *domain.Subscription, userID uuid.UUID) (*domain.Split, error) {
+	split, err := s.repo.Split(ctx, sub.ID())
+	if err != nil {
+		return nil, err
+	}
+	if split == nil {
+		return nil, myerrors.ErrMemberNotFound
+	}
+
+	split, ok := split.Without(userID, sub.Price())
+	if !ok {
+		return nil, myerrors.ErrMemberNotFound
+	}
+	if err := s.repo.SaveSplit(ctx, sub.ID(), split); err != nil {
+		return nil, err
+	}
+	return split, nil
+}
+
+func (s *subService) Unshare(ctx context.Context, id uuid.UUID) error {
+	ctx, span := startSpan(ctx, "SubService.Unshare", attribute.String("subscription.id", id.String()))
+	defer span.End()
+
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		sub, err := s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Подписка и так не общая: менять и сообщать нечего.
+		if split, err := s.repo.Split(ctx, id); err != nil || split == nil {
+			return err
+		}
+		if err := s.repo.SaveSplit(ctx, id, nil); err != nil {
+			return err
+		}
+		return s.events.Add(ctx, newMembersEvent(sub, nil, time.Now()))
+	})
+	if err != nil {
+		logger.Error(ctx, "service: unshare failed", err, map[string]interface{}{
+			"id": id,
+		})
+		return spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: subscription unshared", map[string]interface{}{
+		"id": id,
+	})
+	return nil
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/user"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/repository"
+	logger "testingtask/pkg"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// UserInput — пользователь из запроса. Пустые Currency и Timezone —
+// значения по умолчанию.
+type UserInput struct {
+	DisplayName string
+	Email       *string
+	Currency    string
+	Timezone    string
+}
+
+type UserService interface {
+	Create(ctx context.Context, in UserInput) (*user.User, error)
+	Get(ctx context.Context, id uuid.UUID) (*user.User, error)
+	List(ctx context.Context, paging *domain.PagingBase) ([]*user.User, int64, error)
+	Update(ctx context.Context, id uuid.UUID, in UserInput) (*user.User, error)
+	// Delete удаляет пользователя вместе с его подписками, долями в чужих
+	// общих подписках, webhook-endpoint'ами, бюджетами и настройками
+	// напоминаний и возвращает число удалённых подписок.
+	Delete(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
+// UserOwnedRepository — хранилище прочих данных пользователя, которые
+// удаляются вместе с ним. В Postgres их удаляет и ON DELETE CASCADE, в
+// SQLite и памяти внешних ключей нет.
+type UserOwnedRepository interface {
+	DeleteByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+type userService struct {
+	repo repository.UserRepository
+	// subs удаляет подписки пользователя: через него проходят события,
+	// пересчёт общих подписок и инвалидация кэша.
+	subs  SubService
+	owned []UserOwnedRepository
+	tx    repository.TxManager
+}
+
+// NewUserService возвращает UserService. owned — хранилища данных,
+// которые Delete удаляет вместе с пользователем.
+func NewUserService(r repository.UserRepository, subs SubService, tx repository.TxManager, owned ...UserOwnedRepository) UserService {
+	return &userService{repo: r, subs: subs, owned: owned, tx: tx}
+}
+
+// checkEmail проверяет, что email u не занят другим пользователем.
+// Уникальный индекс тоже это запрещает, но его ошибка неотличима от прочих
+// нарушений ограничений.
+func (s *userService) checkEmail(ctx context.Context, u *user.User) error {
+	if u.Email() == nil {
+		return nil
+	}
+	other, err := s.repo.GetByEmail(ctx, *u.Email())
+	if errors.Is(err, myerrors.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if other.ID() != u.ID() {
+		return user.ErrEmailTaken
+	}
+	return nil
+}
+
+func (s *userService) Create(ctx context.Context, in UserInput) (*user.User, error) {
+	ctx, span := startSpan(ctx, "UserService.Create")
+	defer span.End()
+
+	u, err := user.NewUser(uuid.Nil, in.DisplayName, in.Email, in.Currency, in.Timezone)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	err = s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if err := s.checkEmail(ctx, u); err != nil {
+			return err
+		}
+		return s.repo.Create(ctx, u)
+	})
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: user created", map[string]interface{}{
+		"id":       u.ID(),
+		"currency": u.Currency(),
+		"timezone": u.Timezone(),
+	})
+	return u, nil
+}
+
+func (s *userService) Get(ctx context.Context, id uuid.UUID) (*user.User, error) {
+	ctx, span := startSpan(ctx, "UserService.Get", attribute.String("user.id", id.String()))
+	defer span.End()
+
+	u, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return u, nil
+}
+
+func (s *userService) List(ctx context.Context, paging *domain.PagingBase) ([]*user.User, int64, error) {
+	ctx, span := startSpan(ctx, "UserService.List")
+	defer span.End()
+
+	res, total, err := s.repo.List(ctx, paging)
+	if err != nil {
+		return nil, 0, spanError(span, err)
+	}
+	return res, total, nil
+}
+
+func (s *userService) Update(ctx context.Context, id uuid.UUID, in UserInput) (*user.User, error) {
+	ctx, span := startSpan(ctx, "UserService.Update", attribute.String("user.id", id.String()))
+	defer span.End()
+
+	u, err := user.NewUser(id, in.DisplayName, in.Email, in.Currency, in.Timezone)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	err = s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		old, err := s.repo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		u = user.RestoreUser(id, u.DisplayName(), u.Email(), u.Currency(), u.Timezone(), old.CreatedAt())
+
+		if err := s.checkEmail(ctx, u); err != nil {
+			return err
+		}
+		return s.repo.Update(ctx, u)
+	})
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: user updated", map[string]interface{}{
+		"id":       id,
+		"currency": u.Currency(),
+		"timezone": u.Timezone(),
+	})
+	return u, nil
+}
+
+func (s *userService) Delete(ctx context.Context, id uuid.UUID) (int64, error) {
+	ctx, span := startSpan(ctx, "UserService.Delete", attribute.String("user.id", id.String()))
+	defer span.End()
+
+	var deleted int64
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if _, err := s.repo.Get(ctx, id); err != nil {
+			return err
+		}
+		n, err := s.subs.DeleteByUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		deleted = n
+		for _, r := range s.owned {
+			if _, err := r.DeleteByUser(ctx, id); err != nil {
+				return err
+			}
+		}
+		return s.repo.Delete(ctx, id)
+	})
+	if err != nil {
+		return 0, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: user deleted", map[string]interface{}{
+		"id":                    id,
+		"deleted_subscriptions": deleted,
+	})
+	return deleted, nil
+}
+
+// userSubService проверяет, что владелец подписки и участники общей
+// подписки есть среди пользователей. В Postgres то же проверяют внешние
+// ключи, в SQLite и памяти их нет.
+type userSubService struct {
+	SubService
+	users repository.UserRepository
+	tx    repository.TxManager
+}
+
+// NewUserSubService оборачивает next. Проверка и изменение выполняются в
+// одной транзакции: пользователь не удалится между ними.
+func NewUserSubService(next SubService, users repository.UserRepository, tx repository.TxManager) SubService {
+	return &userSubService{SubService: next, users: users, tx: tx}
+}
+
+// checkUsers возвращает user.ErrUnknownUser с первым отсутствующим ID.
+func checkUsers(ctx context.Context, users repository.UserRepository, ids ...uuid.UUID) error {
+	missing, err := users.Missing(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", user.ErrUnknownUser, missing[0])
+	}
+	return nil
+}
+
+func (s *userSubService) Create(ctx context.Context, sub *domain.Subscription) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if err := checkUsers(ctx, s.users, sub.UserID()); err != nil {
+			return err
+		}
+		var err error
+		id, err = s.SubService.Create(ctx, sub)
+		return err
+	})
+	return id, err
+}
+
+func (s *userSubService) Update(ctx context.Context, id uuid.UUID, sub *domain.Subscription) (uuid.UUID, error) {
+	var res uuid.UUID
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if err := checkUsers(ctx, s.users, sub.UserID()); err != nil {
+			return err
+		}
+		var err error
+		res, err = s.SubService.Update(ctx, id, sub)
+		return err
+	})
+	return res, err
+}
+
+func (s *userSubService) SetMembers(ctx context.Context, id uuid.UUID, rule domain.SplitRule, members []domain.Member) (*domain.Subscription, *domain.Split, error) {
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, m := range members {
+		// Пустой ID отклонит domain.NewSplit.
+		if m.UserID != uuid.Nil {
+			ids = append(ids, m.UserID)
+		}
+	}
+
+	var (
+		sub   *domain.Subscription
+		split *domain.Split
+	)
+	err := s.tx.Do(ctx, repository.TxOptions{}, func(ctx context.Context) error {
+		if err := checkUsers(ctx, s.users, ids...); err != nil {
+			return err
+		}
+		var err error
+		sub, split, err = s.SubService.SetMembers(ctx, id, rule, members)
+		return err
+	})
+	return sub, split, err
+}
@@ -0,0 +1,131 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testingtask/internal/domain/budget"
+	"testingtask/internal/domain/reminder"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/user"
+	"testingtask/internal/domain/webhook"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/service"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestUserSubServiceUnknownUser(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withUsers)
+
+	stranger := uuid.New()
+	sub := domain.RestoreSubscription(uuid.New(), "Netflix", 400, stranger, *start, nil)
+	if _, err := f.subs.Create(ctx, sub); !errors.Is(err, user.ErrUnknownUser) {
+		t.Fatalf("Create for unknown user: got %v, want ErrUnknownUser", err)
+	}
+
+	alice := f.user(t, "Алиса", nil)
+	family := mustCreate(t, f.subs, alice, "Spotify", 900)
+
+	upd := domain.RestoreSubscription(family.ID(), "Spotify", 900, stranger, *start, nil)
+	if _, err := f.subs.Update(ctx, family.ID(), upd); !errors.Is(err, user.ErrUnknownUser) {
+		t.Fatalf("Update to unknown user: got %v, want ErrUnknownUser", err)
+	}
+	if _, _, err := f.subs.SetMembers(ctx, family.ID(), domain.SplitEqual, []domain.Member{{UserID: stranger}}); !errors.Is(err, user.ErrUnknownUser) {
+		t.Fatalf("SetMembers with unknown member: got %v, want ErrUnknownUser", err)
+	}
+}
+
+func TestUserEmailTaken(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withUsers)
+	email := "alice@example.com"
+	alice := f.user(t, "Алиса", &email)
+
+	upper := "Alice@Example.com"
+	if _, err := f.users.Create(ctx, service.UserInput{DisplayName: "Ева", Email: &upper}); !errors.Is(err, user.ErrEmailTaken) {
+		t.Fatalf("Create with taken email: got %v, want ErrEmailTaken", err)
+	}
+
+	bob := f.user(t, "Боб", nil)
+	if _, err := f.users.Update(ctx, bob, service.UserInput{DisplayName: "Боб", Email: &email}); !errors.Is(err, user.ErrEmailTaken) {
+		t.Fatalf("Update to taken email: got %v, want ErrEmailTaken", err)
+	}
+
+	// Свой email не мешает обновлению, дата создания сохраняется.
+	before, err := f.users.Get(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := f.users.Update(ctx, alice, service.UserInput{DisplayName: "Алиса К.", Email: &email, Currency: "EUR"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if after.DisplayName() != "Алиса К." || after.Currency() != "EUR" || !after.CreatedAt().Equal(before.CreatedAt()) {
+		t.Fatalf("Update = %+v, want renamed with created_at %v", after, before.CreatedAt())
+	}
+
+	if _, err := f.users.Update(ctx, uuid.New(), service.UserInput{DisplayName: "Никто"}); !errors.Is(err, myerrors.ErrUserNotFound) {
+		t.Fatalf("Update(missing): got %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestUserDeleteCascades(t *testing.T) {
+	ctx := context.Background()
+	f := newHarness(t, withUsers)
+	alice := f.user(t, "Алиса", nil)
+	bob := f.user(t, "Боб", nil)
+
+	family := mustCreate(t, f.subs, alice, "Spotify", 900)
+	if _, _, err := f.subs.SetMembers(ctx, family.ID(), domain.SplitEqual, []domain.Member{{UserID: bob}}); err != nil {
+		t.Fatal(err)
+	}
+	own := mustCreate(t, f.subs, bob, "Netflix", 400)
+	hook, err := webhook.NewEndpoint(uuid.Nil, bob, "https://example.com/hook", "0123456789abcdef", []string{service.EventSubscriptionCreated})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.hookRepo.CreateEndpoint(ctx, hook); err != nil {
+		t.Fatal(err)
+	}
+	limit := budget.RestoreBudget(uuid.New(), bob, nil, nil, 1000, false, time.Now())
+	if err := f.budgetRepo.Create(ctx, limit); err != nil {
+		t.Fatal(err)
+	}
+	prefs, err := reminder.NewPreferences(bob, true, 3, reminder.ChannelLog, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.reminderRepo.SavePreferences(ctx, prefs); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.users.Delete(ctx, bob); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := f.users.Get(ctx, bob); !errors.Is(err, myerrors.ErrUserNotFound) {
+		t.Fatalf("Get after delete: got %v, want ErrUserNotFound", err)
+	}
+	if _, err := f.subRepo.Get(ctx, own.ID()); !errors.Is(err, myerrors.ErrNotFound) {
+		t.Fatalf("Get own subscription: got %v, want ErrNotFound", err)
+	}
+	if _, err := f.hookRepo.GetEndpoint(ctx, hook.ID()); !errors.Is(err, myerrors.ErrWebhookNotFound) {
+		t.Fatalf("Get webhook: got %v, want ErrWebhookNotFound", err)
+	}
+	if _, err := f.budgetRepo.Get(ctx, limit.ID()); !errors.Is(err, myerrors.ErrBudgetNotFound) {
+		t.Fatalf("Get budget: got %v, want ErrBudgetNotFound", err)
+	}
+	if _, err := f.reminderRepo.GetPreferences(ctx, bob); !errors.Is(err, myerrors.ErrPreferencesNotFound) {
+		t.Fatalf("Get reminder preferences: got %v, want ErrPreferencesNotFound", err)
+	}
+	// Доля удалённого участника возвращается владельцу.
+	if _, split, err := f.subs.Members(ctx, family.ID()); err != nil || split != nil {
+		t.Fatalf("Members after delete = %+v, %v, want not shared", split, err)
+	}
+
+	if _, err := f.users.Delete(ctx, bob); !errors.Is(err, myerrors.ErrUserNotFound) {
+		t.Fatalf("Delete(missing): got %v, want ErrUserNotFound", err)
+	}
+}
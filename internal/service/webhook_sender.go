@@ -0,0 +1,258 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"testingtask/internal/domain/webhook"
+	myerrors "testingtask/internal/errors"
+	"testingtask/internal/netguard"
+	"testingtask/internal/outbox"
+	"testingtask/internal/repository"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// deliveryNamespace — пространство имён UUIDv5 для ID доставок: повторная
+// публикация события relay'ем не создаёт доставки заново.
+var deliveryNamespace = uuid.MustParse("b7e4d1a2-3c5f-4e8a-9d6b-1f2a3c4e5d6f")
+
+type webhookDispatcher struct {
+	repo repository.WebhookRepository
+	now  func() time.Time
+}
+
+// NewWebhookDispatcher возвращает outbox.Sink, который раскладывает
+// событие по доставкам на подписанные endpoint'ы. Сами запросы отправляет
+// WebhookSender.
+func NewWebhookDispatcher(r repository.WebhookRepository) outbox.Sink {
+	return &webhookDispatcher{repo: r, now: time.Now}
+}
+
+func (d *webhookDispatcher) Publish(ctx context.Context, e outbox.Event) error {
+	// Событие уходит только на endpoint'ы его пользователя. Без user_id
+	// его некому доставить, а повтор этого не исправит.
+	userID, err := eventUserID(e)
+	if err != nil || userID == uuid.Nil {
+		logger.Warn(ctx, "webhooks: event has no user, skipped", map[string]interface{}{
+			"event_id":   e.ID,
+			"event_type": e.Type,
+		})
+		return nil
+	}
+
+	endpoints, err := d.repo.EndpointsFor(ctx, e.Type, userID)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(e.Message())
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	now := d.now().UTC()
+	deliveries := make([]webhook.Delivery, 0, len(endpoints))
+	for _, ep := range endpoints {
+		deliveries = append(deliveries, webhook.Delivery{
+			ID:            uuid.NewSHA1(deliveryNamespace, []byte(ep.ID().String()+"/"+e.ID.String())),
+			EndpointID:    ep.ID(),
+			EventID:       e.ID,
+			EventType:     e.Type,
+			Payload:       payload,
+			Status:        webhook.StatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+	return d.repo.AddDeliveries(ctx, deliveries...)
+}
+
+type WebhookSenderConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Timeout — время на один запрос к endpoint'у.
+	Timeout time.Duration
+	// MaxAttempts — после стольких неудач доставка получает статус failed.
+	MaxAttempts int
+	// RetryMin и RetryMax — границы экспоненциальной задержки повтора.
+	RetryMin time.Duration
+	RetryMax time.Duration
+	// Transport — для тестов. По умолчанию соединения во внутреннюю сеть
+	// запрещены, см. netguard.
+	Transport http.RoundTripper
+}
+
+// WebhookSender отправляет доставки, время которых наступило: POST с
+// подписью HMAC-SHA256 и сообщением outbox в теле. Каждая попытка
+// записывается в журнал доставки, неудачная откладывается с растущей
+// задержкой. Несколько экземпляров не берут одну доставку: ClaimDue
+// выдаёт её в аренду.
+type WebhookSender struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+	cfg    WebhookSenderConfig
+	now    func() time.Time
+}
+
+func NewWebhookSender(r repository.WebhookRepository, cfg WebhookSenderConfig) *WebhookSender {
+	client := netguard.NewClient(cfg.Timeout)
+	if cfg.Transport != nil {
+		client.Transport = cfg.Transport
+	}
+	return &WebhookSender{
+		repo:   r,
+		client: client,
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+// Run отправляет доставки до отмены ctx. Полная пачка забирается сразу
+// следующей, иначе WebhookSender ждёт PollInterval.
+func (s *WebhookSender) Run(ctx context.Context) {
+	logger.Info(ctx, "webhooks: sender started", map[string]interface{}{
+		"poll_interval": s.cfg.PollInterval.String(),
+		"batch_size":    s.cfg.BatchSize,
+	})
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(context.Background(), "webhooks: sender stopped", nil)
+			return
+		case <-timer.C:
+		}
+
+		n, err := s.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error(ctx, "webhooks: sender iteration failed", err, nil)
+		}
+
+		if err == nil && n == s.cfg.BatchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(s.cfg.PollInterval)
+		}
+	}
+}
+
+// RunOnce отправляет одну пачку и возвращает число взятых доставок.
+func (s *WebhookSender) RunOnce(ctx context.Context) (int, error) {
+	// Аренда покрывает пачку целиком, даже если все endpoint'ы молчат до
+	// таймаута.
+	lease := time.Duration(s.cfg.BatchSize+1) * s.cfg.Timeout
+	deliveries, err := s.repo.ClaimDue(ctx, s.now().UTC(), lease, s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	endpoints := map[uuid.UUID]*webhook.Endpoint{}
+	for _, d := range deliveries {
+		ep, ok := endpoints[d.EndpointID]
+		if !ok {
+			ep, err = s.repo.GetEndpoint(ctx, d.EndpointID)
+			if errors.Is(err, myerrors.ErrWebhookNotFound) {
+				// Endpoint удалили после выборки, доставки ушли вместе с ним.
+				continue
+			}
+			if err != nil {
+				return len(deliveries), err
+			}
+			endpoints[d.EndpointID] = ep
+		}
+
+		attempt := s.send(ctx, ep, d)
+		if ctx.Err() != nil {
+			// Прерванная остановкой попытка не считается, доставка
+			// вернётся в очередь по окончании аренды.
+			return len(deliveries), ctx.Err()
+		}
+		if err := s.repo.RecordAttempt(ctx, s.advance(ctx, d, attempt), attempt); err != nil {
+			return len(deliveries), err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// advance возвращает состояние доставки после попытки.
+func (s *WebhookSender) advance(ctx context.Context, d webhook.Delivery, a webhook.Attempt) webhook.Delivery {
+	d.Attempts++
+
+	switch {
+	case a.Succeeded():
+		d.Status = webhook.StatusSucceeded
+		d.DeliveredAt = &a.AttemptedAt
+	case d.Attempts >= s.cfg.MaxAttempts:
+		d.Status = webhook.StatusFailed
+		logger.Warn(ctx, "webhooks: delivery failed, attempts exhausted", map[string]interface{}{
+			"delivery_id": d.ID,
+			"endpoint_id": d.EndpointID,
+			"attempts":    d.Attempts,
+			"error":       *a.Error,
+		})
+	default:
+		d.NextAttemptAt = a.AttemptedAt.Add(outbox.Backoff(s.cfg.RetryMin, s.cfg.RetryMax, d.Attempts))
+		logger.Warn(ctx, "webhooks: delivery failed, will retry", map[string]interface{}{
+			"delivery_id": d.ID,
+			"endpoint_id": d.EndpointID,
+			"attempt":     d.Attempts,
+			"retry_at":    d.NextAttemptAt,
+			"error":       *a.Error,
+		})
+	}
+
+	return d
+}
+
+// send выполняет одну попытку. Успех — любой ответ 2xx.
+func (s *WebhookSender) send(ctx context.Context, ep *webhook.Endpoint, d webhook.Delivery) webhook.Attempt {
+	start := s.now().UTC()
+	a := webhook.Attempt{AttemptedAt: start}
+
+	fail := func(err error) webhook.Attempt {
+		msg := err.Error()
+		a.Error = &msg
+		a.Duration = s.now().Sub(start)
+		return a
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL(), bytes.NewReader(d.Payload))
+	if err != nil {
+		return fail(err)
+	}
+	ts := start.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(outbox.HeaderEventID, d.EventID.String())
+	req.Header.Set(outbox.HeaderEventType, d.EventType)
+	req.Header.Set(webhook.HeaderDelivery, d.ID.String())
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(ep.Secret(), ts, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fail(err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	code := resp.StatusCode
+	a.StatusCode = &code
+	if code < 200 || code > 299 {
+		return fail(fmt.Errorf("endpoint responded %s", resp.Status))
+	}
+
+	a.Duration = s.now().Sub(start)
+	return a
+}
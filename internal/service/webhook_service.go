@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/webhook"
+	"testingtask/internal/repository"
+	logger "testingtask/pkg"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// WebhookInput — данные endpoint'а из запроса. Пустой Secret при создании
+// означает «сгенерировать», при обновлении — «оставить прежний».
+type WebhookInput struct {
+	// UserID — владелец: endpoint получает только его события.
+	UserID     uuid.UUID
+	URL        string
+	Secret     string
+	EventTypes []string
+}
+
+type WebhookService interface {
+	Create(ctx context.Context, in WebhookInput) (*webhook.Endpoint, error)
+	Get(ctx context.Context, id uuid.UUID) (*webhook.Endpoint, error)
+	List(ctx context.Context, paging *domain.PagingBase) ([]*webhook.Endpoint, int64, error)
+	Update(ctx context.Context, id uuid.UUID, in WebhookInput) (*webhook.Endpoint, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Deliveries возвращает историю доставок endpoint'а, новые первыми.
+	Deliveries(ctx context.Context, id uuid.UUID, paging *domain.PagingBase) ([]webhook.Delivery, int64, error)
+	// Redeliver ставит доставку в очередь на немедленную отправку, в том
+	// числе после исчерпания попыток.
+	Redeliver(ctx context.Context, id, deliveryID uuid.UUID) (*webhook.Delivery, error)
+}
+
+// webhookEventTypes — события, на которые можно подписать endpoint.
+var webhookEventTypes = map[string]bool{
+	EventSubscriptionCreated: true,
+	EventSubscriptionUpdated: true,
+	EventSubscriptionDeleted: true,
+	EventSubscriptionExpired: true,
+	EventBudgetExceeded:      true,
+}
+
+// secretPrefix отличает сгенерированные ключи в логах и конфигах клиентов.
+const secretPrefix = "whsec_"
+
+type webhookService struct {
+	repo  repository.WebhookRepository
+	users repository.UserRepository
+}
+
+func NewWebhookService(r repository.WebhookRepository, users repository.UserRepository) WebhookService {
+	return &webhookService{repo: r, users: users}
+}
+
+func checkEventTypes(types []string) error {
+	for _, t := range types {
+		if !webhookEventTypes[t] {
+			return webhook.ErrUnknownEventType
+		}
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+func (s *webhookService) Create(ctx context.Context, in WebhookInput) (*webhook.Endpoint, error) {
+	ctx, span := startSpan(ctx, "WebhookService.Create")
+	defer span.End()
+
+	if err := checkEventTypes(in.EventTypes); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	secret := in.Secret
+	if secret == "" {
+		var err error
+		if secret, err = newSecret(); err != nil {
+			logger.Error(ctx, "service: webhook secret generation failed", err, nil)
+			return nil, spanError(span, err)
+		}
+	}
+
+	e, err := webhook.NewEndpoint(uuid.New(), in.UserID, in.URL, secret, in.EventTypes)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	if err := checkUsers(ctx, s.users, e.UserID()); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	if err := s.repo.CreateEndpoint(ctx, e); err != nil {
+		logger.Error(ctx, "service: webhook create failed", err, map[string]interface{}{"url": e.URL()})
+		return nil, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: webhook created", map[string]interface{}{
+		"id":          e.ID(),
+		"user_id":     e.UserID(),
+		"url":         e.URL(),
+		"event_types": e.EventTypes(),
+	})
+
+	return e, nil
+}
+
+func (s *webhookService) Get(ctx context.Context, id uuid.UUID) (*webhook.Endpoint, error) {
+	ctx, span := startSpan(ctx, "WebhookService.Get", attribute.String("webhook.id", id.String()))
+	defer span.End()
+
+	e, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return e, nil
+}
+
+func (s *webhookService) List(ctx context.Context, paging *domain.PagingBase) ([]*webhook.Endpoint, int64, error) {
+	ctx, span := startSpan(ctx, "WebhookService.List",
+		attribute.Int("paging.limit", paging.Limit),
+		attribute.Int("paging.offset", paging.Offset),
+	)
+	defer span.End()
+
+	rows, total, err := s.repo.ListEndpoints(ctx, paging)
+	if err != nil {
+		return nil, 0, spanError(span, err)
+	}
+	return rows, total, nil
+}
+
+func (s *webhookService) Update(ctx context.Context, id uuid.UUID, in WebhookInput) (*webhook.Endpoint, error) {
+	ctx, span := startSpan(ctx, "WebhookService.Update", attribute.String("webhook.id", id.String()))
+	defer span.End()
+
+	if err := checkEventTypes(in.EventTypes); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	current, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	secret := in.Secret
+	if secret == "" {
+		secret = current.Secret()
+	}
+
+	e, err := webhook.NewEndpoint(id, in.UserID, in.URL, secret, in.EventTypes)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	if err := checkUsers(ctx, s.users, e.UserID()); err != nil {
+		return nil, spanError(span, err)
+	}
+	if err := s.repo.UpdateEndpoint(ctx, e); err != nil {
+		logger.Error(ctx, "service: webhook update failed", err, map[string]interface{}{"id": id})
+		return nil, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: webhook updated", map[string]interface{}{
+		"id":          id,
+		"user_id":     e.UserID(),
+		"url":         e.URL(),
+		"event_types": e.EventTypes(),
+	})
+
+	return webhook.RestoreEndpoint(id, e.UserID(), e.URL(), e.Secret(), e.EventTypes(), current.CreatedAt()), nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := startSpan(ctx, "WebhookService.Delete", attribute.String("webhook.id", id.String()))
+	defer span.End()
+
+	if err := s.repo.DeleteEndpoint(ctx, id); err != nil {
+		logger.Error(ctx, "service: webhook delete failed", err, map[string]interface{}{"id": id})
+		return spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: webhook deleted", map[string]interface{}{"id": id})
+	return nil
+}
+
+func (s *webhookService) Deliveries(ctx context.Context, id uuid.UUID, paging *domain.PagingBase) ([]webhook.Delivery, int64, error) {
+	ctx, span := startSpan(ctx, "WebhookService.Deliveries", attribute.String("webhook.id", id.String()))
+	defer span.End()
+
+	// Пустая история и несуществующий endpoint — разные ответы.
+	if _, err := s.repo.GetEndpoint(ctx, id); err != nil {
+		return nil, 0, spanError(span, err)
+	}
+
+	rows, total, err := s.repo.ListDeliveries(ctx, id, paging)
+	if err != nil {
+		return nil, 0, spanError(span, err)
+	}
+	return rows, total, nil
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, id, deliveryID uuid.UUID) (*webhook.Delivery, error) {
+	ctx, span := startSpan(ctx, "WebhookService.Redeliver",
+		attribute.String("webhook.id", id.String()),
+		attribute.String("delivery.id", deliveryID.String()),
+	)
+	defer span.End()
+
+	d, err := s.repo.Redeliver(ctx, id, deliveryID, time.Now().UTC())
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	logger.Info(ctx, "service: webhook redelivery queued", map[string]interface{}{
+		"id":          id,
+		"delivery_id": deliveryID,
+	})
+	return d, nil
+}
@@ -0,0 +1,283 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	domain "testingtask/internal/domain/subscription"
+	"testingtask/internal/domain/user"
+	"testingtask/internal/domain/webhook"
+	"testingtask/internal/netguard"
+	"testingtask/internal/outbox"
+	"testingtask/internal/repository"
+	"testingtask/internal/service"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// hookServer принимает запросы, проверяя подпись, и отвечает status.
+type hookServer struct {
+	*httptest.Server
+	secret string
+
+	mu     sync.Mutex
+	status int
+	got    []http.Header
+	bodies []string
+}
+
+func newHookServer(t *testing.T, secret string) *hookServer {
+	t.Helper()
+	h := &hookServer{secret: secret, status: http.StatusOK}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if r.Header.Get(webhook.HeaderSignature) != webhook.Sign(h.secret, ts, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h.got = append(h.got, r.Header.Clone())
+		h.bodies = append(h.bodies, string(body))
+		w.WriteHeader(h.status)
+	}))
+	t.Cleanup(h.Close)
+	return h
+}
+
+func (h *hookServer) setStatus(code int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.status = code
+}
+
+// transport направляет запросы на любой адрес в h: сервер слушает
+// loopback, и endpoint с его URL отклонил бы NewEndpoint.
+func (h *hookServer) transport() http.RoundTripper {
+	return &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, h.Listener.Addr().String())
+		},
+	}
+}
+
+// hookURL — публичный на вид адрес endpoint'а, см. hookServer.transport.
+const hookURL = "http://hooks.example.com/subscriptions"
+
+func newWebhookUser(t *testing.T, users repository.UserRepository) uuid.UUID {
+	t.Helper()
+	u, err := user.NewUser(uuid.Nil, "Алиса", nil, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := users.Create(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+	return u.ID()
+}
+
+// userEvent — событие подписки пользователя userID.
+func userEvent(typ string, userID uuid.UUID) outbox.Event {
+	payload, _ := json.Marshal(service.SubscriptionEventData{ID: uuid.New(), UserID: userID})
+	return outbox.Event{
+		ID:          uuid.New(),
+		Type:        typ,
+		AggregateID: uuid.New(),
+		Payload:     payload,
+		OccurredAt:  time.Now().UTC(),
+	}
+}
+
+func TestWebhookServiceValidation(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewMemoryUserRepository()
+	svc := service.NewWebhookService(repository.NewMemoryWebhookRepository(), users)
+	alice := newWebhookUser(t, users)
+	created := []string{service.EventSubscriptionCreated}
+
+	cases := []struct {
+		name string
+		in   service.WebhookInput
+		want error
+	}{
+		{"unknown event type", service.WebhookInput{UserID: alice, URL: "https://example.com", EventTypes: []string{"SubscriptionRenamed"}}, webhook.ErrUnknownEventType},
+		{"ftp url", service.WebhookInput{UserID: alice, URL: "ftp://example.com", EventTypes: created}, webhook.ErrInvalidURL},
+		{"no user", service.WebhookInput{URL: "https://example.com", EventTypes: created}, webhook.ErrNoUser},
+		{"unknown user", service.WebhookInput{UserID: uuid.New(), URL: "https://example.com", EventTypes: created}, user.ErrUnknownUser},
+		{"loopback", service.WebhookInput{UserID: alice, URL: "http://127.0.0.1:8080/hook", EventTypes: created}, netguard.ErrForbiddenTarget},
+		{"metadata", service.WebhookInput{UserID: alice, URL: "http://169.254.169.254/latest", EventTypes: created}, netguard.ErrForbiddenTarget},
+		{"private", service.WebhookInput{UserID: alice, URL: "https://10.0.0.7/hook", EventTypes: created}, netguard.ErrForbiddenTarget},
+		{"localhost", service.WebhookInput{UserID: alice, URL: "http://localhost/hook", EventTypes: created}, netguard.ErrForbiddenTarget},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.Create(ctx, tc.in); !errors.Is(err, tc.want) {
+				t.Fatalf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+
+	e, err := svc.Create(ctx, service.WebhookInput{UserID: alice, URL: "https://example.com", EventTypes: created})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(e.Secret(), "whsec_") || len(e.Secret()) < webhook.MinSecretLength {
+		t.Fatalf("generated secret %q", e.Secret())
+	}
+
+	// Пустой секрет при обновлении оставляет прежний.
+	upd, err := svc.Update(ctx, e.ID(), service.WebhookInput{UserID: alice, URL: "https://example.org", EventTypes: []string{service.EventSubscriptionDeleted}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upd.Secret() != e.Secret() || upd.URL() != "https://example.org" || upd.UserID() != alice || !upd.CreatedAt().Equal(e.CreatedAt()) {
+		t.Fatalf("updated endpoint = %+v, want secret and created_at kept", upd)
+	}
+	if _, err := svc.Update(ctx, e.ID(), service.WebhookInput{UserID: alice, URL: "http://[::1]/hook", EventTypes: created}); !errors.Is(err, netguard.ErrForbiddenTarget) {
+		t.Fatalf("Update to loopback: got %v, want ErrForbiddenTarget", err)
+	}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryWebhookRepository()
+	users := repository.NewMemoryUserRepository()
+	svc := service.NewWebhookService(repo, users)
+	const secret = "0123456789abcdef"
+	hook := newHookServer(t, secret)
+	alice := newWebhookUser(t, users)
+
+	e, err := svc.Create(ctx, service.WebhookInput{UserID: alice, URL: hookURL, Secret: secret, EventTypes: []string{service.EventSubscriptionCreated}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dispatcher := service.NewWebhookDispatcher(repo)
+	created := userEvent(service.EventSubscriptionCreated, alice)
+	// Повторная публикация тем же relay не дублирует доставку, а события
+	// без подписчиков и события других пользователей не создают её вовсе.
+	for _, ev := range []outbox.Event{
+		created,
+		created,
+		userEvent(service.EventSubscriptionDeleted, alice),
+		userEvent(service.EventSubscriptionCreated, uuid.New()),
+	} {
+		if err := dispatcher.Publish(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sender := service.NewWebhookSender(repo, service.WebhookSenderConfig{
+		BatchSize:   10,
+		Timeout:     time.Second,
+		MaxAttempts: 2,
+		RetryMin:    time.Millisecond,
+		RetryMax:    time.Millisecond,
+		Transport:   hook.transport(),
+	})
+
+	hook.setStatus(http.StatusServiceUnavailable)
+	for i := 0; i < 2; i++ {
+		if n, err := sender.RunOnce(ctx); err != nil || n != 1 {
+			t.Fatalf("attempt %d: RunOnce = %d, %v, want 1", i+1, n, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// Попытки исчерпаны, доставка больше не выбирается.
+	if n, err := sender.RunOnce(ctx); err != nil || n != 0 {
+		t.Fatalf("RunOnce after failure = %d, %v, want 0", n, err)
+	}
+
+	deliveries, total, err := svc.Deliveries(ctx, e.ID(), domain.NewPagingBase(10, 0))
+	if err != nil || total != 1 {
+		t.Fatalf("Deliveries = %d, %v, want 1", total, err)
+	}
+	d := deliveries[0]
+	if d.Status != webhook.StatusFailed || d.Attempts != 2 || len(d.Log) != 2 || *d.Log[1].StatusCode != 503 {
+		t.Fatalf("delivery after failures = %+v", d)
+	}
+
+	hook.setStatus(http.StatusNoContent)
+	if _, err := svc.Redeliver(ctx, e.ID(), d.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := sender.RunOnce(ctx); err != nil || n != 1 {
+		t.Fatalf("RunOnce after redeliver = %d, %v, want 1", n, err)
+	}
+
+	deliveries, _, err = svc.Deliveries(ctx, e.ID(), domain.NewPagingBase(10, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := deliveries[0]; d.Status != webhook.StatusSucceeded || d.DeliveredAt == nil || len(d.Log) != 3 {
+		t.Fatalf("delivery after redeliver = %+v", d)
+	}
+
+	if len(hook.got) != 3 {
+		t.Fatalf("endpoint received %d signed requests, want 3", len(hook.got))
+	}
+	h := hook.got[2]
+	if h.Get(outbox.HeaderEventID) != created.ID.String() || h.Get(outbox.HeaderEventType) != created.Type ||
+		h.Get(webhook.HeaderDelivery) != d.ID.String() {
+		t.Fatalf("headers = %v", h)
+	}
+	if !strings.Contains(hook.bodies[2], `"type":"SubscriptionCreated"`) {
+		t.Fatalf("body = %s", hook.bodies[2])
+	}
+}
+
+// TestWebhookSenderRefusesPrivate: адрес проверяется и при соединении, так
+// что endpoint, чьё имя позже стало указывать во внутреннюю сеть, ничего
+// туда не отправит.
+func TestWebhookSenderRefusesPrivate(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryWebhookRepository()
+	const secret = "0123456789abcdef"
+	hook := newHookServer(t, secret)
+	alice := uuid.New()
+
+	// В обход NewEndpoint: так выглядит endpoint после DNS rebinding.
+	e := webhook.RestoreEndpoint(uuid.New(), alice, hook.URL, secret, []string{service.EventSubscriptionCreated}, time.Now())
+	if err := repo.CreateEndpoint(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.NewWebhookDispatcher(repo).Publish(ctx, userEvent(service.EventSubscriptionCreated, alice)); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := service.NewWebhookSender(repo, service.WebhookSenderConfig{
+		BatchSize:   10,
+		Timeout:     time.Second,
+		MaxAttempts: 1,
+		RetryMin:    time.Millisecond,
+		RetryMax:    time.Millisecond,
+	})
+	if n, err := sender.RunOnce(ctx); err != nil || n != 1 {
+		t.Fatalf("RunOnce = %d, %v, want 1", n, err)
+	}
+
+	deliveries, _, err := repo.ListDeliveries(ctx, e.ID(), domain.NewPagingBase(10, 0))
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("ListDeliveries = %d, %v, want 1", len(deliveries), err)
+	}
+	d := deliveries[0]
+	if d.Status != webhook.StatusFailed || len(d.Log) != 1 || d.Log[0].Error == nil ||
+		!strings.Contains(*d.Log[0].Error, netguard.ErrForbiddenTarget.Error()) {
+		t.Fatalf("delivery = %+v, want failed with a forbidden target error", d)
+	}
+	if len(hook.got) != 0 {
+		t.Fatalf("loopback server received %d requests", len(hook.got))
+	}
+}
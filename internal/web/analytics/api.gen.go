@@ -0,0 +1,355 @@
+// Package analytics provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.5.1 DO NOT EDIT.
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oapi-codegen/runtime"
+	strictecho "github.com/oapi-codegen/runtime/strictmiddleware/echo"
+)
+
+// ChurnPoint defines model for ChurnPoint.
+type ChurnPoint struct {
+	// Active Подписчики в этом месяце
+	Active    int     `json:"active"`
+	ChurnRate float64 `json:"churn_rate"`
+
+	// Churned Подписчики прошлого месяца, которых нет в этом
+	Churned int    `json:"churned"`
+	Month   string `json:"month"`
+
+	// Subscribers Подписчики в прошлом месяце
+	Subscribers int `json:"subscribers"`
+}
+
+// ChurnReport defines model for ChurnReport.
+type ChurnReport struct {
+	From     string         `json:"from"`
+	Services []ServiceChurn `json:"services"`
+	To       string         `json:"to"`
+}
+
+// ErrorResponse defines model for ErrorResponse.
+type ErrorResponse struct {
+	Error string `json:"error"`
+
+	// RequestId Идентификатор запроса (X-Request-ID)
+	RequestId *string `json:"request_id,omitempty"`
+}
+
+// MRRPoint defines model for MRRPoint.
+type MRRPoint struct {
+	ActiveSubscribers   int `json:"active_subscribers"`
+	ActiveSubscriptions int `json:"active_subscriptions"`
+
+	// Churned MRR прошлого месяца пользователей, у которых не осталось подписок
+	Churned int `json:"churned"`
+
+	// Contraction Снижение MRR остальных пользователей
+	Contraction int `json:"contraction"`
+
+	// Expansion Рост MRR остальных пользователей
+	Expansion int    `json:"expansion"`
+	Month     string `json:"month"`
+	Mrr       int    `json:"mrr"`
+
+	// NetNew new + expansion - contraction - churned, равно изменению MRR
+	NetNew int `json:"net_new"`
+
+	// New MRR пользователей без подписок в прошлом месяце
+	New int `json:"new"`
+}
+
+// MRRReport defines model for MRRReport.
+type MRRReport struct {
+	From        string     `json:"from"`
+	Points      []MRRPoint `json:"points"`
+	ServiceName *string    `json:"service_name,omitempty"`
+	To          string     `json:"to"`
+}
+
+// ServiceChurn defines model for ServiceChurn.
+type ServiceChurn struct {
+	// ChurnRate Средний месячный отток, churned / subscribers
+	ChurnRate   float64      `json:"churn_rate"`
+	Churned     int          `json:"churned"`
+	Points      []ChurnPoint `json:"points"`
+	ServiceName string       `json:"service_name"`
+
+	// Subscribers Сумма подписчиков на начало каждого месяца
+	Subscribers int `json:"subscribers"`
+}
+
+// GetChurnParams defines parameters for GetChurn.
+type GetChurnParams struct {
+	// From First month (MM-YYYY), 11 months before to by default
+	From *string `form:"from,omitempty" json:"from,omitempty"`
+
+	// To Last month (MM-YYYY), the current one by default
+	To *string `form:"to,omitempty" json:"to,omitempty"`
+}
+
+// GetMRRParams defines parameters for GetMRR.
+type GetMRRParams struct {
+	// From First month (MM-YYYY), 11 months before to by default
+	From *string `form:"from,omitempty" json:"from,omitempty"`
+
+	// To Last month (MM-YYYY), the current one by default
+	To *string `form:"to,omitempty" json:"to,omitempty"`
+
+	// ServiceName Count only subscriptions to this service
+	ServiceName *string `form:"service_name,omitempty" json:"service_name,omitempty"`
+}
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Subscriber churn per service
+	// (GET /analytics/churn)
+	GetChurn(ctx echo.Context, params GetChurnParams) error
+	// Monthly recurring revenue and its movement
+	// (GET /analytics/mrr)
+	GetMRR(ctx echo.Context, params GetMRRParams) error
+}
+
+// ServerInterfaceWrapper converts echo contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler ServerInterface
+}
+
+// GetChurn converts echo context to params.
+func (w *ServerInterfaceWrapper) GetChurn(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetChurnParams
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", ctx.QueryParams(), &params.From)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter from: %s", err))
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", ctx.QueryParams(), &params.To)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter to: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetChurn(ctx, params)
+	return err
+}
+
+// GetMRR converts echo context to params.
+func (w *ServerInterfaceWrapper) GetMRR(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetMRRParams
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", ctx.QueryParams(), &params.From)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter from: %s", err))
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", ctx.QueryParams(), &params.To)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter to: %s", err))
+	}
+
+	// ------------- Optional query parameter "service_name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "service_name", ctx.QueryParams(), &params.ServiceName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter service_name: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetMRR(ctx, params)
+	return err
+}
+
+// This is a simple interface which specifies echo.Route addition functions which
+// are present on both echo.Echo and echo.Group, since we want to allow using
+// either of them for path registration
+type EchoRouter interface {
+	CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// RegisterHandlers adds each server route to the EchoRouter.
+func RegisterHandlers(router EchoRouter, si ServerInterface) {
+	RegisterHandlersWithBaseURL(router, si, "")
+}
+
+// Registers handlers, and prepends BaseURL to the paths, so that the paths
+// can be served under a prefix.
+func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL string) {
+
+	wrapper := ServerInterfaceWrapper{
+		Handler: si,
+	}
+
+	router.GET(baseURL+"/analytics/churn", wrapper.GetChurn)
+	router.GET(baseURL+"/analytics/mrr", wrapper.GetMRR)
+
+}
+
+type GetChurnRequestObject struct {
+	Params GetChurnParams
+}
+
+type GetChurnResponseObject interface {
+	VisitGetChurnResponse(w http.ResponseWriter) error
+}
+
+type GetChurn200JSONResponse ChurnReport
+
+func (response GetChurn200JSONResponse) VisitGetChurnResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetChurn400JSONResponse ErrorResponse
+
+func (response GetChurn400JSONResponse) VisitGetChurnResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetChurn500JSONResponse ErrorResponse
+
+func (response GetChurn500JSONResponse) VisitGetChurnResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMRRRequestObject struct {
+	Params GetMRRParams
+}
+
+type GetMRRResponseObject interface {
+	VisitGetMRRResponse(w http.ResponseWriter) error
+}
+
+type GetMRR200JSONResponse MRRReport
+
+func (response GetMRR200JSONResponse) VisitGetMRRResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMRR400JSONResponse ErrorResponse
+
+func (response GetMRR400JSONResponse) VisitGetMRRResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMRR500JSONResponse ErrorResponse
+
+func (response GetMRR500JSONResponse) VisitGetMRRResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+// StrictServerInterface represents all server handlers.
+type StrictServerInterface interface {
+	// Subscriber churn per service
+	// (GET /analytics/churn)
+	GetChurn(ctx context.Context, request GetChurnRequestObject) (GetChurnResponseObject, error)
+	// Monthly recurring revenue and its movement
+	// (GET /analytics/mrr)
+	GetMRR(ctx context.Context, request GetMRRRequestObject) (GetMRRResponseObject, error)
+}
+
+type StrictHandlerFunc = strictecho.StrictEchoHandlerFunc
+type StrictMiddlewareFunc = strictecho.StrictEchoMiddlewareFunc
+
+func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares}
+}
+
+type strictHandler struct {
+	ssi         StrictServerInterface
+	middlewares []StrictMiddlewareFunc
+}
+
+// GetChurn operation middleware
+func (sh *strictHandler) GetChurn(ctx echo.Context, params GetChurnParams) error {
+	var request GetChurnRequestObject
+
+	request.Params = params
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetChurn(ctx.Request().Context(), request.(GetChurnRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetChurn")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetChurnResponseObject); ok {
+		return validResponse.VisitGetChurnResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetMRR operation middleware
+func (sh *strictHandler) GetMRR(ctx echo.Context, params GetMRRParams) error {
+	var request GetMRRRequestObject
+
+	request.Params = params
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetMRR(ctx.Request().Context(), request.(GetMRRRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetMRR")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetMRRResponseObject); ok {
+		return validResponse.VisitGetMRRResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+// Package reminders provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.5.1 DO NOT EDIT.
+package reminders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/oapi-codegen/runtime"
+	strictecho "github.com/oapi-codegen/runtime/strictmiddleware/echo"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// Defines values for ReminderPreferencesChannel.
+const (
+	ReminderPreferencesChannelEmail   ReminderPreferencesChannel = "email"
+	ReminderPreferencesChannelLog     ReminderPreferencesChannel = "log"
+	ReminderPreferencesChannelWebhook ReminderPreferencesChannel = "webhook"
+)
+
+// Defines values for ReminderPreferencesRequestChannel.
+const (
+	ReminderPreferencesRequestChannelEmail   ReminderPreferencesRequestChannel = "email"
+	ReminderPreferencesRequestChannelLog     ReminderPreferencesRequestChannel = "log"
+	ReminderPreferencesRequestChannelWebhook ReminderPreferencesRequestChannel = "webhook"
+)
+
+// ErrorResponse defines model for ErrorResponse.
+type ErrorResponse struct {
+	Error string `json:"error"`
+
+	// RequestId Идентификатор запроса (X-Request-ID)
+	RequestId *string `json:"request_id,omitempty"`
+}
+
+// ReminderPreferences defines model for ReminderPreferences.
+type ReminderPreferences struct {
+	// Channel Канал доставки. email доступен, только если на сервере настроен SMTP
+	Channel ReminderPreferencesChannel `json:"channel"`
+
+	// DaysBefore За сколько дней до окончания подписки напоминать
+	DaysBefore int `json:"days_before"`
+
+	// Email Адрес для канала email
+	Email *string `json:"email"`
+
+	// Enabled Отправлять ли напоминания
+	Enabled bool               `json:"enabled"`
+	UserId  openapi_types.UUID `json:"user_id"`
+
+	// WebhookUrl Адрес для канала webhook (http или https)
+	WebhookUrl *string `json:"webhook_url"`
+}
+
+// ReminderPreferencesChannel Канал доставки. email доступен, только если на сервере настроен SMTP
+type ReminderPreferencesChannel string
+
+// ReminderPreferencesRequest defines model for ReminderPreferencesRequest.
+type ReminderPreferencesRequest struct {
+	// Channel Канал доставки. email доступен, только если на сервере настроен SMTP
+	Channel ReminderPreferencesRequestChannel `json:"channel"`
+
+	// DaysBefore За сколько дней до окончания подписки напоминать
+	DaysBefore int `json:"days_before"`
+
+	// Email Адрес для канала email
+	Email *string `json:"email"`
+
+	// Enabled Отправлять ли напоминания
+	Enabled bool `json:"enabled"`
+
+	// WebhookUrl Адрес для канала webhook (http или https)
+	WebhookUrl *string `json:"webhook_url"`
+}
+
+// ReminderPreferencesRequestChannel Канал доставки. email доступен, только если на сервере настроен SMTP
+type ReminderPreferencesRequestChannel string
+
+// SaveReminderPreferencesJSONRequestBody defines body for SaveReminderPreferences for application/json ContentType.
+type SaveReminderPreferencesJSONRequestBody = ReminderPreferencesRequest
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Get reminder preferences of a user
+	// (GET /users/{user_id}/reminder-preferences)
+	GetReminderPreferences(ctx echo.Context, userId openapi_types.UUID) error
+	// Save reminder preferences of a user
+	// (PUT /users/{user_id}/reminder-preferences)
+	SaveReminderPreferences(ctx echo.Context, userId openapi_types.UUID) error
+}
+
+// ServerInterfaceWrapper converts echo contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler ServerInterface
+}
+
+// GetReminderPreferences converts echo context to params.
+func (w *ServerInterfaceWrapper) GetReminderPreferences(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "user_id" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "user_id", ctx.Param("user_id"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter user_id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetReminderPreferences(ctx, userId)
+	return err
+}
+
+// SaveReminderPreferences converts echo context to params.
+func (w *ServerInterfaceWrapper) SaveReminderPreferences(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "user_id" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "user_id", ctx.Param("user_id"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter user_id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.SaveReminderPreferences(ctx, userId)
+	return err
+}
+
+// This is a simple interface which specifies echo.Route addition functions which
+// are present on both echo.Echo and echo.Group, since we want to allow using
+// either of them for path registration
+type EchoRouter interface {
+	CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// RegisterHandlers adds each server route to the EchoRouter.
+func RegisterHandlers(router EchoRouter, si ServerInterface) {
+	RegisterHandlersWithBaseURL(router, si, "")
+}
+
+// Registers handlers, and prepends BaseURL to the paths, so that the paths
+// can be served under a prefix.
+func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL string) {
+
+	wrapper := ServerInterfaceWrapper{
+		Handler: si,
+	}
+
+	router.GET(baseURL+"/users/:user_id/reminder-preferences", wrapper.GetReminderPreferences)
+	router.PUT(baseURL+"/users/:user_id/reminder-preferences", wrapper.SaveReminderPreferences)
+
+}
+
+type GetReminderPreferencesRequestObject struct {
+	UserId openapi_types.UUID `json:"user_id"`
+}
+
+type GetReminderPreferencesResponseObject interface {
+	VisitGetReminderPreferencesResponse(w http.ResponseWriter) error
+}
+
+type GetReminderPreferences200JSONResponse ReminderPreferences
+
+func (response GetReminderPreferences200JSONResponse) VisitGetReminderPreferencesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetReminderPreferences500JSONResponse ErrorResponse
+
+func (response GetReminderPreferences500JSONResponse) VisitGetReminderPreferencesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SaveReminderPreferencesRequestObject struct {
+	UserId openapi_types.UUID `json:"user_id"`
+	Body   *SaveReminderPreferencesJSONRequestBody
+}
+
+type SaveReminderPreferencesResponseObject interface {
+	VisitSaveReminderPreferencesResponse(w http.ResponseWriter) error
+}
+
+type SaveReminderPreferences200JSONResponse ReminderPreferences
+
+func (response SaveReminderPreferences200JSONResponse) VisitSaveReminderPreferencesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SaveReminderPreferences400JSONResponse ErrorResponse
+
+func (response SaveReminderPreferences400JSONResponse) VisitSaveReminderPreferencesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SaveReminderPreferences500JSONResponse ErrorResponse
+
+func (response SaveReminderPreferences500JSONResponse) VisitSaveReminderPreferencesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+// StrictServerInterface represents all server handlers.
+type StrictServerInterface interface {
+	// Get reminder preferences of a user
+	// (GET /users/{user_id}/reminder-preferences)
+	GetReminderPreferences(ctx context.Context, request GetReminderPreferencesRequestObject) (GetReminderPreferencesResponseObject, error)
+	// Save reminder preferences of a user
+	// (PUT /users/{user_id}/reminder-preferences)
+	SaveReminderPreferences(ctx context.Context, request SaveReminderPreferencesRequestObject) (SaveReminderPreferencesResponseObject, error)
+}
+
+type StrictHandlerFunc = strictecho.StrictEchoHandlerFunc
+type StrictMiddlewareFunc = strictecho.StrictEchoMiddlewareFunc
+
+func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares}
+}
+
+type strictHandler struct {
+	ssi         StrictServerInterface
+	middlewares []StrictMiddlewareFunc
+}
+
+// GetReminderPreferences operation middleware
+func (sh *strictHandler) GetReminderPreferences(ctx echo.Context, userId openapi_types.UUID) error {
+	var request GetReminderPreferencesRequestObject
+
+	request.UserId = userId
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetReminderPreferences(ctx.Request().Context(), request.(GetReminderPreferencesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetReminderPreferences")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetReminderPreferencesResponseObject); ok {
+		return validResponse.VisitGetReminderPreferencesResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// SaveReminderPreferences operation middleware
+func (sh *strictHandler) SaveReminderPreferences(ctx echo.Context, userId openapi_types.UUID) error {
+	var request SaveReminderPreferencesRequestObject
+
+	request.UserId = userId
+
+	var body SaveReminderPreferencesJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return err
+	}
+	request.Body = &body
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.SaveReminderPreferences(ctx.Request().Context(), request.(SaveReminderPreferencesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "SaveReminderPreferences")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(SaveReminderPreferencesResponseObject); ok {
+		return validResponse.VisitSaveReminderPreferencesResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
@@ -15,9 +15,81 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for ForecastGroupBy.
+const (
+	ForecastGroupByService ForecastGroupBy = "service"
+	ForecastGroupByUser    ForecastGroupBy = "user"
+)
+
+// Defines values for SubscriptionMembersSplit.
+const (
+	SubscriptionMembersSplitEqual   SubscriptionMembersSplit = "equal"
+	SubscriptionMembersSplitFixed   SubscriptionMembersSplit = "fixed"
+	SubscriptionMembersSplitPercent SubscriptionMembersSplit = "percent"
+)
+
+// Defines values for SubscriptionMembersRequestSplit.
+const (
+	SubscriptionMembersRequestSplitEqual   SubscriptionMembersRequestSplit = "equal"
+	SubscriptionMembersRequestSplitFixed   SubscriptionMembersRequestSplit = "fixed"
+	SubscriptionMembersRequestSplitPercent SubscriptionMembersRequestSplit = "percent"
+)
+
+// Defines values for ListParamsTagMatch.
+const (
+	ListParamsTagMatchAll ListParamsTagMatch = "all"
+	ListParamsTagMatchAny ListParamsTagMatch = "any"
+)
+
+// Defines values for ForecastParamsGroupBy.
+const (
+	ForecastParamsGroupByService ForecastParamsGroupBy = "service"
+	ForecastParamsGroupByUser    ForecastParamsGroupBy = "user"
+)
+
+// Defines values for SumParamsTagMatch.
+const (
+	SumParamsTagMatchAll SumParamsTagMatch = "all"
+	SumParamsTagMatchAny SumParamsTagMatch = "any"
+)
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
 	Error string `json:"error"`
+
+	// RequestId Идентификатор запроса (X-Request-ID)
+	RequestId *string `json:"request_id,omitempty"`
+}
+
+// Forecast defines model for Forecast.
+type Forecast struct {
+	// From Первый месяц прогноза
+	From    string           `json:"from"`
+	GroupBy *ForecastGroupBy `json:"group_by,omitempty"`
+	Months  int              `json:"months"`
+
+	// Series Ряды по group_by, отсортированы по key. Без group_by пуст
+	Series []ForecastSeries `json:"series"`
+	Total  ForecastSeries   `json:"total"`
+}
+
+// ForecastGroupBy defines model for Forecast.GroupBy.
+type ForecastGroupBy string
+
+// ForecastPoint defines model for ForecastPoint.
+type ForecastPoint struct {
+	Amount int    `json:"amount"`
+	Month  string `json:"month"`
+}
+
+// ForecastSeries defines model for ForecastSeries.
+type ForecastSeries struct {
+	// Key user_id или service_name ряда, у общего ряда отсутствует
+	Key    *string         `json:"key,omitempty"`
+	Points []ForecastPoint `json:"points"`
+
+	// Total Траты ряда за весь прогноз
+	Total int `json:"total"`
 }
 
 // Paging defines model for Paging.
@@ -40,33 +112,109 @@ type Subscription struct {
 	// Id ID подписки
 	Id openapi_types.UUID `json:"id"`
 
+	// Metadata Произвольные данные пользователя
+	Metadata map[string]interface{} `json:"metadata"`
+
 	// Price Стоимость месячной подписки в рублях
 	Price int `json:"price"`
 
+	// ServiceId ID сервиса из каталога, null — названия нет в каталоге
+	ServiceId *openapi_types.UUID `json:"service_id"`
+
 	// ServiceName Название сервиса, предоставляющего подписку
 	ServiceName string `json:"service_name"`
 
+	// Share В /subscriptions/sum с user_id — доля пользователя в общей подписке; в total_sum идёт она, а не price
+	Share *int `json:"share,omitempty"`
+
 	// StartDate Дата начала подписки (месяц и год)
 	StartDate string `json:"start_date"`
 
+	// Tags Теги подписки в нижнем регистре, по алфавиту
+	Tags []string `json:"tags"`
+
 	// UserId ID пользователя
 	UserId openapi_types.UUID `json:"user_id"`
 }
 
+// SubscriptionMember defines model for SubscriptionMember.
+type SubscriptionMember struct {
+	// Amount Сумма участника при split=fixed, у владельца — остаток
+	Amount *int `json:"amount,omitempty"`
+	Owner  bool `json:"owner"`
+
+	// Percent Процент участника при split=percent, у владельца — остаток
+	Percent *float64 `json:"percent,omitempty"`
+
+	// Share Сколько участник платит в месяц
+	Share  int                `json:"share"`
+	UserId openapi_types.UUID `json:"user_id"`
+}
+
+// SubscriptionMemberRequest defines model for SubscriptionMemberRequest.
+type SubscriptionMemberRequest struct {
+	// Amount Сумма для split=fixed
+	Amount *int `json:"amount,omitempty"`
+
+	// Percent Процент цены для split=percent, до сотых
+	Percent *float64 `json:"percent,omitempty"`
+
+	// UserId ID пользователя
+	UserId openapi_types.UUID `json:"user_id"`
+}
+
+// SubscriptionMembers defines model for SubscriptionMembers.
+type SubscriptionMembers struct {
+	// Members Участники, владелец первым. У не общей подписки — только владелец с полной ценой
+	Members []SubscriptionMember `json:"members"`
+
+	// OwnerId Владелец — user_id подписки, платит полную цену сервису
+	OwnerId openapi_types.UUID `json:"owner_id"`
+	Price   int                `json:"price"`
+
+	// Split Правило разделения, нет — подписка не общая
+	Split          *SubscriptionMembersSplit `json:"split,omitempty"`
+	SubscriptionId openapi_types.UUID        `json:"subscription_id"`
+}
+
+// SubscriptionMembersSplit Правило разделения, нет — подписка не общая
+type SubscriptionMembersSplit string
+
+// SubscriptionMembersRequest defines model for SubscriptionMembersRequest.
+type SubscriptionMembersRequest struct {
+	// Members Участники кроме владельца (до 19). Владелец участвует всегда и платит остаток; если он указан с percent или amount, доли должны сойтись с ценой точно
+	Members []SubscriptionMemberRequest `json:"members"`
+
+	// Split Правило разделения цены — поровну, по процентам или фиксированными суммами
+	Split SubscriptionMembersRequestSplit `json:"split"`
+}
+
+// SubscriptionMembersRequestSplit Правило разделения цены — поровну, по процентам или фиксированными суммами
+type SubscriptionMembersRequestSplit string
+
 // SubscriptionRequest defines model for SubscriptionRequest.
 type SubscriptionRequest struct {
 	// EndDate Дата окончания подписки (опционально)
 	EndDate *string `json:"end_date"`
 
+	// Metadata Произвольный JSON-объект до 4 КБ и 50 ключей; ключи — латиница, цифры, - и _
+	Metadata *map[string]interface{} `json:"metadata,omitempty"`
+
 	// Price Стоимость месячной подписки в рублях
 	Price int `json:"price"`
 
+	// ServiceId ID сервиса из каталога. Без него сервис ищется по service_name; найденный сервис заменяет service_name своим каноническим именем
+	ServiceId *openapi_types.UUID `json:"service_id"`
+
 	// ServiceName Название сервиса, предоставляющего подписку
 	ServiceName string `json:"service_name"`
 
 	// StartDate Дата начала подписки (месяц и год)
 	StartDate string `json:"start_date"`
 
+	// Tags Теги — до 20 штук, каждый до 50 букв, цифр, - или _. Сохраняются в нижнем регистре без повторов
+	Tags *[]string `json:"tags,omitempty"`
+
 	// UserId ID пользователя
 	UserId openapi_types.UUID `json:"user_id"`
 }
@@ -78,11 +226,41 @@ type ListParams struct {
 
 	// Offset Offset subscription items
 	Offset int `form:"offset" json:"offset"`
+
+	// Tag Тег подписки, параметр повторяется. Какие подписки подходят, задаёт tag_match
+	Tag *[]string `form:"tag,omitempty" json:"tag,omitempty"`
+
+	// TagMatch any — есть хотя бы один из тегов, all — есть все
+	TagMatch *ListParamsTagMatch `form:"tag_match,omitempty" json:"tag_match,omitempty"`
+
+	// Metadata Условие на metadata, параметр повторяется. key — ключ есть, key:value — значение ключа строка value
+	Metadata *[]string `form:"metadata,omitempty" json:"metadata,omitempty"`
+}
+
+// ListParamsTagMatch defines parameters for List.
+type ListParamsTagMatch string
+
+// ForecastParams defines parameters for Forecast.
+type ForecastParams struct {
+	// Months Number of months to forecast, starting with the current one
+	Months *int `form:"months,omitempty" json:"months,omitempty"`
+
+	// UserId User ID
+	UserId *openapi_types.UUID `form:"user_id,omitempty" json:"user_id,omitempty"`
+
+	// ServiceName Service name
+	ServiceName *string `form:"service_name,omitempty" json:"service_name,omitempty"`
+
+	// GroupBy Split the forecast into series per user or per service
+	GroupBy *ForecastParamsGroupBy `form:"group_by,omitempty" json:"group_by,omitempty"`
 }
 
+// ForecastParamsGroupBy defines parameters for Forecast.
+type ForecastParamsGroupBy string
+
 // SumParams defines parameters for Sum.
 type SumParams struct {
-	// UserId User ID
+	// UserId User ID. Includes shared subscriptions the user is a member of, counted by the user's share
 	UserId *openapi_types.UUID `form:"user_id,omitempty" json:"user_id,omitempty"`
 
 	// ServiceName Service name
@@ -99,14 +277,29 @@ type SumParams struct {
 
 	// Offset Offset subscriptions
 	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// Tag Тег подписки, параметр повторяется. Какие подписки подходят, задаёт tag_match
+	Tag *[]string `form:"tag,omitempty" json:"tag,omitempty"`
+
+	// TagMatch any — есть хотя бы один из тегов, all — есть все
+	TagMatch *SumParamsTagMatch `form:"tag_match,omitempty" json:"tag_match,omitempty"`
+
+	// Metadata Условие на metadata, параметр повторяется. key — ключ есть, key:value — значение ключа строка value
+	Metadata *[]string `form:"metadata,omitempty" json:"metadata,omitempty"`
 }
 
+// SumParamsTagMatch defines parameters for Sum.
+type SumParamsTagMatch string
+
 // CreateJSONRequestBody defines body for Create for application/json ContentType.
 type CreateJSONRequestBody = SubscriptionRequest
 
 // UpdateJSONRequestBody defines body for Update for application/json ContentType.
 type UpdateJSONRequestBody = SubscriptionRequest
 
+// SetMembersJSONRequestBody defines body for SetMembers for application/json ContentType.
+type SetMembersJSONRequestBody = SubscriptionMembersRequest
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 	// List subscriptions
@@ -115,6 +308,9 @@ type ServerInterface interface {
 	// Create subscription
 	// (POST /subscriptions)
 	Create(ctx echo.Context) error
+	// Forecast monthly subscriptions spend
+	// (GET /subscriptions/forecast)
+	Forecast(ctx echo.Context, params ForecastParams) error
 	// List subscriptions with sum prices and filters
 	// (GET /subscriptions/sum)
 	Sum(ctx echo.Context, params SumParams) error
@@ -127,6 +323,18 @@ type ServerInterface interface {
 	// Update subscription by id
 	// (PUT /subscriptions/{id})
 	Update(ctx echo.Context, id openapi_types.UUID) error
+	// Stop sharing a subscription
+	// (DELETE /subscriptions/{id}/members)
+	Unshare(ctx echo.Context, id openapi_types.UUID) error
+	// Get members of a shared subscription
+	// (GET /subscriptions/{id}/members)
+	GetMembers(ctx echo.Context, id openapi_types.UUID) error
+	// Share a subscription between members
+	// (PUT /subscriptions/{id}/members)
+	SetMembers(ctx echo.Context, id openapi_types.UUID) error
+	// Remove a member from a shared subscription
+	// (DELETE /subscriptions/{id}/members/{user_id})
+	RemoveMember(ctx echo.Context, id openapi_types.UUID, userId openapi_types.UUID) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -154,6 +362,27 @@ func (w *ServerInterfaceWrapper) List(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
 	}
 
+	// ------------- Optional query parameter "tag" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tag", ctx.QueryParams(), &params.Tag)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter tag: %s", err))
+	}
+
+	// ------------- Optional query parameter "tag_match" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tag_match", ctx.QueryParams(), &params.TagMatch)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter tag_match: %s", err))
+	}
+
+	// ------------- Optional query parameter "metadata" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "metadata", ctx.QueryParams(), &params.Metadata)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter metadata: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.List(ctx, params)
 	return err
@@ -168,6 +397,45 @@ func (w *ServerInterfaceWrapper) Create(ctx echo.Context) error {
 	return err
 }
 
+// Forecast converts echo context to params.
+func (w *ServerInterfaceWrapper) Forecast(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ForecastParams
+	// ------------- Optional query parameter "months" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "months", ctx.QueryParams(), &params.Months)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter months: %s", err))
+	}
+
+	// ------------- Optional query parameter "user_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "user_id", ctx.QueryParams(), &params.UserId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter user_id: %s", err))
+	}
+
+	// ------------- Optional query parameter "service_name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "service_name", ctx.QueryParams(), &params.ServiceName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter service_name: %s", err))
+	}
+
+	// ------------- Optional query parameter "group_by" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "group_by", ctx.QueryParams(), &params.GroupBy)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter group_by: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Forecast(ctx, params)
+	return err
+}
+
 // Sum converts echo context to params.
 func (w *ServerInterfaceWrapper) Sum(ctx echo.Context) error {
 	var err error
@@ -216,6 +484,27 @@ func (w *ServerInterfaceWrapper) Sum(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter offset: %s", err))
 	}
 
+	// ------------- Optional query parameter "tag" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tag", ctx.QueryParams(), &params.Tag)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter tag: %s", err))
+	}
+
+	// ------------- Optional query parameter "tag_match" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tag_match", ctx.QueryParams(), &params.TagMatch)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter tag_match: %s", err))
+	}
+
+	// ------------- Optional query parameter "metadata" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "metadata", ctx.QueryParams(), &params.Metadata)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter metadata: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.Sum(ctx, params)
 	return err
@@ -269,6 +558,78 @@ func (w *ServerInterfaceWrapper) Update(ctx echo.Context) error {
 	return err
 }
 
+// Unshare converts echo context to params.
+func (w *ServerInterfaceWrapper) Unshare(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Unshare(ctx, id)
+	return err
+}
+
+// GetMembers converts echo context to params.
+func (w *ServerInterfaceWrapper) GetMembers(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetMembers(ctx, id)
+	return err
+}
+
+// SetMembers converts echo context to params.
+func (w *ServerInterfaceWrapper) SetMembers(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.SetMembers(ctx, id)
+	return err
+}
+
+// RemoveMember converts echo context to params.
+func (w *ServerInterfaceWrapper) RemoveMember(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "user_id" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "user_id", ctx.Param("user_id"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter user_id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RemoveMember(ctx, id, userId)
+	return err
+}
+
 // This is a simple interface which specifies echo.Route addition functions which
 // are present on both echo.Echo and echo.Group, since we want to allow using
 // either of them for path registration
@@ -299,10 +660,15 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 
 	router.GET(baseURL+"/subscriptions", wrapper.List)
 	router.POST(baseURL+"/subscriptions", wrapper.Create)
+	router.GET(baseURL+"/subscriptions/forecast", wrapper.Forecast)
 	router.GET(baseURL+"/subscriptions/sum", wrapper.Sum)
 	router.DELETE(baseURL+"/subscriptions/:id", wrapper.Delete)
 	router.GET(baseURL+"/subscriptions/:id", wrapper.Get)
 	router.PUT(baseURL+"/subscriptions/:id", wrapper.Update)
+	router.DELETE(baseURL+"/subscriptions/:id/members", wrapper.Unshare)
+	router.GET(baseURL+"/subscriptions/:id/members", wrapper.GetMembers)
+	router.PUT(baseURL+"/subscriptions/:id/members", wrapper.SetMembers)
+	router.DELETE(baseURL+"/subscriptions/:id/members/:user_id", wrapper.RemoveMember)
 
 }
 
@@ -381,6 +747,15 @@ func (response Create400JSONResponse) VisitCreateResponse(w http.ResponseWriter)
 	return json.NewEncoder(w).Encode(response)
 }
 
+type Create409JSONResponse ErrorResponse
+
+func (response Create409JSONResponse) VisitCreateResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 type Create500JSONResponse ErrorResponse
 
 func (response Create500JSONResponse) VisitCreateResponse(w http.ResponseWriter) error {
@@ -390,6 +765,41 @@ func (response Create500JSONResponse) VisitCreateResponse(w http.ResponseWriter)
 	return json.NewEncoder(w).Encode(response)
 }
 
+type ForecastRequestObject struct {
+	Params ForecastParams
+}
+
+type ForecastResponseObject interface {
+	VisitForecastResponse(w http.ResponseWriter) error
+}
+
+type Forecast200JSONResponse Forecast
+
+func (response Forecast200JSONResponse) VisitForecastResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type Forecast400JSONResponse ErrorResponse
+
+func (response Forecast400JSONResponse) VisitForecastResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type Forecast500JSONResponse ErrorResponse
+
+func (response Forecast500JSONResponse) VisitForecastResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 type SumRequestObject struct {
 	Params SumParams
 }
@@ -563,6 +973,15 @@ func (response Update404JSONResponse) VisitUpdateResponse(w http.ResponseWriter)
 	return json.NewEncoder(w).Encode(response)
 }
 
+type Update409JSONResponse ErrorResponse
+
+func (response Update409JSONResponse) VisitUpdateResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 type Update500JSONResponse ErrorResponse
 
 func (response Update500JSONResponse) VisitUpdateResponse(w http.ResponseWriter) error {
@@ -572,6 +991,201 @@ func (response Update500JSONResponse) VisitUpdateResponse(w http.ResponseWriter)
 	return json.NewEncoder(w).Encode(response)
 }
 
+type UnshareRequestObject struct {
+	Id openapi_types.UUID `json:"id"`
+}
+
+type UnshareResponseObject interface {
+	VisitUnshareResponse(w http.ResponseWriter) error
+}
+
+type Unshare204Response struct {
+}
+
+func (response Unshare204Response) VisitUnshareResponse(w http.ResponseWriter) error {
+	w.WriteHeader(204)
+	return nil
+}
+
+type Unshare400JSONResponse ErrorResponse
+
+func (response Unshare400JSONResponse) VisitUnshareResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type Unshare404JSONResponse ErrorResponse
+
+func (response Unshare404JSONResponse) VisitUnshareResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type Unshare409JSONResponse ErrorResponse
+
+func (response Unshare409JSONResponse) VisitUnshareResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type Unshare500JSONResponse ErrorResponse
+
+func (response Unshare500JSONResponse) VisitUnshareResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMembersRequestObject struct {
+	Id openapi_types.UUID `json:"id"`
+}
+
+type GetMembersResponseObject interface {
+	VisitGetMembersResponse(w http.ResponseWriter) error
+}
+
+type GetMembers200JSONResponse SubscriptionMembers
+
+func (response GetMembers200JSONResponse) VisitGetMembersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMembers404JSONResponse ErrorResponse
+
+func (response GetMembers404JSONResponse) VisitGetMembersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMembers500JSONResponse ErrorResponse
+
+func (response GetMembers500JSONResponse) VisitGetMembersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetMembersRequestObject struct {
+	Id   openapi_types.UUID `json:"id"`
+	Body *SetMembersJSONRequestBody
+}
+
+type SetMembersResponseObject interface {
+	VisitSetMembersResponse(w http.ResponseWriter) error
+}
+
+type SetMembers200JSONResponse SubscriptionMembers
+
+func (response SetMembers200JSONResponse) VisitSetMembersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetMembers400JSONResponse ErrorResponse
+
+func (response SetMembers400JSONResponse) VisitSetMembersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetMembers404JSONResponse ErrorResponse
+
+func (response SetMembers404JSONResponse) VisitSetMembersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetMembers409JSONResponse ErrorResponse
+
+func (response SetMembers409JSONResponse) VisitSetMembersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type SetMembers500JSONResponse ErrorResponse
+
+func (response SetMembers500JSONResponse) VisitSetMembersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RemoveMemberRequestObject struct {
+	Id     openapi_types.UUID `json:"id"`
+	UserId openapi_types.UUID `json:"user_id"`
+}
+
+type RemoveMemberResponseObject interface {
+	VisitRemoveMemberResponse(w http.ResponseWriter) error
+}
+
+type RemoveMember200JSONResponse SubscriptionMembers
+
+func (response RemoveMember200JSONResponse) VisitRemoveMemberResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RemoveMember400JSONResponse ErrorResponse
+
+func (response RemoveMember400JSONResponse) VisitRemoveMemberResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RemoveMember404JSONResponse ErrorResponse
+
+func (response RemoveMember404JSONResponse) VisitRemoveMemberResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RemoveMember409JSONResponse ErrorResponse
+
+func (response RemoveMember409JSONResponse) VisitRemoveMemberResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RemoveMember500JSONResponse ErrorResponse
+
+func (response RemoveMember500JSONResponse) VisitRemoveMemberResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 // StrictServerInterface represents all server handlers.
 type StrictServerInterface interface {
 	// List subscriptions
@@ -580,6 +1194,9 @@ type StrictServerInterface interface {
 	// Create subscription
 	// (POST /subscriptions)
 	Create(ctx context.Context, request CreateRequestObject) (CreateResponseObject, error)
+	// Forecast monthly subscriptions spend
+	// (GET /subscriptions/forecast)
+	Forecast(ctx context.Context, request ForecastRequestObject) (ForecastResponseObject, error)
 	// List subscriptions with sum prices and filters
 	// (GET /subscriptions/sum)
 	Sum(ctx context.Context, request SumRequestObject) (SumResponseObject, error)
@@ -592,6 +1209,18 @@ type StrictServerInterface interface {
 	// Update subscription by id
 	// (PUT /subscriptions/{id})
 	Update(ctx context.Context, request UpdateRequestObject) (UpdateResponseObject, error)
+	// Stop sharing a subscription
+	// (DELETE /subscriptions/{id}/members)
+	Unshare(ctx context.Context, request UnshareRequestObject) (UnshareResponseObject, error)
+	// Get members of a shared subscription
+	// (GET /subscriptions/{id}/members)
+	GetMembers(ctx context.Context, request GetMembersRequestObject) (GetMembersResponseObject, error)
+	// Share a subscription between members
+	// (PUT /subscriptions/{id}/members)
+	SetMembers(ctx context.Context, request SetMembersRequestObject) (SetMembersResponseObject, error)
+	// Remove a member from a shared subscription
+	// (DELETE /subscriptions/{id}/members/{user_id})
+	RemoveMember(ctx context.Context, request RemoveMemberRequestObject) (RemoveMemberResponseObject, error)
 }
 
 type StrictHandlerFunc = strictecho.StrictEchoHandlerFunc
@@ -660,6 +1289,31 @@ func (sh *strictHandler) Create(ctx echo.Context) error {
 	return nil
 }
 
+// Forecast operation middleware
+func (sh *strictHandler) Forecast(ctx echo.Context, params ForecastParams) error {
+	var request ForecastRequestObject
+
+	request.Params = params
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.Forecast(ctx.Request().Context(), request.(ForecastRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Forecast")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(ForecastResponseObject); ok {
+		return validResponse.VisitForecastResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
 // Sum operation middleware
 func (sh *strictHandler) Sum(ctx echo.Context, params SumParams) error {
 	var request SumRequestObject
@@ -765,3 +1419,110 @@ func (sh *strictHandler) Update(ctx echo.Context, id openapi_types.UUID) error {
 	}
 	return nil
 }
+
+// Unshare operation middleware
+func (sh *strictHandler) Unshare(ctx echo.Context, id openapi_types.UUID) error {
+	var request UnshareRequestObject
+
+	request.Id = id
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.Unshare(ctx.Request().Context(), request.(UnshareRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Unshare")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(UnshareResponseObject); ok {
+		return validResponse.VisitUnshareResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetMembers operation middleware
+func (sh *strictHandler) GetMembers(ctx echo.Context, id openapi_types.UUID) error {
+	var request GetMembersRequestObject
+
+	request.Id = id
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetMembers(ctx.Request().Context(), request.(GetMembersRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetMembers")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetMembersResponseObject); ok {
+		return validResponse.VisitGetMembersResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// SetMembers operation middleware
+func (sh *strictHandler) SetMembers(ctx echo.Context, id openapi_types.UUID) error {
+	var request SetMembersRequestObject
+
+	request.Id = id
+
+	var body SetMembersJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return err
+	}
+	request.Body = &body
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.SetMembers(ctx.Request().Context(), request.(SetMembersRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "SetMembers")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(SetMembersResponseObject); ok {
+		return validResponse.VisitSetMembersResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// RemoveMember operation middleware
+func (sh *strictHandler) RemoveMember(ctx echo.Context, id openapi_types.UUID, userId openapi_types.UUID) error {
+	var request RemoveMemberRequestObject
+
+	request.Id = id
+	request.UserId = userId
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.RemoveMember(ctx.Request().Context(), request.(RemoveMemberRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "RemoveMember")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(RemoveMemberResponseObject); ok {
+		return validResponse.VisitRemoveMemberResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+-- Доменные события для доставки во внешние системы (transactional outbox).
+-- seq задаёт порядок доставки, id — ключ идемпотентности у получателей.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    seq BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    aggregate_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT,
+    published_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_pending_idx
+    ON outbox_events (seq) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_events_aggregate_pending_idx
+    ON outbox_events (aggregate_id, seq) WHERE published_at IS NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reminder_notifications;
+DROP TABLE IF EXISTS reminder_preferences;
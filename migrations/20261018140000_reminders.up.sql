@@ -0,0 +1,32 @@
+-- Настройки напоминаний пользователей и журнал отправленных напоминаний.
+CREATE TABLE IF NOT EXISTS reminder_preferences (
+    user_id UUID PRIMARY KEY,
+    enabled BOOLEAN NOT NULL,
+    days_before INTEGER NOT NULL CHECK (days_before BETWEEN 1 AND 90),
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('email', 'webhook', 'log')),
+    email VARCHAR(255),
+    webhook_url VARCHAR(2048),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- id выводится из подписки, вида напоминания и даты события: повторная
+-- проверка после перезапуска или с другого экземпляра попадает в ту же
+-- строку. Строка без sent_at и с истёкшим claimed_until снова доступна
+-- для отправки.
+CREATE TABLE IF NOT EXISTS reminder_notifications (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    subscription_id UUID NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    due_at TIMESTAMP NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    claimed_until TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS reminder_notifications_user_idx
+    ON reminder_notifications (user_id, created_at);
@@ -0,0 +1,14 @@
+-- История запусков фоновых задач планировщика.
+CREATE TABLE IF NOT EXISTS job_runs (
+    id UUID PRIMARY KEY,
+    job VARCHAR(100) NOT NULL,
+    trigger VARCHAR(20) NOT NULL CHECK (trigger IN ('schedule', 'manual')),
+    instance VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS job_runs_job_started_idx
+    ON job_runs (job, started_at DESC);
@@ -0,0 +1,14 @@
+-- Месячные бюджеты пользователей. service_name IS NULL — бюджет на все
+-- подписки пользователя; на пользователя и сервис не больше одного бюджета.
+CREATE TABLE IF NOT EXISTS budgets (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    service_name VARCHAR(100),
+    monthly_limit INTEGER NOT NULL CHECK (monthly_limit > 0),
+    hard_limit BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS budgets_user_service_idx
+    ON budgets (user_id, COALESCE(service_name, ''));
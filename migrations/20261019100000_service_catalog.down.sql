@@ -0,0 +1,5 @@
+-- Исходные написания service_name, заменённые каноническими при
+-- заполнении каталога, не восстанавливаются.
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS service_id;
+DROP TABLE IF EXISTS service_aliases;
+DROP TABLE IF EXISTS services;
@@ -0,0 +1,128 @@
+-- Каталог сервисов. service_aliases хранит ключи сопоставления имени и
+-- синонимов (catalog.Key): по ключу свободный текст service_name
+-- сводится к сервису, поэтому ключ уникален во всём каталоге.
+CREATE TABLE IF NOT EXISTS services (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(50),
+    default_price INTEGER CHECK (default_price > 0),
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    logo_url VARCHAR(500),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS services_category_idx ON services (category);
+
+CREATE TABLE IF NOT EXISTS service_aliases (
+    key VARCHAR(100) PRIMARY KEY,
+    service_id UUID NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+    alias VARCHAR(100) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS service_aliases_service_idx ON service_aliases (service_id);
+
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS service_id UUID REFERENCES services (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS subscriptions_service_id_idx ON subscriptions (service_id);
+
+-- Заполнение каталога из существующих подписок: написания с одним ключом
+-- становятся одним сервисом, его имя — самое частое написание. Ключ
+-- считается так же, как catalog.Key: заглавная кириллица переводится в
+-- строчную отдельно, потому что lower() в локали C её не меняет.
+CREATE TEMP TABLE subscription_keys ON COMMIT DROP AS
+SELECT id, btrim(service_name) AS name,
+       regexp_replace(
+           translate(
+               replace(replace(replace(replace(replace(replace(replace(
+                   lower(translate(service_name,
+                       'АБВГДЕЁЖЗИЙКЛМНОПРСТУФХЦЧШЩЪЫЬЭЮЯ',
+                       'абвгдеёжзийклмнопрстуфхцчшщъыьэюя')),
+                   'кс', 'x'), 'ж', 'zh'), 'ч', 'ch'), 'щ', 'sch'), 'ш', 'sh'), 'ю', 'yu'), 'я', 'ya'),
+               'абвгдеёзийклмнопрстуфхцыэъь',
+               'abvgdeeziyklmnoprstufhcye'),
+           '[^a-z0-9]+', '', 'g') AS key
+FROM subscriptions;
+
+-- Написания с ключами, отличающимися опечаткой, сводятся к одному
+-- сервису: ключи перебираются от самого частого, и ключ на расстоянии
+-- Левенштейна не больше 1 (2 — для ключей от 9 символов) от ключа уже
+-- созданного сервиса становится его синонимом. Ключи короче 5 символов
+-- сводятся только точно: okko и ozon — разные сервисы.
+CREATE EXTENSION IF NOT EXISTS fuzzystrmatch;
+
+CREATE TEMP TABLE key_names ON COMMIT DROP AS
+SELECT key, name, total
+FROM (
+    SELECT key, name,
+           sum(count(*)) OVER (PARTITION BY key) AS total,
+           row_number() OVER (PARTITION BY key ORDER BY count(*) DESC, name) AS rn
+    FROM subscription_keys
+    WHERE key <> ''
+    GROUP BY key, name
+) ranked
+WHERE rn = 1;
+
+CREATE TEMP TABLE service_backfill (id UUID, key TEXT, name TEXT) ON COMMIT DROP;
+CREATE TEMP TABLE alias_backfill (key TEXT, service_key TEXT, name TEXT) ON COMMIT DROP;
+
+DO $$
+DECLARE
+    k RECORD;
+    target TEXT;
+BEGIN
+    FOR k IN SELECT key, name FROM key_names ORDER BY total DESC, key LOOP
+        target := NULL;
+        -- levenshtein принимает строки до 255 символов.
+        IF length(k.key) BETWEEN 5 AND 255 THEN
+            SELECT b.key INTO target
+            FROM service_backfill b
+            JOIN key_names n ON n.key = b.key
+            WHERE length(b.key) BETWEEN 5 AND 255
+              AND levenshtein(b.key, k.key) <=
+                  CASE WHEN least(length(b.key), length(k.key)) >= 9 THEN 2 ELSE 1 END
+            ORDER BY levenshtein(b.key, k.key), n.total DESC, b.key
+            LIMIT 1;
+        END IF;
+
+        IF target IS NULL THEN
+            INSERT INTO service_backfill VALUES (md5(k.key)::uuid, k.key, k.name);
+        ELSE
+            INSERT INTO alias_backfill VALUES (k.key, target, k.name);
+            RAISE LOG 'service catalog: % matched to service key %', quote_literal(k.name), target;
+        END IF;
+    END LOOP;
+END $$;
+
+INSERT INTO services (id, name)
+SELECT id, name FROM service_backfill;
+
+INSERT INTO service_aliases (key, service_id, alias)
+SELECT key, id, name FROM service_backfill
+UNION ALL
+SELECT a.key, b.id, a.name
+FROM alias_backfill a
+JOIN service_backfill b ON b.key = a.service_key;
+
+UPDATE subscriptions s
+SET service_id = b.id, service_name = b.name
+FROM subscription_keys k
+JOIN service_aliases a ON a.key = k.key
+JOIN service_backfill b ON b.id = a.service_id
+WHERE s.id = k.id;
+
+-- Названия без букв и цифр, которые сводятся к ключу, остаются без
+-- сервиса: предупреждение попадает в журнал сервера и клиенту миграции.
+DO $$
+DECLARE
+    unmatched TEXT;
+BEGIN
+    SELECT string_agg(DISTINCT quote_literal(name), ', ') INTO unmatched
+    FROM subscription_keys
+    WHERE key = '';
+
+    IF unmatched IS NOT NULL THEN
+        RAISE WARNING 'service catalog: names left without a service: %', unmatched;
+    END IF;
+END $$;
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS metadata,
+    DROP COLUMN IF EXISTS tags;
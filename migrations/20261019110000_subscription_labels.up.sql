@@ -0,0 +1,8 @@
+-- Теги и произвольная metadata подписок. Индексы GIN (jsonb_ops)
+-- обслуживают фильтры List и Sum: ?| и @> по тегам, ? и @> по metadata.
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]' CHECK (jsonb_typeof(tags) = 'array'),
+    ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof(metadata) = 'object');
+
+CREATE INDEX IF NOT EXISTS subscriptions_tags_idx ON subscriptions USING GIN (tags);
+CREATE INDEX IF NOT EXISTS subscriptions_metadata_idx ON subscriptions USING GIN (metadata);
@@ -0,0 +1,16 @@
+-- Участники общих подписок. Владелец подписки (subscriptions.user_id)
+-- тоже строка таблицы: его percent и amount пусты, share — остаток цены.
+-- Правило разделения следует из строк: есть percent — по процентам, есть
+-- amount — фиксированные суммы, иначе поровну. share хранится, чтобы Sum
+-- считал доли пользователя без пересчёта.
+CREATE TABLE IF NOT EXISTS subscription_members (
+    subscription_id UUID NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    percent NUMERIC(5, 2) CHECK (percent > 0 AND percent <= 100),
+    amount INTEGER CHECK (amount > 0),
+    share INTEGER NOT NULL CHECK (share >= 0),
+    PRIMARY KEY (subscription_id, user_id),
+    CHECK (percent IS NULL OR amount IS NULL)
+);
+
+CREATE INDEX IF NOT EXISTS subscription_members_user_idx ON subscription_members (user_id);
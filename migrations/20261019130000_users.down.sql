@@ -0,0 +1,3 @@
+ALTER TABLE subscription_members DROP CONSTRAINT IF EXISTS subscription_members_user_id_fkey;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_user_id_fkey;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,34 @@
+-- Пользователи. email хранится в нижнем регистре и уникален, валюта и
+-- часовой пояс — значения по умолчанию для отображения трат.
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY,
+    display_name VARCHAR(100) NOT NULL,
+    email VARCHAR(254) UNIQUE,
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Заполнение из всех таблиц, где уже встречается user_id: такие
+-- пользователи получают имя по началу ID, его можно сменить через PUT.
+INSERT INTO users (id, display_name)
+SELECT user_id, 'user ' || left(user_id::text, 8)
+FROM (
+    SELECT user_id FROM subscriptions
+    UNION SELECT user_id FROM subscription_members
+    UNION SELECT user_id FROM budgets
+    UNION SELECT user_id FROM reminder_preferences
+) ids
+ON CONFLICT (id) DO NOTHING;
+
+-- Удаление пользователя с подписками запрещено: подписки удаляет
+-- приложение, чтобы ушли события SubscriptionDeleted, а общие подписки
+-- пересчитали доли.
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
+
+ALTER TABLE subscription_members
+    ADD CONSTRAINT subscription_members_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE RESTRICT;
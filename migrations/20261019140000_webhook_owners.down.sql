@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS webhook_endpoints_user_id_idx;
+ALTER TABLE webhook_endpoints DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,8 @@
+-- Владелец webhook-endpoint'а: endpoint получает только события своего
+-- пользователя и удаляется вместе с ним. У endpoint'ов, созданных раньше,
+-- владельца нет: они ничего не получают, пока user_id не задан через
+-- PUT /webhooks/{id}.
+ALTER TABLE webhook_endpoints
+    ADD COLUMN IF NOT EXISTS user_id UUID REFERENCES users (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS webhook_endpoints_user_id_idx ON webhook_endpoints (user_id);
@@ -0,0 +1,2 @@
+ALTER TABLE reminder_preferences DROP CONSTRAINT IF EXISTS reminder_preferences_user_id_fkey;
+ALTER TABLE budgets DROP CONSTRAINT IF EXISTS budgets_user_id_fkey;
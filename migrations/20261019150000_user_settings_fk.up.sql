@@ -0,0 +1,18 @@
+-- Бюджеты и настройки напоминаний принадлежат пользователю и удаляются
+-- вместе с ним. Строки, записанные после заполнения users, получают
+-- пользователя так же, по началу ID.
+INSERT INTO users (id, display_name)
+SELECT user_id, 'user ' || left(user_id::text, 8)
+FROM (
+    SELECT user_id FROM budgets
+    UNION SELECT user_id FROM reminder_preferences
+) ids
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE budgets
+    ADD CONSTRAINT budgets_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE reminder_preferences
+    ADD CONSTRAINT reminder_preferences_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
@@ -0,0 +1,9 @@
+-- Без категории такие бюджеты стали бы общими и нарушили бы прежний индекс.
+DELETE FROM budgets WHERE category IS NOT NULL;
+
+DROP INDEX IF EXISTS budgets_user_scope_idx;
+ALTER TABLE budgets DROP CONSTRAINT IF EXISTS budgets_scope_check;
+ALTER TABLE budgets DROP COLUMN IF EXISTS category;
+
+CREATE UNIQUE INDEX IF NOT EXISTS budgets_user_service_idx
+    ON budgets (user_id, COALESCE(service_name, ''));
@@ -0,0 +1,10 @@
+-- Бюджет на категорию каталога сервисов: покрывает подписки, связанные
+-- через service_id с сервисом этой категории. Бюджет задаётся либо на
+-- сервис, либо на категорию; на пользователя и область — не больше одного.
+ALTER TABLE budgets
+    ADD COLUMN IF NOT EXISTS category VARCHAR(50),
+    ADD CONSTRAINT budgets_scope_check CHECK (service_name IS NULL OR category IS NULL);
+
+DROP INDEX IF EXISTS budgets_user_service_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS budgets_user_scope_idx
+    ON budgets (user_id, COALESCE(service_name, ''), COALESCE(category, ''));
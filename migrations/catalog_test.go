@@ -0,0 +1,143 @@
+package migrations_test
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"testingtask/internal/migrator"
+	"testingtask/internal/pgtest"
+	"testingtask/migrations"
+
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+const catalogVersion = "20261019100000"
+
+func TestMain(m *testing.M) {
+	pgtest.Main(m)
+}
+
+// upTo применяет миграции с версией меньше version, при inclusive — и
+// саму version.
+func upTo(t *testing.T, db *sql.DB, version string, inclusive bool) {
+	t.Helper()
+
+	entries, err := fs.ReadDir(migrations.FS, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subset := fstest.MapFS{}
+	for _, e := range entries {
+		v, _, _ := strings.Cut(e.Name(), "_")
+		if v < version || (inclusive && v == version) {
+			body, err := fs.ReadFile(migrations.FS, e.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			subset[e.Name()] = &fstest.MapFile{Data: body}
+		}
+	}
+
+	m, err := migrator.New(db, subset, migrator.Postgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up to %s: %v", version, err)
+	}
+}
+
+// TestCatalogBackfill заполняет каталог из подписок, записанных до него.
+// Миграции применяются в отдельной схеме, чтобы не мешать другим тестам
+// той же базы.
+func TestCatalogBackfill(t *testing.T) {
+	dsn := pgtest.DSN(t)
+	ctx := context.Background()
+
+	admin, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+	for _, q := range []string{"DROP SCHEMA IF EXISTS catalog_backfill CASCADE", "CREATE SCHEMA catalog_backfill"} {
+		if _, err := admin.ExecContext(ctx, q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() { _, _ = admin.Exec("DROP SCHEMA IF EXISTS catalog_backfill CASCADE") })
+
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open("pgx", dsn+sep+"search_path=catalog_backfill,public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	upTo(t, db, catalogVersion, false)
+	for _, name := range []string{"Netflix", "Netflix", "netflix ", "Netflx", "Нетфликс", "Spotify Premium", "Spotify Premium", "Spotfy Premum", "Okko", "Ozon", "+++"} {
+		if _, err := db.ExecContext(ctx,
+			"INSERT INTO subscriptions (service_name, price, user_id, start_date) VALUES ($1, 100, $2, '2026-01-01')",
+			name, uuid.New()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	upTo(t, db, catalogVersion, true)
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT sub.service_name, COALESCE(s.name, '')
+		FROM subscriptions sub
+		LEFT JOIN services s ON s.id = sub.service_id
+		ORDER BY sub.service_name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	got := map[string]string{}
+	for rows.Next() {
+		var name, service string
+		if err := rows.Scan(&name, &service); err != nil {
+			t.Fatal(err)
+		}
+		got[name] = service
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Опечатки сводятся к самому частому написанию и переименовываются в
+	// него, короткие ключи — только точно, названия без ключа остаются
+	// без сервиса.
+	want := map[string]string{
+		"Netflix":         "Netflix",
+		"Spotify Premium": "Spotify Premium",
+		"Okko":            "Okko",
+		"Ozon":            "Ozon",
+		"+++":             "",
+	}
+	for name, service := range want {
+		if got[name] != service {
+			t.Errorf("subscription %q linked to %q, want %q (all: %v)", name, got[name], service, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("service names after backfill = %v, want %v", got, want)
+	}
+
+	var aliases int
+	if err := db.QueryRowContext(ctx, `
+		SELECT count(*) FROM service_aliases a JOIN services s ON s.id = a.service_id
+		WHERE s.name = 'Netflix' AND a.key = 'netflx'`).Scan(&aliases); err != nil {
+		t.Fatal(err)
+	}
+	if aliases != 1 {
+		t.Errorf("netflx is not an alias of Netflix")
+	}
+}
@@ -1,6 +1,5 @@
-SELECT 'CREATE DATABASE testingtask'
-WHERE NOT EXISTS (SELECT FROM pg_database WHERE datname = 'testingtask')\gexec
-
+-- Выполняется образом postgres при первой инициализации тома.
+-- База создаётся самим образом из POSTGRES_DB, здесь только расширения.
 CREATE EXTENSION IF NOT EXISTS pgcrypto;
-
-\echo '✅ init.sql completed: database and extensions created'
\ No newline at end of file
+-- levenshtein для заполнения каталога сервисов (миграция service_catalog).
+CREATE EXTENSION IF NOT EXISTS fuzzystrmatch;
@@ -0,0 +1,28 @@
+// Package migrations встраивает SQL-миграции в бинарник.
+// Формат имён совместим с golang-migrate: <version>_<name>.up.sql / .down.sql.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS — миграции для Postgres.
+//
+//go:embed *.up.sql *.down.sql
+var FS embed.FS
+
+//go:embed sqlite/*.up.sql sqlite/*.down.sql
+var sqliteFiles embed.FS
+
+// SQLite — те же миграции для SQLite. Версии должны совпадать с FS,
+// чтобы обе схемы сверялись с одним номером.
+var SQLite = mustSub(sqliteFiles, "sqlite")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
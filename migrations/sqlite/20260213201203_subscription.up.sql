@@ -0,0 +1,13 @@
+-- SQLite не проверяет длину VARCHAR, поэтому ограничение задано через CHECK.
+-- Даты хранятся текстом в формате _time_format=sqlite (UTC) и
+-- сравниваются через date().
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id TEXT PRIMARY KEY NOT NULL,
+    service_name VARCHAR(100) NOT NULL CHECK (length(service_name) <= 100),
+    price INTEGER NOT NULL CHECK (price > 0),
+    user_id TEXT NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE CHECK (date(end_date) >= date(start_date)),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
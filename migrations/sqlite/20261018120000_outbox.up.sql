@@ -0,0 +1,19 @@
+-- Время хранится текстом в UTC (_time_format=sqlite) и сравнивается как
+-- строка, поэтому next_attempt_at всегда задаёт приложение.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    id TEXT NOT NULL UNIQUE,
+    aggregate_id TEXT NOT NULL,
+    event_type VARCHAR(100) NOT NULL CHECK (length(event_type) <= 100),
+    payload TEXT NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT,
+    published_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_pending_idx
+    ON outbox_events (seq) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_events_aggregate_pending_idx
+    ON outbox_events (aggregate_id, seq) WHERE published_at IS NULL;
@@ -0,0 +1,29 @@
+-- Время хранится текстом в UTC и сравнивается как строка, поэтому
+-- due_at, claimed_until и created_at журнала задаёт приложение.
+CREATE TABLE IF NOT EXISTS reminder_preferences (
+    user_id TEXT PRIMARY KEY NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    days_before INTEGER NOT NULL CHECK (days_before BETWEEN 1 AND 90),
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('email', 'webhook', 'log')),
+    email VARCHAR(255) CHECK (length(email) <= 255),
+    webhook_url VARCHAR(2048) CHECK (length(webhook_url) <= 2048),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS reminder_notifications (
+    id TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL,
+    subscription_id TEXT NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    due_at TIMESTAMP NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    claimed_until TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS reminder_notifications_user_idx
+    ON reminder_notifications (user_id, created_at);
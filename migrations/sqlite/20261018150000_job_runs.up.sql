@@ -0,0 +1,15 @@
+-- Время хранится текстом в UTC, started_at и finished_at задаёт
+-- приложение.
+CREATE TABLE IF NOT EXISTS job_runs (
+    id TEXT PRIMARY KEY NOT NULL,
+    job VARCHAR(100) NOT NULL CHECK (length(job) <= 100),
+    trigger VARCHAR(20) NOT NULL CHECK (trigger IN ('schedule', 'manual')),
+    instance VARCHAR(255) NOT NULL CHECK (length(instance) <= 255),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS job_runs_job_started_idx
+    ON job_runs (job, started_at DESC);
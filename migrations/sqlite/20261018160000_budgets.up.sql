@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS budgets (
+    id TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL,
+    service_name VARCHAR(100) CHECK (length(service_name) BETWEEN 1 AND 100),
+    monthly_limit INTEGER NOT NULL CHECK (monthly_limit > 0),
+    hard_limit BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS budgets_user_service_idx
+    ON budgets (user_id, COALESCE(service_name, ''));
@@ -0,0 +1,6 @@
+-- Исходные написания service_name, заменённые каноническими при
+-- заполнении каталога, не восстанавливаются.
+DROP INDEX IF EXISTS subscriptions_service_id_idx;
+ALTER TABLE subscriptions DROP COLUMN service_id;
+DROP TABLE IF EXISTS service_aliases;
+DROP TABLE IF EXISTS services;
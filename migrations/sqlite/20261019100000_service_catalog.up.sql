@@ -0,0 +1,74 @@
+-- Каталог сервисов. service_aliases хранит ключи сопоставления имени и
+-- синонимов (catalog.Key): по ключу свободный текст service_name
+-- сводится к сервису, поэтому ключ уникален во всём каталоге.
+CREATE TABLE IF NOT EXISTS services (
+    id TEXT PRIMARY KEY NOT NULL,
+    name VARCHAR(100) NOT NULL CHECK (length(name) <= 100),
+    category VARCHAR(50) CHECK (length(category) <= 50),
+    default_price INTEGER CHECK (default_price > 0),
+    currency CHAR(3) NOT NULL DEFAULT 'RUB' CHECK (length(currency) = 3),
+    logo_url VARCHAR(500) CHECK (length(logo_url) <= 500),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS services_category_idx ON services (category);
+
+CREATE TABLE IF NOT EXISTS service_aliases (
+    key VARCHAR(100) PRIMARY KEY NOT NULL CHECK (length(key) <= 100),
+    service_id TEXT NOT NULL REFERENCES services (id) ON DELETE CASCADE,
+    alias VARCHAR(100) NOT NULL CHECK (length(alias) <= 100)
+);
+
+CREATE INDEX IF NOT EXISTS service_aliases_service_idx ON service_aliases (service_id);
+
+-- SQLite не удаляет столбец с внешним ключом, поэтому service_id без
+-- REFERENCES: при удалении сервиса подписки отвязывает приложение.
+ALTER TABLE subscriptions ADD COLUMN service_id TEXT;
+
+CREATE INDEX IF NOT EXISTS subscriptions_service_id_idx ON subscriptions (service_id);
+
+-- Заполнение каталога из существующих подписок. В SQLite нет translate и
+-- регулярных выражений, поэтому ключ — латиница в нижнем регистре без
+-- пробелов и знаков. Названия с другими символами (кириллица) остаются без
+-- сервиса и привязываются при следующем изменении подписки.
+CREATE TEMP TABLE subscription_keys AS
+SELECT id, trim(service_name) AS name,
+       replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(
+           lower(service_name),
+           ' ', ''), '-', ''), '_', ''), '.', ''), ',', ''), '!', ''), '+', ''), '&', ''), '''', ''), ':', ''), '/', '') AS key
+FROM subscriptions;
+
+CREATE TEMP TABLE service_backfill AS
+SELECT lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' ||
+       substr(lower(hex(randomblob(2))), 2) || '-a' || substr(lower(hex(randomblob(2))), 2) || '-' ||
+       lower(hex(randomblob(6))) AS id,
+       key, name
+FROM (
+    SELECT key, name,
+           row_number() OVER (PARTITION BY key ORDER BY count(*) DESC, name) AS rn
+    FROM subscription_keys
+    WHERE key <> '' AND key NOT GLOB '*[^a-z0-9]*'
+    GROUP BY key, name
+)
+WHERE rn = 1;
+
+INSERT INTO services (id, name)
+SELECT id, name FROM service_backfill;
+
+INSERT INTO service_aliases (key, service_id, alias)
+SELECT key, id, name FROM service_backfill;
+
+UPDATE subscriptions
+SET (service_id, service_name) = (
+    SELECT b.id, b.name
+    FROM subscription_keys k
+    JOIN service_backfill b ON b.key = k.key
+    WHERE k.id = subscriptions.id
+)
+WHERE id IN (
+    SELECT k.id FROM subscription_keys k JOIN service_backfill b ON b.key = k.key
+);
+
+DROP TABLE subscription_keys;
+DROP TABLE service_backfill;
@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions DROP COLUMN metadata;
+ALTER TABLE subscriptions DROP COLUMN tags;
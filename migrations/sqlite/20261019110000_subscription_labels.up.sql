@@ -0,0 +1,5 @@
+-- Теги и metadata хранятся JSON-текстом. Индексов по содержимому JSON
+-- в SQLite нет, фильтры разбирают его через json_each и json_extract.
+-- CHECK не добавляется: колонку с ним нельзя удалить через DROP COLUMN.
+ALTER TABLE subscriptions ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE subscriptions ADD COLUMN metadata TEXT NOT NULL DEFAULT '{}';
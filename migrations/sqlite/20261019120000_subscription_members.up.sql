@@ -0,0 +1,12 @@
+-- Участники общих подписок, см. миграцию Postgres.
+CREATE TABLE IF NOT EXISTS subscription_members (
+    subscription_id TEXT NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    percent REAL CHECK (percent > 0 AND percent <= 100),
+    amount INTEGER CHECK (amount > 0),
+    share INTEGER NOT NULL CHECK (share >= 0),
+    PRIMARY KEY (subscription_id, user_id),
+    CHECK (percent IS NULL OR amount IS NULL)
+);
+
+CREATE INDEX IF NOT EXISTS subscription_members_user_idx ON subscription_members (user_id);
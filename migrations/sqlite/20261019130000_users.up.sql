@@ -0,0 +1,23 @@
+-- Пользователи, см. миграцию Postgres.
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY NOT NULL,
+    display_name VARCHAR(100) NOT NULL CHECK (length(display_name) <= 100),
+    email VARCHAR(254) UNIQUE CHECK (length(email) <= 254),
+    currency CHAR(3) NOT NULL DEFAULT 'RUB' CHECK (length(currency) = 3),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC' CHECK (length(timezone) <= 64),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT OR IGNORE INTO users (id, display_name)
+SELECT user_id, 'user ' || substr(user_id, 1, 8)
+FROM (
+    SELECT user_id FROM subscriptions
+    UNION SELECT user_id FROM subscription_members
+    UNION SELECT user_id FROM budgets
+    UNION SELECT user_id FROM reminder_preferences
+);
+
+-- SQLite не добавляет внешний ключ к существующему столбцу без
+-- пересоздания таблицы, поэтому user_id подписок и участников без
+-- REFERENCES: ссылку на пользователя проверяет приложение.
@@ -0,0 +1,6 @@
+-- Владелец webhook-endpoint'а, см. миграцию Postgres. SQLite не удаляет
+-- столбец с внешним ключом, поэтому user_id без REFERENCES: endpoint'ы
+-- пользователя удаляет приложение.
+ALTER TABLE webhook_endpoints ADD COLUMN user_id TEXT;
+
+CREATE INDEX IF NOT EXISTS webhook_endpoints_user_id_idx ON webhook_endpoints (user_id);
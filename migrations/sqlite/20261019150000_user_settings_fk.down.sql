@@ -0,0 +1 @@
+-- Заведённые пользователи остаются: их мог создать и API.
@@ -0,0 +1,9 @@
+-- Пользователи для бюджетов и настроек напоминаний, см. миграцию
+-- Postgres. Внешних ключей нет (см. 20261019130000_users): бюджеты и
+-- настройки пользователя удаляет приложение.
+INSERT OR IGNORE INTO users (id, display_name)
+SELECT user_id, 'user ' || substr(user_id, 1, 8)
+FROM (
+    SELECT user_id FROM budgets
+    UNION SELECT user_id FROM reminder_preferences
+);
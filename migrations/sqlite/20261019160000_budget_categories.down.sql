@@ -0,0 +1,7 @@
+DELETE FROM budgets WHERE category IS NOT NULL;
+
+DROP INDEX IF EXISTS budgets_user_scope_idx;
+ALTER TABLE budgets DROP COLUMN category;
+
+CREATE UNIQUE INDEX IF NOT EXISTS budgets_user_service_idx
+    ON budgets (user_id, COALESCE(service_name, ''));
@@ -0,0 +1,7 @@
+-- Бюджет на категорию каталога, см. миграцию Postgres. Что бюджет задан
+-- либо на сервис, либо на категорию, проверяет приложение.
+ALTER TABLE budgets ADD COLUMN category VARCHAR(50) CHECK (length(category) BETWEEN 1 AND 50);
+
+DROP INDEX IF EXISTS budgets_user_service_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS budgets_user_scope_idx
+    ON budgets (user_id, COALESCE(service_name, ''), COALESCE(category, ''));
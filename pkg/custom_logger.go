@@ -7,20 +7,28 @@ import (
 	"time"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ctxKey struct{}
 
 var (
 	log zerolog.Logger
+	out io.Writer
 )
 
 func Init(devMode bool) {
-	var w io.Writer = os.Stdout
+	InitWithWriter(os.Stdout, devMode)
+}
+
+// InitWithWriter — как Init, но с произвольным выводом
+// (например, os.Stderr для CLI, где stdout занят результатом).
+func InitWithWriter(w io.Writer, devMode bool) {
+	out = w
 
 	if devMode {
 		consoleWriter := zerolog.ConsoleWriter{
-			Out:        os.Stdout,
+			Out:        w,
 			TimeFormat: time.RFC3339,
 		}
 		w = consoleWriter
@@ -35,16 +43,49 @@ func Init(devMode bool) {
 		Logger()
 }
 
+// SetLevel задаёт минимальный уровень логов (debug, info, warn, error).
+func SetLevel(level string) error {
+	l, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	zerolog.SetGlobalLevel(l)
+	return nil
+}
+
+// Sync сбрасывает буферы вывода логов, вызывается при остановке.
+func Sync() error {
+	if f, ok := out.(*os.File); ok {
+		return f.Sync()
+	}
+	return nil
+}
+
 func FromContext(ctx context.Context) zerolog.Logger {
 	if ctx == nil {
 		return log
 	}
 
-	if l, ok := ctx.Value(ctxKey{}).(zerolog.Logger); ok {
+	l, ok := ctx.Value(ctxKey{}).(zerolog.Logger)
+	if !ok {
+		l = log
+	}
+
+	return withTrace(ctx, l)
+}
+
+// withTrace добавляет trace_id и span_id текущего спана,
+// чтобы логи можно было сопоставить с трейсами.
+func withTrace(ctx context.Context, l zerolog.Logger) zerolog.Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
 		return l
 	}
 
-	return log
+	return l.With().
+		Str("trace_id", sc.TraceID().String()).
+		Str("span_id", sc.SpanID().String()).
+		Logger()
 }
 
 func WithContext(ctx context.Context, l zerolog.Logger) context.Context {